@@ -7,6 +7,8 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	// Store timezones are resolved even where the host has no zoneinfo database
+	_ "time/tzdata"
 
 	"pizza-must/internal/config"
 	"pizza-must/internal/database"
@@ -78,7 +80,10 @@ func main() {
 	log.Info("Database migrations completed successfully")
 
 	// Create server
-	srv := server.NewServer(cfg, log, db)
+	srv, err := server.NewServer(cfg, log, db)
+	if err != nil {
+		log.Fatal("Failed to create server", zap.Error(err))
+	}
 
 	// Create a done channel to signal when the shutdown is complete
 	done := make(chan bool, 1)
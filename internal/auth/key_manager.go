@@ -0,0 +1,327 @@
+// Package auth signs and verifies JWT access tokens with rotating asymmetric keys held in a
+// persistent key store
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Supported signing algorithms
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+
+	// rsaKeyBits is the modulus size of generated RSA keys
+	rsaKeyBits = 2048
+
+	// keyReloadInterval is how often the keys are reloaded from the store to pick up keys
+	// rotated by other processes
+	keyReloadInterval = 10 * time.Second
+	// keyStoreTimeout bounds each call to the key store
+	keyStoreTimeout = 5 * time.Second
+)
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	ErrUnknownKey           = errors.New("unknown signing key")
+)
+
+// KeyManagerConfig configures the signing algorithm and key rotation schedule
+type KeyManagerConfig struct {
+	// Algorithm is AlgorithmRS256 or AlgorithmEdDSA
+	Algorithm string
+	// RotationInterval is how long a key signs tokens before a new key replaces it.
+	// Zero disables rotation.
+	RotationInterval time.Duration
+	// OverlapWindow is how long a replaced key keeps verifying tokens and stays published.
+	// It must be at least the access token lifetime so tokens outlive their signing key.
+	OverlapWindow time.Duration
+}
+
+// KeyManager signs tokens with the current key and verifies them with any published key
+type KeyManager interface {
+	// Sign signs claims with the current key and sets the kid header
+	Sign(claims jwt.Claims) (string, error)
+	// Keyfunc selects the verification key by the token's kid header; it is a jwt.Keyfunc
+	Keyfunc(token *jwt.Token) (interface{}, error)
+	// JWKS returns the public keys that currently verify tokens
+	JWKS() JWKSet
+	// Rotate replaces the current signing key immediately
+	Rotate() error
+}
+
+type signingKey struct {
+	id        string
+	method    jwt.SigningMethod
+	private   crypto.Signer
+	createdAt time.Time
+	retiredAt time.Time // zero while the key is signing
+}
+
+type keyManager struct {
+	mu       sync.Mutex
+	config   KeyManagerConfig
+	store    KeyStore
+	now      func() time.Time
+	keys     []*signingKey // the current signing key is last
+	loadedAt time.Time
+}
+
+// NewKeyManager creates a KeyManager signing with the keys held in store. A signing key
+// is generated when the store holds none or its key is due for rotation, so processes
+// sharing a store sign and verify with the same keys and tokens survive restarts.
+func NewKeyManager(config KeyManagerConfig, store KeyStore) (KeyManager, error) {
+	return newKeyManager(config, store, time.Now)
+}
+
+// newKeyManager creates a keyManager that reads the time from now
+func newKeyManager(config KeyManagerConfig, store KeyStore, now func() time.Time) (*keyManager, error) {
+	if config.Algorithm != AlgorithmRS256 && config.Algorithm != AlgorithmEdDSA {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, config.Algorithm)
+	}
+
+	m := &keyManager{config: config, store: store, now: now}
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+	if err := m.rotateIfDue(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Sign signs claims with the current key, picking up keys rotated by other processes and
+// rotating the key first when it is due
+func (m *keyManager) Sign(claims jwt.Claims) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.reloadIfStale(); err != nil {
+		return "", err
+	}
+	if err := m.rotateIfDue(); err != nil {
+		return "", err
+	}
+
+	key := m.keys[len(m.keys)-1]
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.private)
+}
+
+// Keyfunc returns the public key named by the token's kid header. An unknown kid reloads
+// the keys, at most once per reload interval, in case another process rotated. Tokens
+// without a kid, signed with a key retired longer than the overlap window, or whose
+// algorithm differs from the key's are rejected.
+func (m *keyManager) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing kid header", ErrUnknownKey)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pruneRetired()
+	key := m.find(kid)
+	if key == nil {
+		if err := m.reloadIfStale(); err != nil {
+			return nil, err
+		}
+		key = m.find(kid)
+	}
+	if key == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.private.Public(), nil
+}
+
+// JWKS returns the public keys that currently verify tokens, newest last
+func (m *keyManager) JWKS() JWKSet {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Publish a due replacement before anyone is handed a token signed with it. When the
+	// store is unavailable the keys already held are published.
+	if err := m.reloadIfStale(); err == nil {
+		_ = m.rotateIfDue()
+	}
+	m.pruneRetired()
+
+	set := JWKSet{Keys: make([]JWK, 0, len(m.keys))}
+	for _, key := range m.keys {
+		set.Keys = append(set.Keys, newJWK(key))
+	}
+	return set
+}
+
+// Rotate generates a new signing key and retires the current one
+func (m *keyManager) Rotate() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Replace the key the store holds, which another process may have rotated
+	if err := m.load(); err != nil {
+		return err
+	}
+	return m.rotate()
+}
+
+// find returns the held key with the given kid, or nil. The caller must hold mu.
+func (m *keyManager) find(kid string) *signingKey {
+	for _, key := range m.keys {
+		if key.id == kid {
+			return key
+		}
+	}
+	return nil
+}
+
+// reloadIfStale reloads the keys from the store once the reload interval has passed since
+// they were last loaded. The caller must hold mu.
+func (m *keyManager) reloadIfStale() error {
+	if m.now().Sub(m.loadedAt) < keyReloadInterval {
+		return nil
+	}
+	return m.load()
+}
+
+// load replaces the held keys with the signing key and the keys within their overlap
+// window from the store. The caller must hold mu.
+func (m *keyManager) load() error {
+	ctx, cancel := context.WithTimeout(context.Background(), keyStoreTimeout)
+	defer cancel()
+
+	now := m.now()
+	stored, err := m.store.List(ctx, now.Add(-m.config.OverlapWindow).UTC())
+	if err != nil {
+		return fmt.Errorf("failed to load signing keys: %w", err)
+	}
+
+	keys := make([]*signingKey, 0, len(stored))
+	for _, s := range stored {
+		key, err := decodeKey(s)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+	m.keys = keys
+	m.loadedAt = now
+	return nil
+}
+
+// due reports whether the signing key must be replaced: there is none, it was retired by
+// another process, it uses another algorithm than configured, or it has signed for the
+// rotation interval. The caller must hold mu.
+func (m *keyManager) due() bool {
+	if len(m.keys) == 0 {
+		return true
+	}
+	current := m.keys[len(m.keys)-1]
+	if !current.retiredAt.IsZero() || current.method.Alg() != m.config.Algorithm {
+		return true
+	}
+	return m.config.RotationInterval > 0 && m.now().Sub(current.createdAt) >= m.config.RotationInterval
+}
+
+// rotateIfDue rotates the signing key when it is due. The caller must hold mu.
+func (m *keyManager) rotateIfDue() error {
+	if !m.due() {
+		return nil
+	}
+	return m.rotate()
+}
+
+// rotate generates a new signing key replacing the current one in the store and drops keys
+// whose overlap window has passed. When another process replaced the current key first,
+// its replacement is loaded and signs instead. The caller must hold mu.
+func (m *keyManager) rotate() error {
+	key, err := generateKey(m.config.Algorithm)
+	if err != nil {
+		return fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	now := m.now()
+	key.createdAt = now
+	stored, err := encodeKey(key)
+	if err != nil {
+		return err
+	}
+	if len(m.keys) > 0 {
+		stored.Replaces = m.keys[len(m.keys)-1].id
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyStoreTimeout)
+	defer cancel()
+
+	if err := m.store.DeleteRetiredBefore(ctx, now.Add(-m.config.OverlapWindow).UTC()); err != nil {
+		return fmt.Errorf("failed to delete retired signing keys: %w", err)
+	}
+	if err := m.store.Create(ctx, stored); err != nil {
+		if loadErr := m.load(); loadErr != nil || m.due() {
+			return fmt.Errorf("failed to store signing key: %w", err)
+		}
+		return nil
+	}
+
+	if len(m.keys) > 0 {
+		m.keys[len(m.keys)-1].retiredAt = now
+	}
+	m.keys = append(m.keys, key)
+	m.pruneRetired()
+	return nil
+}
+
+// pruneRetired drops keys retired longer than the overlap window. The caller must hold mu.
+func (m *keyManager) pruneRetired() {
+	now := m.now()
+	kept := m.keys[:0]
+	for _, key := range m.keys {
+		if !key.retiredAt.IsZero() && now.Sub(key.retiredAt) > m.config.OverlapWindow {
+			continue
+		}
+		kept = append(kept, key)
+	}
+	m.keys = kept
+}
+
+// generateKey creates a key pair for the algorithm identified by a random kid
+func generateKey(algorithm string) (*signingKey, error) {
+	key := &signingKey{id: uuid.New().String()}
+
+	switch algorithm {
+	case AlgorithmRS256:
+		private, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, err
+		}
+		key.method = jwt.SigningMethodRS256
+		key.private = private
+	case AlgorithmEdDSA:
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		key.method = jwt.SigningMethodEdDSA
+		key.private = private
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, algorithm)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,256 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testClock is a manually advanced clock
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func testClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"user_id": "user-1",
+		"role":    "user",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+// publicKeyFromJWK rebuilds the public key a JWK describes, as a relying service would
+func publicKeyFromJWK(t *testing.T, jwk JWK) interface{} {
+	t.Helper()
+	decode := func(value string) []byte {
+		decoded, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil {
+			t.Fatalf("Failed to decode JWK member: %v", err)
+		}
+		return decoded
+	}
+
+	switch jwk.Kty {
+	case "RSA":
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(decode(jwk.N)),
+			E: int(new(big.Int).SetBytes(decode(jwk.E)).Int64()),
+		}
+	case "OKP":
+		return ed25519.PublicKey(decode(jwk.X))
+	}
+	t.Fatalf("Unexpected key type %q", jwk.Kty)
+	return nil
+}
+
+func TestKeyManager_SignedTokensVerifyWithPublishedKeys(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmRS256, AlgorithmEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			keys, err := NewKeyManager(KeyManagerConfig{Algorithm: algorithm, OverlapWindow: time.Hour}, NewMemoryKeyStore())
+			if err != nil {
+				t.Fatalf("NewKeyManager failed: %v", err)
+			}
+
+			tokenString, err := keys.Sign(testClaims())
+			if err != nil {
+				t.Fatalf("Sign failed: %v", err)
+			}
+
+			token, err := jwt.Parse(tokenString, keys.Keyfunc)
+			if err != nil || !token.Valid {
+				t.Fatalf("Token did not verify with its key manager: %v", err)
+			}
+			if token.Method.Alg() != algorithm {
+				t.Errorf("Expected alg %s, got %s", algorithm, token.Method.Alg())
+			}
+
+			// A relying service verifies with the published key named by kid
+			set := keys.JWKS()
+			if len(set.Keys) != 1 {
+				t.Fatalf("Expected 1 published key, got %d", len(set.Keys))
+			}
+			jwk := set.Keys[0]
+			if jwk.Kid != token.Header["kid"] || jwk.Alg != algorithm || jwk.Use != "sig" {
+				t.Fatalf("Published key %+v does not describe the signing key", jwk)
+			}
+			_, err = jwt.Parse(tokenString, func(*jwt.Token) (interface{}, error) {
+				return publicKeyFromJWK(t, jwk), nil
+			})
+			if err != nil {
+				t.Errorf("Token did not verify with the published key: %v", err)
+			}
+		})
+	}
+}
+
+func TestKeyManager_RotationKeepsOldKeyForOverlapWindow(t *testing.T) {
+	clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	keys, err := newKeyManager(KeyManagerConfig{
+		Algorithm:        AlgorithmEdDSA,
+		RotationInterval: time.Hour,
+		OverlapWindow:    30 * time.Minute,
+	}, NewMemoryKeyStore(), clock.Now)
+	if err != nil {
+		t.Fatalf("newKeyManager failed: %v", err)
+	}
+
+	oldToken, _ := keys.Sign(testClaims())
+
+	// The key is rotated once it has signed for the rotation interval
+	clock.now = clock.now.Add(time.Hour)
+	newToken, _ := keys.Sign(testClaims())
+
+	parsedOld, err := jwt.Parse(oldToken, keys.Keyfunc)
+	if err != nil {
+		t.Fatalf("Token signed before rotation rejected within overlap window: %v", err)
+	}
+	parsedNew, err := jwt.Parse(newToken, keys.Keyfunc)
+	if err != nil {
+		t.Fatalf("Token signed after rotation rejected: %v", err)
+	}
+	if parsedOld.Header["kid"] == parsedNew.Header["kid"] {
+		t.Fatalf("Expected a new kid after rotation")
+	}
+	if n := len(keys.JWKS().Keys); n != 2 {
+		t.Fatalf("Expected both keys published during overlap, got %d", n)
+	}
+
+	// Once the overlap window has passed only the new key verifies
+	clock.now = clock.now.Add(31 * time.Minute)
+	if _, err := jwt.Parse(oldToken, keys.Keyfunc); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey for retired key, got %v", err)
+	}
+	if _, err := jwt.Parse(newToken, keys.Keyfunc); err != nil {
+		t.Errorf("Current key rejected after overlap window: %v", err)
+	}
+	if n := len(keys.JWKS().Keys); n != 1 {
+		t.Errorf("Expected only the current key published, got %d", n)
+	}
+}
+
+func TestKeyManager_RejectsTokensNotMatchingAKey(t *testing.T) {
+	keys, err := NewKeyManager(KeyManagerConfig{Algorithm: AlgorithmEdDSA, OverlapWindow: time.Hour}, NewMemoryKeyStore())
+	if err != nil {
+		t.Fatalf("NewKeyManager failed: %v", err)
+	}
+	kid := keys.JWKS().Keys[0].Kid
+
+	missingKid := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+	missingKidString, _ := missingKid.SignedString([]byte("secret"))
+
+	// An HMAC token naming our kid must not be verified with the public key as secret
+	wrongAlg := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+	wrongAlg.Header["kid"] = kid
+	wrongAlgString, _ := wrongAlg.SignedString([]byte("secret"))
+
+	for name, tokenString := range map[string]string{"missing kid": missingKidString, "wrong alg": wrongAlgString} {
+		if _, err := jwt.Parse(tokenString, keys.Keyfunc); err == nil {
+			t.Errorf("%s: expected token to be rejected", name)
+		}
+	}
+}
+
+func TestNewKeyManager_RejectsUnsupportedAlgorithm(t *testing.T) {
+	_, err := NewKeyManager(KeyManagerConfig{Algorithm: "HS256"}, NewMemoryKeyStore())
+	if !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Errorf("Expected ErrUnsupportedAlgorithm, got %v", err)
+	}
+}
+
+func TestKeyManager_ProcessesSharingAStoreUseTheSameKeys(t *testing.T) {
+	clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	config := KeyManagerConfig{Algorithm: AlgorithmEdDSA, RotationInterval: time.Hour, OverlapWindow: 30 * time.Minute}
+	store := NewMemoryKeyStore()
+
+	first, err := newKeyManager(config, store, clock.Now)
+	if err != nil {
+		t.Fatalf("newKeyManager failed: %v", err)
+	}
+	tokenString, _ := first.Sign(testClaims())
+
+	// A replica, or the same process after a restart, verifies and signs with the stored key
+	second, err := newKeyManager(config, store, clock.Now)
+	if err != nil {
+		t.Fatalf("newKeyManager failed: %v", err)
+	}
+	if _, err := jwt.Parse(tokenString, second.Keyfunc); err != nil {
+		t.Fatalf("Token rejected by a process sharing the store: %v", err)
+	}
+	secondToken, _ := second.Sign(testClaims())
+	parsed, _ := jwt.Parse(secondToken, first.Keyfunc)
+	if parsed == nil || parsed.Header["kid"] != first.JWKS().Keys[0].Kid {
+		t.Fatalf("Expected both processes to sign with the stored key")
+	}
+
+	// Both find the key due at once; only one replacement is stored and both sign with it
+	clock.now = clock.now.Add(time.Hour)
+	firstRotated, _ := first.Sign(testClaims())
+	secondRotated, _ := second.Sign(testClaims())
+	parsedFirst, err := jwt.Parse(firstRotated, second.Keyfunc)
+	if err != nil {
+		t.Fatalf("Token signed with the replacement rejected: %v", err)
+	}
+	parsedSecond, err := jwt.Parse(secondRotated, first.Keyfunc)
+	if err != nil {
+		t.Fatalf("Token signed with the replacement rejected: %v", err)
+	}
+	if parsedFirst.Header["kid"] != parsedSecond.Header["kid"] {
+		t.Errorf("Expected a single replacement key, got %v and %v", parsedFirst.Header["kid"], parsedSecond.Header["kid"])
+	}
+	if _, err := jwt.Parse(tokenString, second.Keyfunc); err != nil {
+		t.Errorf("Token signed before rotation rejected within overlap window: %v", err)
+	}
+	if n := len(second.JWKS().Keys); n != 2 {
+		t.Errorf("Expected both keys published during overlap, got %d", n)
+	}
+}
+
+func TestKeyManager_PicksUpKeysRotatedElsewhere(t *testing.T) {
+	clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	config := KeyManagerConfig{Algorithm: AlgorithmEdDSA, OverlapWindow: 30 * time.Minute}
+	store := NewMemoryKeyStore()
+
+	first, _ := newKeyManager(config, store, clock.Now)
+	second, _ := newKeyManager(config, store, clock.Now)
+	if err := first.Rotate(); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	tokenString, _ := first.Sign(testClaims())
+
+	// An unknown kid reloads the keys once the reload interval has passed
+	clock.now = clock.now.Add(keyReloadInterval)
+	if _, err := jwt.Parse(tokenString, second.Keyfunc); err != nil {
+		t.Fatalf("Token signed with a key rotated elsewhere rejected: %v", err)
+	}
+	secondToken, _ := second.Sign(testClaims())
+	parsed, _ := jwt.Parse(secondToken, first.Keyfunc)
+	if parsed == nil || parsed.Header["kid"] != first.JWKS().Keys[1].Kid {
+		t.Errorf("Expected the rotated key to sign everywhere")
+	}
+}
+
+func TestNewKeyManager_RotatesWhenTheAlgorithmChanges(t *testing.T) {
+	store := NewMemoryKeyStore()
+	if _, err := NewKeyManager(KeyManagerConfig{Algorithm: AlgorithmRS256, OverlapWindow: time.Hour}, store); err != nil {
+		t.Fatalf("NewKeyManager failed: %v", err)
+	}
+
+	keys, err := NewKeyManager(KeyManagerConfig{Algorithm: AlgorithmEdDSA, OverlapWindow: time.Hour}, store)
+	if err != nil {
+		t.Fatalf("NewKeyManager failed: %v", err)
+	}
+	set := keys.JWKS()
+	if len(set.Keys) != 2 || set.Keys[0].Alg != AlgorithmRS256 || set.Keys[1].Alg != AlgorithmEdDSA {
+		t.Errorf("Expected the RS256 key to be replaced by an EdDSA key, got %+v", set.Keys)
+	}
+}
@@ -0,0 +1,234 @@
+package auth
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"pizza-must/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeyEncryptionKeySize is the size in bytes of the AES-256 key that encrypts signing keys
+// at rest
+const KeyEncryptionKeySize = 32
+
+var (
+	ErrInvalidKeyEncryptionKey = errors.New("key encryption key must be 32 bytes")
+
+	errKeyReplaced = errors.New("signing key has already been replaced")
+)
+
+// KeyStore persists signing keys so tokens survive restarts and every process sharing the
+// store signs and verifies with the same keys
+type KeyStore interface {
+	// Create stores a key replacing the key named by its Replaces field, or the first key
+	// when Replaces is empty. It fails if that key has already been replaced.
+	Create(ctx context.Context, key *domain.SigningKey) error
+	// List returns the signing key and the keys retired after retiredAfter, oldest first
+	// and the signing key last
+	List(ctx context.Context, retiredAfter time.Time) ([]*domain.SigningKey, error)
+	// DeleteRetiredBefore deletes the keys retired before the given time
+	DeleteRetiredBefore(ctx context.Context, before time.Time) error
+}
+
+type memoryKeyStore struct {
+	mu   sync.Mutex
+	keys []domain.SigningKey
+}
+
+// NewMemoryKeyStore creates a KeyStore holding keys in process memory. Tokens then do not
+// survive a restart and verify only in the issuing process, so it suits tests and single
+// process development setups.
+func NewMemoryKeyStore() KeyStore {
+	return &memoryKeyStore{}
+}
+
+// Create stores a copy of key unless the key it replaces already has a successor
+func (s *memoryKeyStore) Create(ctx context.Context, key *domain.SigningKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stored := range s.keys {
+		if stored.Replaces == key.Replaces {
+			return errKeyReplaced
+		}
+	}
+	stored := *key
+	stored.RetiredAt = nil
+	s.keys = append(s.keys, stored)
+	return nil
+}
+
+// List returns copies of the keys not retired before retiredAfter
+func (s *memoryKeyStore) List(ctx context.Context, retiredAfter time.Time) ([]*domain.SigningKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := []*domain.SigningKey{}
+	for _, stored := range s.keys {
+		key := stored
+		key.RetiredAt = s.retiredAt(key.ID)
+		if key.RetiredAt != nil && !key.RetiredAt.After(retiredAfter) {
+			continue
+		}
+		keys = append(keys, &key)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		if (keys[i].RetiredAt == nil) != (keys[j].RetiredAt == nil) {
+			return keys[i].RetiredAt != nil
+		}
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// DeleteRetiredBefore deletes the keys retired before the given time
+func (s *memoryKeyStore) DeleteRetiredBefore(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := make([]domain.SigningKey, 0, len(s.keys))
+	for _, stored := range s.keys {
+		if retiredAt := s.retiredAt(stored.ID); retiredAt != nil && retiredAt.Before(before) {
+			continue
+		}
+		kept = append(kept, stored)
+	}
+	s.keys = kept
+	return nil
+}
+
+// retiredAt returns when the key's successor was created, or nil while the key is signing.
+// The caller must hold mu.
+func (s *memoryKeyStore) retiredAt(id string) *time.Time {
+	for _, stored := range s.keys {
+		if stored.Replaces == id {
+			createdAt := stored.CreatedAt
+			return &createdAt
+		}
+	}
+	return nil
+}
+
+type encryptedKeyStore struct {
+	store KeyStore
+	aead  cipher.AEAD
+}
+
+// NewEncryptedKeyStore wraps store so private keys are only ever held in it encrypted with
+// AES-256-GCM under encryptionKey. Each key's ID and algorithm are authenticated with it,
+// so stored key material cannot be moved to another row. Reading the store then no longer
+// allows signing tokens without the encryption key.
+func NewEncryptedKeyStore(store KeyStore, encryptionKey []byte) (KeyStore, error) {
+	if len(encryptionKey) != KeyEncryptionKeySize {
+		return nil, ErrInvalidKeyEncryptionKey
+	}
+	block, err := aes.NewCipher(encryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key cipher: %w", err)
+	}
+	return &encryptedKeyStore{store: store, aead: aead}, nil
+}
+
+// Create encrypts the private key of key and stores it; key itself is not modified
+func (s *encryptedKeyStore) Create(ctx context.Context, key *domain.SigningKey) error {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate key nonce: %w", err)
+	}
+
+	sealed := *key
+	sealed.PrivateKey = s.aead.Seal(nonce, nonce, key.PrivateKey, keyAssociatedData(key))
+	return s.store.Create(ctx, &sealed)
+}
+
+// List returns the stored keys with their private keys decrypted
+func (s *encryptedKeyStore) List(ctx context.Context, retiredAfter time.Time) ([]*domain.SigningKey, error) {
+	keys, err := s.store.List(ctx, retiredAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		nonceSize := s.aead.NonceSize()
+		if len(key.PrivateKey) < nonceSize {
+			return nil, fmt.Errorf("failed to decrypt signing key %q: too short", key.ID)
+		}
+		nonce, sealed := key.PrivateKey[:nonceSize], key.PrivateKey[nonceSize:]
+		key.PrivateKey, err = s.aead.Open(nil, nonce, sealed, keyAssociatedData(key))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt signing key %q: %w", key.ID, err)
+		}
+	}
+	return keys, nil
+}
+
+// DeleteRetiredBefore deletes the keys retired before the given time
+func (s *encryptedKeyStore) DeleteRetiredBefore(ctx context.Context, before time.Time) error {
+	return s.store.DeleteRetiredBefore(ctx, before)
+}
+
+// keyAssociatedData binds an encrypted private key to the key's ID and algorithm
+func keyAssociatedData(key *domain.SigningKey) []byte {
+	return []byte(key.Algorithm + "\x00" + key.ID)
+}
+
+// encodeKey converts a signing key into its persisted form
+func encodeKey(key *signingKey) (*domain.SigningKey, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key.private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signing key: %w", err)
+	}
+	return &domain.SigningKey{
+		ID:         key.id,
+		Algorithm:  key.method.Alg(),
+		PrivateKey: der,
+		CreatedAt:  key.createdAt.UTC(),
+	}, nil
+}
+
+// decodeKey converts a persisted key back into a signing key, checking that the key
+// material suits its algorithm
+func decodeKey(stored *domain.SigningKey) (*signingKey, error) {
+	parsed, err := x509.ParsePKCS8PrivateKey(stored.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode signing key %q: %w", stored.ID, err)
+	}
+
+	key := &signingKey{id: stored.ID, createdAt: stored.CreatedAt}
+	if stored.RetiredAt != nil {
+		key.retiredAt = *stored.RetiredAt
+	}
+
+	var ok bool
+	switch stored.Algorithm {
+	case AlgorithmRS256:
+		key.method = jwt.SigningMethodRS256
+		key.private, ok = parsed.(*rsa.PrivateKey)
+	case AlgorithmEdDSA:
+		key.method = jwt.SigningMethodEdDSA
+		key.private, ok = parsed.(ed25519.PrivateKey)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, stored.Algorithm)
+	}
+	if !ok {
+		return nil, fmt.Errorf("signing key %q does not hold a %s key", stored.ID, stored.Algorithm)
+	}
+
+	return key, nil
+}
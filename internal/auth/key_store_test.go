@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestEncryptedKeyStore_StoresKeysEncrypted(t *testing.T) {
+	ctx := context.Background()
+	encryptionKey := bytes.Repeat([]byte{7}, KeyEncryptionKeySize)
+	plain := NewMemoryKeyStore()
+	store, err := NewEncryptedKeyStore(plain, encryptionKey)
+	if err != nil {
+		t.Fatalf("NewEncryptedKeyStore failed: %v", err)
+	}
+
+	config := KeyManagerConfig{Algorithm: AlgorithmEdDSA, OverlapWindow: time.Hour}
+	keys, err := NewKeyManager(config, store)
+	if err != nil {
+		t.Fatalf("NewKeyManager failed: %v", err)
+	}
+	tokenString, err := keys.Sign(testClaims())
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	stored, _ := plain.List(ctx, time.Time{})
+	if len(stored) != 1 {
+		t.Fatalf("Expected one stored key, got %d", len(stored))
+	}
+	if _, err := x509.ParsePKCS8PrivateKey(stored[0].PrivateKey); err == nil {
+		t.Error("Expected the stored private key to be encrypted")
+	}
+
+	// Another process with the same encryption key verifies the token
+	reloaded, err := NewKeyManager(config, store)
+	if err != nil {
+		t.Fatalf("NewKeyManager failed: %v", err)
+	}
+	if _, err := jwt.Parse(tokenString, reloaded.Keyfunc); err != nil {
+		t.Errorf("Token rejected after reloading the encrypted key: %v", err)
+	}
+
+	// Without the encryption key the stored keys are useless
+	wrong, _ := NewEncryptedKeyStore(plain, bytes.Repeat([]byte{8}, KeyEncryptionKeySize))
+	if _, err := NewKeyManager(config, wrong); err == nil {
+		t.Error("Expected loading with another encryption key to fail")
+	}
+
+	// Key material moved to another key's row does not decrypt
+	stored[0].ID = "moved"
+	moved := NewMemoryKeyStore()
+	_ = moved.Create(ctx, stored[0])
+	relabeled, _ := NewEncryptedKeyStore(moved, encryptionKey)
+	if _, err := relabeled.List(ctx, time.Time{}); err == nil {
+		t.Error("Expected key material under another ID to be rejected")
+	}
+}
+
+func TestNewEncryptedKeyStore_RequiresAES256Key(t *testing.T) {
+	for _, size := range []int{0, 16, 31, 33} {
+		if _, err := NewEncryptedKeyStore(NewMemoryKeyStore(), make([]byte, size)); !errors.Is(err, ErrInvalidKeyEncryptionKey) {
+			t.Errorf("Expected ErrInvalidKeyEncryptionKey for a %d byte key, got %v", size, err)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token has expired")
+)
+
+// Access is what a user may do: the roles granted to them and the permissions those roles
+// carry. Permissions apply at every store; StorePermissions holds those of roles granted
+// for a single store. It is embedded in access tokens, so changes to a user's roles take
+// effect when their access token is next refreshed.
+type Access struct {
+	Roles            []string               `json:"roles,omitempty"`
+	Permissions      []string               `json:"permissions,omitempty"`
+	StorePermissions map[uuid.UUID][]string `json:"store_permissions,omitempty"`
+}
+
+// HasRole reports whether the role has been granted
+func (a Access) HasRole(role string) bool {
+	for _, r := range a.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission reports whether any role granted for every store carries the permission
+func (a Access) HasPermission(permission string) bool {
+	return containsPermission(a.Permissions, permission)
+}
+
+// HasStorePermission reports whether the permission is held at the store, either through
+// a role granted for every store or one granted for that store
+func (a Access) HasStorePermission(storeID uuid.UUID, permission string) bool {
+	return a.HasPermission(permission) || containsPermission(a.StorePermissions[storeID], permission)
+}
+
+// HasPermissionAtAnyStore reports whether the permission is held at one store or more
+func (a Access) HasPermissionAtAnyStore(permission string) bool {
+	if a.HasPermission(permission) {
+		return true
+	}
+	for _, permissions := range a.StorePermissions {
+		if containsPermission(permissions, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsPermission reports whether permissions includes permission
+func containsPermission(permissions []string, permission string) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Claims represents the JWT claims of an access token
+type Claims struct {
+	UserID uuid.UUID `json:"user_id"`
+	Access
+	jwt.RegisteredClaims
+}
+
+// Principal is the caller authenticated by a verified access token
+type Principal struct {
+	UserID uuid.UUID
+	Access
+	TokenID string
+	// ExpiresAt is when the access token expires; zero for tokens without an expiry
+	ExpiresAt time.Time
+}
+
+// Principal returns the caller the claims authenticate
+func (c *Claims) Principal() Principal {
+	principal := Principal{
+		UserID:  c.UserID,
+		Access:  c.Access,
+		TokenID: c.ID,
+	}
+	if c.ExpiresAt != nil {
+		principal.ExpiresAt = c.ExpiresAt.Time
+	}
+	return principal
+}
+
+// TokenConfig holds the claims every access token is issued with and checked against
+type TokenConfig struct {
+	Issuer   string
+	Audience string
+	// Leeway is the clock skew tolerated when checking exp, nbf and iat
+	Leeway time.Duration
+}
+
+// TokenVerifier verifies access tokens
+type TokenVerifier interface {
+	// Verify checks the signature, issuer, audience and validity window of an access token.
+	// It returns ErrTokenExpired for expired tokens and an error matching ErrInvalidToken
+	// for any other rejection.
+	Verify(tokenString string) (*Claims, error)
+}
+
+// TokenManager issues access tokens and verifies them for every consumer
+type TokenManager interface {
+	TokenVerifier
+	// Issue signs an access token for a user that is valid for ttl
+	Issue(userID uuid.UUID, access Access, ttl time.Duration) (string, error)
+}
+
+type tokenManager struct {
+	keys   KeyManager
+	config TokenConfig
+	parser *jwt.Parser
+}
+
+// NewTokenManager creates a TokenManager signing and verifying with keys
+func NewTokenManager(keys KeyManager, config TokenConfig) TokenManager {
+	return &tokenManager{
+		keys:   keys,
+		config: config,
+		parser: jwt.NewParser(
+			jwt.WithIssuer(config.Issuer),
+			jwt.WithAudience(config.Audience),
+			jwt.WithLeeway(config.Leeway),
+			jwt.WithExpirationRequired(),
+			jwt.WithIssuedAt(),
+		),
+	}
+}
+
+// Issue signs an access token with a unique token ID
+func (m *tokenManager) Issue(userID uuid.UUID, access Access, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		UserID: userID,
+		Access: access,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Issuer:    m.config.Issuer,
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{m.config.Audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	return m.keys.Sign(claims)
+}
+
+// Verify checks an access token and returns its claims
+func (m *tokenManager) Verify(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := m.parser.ParseWithClaims(tokenString, claims, m.keys.Keyfunc)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	if claims.UserID == uuid.Nil {
+		return nil, fmt.Errorf("%w: missing user claims", ErrInvalidToken)
+	}
+
+	return claims, nil
+}
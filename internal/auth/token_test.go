@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func newTestTokenManager(t *testing.T, config TokenConfig) (TokenManager, KeyManager) {
+	t.Helper()
+	keys, err := NewKeyManager(KeyManagerConfig{Algorithm: AlgorithmEdDSA, OverlapWindow: time.Hour}, NewMemoryKeyStore())
+	if err != nil {
+		t.Fatalf("NewKeyManager failed: %v", err)
+	}
+	return NewTokenManager(keys, config), keys
+}
+
+func TestTokenManager_IssuedTokensVerifyToPrincipal(t *testing.T) {
+	tokens, _ := newTestTokenManager(t, TokenConfig{Issuer: "pizza-must", Audience: "pizza-must-api"})
+	userID := uuid.New()
+
+	access := Access{Roles: []string{"kitchen"}, Permissions: []string{"orders:read", "orders:transition"}}
+	tokenString, err := tokens.Issue(userID, access, time.Minute)
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+
+	claims, err := tokens.Verify(tokenString)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	principal := claims.Principal()
+	if principal.UserID != userID || principal.TokenID == "" {
+		t.Errorf("Unexpected principal %+v", principal)
+	}
+	if !principal.HasRole("kitchen") || !principal.HasPermission("orders:transition") || principal.HasPermission("refunds:issue") {
+		t.Errorf("Unexpected access %+v", principal.Access)
+	}
+	if claims.Subject != userID.String() || claims.NotBefore == nil || claims.IssuedAt == nil {
+		t.Errorf("Missing registered claims: %+v", claims.RegisteredClaims)
+	}
+
+	// Every token gets its own ID
+	other, _ := tokens.Issue(userID, Access{}, time.Minute)
+	otherClaims, _ := tokens.Verify(other)
+	if otherClaims.ID == claims.ID {
+		t.Errorf("Expected distinct token IDs")
+	}
+}
+
+func TestTokenManager_StorePermissionsApplyOnlyAtTheirStore(t *testing.T) {
+	tokens, _ := newTestTokenManager(t, TokenConfig{Issuer: "pizza-must", Audience: "pizza-must-api"})
+	store, otherStore := uuid.New(), uuid.New()
+
+	access := Access{
+		Roles:            []string{"kitchen"},
+		Permissions:      []string{"orders:read"},
+		StorePermissions: map[uuid.UUID][]string{store: {"orders:read", "orders:transition"}},
+	}
+	tokenString, err := tokens.Issue(uuid.New(), access, time.Minute)
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	claims, err := tokens.Verify(tokenString)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	if !claims.HasStorePermission(store, "orders:transition") || claims.HasStorePermission(otherStore, "orders:transition") {
+		t.Errorf("orders:transition should only apply at its store, got %+v", claims.Access)
+	}
+	if !claims.HasStorePermission(otherStore, "orders:read") {
+		t.Errorf("global permissions should apply at every store")
+	}
+	if claims.HasPermission("orders:transition") || !claims.HasPermissionAtAnyStore("orders:transition") {
+		t.Errorf("store permissions must not count as global ones")
+	}
+}
+
+func TestTokenManager_RejectsTokensFailingClaimChecks(t *testing.T) {
+	config := TokenConfig{Issuer: "pizza-must", Audience: "pizza-must-api", Leeway: 30 * time.Second}
+	tokens, keys := newTestTokenManager(t, config)
+	now := time.Now()
+	userID := uuid.New()
+
+	sign := func(mutate func(claims *Claims)) string {
+		claims := &Claims{
+			UserID: userID,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    config.Issuer,
+				Audience:  jwt.ClaimStrings{config.Audience},
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+				NotBefore: jwt.NewNumericDate(now),
+				IssuedAt:  jwt.NewNumericDate(now),
+			},
+		}
+		mutate(claims)
+		signed, err := keys.Sign(claims)
+		if err != nil {
+			t.Fatalf("Sign failed: %v", err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{"expired", sign(func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute)) }), ErrTokenExpired},
+		{"wrong issuer", sign(func(c *Claims) { c.Issuer = "someone-else" }), ErrInvalidToken},
+		{"wrong audience", sign(func(c *Claims) { c.Audience = jwt.ClaimStrings{"other-api"} }), ErrInvalidToken},
+		{"not yet valid", sign(func(c *Claims) { c.NotBefore = jwt.NewNumericDate(now.Add(time.Minute)) }), ErrInvalidToken},
+		{"no expiry", sign(func(c *Claims) { c.ExpiresAt = nil }), ErrInvalidToken},
+		{"no user", sign(func(c *Claims) { c.UserID = uuid.Nil }), ErrInvalidToken},
+		{"malformed", "not-a-token", ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tokens.Verify(tt.token); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestTokenManager_ToleratesClockSkewWithinLeeway(t *testing.T) {
+	config := TokenConfig{Issuer: "pizza-must", Audience: "pizza-must-api", Leeway: 30 * time.Second}
+	tokens, keys := newTestTokenManager(t, config)
+	now := time.Now()
+
+	// Issued by a server whose clock runs 10 seconds ahead
+	signed, _ := keys.Sign(&Claims{
+		UserID: uuid.New(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    config.Issuer,
+			Audience:  jwt.ClaimStrings{config.Audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			NotBefore: jwt.NewNumericDate(now.Add(10 * time.Second)),
+			IssuedAt:  jwt.NewNumericDate(now.Add(10 * time.Second)),
+		},
+	})
+
+	if _, err := tokens.Verify(signed); err != nil {
+		t.Errorf("Token within leeway rejected: %v", err)
+	}
+}
@@ -2,20 +2,26 @@ package config
 
 import (
 	"log"
+	"strings"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
+	Server       ServerConfig
+	Database     DatabaseConfig
+	Redis        RedisConfig
+	JWT          JWTConfig
+	Mail         MailConfig
+	Password     PasswordConfig
+	Verification VerificationConfig
+	Login        LoginConfig
 }
 
 type ServerConfig struct {
-	Port string
-	Env  string
+	Port           string
+	Env            string
+	TrustedProxies []string // CIDR ranges or IPs of reverse proxies allowed to report the client IP
 }
 
 type DatabaseConfig struct {
@@ -35,9 +41,55 @@ type RedisConfig struct {
 }
 
 type JWTConfig struct {
-	Secret        string
-	AccessExpiry  int // in minutes
-	RefreshExpiry int // in days
+	Issuer         string // iss claim of issued tokens, required when verifying
+	Audience       string // aud claim of issued tokens, required when verifying
+	LeewaySeconds  int    // clock skew tolerated when checking token times
+	Algorithm      string // RS256 or EdDSA
+	RotationHours  int    // how long a signing key is used before it is rotated
+	OverlapMinutes int    // how long a rotated key keeps verifying tokens
+	// KeyEncryptionKey is the base64 encoded 32 byte key encrypting the stored signing keys;
+	// the server refuses to start without it
+	KeyEncryptionKey string
+	AccessExpiry     int // in minutes
+	RefreshExpiry    int // in days
+	SessionMaxAge    int // in days, 0 for no limit
+	IdleTimeout      int // in hours, 0 for no limit
+}
+
+type MailConfig struct {
+	Driver       string // smtp, file or log
+	From         string
+	Dir          string // where the file driver writes messages
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+}
+
+type VerificationConfig struct {
+	URL                   string // verification endpoint linked from the email
+	TTLHours              int    // how long a verification link stays usable
+	ResendCooldownSeconds int    // minimum time between two verification emails
+	RequiredForCheckout   bool   // block checkout until the email address is verified
+}
+
+type LoginConfig struct {
+	FailureWindowMinutes  int // how long a failed login is remembered
+	BaseDelaySeconds      int // delay after the first failure beyond the free attempts, doubling after each further one
+	MaxDelaySeconds       int // longest delay between failed logins
+	LockoutMinutes        int // how long a lockout lasts
+	EmailFreeAttempts     int // failures per email address before logins are delayed
+	EmailLockoutThreshold int // failures that lock an email address
+	IPFreeAttempts        int // failures per client IP before logins are delayed
+	IPLockoutThreshold    int // failures that lock a client IP
+}
+
+type PasswordConfig struct {
+	ResetURL             string // page users open to choose a new password
+	ResetTTLMinutes      int    // how long a reset link stays usable
+	ResetCooldownSeconds int    // minimum time between two reset emails to the same account
+	ResetIPLimit         int    // reset requests a client IP may make within the window, 0 for no limit
+	ResetIPWindowMinutes int    // how long a client IP must stop requesting resets for its count to restart
 }
 
 func Load() *Config {
@@ -55,8 +107,37 @@ func Load() *Config {
 	viper.SetDefault("REDIS_HOST", "localhost")
 	viper.SetDefault("REDIS_PORT", "6379")
 	viper.SetDefault("REDIS_DB", 0)
+	viper.SetDefault("JWT_ISSUER", "pizza-must")
+	viper.SetDefault("JWT_AUDIENCE", "pizza-must-api")
+	viper.SetDefault("JWT_LEEWAY_SECONDS", 30)
+	viper.SetDefault("JWT_ALGORITHM", "RS256")
+	viper.SetDefault("JWT_KEY_ROTATION_HOURS", 24)
+	viper.SetDefault("JWT_KEY_OVERLAP_MINUTES", 60)
 	viper.SetDefault("JWT_ACCESS_EXPIRY", 15)
 	viper.SetDefault("JWT_REFRESH_EXPIRY", 7)
+	viper.SetDefault("JWT_SESSION_MAX_AGE", 30)
+	viper.SetDefault("JWT_IDLE_TIMEOUT", 0)
+	viper.SetDefault("MAIL_DRIVER", "log")
+	viper.SetDefault("MAIL_FROM", "no-reply@pizza-must.local")
+	viper.SetDefault("MAIL_DIR", "tmp/mail")
+	viper.SetDefault("SMTP_PORT", "587")
+	viper.SetDefault("PASSWORD_RESET_URL", "http://localhost:3000/reset-password")
+	viper.SetDefault("PASSWORD_RESET_TTL_MINUTES", 30)
+	viper.SetDefault("PASSWORD_RESET_COOLDOWN_SECONDS", 60)
+	viper.SetDefault("PASSWORD_RESET_IP_LIMIT", 10)
+	viper.SetDefault("PASSWORD_RESET_IP_WINDOW_MINUTES", 15)
+	viper.SetDefault("EMAIL_VERIFICATION_URL", "http://localhost:8080/api/users/verify")
+	viper.SetDefault("EMAIL_VERIFICATION_TTL_HOURS", 48)
+	viper.SetDefault("EMAIL_VERIFICATION_RESEND_COOLDOWN_SECONDS", 60)
+	viper.SetDefault("EMAIL_VERIFICATION_REQUIRED_FOR_CHECKOUT", true)
+	viper.SetDefault("LOGIN_FAILURE_WINDOW_MINUTES", 15)
+	viper.SetDefault("LOGIN_BASE_DELAY_SECONDS", 1)
+	viper.SetDefault("LOGIN_MAX_DELAY_SECONDS", 30)
+	viper.SetDefault("LOGIN_LOCKOUT_MINUTES", 15)
+	viper.SetDefault("LOGIN_EMAIL_FREE_ATTEMPTS", 3)
+	viper.SetDefault("LOGIN_EMAIL_LOCKOUT_THRESHOLD", 10)
+	viper.SetDefault("LOGIN_IP_FREE_ATTEMPTS", 20)
+	viper.SetDefault("LOGIN_IP_LOCKOUT_THRESHOLD", 100)
 
 	if err := viper.ReadInConfig(); err != nil {
 		log.Printf("Warning: Could not read config file: %v", err)
@@ -64,8 +145,9 @@ func Load() *Config {
 
 	return &Config{
 		Server: ServerConfig{
-			Port: viper.GetString("SERVER_PORT"),
-			Env:  viper.GetString("SERVER_ENV"),
+			Port:           viper.GetString("SERVER_PORT"),
+			Env:            viper.GetString("SERVER_ENV"),
+			TrustedProxies: splitList(viper.GetString("SERVER_TRUSTED_PROXIES")),
 		},
 		Database: DatabaseConfig{
 			Host:     viper.GetString("DB_HOST"),
@@ -82,9 +164,60 @@ func Load() *Config {
 			DB:       viper.GetInt("REDIS_DB"),
 		},
 		JWT: JWTConfig{
-			Secret:        viper.GetString("JWT_SECRET"),
-			AccessExpiry:  viper.GetInt("JWT_ACCESS_EXPIRY"),
-			RefreshExpiry: viper.GetInt("JWT_REFRESH_EXPIRY"),
+			Issuer:           viper.GetString("JWT_ISSUER"),
+			Audience:         viper.GetString("JWT_AUDIENCE"),
+			LeewaySeconds:    viper.GetInt("JWT_LEEWAY_SECONDS"),
+			Algorithm:        viper.GetString("JWT_ALGORITHM"),
+			RotationHours:    viper.GetInt("JWT_KEY_ROTATION_HOURS"),
+			OverlapMinutes:   viper.GetInt("JWT_KEY_OVERLAP_MINUTES"),
+			KeyEncryptionKey: viper.GetString("JWT_KEY_ENCRYPTION_KEY"),
+			AccessExpiry:     viper.GetInt("JWT_ACCESS_EXPIRY"),
+			RefreshExpiry:    viper.GetInt("JWT_REFRESH_EXPIRY"),
+			SessionMaxAge:    viper.GetInt("JWT_SESSION_MAX_AGE"),
+			IdleTimeout:      viper.GetInt("JWT_IDLE_TIMEOUT"),
 		},
+		Mail: MailConfig{
+			Driver:       viper.GetString("MAIL_DRIVER"),
+			From:         viper.GetString("MAIL_FROM"),
+			Dir:          viper.GetString("MAIL_DIR"),
+			SMTPHost:     viper.GetString("SMTP_HOST"),
+			SMTPPort:     viper.GetString("SMTP_PORT"),
+			SMTPUsername: viper.GetString("SMTP_USERNAME"),
+			SMTPPassword: viper.GetString("SMTP_PASSWORD"),
+		},
+		Password: PasswordConfig{
+			ResetURL:             viper.GetString("PASSWORD_RESET_URL"),
+			ResetTTLMinutes:      viper.GetInt("PASSWORD_RESET_TTL_MINUTES"),
+			ResetCooldownSeconds: viper.GetInt("PASSWORD_RESET_COOLDOWN_SECONDS"),
+			ResetIPLimit:         viper.GetInt("PASSWORD_RESET_IP_LIMIT"),
+			ResetIPWindowMinutes: viper.GetInt("PASSWORD_RESET_IP_WINDOW_MINUTES"),
+		},
+		Verification: VerificationConfig{
+			URL:                   viper.GetString("EMAIL_VERIFICATION_URL"),
+			TTLHours:              viper.GetInt("EMAIL_VERIFICATION_TTL_HOURS"),
+			ResendCooldownSeconds: viper.GetInt("EMAIL_VERIFICATION_RESEND_COOLDOWN_SECONDS"),
+			RequiredForCheckout:   viper.GetBool("EMAIL_VERIFICATION_REQUIRED_FOR_CHECKOUT"),
+		},
+		Login: LoginConfig{
+			FailureWindowMinutes:  viper.GetInt("LOGIN_FAILURE_WINDOW_MINUTES"),
+			BaseDelaySeconds:      viper.GetInt("LOGIN_BASE_DELAY_SECONDS"),
+			MaxDelaySeconds:       viper.GetInt("LOGIN_MAX_DELAY_SECONDS"),
+			LockoutMinutes:        viper.GetInt("LOGIN_LOCKOUT_MINUTES"),
+			EmailFreeAttempts:     viper.GetInt("LOGIN_EMAIL_FREE_ATTEMPTS"),
+			EmailLockoutThreshold: viper.GetInt("LOGIN_EMAIL_LOCKOUT_THRESHOLD"),
+			IPFreeAttempts:        viper.GetInt("LOGIN_IP_FREE_ATTEMPTS"),
+			IPLockoutThreshold:    viper.GetInt("LOGIN_IP_LOCKOUT_THRESHOLD"),
+		},
+	}
+}
+
+// splitList splits a comma separated setting, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
 }
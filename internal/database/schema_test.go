@@ -27,6 +27,28 @@ func TestMigrationFilesExist(t *testing.T) {
 		"00006_create_orders_table.sql",
 		"00007_create_order_items_table.sql",
 		"00008_create_updated_at_trigger.sql",
+		"00009_create_order_status_history_table.sql",
+		"00010_create_product_options_tables.sql",
+		"00011_add_options_to_cart_and_order_items.sql",
+		"00012_add_half_and_half_selections.sql",
+		"00013_create_promotions_tables.sql",
+		"00014_add_refresh_token_families.sql",
+		"00015_hash_refresh_tokens.sql",
+		"00016_add_refresh_token_sessions.sql",
+		"00017_add_refresh_token_session_start.sql",
+		"00018_create_password_reset_tokens_table.sql",
+		"00019_add_email_verification.sql",
+		"00020_add_user_profile_and_deletion.sql",
+		"00021_create_login_throttles_table.sql",
+		"00022_create_roles_and_permissions.sql",
+		"00023_create_stores.sql",
+		"00024_add_store_schedules.sql",
+		"00025_add_fulfillment_and_delivery_zones.sql",
+		"00026_add_kitchen_workflow.sql",
+		"00027_create_signing_keys_table.sql",
+		"00028_throttle_password_reset_requests.sql",
+		"00029_add_order_cancel_permission.sql",
+		"00030_encrypt_signing_keys.sql",
 	}
 
 	for _, migration := range expectedMigrations {
@@ -89,13 +111,34 @@ func TestMigrationFilesCreateExpectedTables(t *testing.T) {
 	migrationsDir := "../../migrations"
 
 	expectedTables := map[string]string{
-		"users":          "00001_create_users_table.sql",
-		"refresh_tokens": "00002_create_refresh_tokens_table.sql",
-		"categories":     "00003_create_categories_table.sql",
-		"products":       "00004_create_products_table.sql",
-		"cart_items":     "00005_create_cart_items_table.sql",
-		"orders":         "00006_create_orders_table.sql",
-		"order_items":    "00007_create_order_items_table.sql",
+		"users":                     "00001_create_users_table.sql",
+		"refresh_tokens":            "00002_create_refresh_tokens_table.sql",
+		"categories":                "00003_create_categories_table.sql",
+		"products":                  "00004_create_products_table.sql",
+		"cart_items":                "00005_create_cart_items_table.sql",
+		"orders":                    "00006_create_orders_table.sql",
+		"order_items":               "00007_create_order_items_table.sql",
+		"order_status_history":      "00009_create_order_status_history_table.sql",
+		"product_option_groups":     "00010_create_product_options_tables.sql",
+		"product_options":           "00010_create_product_options_tables.sql",
+		"promotions":                "00013_create_promotions_tables.sql",
+		"promotion_redemptions":     "00013_create_promotions_tables.sql",
+		"cart_coupons":              "00013_create_promotions_tables.sql",
+		"password_reset_tokens":     "00018_create_password_reset_tokens_table.sql",
+		"email_verification_tokens": "00019_add_email_verification.sql",
+		"login_throttles":           "00021_create_login_throttles_table.sql",
+		"permissions":               "00022_create_roles_and_permissions.sql",
+		"roles":                     "00022_create_roles_and_permissions.sql",
+		"role_permissions":          "00022_create_roles_and_permissions.sql",
+		"user_roles":                "00022_create_roles_and_permissions.sql",
+		"stores":                    "00023_create_stores.sql",
+		"store_opening_hours":       "00023_create_stores.sql",
+		"store_products":            "00023_create_stores.sql",
+		"carts":                     "00023_create_stores.sql",
+		"store_hours_exceptions":    "00024_add_store_schedules.sql",
+		"addresses":                 "00025_add_fulfillment_and_delivery_zones.sql",
+		"delivery_zones":            "00025_add_fulfillment_and_delivery_zones.sql",
+		"signing_keys":              "00027_create_signing_keys_table.sql",
 	}
 
 	for tableName, migrationFile := range expectedTables {
@@ -220,3 +263,18 @@ func TestCartItemsTableHasUniqueConstraint(t *testing.T) {
 		t.Error("Cart items table missing unique constraint on (user_id, product_id)")
 	}
 }
+
+func TestStoreSchedulesSeedOpeningHours(t *testing.T) {
+	migrationsDir := "../../migrations"
+	path := filepath.Join(migrationsDir, "00024_add_store_schedules.sql")
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read store schedules migration: %v", err)
+	}
+
+	// Stores without hours would refuse every checkout once schedules are enforced
+	if !strings.Contains(string(content), "INSERT INTO store_opening_hours") {
+		t.Error("Store schedules migration does not give existing stores opening hours")
+	}
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Address is an entry in a user's address book. Latitude and Longitude locate it for
+// delivery zones.
+type Address struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	UserID       uuid.UUID `json:"user_id" db:"user_id"`
+	Label        string    `json:"label,omitempty" db:"label"`
+	AddressLine1 string    `json:"address_line1" db:"address_line1"`
+	AddressLine2 string    `json:"address_line2,omitempty" db:"address_line2"`
+	City         string    `json:"city" db:"city"`
+	PostalCode   string    `json:"postal_code" db:"postal_code"`
+	Country      string    `json:"country" db:"country"`
+	Latitude     float64   `json:"latitude" db:"latitude"`
+	Longitude    float64   `json:"longitude" db:"longitude"`
+	Phone        string    `json:"phone,omitempty" db:"phone"`
+	Instructions string    `json:"instructions,omitempty" db:"instructions"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Point returns the address's location
+func (a *Address) Point() GeoPoint {
+	return GeoPoint{Latitude: a.Latitude, Longitude: a.Longitude}
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CartItem represents a product, its chosen options and quantity in a user's shopping cart.
+// Selections are kept sorted so identical choices map to the same line.
+type CartItem struct {
+	ID         uuid.UUID         `json:"id" db:"id"`
+	UserID     uuid.UUID         `json:"user_id" db:"user_id"`
+	ProductID  uuid.UUID         `json:"product_id" db:"product_id"`
+	Selections []OptionSelection `json:"selections" db:"selections"`
+	Quantity   int               `json:"quantity" db:"quantity"`
+	CreatedAt  time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// CartLine is a cart item joined with the product's current name, category, price and stock
+// at the cart's store. Products the store does not sell have no stock there.
+// Options, UnitPrice and Subtotal are filled in by the pricing engine.
+type CartLine struct {
+	CartItem
+	ProductName string           `json:"product_name" db:"product_name"`
+	CategoryID  uuid.UUID        `json:"category_id" db:"category_id"`
+	BasePrice   Money            `json:"base_price" db:"price"`
+	Options     []SelectedOption `json:"options"`
+	UnitPrice   Money            `json:"unit_price"`
+	Stock       int              `json:"stock" db:"stock"`
+	Subtotal    Money            `json:"subtotal"`
+}
+
+// Cart represents a user's shopping cart with its price breakdown. Items are priced and
+// stocked at the store the cart is filled at; StoreID is nil until one is selected.
+// Total is the subtotal less the discount of the applied coupon, if any.
+type Cart struct {
+	UserID   uuid.UUID      `json:"user_id"`
+	StoreID  *uuid.UUID     `json:"store_id"`
+	Items    []*CartLine    `json:"items"`
+	Subtotal Money          `json:"subtotal"`
+	Discount Money          `json:"discount"`
+	Total    Money          `json:"total"`
+	Coupon   *AppliedCoupon `json:"coupon,omitempty"`
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// earthRadiusMeters is the mean radius of the Earth used for distances between points
+const earthRadiusMeters = 6371000
+
+// GeoPoint is a location in degrees
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// DeliveryZone is an area a store delivers to: either RadiusMeters around the store or,
+// when RadiusMeters is zero, the Polygon of points. Orders delivered into the zone must
+// reach MinOrderValue before discounts and are charged DeliveryFee.
+type DeliveryZone struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	StoreID       uuid.UUID  `json:"store_id" db:"store_id"`
+	Name          string     `json:"name" db:"name"`
+	RadiusMeters  int        `json:"radius_meters,omitempty" db:"radius_meters"`
+	Polygon       []GeoPoint `json:"polygon,omitempty" db:"polygon"`
+	MinOrderValue Money      `json:"min_order_value" db:"min_order_value"`
+	DeliveryFee   Money      `json:"delivery_fee" db:"delivery_fee"`
+	Active        bool       `json:"active" db:"active"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Point returns the store's location
+func (s *Store) Point() GeoPoint {
+	return GeoPoint{Latitude: s.Latitude, Longitude: s.Longitude}
+}
+
+// Contains reports whether point lies in the zone of a store located at store
+func (z *DeliveryZone) Contains(store, point GeoPoint) bool {
+	if z.RadiusMeters > 0 {
+		return DistanceMeters(store, point) <= float64(z.RadiusMeters)
+	}
+	return polygonContains(z.Polygon, point)
+}
+
+// ServingZone returns the active zone containing point with the lowest delivery fee, or
+// nil when none of the zones of the store located at store reaches it
+func ServingZone(zones []*DeliveryZone, store, point GeoPoint) *DeliveryZone {
+	var serving *DeliveryZone
+	for _, zone := range zones {
+		if !zone.Active || !zone.Contains(store, point) {
+			continue
+		}
+		if serving == nil || zone.DeliveryFee.Amount < serving.DeliveryFee.Amount {
+			serving = zone
+		}
+	}
+	return serving
+}
+
+// DistanceMeters returns the great-circle distance between two points
+func DistanceMeters(a, b GeoPoint) float64 {
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLng := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// polygonContains reports whether point lies inside polygon by casting a ray along its
+// latitude. Coordinates are treated as planar, which holds for zones the size of a city.
+func polygonContains(polygon []GeoPoint, point GeoPoint) bool {
+	if len(polygon) < 3 {
+		return false
+	}
+
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Latitude > point.Latitude) == (b.Latitude > point.Latitude) {
+			continue
+		}
+		crossing := a.Longitude + (point.Latitude-a.Latitude)*(b.Longitude-a.Longitude)/(b.Latitude-a.Latitude)
+		if point.Longitude < crossing {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+var testStorePoint = GeoPoint{Latitude: 41.9028, Longitude: 12.4964}
+
+func TestDeliveryZone_Contains(t *testing.T) {
+	radius := &DeliveryZone{RadiusMeters: 2000}
+	// A square around the store with a notch cut out of its north-east corner
+	polygon := &DeliveryZone{Polygon: []GeoPoint{
+		{Latitude: 41.88, Longitude: 12.47},
+		{Latitude: 41.88, Longitude: 12.52},
+		{Latitude: 41.90, Longitude: 12.52},
+		{Latitude: 41.90, Longitude: 12.50},
+		{Latitude: 41.92, Longitude: 12.50},
+		{Latitude: 41.92, Longitude: 12.47},
+	}}
+
+	tests := []struct {
+		name  string
+		zone  *DeliveryZone
+		point GeoPoint
+		want  bool
+	}{
+		{"the store itself", radius, testStorePoint, true},
+		{"about a kilometre away", radius, GeoPoint{Latitude: 41.9118, Longitude: 12.4964}, true},
+		{"about three kilometres away", radius, GeoPoint{Latitude: 41.9298, Longitude: 12.4964}, false},
+		{"inside the polygon", polygon, GeoPoint{Latitude: 41.89, Longitude: 12.51}, true},
+		{"in the notch", polygon, GeoPoint{Latitude: 41.91, Longitude: 12.51}, false},
+		{"outside the polygon", polygon, GeoPoint{Latitude: 41.95, Longitude: 12.48}, false},
+		{"degenerate polygon", &DeliveryZone{Polygon: []GeoPoint{{}, {Latitude: 1}}}, GeoPoint{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.zone.Contains(testStorePoint, tt.point); got != tt.want {
+				t.Errorf("expected contains %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestServingZone_PicksCheapestActiveZone(t *testing.T) {
+	near := &DeliveryZone{Name: "near", RadiusMeters: 1000, DeliveryFee: Cents(200), Active: true}
+	far := &DeliveryZone{Name: "far", RadiusMeters: 5000, DeliveryFee: Cents(500), Active: true}
+	free := &DeliveryZone{Name: "free", RadiusMeters: 5000, DeliveryFee: Cents(0), Active: false}
+	zones := []*DeliveryZone{far, near, free}
+
+	if zone := ServingZone(zones, testStorePoint, testStorePoint); zone != near {
+		t.Errorf("expected the near zone at the store, got %v", zone)
+	}
+	if zone := ServingZone(zones, testStorePoint, GeoPoint{Latitude: 41.9298, Longitude: 12.4964}); zone != far {
+		t.Errorf("expected the far zone three kilometres away, got %v", zone)
+	}
+	if zone := ServingZone(zones, testStorePoint, GeoPoint{Latitude: 45.4642, Longitude: 9.19}); zone != nil {
+		t.Errorf("expected no zone in another city, got %v", zone)
+	}
+}
+
+// Feature: ordering-platform, Property 92: Polygon containment does not depend on vertex order
+func TestProperty_PolygonContainmentIgnoresVertexOrder(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("rotating or reversing the vertices of a zone keeps the same points inside", prop.ForAll(
+		func(coordinates []float64, rotation int, latitude, longitude float64) bool {
+			polygon := []GeoPoint{}
+			for i := 0; i+1 < len(coordinates); i += 2 {
+				polygon = append(polygon, GeoPoint{Latitude: coordinates[i], Longitude: coordinates[i+1]})
+			}
+			point := GeoPoint{Latitude: latitude, Longitude: longitude}
+			want := polygonContains(polygon, point)
+
+			rotated := append([]GeoPoint{}, polygon...)
+			if len(rotated) > 0 {
+				shift := rotation % len(rotated)
+				rotated = append(rotated[shift:], rotated[:shift]...)
+			}
+			reversed := make([]GeoPoint, len(polygon))
+			for i, p := range polygon {
+				reversed[len(polygon)-1-i] = p
+			}
+
+			if polygonContains(rotated, point) != want || polygonContains(reversed, point) != want {
+				t.Logf("FAIL: Containment of %v in %v changed with the vertex order", point, polygon)
+				return false
+			}
+			return true
+		},
+		gen.SliceOfN(12, gen.Float64Range(0, 1)),
+		gen.IntRange(0, 10),
+		gen.Float64Range(0, 1),
+		gen.Float64Range(0, 1),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
@@ -0,0 +1,84 @@
+package domain
+
+import "github.com/google/uuid"
+
+// Kitchen statuses of a product line, in the order lines go through them
+const (
+	KitchenItemQueued    = "queued"
+	KitchenItemPreparing = "preparing"
+	KitchenItemReady     = "ready"
+)
+
+// kitchenItemSteps ranks the kitchen statuses; lines only move forward
+var kitchenItemSteps = map[string]int{
+	KitchenItemQueued:    0,
+	KitchenItemPreparing: 1,
+	KitchenItemReady:     2,
+}
+
+// Types of order event
+const (
+	// OrderEventCreated is published when an order is placed
+	OrderEventCreated = "order.created"
+	// OrderEventUpdated is published when an order's status or its lines' kitchen status change
+	OrderEventUpdated = "order.updated"
+)
+
+// KitchenQueue lists a store's orders waiting for or being prepared by the kitchen,
+// oldest first
+type KitchenQueue struct {
+	StoreID uuid.UUID `json:"store_id"`
+	Orders  []*Order  `json:"orders"`
+}
+
+// OrderEvent reports a new or changed order, with its items
+type OrderEvent struct {
+	Type  string `json:"type"`
+	Order *Order `json:"order"`
+}
+
+// IsValidKitchenItemStatus reports whether status is a known kitchen status
+func IsValidKitchenItemStatus(status string) bool {
+	_, ok := kitchenItemSteps[status]
+	return ok
+}
+
+// CanAdvanceKitchenItem reports whether a line may move from one kitchen status to
+// another. Lines may skip preparing but never go back.
+func CanAdvanceKitchenItem(from, to string) bool {
+	fromStep, ok := kitchenItemSteps[from]
+	if !ok {
+		return false
+	}
+	toStep, ok := kitchenItemSteps[to]
+	return ok && toStep > fromStep
+}
+
+// KitchenProgress returns the order status the kitchen statuses of the order's product
+// lines call for: ready once every line is ready, preparing once any line has been
+// started and confirmed otherwise
+func (o *Order) KitchenProgress() string {
+	started, ready, lines := 0, 0, 0
+	for _, item := range o.Items {
+		if item.Type != OrderItemProduct {
+			continue
+		}
+		lines++
+		switch item.KitchenStatus {
+		case KitchenItemPreparing:
+			started++
+		case KitchenItemReady:
+			started++
+			ready++
+		}
+	}
+
+	switch {
+	case lines > 0 && ready == lines:
+		return OrderStatusReady
+	case started > 0:
+		return OrderStatusPreparing
+	default:
+		return OrderStatusConfirmed
+	}
+}
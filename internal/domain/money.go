@@ -0,0 +1,240 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultCurrency is the ISO 4217 code of the currency the store sells in
+	DefaultCurrency = "USD"
+
+	// minorUnitDigits is the number of decimal places stored for every amount,
+	// matching the DECIMAL(10, 2) price columns
+	minorUnitDigits = 2
+	minorUnitsScale = 100
+)
+
+var (
+	ErrInvalidMoney  = errors.New("invalid money amount")
+	ErrMoneyOverflow = errors.New("money amount out of range")
+)
+
+// Money is an exact monetary amount held as an integer number of minor units (cents).
+// Amounts are encoded in JSON and SQL as decimal strings such as "12.50" so no value
+// ever passes through a float.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// NewMoney returns amount minor units of currency
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Cents returns amount minor units of the default currency
+func Cents(amount int64) Money {
+	return NewMoney(amount, DefaultCurrency)
+}
+
+// ParseMoney parses a decimal string such as "12.5" or "-0.99" in the given currency.
+// Amounts with more decimal places than the currency's minor unit are rejected rather
+// than silently rounded.
+func ParseMoney(s, currency string) (Money, error) {
+	text := strings.TrimSpace(s)
+	negative := false
+	if strings.HasPrefix(text, "-") || strings.HasPrefix(text, "+") {
+		negative = text[0] == '-'
+		text = text[1:]
+	}
+
+	whole, fraction, hasPoint := strings.Cut(text, ".")
+	if whole == "" && fraction == "" || hasPoint && fraction == "" {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+	if len(fraction) > minorUnitDigits {
+		return Money{}, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidMoney, s, minorUnitDigits)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	fraction += strings.Repeat("0", minorUnitDigits-len(fraction))
+
+	major, err := strconv.ParseUint(whole, 10, 63)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+	minor, err := strconv.ParseUint(fraction, 10, 63)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+	if major > (math.MaxInt64-minor)/minorUnitsScale {
+		return Money{}, fmt.Errorf("%w: %q is out of range", ErrInvalidMoney, s)
+	}
+
+	amount := int64(major*minorUnitsScale + minor)
+	if negative {
+		amount = -amount
+	}
+	return NewMoney(amount, currency), nil
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsNegative reports whether the amount is below zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Add returns m + other. A zero value without a currency takes the other operand's currency.
+// Adding different currencies is a programming error and panics.
+func (m Money) Add(other Money) Money {
+	return NewMoney(m.Amount+other.Amount, m.currencyWith(other))
+}
+
+// Sub returns m - other under the same currency rules as Add
+func (m Money) Sub(other Money) Money {
+	return NewMoney(m.Amount-other.Amount, m.currencyWith(other))
+}
+
+// Mul returns m multiplied by a whole quantity, or ErrMoneyOverflow when the result does
+// not fit in an amount
+func (m Money) Mul(quantity int64) (Money, error) {
+	product, ok := mulInt64(m.Amount, quantity)
+	if !ok {
+		return Money{}, ErrMoneyOverflow
+	}
+	return NewMoney(product, m.Currency), nil
+}
+
+// MulRatio returns m * numerator / denominator rounded to the nearest minor unit, with
+// halves rounded away from zero, or ErrMoneyOverflow when m * numerator does not fit in an
+// amount. It is used for shares and percentages of a price; denominator must be positive.
+func (m Money) MulRatio(numerator, denominator int64) (Money, error) {
+	product, ok := mulInt64(m.Amount, numerator)
+	if !ok {
+		return Money{}, ErrMoneyOverflow
+	}
+	quotient, remainder := product/denominator, product%denominator
+	if remainder < 0 {
+		remainder = -remainder
+	}
+	if 2*remainder >= denominator {
+		if product < 0 {
+			quotient--
+		} else {
+			quotient++
+		}
+	}
+	return NewMoney(quotient, m.Currency), nil
+}
+
+// mulInt64 returns a * b and whether it fits in an int64
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return product, true
+}
+
+// String formats the amount as a decimal string with two places, such as "12.50"
+func (m Money) String() string {
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+	}
+	major := amount / minorUnitsScale
+	minor := amount % minorUnitsScale
+	if major < 0 {
+		major = -major
+	}
+	if minor < 0 {
+		minor = -minor
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, major, minorUnitDigits, minor)
+}
+
+// MarshalJSON encodes the amount as a decimal string
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON decodes a decimal string or a JSON number in the default currency.
+// Numbers are read from their literal text so they are never rounded through a float.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	} else if strings.ContainsAny(text, "eE") {
+		return fmt.Errorf("%w: %s", ErrInvalidMoney, text)
+	}
+
+	parsed, err := ParseMoney(text, DefaultCurrency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Scan implements sql.Scanner for DECIMAL columns in the default currency
+func (m *Money) Scan(src interface{}) error {
+	var text string
+	switch v := src.(type) {
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	case int64:
+		*m = Cents(v * minorUnitsScale)
+		return nil
+	case float64:
+		*m = Cents(int64(math.Round(v * minorUnitsScale)))
+		return nil
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidMoney, src)
+	}
+
+	parsed, err := ParseMoney(text, DefaultCurrency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value implements driver.Valuer by writing the exact decimal string
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// currencyWith returns the currency shared by m and other
+func (m Money) currencyWith(other Money) string {
+	switch {
+	case m.Currency == "":
+		return other.Currency
+	case other.Currency == "" || other.Currency == m.Currency:
+		return m.Currency
+	default:
+		panic(fmt.Sprintf("money: cannot combine %s and %s", m.Currency, other.Currency))
+	}
+}
@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// Feature: ordering-platform, Property 79: Money sums and encodings are exact
+func TestProperty_MoneySumsAndEncodingsAreExact(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("adding line totals gives exactly the integer sum of their cents", prop.ForAll(
+		func(prices []int64, quantities []int64) bool {
+			total := Cents(0)
+			var expected int64
+			for i, price := range prices {
+				quantity := quantities[i%len(quantities)]
+				lineTotal, err := Cents(price).Mul(quantity)
+				if err != nil {
+					t.Logf("FAIL: %d * %d reported %v", price, quantity, err)
+					return false
+				}
+				total = total.Add(lineTotal)
+				expected += price * quantity
+			}
+
+			if total != Cents(expected) {
+				t.Logf("FAIL: Sum mismatch. Expected %d cents, got %d", expected, total.Amount)
+				return false
+			}
+			return total.Sub(total) == Cents(0)
+		},
+		gen.SliceOfN(20, gen.Int64Range(1, 99999)),
+		gen.SliceOfN(20, gen.Int64Range(1, 50)),
+	))
+
+	properties.Property("decimal strings and JSON round-trip without loss", prop.ForAll(
+		func(cents int64) bool {
+			money := Cents(cents)
+
+			parsed, err := ParseMoney(money.String(), DefaultCurrency)
+			if err != nil || parsed != money {
+				t.Logf("FAIL: %s parsed as %s (%v)", money, parsed, err)
+				return false
+			}
+
+			data, err := json.Marshal(money)
+			if err != nil {
+				t.Logf("FAIL: Marshal failed: %v", err)
+				return false
+			}
+			var decoded Money
+			if err := json.Unmarshal(data, &decoded); err != nil || decoded != money {
+				t.Logf("FAIL: %s decoded as %s (%v)", data, decoded, err)
+				return false
+			}
+
+			var scanned Money
+			value, _ := money.Value()
+			if err := scanned.Scan(value); err != nil || scanned != money {
+				t.Logf("FAIL: %v scanned as %s (%v)", value, scanned, err)
+				return false
+			}
+			return true
+		},
+		gen.Int64Range(-99999999, 99999999),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// Feature: ordering-platform, Property 80: Ratios round to the nearest cent with halves away from zero
+func TestProperty_MoneyRatiosRoundToNearestCent(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("MulRatio is within half a cent of the exact value", prop.ForAll(
+		func(cents int64, numerator int64, denominator int64) bool {
+			result, err := Cents(cents).MulRatio(numerator, denominator)
+			if err != nil {
+				t.Logf("FAIL: %d * %d / %d reported %v", cents, numerator, denominator, err)
+				return false
+			}
+
+			// |result*denominator - cents*numerator| <= denominator/2, with ties going away from zero
+			diff := result.Amount*denominator - cents*numerator
+			if 2*diff > denominator || 2*diff < -denominator {
+				t.Logf("FAIL: %d * %d / %d rounded to %d", cents, numerator, denominator, result.Amount)
+				return false
+			}
+			if 2*diff == denominator && cents*numerator < 0 || 2*diff == -denominator && cents*numerator > 0 {
+				t.Logf("FAIL: %d * %d / %d rounded a half towards zero", cents, numerator, denominator)
+				return false
+			}
+			return true
+		},
+		gen.Int64Range(-100000, 100000),
+		gen.Int64Range(0, 100),
+		gen.Int64Range(1, 100),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestMoneyMul_DetectsOverflow(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		quantity int64
+	}{
+		{"large quantity", 1000, math.MaxInt64 / 100},
+		{"large amount", math.MaxInt64, 2},
+		{"negative", -1000, math.MaxInt64 / 100},
+		{"minimum by minus one", math.MinInt64, -1},
+	}
+	for _, tt := range tests {
+		if _, err := Cents(tt.amount).Mul(tt.quantity); err != ErrMoneyOverflow {
+			t.Errorf("%s: expected ErrMoneyOverflow from Mul, got %v", tt.name, err)
+		}
+		if _, err := Cents(tt.amount).MulRatio(tt.quantity, 3); err != ErrMoneyOverflow {
+			t.Errorf("%s: expected ErrMoneyOverflow from MulRatio, got %v", tt.name, err)
+		}
+	}
+
+	if total, err := Cents(math.MaxInt64 / 2).Mul(2); err != nil || total.Amount != math.MaxInt64-1 {
+		t.Errorf("expected the largest even amount, got %d (%v)", total.Amount, err)
+	}
+}
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+		wantErr  bool
+	}{
+		{"12.5", 1250, false},
+		{"12.50", 1250, false},
+		{"0.99", 99, false},
+		{".5", 50, false},
+		{"-3.07", -307, false},
+		{"7", 700, false},
+		{"1.005", 0, true},
+		{"12.", 0, true},
+		{"", 0, true},
+		{"abc", 0, true},
+		{"1e3", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			money, err := ParseMoney(tt.input, DefaultCurrency)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidMoney) {
+					t.Errorf("Expected ErrInvalidMoney, got %v", err)
+				}
+				return
+			}
+			if err != nil || money != Cents(tt.expected) {
+				t.Errorf("Expected %d cents, got %s (%v)", tt.expected, money, err)
+			}
+		})
+	}
+}
+
+func TestMoney_UnmarshalJSONAcceptsNumbers(t *testing.T) {
+	var request struct {
+		Price Money `json:"price"`
+	}
+	if err := json.Unmarshal([]byte(`{"price": 19.99}`), &request); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if request.Price != Cents(1999) {
+		t.Errorf("Expected 19.99, got %s", request.Price)
+	}
+
+	data, _ := json.Marshal(request)
+	if string(data) != `{"price":"19.99"}` {
+		t.Errorf("Expected price encoded as a decimal string, got %s", data)
+	}
+}
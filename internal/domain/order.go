@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Fulfillment types of an order
+const (
+	FulfillmentPickup   = "pickup"
+	FulfillmentDelivery = "delivery"
+)
+
+// Types of order line
+const (
+	// OrderItemProduct is a line of a catalog product
+	OrderItemProduct = "product"
+	// OrderItemDeliveryFee is the delivery charge of a delivery order; it has no product
+	OrderItemDeliveryFee = "delivery_fee"
+)
+
+// IsValidFulfillmentType reports whether t is a known fulfillment type
+func IsValidFulfillmentType(t string) bool {
+	return t == FulfillmentPickup || t == FulfillmentDelivery
+}
+
+// Order represents a placed order.
+// Subtotal sums the product lines. Total is the subtotal less the discount of the
+// redeemed coupon, if any, plus charges such as the delivery fee line.
+// DeliveryAddress is a snapshot of the address a delivery order goes to.
+// ScheduledFor is the start of the time slot the order was booked into; orders without
+// one are prepared as soon as possible.
+type Order struct {
+	ID              uuid.UUID            `json:"id" db:"id"`
+	UserID          uuid.UUID            `json:"user_id" db:"user_id"`
+	StoreID         uuid.UUID            `json:"store_id" db:"store_id"`
+	Status          string               `json:"status" db:"status"`
+	Subtotal        Money                `json:"subtotal" db:"subtotal"`
+	Discount        Money                `json:"discount" db:"discount"`
+	CouponCode      string               `json:"coupon_code,omitempty" db:"coupon_code"`
+	Total           Money                `json:"total" db:"total"`
+	Fulfillment     string               `json:"fulfillment_type" db:"fulfillment_type"`
+	DeliveryAddress *Address             `json:"delivery_address,omitempty" db:"delivery_address"`
+	ScheduledFor    *time.Time           `json:"scheduled_for,omitempty" db:"scheduled_for"`
+	Items           []*OrderItem         `json:"items,omitempty"`
+	History         []*OrderStatusChange `json:"history,omitempty"`
+	CreatedAt       time.Time            `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time            `json:"updated_at" db:"updated_at"`
+}
+
+// OrderItem represents a line in an order, a product or a charge such as the delivery fee.
+// Name, chosen options and unit price are snapshotted at checkout so later catalog changes
+// do not alter past orders. Charge lines have no ProductID. KitchenStatus tracks the
+// kitchen's progress on product lines and is empty for charge lines.
+type OrderItem struct {
+	ID            uuid.UUID        `json:"id" db:"id"`
+	OrderID       uuid.UUID        `json:"order_id" db:"order_id"`
+	Type          string           `json:"type" db:"type"`
+	ProductID     uuid.UUID        `json:"product_id" db:"product_id"`
+	ProductName   string           `json:"product_name" db:"product_name"`
+	Options       []SelectedOption `json:"options" db:"options"`
+	Price         Money            `json:"price" db:"price"`
+	Quantity      int              `json:"quantity" db:"quantity"`
+	Subtotal      Money            `json:"subtotal" db:"subtotal"`
+	KitchenStatus string           `json:"kitchen_status,omitempty" db:"kitchen_status"`
+}
+
+// OrderStatusChange records a single transition in an order's lifecycle
+type OrderStatusChange struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	OrderID    uuid.UUID  `json:"order_id" db:"order_id"`
+	FromStatus string     `json:"from_status,omitempty" db:"from_status"`
+	ToStatus   string     `json:"to_status" db:"to_status"`
+	ActorID    *uuid.UUID `json:"actor_id,omitempty" db:"actor_id"`
+	Reason     string     `json:"reason,omitempty" db:"reason"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
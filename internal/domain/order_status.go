@@ -0,0 +1,70 @@
+package domain
+
+// Order statuses allowed by the orders.status check constraint
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusConfirmed = "confirmed"
+	OrderStatusPreparing = "preparing"
+	OrderStatusReady     = "ready"
+	OrderStatusShipped   = "shipped"
+	OrderStatusDelivered = "delivered"
+	OrderStatusCancelled = "cancelled"
+	OrderStatusRefunded  = "refunded"
+)
+
+// orderTransitions lists, for each status, the statuses an order may move to next.
+// Confirmed orders may skip the kitchen for stores that do not track it. Ready orders
+// are shipped, or delivered when picked up. Cancelled and refunded are terminal.
+var orderTransitions = map[string][]string{
+	OrderStatusPending:   {OrderStatusConfirmed, OrderStatusCancelled},
+	OrderStatusConfirmed: {OrderStatusPreparing, OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusPreparing: {OrderStatusReady, OrderStatusCancelled},
+	OrderStatusReady:     {OrderStatusShipped, OrderStatusDelivered},
+	OrderStatusShipped:   {OrderStatusDelivered},
+	OrderStatusDelivered: {OrderStatusRefunded},
+	OrderStatusCancelled: {},
+	OrderStatusRefunded:  {},
+}
+
+// IsValidOrderStatus reports whether status is a known order status
+func IsValidOrderStatus(status string) bool {
+	_, ok := orderTransitions[status]
+	return ok
+}
+
+// CanTransitionOrder reports whether an order may move from one status to another
+func CanTransitionOrder(from, to string) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedOrderTransitions returns the statuses an order in the given status may move to
+func AllowedOrderTransitions(from string) []string {
+	return append([]string(nil), orderTransitions[from]...)
+}
+
+// IsInKitchenQueue reports whether orders in status are waiting for or being prepared
+// by the kitchen
+func IsInKitchenQueue(status string) bool {
+	return status == OrderStatusConfirmed || status == OrderStatusPreparing
+}
+
+// RestoresStock reports whether moving an order into status returns its items to stock.
+// Only cancellation restores stock; refunded orders have already been fulfilled.
+func RestoresStock(status string) bool {
+	return status == OrderStatusCancelled
+}
+
+// EndsKitchenWork reports whether moving an order into status ends the kitchen's work on
+// it, so none of its lines are still queued or being prepared
+func EndsKitchenWork(status string) bool {
+	switch status {
+	case OrderStatusReady, OrderStatusShipped, OrderStatusDelivered, OrderStatusCancelled:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "github.com/google/uuid"
+
+// PriceQuote is the price of a quantity of a product with the chosen options.
+// Quotes, cart lines and order items are all priced by the same engine.
+type PriceQuote struct {
+	ProductID   uuid.UUID        `json:"product_id"`
+	ProductName string           `json:"product_name"`
+	BasePrice   Money            `json:"base_price"`
+	Options     []SelectedOption `json:"options"`
+	UnitPrice   Money            `json:"unit_price"`
+	Quantity    int              `json:"quantity"`
+	Total       Money            `json:"total"`
+}
@@ -6,17 +6,21 @@ import (
 	"github.com/google/uuid"
 )
 
-// Product represents a product in the catalog
+// Product represents a product in the catalog. When it is loaded for a store, Price is the
+// store's price and Stock the store's stock; otherwise Stock is zero.
 type Product struct {
 	ID          uuid.UUID `json:"id" db:"id"`
 	Name        string    `json:"name" db:"name"`
 	Description string    `json:"description" db:"description"`
-	Price       float64   `json:"price" db:"price"`
+	Price       Money     `json:"price" db:"price"`
 	CategoryID  uuid.UUID `json:"category_id" db:"category_id"`
 	ImageURL    string    `json:"image_url" db:"image_url"`
-	Stock       int       `json:"stock" db:"stock"`
+	Stock       int       `json:"stock" db:"-"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	// OptionGroups is only loaded when a single product is retrieved
+	OptionGroups []*OptionGroup `json:"option_groups,omitempty"`
 }
 
 // Category represents a product category
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Placements of an option on a pizza
+const (
+	PlacementWhole = "whole"
+	PlacementLeft  = "left"
+	PlacementRight = "right"
+)
+
+// OptionGroup is a set of choices offered for a product, such as size, crust or toppings.
+// A customer must pick between MinSelect and MaxSelect options from the group. When
+// AllowHalves is set, options may be placed on one half only and the limits apply to
+// each half separately.
+type OptionGroup struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	ProductID   uuid.UUID `json:"product_id" db:"product_id"`
+	Name        string    `json:"name" db:"name"`
+	MinSelect   int       `json:"min_select" db:"min_select"`
+	MaxSelect   int       `json:"max_select" db:"max_select"`
+	AllowHalves bool      `json:"allow_halves" db:"allow_halves"`
+	Position    int       `json:"position" db:"position"`
+	Options     []*Option `json:"options"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// Option is a single choice within an option group and the amount it adds to the unit price
+type Option struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	GroupID    uuid.UUID `json:"group_id" db:"group_id"`
+	Name       string    `json:"name" db:"name"`
+	PriceDelta Money     `json:"price_delta" db:"price_delta"`
+	Position   int       `json:"position" db:"position"`
+}
+
+// OptionSelection is an option chosen by the customer and where it goes on the pizza.
+// An empty placement means the whole pizza.
+type OptionSelection struct {
+	OptionID  uuid.UUID `json:"option_id"`
+	Placement string    `json:"placement,omitempty"`
+}
+
+// SelectedOption is a chosen option together with its group, as shown on cart lines
+// and snapshotted on order items. PriceDelta is the amount charged for the selection,
+// which is less than the option's delta when it covers only half of the pizza.
+type SelectedOption struct {
+	GroupID    uuid.UUID `json:"group_id"`
+	GroupName  string    `json:"group_name"`
+	OptionID   uuid.UUID `json:"option_id"`
+	OptionName string    `json:"option_name"`
+	Placement  string    `json:"placement"`
+	PriceDelta Money     `json:"price_delta"`
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of discount a promotion grants
+const (
+	// PromotionPercentage takes PercentOff percent off the eligible items
+	PromotionPercentage = "percentage"
+	// PromotionFixed takes AmountOff off the eligible items
+	PromotionFixed = "fixed"
+	// PromotionFreeItem makes one unit of the cheapest eligible item free
+	PromotionFreeItem = "free_item"
+	// PromotionBOGO pairs eligible units by price and makes the cheaper unit of each pair free
+	PromotionBOGO = "bogo"
+)
+
+// IsValidPromotionType reports whether t is a known promotion type
+func IsValidPromotionType(t string) bool {
+	switch t {
+	case PromotionPercentage, PromotionFixed, PromotionFreeItem, PromotionBOGO:
+		return true
+	}
+	return false
+}
+
+// Promotion is a coupon code and the discount it grants.
+// When ProductIDs or CategoryIDs are set, only cart lines of those products or categories
+// are eligible for the discount. A zero MaxRedemptions or MaxRedemptionsPerUser means the
+// promotion can be redeemed without limit.
+type Promotion struct {
+	ID                    uuid.UUID   `json:"id" db:"id"`
+	Code                  string      `json:"code" db:"code"`
+	Description           string      `json:"description" db:"description"`
+	Type                  string      `json:"type" db:"type"`
+	PercentOff            int         `json:"percent_off" db:"percent_off"`
+	AmountOff             Money       `json:"amount_off" db:"amount_off"`
+	MinOrderValue         Money       `json:"min_order_value" db:"min_order_value"`
+	ProductIDs            []uuid.UUID `json:"product_ids" db:"product_ids"`
+	CategoryIDs           []uuid.UUID `json:"category_ids" db:"category_ids"`
+	StartsAt              *time.Time  `json:"starts_at,omitempty" db:"starts_at"`
+	EndsAt                *time.Time  `json:"ends_at,omitempty" db:"ends_at"`
+	MaxRedemptions        int         `json:"max_redemptions" db:"max_redemptions"`
+	MaxRedemptionsPerUser int         `json:"max_redemptions_per_user" db:"max_redemptions_per_user"`
+	RedemptionCount       int         `json:"redemption_count" db:"redemption_count"`
+	Active                bool        `json:"active" db:"active"`
+	CreatedAt             time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt             time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// PromotionRedemption records a coupon redeemed by a user on an order
+type PromotionRedemption struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	PromotionID uuid.UUID `json:"promotion_id" db:"promotion_id"`
+	UserID      uuid.UUID `json:"user_id" db:"user_id"`
+	OrderID     uuid.UUID `json:"order_id" db:"order_id"`
+	Discount    Money     `json:"discount" db:"discount"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// AppliedCoupon is the coupon attached to a cart and the discount it currently grants.
+// A coupon that no longer applies, for example because the cart fell below the minimum
+// order value, stays attached with a zero discount and the reason it was not applied.
+type AppliedCoupon struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Discount    Money  `json:"discount"`
+	Applicable  bool   `json:"applicable"`
+	Reason      string `json:"reason,omitempty"`
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Permissions granted through roles; the permissions table lists the same names
+const (
+	// PermissionOrdersRead allows viewing every user's orders
+	PermissionOrdersRead = "orders:read"
+	// PermissionOrdersTransition allows moving orders through their lifecycle
+	PermissionOrdersTransition = "orders:transition"
+	// PermissionOrdersCancel allows cancelling orders, which returns their items to stock
+	PermissionOrdersCancel = "orders:cancel"
+	// PermissionRefundsIssue allows moving delivered orders to refunded
+	PermissionRefundsIssue = "refunds:issue"
+	// PermissionProductsWrite allows managing products, their options and categories
+	PermissionProductsWrite = "products:write"
+	// PermissionPromotionsWrite allows managing promotions
+	PermissionPromotionsWrite = "promotions:write"
+	// PermissionUsersManage allows signing users out and lifting login lockouts
+	PermissionUsersManage = "users:manage"
+	// PermissionRolesManage allows granting and revoking roles
+	PermissionRolesManage = "roles:manage"
+	// PermissionStoresManage allows managing store details and opening hours
+	PermissionStoresManage = "stores:manage"
+	// PermissionKitchenOperate allows viewing the kitchen queue and marking order items as
+	// preparing or ready
+	PermissionKitchenOperate = "kitchen:operate"
+)
+
+// Built-in roles created by the migrations
+const (
+	RoleAdmin        = "admin"
+	RoleStoreManager = "store_manager"
+	RoleKitchen      = "kitchen"
+	RoleDriver       = "driver"
+	RoleSupport      = "support"
+)
+
+// Role is a named set of permissions that can be granted to users.
+// Customers hold no roles; staff are granted one or more.
+type Role struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// RoleAssignment is a role granted to a user, either for every store or, when StoreID is
+// set, for that store only
+type RoleAssignment struct {
+	Role    string     `json:"role" db:"role"`
+	StoreID *uuid.UUID `json:"store_id,omitempty" db:"store_id"`
+}
+
+// PermissionGrant is a permission a user holds through a role assignment
+type PermissionGrant struct {
+	Permission string     `json:"permission" db:"permission"`
+	StoreID    *uuid.UUID `json:"store_id,omitempty" db:"store_id"`
+}
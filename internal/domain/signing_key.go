@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// SigningKey is an access token signing key as it is persisted. PrivateKey holds the
+// PKCS #8 DER encoding of the key, encrypted while it is stored, and is never serialized. A key signs tokens until
+// RetiredAt, when the key replacing it was created.
+type SigningKey struct {
+	ID         string     `json:"id" db:"id"`
+	Algorithm  string     `json:"algorithm" db:"algorithm"`
+	PrivateKey []byte     `json:"-" db:"private_key"`
+	Replaces   string     `json:"replaces,omitempty" db:"replaces"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	RetiredAt  *time.Time `json:"retired_at,omitempty" db:"retired_at"`
+}
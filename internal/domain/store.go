@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store represents a pizzeria location. Each store keeps its own menu, stock and orders.
+// Orders for later are booked into slots of SlotMinutes, each taking up to SlotCapacity
+// orders, and need at least LeadMinutes of notice.
+type Store struct {
+	ID              uuid.UUID         `json:"id" db:"id"`
+	Name            string            `json:"name" db:"name"`
+	AddressLine1    string            `json:"address_line1" db:"address_line1"`
+	AddressLine2    string            `json:"address_line2,omitempty" db:"address_line2"`
+	City            string            `json:"city" db:"city"`
+	PostalCode      string            `json:"postal_code" db:"postal_code"`
+	Country         string            `json:"country" db:"country"`
+	Latitude        float64           `json:"latitude" db:"latitude"`
+	Longitude       float64           `json:"longitude" db:"longitude"`
+	Timezone        string            `json:"timezone" db:"timezone"`
+	Phone           string            `json:"phone,omitempty" db:"phone"`
+	Active          bool              `json:"active" db:"active"`
+	SlotMinutes     int               `json:"slot_minutes" db:"slot_minutes"`
+	SlotCapacity    int               `json:"slot_capacity" db:"slot_capacity"`
+	LeadMinutes     int               `json:"lead_minutes" db:"lead_minutes"`
+	OpeningHours    []*OpeningHours   `json:"opening_hours"`
+	HoursExceptions []*HoursException `json:"hours_exceptions"`
+	CreatedAt       time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// OpeningHours is a period a store is open on a weekday, in the store's local time.
+// Weekday counts from Sunday (0) like time.Weekday; Opens and Closes are "HH:MM".
+// A period closing at or before its opening time runs past midnight.
+type OpeningHours struct {
+	Weekday int    `json:"weekday" db:"weekday"`
+	Opens   string `json:"opens" db:"opens_at"`
+	Closes  string `json:"closes" db:"closes_at"`
+}
+
+// HoursException replaces a store's weekly opening hours on one local date, such as a
+// holiday. Date is "YYYY-MM-DD"; without Opens and Closes the store is closed all day.
+type HoursException struct {
+	Date   string `json:"date" db:"date"`
+	Opens  string `json:"opens,omitempty" db:"opens_at"`
+	Closes string `json:"closes,omitempty" db:"closes_at"`
+	Note   string `json:"note,omitempty" db:"note"`
+}
+
+// Closed reports whether the exception closes the store for the whole day
+func (e *HoursException) Closed() bool {
+	return e.Opens == "" && e.Closes == ""
+}
+
+// StoreProduct is a store's listing of a catalog product.
+// A nil Price means the store sells the product at its catalog price.
+type StoreProduct struct {
+	StoreID   uuid.UUID `json:"store_id" db:"store_id"`
+	ProductID uuid.UUID `json:"product_id" db:"product_id"`
+	Available bool      `json:"available" db:"available"`
+	Stock     int       `json:"stock" db:"stock"`
+	Price     *Money    `json:"price,omitempty" db:"price"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// Scheduling settings of a store created without them
+const (
+	DefaultSlotMinutes  = 15
+	DefaultSlotCapacity = 10
+	DefaultLeadMinutes  = 30
+)
+
+const (
+	// ClockLayout is the "HH:MM" format of opening and closing times
+	ClockLayout = "15:04"
+	// DateLayout is the "YYYY-MM-DD" format of local store dates
+	DateLayout = "2006-01-02"
+)
+
+// OpenPeriod is a span of time a store is open
+type OpenPeriod struct {
+	Start time.Time
+	End   time.Time
+}
+
+// TimeSlot is a span of time orders can be scheduled into. Remaining counts the orders
+// the slot still takes.
+type TimeSlot struct {
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Capacity  int       `json:"capacity"`
+	Remaining int       `json:"remaining"`
+}
+
+// Location returns the store's timezone. Timezones are validated when a store is saved,
+// so an unknown name only falls back to UTC for rows written outside the API.
+func (s *Store) Location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// OpenPeriodsOn returns the periods, oldest first, that open on the store's local date
+// of day. An exception for that date replaces the weekly hours.
+func (s *Store) OpenPeriodsOn(day time.Time) []OpenPeriod {
+	date := localDate(day, s.Location())
+	periods := []OpenPeriod{}
+
+	for _, exception := range s.HoursExceptions {
+		if exception.Date != date.Format(DateLayout) {
+			continue
+		}
+		if !exception.Closed() {
+			if period, ok := openPeriod(date, exception.Opens, exception.Closes); ok {
+				periods = append(periods, period)
+			}
+		}
+		return periods
+	}
+
+	for _, hours := range s.OpeningHours {
+		if hours.Weekday != int(date.Weekday()) {
+			continue
+		}
+		if period, ok := openPeriod(date, hours.Opens, hours.Closes); ok {
+			periods = append(periods, period)
+		}
+	}
+
+	sort.Slice(periods, func(i, j int) bool { return periods[i].Start.Before(periods[j].Start) })
+	return periods
+}
+
+// IsOpenAt reports whether the store is open at t, including periods of the previous
+// local day that run past midnight
+func (s *Store) IsOpenAt(t time.Time) bool {
+	local := t.In(s.Location())
+	for _, day := range []time.Time{local.AddDate(0, 0, -1), local} {
+		for _, period := range s.OpenPeriodsOn(day) {
+			if !t.Before(period.Start) && t.Before(period.End) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SlotsOn returns the slots starting on the store's local date of day, earliest first.
+// Slots are laid out from the start of each open period and must end before it closes.
+// Every slot is returned with its full capacity remaining.
+func (s *Store) SlotsOn(day time.Time) []*TimeSlot {
+	slots := []*TimeSlot{}
+	if s.SlotMinutes <= 0 {
+		return slots
+	}
+
+	loc := s.Location()
+	date := localDate(day, loc)
+	length := time.Duration(s.SlotMinutes) * time.Minute
+
+	for _, openDay := range []time.Time{date.AddDate(0, 0, -1), date} {
+		for _, period := range s.OpenPeriodsOn(openDay) {
+			for start := period.Start; !start.Add(length).After(period.End); start = start.Add(length) {
+				if !localDate(start, loc).Equal(date) {
+					continue
+				}
+				slots = append(slots, &TimeSlot{
+					Start:     start,
+					End:       start.Add(length),
+					Capacity:  s.SlotCapacity,
+					Remaining: s.SlotCapacity,
+				})
+			}
+		}
+	}
+
+	sort.Slice(slots, func(i, j int) bool { return slots[i].Start.Before(slots[j].Start) })
+	return slots
+}
+
+// localDate returns midnight of t's date in loc
+func localDate(t time.Time, loc *time.Location) time.Time {
+	year, month, day := t.In(loc).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+// openPeriod turns "HH:MM" opening and closing times on date into a period. A period
+// closing at or before its opening time ends on the next day.
+func openPeriod(date time.Time, opens, closes string) (OpenPeriod, bool) {
+	openClock, err := time.Parse(ClockLayout, opens)
+	if err != nil {
+		return OpenPeriod{}, false
+	}
+	closeClock, err := time.Parse(ClockLayout, closes)
+	if err != nil {
+		return OpenPeriod{}, false
+	}
+
+	year, month, day := date.Date()
+	start := time.Date(year, month, day, openClock.Hour(), openClock.Minute(), 0, 0, date.Location())
+	end := time.Date(year, month, day, closeClock.Hour(), closeClock.Minute(), 0, 0, date.Location())
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	return OpenPeriod{Start: start, End: end}, true
+}
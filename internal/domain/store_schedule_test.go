@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func newScheduleTestStore(t *testing.T) (*Store, *time.Location) {
+	t.Helper()
+	loc, err := time.LoadLocation("Europe/Rome")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	return &Store{
+		Timezone:     "Europe/Rome",
+		SlotMinutes:  30,
+		SlotCapacity: 4,
+		OpeningHours: []*OpeningHours{
+			{Weekday: int(time.Friday), Opens: "12:00", Closes: "14:00"},
+			{Weekday: int(time.Friday), Opens: "19:00", Closes: "01:00"},
+			{Weekday: int(time.Saturday), Opens: "19:00", Closes: "23:00"},
+		},
+		HoursExceptions: []*HoursException{
+			{Date: "2024-12-25", Note: "Christmas"},
+			{Date: "2024-12-31", Opens: "18:00", Closes: "20:00"},
+		},
+	}, loc
+}
+
+func TestStore_IsOpenAt(t *testing.T) {
+	store, loc := newScheduleTestStore(t)
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{"friday lunch", time.Date(2024, 3, 8, 12, 30, 0, 0, loc), true},
+		{"friday afternoon", time.Date(2024, 3, 8, 16, 0, 0, 0, loc), false},
+		{"closing time", time.Date(2024, 3, 8, 14, 0, 0, 0, loc), false},
+		{"past midnight into saturday", time.Date(2024, 3, 9, 0, 30, 0, 0, loc), true},
+		{"after the late close", time.Date(2024, 3, 9, 1, 0, 0, 0, loc), false},
+		{"same instant in UTC", time.Date(2024, 3, 8, 11, 30, 0, 0, time.UTC), true},
+		{"sunday", time.Date(2024, 3, 10, 20, 0, 0, 0, loc), false},
+		{"closed all day exception", time.Date(2024, 12, 25, 20, 0, 0, 0, loc), false},
+		{"special hours exception", time.Date(2024, 12, 31, 18, 30, 0, 0, loc), true},
+		{"outside special hours", time.Date(2024, 12, 31, 21, 0, 0, 0, loc), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := store.IsOpenAt(tt.at); got != tt.want {
+				t.Errorf("expected open %v at %s, got %v", tt.want, tt.at, got)
+			}
+		})
+	}
+}
+
+func TestStore_SlotsOn(t *testing.T) {
+	store, loc := newScheduleTestStore(t)
+
+	// Friday: four lunch slots and twelve evening slots, two of them after midnight
+	// belonging to Saturday
+	friday := store.SlotsOn(time.Date(2024, 3, 8, 9, 0, 0, 0, loc))
+	if len(friday) != 4+10 {
+		t.Fatalf("expected 14 slots on friday, got %d", len(friday))
+	}
+	if !friday[0].Start.Equal(time.Date(2024, 3, 8, 12, 0, 0, 0, loc)) || friday[0].Capacity != 4 || friday[0].Remaining != 4 {
+		t.Errorf("unexpected first slot %+v", friday[0])
+	}
+	if last := friday[len(friday)-1]; !last.End.Equal(time.Date(2024, 3, 9, 0, 0, 0, 0, loc)) {
+		t.Errorf("expected the last friday slot to end at midnight, got %s", last.End)
+	}
+
+	saturday := store.SlotsOn(time.Date(2024, 3, 9, 9, 0, 0, 0, loc))
+	if len(saturday) != 2+8 || !saturday[0].Start.Equal(time.Date(2024, 3, 9, 0, 0, 0, 0, loc)) {
+		t.Errorf("expected saturday to start with the slots past midnight, got %d slots", len(saturday))
+	}
+
+	if closed := store.SlotsOn(time.Date(2024, 12, 25, 9, 0, 0, 0, loc)); len(closed) != 0 {
+		t.Errorf("expected no slots on a closed day, got %d", len(closed))
+	}
+}
+
+// Feature: ordering-platform, Property 91: Time slots fall inside opening hours
+func TestProperty_TimeSlotsFallInsideOpeningHours(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+
+	properties.Property("every slot starts on the requested date and the store is open throughout it", prop.ForAll(
+		func(opens, length, slotMinutes, dayOffset int) bool {
+			clock := func(minutes int) string {
+				minutes %= 24 * 60
+				return time.Date(2000, 1, 1, minutes/60, minutes%60, 0, 0, time.UTC).Format(ClockLayout)
+			}
+			store := &Store{Timezone: "America/New_York", SlotMinutes: slotMinutes, SlotCapacity: 1}
+			for weekday := 0; weekday < 7; weekday++ {
+				store.OpeningHours = append(store.OpeningHours, &OpeningHours{Weekday: weekday, Opens: clock(opens), Closes: clock(opens + length)})
+			}
+
+			// Covers the daylight saving changes of March and November
+			day := time.Date(2024, 1, 1, 12, 0, 0, 0, loc).AddDate(0, 0, dayOffset)
+			for _, slot := range store.SlotsOn(day) {
+				if !localDate(slot.Start, loc).Equal(localDate(day, loc)) {
+					t.Logf("FAIL: Slot %s does not start on %s", slot.Start, day)
+					return false
+				}
+				if slot.End.Sub(slot.Start) != time.Duration(slotMinutes)*time.Minute {
+					t.Logf("FAIL: Slot %s-%s has the wrong length", slot.Start, slot.End)
+					return false
+				}
+				if !store.IsOpenAt(slot.Start) || !store.IsOpenAt(slot.End.Add(-time.Minute)) {
+					t.Logf("FAIL: Store is closed during slot %s-%s", slot.Start, slot.End)
+					return false
+				}
+			}
+			return true
+		},
+		gen.IntRange(0, 24*60-1),
+		gen.IntRange(1, 23*60),
+		gen.IntRange(5, 120),
+		gen.IntRange(0, 365),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
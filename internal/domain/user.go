@@ -13,17 +13,101 @@ type User struct {
 	PasswordHash string    `json:"-" db:"password_hash"`
 	FirstName    string    `json:"first_name" db:"first_name"`
 	LastName     string    `json:"last_name" db:"last_name"`
-	Role         string    `json:"role" db:"role"` // "user" or "admin"
+	Phone        string    `json:"phone" db:"phone"`
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	// Roles names the roles granted to the user. They are stored in user_roles and filled
+	// in by the user service.
+	Roles []string `json:"roles" db:"-"`
+	// EmailVerifiedAt is when the user proved they own Email; nil until then
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty" db:"email_verified_at"`
+	// DeletedAt is when the user deleted their account. The row is kept, stripped of
+	// personal data, so the account's orders survive.
+	DeletedAt *time.Time `json:"-" db:"deleted_at"`
 }
 
-// RefreshToken represents a refresh token for JWT authentication
+// IsEmailVerified reports whether the user has verified their email address
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// RefreshToken represents a refresh token for JWT authentication.
+// Each refresh rotates the token: the presented token is revoked with ReplacedBy pointing at
+// its successor, which joins the same family. FamilyID is the ID of the family's first token.
+// Only the SHA-256 digest of the token handed to the client is stored.
 type RefreshToken struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	UserID    uuid.UUID `json:"user_id" db:"user_id"`
-	Token     string    `json:"token" db:"token"`
-	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	Revoked   bool      `json:"revoked" db:"revoked"`
+	ID         uuid.UUID  `json:"id" db:"id"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	FamilyID   uuid.UUID  `json:"family_id" db:"family_id"`
+	TokenHash  string     `json:"-" db:"token_hash"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	Revoked    bool       `json:"revoked" db:"revoked"`
+	ReplacedBy *uuid.UUID `json:"replaced_by,omitempty" db:"replaced_by"`
+	UserAgent  string     `json:"user_agent" db:"user_agent"`
+	IPAddress  string     `json:"ip_address" db:"ip_address"`
+	LastUsedAt time.Time  `json:"last_used_at" db:"last_used_at"`
+	// SessionStartedAt is when the user signed in; it is shared by every token of the family
+	SessionStartedAt time.Time `json:"session_started_at" db:"session_started_at"`
+}
+
+// PasswordResetToken lets a user who lost their password choose a new one.
+// Only the SHA-256 digest of the token mailed to the user is stored; UsedAt is set once
+// the token has been redeemed, after which it cannot be used again.
+type PasswordResetToken struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	UserID    uuid.UUID  `json:"user_id" db:"user_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty" db:"used_at"`
+}
+
+// EmailVerificationToken proves that a user can read mail sent to their email address.
+// Only the SHA-256 digest of the token mailed to the user is stored; UsedAt is set once
+// the token has been redeemed, after which it cannot be used again.
+type EmailVerificationToken struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	UserID    uuid.UUID  `json:"user_id" db:"user_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty" db:"used_at"`
+}
+
+// Session is a signed-in device: the active refresh token of a token family.
+// ID is the family ID, which stays the same across refreshes. CreatedAt is when the user
+// signed in and LastUsedAt when the session was last refreshed; UserAgent and IPAddress
+// are those of the client that last used it.
+type Session struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Kinds of login throttles
+const (
+	LoginThrottleEmail = "email"
+	LoginThrottleIP    = "ip"
+	// LoginThrottleResetIP counts password reset requests per client IP
+	LoginThrottleResetIP = "reset_ip"
+)
+
+// LoginThrottle counts the recent failed logins for one email address or one client IP, or
+// the recent password reset requests from one client IP. Logins for it are refused until
+// LockedUntil has passed.
+type LoginThrottle struct {
+	Kind          string     `json:"kind" db:"kind"`
+	Subject       string     `json:"subject" db:"subject"`
+	Failures      int        `json:"failures" db:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at" db:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty" db:"locked_until"`
+}
+
+// IsLocked reports whether logins are refused at the given time
+func (t *LoginThrottle) IsLocked(now time.Time) bool {
+	return t.LockedUntil != nil && t.LockedUntil.After(now)
 }
@@ -0,0 +1,50 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidMessage = errors.New("invalid mail message")
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers email messages
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// validate rejects messages without a recipient and header values that would let a caller
+// inject extra headers
+func (m Message) validate() error {
+	switch {
+	case m.To == "":
+		return fmt.Errorf("%w: recipient is required", ErrInvalidMessage)
+	case strings.ContainsAny(m.To, "\r\n"), strings.ContainsAny(m.Subject, "\r\n"):
+		return fmt.Errorf("%w: header values must not contain line breaks", ErrInvalidMessage)
+	}
+	return nil
+}
+
+// render formats the message sent from from as an RFC 5322 document
+func (m Message) render(from string, date time.Time) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", m.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", m.Subject)
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(m.Body, "\n", "\r\n"))
+	return buf.Bytes()
+}
@@ -0,0 +1,67 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileMailer_WritesOneFilePerMessage(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	m, err := NewFileMailer(dir, "noreply@example.com")
+	if err != nil {
+		t.Fatalf("NewFileMailer failed: %v", err)
+	}
+
+	for _, to := range []string{"a@example.com", "b@example.com"} {
+		msg := Message{To: to, Subject: "Reset your password", Body: "line one\nline two"}
+		if err := m.Send(context.Background(), msg); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(files))
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	for _, want := range []string{"From: noreply@example.com\r\n", "Subject: Reset your password\r\n", "\r\n\r\nline one\r\nline two"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("message %q does not contain %q", content, want)
+		}
+	}
+}
+
+func TestMessage_RejectsHeaderInjection(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  Message
+	}{
+		{"missing recipient", Message{Subject: "Hello"}},
+		{"line break in recipient", Message{To: "a@example.com\r\nBcc: b@example.com", Subject: "Hello"}},
+		{"line break in subject", Message{To: "a@example.com", Subject: "Hello\nBcc: b@example.com"}},
+	}
+
+	m, err := NewFileMailer(t.TempDir(), "noreply@example.com")
+	if err != nil {
+		t.Fatalf("NewFileMailer failed: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := m.Send(context.Background(), tt.msg); !errors.Is(err, ErrInvalidMessage) {
+				t.Errorf("expected ErrInvalidMessage, got %v", err)
+			}
+		})
+	}
+}
@@ -2,22 +2,24 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 
-	"github.com/golang-jwt/jwt/v5"
+	"pizza-must/internal/auth"
+
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type contextKey string
 
 const (
-	UserIDKey   contextKey = "user_id"
-	UserRoleKey contextKey = "user_role"
+	PrincipalKey contextKey = "principal"
 )
 
-// AuthMiddleware validates JWT tokens and extracts user claims
-func AuthMiddleware(jwtSecret string, logger *zap.Logger) func(http.Handler) http.Handler {
+// AuthMiddleware verifies JWT tokens and puts the authenticated principal on the request context
+func AuthMiddleware(verifier auth.TokenVerifier, logger *zap.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Extract token from Authorization header
@@ -38,18 +40,11 @@ func AuthMiddleware(jwtSecret string, logger *zap.Logger) func(http.Handler) htt
 
 			tokenString := parts[1]
 
-			// Parse and validate token
-			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				// Validate signing method
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, jwt.ErrSignatureInvalid
-				}
-				return []byte(jwtSecret), nil
-			})
-
+			// Verify token
+			claims, err := verifier.Verify(tokenString)
 			if err != nil {
 				logger.Debug("Token validation failed", zap.Error(err))
-				if err == jwt.ErrTokenExpired {
+				if errors.Is(err, auth.ErrTokenExpired) {
 					respondWithError(w, http.StatusUnauthorized, "token expired")
 				} else {
 					respondWithError(w, http.StatusUnauthorized, "invalid token")
@@ -57,59 +52,38 @@ func AuthMiddleware(jwtSecret string, logger *zap.Logger) func(http.Handler) htt
 				return
 			}
 
-			if !token.Valid {
-				logger.Debug("Invalid token")
-				respondWithError(w, http.StatusUnauthorized, "invalid token")
-				return
-			}
-
-			// Extract claims
-			claims, ok := token.Claims.(jwt.MapClaims)
-			if !ok {
-				logger.Error("Failed to extract claims from token")
-				respondWithError(w, http.StatusUnauthorized, "invalid token claims")
-				return
-			}
-
-			// Extract user ID
-			userID, ok := claims["user_id"].(string)
-			if !ok {
-				logger.Error("Missing user_id in token claims")
-				respondWithError(w, http.StatusUnauthorized, "invalid token claims")
-				return
-			}
-
-			// Extract role
-			role, ok := claims["role"].(string)
-			if !ok {
-				logger.Error("Missing role in token claims")
-				respondWithError(w, http.StatusUnauthorized, "invalid token claims")
-				return
-			}
-
-			// Add user info to context
-			ctx := context.WithValue(r.Context(), UserIDKey, userID)
-			ctx = context.WithValue(ctx, UserRoleKey, role)
+			principal := claims.Principal()
 
 			logger.Debug("User authenticated",
-				zap.String("user_id", userID),
-				zap.String("role", role),
+				zap.String("user_id", principal.UserID.String()),
+				zap.Strings("roles", principal.Roles),
 			)
 
-			// Call next handler with updated context
-			next.ServeHTTP(w, r.WithContext(ctx))
+			// Call next handler with the principal on the context
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
 		})
 	}
 }
 
+// WithPrincipal returns a copy of ctx carrying the authenticated principal
+func WithPrincipal(ctx context.Context, principal auth.Principal) context.Context {
+	return context.WithValue(ctx, PrincipalKey, principal)
+}
+
+// GetPrincipal extracts the authenticated principal from request context
+func GetPrincipal(ctx context.Context) (auth.Principal, bool) {
+	principal, ok := ctx.Value(PrincipalKey).(auth.Principal)
+	return principal, ok
+}
+
 // GetUserID extracts user ID from request context
-func GetUserID(ctx context.Context) (string, bool) {
-	userID, ok := ctx.Value(UserIDKey).(string)
-	return userID, ok
+func GetUserID(ctx context.Context) (uuid.UUID, bool) {
+	principal, ok := GetPrincipal(ctx)
+	return principal.UserID, ok
 }
 
-// GetUserRole extracts user role from request context
-func GetUserRole(ctx context.Context) (string, bool) {
-	role, ok := ctx.Value(UserRoleKey).(string)
-	return role, ok
+// HasPermission reports whether the authenticated user on the context holds a permission
+func HasPermission(ctx context.Context, permission string) bool {
+	principal, ok := GetPrincipal(ctx)
+	return ok && principal.HasPermission(permission)
 }
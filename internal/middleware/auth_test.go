@@ -3,16 +3,39 @@ package middleware
 import (
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"pizza-must/internal/auth"
+
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
 	"github.com/leanovate/gopter/prop"
 	"go.uber.org/zap"
 )
 
+// newTestTokenManager creates a token manager with a fast EdDSA key for signing test tokens
+func newTestTokenManager(t *testing.T) auth.TokenManager {
+	t.Helper()
+	keys, err := auth.NewKeyManager(auth.KeyManagerConfig{Algorithm: auth.AlgorithmEdDSA, OverlapWindow: time.Hour}, auth.NewMemoryKeyStore())
+	if err != nil {
+		t.Fatalf("Failed to create key manager: %v", err)
+	}
+	return auth.NewTokenManager(keys, auth.TokenConfig{Issuer: "test", Audience: "test-api", Leeway: 30 * time.Second})
+}
+
+// testAccess returns the access of a user holding role, or of a customer when role is empty
+func testAccess(role string) auth.Access {
+	if role == "" {
+		return auth.Access{}
+	}
+	return auth.Access{Roles: []string{role}}
+}
+
 // Feature: ordering-platform, Property 43: Protected endpoints reject missing tokens
 // Validates: Requirements 17.1
 func TestProperty_ProtectedEndpointsRejectMissingTokens(t *testing.T) {
@@ -21,7 +44,7 @@ func TestProperty_ProtectedEndpointsRejectMissingTokens(t *testing.T) {
 	properties.Property("requests without authorization header are rejected", prop.ForAll(
 		func(pathSuffix string, method string) bool {
 			logger, _ := zap.NewDevelopment()
-			middleware := AuthMiddleware("test-secret", logger)
+			middleware := AuthMiddleware(newTestTokenManager(t), logger)
 
 			// Create a test handler
 			handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -56,20 +79,14 @@ func TestProperty_ExpiredTokensAreRejected(t *testing.T) {
 	properties := gopter.NewProperties(nil)
 
 	properties.Property("expired tokens are rejected with 401", prop.ForAll(
-		func(userID string, role string) bool {
+		func(role string) bool {
+			userID := uuid.New()
 			logger, _ := zap.NewDevelopment()
-			secret := "test-secret"
-			middleware := AuthMiddleware(secret, logger)
+			tokens := newTestTokenManager(t)
+			middleware := AuthMiddleware(tokens, logger)
 
-			// Create expired token
-			claims := jwt.MapClaims{
-				"user_id": userID,
-				"role":    role,
-				"exp":     time.Now().Add(-1 * time.Hour).Unix(), // Expired 1 hour ago
-			}
-
-			token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-			tokenString, _ := token.SignedString([]byte(secret))
+			// Create token that expired 1 hour ago
+			tokenString, _ := tokens.Issue(userID, testAccess(role), -1*time.Hour)
 
 			// Create test handler
 			handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -83,11 +100,10 @@ func TestProperty_ExpiredTokensAreRejected(t *testing.T) {
 
 			handler.ServeHTTP(w, req)
 
-			// Should return 401 Unauthorized
-			return w.Code == http.StatusUnauthorized
+			// Should return 401 Unauthorized and say why
+			return w.Code == http.StatusUnauthorized && strings.Contains(w.Body.String(), "token expired")
 		},
-		gen.AnyString(),
-		gen.OneConstOf("user", "admin"),
+		gen.OneConstOf("", "admin", "kitchen"),
 	))
 
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
@@ -99,20 +115,14 @@ func TestProperty_ValidTokensAllowProcessing(t *testing.T) {
 	properties := gopter.NewProperties(nil)
 
 	properties.Property("valid tokens allow request processing", prop.ForAll(
-		func(userID string, role string) bool {
+		func(role string) bool {
+			userID := uuid.New()
 			logger, _ := zap.NewDevelopment()
-			secret := "test-secret"
-			middleware := AuthMiddleware(secret, logger)
-
-			// Create valid token
-			claims := jwt.MapClaims{
-				"user_id": userID,
-				"role":    role,
-				"exp":     time.Now().Add(1 * time.Hour).Unix(), // Expires in 1 hour
-			}
+			tokens := newTestTokenManager(t)
+			middleware := AuthMiddleware(tokens, logger)
 
-			token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-			tokenString, _ := token.SignedString([]byte(secret))
+			// Create token that expires in 1 hour
+			tokenString, _ := tokens.Issue(userID, testAccess(role), 1*time.Hour)
 
 			// Track if handler was called
 			handlerCalled := false
@@ -121,16 +131,14 @@ func TestProperty_ValidTokensAllowProcessing(t *testing.T) {
 			handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				handlerCalled = true
 
-				// Verify user ID and role are in context
-				ctxUserID, ok1 := GetUserID(r.Context())
-				ctxRole, ok2 := GetUserRole(r.Context())
-
-				if !ok1 || !ok2 {
+				// Verify the typed principal is in context
+				principal, ok := GetPrincipal(r.Context())
+				if !ok {
 					w.WriteHeader(http.StatusInternalServerError)
 					return
 				}
 
-				if ctxUserID != userID || ctxRole != role {
+				if principal.UserID != userID || !reflect.DeepEqual(principal.Access, testAccess(role)) || principal.TokenID == "" {
 					w.WriteHeader(http.StatusInternalServerError)
 					return
 				}
@@ -148,8 +156,7 @@ func TestProperty_ValidTokensAllowProcessing(t *testing.T) {
 			// Handler should be called and return 200
 			return handlerCalled && w.Code == http.StatusOK
 		},
-		gen.AnyString(),
-		gen.OneConstOf("user", "admin"),
+		gen.OneConstOf("", "admin", "kitchen"),
 	))
 
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
@@ -162,7 +169,7 @@ func TestProperty_InvalidTokenFormatRejected(t *testing.T) {
 	properties.Property("invalid token formats are rejected", prop.ForAll(
 		func(invalidToken string) bool {
 			logger, _ := zap.NewDevelopment()
-			middleware := AuthMiddleware("test-secret", logger)
+			middleware := AuthMiddleware(newTestTokenManager(t), logger)
 
 			handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				w.WriteHeader(http.StatusOK)
@@ -191,7 +198,7 @@ func TestProperty_MissingBearerPrefixRejected(t *testing.T) {
 	properties.Property("tokens without Bearer prefix are rejected", prop.ForAll(
 		func(token string) bool {
 			logger, _ := zap.NewDevelopment()
-			middleware := AuthMiddleware("test-secret", logger)
+			middleware := AuthMiddleware(newTestTokenManager(t), logger)
 
 			handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				w.WriteHeader(http.StatusOK)
@@ -212,3 +219,54 @@ func TestProperty_MissingBearerPrefixRejected(t *testing.T) {
 
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
 }
+
+// Feature: ordering-platform, Property 85: Tokens are only accepted from published keys
+// Validates: Requirements 17.3
+func TestProperty_TokensFromUnknownKeysRejected(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("tokens signed by another key or with HS256 are rejected", prop.ForAll(
+		func(role string) bool {
+			userID := uuid.New()
+			logger, _ := zap.NewDevelopment()
+			middleware := AuthMiddleware(newTestTokenManager(t), logger)
+
+			handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			claims := jwt.MapClaims{
+				"user_id": userID.String(),
+				"role":    role,
+				"iss":     "test",
+				"aud":     "test-api",
+				"exp":     time.Now().Add(1 * time.Hour).Unix(),
+			}
+
+			// Signed by a key this server never published
+			foreignToken, _ := newTestTokenManager(t).Issue(userID, testAccess(role), time.Hour)
+
+			// Signed with a shared secret, as tokens were before asymmetric keys
+			hmacToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+			hmacToken.Header["kid"] = "test-secret"
+			hmacTokenString, _ := hmacToken.SignedString([]byte("test-secret"))
+
+			for _, tokenString := range []string{foreignToken, hmacTokenString} {
+				req := httptest.NewRequest("GET", "/test", nil)
+				req.Header.Set("Authorization", "Bearer "+tokenString)
+				w := httptest.NewRecorder()
+
+				handler.ServeHTTP(w, req)
+
+				if w.Code != http.StatusUnauthorized {
+					return false
+				}
+			}
+
+			return true
+		},
+		gen.OneConstOf("", "admin", "kitchen"),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
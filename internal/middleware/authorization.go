@@ -6,20 +6,22 @@ import (
 	"go.uber.org/zap"
 )
 
-// RequireAdmin middleware ensures the user has admin role
-func RequireAdmin(logger *zap.Logger) func(http.Handler) http.Handler {
+// RequirePermission middleware ensures the user holds a permission through one of their roles
+func RequirePermission(permission string, logger *zap.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			role, ok := GetUserRole(r.Context())
+			principal, ok := GetPrincipal(r.Context())
 			if !ok {
-				logger.Warn("Role not found in context")
+				logger.Warn("Principal not found in context")
 				respondWithError(w, http.StatusForbidden, "insufficient permissions")
 				return
 			}
 
-			if role != "admin" {
-				logger.Warn("Non-admin user attempted to access admin endpoint",
-					zap.String("role", role),
+			if !principal.HasPermission(permission) {
+				logger.Warn("User lacks permission",
+					zap.String("user_id", principal.UserID.String()),
+					zap.Strings("roles", principal.Roles),
+					zap.String("permission", permission),
 				)
 				respondWithError(w, http.StatusForbidden, "insufficient permissions")
 				return
@@ -30,30 +32,23 @@ func RequireAdmin(logger *zap.Logger) func(http.Handler) http.Handler {
 	}
 }
 
-// RequireRole middleware ensures the user has one of the specified roles
-func RequireRole(allowedRoles []string, logger *zap.Logger) func(http.Handler) http.Handler {
+// RequireStorePermission middleware ensures the user holds a permission at one store or
+// more. Handlers are expected to check the permission at the store a request touches.
+func RequireStorePermission(permission string, logger *zap.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			role, ok := GetUserRole(r.Context())
+			principal, ok := GetPrincipal(r.Context())
 			if !ok {
-				logger.Warn("Role not found in context")
+				logger.Warn("Principal not found in context")
 				respondWithError(w, http.StatusForbidden, "insufficient permissions")
 				return
 			}
 
-			// Check if user's role is in allowed roles
-			allowed := false
-			for _, allowedRole := range allowedRoles {
-				if role == allowedRole {
-					allowed = true
-					break
-				}
-			}
-
-			if !allowed {
-				logger.Warn("User role not authorized",
-					zap.String("role", role),
-					zap.Strings("allowed_roles", allowedRoles),
+			if !principal.HasPermissionAtAnyStore(permission) {
+				logger.Warn("User lacks permission at every store",
+					zap.String("user_id", principal.UserID.String()),
+					zap.Strings("roles", principal.Roles),
+					zap.String("permission", permission),
 				)
 				respondWithError(w, http.StatusForbidden, "insufficient permissions")
 				return
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pizza-must/internal/auth"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		want      int
+	}{
+		{"no principal", nil, http.StatusForbidden},
+		{"customer", &auth.Principal{UserID: uuid.New()}, http.StatusForbidden},
+		{"other permission", &auth.Principal{UserID: uuid.New(), Access: auth.Access{Roles: []string{"kitchen"}, Permissions: []string{"orders:transition"}}}, http.StatusForbidden},
+		{"holds permission", &auth.Principal{UserID: uuid.New(), Access: auth.Access{Roles: []string{"store_manager"}, Permissions: []string{"orders:transition", "products:write"}}}, http.StatusOK},
+	}
+
+	handler := RequirePermission("products:write", zap.NewNop())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/products", nil)
+			if tt.principal != nil {
+				req = req.WithContext(WithPrincipal(req.Context(), *tt.principal))
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestRequireStorePermission(t *testing.T) {
+	storeID := uuid.New()
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		want      int
+	}{
+		{"no principal", nil, http.StatusForbidden},
+		{"customer", &auth.Principal{UserID: uuid.New()}, http.StatusForbidden},
+		{"other store permission", &auth.Principal{UserID: uuid.New(), Access: auth.Access{StorePermissions: map[uuid.UUID][]string{storeID: {"orders:read"}}}}, http.StatusForbidden},
+		{"holds permission at a store", &auth.Principal{UserID: uuid.New(), Access: auth.Access{StorePermissions: map[uuid.UUID][]string{storeID: {"orders:transition"}}}}, http.StatusOK},
+		{"holds permission everywhere", &auth.Principal{UserID: uuid.New(), Access: auth.Access{Permissions: []string{"orders:transition"}}}, http.StatusOK},
+	}
+
+	handler := RequireStorePermission("orders:transition", zap.NewNop())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/orders", nil)
+			if tt.principal != nil {
+				req = req.WithContext(WithPrincipal(req.Context(), *tt.principal))
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
@@ -24,11 +24,12 @@ func CORSMiddleware(allowedOrigins []string, isDevelopment bool) func(http.Handl
 	})
 }
 
-// DefaultMiddlewareStack returns a stack of commonly used middleware
-func DefaultMiddlewareStack() []func(http.Handler) http.Handler {
+// DefaultMiddlewareStack returns a stack of commonly used middleware; client IPs are taken
+// from forwarding headers set by the trusted proxies only
+func DefaultMiddlewareStack(trustedProxies TrustedProxies) []func(http.Handler) http.Handler {
 	return []func(http.Handler) http.Handler{
 		middleware.RequestID,
-		middleware.RealIP,
+		RealIP(trustedProxies),
 		middleware.Recoverer,
 		middleware.Compress(5),
 	}
@@ -25,7 +25,7 @@ func RateLimitMiddleware(redisClient *redis.Client, config RateLimitConfig, logg
 			// Get client identifier (IP address or user ID if authenticated)
 			clientID := r.RemoteAddr
 			if userID, ok := GetUserID(r.Context()); ok {
-				clientID = userID
+				clientID = userID.String()
 			}
 
 			// Create Redis key
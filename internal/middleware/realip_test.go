@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP_HonoursForwardingHeadersFromTrustedProxiesOnly(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1"})
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies failed: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		realIP     string
+		want       string
+	}{
+		{"direct client spoofing headers", "198.51.100.7:4711", []string{"203.0.113.1"}, "203.0.113.2", "198.51.100.7:4711"},
+		{"direct client without headers", "198.51.100.7:4711", nil, "", "198.51.100.7:4711"},
+		{"trusted proxy", "10.1.2.3:8080", []string{"203.0.113.1"}, "", "203.0.113.1"},
+		{"trusted single address", "192.0.2.1:8080", nil, "203.0.113.2", "203.0.113.2"},
+		// The client may prepend anything; the first untrusted hop from the right is the client
+		{"spoofed leading entries", "10.1.2.3:8080", []string{"1.2.3.4, 203.0.113.1, 10.9.9.9"}, "", "203.0.113.1"},
+		{"headers across lines", "10.1.2.3:8080", []string{"1.2.3.4", "203.0.113.1"}, "", "203.0.113.1"},
+		{"only trusted hops", "10.1.2.3:8080", []string{"10.4.4.4"}, "", "10.4.4.4"},
+		{"garbage hop", "10.1.2.3:8080", []string{"203.0.113.1, not-an-ip"}, "", "10.1.2.3"},
+		{"trusted proxy without headers", "10.1.2.3:8080", nil, "", "10.1.2.3:8080"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := RealIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Errorf("expected RemoteAddr %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxies_RejectsInvalidEntries(t *testing.T) {
+	for _, value := range []string{"not-an-ip", "10.0.0.0/33"} {
+		if _, err := ParseTrustedProxies([]string{value}); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		}
+	}
+}
@@ -3,6 +3,9 @@ package middleware
 import (
 	"encoding/json"
 	"net/http"
+	"reflect"
+
+	"pizza-must/internal/domain"
 
 	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
@@ -13,6 +16,14 @@ var validate *validator.Validate
 
 func init() {
 	validate = validator.New()
+
+	// Money is validated by its amount in minor units, so gte=0 rejects negative prices
+	validate.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		if money, ok := field.Interface().(domain.Money); ok {
+			return money.Amount
+		}
+		return nil
+	}, domain.Money{})
 }
 
 // ValidateRequest validates the request body against a struct with validation tags
@@ -68,6 +79,10 @@ func getErrorMessage(e validator.FieldError) string {
 		return "This field is required"
 	case "email":
 		return "Invalid email format"
+	case "uuid":
+		return "Invalid UUID format"
+	case "url":
+		return "Invalid URL format"
 	case "min":
 		return "Value is too short"
 	case "max":
@@ -80,6 +95,8 @@ func getErrorMessage(e validator.FieldError) string {
 		return "Value must be greater than " + e.Param()
 	case "lt":
 		return "Value must be less than " + e.Param()
+	case "oneof":
+		return "Value must be one of: " + e.Param()
 	default:
 		return "Invalid value"
 	}
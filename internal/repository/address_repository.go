@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAddressNotFound = errors.New("address not found")
+)
+
+// AddressRepository defines the interface for address book data access. Every lookup is
+// scoped to the user owning the address.
+type AddressRepository interface {
+	Create(ctx context.Context, address *domain.Address) error
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Address, error)
+	FindByID(ctx context.Context, userID, id uuid.UUID) (*domain.Address, error)
+	Update(ctx context.Context, address *domain.Address) error
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+}
+
+type addressRepository struct {
+	db *sql.DB
+}
+
+// NewAddressRepository creates a new instance of AddressRepository
+func NewAddressRepository(db *sql.DB) AddressRepository {
+	return &addressRepository{db: db}
+}
+
+// Create inserts a new address
+func (r *addressRepository) Create(ctx context.Context, address *domain.Address) error {
+	query := `
+		INSERT INTO addresses (id, user_id, label, address_line1, address_line2, city, postal_code,
+		                       country, latitude, longitude, phone, instructions, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		address.ID,
+		address.UserID,
+		address.Label,
+		address.AddressLine1,
+		address.AddressLine2,
+		address.City,
+		address.PostalCode,
+		address.Country,
+		address.Latitude,
+		address.Longitude,
+		address.Phone,
+		address.Instructions,
+		address.CreatedAt,
+		address.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create address: %w", err)
+	}
+
+	return nil
+}
+
+// ListByUser retrieves a user's addresses, oldest first
+func (r *addressRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Address, error) {
+	query := `
+		SELECT ` + addressColumns + `
+		FROM addresses
+		WHERE user_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list addresses: %w", err)
+	}
+	defer rows.Close()
+
+	addresses := []*domain.Address{}
+	for rows.Next() {
+		address, err := scanAddress(rows)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating addresses: %w", err)
+	}
+
+	return addresses, nil
+}
+
+// FindByID retrieves one of a user's addresses
+func (r *addressRepository) FindByID(ctx context.Context, userID, id uuid.UUID) (*domain.Address, error) {
+	query := `
+		SELECT ` + addressColumns + `
+		FROM addresses
+		WHERE id = $1 AND user_id = $2
+	`
+
+	return scanAddress(r.db.QueryRowContext(ctx, query, id, userID))
+}
+
+// Update replaces every writable field of one of a user's addresses
+func (r *addressRepository) Update(ctx context.Context, address *domain.Address) error {
+	query := `
+		UPDATE addresses
+		SET label = $3, address_line1 = $4, address_line2 = $5, city = $6, postal_code = $7,
+		    country = $8, latitude = $9, longitude = $10, phone = $11, instructions = $12
+		WHERE id = $1 AND user_id = $2
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		address.ID,
+		address.UserID,
+		address.Label,
+		address.AddressLine1,
+		address.AddressLine2,
+		address.City,
+		address.PostalCode,
+		address.Country,
+		address.Latitude,
+		address.Longitude,
+		address.Phone,
+		address.Instructions,
+	).Scan(&address.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrAddressNotFound
+		}
+		return fmt.Errorf("failed to update address: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes one of a user's addresses. Orders keep their own snapshot of it.
+func (r *addressRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM addresses WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete address: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrAddressNotFound
+	}
+
+	return nil
+}
+
+// addressColumns lists the columns scanAddress reads, in order
+const addressColumns = `id, user_id, label, address_line1, address_line2, city, postal_code, country,
+		       latitude, longitude, phone, instructions, created_at, updated_at`
+
+// scanAddress reads a single address row selected with addressColumns
+func scanAddress(row rowScanner) (*domain.Address, error) {
+	address := &domain.Address{}
+	err := row.Scan(
+		&address.ID,
+		&address.UserID,
+		&address.Label,
+		&address.AddressLine1,
+		&address.AddressLine2,
+		&address.City,
+		&address.PostalCode,
+		&address.Country,
+		&address.Latitude,
+		&address.Longitude,
+		&address.Phone,
+		&address.Instructions,
+		&address.CreatedAt,
+		&address.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAddressNotFound
+		}
+		return nil, fmt.Errorf("failed to scan address: %w", err)
+	}
+
+	return address, nil
+}
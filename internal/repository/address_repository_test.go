@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// newTestAddress inserts an address in central Rome into a user's address book
+func newTestAddress(t *testing.T, userID uuid.UUID) *domain.Address {
+	t.Helper()
+	now := time.Now().UTC()
+	address := &domain.Address{
+		ID:           uuid.New(),
+		UserID:       userID,
+		Label:        "Home",
+		AddressLine1: "Via del Corso 10",
+		City:         "Roma",
+		PostalCode:   "00186",
+		Country:      "IT",
+		Latitude:     41.9,
+		Longitude:    12.48,
+		Phone:        "+39 06 1234567",
+		Instructions: "Ring twice",
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := NewAddressRepository(testDB).Create(context.Background(), address); err != nil {
+		t.Fatalf("Failed to create address: %v", err)
+	}
+	return address
+}
+
+func TestAddressRepository_CRUD(t *testing.T) {
+	ctx := context.Background()
+	addressRepo := NewAddressRepository(testDB)
+
+	user := newTestUser(t)
+	other := newTestUser(t)
+	address := newTestAddress(t, user.ID)
+	newTestAddress(t, user.ID)
+
+	found, err := addressRepo.FindByID(ctx, user.ID, address.ID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if found.Label != "Home" || found.Latitude != 41.9 || found.Instructions != "Ring twice" {
+		t.Errorf("Unexpected address: %+v", found)
+	}
+	// Addresses are only found through their owner
+	if _, err := addressRepo.FindByID(ctx, other.ID, address.ID); err != ErrAddressNotFound {
+		t.Errorf("Expected ErrAddressNotFound for another user, got %v", err)
+	}
+
+	addresses, err := addressRepo.ListByUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ListByUser failed: %v", err)
+	}
+	if len(addresses) != 2 || addresses[0].ID != address.ID {
+		t.Errorf("Expected both addresses, oldest first, got %+v", addresses)
+	}
+
+	address.Label = "Work"
+	address.AddressLine2 = "Scala B"
+	if err := addressRepo.Update(ctx, address); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	found, err = addressRepo.FindByID(ctx, user.ID, address.ID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if found.Label != "Work" || found.AddressLine2 != "Scala B" {
+		t.Errorf("Expected the update to be persisted, got %+v", found)
+	}
+
+	stolen := *address
+	stolen.UserID = other.ID
+	if err := addressRepo.Update(ctx, &stolen); err != ErrAddressNotFound {
+		t.Errorf("Expected ErrAddressNotFound updating another user's address, got %v", err)
+	}
+	if err := addressRepo.Delete(ctx, other.ID, address.ID); err != ErrAddressNotFound {
+		t.Errorf("Expected ErrAddressNotFound deleting another user's address, got %v", err)
+	}
+
+	if err := addressRepo.Delete(ctx, user.ID, address.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := addressRepo.FindByID(ctx, user.ID, address.ID); err != ErrAddressNotFound {
+		t.Errorf("Expected ErrAddressNotFound after delete, got %v", err)
+	}
+}
+
+func TestStoreRepository_DeliveryZones(t *testing.T) {
+	ctx := context.Background()
+	storeRepo := NewStoreRepository(testDB)
+
+	store := newTestStore(t)
+	now := time.Now().UTC()
+	radius := &domain.DeliveryZone{
+		ID:            uuid.New(),
+		StoreID:       store.ID,
+		Name:          "Centro",
+		RadiusMeters:  3000,
+		MinOrderValue: domain.Cents(1500),
+		DeliveryFee:   domain.Cents(250),
+		Active:        true,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	polygon := &domain.DeliveryZone{
+		ID:      uuid.New(),
+		StoreID: store.ID,
+		Name:    "Trastevere",
+		Polygon: []domain.GeoPoint{
+			{Latitude: 41.88, Longitude: 12.46},
+			{Latitude: 41.89, Longitude: 12.48},
+			{Latitude: 41.87, Longitude: 12.48},
+		},
+		MinOrderValue: domain.Cents(2000),
+		DeliveryFee:   domain.Cents(400),
+		Active:        true,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	for _, zone := range []*domain.DeliveryZone{radius, polygon} {
+		if err := storeRepo.CreateDeliveryZone(ctx, zone); err != nil {
+			t.Fatalf("CreateDeliveryZone failed: %v", err)
+		}
+	}
+
+	zones, err := storeRepo.ListDeliveryZones(ctx, store.ID)
+	if err != nil {
+		t.Fatalf("ListDeliveryZones failed: %v", err)
+	}
+	if len(zones) != 2 {
+		t.Fatalf("Expected 2 zones, got %d", len(zones))
+	}
+	// Radius zones have no polygon, polygon zones no radius
+	if zones[0].Name != "Centro" || zones[0].RadiusMeters != 3000 || zones[0].Polygon != nil || zones[0].DeliveryFee.Amount != 250 {
+		t.Errorf("Unexpected radius zone: %+v", zones[0])
+	}
+	if zones[1].Name != "Trastevere" || zones[1].RadiusMeters != 0 || len(zones[1].Polygon) != 3 || zones[1].Polygon[1].Longitude != 12.48 {
+		t.Errorf("Unexpected polygon zone: %+v", zones[1])
+	}
+
+	// Turning a polygon zone into a radius zone drops its polygon
+	polygon.RadiusMeters = 1500
+	polygon.Active = false
+	if err := storeRepo.UpdateDeliveryZone(ctx, polygon); err != nil {
+		t.Fatalf("UpdateDeliveryZone failed: %v", err)
+	}
+	zones, err = storeRepo.ListDeliveryZones(ctx, store.ID)
+	if err != nil {
+		t.Fatalf("ListDeliveryZones failed: %v", err)
+	}
+	if zones[1].RadiusMeters != 1500 || zones[1].Polygon != nil || zones[1].Active {
+		t.Errorf("Expected the update to be persisted, got %+v", zones[1])
+	}
+
+	// Zones are only reachable through their store
+	other := newTestStore(t)
+	misplaced := *radius
+	misplaced.StoreID = other.ID
+	if err := storeRepo.UpdateDeliveryZone(ctx, &misplaced); err != ErrDeliveryZoneNotFound {
+		t.Errorf("Expected ErrDeliveryZoneNotFound updating through another store, got %v", err)
+	}
+	if err := storeRepo.DeleteDeliveryZone(ctx, other.ID, radius.ID); err != ErrDeliveryZoneNotFound {
+		t.Errorf("Expected ErrDeliveryZoneNotFound deleting through another store, got %v", err)
+	}
+
+	if err := storeRepo.DeleteDeliveryZone(ctx, store.ID, radius.ID); err != nil {
+		t.Fatalf("DeleteDeliveryZone failed: %v", err)
+	}
+	zones, err = storeRepo.ListDeliveryZones(ctx, store.ID)
+	if err != nil {
+		t.Fatalf("ListDeliveryZones failed: %v", err)
+	}
+	if len(zones) != 1 {
+		t.Errorf("Expected 1 zone left, got %d", len(zones))
+	}
+
+	unknown := *radius
+	unknown.ID = uuid.New()
+	unknown.StoreID = uuid.New()
+	if err := storeRepo.CreateDeliveryZone(ctx, &unknown); err != ErrStoreNotFound {
+		t.Errorf("Expected ErrStoreNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,405 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCartItemNotFound   = errors.New("cart item not found")
+	ErrCartCouponNotFound = errors.New("no coupon applied to cart")
+	ErrCartStoreNotSet    = errors.New("no store selected for cart")
+)
+
+// CartRepository defines the interface for cart data access
+type CartRepository interface {
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.CartLine, error)
+	FindItem(ctx context.Context, userID, itemID uuid.UUID) (*domain.CartItem, error)
+	Upsert(ctx context.Context, item *domain.CartItem) error
+	Delete(ctx context.Context, userID, itemID uuid.UUID) error
+	Clear(ctx context.Context, userID uuid.UUID) error
+	LockForCheckoutTx(ctx context.Context, tx *sql.Tx, userID, storeID uuid.UUID) ([]*domain.CartLine, error)
+	ClearTx(ctx context.Context, tx *sql.Tx, userID uuid.UUID) error
+	DeleteByProductTx(ctx context.Context, tx *sql.Tx, productID uuid.UUID) error
+	FindCoupon(ctx context.Context, userID uuid.UUID) (uuid.UUID, error)
+	FindCouponTx(ctx context.Context, tx *sql.Tx, userID uuid.UUID) (uuid.UUID, error)
+	SetCoupon(ctx context.Context, userID, promotionID uuid.UUID) error
+	RemoveCoupon(ctx context.Context, userID uuid.UUID) error
+	FindStore(ctx context.Context, userID uuid.UUID) (uuid.UUID, error)
+	FindStoreTx(ctx context.Context, tx *sql.Tx, userID uuid.UUID) (uuid.UUID, error)
+	SetStore(ctx context.Context, userID, storeID uuid.UUID) error
+}
+
+type cartRepository struct {
+	db *sql.DB
+}
+
+// NewCartRepository creates a new instance of CartRepository
+func NewCartRepository(db *sql.DB) CartRepository {
+	return &cartRepository{db: db}
+}
+
+// ListByUser retrieves a user's cart items joined with current product data at the cart's
+// store. Products the store does not sell are listed with no stock.
+func (r *cartRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.CartLine, error) {
+	query := `
+		SELECT ci.id, ci.user_id, ci.product_id, ci.selections, ci.quantity, ci.created_at, ci.updated_at,
+		       p.name, p.category_id, COALESCE(sp.price, p.price),
+		       CASE WHEN sp.available THEN sp.stock ELSE 0 END
+		FROM cart_items ci
+		JOIN products p ON p.id = ci.product_id
+		LEFT JOIN carts c ON c.user_id = ci.user_id
+		LEFT JOIN store_products sp ON sp.store_id = c.store_id AND sp.product_id = ci.product_id
+		WHERE ci.user_id = $1
+		ORDER BY ci.created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cart items: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCartLines(rows)
+}
+
+// FindItem retrieves a single item of a user's cart
+func (r *cartRepository) FindItem(ctx context.Context, userID, itemID uuid.UUID) (*domain.CartItem, error) {
+	query := `
+		SELECT id, user_id, product_id, selections, quantity, created_at, updated_at
+		FROM cart_items
+		WHERE user_id = $1 AND id = $2
+	`
+
+	item := &domain.CartItem{}
+	var selections []byte
+	err := r.db.QueryRowContext(ctx, query, userID, itemID).Scan(
+		&item.ID,
+		&item.UserID,
+		&item.ProductID,
+		&selections,
+		&item.Quantity,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCartItemNotFound
+		}
+		return nil, fmt.Errorf("failed to find cart item: %w", err)
+	}
+
+	if err := json.Unmarshal(selections, &item.Selections); err != nil {
+		return nil, fmt.Errorf("failed to decode cart item options: %w", err)
+	}
+
+	return item, nil
+}
+
+// Upsert inserts a cart item or, if the user already has the product with the same selections
+// in their cart, replaces its quantity. The stored ID and creation time are written back to item.
+func (r *cartRepository) Upsert(ctx context.Context, item *domain.CartItem) error {
+	query := `
+		INSERT INTO cart_items (id, user_id, product_id, selections, quantity, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT ON CONSTRAINT unique_user_product_selections
+		DO UPDATE SET quantity = EXCLUDED.quantity, updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at
+	`
+
+	selections, err := encodeSelections(item.Selections)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRowContext(
+		ctx,
+		query,
+		item.ID,
+		item.UserID,
+		item.ProductID,
+		selections,
+		item.Quantity,
+		item.CreatedAt,
+		item.UpdatedAt,
+	).Scan(&item.ID, &item.CreatedAt)
+
+	if err != nil {
+		if isConstraintViolation(err, pgForeignKeyViolation, "fk_cart_items_product") {
+			return ErrProductNotFound
+		}
+		return fmt.Errorf("failed to upsert cart item: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes an item from a user's cart
+func (r *cartRepository) Delete(ctx context.Context, userID, itemID uuid.UUID) error {
+	query := `DELETE FROM cart_items WHERE user_id = $1 AND id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, userID, itemID)
+	if err != nil {
+		return fmt.Errorf("failed to delete cart item: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrCartItemNotFound
+	}
+
+	return nil
+}
+
+// Clear removes every item and the applied coupon from a user's cart
+func (r *cartRepository) Clear(ctx context.Context, userID uuid.UUID) error {
+	return clearCart(ctx, r.db, userID)
+}
+
+// LockForCheckoutTx retrieves a user's cart joined with product data at storeID inside tx,
+// locking both the cart rows and the store's stock rows of the referenced products until
+// tx ends. Stock rows are locked in product ID order so concurrent checkouts cannot deadlock.
+func (r *cartRepository) LockForCheckoutTx(ctx context.Context, tx *sql.Tx, userID, storeID uuid.UUID) ([]*domain.CartLine, error) {
+	stockQuery := `
+		SELECT sp.product_id
+		FROM store_products sp
+		WHERE sp.store_id = $2
+		  AND sp.product_id IN (SELECT product_id FROM cart_items WHERE user_id = $1)
+		ORDER BY sp.product_id
+		FOR UPDATE
+	`
+
+	// Only the row locks are wanted; executing the query takes them all without reading rows
+	if _, err := tx.ExecContext(ctx, stockQuery, userID, storeID); err != nil {
+		return nil, fmt.Errorf("failed to lock store stock: %w", err)
+	}
+
+	query := `
+		SELECT ci.id, ci.user_id, ci.product_id, ci.selections, ci.quantity, ci.created_at, ci.updated_at,
+		       p.name, p.category_id, COALESCE(sp.price, p.price),
+		       CASE WHEN sp.available THEN sp.stock ELSE 0 END
+		FROM cart_items ci
+		JOIN products p ON p.id = ci.product_id
+		LEFT JOIN store_products sp ON sp.store_id = $2 AND sp.product_id = ci.product_id
+		WHERE ci.user_id = $1
+		ORDER BY ci.product_id, ci.created_at
+		FOR UPDATE OF ci
+	`
+
+	rows, err := tx.QueryContext(ctx, query, userID, storeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock cart items: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCartLines(rows)
+}
+
+// ClearTx removes every item and the applied coupon from a user's cart inside tx
+func (r *cartRepository) ClearTx(ctx context.Context, tx *sql.Tx, userID uuid.UUID) error {
+	return clearCart(ctx, tx, userID)
+}
+
+// DeleteByProductTx removes every cart line of a product inside tx
+func (r *cartRepository) DeleteByProductTx(ctx context.Context, tx *sql.Tx, productID uuid.UUID) error {
+	query := `DELETE FROM cart_items WHERE product_id = $1`
+
+	if _, err := tx.ExecContext(ctx, query, productID); err != nil {
+		return fmt.Errorf("failed to delete cart items of product: %w", err)
+	}
+
+	return nil
+}
+
+// FindCoupon returns the ID of the promotion whose coupon is applied to a user's cart
+func (r *cartRepository) FindCoupon(ctx context.Context, userID uuid.UUID) (uuid.UUID, error) {
+	return findCartCoupon(ctx, r.db, userID)
+}
+
+// FindCouponTx returns the ID of the promotion whose coupon is applied to a user's cart inside tx
+func (r *cartRepository) FindCouponTx(ctx context.Context, tx *sql.Tx, userID uuid.UUID) (uuid.UUID, error) {
+	return findCartCoupon(ctx, tx, userID)
+}
+
+// SetCoupon applies a promotion's coupon to a user's cart, replacing any coupon already applied
+func (r *cartRepository) SetCoupon(ctx context.Context, userID, promotionID uuid.UUID) error {
+	query := `
+		INSERT INTO cart_coupons (user_id, promotion_id, applied_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id)
+		DO UPDATE SET promotion_id = EXCLUDED.promotion_id, applied_at = EXCLUDED.applied_at
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, promotionID, time.Now()); err != nil {
+		if isConstraintViolation(err, pgForeignKeyViolation, "fk_cart_coupons_promotion") {
+			return ErrPromotionNotFound
+		}
+		return fmt.Errorf("failed to apply coupon: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveCoupon removes the coupon applied to a user's cart
+func (r *cartRepository) RemoveCoupon(ctx context.Context, userID uuid.UUID) error {
+	query := `DELETE FROM cart_coupons WHERE user_id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove coupon: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrCartCouponNotFound
+	}
+
+	return nil
+}
+
+// FindStore returns the ID of the store a user's cart is filled at
+func (r *cartRepository) FindStore(ctx context.Context, userID uuid.UUID) (uuid.UUID, error) {
+	return findCartStore(ctx, r.db, userID, "")
+}
+
+// FindStoreTx returns the ID of the store a user's cart is filled at inside tx, locking the
+// selection until tx ends
+func (r *cartRepository) FindStoreTx(ctx context.Context, tx *sql.Tx, userID uuid.UUID) (uuid.UUID, error) {
+	return findCartStore(ctx, tx, userID, "FOR UPDATE")
+}
+
+// SetStore selects the store a user's cart is filled at, replacing any earlier selection
+func (r *cartRepository) SetStore(ctx context.Context, userID, storeID uuid.UUID) error {
+	query := `
+		INSERT INTO carts (user_id, store_id, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id)
+		DO UPDATE SET store_id = EXCLUDED.store_id, updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, storeID, time.Now()); err != nil {
+		if isConstraintViolation(err, pgForeignKeyViolation, "fk_carts_store") {
+			return ErrStoreNotFound
+		}
+		if isConstraintViolation(err, pgForeignKeyViolation, "fk_carts_user") {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to select cart store: %w", err)
+	}
+
+	return nil
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// clearCart removes every item and the applied coupon from a user's cart using e
+func clearCart(ctx context.Context, e execer, userID uuid.UUID) error {
+	if _, err := e.ExecContext(ctx, `DELETE FROM cart_items WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to clear cart: %w", err)
+	}
+
+	if _, err := e.ExecContext(ctx, `DELETE FROM cart_coupons WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to clear cart coupon: %w", err)
+	}
+
+	return nil
+}
+
+// findCartCoupon returns the ID of the promotion applied to a user's cart using q
+func findCartCoupon(ctx context.Context, q rowQuerier, userID uuid.UUID) (uuid.UUID, error) {
+	query := `SELECT promotion_id FROM cart_coupons WHERE user_id = $1`
+
+	var promotionID uuid.UUID
+	if err := q.QueryRowContext(ctx, query, userID).Scan(&promotionID); err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, ErrCartCouponNotFound
+		}
+		return uuid.Nil, fmt.Errorf("failed to find cart coupon: %w", err)
+	}
+
+	return promotionID, nil
+}
+
+// findCartStore returns the ID of the store selected for a user's cart using q
+func findCartStore(ctx context.Context, q rowQuerier, userID uuid.UUID, lock string) (uuid.UUID, error) {
+	query := `SELECT store_id FROM carts WHERE user_id = $1 ` + lock
+
+	var storeID uuid.UUID
+	if err := q.QueryRowContext(ctx, query, userID).Scan(&storeID); err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, ErrCartStoreNotSet
+		}
+		return uuid.Nil, fmt.Errorf("failed to find cart store: %w", err)
+	}
+
+	return storeID, nil
+}
+
+// scanCartLines reads cart item rows joined with product name, category, price and stock
+func scanCartLines(rows *sql.Rows) ([]*domain.CartLine, error) {
+	lines := []*domain.CartLine{}
+	for rows.Next() {
+		line := &domain.CartLine{}
+		var selections []byte
+		err := rows.Scan(
+			&line.ID,
+			&line.UserID,
+			&line.ProductID,
+			&selections,
+			&line.Quantity,
+			&line.CreatedAt,
+			&line.UpdatedAt,
+			&line.ProductName,
+			&line.CategoryID,
+			&line.BasePrice,
+			&line.Stock,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan cart item: %w", err)
+		}
+		if err := json.Unmarshal(selections, &line.Selections); err != nil {
+			return nil, fmt.Errorf("failed to decode cart item options: %w", err)
+		}
+		lines = append(lines, line)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating cart items: %w", err)
+	}
+
+	return lines, nil
+}
+
+// encodeSelections renders option selections as the JSON array stored in cart_items.selections
+func encodeSelections(selections []domain.OptionSelection) (string, error) {
+	if selections == nil {
+		selections = []domain.OptionSelection{}
+	}
+
+	encoded, err := json.Marshal(selections)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cart item options: %w", err)
+	}
+
+	return string(encoded), nil
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// newTestCart fills a user's cart at store with quantity of product
+func newTestCart(t *testing.T, userID, storeID, productID uuid.UUID, quantity int) *domain.CartItem {
+	t.Helper()
+	ctx := context.Background()
+	cartRepo := NewCartRepository(testDB)
+
+	if err := cartRepo.SetStore(ctx, userID, storeID); err != nil {
+		t.Fatalf("Failed to select store: %v", err)
+	}
+
+	now := time.Now().UTC()
+	item := &domain.CartItem{
+		ID:        uuid.New(),
+		UserID:    userID,
+		ProductID: productID,
+		Quantity:  quantity,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := cartRepo.Upsert(ctx, item); err != nil {
+		t.Fatalf("Failed to add cart item: %v", err)
+	}
+	return item
+}
+
+func TestCartRepository_LockForCheckoutTxLocksCartAndStock(t *testing.T) {
+	ctx := context.Background()
+	cartRepo := NewCartRepository(testDB)
+
+	user := newTestUser(t)
+	store := newTestStore(t)
+	product := newTestProduct(t, 1200)
+	stockTestProduct(t, store.ID, product.ID, 5)
+	item := newTestCart(t, user.ID, store.ID, product.ID, 2)
+
+	tx := beginTestTx(t)
+	lines, err := cartRepo.LockForCheckoutTx(ctx, tx, user.ID, store.ID)
+	if err != nil {
+		t.Fatalf("LockForCheckoutTx failed: %v", err)
+	}
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 cart line, got %d", len(lines))
+	}
+	line := lines[0]
+	if line.ID != item.ID || line.Quantity != 2 || line.Stock != 5 || line.BasePrice.Amount != 1200 {
+		t.Errorf("Unexpected cart line: id=%s quantity=%d stock=%d price=%s", line.ID, line.Quantity, line.Stock, line.BasePrice)
+	}
+
+	// A concurrent checkout cannot take the same rows while tx holds them
+	other := beginTestTx(t)
+	_, err = other.ExecContext(ctx, `SELECT 1 FROM store_products WHERE store_id = $1 AND product_id = $2 FOR UPDATE NOWAIT`, store.ID, product.ID)
+	if !isLockNotAvailable(err) {
+		t.Errorf("Expected the stock row to be locked, got %v", err)
+	}
+	_ = other.Rollback()
+
+	other = beginTestTx(t)
+	_, err = other.ExecContext(ctx, `SELECT 1 FROM cart_items WHERE id = $1 FOR UPDATE NOWAIT`, item.ID)
+	if !isLockNotAvailable(err) {
+		t.Errorf("Expected the cart row to be locked, got %v", err)
+	}
+	_ = other.Rollback()
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Failed to roll back: %v", err)
+	}
+
+	// Released once the checkout ends
+	other = beginTestTx(t)
+	if _, err := other.ExecContext(ctx, `SELECT 1 FROM store_products WHERE store_id = $1 AND product_id = $2 FOR UPDATE NOWAIT`, store.ID, product.ID); err != nil {
+		t.Errorf("Expected the stock row to be released, got %v", err)
+	}
+}
+
+func TestCartRepository_LockForCheckoutTxPricesAtStore(t *testing.T) {
+	ctx := context.Background()
+	cartRepo := NewCartRepository(testDB)
+
+	user := newTestUser(t)
+	store := newTestStore(t)
+	listed := newTestProduct(t, 1000)
+	unlisted := newTestProduct(t, 800)
+
+	storePrice := domain.Cents(1100)
+	err := NewStoreRepository(testDB).SaveProduct(ctx, &domain.StoreProduct{
+		StoreID:   store.ID,
+		ProductID: listed.ID,
+		Available: true,
+		Stock:     3,
+		Price:     &storePrice,
+		UpdatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to stock product: %v", err)
+	}
+	newTestCart(t, user.ID, store.ID, listed.ID, 1)
+	newTestCart(t, user.ID, store.ID, unlisted.ID, 1)
+
+	tx := beginTestTx(t)
+	lines, err := cartRepo.LockForCheckoutTx(ctx, tx, user.ID, store.ID)
+	if err != nil {
+		t.Fatalf("LockForCheckoutTx failed: %v", err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 cart lines, got %d", len(lines))
+	}
+
+	for _, line := range lines {
+		switch line.ProductID {
+		case listed.ID:
+			// The store's price overrides the catalog price
+			if line.BasePrice.Amount != 1100 || line.Stock != 3 {
+				t.Errorf("Listed product: expected price 1100 and stock 3, got %s and %d", line.BasePrice, line.Stock)
+			}
+		case unlisted.ID:
+			// Products the store does not sell keep the catalog price and have no stock
+			if line.BasePrice.Amount != 800 || line.Stock != 0 {
+				t.Errorf("Unlisted product: expected price 800 and stock 0, got %s and %d", line.BasePrice, line.Stock)
+			}
+		default:
+			t.Errorf("Unexpected product %s", line.ProductID)
+		}
+	}
+}
+
+func TestCartRepository_ClearTxRemovesItems(t *testing.T) {
+	ctx := context.Background()
+	cartRepo := NewCartRepository(testDB)
+
+	user := newTestUser(t)
+	store := newTestStore(t)
+	product := newTestProduct(t, 900)
+	stockTestProduct(t, store.ID, product.ID, 10)
+	newTestCart(t, user.ID, store.ID, product.ID, 1)
+
+	tx := beginTestTx(t)
+	if err := cartRepo.ClearTx(ctx, tx, user.ID); err != nil {
+		t.Fatalf("ClearTx failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	if count := countRows(t, "cart_items", "user_id = $1", user.ID); count != 0 {
+		t.Errorf("Expected an empty cart, got %d items", count)
+	}
+}
+
+func TestCartRepository_SetStoreRejectsUnknownStore(t *testing.T) {
+	user := newTestUser(t)
+
+	if err := NewCartRepository(testDB).SetStore(context.Background(), user.ID, uuid.New()); err != ErrStoreNotFound {
+		t.Errorf("Expected ErrStoreNotFound, got %v", err)
+	}
+}
+
+func TestProductRepository_AdjustStockTx(t *testing.T) {
+	ctx := context.Background()
+	productRepo := NewProductRepository(testDB)
+
+	store := newTestStore(t)
+	product := newTestProduct(t, 1000)
+	stockTestProduct(t, store.ID, product.ID, 5)
+
+	tx := beginTestTx(t)
+	if err := productRepo.AdjustStockTx(ctx, tx, store.ID, product.ID, -3); err != nil {
+		t.Fatalf("AdjustStockTx failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	if stock := storeStock(t, store.ID, product.ID); stock != 2 {
+		t.Errorf("Expected stock 2 after decrement, got %d", stock)
+	}
+
+	// The stock check constraint refuses to go below zero
+	tx = beginTestTx(t)
+	if err := productRepo.AdjustStockTx(ctx, tx, store.ID, product.ID, -3); err == nil {
+		t.Error("Expected an error when stock would go negative")
+	}
+	_ = tx.Rollback()
+	if stock := storeStock(t, store.ID, product.ID); stock != 2 {
+		t.Errorf("Expected stock to stay 2, got %d", stock)
+	}
+
+	tx = beginTestTx(t)
+	if err := productRepo.AdjustStockTx(ctx, tx, store.ID, uuid.New(), 1); err != ErrProductNotFound {
+		t.Errorf("Expected ErrProductNotFound, got %v", err)
+	}
+}
@@ -14,6 +14,7 @@ import (
 var (
 	ErrCategoryNotFound      = errors.New("category not found")
 	ErrCategoryAlreadyExists = errors.New("category with this name already exists")
+	ErrCategoryHasProducts   = errors.New("category still has products")
 )
 
 // CategoryRepository defines the interface for category data access
@@ -21,6 +22,8 @@ type CategoryRepository interface {
 	Create(ctx context.Context, category *domain.Category) error
 	List(ctx context.Context) ([]*domain.Category, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*domain.Category, error)
+	Update(ctx context.Context, category *domain.Category) error
+	Delete(ctx context.Context, id uuid.UUID) error
 }
 
 type categoryRepository struct {
@@ -50,8 +53,7 @@ func (r *categoryRepository) Create(ctx context.Context, category *domain.Catego
 
 	if err != nil {
 		// Check for unique constraint violation (duplicate name)
-		if err.Error() == "pq: duplicate key value violates unique constraint \"categories_name_key\"" ||
-			err.Error() == "ERROR: duplicate key value violates unique constraint \"categories_name_key\" (SQLSTATE 23505)" {
+		if isConstraintViolation(err, pgUniqueViolation, "categories_name_key") {
 			return ErrCategoryAlreadyExists
 		}
 		return fmt.Errorf("failed to create category: %w", err)
@@ -121,3 +123,57 @@ func (r *categoryRepository) FindByID(ctx context.Context, id uuid.UUID) (*domai
 
 	return category, nil
 }
+
+// Update updates an existing category using parameterized queries
+func (r *categoryRepository) Update(ctx context.Context, category *domain.Category) error {
+	query := `
+		UPDATE categories
+		SET name = $2, description = $3
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, category.ID, category.Name, category.Description)
+	if err != nil {
+		// Check for unique constraint violation (duplicate name)
+		if isConstraintViolation(err, pgUniqueViolation, "categories_name_key") {
+			return ErrCategoryAlreadyExists
+		}
+		return fmt.Errorf("failed to update category: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrCategoryNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a category using parameterized queries.
+// Categories referenced by products cannot be deleted (ON DELETE RESTRICT).
+func (r *categoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM categories WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		if isConstraintViolation(err, pgForeignKeyViolation, "fk_products_category") {
+			return ErrCategoryHasProducts
+		}
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrCategoryNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+var ErrEmailVerificationTokenNotFound = errors.New("email verification token not found")
+
+// EmailVerificationTokenRepository defines the interface for email verification token data access
+type EmailVerificationTokenRepository interface {
+	Create(ctx context.Context, token *domain.EmailVerificationToken) error
+	Consume(ctx context.Context, tokenHash string, now time.Time) (*domain.EmailVerificationToken, error)
+	FindLatestForUser(ctx context.Context, userID uuid.UUID) (*domain.EmailVerificationToken, error)
+	InvalidateForUser(ctx context.Context, userID uuid.UUID, now time.Time) error
+}
+
+type emailVerificationTokenRepository struct {
+	db *sql.DB
+}
+
+// NewEmailVerificationTokenRepository creates a new instance of EmailVerificationTokenRepository
+func NewEmailVerificationTokenRepository(db *sql.DB) EmailVerificationTokenRepository {
+	return &emailVerificationTokenRepository{db: db}
+}
+
+// Create inserts a new email verification token
+func (r *emailVerificationTokenRepository) Create(ctx context.Context, token *domain.EmailVerificationToken) error {
+	query := `
+		INSERT INTO email_verification_tokens (id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		token.ID,
+		token.UserID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create email verification token: %w", err)
+	}
+
+	return nil
+}
+
+// Consume marks the unused, unexpired token with the given digest as used at now and returns it.
+// Unknown, used and expired tokens are all reported as ErrEmailVerificationTokenNotFound.
+func (r *emailVerificationTokenRepository) Consume(ctx context.Context, tokenHash string, now time.Time) (*domain.EmailVerificationToken, error) {
+	query := `
+		UPDATE email_verification_tokens
+		SET used_at = $2
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+		RETURNING id, user_id, token_hash, expires_at, created_at, used_at
+	`
+
+	token, err := scanEmailVerificationToken(r.db.QueryRowContext(ctx, query, tokenHash, now))
+	if err != nil {
+		if err == ErrEmailVerificationTokenNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to consume email verification token: %w", err)
+	}
+
+	return token, nil
+}
+
+// FindLatestForUser retrieves the most recently issued verification token of a user
+func (r *emailVerificationTokenRepository) FindLatestForUser(ctx context.Context, userID uuid.UUID) (*domain.EmailVerificationToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, expires_at, created_at, used_at
+		FROM email_verification_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	token, err := scanEmailVerificationToken(r.db.QueryRowContext(ctx, query, userID))
+	if err != nil {
+		if err == ErrEmailVerificationTokenNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find email verification token: %w", err)
+	}
+
+	return token, nil
+}
+
+// InvalidateForUser marks every unused verification token of a user as used at now
+func (r *emailVerificationTokenRepository) InvalidateForUser(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	query := `UPDATE email_verification_tokens SET used_at = $2 WHERE user_id = $1 AND used_at IS NULL`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, now); err != nil {
+		return fmt.Errorf("failed to invalidate email verification tokens: %w", err)
+	}
+
+	return nil
+}
+
+// scanEmailVerificationToken reads a single email verification token row
+func scanEmailVerificationToken(row rowScanner) (*domain.EmailVerificationToken, error) {
+	token := &domain.EmailVerificationToken{}
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&token.UsedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrEmailVerificationTokenNotFound
+		}
+		return nil, err
+	}
+
+	return token, nil
+}
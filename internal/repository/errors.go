@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgreSQL error codes used to translate constraint violations into domain errors
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+// isConstraintViolation reports whether err is a PostgreSQL error with the given
+// SQLSTATE code raised by the named constraint
+func isConstraintViolation(err error, code, constraint string) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == code && pgErr.ConstraintName == constraint
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// newTestUser inserts a customer with a unique email address
+func newTestUser(t *testing.T) *domain.User {
+	t.Helper()
+	now := time.Now().UTC()
+	user := &domain.User{
+		ID:           uuid.New(),
+		Email:        uuid.NewString() + "@example.com",
+		PasswordHash: "hash",
+		FirstName:    "Test",
+		LastName:     "User",
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := NewUserRepository(testDB).Create(context.Background(), user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	return user
+}
+
+// newTestStore inserts an active store in Rome with the default scheduling settings
+func newTestStore(t *testing.T) *domain.Store {
+	t.Helper()
+	now := time.Now().UTC()
+	store := &domain.Store{
+		ID:           uuid.New(),
+		Name:         "Test store",
+		AddressLine1: "Piazza Venezia 1",
+		City:         "Roma",
+		PostalCode:   "00186",
+		Country:      "IT",
+		Latitude:     41.9028,
+		Longitude:    12.4964,
+		Timezone:     "Europe/Rome",
+		Active:       true,
+		SlotMinutes:  domain.DefaultSlotMinutes,
+		SlotCapacity: domain.DefaultSlotCapacity,
+		LeadMinutes:  domain.DefaultLeadMinutes,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := NewStoreRepository(testDB).Create(context.Background(), store); err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	return store
+}
+
+// newTestProduct inserts a product priced at price cents in a new category
+func newTestProduct(t *testing.T, price int64) *domain.Product {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	category := &domain.Category{ID: uuid.New(), Name: "Category " + uuid.NewString(), CreatedAt: now}
+	if err := NewCategoryRepository(testDB).Create(ctx, category); err != nil {
+		t.Fatalf("Failed to create category: %v", err)
+	}
+
+	product := &domain.Product{
+		ID:         uuid.New(),
+		Name:       "Pizza " + uuid.NewString()[:8],
+		Price:      domain.Cents(price),
+		CategoryID: category.ID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := NewProductRepository(testDB).Create(ctx, product); err != nil {
+		t.Fatalf("Failed to create product: %v", err)
+	}
+	return product
+}
+
+// stockTestProduct lists a product at a store with the given stock and catalog price
+func stockTestProduct(t *testing.T, storeID, productID uuid.UUID, stock int) {
+	t.Helper()
+	listing := &domain.StoreProduct{
+		StoreID:   storeID,
+		ProductID: productID,
+		Available: true,
+		Stock:     stock,
+		UpdatedAt: time.Now().UTC(),
+	}
+	if err := NewStoreRepository(testDB).SaveProduct(context.Background(), listing); err != nil {
+		t.Fatalf("Failed to stock product: %v", err)
+	}
+}
+
+// storeStock reads a product's stock at a store
+func storeStock(t *testing.T, storeID, productID uuid.UUID) int {
+	t.Helper()
+	var stock int
+	err := testDB.QueryRow(`SELECT stock FROM store_products WHERE store_id = $1 AND product_id = $2`, storeID, productID).Scan(&stock)
+	if err != nil {
+		t.Fatalf("Failed to read stock: %v", err)
+	}
+	return stock
+}
+
+// countRows counts the rows of table matching the condition on $1
+func countRows(t *testing.T, table, condition string, arg interface{}) int {
+	t.Helper()
+	var count int
+	if err := testDB.QueryRow(`SELECT COUNT(*) FROM `+table+` WHERE `+condition, arg).Scan(&count); err != nil {
+		t.Fatalf("Failed to count %s: %v", table, err)
+	}
+	return count
+}
+
+// beginTestTx begins a transaction that is rolled back when the test ends, unless the
+// test has already finished it
+func beginTestTx(t *testing.T) *sql.Tx {
+	t.Helper()
+	tx, err := testDB.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	t.Cleanup(func() { _ = tx.Rollback() })
+	return tx
+}
+
+// isLockNotAvailable reports whether err is PostgreSQL's lock_not_available error,
+// raised by NOWAIT when a row is locked by another transaction
+func isLockNotAvailable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "55P03"
+}
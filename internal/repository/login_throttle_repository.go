@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"pizza-must/internal/domain"
+)
+
+var ErrLoginThrottleNotFound = errors.New("login throttle not found")
+
+// LoginThrottleRepository defines the interface for failed login attempt data access
+type LoginThrottleRepository interface {
+	Find(ctx context.Context, kind, subject string) (*domain.LoginThrottle, error)
+	RecordFailure(ctx context.Context, kind, subject string, now, windowStart time.Time) (*domain.LoginThrottle, error)
+	Lock(ctx context.Context, kind, subject string, until time.Time) error
+	Clear(ctx context.Context, kind, subject string) error
+}
+
+type loginThrottleRepository struct {
+	db *sql.DB
+}
+
+// NewLoginThrottleRepository creates a new instance of LoginThrottleRepository
+func NewLoginThrottleRepository(db *sql.DB) LoginThrottleRepository {
+	return &loginThrottleRepository{db: db}
+}
+
+// Find retrieves the throttle for an email address or client IP
+func (r *loginThrottleRepository) Find(ctx context.Context, kind, subject string) (*domain.LoginThrottle, error) {
+	query := `
+		SELECT kind, subject, failures, last_failure_at, locked_until
+		FROM login_throttles
+		WHERE kind = $1 AND subject = $2
+	`
+
+	throttle, err := scanLoginThrottle(r.db.QueryRowContext(ctx, query, kind, subject))
+	if err != nil {
+		if err == ErrLoginThrottleNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find login throttle: %w", err)
+	}
+
+	return throttle, nil
+}
+
+// RecordFailure counts a failed login at now and returns the updated throttle. Failures
+// before windowStart are forgotten, so the count restarts after a quiet period. The count
+// is incremented in a single statement, so concurrent failures are all counted.
+func (r *loginThrottleRepository) RecordFailure(ctx context.Context, kind, subject string, now, windowStart time.Time) (*domain.LoginThrottle, error) {
+	query := `
+		INSERT INTO login_throttles (kind, subject, failures, last_failure_at)
+		VALUES ($1, $2, 1, $3)
+		ON CONFLICT (kind, subject) DO UPDATE
+		SET failures = CASE
+				WHEN login_throttles.last_failure_at < $4 THEN 1
+				ELSE login_throttles.failures + 1
+			END,
+			last_failure_at = $3
+		RETURNING kind, subject, failures, last_failure_at, locked_until
+	`
+
+	throttle, err := scanLoginThrottle(r.db.QueryRowContext(ctx, query, kind, subject, now, windowStart))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record login failure: %w", err)
+	}
+
+	return throttle, nil
+}
+
+// Lock refuses logins for an email address or client IP until the given time
+func (r *loginThrottleRepository) Lock(ctx context.Context, kind, subject string, until time.Time) error {
+	query := `UPDATE login_throttles SET locked_until = $3 WHERE kind = $1 AND subject = $2`
+
+	result, err := r.db.ExecContext(ctx, query, kind, subject, until)
+	if err != nil {
+		return fmt.Errorf("failed to lock login: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrLoginThrottleNotFound
+	}
+
+	return nil
+}
+
+// Clear forgets the failed logins of an email address or client IP and lifts its lock
+func (r *loginThrottleRepository) Clear(ctx context.Context, kind, subject string) error {
+	query := `DELETE FROM login_throttles WHERE kind = $1 AND subject = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, kind, subject); err != nil {
+		return fmt.Errorf("failed to clear login throttle: %w", err)
+	}
+
+	return nil
+}
+
+// scanLoginThrottle reads a single login throttle row
+func scanLoginThrottle(row rowScanner) (*domain.LoginThrottle, error) {
+	throttle := &domain.LoginThrottle{}
+	err := row.Scan(
+		&throttle.Kind,
+		&throttle.Subject,
+		&throttle.Failures,
+		&throttle.LastFailureAt,
+		&throttle.LockedUntil,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrLoginThrottleNotFound
+		}
+		return nil, err
+	}
+
+	return throttle, nil
+}
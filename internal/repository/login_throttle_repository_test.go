@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestLoginThrottleRepository_RecordFailureCountsWithinWindow(t *testing.T) {
+	ctx := context.Background()
+	throttleRepo := NewLoginThrottleRepository(testDB)
+	subject := uuid.NewString() + "@example.com"
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	if _, err := throttleRepo.Find(ctx, domain.LoginThrottleEmail, subject); err != ErrLoginThrottleNotFound {
+		t.Errorf("Expected ErrLoginThrottleNotFound before any failure, got %v", err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		throttle, err := throttleRepo.RecordFailure(ctx, domain.LoginThrottleEmail, subject, now, now.Add(-15*time.Minute))
+		if err != nil {
+			t.Fatalf("RecordFailure failed: %v", err)
+		}
+		if throttle.Failures != i || throttle.LockedUntil != nil {
+			t.Errorf("Expected %d failures and no lock, got %+v", i, throttle)
+		}
+	}
+
+	// The same subject is counted separately per kind
+	throttle, err := throttleRepo.RecordFailure(ctx, domain.LoginThrottleIP, subject, now, now.Add(-15*time.Minute))
+	if err != nil {
+		t.Fatalf("RecordFailure failed: %v", err)
+	}
+	if throttle.Failures != 1 {
+		t.Errorf("Expected a separate IP count, got %d", throttle.Failures)
+	}
+	throttle, err = throttleRepo.RecordFailure(ctx, domain.LoginThrottleResetIP, subject, now, now.Add(-15*time.Minute))
+	if err != nil {
+		t.Fatalf("RecordFailure failed for password reset requests: %v", err)
+	}
+	if throttle.Failures != 1 {
+		t.Errorf("Expected a separate password reset count, got %d", throttle.Failures)
+	}
+
+	// Failures older than the window are forgotten
+	later := now.Add(time.Hour)
+	throttle, err = throttleRepo.RecordFailure(ctx, domain.LoginThrottleEmail, subject, later, later.Add(-15*time.Minute))
+	if err != nil {
+		t.Fatalf("RecordFailure failed: %v", err)
+	}
+	if throttle.Failures != 1 || !throttle.LastFailureAt.Equal(later) {
+		t.Errorf("Expected the count to restart at %v, got %+v", later, throttle)
+	}
+}
+
+func TestLoginThrottleRepository_RecordFailureIsAtomic(t *testing.T) {
+	ctx := context.Background()
+	throttleRepo := NewLoginThrottleRepository(testDB)
+	subject := "198.51.100." + uuid.NewString()[:3]
+	now := time.Now().UTC()
+
+	const attempts = 10
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := throttleRepo.RecordFailure(ctx, domain.LoginThrottleIP, subject, now, now.Add(-time.Minute)); err != nil {
+				t.Errorf("RecordFailure failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	throttle, err := throttleRepo.Find(ctx, domain.LoginThrottleIP, subject)
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if throttle.Failures != attempts {
+		t.Errorf("Expected all %d concurrent failures to be counted, got %d", attempts, throttle.Failures)
+	}
+}
+
+func TestLoginThrottleRepository_LockAndClear(t *testing.T) {
+	ctx := context.Background()
+	throttleRepo := NewLoginThrottleRepository(testDB)
+	subject := uuid.NewString() + "@example.com"
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	if err := throttleRepo.Lock(ctx, domain.LoginThrottleEmail, subject, now.Add(time.Minute)); err != ErrLoginThrottleNotFound {
+		t.Errorf("Expected ErrLoginThrottleNotFound locking an unknown subject, got %v", err)
+	}
+
+	if _, err := throttleRepo.RecordFailure(ctx, domain.LoginThrottleEmail, subject, now, now.Add(-time.Minute)); err != nil {
+		t.Fatalf("RecordFailure failed: %v", err)
+	}
+	until := now.Add(15 * time.Minute)
+	if err := throttleRepo.Lock(ctx, domain.LoginThrottleEmail, subject, until); err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+
+	throttle, err := throttleRepo.Find(ctx, domain.LoginThrottleEmail, subject)
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if throttle.LockedUntil == nil || !throttle.LockedUntil.Equal(until) || !throttle.IsLocked(now) {
+		t.Errorf("Expected a lock until %v, got %v", until, throttle.LockedUntil)
+	}
+
+	if err := throttleRepo.Clear(ctx, domain.LoginThrottleEmail, subject); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if _, err := throttleRepo.Find(ctx, domain.LoginThrottleEmail, subject); err != ErrLoginThrottleNotFound {
+		t.Errorf("Expected ErrLoginThrottleNotFound after clearing, got %v", err)
+	}
+}
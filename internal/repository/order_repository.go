@@ -0,0 +1,557 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrOrderNotFound     = errors.New("order not found")
+	ErrOrderItemNotFound = errors.New("order item not found")
+)
+
+// OrderFilter narrows an order listing; zero-valued fields are ignored.
+// CreatedFrom is inclusive and CreatedTo is exclusive.
+type OrderFilter struct {
+	UserID      uuid.UUID
+	Status      string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+}
+
+// OrderRepository defines the interface for order data access
+type OrderRepository interface {
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.Order, error)
+	List(ctx context.Context, filter OrderFilter, page, pageSize int) ([]*domain.Order, int, error)
+	ListStatusHistory(ctx context.Context, orderID uuid.UUID) ([]*domain.OrderStatusChange, error)
+	CreateTx(ctx context.Context, tx *sql.Tx, order *domain.Order) error
+	FindByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*domain.Order, error)
+	UpdateStatusTx(ctx context.Context, tx *sql.Tx, order *domain.Order) error
+	AddStatusChangeTx(ctx context.Context, tx *sql.Tx, change *domain.OrderStatusChange) error
+	ListScheduledTimes(ctx context.Context, storeID uuid.UUID, from, to time.Time) ([]time.Time, error)
+	CountScheduledTx(ctx context.Context, tx *sql.Tx, storeID uuid.UUID, from, to time.Time) (int, error)
+	ListKitchenQueue(ctx context.Context) ([]*domain.Order, error)
+	UpdateItemKitchenStatusTx(ctx context.Context, tx *sql.Tx, item *domain.OrderItem) error
+}
+
+// rowQuerier is implemented by both *sql.DB and *sql.Tx
+type rowQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type orderRepository struct {
+	db *sql.DB
+}
+
+// NewOrderRepository creates a new instance of OrderRepository
+func NewOrderRepository(db *sql.DB) OrderRepository {
+	return &orderRepository{db: db}
+}
+
+// CreateTx inserts an order and all of its items inside tx
+func (r *orderRepository) CreateTx(ctx context.Context, tx *sql.Tx, order *domain.Order) error {
+	orderQuery := `
+		INSERT INTO orders (id, user_id, store_id, status, subtotal, discount, coupon_code, total,
+		                    fulfillment_type, delivery_address, scheduled_for, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, $10, $11, $12, $13)
+	`
+
+	var deliveryAddress interface{}
+	if order.DeliveryAddress != nil {
+		encoded, err := json.Marshal(order.DeliveryAddress)
+		if err != nil {
+			return fmt.Errorf("failed to encode delivery address: %w", err)
+		}
+		deliveryAddress = string(encoded)
+	}
+
+	_, err := tx.ExecContext(
+		ctx,
+		orderQuery,
+		order.ID,
+		order.UserID,
+		order.StoreID,
+		order.Status,
+		order.Subtotal,
+		order.Discount,
+		order.CouponCode,
+		order.Total,
+		order.Fulfillment,
+		deliveryAddress,
+		order.ScheduledFor,
+		order.CreatedAt,
+		order.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create order: %w", err)
+	}
+
+	itemQuery := `
+		INSERT INTO order_items (id, order_id, type, product_id, product_name, options, price, quantity, subtotal, kitchen_status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''))
+	`
+
+	for _, item := range order.Items {
+		// Charge lines have no product
+		productID := uuid.NullUUID{UUID: item.ProductID, Valid: item.Type == domain.OrderItemProduct}
+		options := item.Options
+		if options == nil {
+			options = []domain.SelectedOption{}
+		}
+		encodedOptions, err := json.Marshal(options)
+		if err != nil {
+			return fmt.Errorf("failed to encode order item options: %w", err)
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			itemQuery,
+			item.ID,
+			item.OrderID,
+			item.Type,
+			productID,
+			item.ProductName,
+			string(encodedOptions),
+			item.Price,
+			item.Quantity,
+			item.Subtotal,
+			item.KitchenStatus,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create order item: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// FindByID retrieves an order with its items
+func (r *orderRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Order, error) {
+	return r.findByID(ctx, r.db, id, "")
+}
+
+// FindByIDForUpdateTx retrieves an order with its items inside tx, locking the order row
+// until the transaction ends
+func (r *orderRepository) FindByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*domain.Order, error) {
+	return r.findByID(ctx, tx, id, "FOR UPDATE")
+}
+
+// List retrieves orders matching filter, newest first, with pagination.
+// Items and history are not loaded.
+func (r *orderRepository) List(ctx context.Context, filter OrderFilter, page, pageSize int) ([]*domain.Order, int, error) {
+	// Build the WHERE clause
+	conditions := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.UserID != uuid.Nil {
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", argIndex))
+		args = append(args, filter.UserID)
+		argIndex++
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", argIndex))
+		args = append(args, filter.Status)
+		argIndex++
+	}
+
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", argIndex))
+		args = append(args, *filter.CreatedFrom)
+		argIndex++
+	}
+
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", argIndex))
+		args = append(args, *filter.CreatedTo)
+		argIndex++
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// Count total orders
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM orders %s", whereClause)
+	var total int
+	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count orders: %w", err)
+	}
+
+	// Calculate offset
+	offset := (page - 1) * pageSize
+
+	query := fmt.Sprintf(`
+		SELECT `+orderColumns+`
+		FROM orders
+		%s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, argIndex, argIndex+1)
+
+	args = append(args, pageSize, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []*domain.Order{}
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		orders = append(orders, order)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating orders: %w", err)
+	}
+
+	return orders, total, nil
+}
+
+// ListStatusHistory retrieves every status change of an order, oldest first
+func (r *orderRepository) ListStatusHistory(ctx context.Context, orderID uuid.UUID) ([]*domain.OrderStatusChange, error) {
+	query := `
+		SELECT id, order_id, COALESCE(from_status, ''), to_status, actor_id, COALESCE(reason, ''), created_at
+		FROM order_status_history
+		WHERE order_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order status history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []*domain.OrderStatusChange{}
+	for rows.Next() {
+		change := &domain.OrderStatusChange{}
+		var actorID uuid.NullUUID
+		err := rows.Scan(
+			&change.ID,
+			&change.OrderID,
+			&change.FromStatus,
+			&change.ToStatus,
+			&actorID,
+			&change.Reason,
+			&change.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order status change: %w", err)
+		}
+		if actorID.Valid {
+			change.ActorID = &actorID.UUID
+		}
+		history = append(history, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating order status history: %w", err)
+	}
+
+	return history, nil
+}
+
+// UpdateStatusTx persists the order's status and updated_at inside tx
+func (r *orderRepository) UpdateStatusTx(ctx context.Context, tx *sql.Tx, order *domain.Order) error {
+	query := `
+		UPDATE orders
+		SET status = $2, updated_at = $3
+		WHERE id = $1
+	`
+
+	result, err := tx.ExecContext(ctx, query, order.ID, order.Status, order.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrOrderNotFound
+	}
+
+	return nil
+}
+
+// AddStatusChangeTx appends an entry to the order's status history inside tx
+func (r *orderRepository) AddStatusChangeTx(ctx context.Context, tx *sql.Tx, change *domain.OrderStatusChange) error {
+	query := `
+		INSERT INTO order_status_history (id, order_id, from_status, to_status, actor_id, reason, created_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, NULLIF($6, ''), $7)
+	`
+
+	_, err := tx.ExecContext(
+		ctx,
+		query,
+		change.ID,
+		change.OrderID,
+		change.FromStatus,
+		change.ToStatus,
+		change.ActorID,
+		change.Reason,
+		change.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record order status change: %w", err)
+	}
+
+	return nil
+}
+
+// ListScheduledTimes retrieves the times orders are scheduled for at a store within
+// [from, to); cancelled and refunded orders no longer hold their slot
+func (r *orderRepository) ListScheduledTimes(ctx context.Context, storeID uuid.UUID, from, to time.Time) ([]time.Time, error) {
+	query := `
+		SELECT scheduled_for
+		FROM orders
+		WHERE store_id = $1 AND scheduled_for >= $2 AND scheduled_for < $3 AND status NOT IN ($4, $5)
+		ORDER BY scheduled_for
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, storeID, from.UTC(), to.UTC(), domain.OrderStatusCancelled, domain.OrderStatusRefunded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled orders: %w", err)
+	}
+	defer rows.Close()
+
+	times := []time.Time{}
+	for rows.Next() {
+		var scheduledFor time.Time
+		if err := rows.Scan(&scheduledFor); err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled order: %w", err)
+		}
+		times = append(times, scheduledFor)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating scheduled orders: %w", err)
+	}
+
+	return times, nil
+}
+
+// CountScheduledTx counts the orders scheduled at a store within [from, to) inside tx
+func (r *orderRepository) CountScheduledTx(ctx context.Context, tx *sql.Tx, storeID uuid.UUID, from, to time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM orders
+		WHERE store_id = $1 AND scheduled_for >= $2 AND scheduled_for < $3 AND status NOT IN ($4, $5)
+	`
+
+	var count int
+	err := tx.QueryRowContext(ctx, query, storeID, from.UTC(), to.UTC(), domain.OrderStatusCancelled, domain.OrderStatusRefunded).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count scheduled orders: %w", err)
+	}
+	return count, nil
+}
+
+// ListKitchenQueue retrieves the confirmed and preparing orders of every store with their
+// items, oldest first
+func (r *orderRepository) ListKitchenQueue(ctx context.Context) ([]*domain.Order, error) {
+	query := `
+		SELECT ` + orderColumns + `
+		FROM orders
+		WHERE status IN ($1, $2)
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, domain.OrderStatusConfirmed, domain.OrderStatusPreparing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list kitchen queue: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []*domain.Order{}
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating kitchen queue: %w", err)
+	}
+	rows.Close()
+
+	for _, order := range orders {
+		items, err := r.listItems(ctx, r.db, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		order.Items = items
+	}
+
+	return orders, nil
+}
+
+// UpdateItemKitchenStatusTx persists the kitchen status of a product line inside tx
+func (r *orderRepository) UpdateItemKitchenStatusTx(ctx context.Context, tx *sql.Tx, item *domain.OrderItem) error {
+	query := `
+		UPDATE order_items
+		SET kitchen_status = $3
+		WHERE id = $1 AND order_id = $2 AND type = $4
+	`
+
+	result, err := tx.ExecContext(ctx, query, item.ID, item.OrderID, item.KitchenStatus, domain.OrderItemProduct)
+	if err != nil {
+		return fmt.Errorf("failed to update order item kitchen status: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrOrderItemNotFound
+	}
+
+	return nil
+}
+
+// findByID retrieves an order with its items using q; lockClause is appended to the
+// order query so transactional callers can lock the row
+func (r *orderRepository) findByID(ctx context.Context, q rowQuerier, id uuid.UUID, lockClause string) (*domain.Order, error) {
+	query := `
+		SELECT ` + orderColumns + `
+		FROM orders
+		WHERE id = $1
+	` + lockClause
+
+	order, err := scanOrder(q.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := r.listItems(ctx, q, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	order.Items = items
+
+	return order, nil
+}
+
+// orderColumns lists the order columns in the order scanOrder reads them
+const orderColumns = `id, user_id, store_id, status, subtotal, discount, COALESCE(coupon_code, ''), total,
+		       fulfillment_type, delivery_address, scheduled_for, created_at, updated_at`
+
+// scanOrder reads a single order row selected with orderColumns; items are not loaded
+func scanOrder(row rowScanner) (*domain.Order, error) {
+	order := &domain.Order{}
+	var (
+		deliveryAddress []byte
+		scheduledFor    sql.NullTime
+	)
+	err := row.Scan(
+		&order.ID,
+		&order.UserID,
+		&order.StoreID,
+		&order.Status,
+		&order.Subtotal,
+		&order.Discount,
+		&order.CouponCode,
+		&order.Total,
+		&order.Fulfillment,
+		&deliveryAddress,
+		&scheduledFor,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to scan order: %w", err)
+	}
+
+	if deliveryAddress != nil {
+		order.DeliveryAddress = &domain.Address{}
+		if err := json.Unmarshal(deliveryAddress, order.DeliveryAddress); err != nil {
+			return nil, fmt.Errorf("failed to decode delivery address: %w", err)
+		}
+	}
+	if scheduledFor.Valid {
+		order.ScheduledFor = &scheduledFor.Time
+	}
+
+	return order, nil
+}
+
+// listItems retrieves the items of an order
+func (r *orderRepository) listItems(ctx context.Context, q rowQuerier, orderID uuid.UUID) ([]*domain.OrderItem, error) {
+	query := `
+		SELECT id, order_id, type, product_id, product_name, options, price, quantity, subtotal,
+		       COALESCE(kitchen_status, '')
+		FROM order_items
+		WHERE order_id = $1
+		ORDER BY type = 'product' DESC, product_name ASC
+	`
+
+	rows, err := q.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []*domain.OrderItem{}
+	for rows.Next() {
+		item := &domain.OrderItem{}
+		var (
+			productID uuid.NullUUID
+			options   []byte
+		)
+		err := rows.Scan(
+			&item.ID,
+			&item.OrderID,
+			&item.Type,
+			&productID,
+			&item.ProductName,
+			&options,
+			&item.Price,
+			&item.Quantity,
+			&item.Subtotal,
+			&item.KitchenStatus,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order item: %w", err)
+		}
+		item.ProductID = productID.UUID
+		if err := json.Unmarshal(options, &item.Options); err != nil {
+			return nil, fmt.Errorf("failed to decode order item options: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating order items: %w", err)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,309 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// newTestOrder builds a confirmed delivery order of one product line plus a delivery fee
+func newTestOrder(userID, storeID, productID uuid.UUID) *domain.Order {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	orderID := uuid.New()
+	return &domain.Order{
+		ID:          orderID,
+		UserID:      userID,
+		StoreID:     storeID,
+		Status:      domain.OrderStatusConfirmed,
+		Subtotal:    domain.Cents(2400),
+		Discount:    domain.Cents(0),
+		Total:       domain.Cents(2650),
+		Fulfillment: domain.FulfillmentDelivery,
+		DeliveryAddress: &domain.Address{
+			AddressLine1: "Via del Corso 10",
+			City:         "Roma",
+			PostalCode:   "00186",
+			Country:      "IT",
+			Latitude:     41.9,
+			Longitude:    12.48,
+		},
+		Items: []*domain.OrderItem{
+			{
+				ID:            uuid.New(),
+				OrderID:       orderID,
+				Type:          domain.OrderItemProduct,
+				ProductID:     productID,
+				ProductName:   "Margherita",
+				Price:         domain.Cents(1200),
+				Quantity:      2,
+				Subtotal:      domain.Cents(2400),
+				KitchenStatus: domain.KitchenItemQueued,
+			},
+			{
+				ID:          uuid.New(),
+				OrderID:     orderID,
+				Type:        domain.OrderItemDeliveryFee,
+				ProductName: "Delivery",
+				Price:       domain.Cents(250),
+				Quantity:    1,
+				Subtotal:    domain.Cents(250),
+			},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// createTestOrder commits order
+func createTestOrder(t *testing.T, order *domain.Order) {
+	t.Helper()
+	tx := beginTestTx(t)
+	if err := NewOrderRepository(testDB).CreateTx(context.Background(), tx, order); err != nil {
+		t.Fatalf("CreateTx failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+}
+
+func TestOrderRepository_CreateTxRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := NewOrderRepository(testDB)
+
+	user := newTestUser(t)
+	store := newTestStore(t)
+	product := newTestProduct(t, 1200)
+	order := newTestOrder(user.ID, store.ID, product.ID)
+	createTestOrder(t, order)
+
+	found, err := orderRepo.FindByID(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+
+	if found.Status != order.Status || found.StoreID != store.ID || found.Total.Amount != 2650 {
+		t.Errorf("Unexpected order: status=%s store=%s total=%s", found.Status, found.StoreID, found.Total)
+	}
+	// A NULL coupon code and scheduled time read back as empty
+	if found.CouponCode != "" {
+		t.Errorf("Expected no coupon code, got %q", found.CouponCode)
+	}
+	if found.ScheduledFor != nil {
+		t.Errorf("Expected no scheduled time, got %v", found.ScheduledFor)
+	}
+	if found.DeliveryAddress == nil || found.DeliveryAddress.AddressLine1 != "Via del Corso 10" {
+		t.Errorf("Expected the delivery address snapshot, got %+v", found.DeliveryAddress)
+	}
+
+	if len(found.Items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(found.Items))
+	}
+	// Product lines are listed before charge lines
+	productLine, feeLine := found.Items[0], found.Items[1]
+	if productLine.ProductID != product.ID || productLine.KitchenStatus != domain.KitchenItemQueued || productLine.Quantity != 2 {
+		t.Errorf("Unexpected product line: %+v", productLine)
+	}
+	if productLine.Options == nil {
+		t.Error("Expected product line options to decode to an empty list")
+	}
+	if feeLine.Type != domain.OrderItemDeliveryFee || feeLine.ProductID != uuid.Nil || feeLine.KitchenStatus != "" {
+		t.Errorf("Unexpected fee line: %+v", feeLine)
+	}
+	if count := countRows(t, "order_items", "order_id = $1 AND type = 'delivery_fee' AND product_id IS NULL AND kitchen_status IS NULL", order.ID); count != 1 {
+		t.Errorf("Expected the fee line to be stored without product or kitchen status, got %d rows", count)
+	}
+}
+
+func TestOrderRepository_FindByIDNotFound(t *testing.T) {
+	if _, err := NewOrderRepository(testDB).FindByID(context.Background(), uuid.New()); err != ErrOrderNotFound {
+		t.Errorf("Expected ErrOrderNotFound, got %v", err)
+	}
+}
+
+func TestOrderRepository_FindByIDForUpdateTxLocksOrder(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := NewOrderRepository(testDB)
+
+	user := newTestUser(t)
+	store := newTestStore(t)
+	product := newTestProduct(t, 1200)
+	order := newTestOrder(user.ID, store.ID, product.ID)
+	createTestOrder(t, order)
+
+	tx := beginTestTx(t)
+	if _, err := orderRepo.FindByIDForUpdateTx(ctx, tx, order.ID); err != nil {
+		t.Fatalf("FindByIDForUpdateTx failed: %v", err)
+	}
+
+	other := beginTestTx(t)
+	_, err := other.ExecContext(ctx, `SELECT 1 FROM orders WHERE id = $1 FOR UPDATE NOWAIT`, order.ID)
+	if !isLockNotAvailable(err) {
+		t.Errorf("Expected the order row to be locked, got %v", err)
+	}
+}
+
+func TestOrderRepository_StatusChangeAndHistory(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := NewOrderRepository(testDB)
+
+	user := newTestUser(t)
+	store := newTestStore(t)
+	product := newTestProduct(t, 1200)
+	order := newTestOrder(user.ID, store.ID, product.ID)
+	createTestOrder(t, order)
+
+	order.Status = domain.OrderStatusPreparing
+	order.UpdatedAt = time.Now().UTC()
+	change := &domain.OrderStatusChange{
+		ID:         uuid.New(),
+		OrderID:    order.ID,
+		FromStatus: domain.OrderStatusConfirmed,
+		ToStatus:   domain.OrderStatusPreparing,
+		ActorID:    &user.ID,
+		CreatedAt:  order.UpdatedAt,
+	}
+
+	tx := beginTestTx(t)
+	if err := orderRepo.UpdateStatusTx(ctx, tx, order); err != nil {
+		t.Fatalf("UpdateStatusTx failed: %v", err)
+	}
+	if err := orderRepo.AddStatusChangeTx(ctx, tx, change); err != nil {
+		t.Fatalf("AddStatusChangeTx failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	found, err := orderRepo.FindByID(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if found.Status != domain.OrderStatusPreparing {
+		t.Errorf("Expected status %s, got %s", domain.OrderStatusPreparing, found.Status)
+	}
+
+	history, err := orderRepo.ListStatusHistory(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("ListStatusHistory failed: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("Expected 1 history entry, got %d", len(history))
+	}
+	if history[0].FromStatus != domain.OrderStatusConfirmed || history[0].Reason != "" ||
+		history[0].ActorID == nil || *history[0].ActorID != user.ID {
+		t.Errorf("Unexpected history entry: %+v", history[0])
+	}
+
+	tx = beginTestTx(t)
+	missing := &domain.Order{ID: uuid.New(), Status: domain.OrderStatusReady, UpdatedAt: time.Now().UTC()}
+	if err := orderRepo.UpdateStatusTx(ctx, tx, missing); err != ErrOrderNotFound {
+		t.Errorf("Expected ErrOrderNotFound, got %v", err)
+	}
+}
+
+func TestOrderRepository_ScheduledOrdersHoldSlots(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := NewOrderRepository(testDB)
+
+	user := newTestUser(t)
+	store := newTestStore(t)
+	product := newTestProduct(t, 1200)
+	slot := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Hour)
+
+	scheduled := newTestOrder(user.ID, store.ID, product.ID)
+	scheduled.ScheduledFor = &slot
+	createTestOrder(t, scheduled)
+
+	cancelled := newTestOrder(user.ID, store.ID, product.ID)
+	cancelled.Status = domain.OrderStatusCancelled
+	cancelled.ScheduledFor = &slot
+	createTestOrder(t, cancelled)
+
+	times, err := orderRepo.ListScheduledTimes(ctx, store.ID, slot, slot.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("ListScheduledTimes failed: %v", err)
+	}
+	if len(times) != 1 || !times[0].Equal(slot) {
+		t.Errorf("Expected only the live order's slot, got %v", times)
+	}
+
+	tx := beginTestTx(t)
+	count, err := orderRepo.CountScheduledTx(ctx, tx, store.ID, slot, slot.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("CountScheduledTx failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 scheduled order, got %d", count)
+	}
+
+	found, err := orderRepo.FindByID(ctx, scheduled.ID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if found.ScheduledFor == nil || !found.ScheduledFor.Equal(slot) {
+		t.Errorf("Expected scheduled time %v, got %v", slot, found.ScheduledFor)
+	}
+}
+
+func TestOrderRepository_KitchenQueueAndItemStatus(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := NewOrderRepository(testDB)
+
+	user := newTestUser(t)
+	store := newTestStore(t)
+	product := newTestProduct(t, 1200)
+	order := newTestOrder(user.ID, store.ID, product.ID)
+	createTestOrder(t, order)
+
+	pending := newTestOrder(user.ID, store.ID, product.ID)
+	pending.Status = domain.OrderStatusPending
+	createTestOrder(t, pending)
+
+	queue, err := orderRepo.ListKitchenQueue(ctx)
+	if err != nil {
+		t.Fatalf("ListKitchenQueue failed: %v", err)
+	}
+	var queued *domain.Order
+	for _, o := range queue {
+		if o.ID == pending.ID {
+			t.Error("Pending orders must not be in the kitchen queue")
+		}
+		if o.ID == order.ID {
+			queued = o
+		}
+	}
+	if queued == nil {
+		t.Fatal("Expected the confirmed order in the kitchen queue")
+	}
+	if len(queued.Items) != 2 {
+		t.Errorf("Expected queued order with its 2 items, got %d", len(queued.Items))
+	}
+
+	item := order.Items[0]
+	item.KitchenStatus = domain.KitchenItemPreparing
+	tx := beginTestTx(t)
+	if err := orderRepo.UpdateItemKitchenStatusTx(ctx, tx, item); err != nil {
+		t.Fatalf("UpdateItemKitchenStatusTx failed: %v", err)
+	}
+	// Charge lines have no kitchen status
+	fee := order.Items[1]
+	fee.KitchenStatus = domain.KitchenItemReady
+	if err := orderRepo.UpdateItemKitchenStatusTx(ctx, tx, fee); err != ErrOrderItemNotFound {
+		t.Errorf("Expected ErrOrderItemNotFound for the fee line, got %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	found, err := orderRepo.FindByID(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if found.Items[0].KitchenStatus != domain.KitchenItemPreparing {
+		t.Errorf("Expected kitchen status %s, got %s", domain.KitchenItemPreparing, found.Items[0].KitchenStatus)
+	}
+}
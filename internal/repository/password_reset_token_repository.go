@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+var ErrPasswordResetTokenNotFound = errors.New("password reset token not found")
+
+// PasswordResetTokenRepository defines the interface for password reset token data access
+type PasswordResetTokenRepository interface {
+	Create(ctx context.Context, token *domain.PasswordResetToken) error
+	Consume(ctx context.Context, tokenHash string, now time.Time) (*domain.PasswordResetToken, error)
+	FindLatestForUser(ctx context.Context, userID uuid.UUID) (*domain.PasswordResetToken, error)
+	InvalidateForUser(ctx context.Context, userID uuid.UUID, now time.Time) error
+}
+
+type passwordResetTokenRepository struct {
+	db *sql.DB
+}
+
+// NewPasswordResetTokenRepository creates a new instance of PasswordResetTokenRepository
+func NewPasswordResetTokenRepository(db *sql.DB) PasswordResetTokenRepository {
+	return &passwordResetTokenRepository{db: db}
+}
+
+// Create inserts a new password reset token
+func (r *passwordResetTokenRepository) Create(ctx context.Context, token *domain.PasswordResetToken) error {
+	query := `
+		INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		token.ID,
+		token.UserID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+
+	return nil
+}
+
+// Consume marks the unused, unexpired token with the given digest as used at now and returns it.
+// The check and the update are a single statement, so a token can only be consumed once even
+// when it is presented concurrently. Unknown, used and expired tokens are all reported as
+// ErrPasswordResetTokenNotFound.
+func (r *passwordResetTokenRepository) Consume(ctx context.Context, tokenHash string, now time.Time) (*domain.PasswordResetToken, error) {
+	query := `
+		UPDATE password_reset_tokens
+		SET used_at = $2
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+		RETURNING id, user_id, token_hash, expires_at, created_at, used_at
+	`
+
+	token := &domain.PasswordResetToken{}
+	err := r.db.QueryRowContext(ctx, query, tokenHash, now).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&token.UsedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrPasswordResetTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to consume password reset token: %w", err)
+	}
+
+	return token, nil
+}
+
+// FindLatestForUser retrieves the most recently issued reset token of a user
+func (r *passwordResetTokenRepository) FindLatestForUser(ctx context.Context, userID uuid.UUID) (*domain.PasswordResetToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, expires_at, created_at, used_at
+		FROM password_reset_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	token := &domain.PasswordResetToken{}
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&token.UsedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrPasswordResetTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to find password reset token: %w", err)
+	}
+
+	return token, nil
+}
+
+// InvalidateForUser marks every unused reset token of a user as used at now
+func (r *passwordResetTokenRepository) InvalidateForUser(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	query := `UPDATE password_reset_tokens SET used_at = $2 WHERE user_id = $1 AND used_at IS NULL`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, now); err != nil {
+		return fmt.Errorf("failed to invalidate password reset tokens: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestPasswordResetTokenRepository_FindLatestForUser(t *testing.T) {
+	ctx := context.Background()
+	tokenRepo := NewPasswordResetTokenRepository(testDB)
+
+	user := newTestUser(t)
+	if _, err := tokenRepo.FindLatestForUser(ctx, user.ID); err != ErrPasswordResetTokenNotFound {
+		t.Errorf("Expected ErrPasswordResetTokenNotFound before any request, got %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	var latest *domain.PasswordResetToken
+	for i := 2; i >= 0; i-- {
+		latest = &domain.PasswordResetToken{
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			TokenHash: uuid.NewString(),
+			ExpiresAt: now.Add(30 * time.Minute),
+			CreatedAt: now.Add(-time.Duration(i) * time.Minute),
+		}
+		if err := tokenRepo.Create(ctx, latest); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	// Used tokens still count, so redeeming a link does not lift the cooldown
+	if err := tokenRepo.InvalidateForUser(ctx, user.ID, now); err != nil {
+		t.Fatalf("InvalidateForUser failed: %v", err)
+	}
+	found, err := tokenRepo.FindLatestForUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("FindLatestForUser failed: %v", err)
+	}
+	if found.ID != latest.ID || !found.CreatedAt.Equal(latest.CreatedAt) || found.UsedAt == nil {
+		t.Errorf("Expected the most recent token, got %+v", found)
+	}
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrDuplicateOptionName = errors.New("option group or option name already exists")
+)
+
+// ProductOptionRepository defines the interface for product option group data access
+type ProductOptionRepository interface {
+	ListByProduct(ctx context.Context, productID uuid.UUID) ([]*domain.OptionGroup, error)
+	ReplaceForProductTx(ctx context.Context, tx *sql.Tx, productID uuid.UUID, groups []*domain.OptionGroup) error
+}
+
+type productOptionRepository struct {
+	db *sql.DB
+}
+
+// NewProductOptionRepository creates a new instance of ProductOptionRepository
+func NewProductOptionRepository(db *sql.DB) ProductOptionRepository {
+	return &productOptionRepository{db: db}
+}
+
+// ListByProduct retrieves a product's option groups with their options, both in display order
+func (r *productOptionRepository) ListByProduct(ctx context.Context, productID uuid.UUID) ([]*domain.OptionGroup, error) {
+	query := `
+		SELECT g.id, g.product_id, g.name, g.min_select, g.max_select, g.allow_halves, g.position, g.created_at,
+		       o.id, o.name, o.price_delta, o.position
+		FROM product_option_groups g
+		LEFT JOIN product_options o ON o.group_id = g.id
+		WHERE g.product_id = $1
+		ORDER BY g.position ASC, o.position ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list option groups: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []*domain.OptionGroup{}
+	var current *domain.OptionGroup
+	for rows.Next() {
+		group := &domain.OptionGroup{Options: []*domain.Option{}}
+		var (
+			optionID       uuid.NullUUID
+			optionName     sql.NullString
+			optionDelta    sql.Null[domain.Money]
+			optionPosition sql.NullInt64
+		)
+		err := rows.Scan(
+			&group.ID,
+			&group.ProductID,
+			&group.Name,
+			&group.MinSelect,
+			&group.MaxSelect,
+			&group.AllowHalves,
+			&group.Position,
+			&group.CreatedAt,
+			&optionID,
+			&optionName,
+			&optionDelta,
+			&optionPosition,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan option group: %w", err)
+		}
+
+		if current == nil || current.ID != group.ID {
+			current = group
+			groups = append(groups, current)
+		}
+
+		if optionID.Valid {
+			current.Options = append(current.Options, &domain.Option{
+				ID:         optionID.UUID,
+				GroupID:    current.ID,
+				Name:       optionName.String,
+				PriceDelta: optionDelta.V,
+				Position:   int(optionPosition.Int64),
+			})
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating option groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// ReplaceForProductTx deletes a product's option groups and inserts groups in their place inside tx
+func (r *productOptionRepository) ReplaceForProductTx(ctx context.Context, tx *sql.Tx, productID uuid.UUID, groups []*domain.OptionGroup) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM product_option_groups WHERE product_id = $1`, productID); err != nil {
+		return fmt.Errorf("failed to delete option groups: %w", err)
+	}
+
+	groupQuery := `
+		INSERT INTO product_option_groups (id, product_id, name, min_select, max_select, allow_halves, position, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	optionQuery := `
+		INSERT INTO product_options (id, group_id, name, price_delta, position)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	for _, group := range groups {
+		_, err := tx.ExecContext(
+			ctx,
+			groupQuery,
+			group.ID,
+			productID,
+			group.Name,
+			group.MinSelect,
+			group.MaxSelect,
+			group.AllowHalves,
+			group.Position,
+			group.CreatedAt,
+		)
+		if err != nil {
+			if isConstraintViolation(err, pgUniqueViolation, "unique_product_option_group_name") {
+				return ErrDuplicateOptionName
+			}
+			if isConstraintViolation(err, pgForeignKeyViolation, "fk_product_option_groups_product") {
+				return ErrProductNotFound
+			}
+			return fmt.Errorf("failed to create option group: %w", err)
+		}
+
+		for _, option := range group.Options {
+			_, err := tx.ExecContext(
+				ctx,
+				optionQuery,
+				option.ID,
+				group.ID,
+				option.Name,
+				option.PriceDelta,
+				option.Position,
+			)
+			if err != nil {
+				if isConstraintViolation(err, pgUniqueViolation, "unique_product_option_name") {
+					return ErrDuplicateOptionName
+				}
+				return fmt.Errorf("failed to create option: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -13,7 +13,9 @@ import (
 )
 
 var (
-	ErrProductNotFound = errors.New("product not found")
+	ErrProductNotFound    = errors.New("product not found")
+	ErrProductHasOrders   = errors.New("product is referenced by existing orders")
+	ErrProductUnavailable = errors.New("product is not available at this store")
 )
 
 // SortOrder represents the sort direction
@@ -30,8 +32,10 @@ type ProductRepository interface {
 	Update(ctx context.Context, product *domain.Product) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	FindByID(ctx context.Context, id uuid.UUID) (*domain.Product, error)
-	List(ctx context.Context, categoryID *uuid.UUID, page, pageSize int, sortBy string, sortOrder SortOrder) ([]*domain.Product, int, error)
-	Search(ctx context.Context, query string, page, pageSize int) ([]*domain.Product, int, error)
+	FindForStore(ctx context.Context, storeID, id uuid.UUID) (*domain.Product, error)
+	List(ctx context.Context, categoryID, storeID *uuid.UUID, page, pageSize int, sortBy string, sortOrder SortOrder) ([]*domain.Product, int, error)
+	Search(ctx context.Context, query string, storeID *uuid.UUID, page, pageSize int) ([]*domain.Product, int, error)
+	AdjustStockTx(ctx context.Context, tx *sql.Tx, storeID, id uuid.UUID, delta int) error
 }
 
 type productRepository struct {
@@ -46,8 +50,8 @@ func NewProductRepository(db *sql.DB) ProductRepository {
 // Create inserts a new product into the database using parameterized queries
 func (r *productRepository) Create(ctx context.Context, product *domain.Product) error {
 	query := `
-		INSERT INTO products (id, name, description, price, category_id, image_url, stock, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO products (id, name, description, price, category_id, image_url, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
 	_, err := r.db.ExecContext(
@@ -59,12 +63,14 @@ func (r *productRepository) Create(ctx context.Context, product *domain.Product)
 		product.Price,
 		product.CategoryID,
 		product.ImageURL,
-		product.Stock,
 		product.CreatedAt,
 		product.UpdatedAt,
 	)
 
 	if err != nil {
+		if isConstraintViolation(err, pgForeignKeyViolation, "fk_products_category") {
+			return ErrCategoryNotFound
+		}
 		return fmt.Errorf("failed to create product: %w", err)
 	}
 
@@ -75,8 +81,8 @@ func (r *productRepository) Create(ctx context.Context, product *domain.Product)
 func (r *productRepository) Update(ctx context.Context, product *domain.Product) error {
 	query := `
 		UPDATE products
-		SET name = $2, description = $3, price = $4, category_id = $5, 
-		    image_url = $6, stock = $7, updated_at = $8
+		SET name = $2, description = $3, price = $4, category_id = $5,
+		    image_url = $6, updated_at = $7
 		WHERE id = $1
 	`
 
@@ -89,11 +95,13 @@ func (r *productRepository) Update(ctx context.Context, product *domain.Product)
 		product.Price,
 		product.CategoryID,
 		product.ImageURL,
-		product.Stock,
 		product.UpdatedAt,
 	)
 
 	if err != nil {
+		if isConstraintViolation(err, pgForeignKeyViolation, "fk_products_category") {
+			return ErrCategoryNotFound
+		}
 		return fmt.Errorf("failed to update product: %w", err)
 	}
 
@@ -115,6 +123,10 @@ func (r *productRepository) Delete(ctx context.Context, id uuid.UUID) error {
 
 	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
+		// Order items keep a RESTRICT reference to the product they snapshot
+		if isConstraintViolation(err, pgForeignKeyViolation, "fk_order_items_product") {
+			return ErrProductHasOrders
+		}
 		return fmt.Errorf("failed to delete product: %w", err)
 	}
 
@@ -130,16 +142,39 @@ func (r *productRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-// FindByID retrieves a product by ID using parameterized queries
+// FindByID retrieves a catalog product by ID at its catalog price
 func (r *productRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
 	query := `
-		SELECT id, name, description, price, category_id, image_url, stock, created_at, updated_at
-		FROM products
-		WHERE id = $1
+		SELECT p.id, p.name, p.description, p.price, p.category_id, p.image_url, 0, p.created_at, p.updated_at
+		FROM products p
+		WHERE p.id = $1
+	`
+
+	product, err := scanProduct(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrProductNotFound
+		}
+		return nil, fmt.Errorf("failed to find product by ID: %w", err)
+	}
+
+	return product, nil
+}
+
+// FindForStore retrieves a product with the store's price and stock. It returns
+// ErrProductUnavailable when the product exists but the store does not sell it.
+func (r *productRepository) FindForStore(ctx context.Context, storeID, id uuid.UUID) (*domain.Product, error) {
+	query := `
+		SELECT p.id, p.name, p.description, COALESCE(sp.price, p.price), p.category_id, p.image_url,
+		       COALESCE(sp.stock, 0), p.created_at, p.updated_at, COALESCE(sp.available, FALSE)
+		FROM products p
+		LEFT JOIN store_products sp ON sp.product_id = p.id AND sp.store_id = $1
+		WHERE p.id = $2
 	`
 
 	product := &domain.Product{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	var available bool
+	err := r.db.QueryRowContext(ctx, query, storeID, id).Scan(
 		&product.ID,
 		&product.Name,
 		&product.Description,
@@ -149,20 +184,42 @@ func (r *productRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain
 		&product.Stock,
 		&product.CreatedAt,
 		&product.UpdatedAt,
+		&available,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrProductNotFound
 		}
-		return nil, fmt.Errorf("failed to find product by ID: %w", err)
+		return nil, fmt.Errorf("failed to find product for store: %w", err)
+	}
+
+	if !available {
+		return nil, ErrProductUnavailable
 	}
 
 	return product, nil
 }
 
-// List retrieves products with optional category filtering, pagination, and sorting
-func (r *productRepository) List(ctx context.Context, categoryID *uuid.UUID, page, pageSize int, sortBy string, sortOrder SortOrder) ([]*domain.Product, int, error) {
+// productSource returns the columns and FROM clause selecting products. With a store, only
+// products available there are selected, priced and stocked at that store, and the store
+// ID is bound to the placeholder $argIndex.
+func productSource(storeID *uuid.UUID, argIndex int) (string, string) {
+	if storeID == nil {
+		return `p.id, p.name, p.description, p.price AS price, p.category_id, p.image_url, 0 AS stock,
+		        p.created_at AS created_at, p.updated_at`,
+			`FROM products p`
+	}
+
+	return `p.id, p.name, p.description, COALESCE(sp.price, p.price) AS price, p.category_id, p.image_url,
+	        sp.stock AS stock, p.created_at AS created_at, p.updated_at`,
+		fmt.Sprintf(`FROM products p
+		JOIN store_products sp ON sp.product_id = p.id AND sp.store_id = $%d AND sp.available`, argIndex)
+}
+
+// List retrieves products with optional category filtering, pagination, and sorting.
+// When storeID is set only the store's menu is listed, with the store's prices and stock.
+func (r *productRepository) List(ctx context.Context, categoryID, storeID *uuid.UUID, page, pageSize int, sortBy string, sortOrder SortOrder) ([]*domain.Product, int, error) {
 	// Validate sort field to prevent SQL injection
 	validSortFields := map[string]bool{
 		"name":       true,
@@ -180,19 +237,25 @@ func (r *productRepository) List(ctx context.Context, categoryID *uuid.UUID, pag
 		sortOrder = SortOrderDesc // Default sort order
 	}
 
-	// Build the WHERE clause
-	whereClause := ""
 	args := []interface{}{}
 	argIndex := 1
 
+	columns, from := productSource(storeID, argIndex)
+	if storeID != nil {
+		args = append(args, *storeID)
+		argIndex++
+	}
+
+	// Build the WHERE clause
+	whereClause := ""
 	if categoryID != nil {
-		whereClause = fmt.Sprintf("WHERE category_id = $%d", argIndex)
+		whereClause = fmt.Sprintf("WHERE p.category_id = $%d", argIndex)
 		args = append(args, *categoryID)
 		argIndex++
 	}
 
 	// Count total products
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM products %s", whereClause)
+	countQuery := fmt.Sprintf("SELECT COUNT(*) %s %s", from, whereClause)
 	var total int
 	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
@@ -202,14 +265,14 @@ func (r *productRepository) List(ctx context.Context, categoryID *uuid.UUID, pag
 	// Calculate offset
 	offset := (page - 1) * pageSize
 
-	// Build the main query with sorting and pagination
+	// Build the main query with sorting and pagination; sort fields name output columns
 	query := fmt.Sprintf(`
-		SELECT id, name, description, price, category_id, image_url, stock, created_at, updated_at
-		FROM products
+		SELECT %s
+		%s
 		%s
-		ORDER BY %s %s
+		ORDER BY %s %s, p.id
 		LIMIT $%d OFFSET $%d
-	`, whereClause, sortBy, sortOrder, argIndex, argIndex+1)
+	`, columns, from, whereClause, sortBy, sortOrder, argIndex, argIndex+1)
 
 	args = append(args, pageSize, offset)
 
@@ -219,51 +282,37 @@ func (r *productRepository) List(ctx context.Context, categoryID *uuid.UUID, pag
 	}
 	defer rows.Close()
 
-	products := []*domain.Product{}
-	for rows.Next() {
-		product := &domain.Product{}
-		err := rows.Scan(
-			&product.ID,
-			&product.Name,
-			&product.Description,
-			&product.Price,
-			&product.CategoryID,
-			&product.ImageURL,
-			&product.Stock,
-			&product.CreatedAt,
-			&product.UpdatedAt,
-		)
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan product: %w", err)
-		}
-		products = append(products, product)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error iterating products: %w", err)
+	products, err := scanProducts(rows)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	return products, total, nil
 }
 
-// Search searches for products by name or description with pagination
-func (r *productRepository) Search(ctx context.Context, query string, page, pageSize int) ([]*domain.Product, int, error) {
+// Search searches for products by name or description with pagination.
+// When storeID is set only the store's menu is searched.
+func (r *productRepository) Search(ctx context.Context, query string, storeID *uuid.UUID, page, pageSize int) ([]*domain.Product, int, error) {
 	// If query is empty, return all products
 	if strings.TrimSpace(query) == "" {
-		return r.List(ctx, nil, page, pageSize, "created_at", SortOrderDesc)
+		return r.List(ctx, nil, storeID, page, pageSize, "created_at", SortOrderDesc)
 	}
 
 	// Use ILIKE for case-insensitive search
-	searchPattern := "%" + query + "%"
+	args := []interface{}{"%" + query + "%"}
+	columns, from := productSource(storeID, 2)
+	if storeID != nil {
+		args = append(args, *storeID)
+	}
 
 	// Count total matching products
-	countQuery := `
+	countQuery := fmt.Sprintf(`
 		SELECT COUNT(*)
-		FROM products
-		WHERE name ILIKE $1 OR description ILIKE $1
-	`
+		%s
+		WHERE p.name ILIKE $1 OR p.description ILIKE $1
+	`, from)
 	var total int
-	err := r.db.QueryRowContext(ctx, countQuery, searchPattern).Scan(&total)
+	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count search results: %w", err)
 	}
@@ -272,43 +321,90 @@ func (r *productRepository) Search(ctx context.Context, query string, page, page
 	offset := (page - 1) * pageSize
 
 	// Search products
-	searchQuery := `
-		SELECT id, name, description, price, category_id, image_url, stock, created_at, updated_at
-		FROM products
-		WHERE name ILIKE $1 OR description ILIKE $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
-	`
+	searchQuery := fmt.Sprintf(`
+		SELECT %s
+		%s
+		WHERE p.name ILIKE $1 OR p.description ILIKE $1
+		ORDER BY created_at DESC, p.id
+		LIMIT $%d OFFSET $%d
+	`, columns, from, len(args)+1, len(args)+2)
+
+	args = append(args, pageSize, offset)
 
-	rows, err := r.db.QueryContext(ctx, searchQuery, searchPattern, pageSize, offset)
+	rows, err := r.db.QueryContext(ctx, searchQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to search products: %w", err)
 	}
 	defer rows.Close()
 
+	products, err := scanProducts(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return products, total, nil
+}
+
+// AdjustStockTx adds delta (negative to decrement) to a product's stock at a store inside tx.
+// Callers are expected to hold a row lock and to have checked that stock stays non-negative.
+func (r *productRepository) AdjustStockTx(ctx context.Context, tx *sql.Tx, storeID, id uuid.UUID, delta int) error {
+	query := `
+		UPDATE store_products
+		SET stock = stock + $3, updated_at = CURRENT_TIMESTAMP
+		WHERE store_id = $1 AND product_id = $2
+	`
+
+	result, err := tx.ExecContext(ctx, query, storeID, id, delta)
+	if err != nil {
+		return fmt.Errorf("failed to adjust product stock: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrProductNotFound
+	}
+
+	return nil
+}
+
+// scanProduct reads a product row selected with the columns of productSource
+func scanProduct(row rowScanner) (*domain.Product, error) {
+	product := &domain.Product{}
+	err := row.Scan(
+		&product.ID,
+		&product.Name,
+		&product.Description,
+		&product.Price,
+		&product.CategoryID,
+		&product.ImageURL,
+		&product.Stock,
+		&product.CreatedAt,
+		&product.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// scanProducts reads every product row selected with the columns of productSource
+func scanProducts(rows *sql.Rows) ([]*domain.Product, error) {
 	products := []*domain.Product{}
 	for rows.Next() {
-		product := &domain.Product{}
-		err := rows.Scan(
-			&product.ID,
-			&product.Name,
-			&product.Description,
-			&product.Price,
-			&product.CategoryID,
-			&product.ImageURL,
-			&product.Stock,
-			&product.CreatedAt,
-			&product.UpdatedAt,
-		)
+		product, err := scanProduct(rows)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan product: %w", err)
+			return nil, fmt.Errorf("failed to scan product: %w", err)
 		}
 		products = append(products, product)
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error iterating search results: %w", err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating products: %w", err)
 	}
 
-	return products, total, nil
+	return products, nil
 }
@@ -16,44 +16,13 @@ import (
 // Feature: ordering-platform, Property 10: Product creation preserves attributes
 // Validates: Requirements 4.1
 func TestProperty_ProductCreationPreservesAttributes(t *testing.T) {
-	// Ensure tables exist
-	_, err := testDB.Exec(`
-		CREATE TABLE IF NOT EXISTS categories (
-			id UUID PRIMARY KEY,
-			name VARCHAR(100) UNIQUE NOT NULL,
-			description TEXT,
-			created_at TIMESTAMP NOT NULL
-		)
-	`)
-	if err != nil {
-		t.Fatalf("Failed to create categories table: %v", err)
-	}
-
-	_, err = testDB.Exec(`
-		CREATE TABLE IF NOT EXISTS products (
-			id UUID PRIMARY KEY,
-			name VARCHAR(255) NOT NULL,
-			description TEXT,
-			price DECIMAL(10, 2) NOT NULL,
-			category_id UUID NOT NULL,
-			image_url VARCHAR(500),
-			stock INTEGER NOT NULL DEFAULT 0,
-			created_at TIMESTAMP NOT NULL,
-			updated_at TIMESTAMP NOT NULL,
-			CONSTRAINT fk_products_category FOREIGN KEY (category_id) REFERENCES categories(id)
-		)
-	`)
-	if err != nil {
-		t.Fatalf("Failed to create products table: %v", err)
-	}
-
 	productRepo := NewProductRepository(testDB)
 	categoryRepo := NewCategoryRepository(testDB)
 
 	properties := gopter.NewProperties(nil)
 
 	properties.Property("creating and retrieving a product preserves all attributes", prop.ForAll(
-		func(name string, description string, price float64, imageURL string, stock int) bool {
+		func(name string, description string, price int64, imageURL string) bool {
 			ctx := context.Background()
 
 			// Create a category first
@@ -74,10 +43,9 @@ func TestProperty_ProductCreationPreservesAttributes(t *testing.T) {
 				ID:          uuid.New(),
 				Name:        name,
 				Description: description,
-				Price:       price,
+				Price:       domain.Cents(price),
 				CategoryID:  category.ID,
 				ImageURL:    imageURL,
-				Stock:       stock,
 				CreatedAt:   time.Now(),
 				UpdatedAt:   time.Now(),
 			}
@@ -112,9 +80,9 @@ func TestProperty_ProductCreationPreservesAttributes(t *testing.T) {
 				return false
 			}
 
-			// Compare prices with small tolerance for floating point
-			if retrieved.Price < product.Price-0.01 || retrieved.Price > product.Price+0.01 {
-				t.Logf("FAIL: Price mismatch. Expected %f, got %f", product.Price, retrieved.Price)
+			// Prices are exact, so they must round-trip without any tolerance
+			if retrieved.Price != product.Price {
+				t.Logf("FAIL: Price mismatch. Expected %s, got %s", product.Price, retrieved.Price)
 				return false
 			}
 
@@ -128,11 +96,6 @@ func TestProperty_ProductCreationPreservesAttributes(t *testing.T) {
 				return false
 			}
 
-			if retrieved.Stock != product.Stock {
-				t.Logf("FAIL: Stock mismatch. Expected %d, got %d", product.Stock, retrieved.Stock)
-				return false
-			}
-
 			// Verify timestamps are set
 			if retrieved.CreatedAt.IsZero() {
 				t.Logf("FAIL: CreatedAt is zero")
@@ -152,9 +115,8 @@ func TestProperty_ProductCreationPreservesAttributes(t *testing.T) {
 		},
 		gen.RegexMatch(`[A-Za-z0-9 ]{3,50}`),                      // name
 		gen.RegexMatch(`[A-Za-z0-9 .,!?]{10,200}`),                // description
-		gen.Float64Range(0.01, 9999.99),                           // price (positive values)
+		gen.Int64Range(1, 999999),                                 // price in cents (positive values)
 		gen.RegexMatch(`https?://[a-z0-9.-]+/[a-z0-9/._-]{1,50}`), // imageURL
-		gen.IntRange(0, 1000),                                     // stock (non-negative)
 	))
 
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
@@ -163,37 +125,6 @@ func TestProperty_ProductCreationPreservesAttributes(t *testing.T) {
 // Feature: ordering-platform, Property 14: Product updates are reflected
 // Validates: Requirements 5.1, 5.3
 func TestProperty_ProductUpdatesAreReflected(t *testing.T) {
-	// Ensure tables exist
-	_, err := testDB.Exec(`
-		CREATE TABLE IF NOT EXISTS categories (
-			id UUID PRIMARY KEY,
-			name VARCHAR(100) UNIQUE NOT NULL,
-			description TEXT,
-			created_at TIMESTAMP NOT NULL
-		)
-	`)
-	if err != nil {
-		t.Fatalf("Failed to create categories table: %v", err)
-	}
-
-	_, err = testDB.Exec(`
-		CREATE TABLE IF NOT EXISTS products (
-			id UUID PRIMARY KEY,
-			name VARCHAR(255) NOT NULL,
-			description TEXT,
-			price DECIMAL(10, 2) NOT NULL,
-			category_id UUID NOT NULL,
-			image_url VARCHAR(500),
-			stock INTEGER NOT NULL DEFAULT 0,
-			created_at TIMESTAMP NOT NULL,
-			updated_at TIMESTAMP NOT NULL,
-			CONSTRAINT fk_products_category FOREIGN KEY (category_id) REFERENCES categories(id)
-		)
-	`)
-	if err != nil {
-		t.Fatalf("Failed to create products table: %v", err)
-	}
-
 	productRepo := NewProductRepository(testDB)
 	categoryRepo := NewCategoryRepository(testDB)
 
@@ -201,7 +132,7 @@ func TestProperty_ProductUpdatesAreReflected(t *testing.T) {
 
 	properties.Property("updating a product and retrieving it shows the updated values", prop.ForAll(
 		func(name1 string, name2 string, description1 string, description2 string,
-			price1 float64, price2 float64, stock1 int, stock2 int) bool {
+			price1 int64, price2 int64) bool {
 			ctx := context.Background()
 
 			// Create a category first
@@ -222,10 +153,9 @@ func TestProperty_ProductUpdatesAreReflected(t *testing.T) {
 				ID:          uuid.New(),
 				Name:        name1,
 				Description: description1,
-				Price:       price1,
+				Price:       domain.Cents(price1),
 				CategoryID:  category.ID,
 				ImageURL:    "http://example.com/image1.jpg",
-				Stock:       stock1,
 				CreatedAt:   time.Now(),
 				UpdatedAt:   time.Now(),
 			}
@@ -239,8 +169,7 @@ func TestProperty_ProductUpdatesAreReflected(t *testing.T) {
 			// Update the product with new values
 			product.Name = name2
 			product.Description = description2
-			product.Price = price2
-			product.Stock = stock2
+			product.Price = domain.Cents(price2)
 			product.UpdatedAt = time.Now()
 
 			err = productRepo.Update(ctx, product)
@@ -267,14 +196,8 @@ func TestProperty_ProductUpdatesAreReflected(t *testing.T) {
 				return false
 			}
 
-			// Compare prices with small tolerance for floating point
-			if retrieved.Price < price2-0.01 || retrieved.Price > price2+0.01 {
-				t.Logf("FAIL: Price not updated. Expected %f, got %f", price2, retrieved.Price)
-				return false
-			}
-
-			if retrieved.Stock != stock2 {
-				t.Logf("FAIL: Stock not updated. Expected %d, got %d", stock2, retrieved.Stock)
+			if retrieved.Price != domain.Cents(price2) {
+				t.Logf("FAIL: Price not updated. Expected %s, got %s", domain.Cents(price2), retrieved.Price)
 				return false
 			}
 
@@ -288,10 +211,8 @@ func TestProperty_ProductUpdatesAreReflected(t *testing.T) {
 		gen.RegexMatch(`[A-Za-z0-9 ]{3,50}`),       // name2
 		gen.RegexMatch(`[A-Za-z0-9 .,!?]{10,200}`), // description1
 		gen.RegexMatch(`[A-Za-z0-9 .,!?]{10,200}`), // description2
-		gen.Float64Range(0.01, 9999.99),            // price1
-		gen.Float64Range(0.01, 9999.99),            // price2
-		gen.IntRange(0, 1000),                      // stock1
-		gen.IntRange(0, 1000),                      // stock2
+		gen.Int64Range(1, 999999),                  // price1 in cents
+		gen.Int64Range(1, 999999),                  // price2 in cents
 	))
 
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
@@ -300,44 +221,13 @@ func TestProperty_ProductUpdatesAreReflected(t *testing.T) {
 // Feature: ordering-platform, Property 16: Product deletion removes from catalog
 // Validates: Requirements 6.1
 func TestProperty_ProductDeletionRemovesFromCatalog(t *testing.T) {
-	// Ensure tables exist
-	_, err := testDB.Exec(`
-		CREATE TABLE IF NOT EXISTS categories (
-			id UUID PRIMARY KEY,
-			name VARCHAR(100) UNIQUE NOT NULL,
-			description TEXT,
-			created_at TIMESTAMP NOT NULL
-		)
-	`)
-	if err != nil {
-		t.Fatalf("Failed to create categories table: %v", err)
-	}
-
-	_, err = testDB.Exec(`
-		CREATE TABLE IF NOT EXISTS products (
-			id UUID PRIMARY KEY,
-			name VARCHAR(255) NOT NULL,
-			description TEXT,
-			price DECIMAL(10, 2) NOT NULL,
-			category_id UUID NOT NULL,
-			image_url VARCHAR(500),
-			stock INTEGER NOT NULL DEFAULT 0,
-			created_at TIMESTAMP NOT NULL,
-			updated_at TIMESTAMP NOT NULL,
-			CONSTRAINT fk_products_category FOREIGN KEY (category_id) REFERENCES categories(id)
-		)
-	`)
-	if err != nil {
-		t.Fatalf("Failed to create products table: %v", err)
-	}
-
 	productRepo := NewProductRepository(testDB)
 	categoryRepo := NewCategoryRepository(testDB)
 
 	properties := gopter.NewProperties(nil)
 
 	properties.Property("deleting a product makes it not retrievable", prop.ForAll(
-		func(name string, description string, price float64, stock int) bool {
+		func(name string, description string, price int64) bool {
 			ctx := context.Background()
 
 			// Create a category first
@@ -358,10 +248,9 @@ func TestProperty_ProductDeletionRemovesFromCatalog(t *testing.T) {
 				ID:          uuid.New(),
 				Name:        name,
 				Description: description,
-				Price:       price,
+				Price:       domain.Cents(price),
 				CategoryID:  category.ID,
 				ImageURL:    "http://example.com/image.jpg",
-				Stock:       stock,
 				CreatedAt:   time.Now(),
 				UpdatedAt:   time.Now(),
 			}
@@ -400,8 +289,7 @@ func TestProperty_ProductDeletionRemovesFromCatalog(t *testing.T) {
 		},
 		gen.RegexMatch(`[A-Za-z0-9 ]{3,50}`),       // name
 		gen.RegexMatch(`[A-Za-z0-9 .,!?]{10,200}`), // description
-		gen.Float64Range(0.01, 9999.99),            // price
-		gen.IntRange(0, 1000),                      // stock
+		gen.Int64Range(1, 999999),                  // price in cents
 	))
 
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
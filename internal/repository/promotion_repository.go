@@ -0,0 +1,348 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPromotionNotFound   = errors.New("promotion not found")
+	ErrPromotionCodeExists = errors.New("promotion with this code already exists")
+)
+
+// PromotionRepository defines the interface for promotion and redemption data access
+type PromotionRepository interface {
+	Create(ctx context.Context, promotion *domain.Promotion) error
+	Update(ctx context.Context, promotion *domain.Promotion) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.Promotion, error)
+	FindByCode(ctx context.Context, code string) (*domain.Promotion, error)
+	List(ctx context.Context) ([]*domain.Promotion, error)
+	CountUserRedemptions(ctx context.Context, promotionID, userID uuid.UUID) (int, error)
+	FindByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*domain.Promotion, error)
+	CountUserRedemptionsTx(ctx context.Context, tx *sql.Tx, promotionID, userID uuid.UUID) (int, error)
+	AddRedemptionTx(ctx context.Context, tx *sql.Tx, redemption *domain.PromotionRedemption) error
+}
+
+type promotionRepository struct {
+	db *sql.DB
+}
+
+// NewPromotionRepository creates a new instance of PromotionRepository
+func NewPromotionRepository(db *sql.DB) PromotionRepository {
+	return &promotionRepository{db: db}
+}
+
+// promotionColumns lists the promotion columns in the order scanPromotion reads them
+const promotionColumns = `
+	id, code, description, type, percent_off, amount_off, min_order_value, product_ids, category_ids,
+	starts_at, ends_at, max_redemptions, max_redemptions_per_user, redemption_count, active,
+	created_at, updated_at
+`
+
+// Create inserts a new promotion
+func (r *promotionRepository) Create(ctx context.Context, promotion *domain.Promotion) error {
+	query := `
+		INSERT INTO promotions (
+			id, code, description, type, percent_off, amount_off, min_order_value, product_ids, category_ids,
+			starts_at, ends_at, max_redemptions, max_redemptions_per_user, active, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	`
+
+	productIDs, categoryIDs, err := encodePromotionScope(promotion)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		promotion.ID,
+		promotion.Code,
+		promotion.Description,
+		promotion.Type,
+		promotion.PercentOff,
+		promotion.AmountOff,
+		promotion.MinOrderValue,
+		productIDs,
+		categoryIDs,
+		promotion.StartsAt,
+		promotion.EndsAt,
+		promotion.MaxRedemptions,
+		promotion.MaxRedemptionsPerUser,
+		promotion.Active,
+		promotion.CreatedAt,
+		promotion.UpdatedAt,
+	)
+
+	if err != nil {
+		if isConstraintViolation(err, pgUniqueViolation, "promotions_code_key") {
+			return ErrPromotionCodeExists
+		}
+		return fmt.Errorf("failed to create promotion: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces the writable attributes of a promotion; the redemption count is left untouched
+func (r *promotionRepository) Update(ctx context.Context, promotion *domain.Promotion) error {
+	query := `
+		UPDATE promotions
+		SET code = $2, description = $3, type = $4, percent_off = $5, amount_off = $6,
+		    min_order_value = $7, product_ids = $8, category_ids = $9, starts_at = $10, ends_at = $11,
+		    max_redemptions = $12, max_redemptions_per_user = $13, active = $14, updated_at = $15
+		WHERE id = $1
+	`
+
+	productIDs, categoryIDs, err := encodePromotionScope(promotion)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		promotion.ID,
+		promotion.Code,
+		promotion.Description,
+		promotion.Type,
+		promotion.PercentOff,
+		promotion.AmountOff,
+		promotion.MinOrderValue,
+		productIDs,
+		categoryIDs,
+		promotion.StartsAt,
+		promotion.EndsAt,
+		promotion.MaxRedemptions,
+		promotion.MaxRedemptionsPerUser,
+		promotion.Active,
+		promotion.UpdatedAt,
+	)
+	if err != nil {
+		if isConstraintViolation(err, pgUniqueViolation, "promotions_code_key") {
+			return ErrPromotionCodeExists
+		}
+		return fmt.Errorf("failed to update promotion: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrPromotionNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a promotion together with its redemption records and cart coupons.
+// Orders keep the coupon code and discount they were placed with.
+func (r *promotionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM promotions WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete promotion: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrPromotionNotFound
+	}
+
+	return nil
+}
+
+// FindByID retrieves a promotion by ID
+func (r *promotionRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Promotion, error) {
+	query := `SELECT ` + promotionColumns + ` FROM promotions WHERE id = $1`
+	return scanPromotion(r.db.QueryRowContext(ctx, query, id))
+}
+
+// FindByCode retrieves a promotion by its coupon code
+func (r *promotionRepository) FindByCode(ctx context.Context, code string) (*domain.Promotion, error) {
+	query := `SELECT ` + promotionColumns + ` FROM promotions WHERE code = $1`
+	return scanPromotion(r.db.QueryRowContext(ctx, query, code))
+}
+
+// FindByIDForUpdateTx retrieves a promotion inside tx, locking the row until the transaction
+// ends so concurrent checkouts redeem it one at a time
+func (r *promotionRepository) FindByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*domain.Promotion, error) {
+	query := `SELECT ` + promotionColumns + ` FROM promotions WHERE id = $1 FOR UPDATE`
+	return scanPromotion(tx.QueryRowContext(ctx, query, id))
+}
+
+// List retrieves every promotion, newest first
+func (r *promotionRepository) List(ctx context.Context) ([]*domain.Promotion, error) {
+	query := `SELECT ` + promotionColumns + ` FROM promotions ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list promotions: %w", err)
+	}
+	defer rows.Close()
+
+	promotions := []*domain.Promotion{}
+	for rows.Next() {
+		promotion, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, promotion)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating promotions: %w", err)
+	}
+
+	return promotions, nil
+}
+
+// CountUserRedemptions returns how many times a user has redeemed a promotion
+func (r *promotionRepository) CountUserRedemptions(ctx context.Context, promotionID, userID uuid.UUID) (int, error) {
+	return countUserRedemptions(ctx, r.db, promotionID, userID)
+}
+
+// CountUserRedemptionsTx returns how many times a user has redeemed a promotion inside tx
+func (r *promotionRepository) CountUserRedemptionsTx(ctx context.Context, tx *sql.Tx, promotionID, userID uuid.UUID) (int, error) {
+	return countUserRedemptions(ctx, tx, promotionID, userID)
+}
+
+// AddRedemptionTx records a redemption and increments the promotion's redemption count inside tx
+func (r *promotionRepository) AddRedemptionTx(ctx context.Context, tx *sql.Tx, redemption *domain.PromotionRedemption) error {
+	query := `
+		INSERT INTO promotion_redemptions (id, promotion_id, user_id, order_id, discount, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := tx.ExecContext(
+		ctx,
+		query,
+		redemption.ID,
+		redemption.PromotionID,
+		redemption.UserID,
+		redemption.OrderID,
+		redemption.Discount,
+		redemption.CreatedAt,
+	)
+	if err != nil {
+		if isConstraintViolation(err, pgForeignKeyViolation, "fk_promotion_redemptions_promotion") {
+			return ErrPromotionNotFound
+		}
+		return fmt.Errorf("failed to record promotion redemption: %w", err)
+	}
+
+	query = `UPDATE promotions SET redemption_count = redemption_count + 1 WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, query, redemption.PromotionID); err != nil {
+		return fmt.Errorf("failed to count promotion redemption: %w", err)
+	}
+
+	return nil
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPromotion reads a single promotion row selected with promotionColumns
+func scanPromotion(row rowScanner) (*domain.Promotion, error) {
+	promotion := &domain.Promotion{}
+	var (
+		productIDs  []byte
+		categoryIDs []byte
+		startsAt    sql.NullTime
+		endsAt      sql.NullTime
+	)
+	err := row.Scan(
+		&promotion.ID,
+		&promotion.Code,
+		&promotion.Description,
+		&promotion.Type,
+		&promotion.PercentOff,
+		&promotion.AmountOff,
+		&promotion.MinOrderValue,
+		&productIDs,
+		&categoryIDs,
+		&startsAt,
+		&endsAt,
+		&promotion.MaxRedemptions,
+		&promotion.MaxRedemptionsPerUser,
+		&promotion.RedemptionCount,
+		&promotion.Active,
+		&promotion.CreatedAt,
+		&promotion.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrPromotionNotFound
+		}
+		return nil, fmt.Errorf("failed to scan promotion: %w", err)
+	}
+
+	if err := json.Unmarshal(productIDs, &promotion.ProductIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode promotion products: %w", err)
+	}
+	if err := json.Unmarshal(categoryIDs, &promotion.CategoryIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode promotion categories: %w", err)
+	}
+	if startsAt.Valid {
+		promotion.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		promotion.EndsAt = &endsAt.Time
+	}
+
+	return promotion, nil
+}
+
+// countUserRedemptions counts a user's redemptions of a promotion using q
+func countUserRedemptions(ctx context.Context, q rowQuerier, promotionID, userID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM promotion_redemptions WHERE promotion_id = $1 AND user_id = $2`
+
+	var count int
+	if err := q.QueryRowContext(ctx, query, promotionID, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count promotion redemptions: %w", err)
+	}
+
+	return count, nil
+}
+
+// encodePromotionScope renders the product and category scope as the JSON arrays stored on promotions
+func encodePromotionScope(promotion *domain.Promotion) (string, string, error) {
+	productIDs := promotion.ProductIDs
+	if productIDs == nil {
+		productIDs = []uuid.UUID{}
+	}
+	categoryIDs := promotion.CategoryIDs
+	if categoryIDs == nil {
+		categoryIDs = []uuid.UUID{}
+	}
+
+	encodedProducts, err := json.Marshal(productIDs)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode promotion products: %w", err)
+	}
+	encodedCategories, err := json.Marshal(categoryIDs)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode promotion categories: %w", err)
+	}
+
+	return string(encodedProducts), string(encodedCategories), nil
+}
@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// newTestPromotion inserts an active 10% promotion with a unique code
+func newTestPromotion(t *testing.T) *domain.Promotion {
+	t.Helper()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	promotion := &domain.Promotion{
+		ID:                    uuid.New(),
+		Code:                  "SAVE" + uuid.NewString()[:8],
+		Description:           "Ten percent off",
+		Type:                  domain.PromotionPercentage,
+		PercentOff:            10,
+		AmountOff:             domain.Cents(0),
+		MinOrderValue:         domain.Cents(1500),
+		MaxRedemptions:        100,
+		MaxRedemptionsPerUser: 1,
+		Active:                true,
+		CreatedAt:             now,
+		UpdatedAt:             now,
+	}
+	if err := NewPromotionRepository(testDB).Create(context.Background(), promotion); err != nil {
+		t.Fatalf("Failed to create promotion: %v", err)
+	}
+	return promotion
+}
+
+func TestPromotionRepository_CRUD(t *testing.T) {
+	ctx := context.Background()
+	promotionRepo := NewPromotionRepository(testDB)
+
+	promotion := newTestPromotion(t)
+
+	found, err := promotionRepo.FindByCode(ctx, promotion.Code)
+	if err != nil {
+		t.Fatalf("FindByCode failed: %v", err)
+	}
+	if found.ID != promotion.ID || found.PercentOff != 10 || found.MinOrderValue.Amount != 1500 {
+		t.Errorf("Unexpected promotion: %+v", found)
+	}
+	// Unscoped promotions and open windows read back as empty, not NULL
+	if found.ProductIDs == nil || len(found.ProductIDs) != 0 || found.CategoryIDs == nil || len(found.CategoryIDs) != 0 {
+		t.Errorf("Expected empty product and category scopes, got %v and %v", found.ProductIDs, found.CategoryIDs)
+	}
+	if found.StartsAt != nil || found.EndsAt != nil {
+		t.Errorf("Expected an open window, got %v to %v", found.StartsAt, found.EndsAt)
+	}
+
+	duplicate := *promotion
+	duplicate.ID = uuid.New()
+	if err := promotionRepo.Create(ctx, &duplicate); err != ErrPromotionCodeExists {
+		t.Errorf("Expected ErrPromotionCodeExists, got %v", err)
+	}
+
+	productID := uuid.New()
+	startsAt := time.Now().UTC().Truncate(time.Microsecond)
+	endsAt := startsAt.Add(24 * time.Hour)
+	promotion.ProductIDs = []uuid.UUID{productID}
+	promotion.StartsAt = &startsAt
+	promotion.EndsAt = &endsAt
+	promotion.Active = false
+	promotion.UpdatedAt = time.Now().UTC()
+	if err := promotionRepo.Update(ctx, promotion); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	found, err = promotionRepo.FindByID(ctx, promotion.ID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if found.Active || len(found.ProductIDs) != 1 || found.ProductIDs[0] != productID {
+		t.Errorf("Expected the update to be persisted, got %+v", found)
+	}
+	if found.StartsAt == nil || !found.StartsAt.Equal(startsAt) || found.EndsAt == nil || !found.EndsAt.Equal(endsAt) {
+		t.Errorf("Expected window %v to %v, got %v to %v", startsAt, endsAt, found.StartsAt, found.EndsAt)
+	}
+
+	promotions, err := promotionRepo.List(ctx)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	listed := false
+	for _, p := range promotions {
+		listed = listed || p.ID == promotion.ID
+	}
+	if !listed {
+		t.Error("Expected the promotion to be listed")
+	}
+
+	if err := promotionRepo.Delete(ctx, promotion.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := promotionRepo.FindByID(ctx, promotion.ID); err != ErrPromotionNotFound {
+		t.Errorf("Expected ErrPromotionNotFound after delete, got %v", err)
+	}
+	if err := promotionRepo.Delete(ctx, promotion.ID); err != ErrPromotionNotFound {
+		t.Errorf("Expected ErrPromotionNotFound deleting twice, got %v", err)
+	}
+	if err := promotionRepo.Update(ctx, promotion); err != ErrPromotionNotFound {
+		t.Errorf("Expected ErrPromotionNotFound updating a deleted promotion, got %v", err)
+	}
+}
+
+func TestPromotionRepository_AddRedemptionTxCountsRedemptions(t *testing.T) {
+	ctx := context.Background()
+	promotionRepo := NewPromotionRepository(testDB)
+
+	user := newTestUser(t)
+	store := newTestStore(t)
+	product := newTestProduct(t, 2000)
+	promotion := newTestPromotion(t)
+
+	order := newTestOrder(user.ID, store.ID, product.ID)
+	order.CouponCode = promotion.Code
+	order.Discount = domain.Cents(240)
+	createTestOrder(t, order)
+
+	tx := beginTestTx(t)
+	locked, err := promotionRepo.FindByIDForUpdateTx(ctx, tx, promotion.ID)
+	if err != nil {
+		t.Fatalf("FindByIDForUpdateTx failed: %v", err)
+	}
+	if locked.RedemptionCount != 0 {
+		t.Errorf("Expected no redemptions yet, got %d", locked.RedemptionCount)
+	}
+
+	// A concurrent checkout redeeming the same promotion waits for this one
+	other := beginTestTx(t)
+	_, err = other.ExecContext(ctx, `SELECT 1 FROM promotions WHERE id = $1 FOR UPDATE NOWAIT`, promotion.ID)
+	if !isLockNotAvailable(err) {
+		t.Errorf("Expected the promotion row to be locked, got %v", err)
+	}
+	_ = other.Rollback()
+
+	redemption := &domain.PromotionRedemption{
+		ID:          uuid.New(),
+		PromotionID: promotion.ID,
+		UserID:      user.ID,
+		OrderID:     order.ID,
+		Discount:    domain.Cents(240),
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := promotionRepo.AddRedemptionTx(ctx, tx, redemption); err != nil {
+		t.Fatalf("AddRedemptionTx failed: %v", err)
+	}
+	count, err := promotionRepo.CountUserRedemptionsTx(ctx, tx, promotion.ID, user.ID)
+	if err != nil {
+		t.Fatalf("CountUserRedemptionsTx failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 redemption inside the transaction, got %d", count)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	found, err := promotionRepo.FindByID(ctx, promotion.ID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if found.RedemptionCount != 1 {
+		t.Errorf("Expected redemption count 1, got %d", found.RedemptionCount)
+	}
+	if count, err := promotionRepo.CountUserRedemptions(ctx, promotion.ID, user.ID); err != nil || count != 1 {
+		t.Errorf("Expected 1 redemption for the user, got %d (%v)", count, err)
+	}
+	if count, err := promotionRepo.CountUserRedemptions(ctx, promotion.ID, uuid.New()); err != nil || count != 0 {
+		t.Errorf("Expected no redemptions for another user, got %d (%v)", count, err)
+	}
+
+	// Orders keep their coupon code
+	placed, err := NewOrderRepository(testDB).FindByID(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if placed.CouponCode != promotion.Code {
+		t.Errorf("Expected coupon code %s on the order, got %q", promotion.Code, placed.CouponCode)
+	}
+
+	tx = beginTestTx(t)
+	redemption.ID = uuid.New()
+	redemption.PromotionID = uuid.New()
+	if err := promotionRepo.AddRedemptionTx(ctx, tx, redemption); err != ErrPromotionNotFound {
+		t.Errorf("Expected ErrPromotionNotFound for an unknown promotion, got %v", err)
+	}
+}
+
+func TestCartRepository_CouponLifecycle(t *testing.T) {
+	ctx := context.Background()
+	cartRepo := NewCartRepository(testDB)
+
+	user := newTestUser(t)
+	promotion := newTestPromotion(t)
+
+	if _, err := cartRepo.FindCoupon(ctx, user.ID); err != ErrCartCouponNotFound {
+		t.Errorf("Expected ErrCartCouponNotFound on an empty cart, got %v", err)
+	}
+	if err := cartRepo.SetCoupon(ctx, user.ID, uuid.New()); err != ErrPromotionNotFound {
+		t.Errorf("Expected ErrPromotionNotFound for an unknown promotion, got %v", err)
+	}
+
+	if err := cartRepo.SetCoupon(ctx, user.ID, promotion.ID); err != nil {
+		t.Fatalf("SetCoupon failed: %v", err)
+	}
+	promotionID, err := cartRepo.FindCoupon(ctx, user.ID)
+	if err != nil || promotionID != promotion.ID {
+		t.Errorf("Expected coupon %s, got %s (%v)", promotion.ID, promotionID, err)
+	}
+
+	// Clearing the cart at checkout drops the coupon too
+	tx := beginTestTx(t)
+	if err := cartRepo.ClearTx(ctx, tx, user.ID); err != nil {
+		t.Fatalf("ClearTx failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	if _, err := cartRepo.FindCoupon(ctx, user.ID); err != ErrCartCouponNotFound {
+		t.Errorf("Expected ErrCartCouponNotFound after clearing, got %v", err)
+	}
+
+	if err := cartRepo.RemoveCoupon(ctx, user.ID); err != ErrCartCouponNotFound {
+		t.Errorf("Expected ErrCartCouponNotFound removing a missing coupon, got %v", err)
+	}
+}
@@ -5,20 +5,30 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
 )
 
 var (
 	ErrRefreshTokenNotFound = errors.New("refresh token not found")
 	ErrRefreshTokenRevoked  = errors.New("refresh token has been revoked")
+	ErrSessionNotFound      = errors.New("session not found")
 )
 
 // RefreshTokenRepository defines the interface for refresh token data access
 type RefreshTokenRepository interface {
 	Create(ctx context.Context, token *domain.RefreshToken) error
-	FindByToken(ctx context.Context, token string) (*domain.RefreshToken, error)
-	Revoke(ctx context.Context, token string) error
+	FindByTokenHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	FindByTokenHashIncludingRevoked(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	Revoke(ctx context.Context, tokenHash string) error
+	Rotate(ctx context.Context, current *domain.RefreshToken, replacement *domain.RefreshToken) error
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
+	ListActiveSessions(ctx context.Context, userID uuid.UUID) ([]*domain.Session, error)
+	RevokeSession(ctx context.Context, userID, familyID uuid.UUID) error
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID) error
 }
 
 type refreshTokenRepository struct {
@@ -33,8 +43,11 @@ func NewRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
 // Create inserts a new refresh token into the database using parameterized queries
 func (r *refreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
 	query := `
-		INSERT INTO refresh_tokens (id, user_id, token, expires_at, created_at, revoked)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO refresh_tokens (
+			id, user_id, family_id, token_hash, expires_at, created_at, revoked,
+			user_agent, ip_address, last_used_at, session_started_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 
 	_, err := r.db.ExecContext(
@@ -42,10 +55,15 @@ func (r *refreshTokenRepository) Create(ctx context.Context, token *domain.Refre
 		query,
 		token.ID,
 		token.UserID,
-		token.Token,
+		token.FamilyID,
+		token.TokenHash,
 		token.ExpiresAt,
 		token.CreatedAt,
 		token.Revoked,
+		token.UserAgent,
+		token.IPAddress,
+		token.LastUsedAt,
+		token.SessionStartedAt,
 	)
 
 	if err != nil {
@@ -55,22 +73,45 @@ func (r *refreshTokenRepository) Create(ctx context.Context, token *domain.Refre
 	return nil
 }
 
-// FindByToken retrieves a refresh token by its token string using parameterized queries
-func (r *refreshTokenRepository) FindByToken(ctx context.Context, token string) (*domain.RefreshToken, error) {
+// FindByTokenHash retrieves a refresh token by the digest of its token string using parameterized queries
+func (r *refreshTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	refreshToken, err := r.FindByTokenHashIncludingRevoked(ctx, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if refreshToken.Revoked {
+		return nil, ErrRefreshTokenRevoked
+	}
+
+	return refreshToken, nil
+}
+
+// FindByTokenHashIncludingRevoked retrieves a refresh token by the digest of its token string
+// even when it has been revoked, so that reuse of a rotated token can be traced back to its family
+func (r *refreshTokenRepository) FindByTokenHashIncludingRevoked(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
 	query := `
-		SELECT id, user_id, token, expires_at, created_at, revoked
+		SELECT id, user_id, family_id, token_hash, expires_at, created_at, revoked, replaced_by,
+		       user_agent, ip_address, last_used_at, session_started_at
 		FROM refresh_tokens
-		WHERE token = $1
+		WHERE token_hash = $1
 	`
 
 	refreshToken := &domain.RefreshToken{}
-	err := r.db.QueryRowContext(ctx, query, token).Scan(
+	var replacedBy uuid.NullUUID
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
 		&refreshToken.ID,
 		&refreshToken.UserID,
-		&refreshToken.Token,
+		&refreshToken.FamilyID,
+		&refreshToken.TokenHash,
 		&refreshToken.ExpiresAt,
 		&refreshToken.CreatedAt,
 		&refreshToken.Revoked,
+		&replacedBy,
+		&refreshToken.UserAgent,
+		&refreshToken.IPAddress,
+		&refreshToken.LastUsedAt,
+		&refreshToken.SessionStartedAt,
 	)
 
 	if err != nil {
@@ -80,22 +121,22 @@ func (r *refreshTokenRepository) FindByToken(ctx context.Context, token string)
 		return nil, fmt.Errorf("failed to find refresh token: %w", err)
 	}
 
-	if refreshToken.Revoked {
-		return nil, ErrRefreshTokenRevoked
+	if replacedBy.Valid {
+		refreshToken.ReplacedBy = &replacedBy.UUID
 	}
 
 	return refreshToken, nil
 }
 
-// Revoke marks a refresh token as revoked using parameterized queries
-func (r *refreshTokenRepository) Revoke(ctx context.Context, token string) error {
+// Revoke marks the refresh token with the given digest as revoked using parameterized queries
+func (r *refreshTokenRepository) Revoke(ctx context.Context, tokenHash string) error {
 	query := `
 		UPDATE refresh_tokens
 		SET revoked = TRUE
-		WHERE token = $1
+		WHERE token_hash = $1
 	`
 
-	result, err := r.db.ExecContext(ctx, query, token)
+	result, err := r.db.ExecContext(ctx, query, tokenHash)
 	if err != nil {
 		return fmt.Errorf("failed to revoke refresh token: %w", err)
 	}
@@ -111,3 +152,150 @@ func (r *refreshTokenRepository) Revoke(ctx context.Context, token string) error
 
 	return nil
 }
+
+// Rotate revokes current, records replacement as its successor and inserts replacement in a
+// single statement. It returns ErrRefreshTokenRevoked if current was revoked in the meantime,
+// in which case replacement is not stored.
+func (r *refreshTokenRepository) Rotate(ctx context.Context, current *domain.RefreshToken, replacement *domain.RefreshToken) error {
+	query := `
+		WITH rotated AS (
+			UPDATE refresh_tokens
+			SET revoked = TRUE, replaced_by = $1
+			WHERE id = $2 AND revoked = FALSE
+			RETURNING id
+		)
+		INSERT INTO refresh_tokens (
+			id, user_id, family_id, token_hash, expires_at, created_at, revoked,
+			user_agent, ip_address, last_used_at, session_started_at
+		)
+		SELECT $1, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+		FROM rotated
+	`
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		replacement.ID,
+		current.ID,
+		replacement.UserID,
+		replacement.FamilyID,
+		replacement.TokenHash,
+		replacement.ExpiresAt,
+		replacement.CreatedAt,
+		replacement.Revoked,
+		replacement.UserAgent,
+		replacement.IPAddress,
+		replacement.LastUsedAt,
+		replacement.SessionStartedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrRefreshTokenRevoked
+	}
+
+	return nil
+}
+
+// RevokeFamily marks every refresh token of a family as revoked
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked = TRUE
+		WHERE family_id = $1 AND revoked = FALSE
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, familyID); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	return nil
+}
+
+// ListActiveSessions retrieves the unexpired, unrevoked refresh tokens of a user as sessions,
+// most recently used first
+func (r *refreshTokenRepository) ListActiveSessions(ctx context.Context, userID uuid.UUID) ([]*domain.Session, error) {
+	query := `
+		SELECT family_id, user_agent, ip_address, session_started_at, last_used_at, expires_at
+		FROM refresh_tokens
+		WHERE user_id = $1 AND revoked = FALSE AND expires_at > $2
+		ORDER BY last_used_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []*domain.Session{}
+	for rows.Next() {
+		session := &domain.Session{}
+		err := rows.Scan(
+			&session.ID,
+			&session.UserAgent,
+			&session.IPAddress,
+			&session.CreatedAt,
+			&session.LastUsedAt,
+			&session.ExpiresAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession revokes the tokens of one of a user's token families.
+// It returns ErrSessionNotFound if the user has no active token in that family.
+func (r *refreshTokenRepository) RevokeSession(ctx context.Context, userID, familyID uuid.UUID) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked = TRUE
+		WHERE user_id = $1 AND family_id = $2 AND revoked = FALSE
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RevokeAllForUser marks every refresh token of a user as revoked
+func (r *refreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked = TRUE
+		WHERE user_id = $1 AND revoked = FALSE
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to revoke user sessions: %w", err)
+	}
+
+	return nil
+}
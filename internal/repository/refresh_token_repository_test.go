@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// newTestRefreshToken builds an unrevoked token of a family that expires in a day
+func newTestRefreshToken(userID, familyID uuid.UUID) *domain.RefreshToken {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	return &domain.RefreshToken{
+		ID:               uuid.New(),
+		UserID:           userID,
+		FamilyID:         familyID,
+		TokenHash:        uuid.NewString(),
+		ExpiresAt:        now.Add(24 * time.Hour),
+		CreatedAt:        now,
+		UserAgent:        "test-agent",
+		IPAddress:        "192.0.2.1",
+		LastUsedAt:       now,
+		SessionStartedAt: now,
+	}
+}
+
+func TestRefreshTokenRepository_RotateRecordsSuccessor(t *testing.T) {
+	ctx := context.Background()
+	tokenRepo := NewRefreshTokenRepository(testDB)
+
+	user := newTestUser(t)
+	current := newTestRefreshToken(user.ID, uuid.New())
+	if err := tokenRepo.Create(ctx, current); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	replacement := newTestRefreshToken(user.ID, current.FamilyID)
+	replacement.SessionStartedAt = current.SessionStartedAt
+	if err := tokenRepo.Rotate(ctx, current, replacement); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+
+	// The rotated token is only found when revoked tokens are included, pointing at its successor
+	if _, err := tokenRepo.FindByTokenHash(ctx, current.TokenHash); err != ErrRefreshTokenRevoked {
+		t.Errorf("Expected ErrRefreshTokenRevoked for the rotated token, got %v", err)
+	}
+	rotated, err := tokenRepo.FindByTokenHashIncludingRevoked(ctx, current.TokenHash)
+	if err != nil {
+		t.Fatalf("FindByTokenHashIncludingRevoked failed: %v", err)
+	}
+	if !rotated.Revoked || rotated.ReplacedBy == nil || *rotated.ReplacedBy != replacement.ID {
+		t.Errorf("Expected the rotated token to be revoked and replaced by %s, got %+v", replacement.ID, rotated)
+	}
+
+	found, err := tokenRepo.FindByTokenHash(ctx, replacement.TokenHash)
+	if err != nil {
+		t.Fatalf("FindByTokenHash failed: %v", err)
+	}
+	if found.FamilyID != current.FamilyID || found.ReplacedBy != nil || !found.SessionStartedAt.Equal(current.SessionStartedAt) {
+		t.Errorf("Unexpected replacement: %+v", found)
+	}
+
+	// Rotating an already rotated token stores nothing
+	second := newTestRefreshToken(user.ID, current.FamilyID)
+	if err := tokenRepo.Rotate(ctx, current, second); err != ErrRefreshTokenRevoked {
+		t.Errorf("Expected ErrRefreshTokenRevoked rotating twice, got %v", err)
+	}
+	if _, err := tokenRepo.FindByTokenHashIncludingRevoked(ctx, second.TokenHash); err != ErrRefreshTokenNotFound {
+		t.Errorf("Expected the second replacement not to be stored, got %v", err)
+	}
+}
+
+func TestRefreshTokenRepository_Sessions(t *testing.T) {
+	ctx := context.Background()
+	tokenRepo := NewRefreshTokenRepository(testDB)
+
+	user := newTestUser(t)
+	first := newTestRefreshToken(user.ID, uuid.New())
+	second := newTestRefreshToken(user.ID, uuid.New())
+	second.LastUsedAt = first.LastUsedAt.Add(time.Minute)
+	expired := newTestRefreshToken(user.ID, uuid.New())
+	expired.ExpiresAt = time.Now().UTC().Add(-time.Hour)
+	for _, token := range []*domain.RefreshToken{first, second, expired} {
+		if err := tokenRepo.Create(ctx, token); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	sessions, err := tokenRepo.ListActiveSessions(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ListActiveSessions failed: %v", err)
+	}
+	// Expired tokens are not sessions; the most recently used comes first
+	if len(sessions) != 2 || sessions[0].ID != second.FamilyID || sessions[1].ID != first.FamilyID {
+		t.Fatalf("Expected the two live sessions, most recent first, got %+v", sessions)
+	}
+	if sessions[0].UserAgent != "test-agent" || sessions[0].IPAddress != "192.0.2.1" {
+		t.Errorf("Unexpected session details: %+v", sessions[0])
+	}
+
+	if err := tokenRepo.RevokeSession(ctx, uuid.New(), first.FamilyID); err != ErrSessionNotFound {
+		t.Errorf("Expected ErrSessionNotFound for another user's session, got %v", err)
+	}
+	if err := tokenRepo.RevokeSession(ctx, user.ID, first.FamilyID); err != nil {
+		t.Fatalf("RevokeSession failed: %v", err)
+	}
+	if err := tokenRepo.RevokeSession(ctx, user.ID, first.FamilyID); err != ErrSessionNotFound {
+		t.Errorf("Expected ErrSessionNotFound revoking twice, got %v", err)
+	}
+
+	sessions, err = tokenRepo.ListActiveSessions(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ListActiveSessions failed: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != second.FamilyID {
+		t.Errorf("Expected only the second session left, got %+v", sessions)
+	}
+
+	if err := tokenRepo.RevokeAllForUser(ctx, user.ID); err != nil {
+		t.Fatalf("RevokeAllForUser failed: %v", err)
+	}
+	if count := countRows(t, "refresh_tokens", "user_id = $1 AND revoked = FALSE", user.ID); count != 0 {
+		t.Errorf("Expected every token to be revoked, got %d live", count)
+	}
+}
+
+func TestRefreshTokenRepository_RevokeFamily(t *testing.T) {
+	ctx := context.Background()
+	tokenRepo := NewRefreshTokenRepository(testDB)
+
+	user := newTestUser(t)
+	familyID := uuid.New()
+	tokens := []*domain.RefreshToken{
+		newTestRefreshToken(user.ID, familyID),
+		newTestRefreshToken(user.ID, familyID),
+		newTestRefreshToken(user.ID, uuid.New()),
+	}
+	for _, token := range tokens {
+		if err := tokenRepo.Create(ctx, token); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	if err := tokenRepo.RevokeFamily(ctx, familyID); err != nil {
+		t.Fatalf("RevokeFamily failed: %v", err)
+	}
+
+	if count := countRows(t, "refresh_tokens", "family_id = $1 AND revoked = FALSE", familyID); count != 0 {
+		t.Errorf("Expected the family to be revoked, got %d live tokens", count)
+	}
+	if _, err := tokenRepo.FindByTokenHash(ctx, tokens[2].TokenHash); err != nil {
+		t.Errorf("Expected other families to stay live, got %v", err)
+	}
+
+	if err := tokenRepo.Revoke(ctx, uuid.NewString()); err != ErrRefreshTokenNotFound {
+		t.Errorf("Expected ErrRefreshTokenNotFound, got %v", err)
+	}
+}
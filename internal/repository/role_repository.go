@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRoleNotFound           = errors.New("role not found")
+	ErrRoleAssignmentNotFound = errors.New("user does not have this role")
+)
+
+// RoleRepository defines the interface for role and role assignment data access
+type RoleRepository interface {
+	List(ctx context.Context) ([]*domain.Role, error)
+	FindByName(ctx context.Context, name string) (*domain.Role, error)
+	ListNamesForUser(ctx context.Context, userID uuid.UUID) ([]string, error)
+	ListAssignmentsForUser(ctx context.Context, userID uuid.UUID) ([]*domain.RoleAssignment, error)
+	ListPermissionsForUser(ctx context.Context, userID uuid.UUID) ([]*domain.PermissionGrant, error)
+	ListHoldersForUpdateTx(ctx context.Context, tx *sql.Tx, roleID uuid.UUID) ([]uuid.UUID, error)
+	Assign(ctx context.Context, userID, roleID uuid.UUID, storeID *uuid.UUID, at time.Time) error
+	RevokeTx(ctx context.Context, tx *sql.Tx, userID, roleID uuid.UUID, storeID *uuid.UUID) error
+}
+
+type roleRepository struct {
+	db *sql.DB
+}
+
+// NewRoleRepository creates a new instance of RoleRepository
+func NewRoleRepository(db *sql.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+// List retrieves every role with its permissions, ordered by name
+func (r *roleRepository) List(ctx context.Context) ([]*domain.Role, error) {
+	roles, err := r.queryRoles(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	return roles, nil
+}
+
+// FindByName retrieves a role with its permissions
+func (r *roleRepository) FindByName(ctx context.Context, name string) (*domain.Role, error) {
+	roles, err := r.queryRoles(ctx, "WHERE r.name = $1", name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find role: %w", err)
+	}
+	if len(roles) == 0 {
+		return nil, ErrRoleNotFound
+	}
+	return roles[0], nil
+}
+
+// queryRoles retrieves the roles matching where together with their permissions
+func (r *roleRepository) queryRoles(ctx context.Context, where string, args ...interface{}) ([]*domain.Role, error) {
+	query := `
+		SELECT r.id, r.name, r.description, r.created_at, rp.permission
+		FROM roles r
+		LEFT JOIN role_permissions rp ON rp.role_id = r.id
+		` + where + `
+		ORDER BY r.name ASC, rp.permission ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []*domain.Role{}
+	var current *domain.Role
+	for rows.Next() {
+		role := &domain.Role{Permissions: []string{}}
+		var permission sql.NullString
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.CreatedAt, &permission); err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+
+		// Rows of the same role are adjacent; each carries one of its permissions
+		if current == nil || current.ID != role.ID {
+			current = role
+			roles = append(roles, current)
+		}
+		if permission.Valid {
+			current.Permissions = append(current.Permissions, permission.String)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+// ListNamesForUser retrieves the names of the roles granted to a user at any store, ordered by name
+func (r *roleRepository) ListNamesForUser(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	query := `
+		SELECT DISTINCT r.name
+		FROM user_roles ur
+		JOIN roles r ON r.id = ur.role_id
+		WHERE ur.user_id = $1
+		ORDER BY r.name ASC
+	`
+
+	names, err := r.queryStrings(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user roles: %w", err)
+	}
+	return names, nil
+}
+
+// ListAssignmentsForUser retrieves the roles granted to a user together with the store each
+// is granted for, global grants first
+func (r *roleRepository) ListAssignmentsForUser(ctx context.Context, userID uuid.UUID) ([]*domain.RoleAssignment, error) {
+	query := `
+		SELECT r.name, ur.store_id
+		FROM user_roles ur
+		JOIN roles r ON r.id = ur.role_id
+		WHERE ur.user_id = $1
+		ORDER BY ur.store_id ASC NULLS FIRST, r.name ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user role assignments: %w", err)
+	}
+	defer rows.Close()
+
+	assignments := []*domain.RoleAssignment{}
+	for rows.Next() {
+		assignment := &domain.RoleAssignment{}
+		if err := rows.Scan(&assignment.Role, &assignment.StoreID); err != nil {
+			return nil, fmt.Errorf("failed to scan role assignment: %w", err)
+		}
+		assignments = append(assignments, assignment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating role assignments: %w", err)
+	}
+
+	return assignments, nil
+}
+
+// ListPermissionsForUser retrieves every permission a user holds through any of their roles,
+// once for every store it is granted at; a nil store means every store
+func (r *roleRepository) ListPermissionsForUser(ctx context.Context, userID uuid.UUID) ([]*domain.PermissionGrant, error) {
+	query := `
+		SELECT DISTINCT rp.permission, ur.store_id
+		FROM user_roles ur
+		JOIN role_permissions rp ON rp.role_id = ur.role_id
+		WHERE ur.user_id = $1
+		ORDER BY ur.store_id ASC NULLS FIRST, rp.permission ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user permissions: %w", err)
+	}
+	defer rows.Close()
+
+	grants := []*domain.PermissionGrant{}
+	for rows.Next() {
+		grant := &domain.PermissionGrant{}
+		if err := rows.Scan(&grant.Permission, &grant.StoreID); err != nil {
+			return nil, fmt.Errorf("failed to scan permission: %w", err)
+		}
+		grants = append(grants, grant)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating permissions: %w", err)
+	}
+
+	return grants, nil
+}
+
+// queryStrings runs a query selecting a single text column
+func (r *roleRepository) queryStrings(ctx context.Context, query string, args ...interface{}) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []string{}
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// ListHoldersForUpdateTx retrieves the users holding a role for every store inside tx,
+// locking their grants until tx ends so concurrent revocations see each other
+func (r *roleRepository) ListHoldersForUpdateTx(ctx context.Context, tx *sql.Tx, roleID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT user_id
+		FROM user_roles
+		WHERE role_id = $1 AND store_id IS NULL
+		ORDER BY user_id
+		FOR UPDATE
+	`
+
+	rows, err := tx.QueryContext(ctx, query, roleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock role holders: %w", err)
+	}
+	defer rows.Close()
+
+	holders := []uuid.UUID{}
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan role holder: %w", err)
+		}
+		holders = append(holders, userID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating role holders: %w", err)
+	}
+
+	return holders, nil
+}
+
+// Assign grants a role to a user at a store, or at every store when storeID is nil;
+// granting a role the user already holds there does nothing
+func (r *roleRepository) Assign(ctx context.Context, userID, roleID uuid.UUID, storeID *uuid.UUID, at time.Time) error {
+	query := `
+		INSERT INTO user_roles (user_id, role_id, store_id, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT ON CONSTRAINT user_roles_assignment_key DO NOTHING
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, roleID, storeID, at); err != nil {
+		if isConstraintViolation(err, pgForeignKeyViolation, "fk_user_roles_user") {
+			return ErrUserNotFound
+		}
+		if isConstraintViolation(err, pgForeignKeyViolation, "fk_user_roles_role") {
+			return ErrRoleNotFound
+		}
+		if isConstraintViolation(err, pgForeignKeyViolation, "fk_user_roles_store") {
+			return ErrStoreNotFound
+		}
+		return fmt.Errorf("failed to assign role: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeTx takes away a role granted to a user at a store, or at every store when storeID is
+// nil, inside tx
+func (r *roleRepository) RevokeTx(ctx context.Context, tx *sql.Tx, userID, roleID uuid.UUID, storeID *uuid.UUID) error {
+	query := `DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2 AND store_id IS NOT DISTINCT FROM $3`
+
+	result, err := tx.ExecContext(ctx, query, userID, roleID, storeID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke role: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrRoleAssignmentNotFound
+	}
+
+	return nil
+}
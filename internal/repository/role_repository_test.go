@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// findTestRole retrieves one of the roles the migrations seed
+func findTestRole(t *testing.T, name string) *domain.Role {
+	t.Helper()
+	role, err := NewRoleRepository(testDB).FindByName(context.Background(), name)
+	if err != nil {
+		t.Fatalf("Failed to find role %s: %v", name, err)
+	}
+	return role
+}
+
+func TestRoleRepository_SeededRoles(t *testing.T) {
+	ctx := context.Background()
+	roleRepo := NewRoleRepository(testDB)
+
+	roles, err := roleRepo.List(ctx)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	names := map[string]bool{}
+	for _, role := range roles {
+		names[role.Name] = true
+	}
+	for _, name := range []string{domain.RoleAdmin, domain.RoleStoreManager, domain.RoleKitchen, domain.RoleDriver, domain.RoleSupport} {
+		if !names[name] {
+			t.Errorf("Expected role %s to be seeded", name)
+		}
+	}
+
+	admin := findTestRole(t, domain.RoleAdmin)
+	granted := map[string]bool{}
+	for _, permission := range admin.Permissions {
+		granted[permission] = true
+	}
+	if !granted[domain.PermissionRolesManage] || !granted[domain.PermissionOrdersRead] {
+		t.Errorf("Expected admin to hold every permission, got %v", admin.Permissions)
+	}
+
+	if _, err := roleRepo.FindByName(ctx, "chef-de-partie"); err != ErrRoleNotFound {
+		t.Errorf("Expected ErrRoleNotFound, got %v", err)
+	}
+}
+
+func TestRoleRepository_AssignAndRevoke(t *testing.T) {
+	ctx := context.Background()
+	roleRepo := NewRoleRepository(testDB)
+
+	user := newTestUser(t)
+	store := newTestStore(t)
+	support := findTestRole(t, domain.RoleSupport)
+	kitchen := findTestRole(t, domain.RoleKitchen)
+	now := time.Now().UTC()
+
+	if err := roleRepo.Assign(ctx, user.ID, support.ID, nil, now); err != nil {
+		t.Fatalf("Assign failed: %v", err)
+	}
+	if err := roleRepo.Assign(ctx, user.ID, kitchen.ID, &store.ID, now); err != nil {
+		t.Fatalf("Assign failed: %v", err)
+	}
+	// Granting a role twice is not an error
+	if err := roleRepo.Assign(ctx, user.ID, kitchen.ID, &store.ID, now); err != nil {
+		t.Errorf("Expected assigning twice to do nothing, got %v", err)
+	}
+
+	assignments, err := roleRepo.ListAssignmentsForUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ListAssignmentsForUser failed: %v", err)
+	}
+	// Global grants come first
+	if len(assignments) != 2 || assignments[0].Role != domain.RoleSupport || assignments[0].StoreID != nil ||
+		assignments[1].Role != domain.RoleKitchen || assignments[1].StoreID == nil || *assignments[1].StoreID != store.ID {
+		t.Errorf("Unexpected assignments: %+v", assignments)
+	}
+
+	names, err := roleRepo.ListNamesForUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ListNamesForUser failed: %v", err)
+	}
+	if len(names) != 2 || names[0] != domain.RoleKitchen || names[1] != domain.RoleSupport {
+		t.Errorf("Expected role names [kitchen support], got %v", names)
+	}
+
+	grants, err := roleRepo.ListPermissionsForUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ListPermissionsForUser failed: %v", err)
+	}
+	storeScoped := false
+	for _, grant := range grants {
+		if grant.Permission == domain.PermissionKitchenOperate {
+			storeScoped = grant.StoreID != nil && *grant.StoreID == store.ID
+		}
+		if grant.Permission == domain.PermissionOrdersCancel {
+			t.Errorf("Expected neither kitchen nor support to cancel orders, got %+v", grant)
+		}
+	}
+	if !storeScoped {
+		t.Errorf("Expected kitchen:operate to be granted at the store only, got %+v", grants)
+	}
+
+	tx := beginTestTx(t)
+	// Revoking at the wrong scope finds nothing
+	if err := roleRepo.RevokeTx(ctx, tx, user.ID, kitchen.ID, nil); err != ErrRoleAssignmentNotFound {
+		t.Errorf("Expected ErrRoleAssignmentNotFound, got %v", err)
+	}
+	if err := roleRepo.RevokeTx(ctx, tx, user.ID, kitchen.ID, &store.ID); err != nil {
+		t.Fatalf("RevokeTx failed: %v", err)
+	}
+	if err := roleRepo.RevokeTx(ctx, tx, user.ID, support.ID, nil); err != nil {
+		t.Fatalf("RevokeTx failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	assignments, err = roleRepo.ListAssignmentsForUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ListAssignmentsForUser failed: %v", err)
+	}
+	if len(assignments) != 0 {
+		t.Errorf("Expected no assignments left, got %+v", assignments)
+	}
+}
+
+func TestRoleRepository_AssignRejectsUnknownReferences(t *testing.T) {
+	ctx := context.Background()
+	roleRepo := NewRoleRepository(testDB)
+
+	user := newTestUser(t)
+	support := findTestRole(t, domain.RoleSupport)
+	now := time.Now().UTC()
+
+	if err := roleRepo.Assign(ctx, uuid.New(), support.ID, nil, now); err != ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+	if err := roleRepo.Assign(ctx, user.ID, uuid.New(), nil, now); err != ErrRoleNotFound {
+		t.Errorf("Expected ErrRoleNotFound, got %v", err)
+	}
+	storeID := uuid.New()
+	if err := roleRepo.Assign(ctx, user.ID, support.ID, &storeID, now); err != ErrStoreNotFound {
+		t.Errorf("Expected ErrStoreNotFound, got %v", err)
+	}
+}
+
+func TestRoleRepository_ListHoldersForUpdateTxLocksGlobalGrants(t *testing.T) {
+	ctx := context.Background()
+	roleRepo := NewRoleRepository(testDB)
+
+	manager := findTestRole(t, domain.RoleStoreManager)
+	store := newTestStore(t)
+	global := newTestUser(t)
+	local := newTestUser(t)
+	now := time.Now().UTC()
+	if err := roleRepo.Assign(ctx, global.ID, manager.ID, nil, now); err != nil {
+		t.Fatalf("Assign failed: %v", err)
+	}
+	if err := roleRepo.Assign(ctx, local.ID, manager.ID, &store.ID, now); err != nil {
+		t.Fatalf("Assign failed: %v", err)
+	}
+
+	tx := beginTestTx(t)
+	holders, err := roleRepo.ListHoldersForUpdateTx(ctx, tx, manager.ID)
+	if err != nil {
+		t.Fatalf("ListHoldersForUpdateTx failed: %v", err)
+	}
+	held := map[uuid.UUID]bool{}
+	for _, userID := range holders {
+		held[userID] = true
+	}
+	// Only grants for every store count
+	if !held[global.ID] || held[local.ID] {
+		t.Errorf("Expected the global holder only, got %v", holders)
+	}
+
+	// A concurrent revocation waits until tx ends
+	other := beginTestTx(t)
+	_, err = other.ExecContext(ctx, `SELECT 1 FROM user_roles WHERE user_id = $1 AND role_id = $2 FOR UPDATE NOWAIT`, global.ID, manager.ID)
+	if !isLockNotAvailable(err) {
+		t.Errorf("Expected the holder's grant to be locked, got %v", err)
+	}
+}
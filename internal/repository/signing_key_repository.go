@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"pizza-must/internal/domain"
+)
+
+var ErrSigningKeyReplaced = errors.New("signing key has already been replaced")
+
+// SigningKeyRepository defines the interface for access token signing key data access
+type SigningKeyRepository interface {
+	Create(ctx context.Context, key *domain.SigningKey) error
+	List(ctx context.Context, retiredAfter time.Time) ([]*domain.SigningKey, error)
+	DeleteRetiredBefore(ctx context.Context, before time.Time) error
+}
+
+type signingKeyRepository struct {
+	db *sql.DB
+}
+
+// NewSigningKeyRepository creates a new instance of SigningKeyRepository
+func NewSigningKeyRepository(db *sql.DB) SigningKeyRepository {
+	return &signingKeyRepository{db: db}
+}
+
+// Create stores a key replacing the key named by its Replaces field, or the first key when
+// Replaces is empty. ErrSigningKeyReplaced is returned if that key already has a successor,
+// which happens when another process rotated it first.
+func (r *signingKeyRepository) Create(ctx context.Context, key *domain.SigningKey) error {
+	query := `
+		INSERT INTO signing_keys (id, algorithm, private_key, replaces, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, key.ID, key.Algorithm, key.PrivateKey, key.Replaces, key.CreatedAt)
+	if err != nil {
+		if isConstraintViolation(err, pgUniqueViolation, "idx_signing_keys_replaces") {
+			return ErrSigningKeyReplaced
+		}
+		return fmt.Errorf("failed to create signing key: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves the keys retired after retiredAfter, oldest first, followed by the signing
+// key. A key is retired when the key replacing it was created; the signing key comes last
+// even when a replica with a slow clock created it.
+func (r *signingKeyRepository) List(ctx context.Context, retiredAfter time.Time) ([]*domain.SigningKey, error) {
+	query := `
+		SELECT k.id, k.algorithm, k.private_key, COALESCE(k.replaces, ''), k.created_at, s.created_at
+		FROM signing_keys k
+		LEFT JOIN signing_keys s ON s.replaces = k.id
+		WHERE s.created_at IS NULL OR s.created_at > $1
+		ORDER BY s.created_at IS NULL, k.created_at, k.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, retiredAfter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*domain.SigningKey{}
+	for rows.Next() {
+		key := &domain.SigningKey{}
+		if err := rows.Scan(&key.ID, &key.Algorithm, &key.PrivateKey, &key.Replaces, &key.CreatedAt, &key.RetiredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan signing key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating signing keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// DeleteRetiredBefore deletes the keys retired before the given time. Their successors keep
+// naming them, so a retired key can still not be replaced a second time.
+func (r *signingKeyRepository) DeleteRetiredBefore(ctx context.Context, before time.Time) error {
+	query := `
+		DELETE FROM signing_keys k
+		USING signing_keys s
+		WHERE s.replaces = k.id AND s.created_at < $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, before); err != nil {
+		return fmt.Errorf("failed to delete retired signing keys: %w", err)
+	}
+
+	return nil
+}
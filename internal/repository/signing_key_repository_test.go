@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// newTestSigningKey builds a key replacing the key with ID replaces, created at createdAt
+func newTestSigningKey(replaces string, createdAt time.Time) *domain.SigningKey {
+	return &domain.SigningKey{
+		ID:         uuid.New().String(),
+		Algorithm:  "EdDSA",
+		PrivateKey: []byte("pkcs8-" + replaces),
+		Replaces:   replaces,
+		CreatedAt:  createdAt,
+	}
+}
+
+func TestSigningKeyRepository_RotationChain(t *testing.T) {
+	ctx := context.Background()
+	keyRepo := NewSigningKeyRepository(testDB)
+
+	if _, err := testDB.ExecContext(ctx, `DELETE FROM signing_keys`); err != nil {
+		t.Fatalf("Failed to clear signing keys: %v", err)
+	}
+
+	start := time.Now().UTC().Truncate(time.Second)
+	first := newTestSigningKey("", start)
+	if err := keyRepo.Create(ctx, first); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	// Only one key may start the chain
+	if err := keyRepo.Create(ctx, newTestSigningKey("", start)); err != ErrSigningKeyReplaced {
+		t.Errorf("Expected ErrSigningKeyReplaced for a second first key, got %v", err)
+	}
+
+	second := newTestSigningKey(first.ID, start.Add(time.Hour))
+	if err := keyRepo.Create(ctx, second); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	// A key rotated concurrently elsewhere is not replaced twice
+	if err := keyRepo.Create(ctx, newTestSigningKey(first.ID, start.Add(time.Hour))); err != ErrSigningKeyReplaced {
+		t.Errorf("Expected ErrSigningKeyReplaced, got %v", err)
+	}
+
+	keys, err := keyRepo.List(ctx, start)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(keys) != 2 || keys[0].ID != first.ID || keys[1].ID != second.ID {
+		t.Fatalf("Expected both keys with the signing key last, got %+v", keys)
+	}
+	// A key is retired when its replacement is created
+	if keys[0].RetiredAt == nil || !keys[0].RetiredAt.Equal(second.CreatedAt) || keys[1].RetiredAt != nil {
+		t.Errorf("Unexpected retirement times: %v, %v", keys[0].RetiredAt, keys[1].RetiredAt)
+	}
+	if keys[1].Replaces != first.ID || keys[0].Replaces != "" || string(keys[1].PrivateKey) != string(second.PrivateKey) {
+		t.Errorf("Unexpected key: %+v", keys[1])
+	}
+
+	// Keys retired before the overlap window are neither listed nor kept
+	keys, err = keyRepo.List(ctx, start.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(keys) != 1 || keys[0].ID != second.ID {
+		t.Errorf("Expected only the signing key, got %+v", keys)
+	}
+	if err := keyRepo.DeleteRetiredBefore(ctx, start.Add(2*time.Hour)); err != nil {
+		t.Fatalf("DeleteRetiredBefore failed: %v", err)
+	}
+	if n := countRows(t, "signing_keys", "id = $1", first.ID); n != 0 {
+		t.Errorf("Expected the retired key to be deleted, %d left", n)
+	}
+	if n := countRows(t, "signing_keys", "id = $1", second.ID); n != 1 {
+		t.Errorf("Expected the signing key to be kept, %d left", n)
+	}
+}
@@ -0,0 +1,555 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrStoreNotFound        = errors.New("store not found")
+	ErrDeliveryZoneNotFound = errors.New("delivery zone not found")
+)
+
+// StoreRepository defines the interface for store, opening hours, delivery zone and store
+// menu data access
+type StoreRepository interface {
+	Create(ctx context.Context, store *domain.Store) error
+	Update(ctx context.Context, store *domain.Store) error
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.Store, error)
+	List(ctx context.Context, activeOnly bool) ([]*domain.Store, error)
+	ReplaceOpeningHoursTx(ctx context.Context, tx *sql.Tx, storeID uuid.UUID, hours []*domain.OpeningHours) error
+	ReplaceHoursExceptionsTx(ctx context.Context, tx *sql.Tx, storeID uuid.UUID, exceptions []*domain.HoursException) error
+	LockTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) error
+	ListProducts(ctx context.Context, storeID uuid.UUID) ([]*domain.StoreProduct, error)
+	SaveProduct(ctx context.Context, product *domain.StoreProduct) error
+	ListDeliveryZones(ctx context.Context, storeID uuid.UUID) ([]*domain.DeliveryZone, error)
+	CreateDeliveryZone(ctx context.Context, zone *domain.DeliveryZone) error
+	UpdateDeliveryZone(ctx context.Context, zone *domain.DeliveryZone) error
+	DeleteDeliveryZone(ctx context.Context, storeID, id uuid.UUID) error
+}
+
+type storeRepository struct {
+	db *sql.DB
+}
+
+// NewStoreRepository creates a new instance of StoreRepository
+func NewStoreRepository(db *sql.DB) StoreRepository {
+	return &storeRepository{db: db}
+}
+
+// Create inserts a new store; opening hours and their exceptions are set separately
+func (r *storeRepository) Create(ctx context.Context, store *domain.Store) error {
+	query := `
+		INSERT INTO stores (id, name, address_line1, address_line2, city, postal_code, country,
+		                    latitude, longitude, timezone, phone, active, slot_minutes, slot_capacity,
+		                    lead_minutes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		store.ID,
+		store.Name,
+		store.AddressLine1,
+		store.AddressLine2,
+		store.City,
+		store.PostalCode,
+		store.Country,
+		store.Latitude,
+		store.Longitude,
+		store.Timezone,
+		store.Phone,
+		store.Active,
+		store.SlotMinutes,
+		store.SlotCapacity,
+		store.LeadMinutes,
+		store.CreatedAt,
+		store.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces the details of an existing store
+func (r *storeRepository) Update(ctx context.Context, store *domain.Store) error {
+	query := `
+		UPDATE stores
+		SET name = $2, address_line1 = $3, address_line2 = $4, city = $5, postal_code = $6,
+		    country = $7, latitude = $8, longitude = $9, timezone = $10, phone = $11, active = $12,
+		    slot_minutes = $13, slot_capacity = $14, lead_minutes = $15
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		store.ID,
+		store.Name,
+		store.AddressLine1,
+		store.AddressLine2,
+		store.City,
+		store.PostalCode,
+		store.Country,
+		store.Latitude,
+		store.Longitude,
+		store.Timezone,
+		store.Phone,
+		store.Active,
+		store.SlotMinutes,
+		store.SlotCapacity,
+		store.LeadMinutes,
+	).Scan(&store.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrStoreNotFound
+		}
+		return fmt.Errorf("failed to update store: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a store with its opening hours and their exceptions
+func (r *storeRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Store, error) {
+	stores, err := r.queryStores(ctx, "WHERE id = $1", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find store: %w", err)
+	}
+	if len(stores) == 0 {
+		return nil, ErrStoreNotFound
+	}
+	return stores[0], nil
+}
+
+// List retrieves stores with their opening hours and their exceptions ordered by name, optionally only active ones
+func (r *storeRepository) List(ctx context.Context, activeOnly bool) ([]*domain.Store, error) {
+	where := ""
+	if activeOnly {
+		where = "WHERE active"
+	}
+
+	stores, err := r.queryStores(ctx, where)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stores: %w", err)
+	}
+	return stores, nil
+}
+
+// queryStores retrieves the stores matching where and loads their opening hours and the
+// exceptions from yesterday on; older exceptions no longer affect any slot
+func (r *storeRepository) queryStores(ctx context.Context, where string, args ...interface{}) ([]*domain.Store, error) {
+	query := `
+		SELECT id, name, address_line1, address_line2, city, postal_code, country,
+		       latitude, longitude, timezone, phone, active, slot_minutes, slot_capacity,
+		       lead_minutes, created_at, updated_at
+		FROM stores
+		` + where + `
+		ORDER BY name ASC, id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stores := []*domain.Store{}
+	byID := make(map[uuid.UUID]*domain.Store)
+	for rows.Next() {
+		store := &domain.Store{OpeningHours: []*domain.OpeningHours{}, HoursExceptions: []*domain.HoursException{}}
+		err := rows.Scan(
+			&store.ID,
+			&store.Name,
+			&store.AddressLine1,
+			&store.AddressLine2,
+			&store.City,
+			&store.PostalCode,
+			&store.Country,
+			&store.Latitude,
+			&store.Longitude,
+			&store.Timezone,
+			&store.Phone,
+			&store.Active,
+			&store.SlotMinutes,
+			&store.SlotCapacity,
+			&store.LeadMinutes,
+			&store.CreatedAt,
+			&store.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan store: %w", err)
+		}
+		stores = append(stores, store)
+		byID[store.ID] = store
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stores: %w", err)
+	}
+
+	if len(stores) == 0 {
+		return stores, nil
+	}
+
+	hoursQuery := `
+		SELECT store_id, weekday, to_char(opens_at, 'HH24:MI'), to_char(closes_at, 'HH24:MI')
+		FROM store_opening_hours
+		WHERE store_id IN (SELECT id FROM stores ` + where + `)
+		ORDER BY store_id, weekday, opens_at
+	`
+
+	hoursRows, err := r.db.QueryContext(ctx, hoursQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list opening hours: %w", err)
+	}
+	defer hoursRows.Close()
+
+	for hoursRows.Next() {
+		var storeID uuid.UUID
+		hours := &domain.OpeningHours{}
+		if err := hoursRows.Scan(&storeID, &hours.Weekday, &hours.Opens, &hours.Closes); err != nil {
+			return nil, fmt.Errorf("failed to scan opening hours: %w", err)
+		}
+		if store, exists := byID[storeID]; exists {
+			store.OpeningHours = append(store.OpeningHours, hours)
+		}
+	}
+
+	if err = hoursRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating opening hours: %w", err)
+	}
+
+	exceptionsQuery := `
+		SELECT store_id, to_char(date, 'YYYY-MM-DD'), COALESCE(to_char(opens_at, 'HH24:MI'), ''),
+		       COALESCE(to_char(closes_at, 'HH24:MI'), ''), note
+		FROM store_hours_exceptions
+		WHERE date >= CURRENT_DATE - 1 AND store_id IN (SELECT id FROM stores ` + where + `)
+		ORDER BY store_id, date
+	`
+
+	exceptionRows, err := r.db.QueryContext(ctx, exceptionsQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list opening hours exceptions: %w", err)
+	}
+	defer exceptionRows.Close()
+
+	for exceptionRows.Next() {
+		var storeID uuid.UUID
+		exception := &domain.HoursException{}
+		if err := exceptionRows.Scan(&storeID, &exception.Date, &exception.Opens, &exception.Closes, &exception.Note); err != nil {
+			return nil, fmt.Errorf("failed to scan opening hours exception: %w", err)
+		}
+		if store, exists := byID[storeID]; exists {
+			store.HoursExceptions = append(store.HoursExceptions, exception)
+		}
+	}
+
+	if err = exceptionRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating opening hours exceptions: %w", err)
+	}
+
+	return stores, nil
+}
+
+// ReplaceOpeningHoursTx replaces every opening period of a store inside tx
+func (r *storeRepository) ReplaceOpeningHoursTx(ctx context.Context, tx *sql.Tx, storeID uuid.UUID, hours []*domain.OpeningHours) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM store_opening_hours WHERE store_id = $1`, storeID); err != nil {
+		return fmt.Errorf("failed to delete opening hours: %w", err)
+	}
+
+	query := `
+		INSERT INTO store_opening_hours (store_id, weekday, opens_at, closes_at)
+		VALUES ($1, $2, $3::time, $4::time)
+	`
+
+	for _, h := range hours {
+		if _, err := tx.ExecContext(ctx, query, storeID, h.Weekday, h.Opens, h.Closes); err != nil {
+			if isConstraintViolation(err, pgForeignKeyViolation, "fk_store_opening_hours_store") {
+				return ErrStoreNotFound
+			}
+			return fmt.Errorf("failed to insert opening hours: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ReplaceHoursExceptionsTx replaces every opening hours exception of a store inside tx
+func (r *storeRepository) ReplaceHoursExceptionsTx(ctx context.Context, tx *sql.Tx, storeID uuid.UUID, exceptions []*domain.HoursException) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM store_hours_exceptions WHERE store_id = $1`, storeID); err != nil {
+		return fmt.Errorf("failed to delete opening hours exceptions: %w", err)
+	}
+
+	query := `
+		INSERT INTO store_hours_exceptions (store_id, date, opens_at, closes_at, note)
+		VALUES ($1, $2::date, NULLIF($3, '')::time, NULLIF($4, '')::time, $5)
+	`
+
+	for _, e := range exceptions {
+		if _, err := tx.ExecContext(ctx, query, storeID, e.Date, e.Opens, e.Closes, e.Note); err != nil {
+			if isConstraintViolation(err, pgForeignKeyViolation, "fk_store_hours_exceptions_store") {
+				return ErrStoreNotFound
+			}
+			return fmt.Errorf("failed to insert opening hours exception: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// LockTx locks a store row until tx ends, so checkouts booking its time slots run one at a time
+func (r *storeRepository) LockTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	var locked uuid.UUID
+	err := tx.QueryRowContext(ctx, `SELECT id FROM stores WHERE id = $1 FOR UPDATE`, id).Scan(&locked)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrStoreNotFound
+		}
+		return fmt.Errorf("failed to lock store: %w", err)
+	}
+	return nil
+}
+
+// ListProducts retrieves a store's listings of catalog products, including unavailable ones
+func (r *storeRepository) ListProducts(ctx context.Context, storeID uuid.UUID) ([]*domain.StoreProduct, error) {
+	query := `
+		SELECT sp.store_id, sp.product_id, sp.available, sp.stock, sp.price, sp.updated_at
+		FROM store_products sp
+		JOIN products p ON p.id = sp.product_id
+		WHERE sp.store_id = $1
+		ORDER BY p.name ASC, p.id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, storeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list store products: %w", err)
+	}
+	defer rows.Close()
+
+	products := []*domain.StoreProduct{}
+	for rows.Next() {
+		product := &domain.StoreProduct{}
+		err := rows.Scan(
+			&product.StoreID,
+			&product.ProductID,
+			&product.Available,
+			&product.Stock,
+			&product.Price,
+			&product.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan store product: %w", err)
+		}
+		products = append(products, product)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating store products: %w", err)
+	}
+
+	return products, nil
+}
+
+// SaveProduct inserts or replaces a store's listing of a catalog product
+func (r *storeRepository) SaveProduct(ctx context.Context, product *domain.StoreProduct) error {
+	query := `
+		INSERT INTO store_products (store_id, product_id, available, stock, price, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT ON CONSTRAINT store_products_pkey
+		DO UPDATE SET available = EXCLUDED.available, stock = EXCLUDED.stock,
+		              price = EXCLUDED.price, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		product.StoreID,
+		product.ProductID,
+		product.Available,
+		product.Stock,
+		product.Price,
+		product.UpdatedAt,
+	)
+
+	if err != nil {
+		if isConstraintViolation(err, pgForeignKeyViolation, "fk_store_products_store") {
+			return ErrStoreNotFound
+		}
+		if isConstraintViolation(err, pgForeignKeyViolation, "fk_store_products_product") {
+			return ErrProductNotFound
+		}
+		return fmt.Errorf("failed to save store product: %w", err)
+	}
+
+	return nil
+}
+
+// ListDeliveryZones retrieves a store's delivery zones, including inactive ones, ordered by name
+func (r *storeRepository) ListDeliveryZones(ctx context.Context, storeID uuid.UUID) ([]*domain.DeliveryZone, error) {
+	query := `
+		SELECT id, store_id, name, COALESCE(radius_meters, 0), polygon, min_order_value, delivery_fee,
+		       active, created_at, updated_at
+		FROM delivery_zones
+		WHERE store_id = $1
+		ORDER BY name ASC, id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, storeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delivery zones: %w", err)
+	}
+	defer rows.Close()
+
+	zones := []*domain.DeliveryZone{}
+	for rows.Next() {
+		zone := &domain.DeliveryZone{}
+		var polygon []byte
+		err := rows.Scan(
+			&zone.ID,
+			&zone.StoreID,
+			&zone.Name,
+			&zone.RadiusMeters,
+			&polygon,
+			&zone.MinOrderValue,
+			&zone.DeliveryFee,
+			&zone.Active,
+			&zone.CreatedAt,
+			&zone.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan delivery zone: %w", err)
+		}
+		if polygon != nil {
+			if err := json.Unmarshal(polygon, &zone.Polygon); err != nil {
+				return nil, fmt.Errorf("failed to decode delivery zone polygon: %w", err)
+			}
+		}
+		zones = append(zones, zone)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating delivery zones: %w", err)
+	}
+
+	return zones, nil
+}
+
+// CreateDeliveryZone inserts a new delivery zone
+func (r *storeRepository) CreateDeliveryZone(ctx context.Context, zone *domain.DeliveryZone) error {
+	polygon, err := encodePolygon(zone)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO delivery_zones (id, store_id, name, radius_meters, polygon, min_order_value,
+		                            delivery_fee, active, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, 0), $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		zone.ID,
+		zone.StoreID,
+		zone.Name,
+		zone.RadiusMeters,
+		polygon,
+		zone.MinOrderValue,
+		zone.DeliveryFee,
+		zone.Active,
+		zone.CreatedAt,
+		zone.UpdatedAt,
+	)
+
+	if err != nil {
+		if isConstraintViolation(err, pgForeignKeyViolation, "fk_delivery_zones_store") {
+			return ErrStoreNotFound
+		}
+		return fmt.Errorf("failed to create delivery zone: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateDeliveryZone replaces every writable field of one of a store's delivery zones
+func (r *storeRepository) UpdateDeliveryZone(ctx context.Context, zone *domain.DeliveryZone) error {
+	polygon, err := encodePolygon(zone)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE delivery_zones
+		SET name = $3, radius_meters = NULLIF($4, 0), polygon = $5, min_order_value = $6,
+		    delivery_fee = $7, active = $8
+		WHERE id = $1 AND store_id = $2
+		RETURNING created_at, updated_at
+	`
+
+	err = r.db.QueryRowContext(
+		ctx,
+		query,
+		zone.ID,
+		zone.StoreID,
+		zone.Name,
+		zone.RadiusMeters,
+		polygon,
+		zone.MinOrderValue,
+		zone.DeliveryFee,
+		zone.Active,
+	).Scan(&zone.CreatedAt, &zone.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrDeliveryZoneNotFound
+		}
+		return fmt.Errorf("failed to update delivery zone: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteDeliveryZone removes one of a store's delivery zones
+func (r *storeRepository) DeleteDeliveryZone(ctx context.Context, storeID, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM delivery_zones WHERE id = $1 AND store_id = $2`, id, storeID)
+	if err != nil {
+		return fmt.Errorf("failed to delete delivery zone: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrDeliveryZoneNotFound
+	}
+
+	return nil
+}
+
+// encodePolygon returns the JSON polygon column of a zone, or nil for radius zones
+func encodePolygon(zone *domain.DeliveryZone) (interface{}, error) {
+	if zone.RadiusMeters > 0 {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(zone.Polygon)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode delivery zone polygon: %w", err)
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestStoreRepository_CreateUpdateAndFind(t *testing.T) {
+	ctx := context.Background()
+	storeRepo := NewStoreRepository(testDB)
+
+	store := newTestStore(t)
+
+	found, err := storeRepo.FindByID(ctx, store.ID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if found.Name != store.Name || found.Country != "IT" || found.Timezone != "Europe/Rome" ||
+		found.Latitude != store.Latitude || found.SlotCapacity != domain.DefaultSlotCapacity {
+		t.Errorf("Unexpected store: %+v", found)
+	}
+	// A store without hours reads back with empty lists, not nil
+	if found.OpeningHours == nil || found.HoursExceptions == nil {
+		t.Error("Expected empty opening hours and exceptions")
+	}
+
+	store.Name = "Renamed store"
+	store.Active = false
+	store.SlotMinutes = 20
+	if err := storeRepo.Update(ctx, store); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	found, err = storeRepo.FindByID(ctx, store.ID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if found.Name != "Renamed store" || found.Active || found.SlotMinutes != 20 {
+		t.Errorf("Expected the update to be persisted, got %+v", found)
+	}
+
+	active, err := storeRepo.List(ctx, true)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	for _, s := range active {
+		if s.ID == store.ID {
+			t.Error("Inactive stores must not be listed as active")
+		}
+	}
+	all, err := storeRepo.List(ctx, false)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	listed := false
+	for _, s := range all {
+		listed = listed || s.ID == store.ID
+	}
+	if !listed {
+		t.Error("Expected the inactive store in the full listing")
+	}
+
+	missing := *store
+	missing.ID = uuid.New()
+	if err := storeRepo.Update(ctx, &missing); err != ErrStoreNotFound {
+		t.Errorf("Expected ErrStoreNotFound updating an unknown store, got %v", err)
+	}
+	if _, err := storeRepo.FindByID(ctx, missing.ID); err != ErrStoreNotFound {
+		t.Errorf("Expected ErrStoreNotFound, got %v", err)
+	}
+}
+
+func TestStoreRepository_ReplaceHours(t *testing.T) {
+	ctx := context.Background()
+	storeRepo := NewStoreRepository(testDB)
+
+	store := newTestStore(t)
+	holiday := time.Now().AddDate(0, 0, 10).Format("2006-01-02")
+	past := time.Now().AddDate(0, 0, -10).Format("2006-01-02")
+
+	tx := beginTestTx(t)
+	err := storeRepo.ReplaceOpeningHoursTx(ctx, tx, store.ID, []*domain.OpeningHours{
+		{Weekday: 1, Opens: "11:00", Closes: "15:00"},
+		{Weekday: 1, Opens: "18:00", Closes: "23:00"},
+		{Weekday: 5, Opens: "18:00", Closes: "02:00"},
+	})
+	if err != nil {
+		t.Fatalf("ReplaceOpeningHoursTx failed: %v", err)
+	}
+	err = storeRepo.ReplaceHoursExceptionsTx(ctx, tx, store.ID, []*domain.HoursException{
+		{Date: holiday, Note: "Closed for the holiday"},
+		{Date: past, Opens: "12:00", Closes: "14:00"},
+	})
+	if err != nil {
+		t.Fatalf("ReplaceHoursExceptionsTx failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	found, err := storeRepo.FindByID(ctx, store.ID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if len(found.OpeningHours) != 3 {
+		t.Fatalf("Expected 3 opening periods, got %d", len(found.OpeningHours))
+	}
+	if first := found.OpeningHours[0]; first.Weekday != 1 || first.Opens != "11:00" || first.Closes != "15:00" {
+		t.Errorf("Unexpected first period: %+v", first)
+	}
+	if overnight := found.OpeningHours[2]; overnight.Opens != "18:00" || overnight.Closes != "02:00" {
+		t.Errorf("Unexpected overnight period: %+v", overnight)
+	}
+	// Past exceptions are no longer loaded; a closed day has no times
+	if len(found.HoursExceptions) != 1 {
+		t.Fatalf("Expected only the upcoming exception, got %+v", found.HoursExceptions)
+	}
+	if exception := found.HoursExceptions[0]; exception.Date != holiday || !exception.Closed() || exception.Note != "Closed for the holiday" {
+		t.Errorf("Unexpected exception: %+v", exception)
+	}
+
+	// Replacing with nothing clears them
+	tx = beginTestTx(t)
+	if err := storeRepo.ReplaceOpeningHoursTx(ctx, tx, store.ID, nil); err != nil {
+		t.Fatalf("ReplaceOpeningHoursTx failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	found, err = storeRepo.FindByID(ctx, store.ID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if len(found.OpeningHours) != 0 {
+		t.Errorf("Expected no opening hours, got %+v", found.OpeningHours)
+	}
+}
+
+func TestStoreRepository_LockTx(t *testing.T) {
+	ctx := context.Background()
+	storeRepo := NewStoreRepository(testDB)
+
+	store := newTestStore(t)
+
+	tx := beginTestTx(t)
+	if err := storeRepo.LockTx(ctx, tx, store.ID); err != nil {
+		t.Fatalf("LockTx failed: %v", err)
+	}
+	other := beginTestTx(t)
+	_, err := other.ExecContext(ctx, `SELECT 1 FROM stores WHERE id = $1 FOR UPDATE NOWAIT`, store.ID)
+	if !isLockNotAvailable(err) {
+		t.Errorf("Expected the store row to be locked, got %v", err)
+	}
+
+	tx = beginTestTx(t)
+	if err := storeRepo.LockTx(ctx, tx, uuid.New()); err != ErrStoreNotFound {
+		t.Errorf("Expected ErrStoreNotFound, got %v", err)
+	}
+}
+
+func TestStoreRepository_SaveProduct(t *testing.T) {
+	ctx := context.Background()
+	storeRepo := NewStoreRepository(testDB)
+
+	store := newTestStore(t)
+	product := newTestProduct(t, 1000)
+	stockTestProduct(t, store.ID, product.ID, 4)
+
+	products, err := storeRepo.ListProducts(ctx, store.ID)
+	if err != nil {
+		t.Fatalf("ListProducts failed: %v", err)
+	}
+	// Listings without their own price sell at the catalog price
+	if len(products) != 1 || products[0].Stock != 4 || !products[0].Available || products[0].Price != nil {
+		t.Fatalf("Unexpected listings: %+v", products)
+	}
+
+	price := domain.Cents(950)
+	listing := &domain.StoreProduct{
+		StoreID:   store.ID,
+		ProductID: product.ID,
+		Available: false,
+		Stock:     9,
+		Price:     &price,
+		UpdatedAt: time.Now().UTC(),
+	}
+	if err := storeRepo.SaveProduct(ctx, listing); err != nil {
+		t.Fatalf("SaveProduct failed: %v", err)
+	}
+
+	products, err = storeRepo.ListProducts(ctx, store.ID)
+	if err != nil {
+		t.Fatalf("ListProducts failed: %v", err)
+	}
+	// Saving again replaces the listing
+	if len(products) != 1 || products[0].Stock != 9 || products[0].Available ||
+		products[0].Price == nil || products[0].Price.Amount != 950 {
+		t.Errorf("Expected the listing to be replaced, got %+v", products)
+	}
+
+	listing.ProductID = uuid.New()
+	if err := storeRepo.SaveProduct(ctx, listing); err != ErrProductNotFound {
+		t.Errorf("Expected ErrProductNotFound, got %v", err)
+	}
+	listing.ProductID = product.ID
+	listing.StoreID = uuid.New()
+	if err := storeRepo.SaveProduct(ctx, listing); err != ErrStoreNotFound {
+		t.Errorf("Expected ErrStoreNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Transactor runs a unit of work inside a single database transaction
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(tx *sql.Tx) error) error
+}
+
+type transactor struct {
+	db *sql.DB
+}
+
+// NewTransactor creates a new instance of Transactor
+func NewTransactor(db *sql.DB) Transactor {
+	return &transactor{db: db}
+}
+
+// WithinTx begins a transaction, runs fn and commits if fn succeeds.
+// The transaction is rolled back if fn returns an error or panics.
+func (t *transactor) WithinTx(ctx context.Context, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
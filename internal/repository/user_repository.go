@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"pizza-must/internal/domain"
 
@@ -21,6 +22,10 @@ type UserRepository interface {
 	Create(ctx context.Context, user *domain.User) error
 	FindByEmail(ctx context.Context, email string) (*domain.User, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, id uuid.UUID, verifiedAt time.Time) error
+	Update(ctx context.Context, user *domain.User) error
+	AnonymizeTx(ctx context.Context, tx *sql.Tx, id uuid.UUID, deletedAt time.Time) error
 }
 
 type userRepository struct {
@@ -32,11 +37,19 @@ func NewUserRepository(db *sql.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
+// userColumns lists the user columns in the order scanUser reads them
+const userColumns = `
+	id, email, password_hash, first_name, last_name, phone, created_at, updated_at,
+	email_verified_at, deleted_at
+`
+
 // Create inserts a new user into the database using parameterized queries
 func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
 	query := `
-		INSERT INTO users (id, email, password_hash, first_name, last_name, role, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO users (
+			id, email, password_hash, first_name, last_name, phone, created_at, updated_at, email_verified_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
 	_, err := r.db.ExecContext(
@@ -47,9 +60,10 @@ func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
 		user.PasswordHash,
 		user.FirstName,
 		user.LastName,
-		user.Role,
+		user.Phone,
 		user.CreatedAt,
 		user.UpdatedAt,
+		user.EmailVerifiedAt,
 	)
 
 	if err != nil {
@@ -64,29 +78,14 @@ func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
 	return nil
 }
 
-// FindByEmail retrieves a user by email using parameterized queries
+// FindByEmail retrieves an active user by email using parameterized queries
 func (r *userRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
-	query := `
-		SELECT id, email, password_hash, first_name, last_name, role, created_at, updated_at
-		FROM users
-		WHERE email = $1
-	`
-
-	user := &domain.User{}
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
-		&user.ID,
-		&user.Email,
-		&user.PasswordHash,
-		&user.FirstName,
-		&user.LastName,
-		&user.Role,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1 AND deleted_at IS NULL`
 
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, email))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, ErrUserNotFound
+		if err == ErrUserNotFound {
+			return nil, err
 		}
 		return nil, fmt.Errorf("failed to find user by email: %w", err)
 	}
@@ -94,31 +93,173 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (*domain
 	return user, nil
 }
 
-// FindByID retrieves a user by ID using parameterized queries
+// FindByID retrieves an active user by ID using parameterized queries
 func (r *userRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1 AND deleted_at IS NULL`
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == ErrUserNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find user by ID: %w", err)
+	}
+
+	return user, nil
+}
+
+// Update replaces the profile attributes and email address of an active user. Changing
+// the email address discards outstanding verification links sent to the old address.
+func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
 	query := `
-		SELECT id, email, password_hash, first_name, last_name, role, created_at, updated_at
-		FROM users
-		WHERE id = $1
+		WITH verifications AS (
+			DELETE FROM email_verification_tokens t
+			USING users u
+			WHERE u.id = $1 AND t.user_id = u.id AND u.email <> $2
+		)
+		UPDATE users
+		SET email = $2, first_name = $3, last_name = $4, phone = $5, email_verified_at = $6, updated_at = $7
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		user.ID,
+		user.Email,
+		user.FirstName,
+		user.LastName,
+		user.Phone,
+		user.EmailVerifiedAt,
+		user.UpdatedAt,
+	)
+	if err != nil {
+		if isConstraintViolation(err, pgUniqueViolation, "users_email_key") {
+			return ErrUserAlreadyExists
+		}
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// AnonymizeTx deletes an account inside tx without removing its row, which orders still
+// reference. Personal data is erased, the email address is freed and no password can match.
+// The user's cart, coupon, sessions, roles, address book and outstanding reset and
+// verification tokens are removed in the same statement, so a failure leaves the account
+// untouched. Orders keep their own snapshot of the address they were delivered to.
+func (r *userRepository) AnonymizeTx(ctx context.Context, tx *sql.Tx, id uuid.UUID, deletedAt time.Time) error {
+	query := `
+		WITH sessions AS (
+			UPDATE refresh_tokens SET revoked = TRUE WHERE user_id = $1 AND revoked = FALSE
+		), cart AS (
+			DELETE FROM cart_items WHERE user_id = $1
+		), coupon AS (
+			DELETE FROM cart_coupons WHERE user_id = $1
+		), resets AS (
+			DELETE FROM password_reset_tokens WHERE user_id = $1
+		), verifications AS (
+			DELETE FROM email_verification_tokens WHERE user_id = $1
+		), roles AS (
+			DELETE FROM user_roles WHERE user_id = $1
+		), addresses AS (
+			DELETE FROM addresses WHERE user_id = $1
+		)
+		UPDATE users
+		SET email = 'deleted-' || id || '@deleted.invalid', password_hash = '', first_name = '',
+		    last_name = '', phone = '', email_verified_at = NULL, deleted_at = $2, updated_at = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := tx.ExecContext(ctx, query, id, deletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to anonymize user: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// UpdatePassword replaces the password hash of a user
+func (r *userRepository) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	query := `UPDATE users SET password_hash = $2 WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, passwordHash)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// MarkEmailVerified records when a user verified their email address.
+// An address that is already verified keeps its original verification time.
+func (r *userRepository) MarkEmailVerified(ctx context.Context, id uuid.UUID, verifiedAt time.Time) error {
+	query := `UPDATE users SET email_verified_at = COALESCE(email_verified_at, $2) WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, verifiedAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// scanUser reads a single user row selected with userColumns
+func scanUser(row rowScanner) (*domain.User, error) {
 	user := &domain.User{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := row.Scan(
 		&user.ID,
 		&user.Email,
 		&user.PasswordHash,
 		&user.FirstName,
 		&user.LastName,
-		&user.Role,
+		&user.Phone,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.EmailVerifiedAt,
+		&user.DeletedAt,
 	)
-
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrUserNotFound
 		}
-		return nil, fmt.Errorf("failed to find user by ID: %w", err)
+		return nil, err
 	}
 
 	return user, nil
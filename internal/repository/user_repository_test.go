@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"pizza-must/internal/database"
 	"pizza-must/internal/domain"
 
 	"github.com/google/uuid"
@@ -16,6 +17,7 @@ import (
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 	"github.com/testcontainers/testcontainers-go/wait"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
@@ -61,20 +63,8 @@ func setupTestDB() (func(context.Context, ...testcontainers.TerminateOption) err
 		return dbContainer.Terminate, err
 	}
 
-	// Create the users table
-	_, err = testDB.Exec(`
-		CREATE TABLE IF NOT EXISTS users (
-			id UUID PRIMARY KEY,
-			email VARCHAR(255) UNIQUE NOT NULL,
-			password_hash VARCHAR(255) NOT NULL,
-			first_name VARCHAR(100),
-			last_name VARCHAR(100),
-			role VARCHAR(50) NOT NULL DEFAULT 'user',
-			created_at TIMESTAMP NOT NULL,
-			updated_at TIMESTAMP NOT NULL
-		)
-	`)
-	if err != nil {
+	// Tests run against the schema the migrations build
+	if err := database.RunMigrations(testDB, "../../migrations", zap.NewNop()); err != nil {
 		return dbContainer.Terminate, err
 	}
 
@@ -123,7 +113,6 @@ func TestProperty_RegistrationCreatesHashedPasswords(t *testing.T) {
 				PasswordHash: string(hashedPassword),
 				FirstName:    firstName,
 				LastName:     lastName,
-				Role:         "user",
 				CreatedAt:    time.Now(),
 				UpdatedAt:    time.Now(),
 			}
@@ -172,3 +161,60 @@ func TestProperty_RegistrationCreatesHashedPasswords(t *testing.T) {
 
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
 }
+
+func TestUserRepository_AnonymizeTxErasesPersonalData(t *testing.T) {
+	ctx := context.Background()
+	userRepo := NewUserRepository(testDB)
+
+	user := newTestUser(t)
+	store := newTestStore(t)
+	product := newTestProduct(t, 1200)
+	stockTestProduct(t, store.ID, product.ID, 5)
+	newTestCart(t, user.ID, store.ID, product.ID, 1)
+	newTestAddress(t, user.ID)
+	if err := NewRoleRepository(testDB).Assign(ctx, user.ID, findTestRole(t, domain.RoleSupport).ID, nil, time.Now()); err != nil {
+		t.Fatalf("Failed to assign role: %v", err)
+	}
+	if err := NewRefreshTokenRepository(testDB).Create(ctx, newTestRefreshToken(user.ID, uuid.New())); err != nil {
+		t.Fatalf("Failed to create refresh token: %v", err)
+	}
+	order := newTestOrder(user.ID, store.ID, product.ID)
+	createTestOrder(t, order)
+
+	tx := beginTestTx(t)
+	if err := userRepo.AnonymizeTx(ctx, tx, user.ID, time.Now().UTC()); err != nil {
+		t.Fatalf("AnonymizeTx failed: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	if _, err := userRepo.FindByID(ctx, user.ID); err != ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound for a deleted account, got %v", err)
+	}
+	for _, table := range []string{"addresses", "cart_items", "user_roles"} {
+		if count := countRows(t, table, "user_id = $1", user.ID); count != 0 {
+			t.Errorf("Expected no %s left, got %d", table, count)
+		}
+	}
+	if count := countRows(t, "refresh_tokens", "user_id = $1 AND revoked = FALSE", user.ID); count != 0 {
+		t.Errorf("Expected every session to be revoked, got %d live", count)
+	}
+	if count := countRows(t, "users", "id = $1 AND first_name = '' AND email LIKE 'deleted-%'", user.ID); count != 1 {
+		t.Error("Expected the user row to be kept without personal data")
+	}
+
+	// Orders keep the address they were delivered to
+	kept, err := NewOrderRepository(testDB).FindByID(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if kept.DeliveryAddress == nil || kept.DeliveryAddress.AddressLine1 != order.DeliveryAddress.AddressLine1 {
+		t.Errorf("Expected the order's address snapshot to be kept, got %+v", kept.DeliveryAddress)
+	}
+
+	tx = beginTestTx(t)
+	if err := userRepo.AnonymizeTx(ctx, tx, user.ID, time.Now().UTC()); err != ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound deleting twice, got %v", err)
+	}
+}
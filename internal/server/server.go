@@ -1,12 +1,16 @@
 package server
 
 import (
+	"context"
 	"database/sql"
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"time"
 
+	"pizza-must/internal/auth"
 	"pizza-must/internal/config"
+	"pizza-must/internal/mailer"
 	custommiddleware "pizza-must/internal/middleware"
 	"pizza-must/internal/repository"
 	"pizza-must/internal/service"
@@ -24,13 +28,19 @@ type Server struct {
 	db     *sql.DB
 }
 
-func NewServer(cfg *config.Config, logger *zap.Logger, db *sql.DB) *Server {
+func NewServer(cfg *config.Config, logger *zap.Logger, db *sql.DB) (*Server, error) {
+	// Only the configured reverse proxies may report the client IP
+	trustedProxies, err := custommiddleware.ParseTrustedProxies(cfg.Server.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create router
 	router := chi.NewRouter()
 
 	// Add basic middleware
 	router.Use(middleware.RequestID)
-	router.Use(middleware.RealIP)
+	router.Use(custommiddleware.RealIP(trustedProxies))
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.Compress(5))
 	router.Use(custommiddleware.ErrorHandlingMiddleware(logger))
@@ -44,19 +54,161 @@ func NewServer(cfg *config.Config, logger *zap.Logger, db *sql.DB) *Server {
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
 	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	passwordResetTokenRepo := repository.NewPasswordResetTokenRepository(db)
+	emailVerificationTokenRepo := repository.NewEmailVerificationTokenRepository(db)
+	loginThrottleRepo := repository.NewLoginThrottleRepository(db)
+	productRepo := repository.NewProductRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	optionRepo := repository.NewProductOptionRepository(db)
+	cartRepo := repository.NewCartRepository(db)
+	orderRepo := repository.NewOrderRepository(db)
+	promotionRepo := repository.NewPromotionRepository(db)
+	storeRepo := repository.NewStoreRepository(db)
+	addressRepo := repository.NewAddressRepository(db)
+	transactor := repository.NewTransactor(db)
+
+	// Build the token lifetimes from configuration
+	tokenPolicy := service.TokenPolicy{
+		AccessTokenTTL:  time.Duration(cfg.JWT.AccessExpiry) * time.Minute,
+		RefreshTokenTTL: time.Duration(cfg.JWT.RefreshExpiry) * 24 * time.Hour,
+		SessionMaxAge:   time.Duration(cfg.JWT.SessionMaxAge) * 24 * time.Hour,
+		IdleTimeout:     time.Duration(cfg.JWT.IdleTimeout) * time.Hour,
+	}
+	if err := tokenPolicy.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Initialize the access token signing keys from the database, so tokens survive restarts
+	// and verify on every replica; a rotated key must keep verifying the tokens it signed
+	// until they expire
+	overlap := time.Duration(cfg.JWT.OverlapMinutes) * time.Minute
+	if overlap < tokenPolicy.AccessTokenTTL {
+		return nil, fmt.Errorf("JWT key overlap of %s is shorter than the access token lifetime", overlap)
+	}
+	// The private keys are encrypted before they reach the database, so a leaked dump
+	// cannot sign tokens
+	keyEncryptionKey, err := base64.StdEncoding.DecodeString(cfg.JWT.KeyEncryptionKey)
+	if err != nil || len(keyEncryptionKey) != auth.KeyEncryptionKeySize {
+		return nil, fmt.Errorf("JWT_KEY_ENCRYPTION_KEY must be a base64 encoded %d byte key", auth.KeyEncryptionKeySize)
+	}
+	keyStore, err := auth.NewEncryptedKeyStore(repository.NewSigningKeyRepository(db), keyEncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize signing key store: %w", err)
+	}
+	keyManager, err := auth.NewKeyManager(auth.KeyManagerConfig{
+		Algorithm:        cfg.JWT.Algorithm,
+		RotationInterval: time.Duration(cfg.JWT.RotationHours) * time.Hour,
+		OverlapWindow:    overlap,
+	}, keyStore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize signing keys: %w", err)
+	}
+	tokenManager := auth.NewTokenManager(keyManager, auth.TokenConfig{
+		Issuer:   cfg.JWT.Issuer,
+		Audience: cfg.JWT.Audience,
+		Leeway:   time.Duration(cfg.JWT.LeewaySeconds) * time.Second,
+	})
+
+	// Build the failed login limits from configuration
+	loginThrottlePolicy := service.LoginThrottlePolicy{
+		FailureWindow:   time.Duration(cfg.Login.FailureWindowMinutes) * time.Minute,
+		BaseDelay:       time.Duration(cfg.Login.BaseDelaySeconds) * time.Second,
+		MaxDelay:        time.Duration(cfg.Login.MaxDelaySeconds) * time.Second,
+		LockoutDuration: time.Duration(cfg.Login.LockoutMinutes) * time.Minute,
+		Email: service.LoginLimit{
+			FreeAttempts:     cfg.Login.EmailFreeAttempts,
+			LockoutThreshold: cfg.Login.EmailLockoutThreshold,
+		},
+		IP: service.LoginLimit{
+			FreeAttempts:     cfg.Login.IPFreeAttempts,
+			LockoutThreshold: cfg.Login.IPLockoutThreshold,
+		},
+	}
+	if err := loginThrottlePolicy.Validate(); err != nil {
+		return nil, err
+	}
+
+	mail, err := newMailer(cfg.Mail, logger)
+	if err != nil {
+		return nil, err
+	}
 
 	// Initialize services
-	userService := service.NewUserService(userRepo, refreshTokenRepo, cfg.JWT.Secret)
+	userService := service.NewUserService(
+		transactor,
+		userRepo,
+		roleRepo,
+		refreshTokenRepo,
+		tokenManager,
+		tokenPolicy,
+		service.NewLoginThrottle(loginThrottleRepo, loginThrottlePolicy),
+	)
+	passwordResetService := service.NewPasswordResetService(userRepo, refreshTokenRepo, passwordResetTokenRepo, loginThrottleRepo, mail, service.PasswordResetConfig{
+		TokenTTL:        time.Duration(cfg.Password.ResetTTLMinutes) * time.Minute,
+		ResetURL:        cfg.Password.ResetURL,
+		RequestCooldown: time.Duration(cfg.Password.ResetCooldownSeconds) * time.Second,
+		IPRequestLimit:  cfg.Password.ResetIPLimit,
+		IPRequestWindow: time.Duration(cfg.Password.ResetIPWindowMinutes) * time.Minute,
+	})
+	emailVerificationService := service.NewEmailVerificationService(userRepo, emailVerificationTokenRepo, mail, service.EmailVerificationConfig{
+		TokenTTL:            time.Duration(cfg.Verification.TTLHours) * time.Hour,
+		VerifyURL:           cfg.Verification.URL,
+		ResendCooldown:      time.Duration(cfg.Verification.ResendCooldownSeconds) * time.Second,
+		RequiredForCheckout: cfg.Verification.RequiredForCheckout,
+	})
+	productService := service.NewProductService(transactor, productRepo, storeRepo, categoryRepo, optionRepo, cartRepo)
+	categoryService := service.NewCategoryService(categoryRepo)
+	pricingEngine := service.NewPricingEngine(productRepo, optionRepo)
+	promotionService := service.NewPromotionService(promotionRepo)
+	roleService := service.NewRoleService(transactor, roleRepo, userRepo)
+	storeService := service.NewStoreService(transactor, storeRepo, productRepo, orderRepo)
+	cartService := service.NewCartService(cartRepo, productRepo, storeRepo, promotionRepo, pricingEngine)
+	orderEvents := service.NewOrderEventBroker()
+	orderService := service.NewOrderService(transactor, orderRepo, cartRepo, productRepo, storeRepo, addressRepo, promotionRepo, pricingEngine, orderEvents)
+	addressService := service.NewAddressService(addressRepo)
+	kitchenService := service.NewKitchenService(transactor, orderRepo, orderEvents)
+
+	// A store without opening hours refuses every checkout, so make it visible at startup
+	warnUnscheduledStores(storeRepo, logger)
 
 	// Initialize handlers
-	userHandler := transport.NewUserHandler(userService, logger)
+	userHandler := transport.NewUserHandler(userService, emailVerificationService, logger)
+	emailVerificationHandler := transport.NewEmailVerificationHandler(emailVerificationService, logger)
+	passwordHandler := transport.NewPasswordHandler(userService, passwordResetService, logger)
+	jwksHandler := transport.NewJWKSHandler(keyManager, logger)
+	productHandler := transport.NewProductHandler(productService, categoryService, logger)
+	pricingHandler := transport.NewPricingHandler(pricingEngine, logger)
+	cartHandler := transport.NewCartHandler(cartService, logger)
+	orderHandler := transport.NewOrderHandler(orderService, logger)
+	promotionHandler := transport.NewPromotionHandler(promotionService, logger)
+	roleHandler := transport.NewRoleHandler(roleService, logger)
+	storeHandler := transport.NewStoreHandler(storeService, logger)
+	addressHandler := transport.NewAddressHandler(addressService, logger)
+	kitchenHandler := transport.NewKitchenHandler(kitchenService, logger)
 
 	// Create auth middleware
-	authMiddleware := custommiddleware.AuthMiddleware(cfg.JWT.Secret, logger)
+	authMiddleware := custommiddleware.AuthMiddleware(tokenManager, logger)
 
 	// Register routes
+	jwksHandler.RegisterRoutes(router)
 	userHandler.RegisterRoutes(router, authMiddleware)
+	userHandler.RegisterAdminRoutes(router, authMiddleware)
+	passwordHandler.RegisterRoutes(router, authMiddleware)
+	emailVerificationHandler.RegisterRoutes(router, authMiddleware)
+	addressHandler.RegisterRoutes(router, authMiddleware)
+	storeHandler.RegisterRoutes(router)
+	storeHandler.RegisterAdminRoutes(router, authMiddleware)
+	productHandler.RegisterRoutes(router)
+	productHandler.RegisterAdminRoutes(router, authMiddleware)
+	pricingHandler.RegisterRoutes(router)
+	cartHandler.RegisterRoutes(router, authMiddleware)
+	orderHandler.RegisterRoutes(router, authMiddleware, emailVerificationHandler.RequireCheckoutAllowed)
+	orderHandler.RegisterAdminRoutes(router, authMiddleware)
+	kitchenHandler.RegisterRoutes(router, authMiddleware)
+	promotionHandler.RegisterAdminRoutes(router, authMiddleware)
+	roleHandler.RegisterRoutes(router, authMiddleware)
 
 	server := &Server{
 		Server: &http.Server{
@@ -71,7 +223,51 @@ func NewServer(cfg *config.Config, logger *zap.Logger, db *sql.DB) *Server {
 		db:     db,
 	}
 
-	return server
+	return server, nil
+}
+
+// warnUnscheduledStores logs every active store without weekly opening hours, which is
+// always closed and refuses every checkout
+func warnUnscheduledStores(storeRepo repository.StoreRepository, logger *zap.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stores, err := storeRepo.List(ctx, true)
+	if err != nil {
+		logger.Warn("Failed to check store opening hours", zap.Error(err))
+		return
+	}
+	for _, store := range stores {
+		if len(store.OpeningHours) == 0 {
+			logger.Warn("Active store has no opening hours and refuses every checkout",
+				zap.String("store_id", store.ID.String()),
+				zap.String("store_name", store.Name),
+			)
+		}
+	}
+}
+
+// newMailer creates the mailer selected by the mail driver
+func newMailer(cfg config.MailConfig, logger *zap.Logger) (mailer.Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required by the smtp mail driver")
+		}
+		return mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.From,
+		}), nil
+	case "file":
+		return mailer.NewFileMailer(cfg.Dir, cfg.From)
+	case "log":
+		return mailer.NewLogMailer(logger), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
 }
 
 func (s *Server) Close() error {
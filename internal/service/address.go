@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidAddress = errors.New("invalid address")
+)
+
+// AddressInput holds the full set of writable address attributes
+type AddressInput struct {
+	Label        string
+	AddressLine1 string
+	AddressLine2 string
+	City         string
+	PostalCode   string
+	Country      string
+	Latitude     float64
+	Longitude    float64
+	Phone        string
+	Instructions string
+}
+
+// AddressService defines the interface for users' address books
+type AddressService interface {
+	ListAddresses(ctx context.Context, userID uuid.UUID) ([]*domain.Address, error)
+	GetAddress(ctx context.Context, userID, id uuid.UUID) (*domain.Address, error)
+	CreateAddress(ctx context.Context, userID uuid.UUID, input AddressInput) (*domain.Address, error)
+	UpdateAddress(ctx context.Context, userID, id uuid.UUID, input AddressInput) (*domain.Address, error)
+	DeleteAddress(ctx context.Context, userID, id uuid.UUID) error
+}
+
+type addressService struct {
+	addressRepo repository.AddressRepository
+}
+
+// NewAddressService creates a new instance of AddressService
+func NewAddressService(addressRepo repository.AddressRepository) AddressService {
+	return &addressService{
+		addressRepo: addressRepo,
+	}
+}
+
+// ListAddresses returns the user's addresses, oldest first
+func (s *addressService) ListAddresses(ctx context.Context, userID uuid.UUID) ([]*domain.Address, error) {
+	addresses, err := s.addressRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list addresses: %w", err)
+	}
+	return addresses, nil
+}
+
+// GetAddress retrieves one of the user's addresses. Addresses of other users are reported
+// as not found.
+func (s *addressService) GetAddress(ctx context.Context, userID, id uuid.UUID) (*domain.Address, error) {
+	address, err := s.addressRepo.FindByID(ctx, userID, id)
+	if err != nil {
+		if err == repository.ErrAddressNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find address: %w", err)
+	}
+	return address, nil
+}
+
+// CreateAddress validates input and adds it to the user's address book
+func (s *addressService) CreateAddress(ctx context.Context, userID uuid.UUID, input AddressInput) (*domain.Address, error) {
+	now := time.Now()
+	address := &domain.Address{
+		ID:        uuid.New(),
+		UserID:    userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := applyAddressInput(address, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.addressRepo.Create(ctx, address); err != nil {
+		return nil, fmt.Errorf("failed to create address: %w", err)
+	}
+
+	return address, nil
+}
+
+// UpdateAddress validates input and replaces every writable attribute of one of the
+// user's addresses. Orders already placed keep the address they were delivered to.
+func (s *addressService) UpdateAddress(ctx context.Context, userID, id uuid.UUID, input AddressInput) (*domain.Address, error) {
+	address, err := s.GetAddress(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyAddressInput(address, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.addressRepo.Update(ctx, address); err != nil {
+		if err == repository.ErrAddressNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update address: %w", err)
+	}
+
+	return address, nil
+}
+
+// DeleteAddress removes one of the user's addresses
+func (s *addressService) DeleteAddress(ctx context.Context, userID, id uuid.UUID) error {
+	if err := s.addressRepo.Delete(ctx, userID, id); err != nil {
+		if err == repository.ErrAddressNotFound {
+			return err
+		}
+		return fmt.Errorf("failed to delete address: %w", err)
+	}
+	return nil
+}
+
+// applyAddressInput validates input and copies it onto address
+func applyAddressInput(address *domain.Address, input AddressInput) error {
+	switch {
+	case strings.TrimSpace(input.AddressLine1) == "":
+		return fmt.Errorf("%w: address line 1 is required", ErrInvalidAddress)
+	case strings.TrimSpace(input.City) == "":
+		return fmt.Errorf("%w: city is required", ErrInvalidAddress)
+	case strings.TrimSpace(input.PostalCode) == "":
+		return fmt.Errorf("%w: postal code is required", ErrInvalidAddress)
+	case len(input.Country) != 2:
+		return fmt.Errorf("%w: country must be a two-letter code", ErrInvalidAddress)
+	case input.Latitude < -90 || input.Latitude > 90 || input.Longitude < -180 || input.Longitude > 180:
+		return fmt.Errorf("%w: coordinates are out of range", ErrInvalidAddress)
+	}
+
+	address.Label = strings.TrimSpace(input.Label)
+	address.AddressLine1 = strings.TrimSpace(input.AddressLine1)
+	address.AddressLine2 = strings.TrimSpace(input.AddressLine2)
+	address.City = strings.TrimSpace(input.City)
+	address.PostalCode = strings.TrimSpace(input.PostalCode)
+	address.Country = strings.ToUpper(input.Country)
+	address.Latitude = input.Latitude
+	address.Longitude = input.Longitude
+	address.Phone = input.Phone
+	address.Instructions = input.Instructions
+	return nil
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type mockAddressRepository struct {
+	addresses map[uuid.UUID]*domain.Address
+}
+
+func newMockAddressRepository() *mockAddressRepository {
+	return &mockAddressRepository{addresses: make(map[uuid.UUID]*domain.Address)}
+}
+
+func (m *mockAddressRepository) Create(ctx context.Context, address *domain.Address) error {
+	m.addresses[address.ID] = address
+	return nil
+}
+
+func (m *mockAddressRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Address, error) {
+	addresses := []*domain.Address{}
+	for _, address := range m.addresses {
+		if address.UserID == userID {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses, nil
+}
+
+func (m *mockAddressRepository) FindByID(ctx context.Context, userID, id uuid.UUID) (*domain.Address, error) {
+	address, exists := m.addresses[id]
+	if !exists || address.UserID != userID {
+		return nil, repository.ErrAddressNotFound
+	}
+	copied := *address
+	return &copied, nil
+}
+
+func (m *mockAddressRepository) Update(ctx context.Context, address *domain.Address) error {
+	existing, exists := m.addresses[address.ID]
+	if !exists || existing.UserID != address.UserID {
+		return repository.ErrAddressNotFound
+	}
+	address.UpdatedAt = time.Now()
+	m.addresses[address.ID] = address
+	return nil
+}
+
+func (m *mockAddressRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	existing, exists := m.addresses[id]
+	if !exists || existing.UserID != userID {
+		return repository.ErrAddressNotFound
+	}
+	delete(m.addresses, id)
+	return nil
+}
+
+// testAddressInput is an address about 250 metres north of the test store
+func testAddressInput() AddressInput {
+	return AddressInput{
+		Label:        "Home",
+		AddressLine1: "Via del Corso 1",
+		City:         "Roma",
+		PostalCode:   "00186",
+		Country:      "it",
+		Latitude:     41.9050,
+		Longitude:    12.4964,
+	}
+}
+
+func TestAddressService_AddressesBelongToTheirUser(t *testing.T) {
+	ctx := context.Background()
+	service := NewAddressService(newMockAddressRepository())
+	owner, stranger := uuid.New(), uuid.New()
+
+	address, err := service.CreateAddress(ctx, owner, testAddressInput())
+	if err != nil {
+		t.Fatalf("CreateAddress: %v", err)
+	}
+	if address.Country != "IT" || address.UserID != owner {
+		t.Errorf("expected an upper-case country owned by the user, got %+v", address)
+	}
+
+	if _, err := service.GetAddress(ctx, stranger, address.ID); err != repository.ErrAddressNotFound {
+		t.Errorf("expected another user's address to be not found, got %v", err)
+	}
+	if _, err := service.UpdateAddress(ctx, stranger, address.ID, testAddressInput()); err != repository.ErrAddressNotFound {
+		t.Errorf("expected updating another user's address to fail, got %v", err)
+	}
+	if err := service.DeleteAddress(ctx, stranger, address.ID); err != repository.ErrAddressNotFound {
+		t.Errorf("expected deleting another user's address to fail, got %v", err)
+	}
+
+	input := testAddressInput()
+	input.Instructions = "Ring twice"
+	updated, err := service.UpdateAddress(ctx, owner, address.ID, input)
+	if err != nil || updated.Instructions != "Ring twice" {
+		t.Fatalf("expected the owner to update the address, got %+v, %v", updated, err)
+	}
+
+	if err := service.DeleteAddress(ctx, owner, address.ID); err != nil {
+		t.Fatalf("DeleteAddress: %v", err)
+	}
+	if addresses, _ := service.ListAddresses(ctx, owner); len(addresses) != 0 {
+		t.Errorf("expected an empty address book, got %d addresses", len(addresses))
+	}
+}
+
+func TestAddressService_CreateAddressValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*AddressInput)
+	}{
+		{"missing street", func(in *AddressInput) { in.AddressLine1 = " " }},
+		{"missing city", func(in *AddressInput) { in.City = "" }},
+		{"missing postal code", func(in *AddressInput) { in.PostalCode = "" }},
+		{"long country", func(in *AddressInput) { in.Country = "ITA" }},
+		{"latitude out of range", func(in *AddressInput) { in.Latitude = 91 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := testAddressInput()
+			tt.modify(&input)
+
+			_, err := NewAddressService(newMockAddressRepository()).CreateAddress(context.Background(), uuid.New(), input)
+			if !errors.Is(err, ErrInvalidAddress) {
+				t.Errorf("expected ErrInvalidAddress, got %v", err)
+			}
+		})
+	}
+}
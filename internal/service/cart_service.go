@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidQuantity   = errors.New("quantity must be between 1 and 99")
+	ErrInsufficientStock = errors.New("insufficient stock")
+)
+
+// StockShortage describes a single product whose requested quantity exceeds available stock
+type StockShortage struct {
+	ProductID   uuid.UUID `json:"product_id"`
+	ProductName string    `json:"product_name"`
+	Requested   int       `json:"requested"`
+	Available   int       `json:"available"`
+}
+
+// InsufficientStockError lists every product that cannot be supplied in the requested quantity.
+// It matches ErrInsufficientStock with errors.Is.
+type InsufficientStockError struct {
+	Shortages []StockShortage
+}
+
+func (e *InsufficientStockError) Error() string {
+	names := make([]string, 0, len(e.Shortages))
+	for _, s := range e.Shortages {
+		names = append(names, fmt.Sprintf("%s (requested %d, available %d)", s.ProductName, s.Requested, s.Available))
+	}
+	return fmt.Sprintf("%s: %s", ErrInsufficientStock, strings.Join(names, ", "))
+}
+
+func (e *InsufficientStockError) Is(target error) bool {
+	return target == ErrInsufficientStock
+}
+
+// CartService defines the interface for shopping cart business logic
+type CartService interface {
+	GetCart(ctx context.Context, userID uuid.UUID) (*domain.Cart, error)
+	AddItem(ctx context.Context, userID, productID uuid.UUID, selections []domain.OptionSelection, quantity int) (*domain.Cart, error)
+	UpdateItem(ctx context.Context, userID, itemID uuid.UUID, quantity int) (*domain.Cart, error)
+	RemoveItem(ctx context.Context, userID, itemID uuid.UUID) (*domain.Cart, error)
+	Clear(ctx context.Context, userID uuid.UUID) error
+	ApplyCoupon(ctx context.Context, userID uuid.UUID, code string) (*domain.Cart, error)
+	RemoveCoupon(ctx context.Context, userID uuid.UUID) (*domain.Cart, error)
+	SelectStore(ctx context.Context, userID, storeID uuid.UUID) (*domain.Cart, error)
+}
+
+type cartService struct {
+	cartRepo      repository.CartRepository
+	productRepo   repository.ProductRepository
+	storeRepo     repository.StoreRepository
+	promotionRepo repository.PromotionRepository
+	pricing       PricingEngine
+}
+
+// NewCartService creates a new instance of CartService
+func NewCartService(
+	cartRepo repository.CartRepository,
+	productRepo repository.ProductRepository,
+	storeRepo repository.StoreRepository,
+	promotionRepo repository.PromotionRepository,
+	pricing PricingEngine,
+) CartService {
+	return &cartService{
+		cartRepo:      cartRepo,
+		productRepo:   productRepo,
+		storeRepo:     storeRepo,
+		promotionRepo: promotionRepo,
+		pricing:       pricing,
+	}
+}
+
+// GetCart returns the user's cart priced by the pricing engine at current prices, with the
+// discount of the applied coupon. A coupon that no longer applies stays on the cart without
+// a discount so the customer can see why.
+func (s *cartService) GetCart(ctx context.Context, userID uuid.UUID) (*domain.Cart, error) {
+	lines, err := s.cartRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	if err := s.pricing.PriceCartLines(ctx, lines); err != nil {
+		return nil, err
+	}
+
+	cart := &domain.Cart{
+		UserID:   userID,
+		Items:    lines,
+		Subtotal: domain.Cents(0),
+		Discount: domain.Cents(0),
+	}
+
+	storeID, err := s.cartRepo.FindStore(ctx, userID)
+	switch {
+	case err == repository.ErrCartStoreNotSet:
+	case err != nil:
+		return nil, fmt.Errorf("failed to get cart store: %w", err)
+	default:
+		cart.StoreID = &storeID
+	}
+	for _, line := range lines {
+		cart.Subtotal = cart.Subtotal.Add(line.Subtotal)
+	}
+
+	promotionID, err := s.cartRepo.FindCoupon(ctx, userID)
+	switch {
+	case err == repository.ErrCartCouponNotFound:
+	case err != nil:
+		return nil, fmt.Errorf("failed to get cart coupon: %w", err)
+	default:
+		promotion, err := s.promotionRepo.FindByID(ctx, promotionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cart coupon: %w", err)
+		}
+
+		discount, err := s.evaluateCoupon(ctx, userID, promotion, lines)
+		var couponErr *CouponError
+		if err != nil && !errors.As(err, &couponErr) {
+			return nil, err
+		}
+
+		cart.Discount = discount
+		cart.Coupon = &domain.AppliedCoupon{
+			Code:        promotion.Code,
+			Description: promotion.Description,
+			Discount:    discount,
+			Applicable:  couponErr == nil,
+		}
+		if couponErr != nil {
+			cart.Coupon.Reason = couponErr.Reason
+		}
+	}
+
+	cart.Total = cart.Subtotal.Sub(cart.Discount)
+	return cart, nil
+}
+
+// ApplyCoupon attaches the promotion with the given code to the user's cart, replacing any
+// coupon already applied. The coupon must apply to the cart as it is now.
+func (s *cartService) ApplyCoupon(ctx context.Context, userID uuid.UUID, code string) (*domain.Cart, error) {
+	promotion, err := s.promotionRepo.FindByCode(ctx, normalizeCouponCode(code))
+	if err != nil {
+		if err == repository.ErrPromotionNotFound {
+			return nil, repository.ErrPromotionNotFound
+		}
+		return nil, fmt.Errorf("failed to find promotion: %w", err)
+	}
+
+	lines, err := s.cartRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+	if len(lines) == 0 {
+		return nil, ErrEmptyCart
+	}
+
+	if err := s.pricing.PriceCartLines(ctx, lines); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.evaluateCoupon(ctx, userID, promotion, lines); err != nil {
+		return nil, err
+	}
+
+	if err := s.cartRepo.SetCoupon(ctx, userID, promotion.ID); err != nil {
+		if err == repository.ErrPromotionNotFound {
+			return nil, repository.ErrPromotionNotFound
+		}
+		return nil, fmt.Errorf("failed to apply coupon: %w", err)
+	}
+
+	return s.GetCart(ctx, userID)
+}
+
+// RemoveCoupon detaches the applied coupon from the user's cart
+func (s *cartService) RemoveCoupon(ctx context.Context, userID uuid.UUID) (*domain.Cart, error) {
+	if err := s.cartRepo.RemoveCoupon(ctx, userID); err != nil {
+		if err == repository.ErrCartCouponNotFound {
+			return nil, repository.ErrCartCouponNotFound
+		}
+		return nil, fmt.Errorf("failed to remove coupon: %w", err)
+	}
+
+	return s.GetCart(ctx, userID)
+}
+
+// SelectStore sets the store the cart is filled at. Items already in the cart stay and are
+// priced and stocked at the new store; items it does not sell cannot be checked out there.
+func (s *cartService) SelectStore(ctx context.Context, userID, storeID uuid.UUID) (*domain.Cart, error) {
+	store, err := findStore(ctx, s.storeRepo, storeID)
+	if err != nil {
+		return nil, err
+	}
+	if !store.Active {
+		return nil, ErrStoreInactive
+	}
+
+	if err := s.cartRepo.SetStore(ctx, userID, storeID); err != nil {
+		if err == repository.ErrStoreNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to select cart store: %w", err)
+	}
+
+	return s.GetCart(ctx, userID)
+}
+
+// AddItem adds quantity units of a product with the chosen options to the cart,
+// merging with an existing line that has the same selections. The product must be
+// available at the cart's store and a line may hold at most MaxQuantity units.
+func (s *cartService) AddItem(ctx context.Context, userID, productID uuid.UUID, selections []domain.OptionSelection, quantity int) (*domain.Cart, error) {
+	storeID, err := s.findStore(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Quoting validates the quantity and the selections against the product's option groups
+	selections = normalizeSelections(selections)
+	if _, err := s.pricing.Quote(ctx, &storeID, productID, selections, quantity); err != nil {
+		return nil, err
+	}
+
+	product, err := findProduct(ctx, s.productRepo, &storeID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.cartRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+	for _, line := range lines {
+		if line.ProductID == productID && sameSelections(line.Selections, selections) {
+			quantity += line.Quantity
+		}
+	}
+	if quantity > MaxQuantity {
+		return nil, ErrInvalidQuantity
+	}
+
+	return s.saveItem(ctx, userID, product, selections, quantity, lines)
+}
+
+// UpdateItem sets the quantity of an item that is already in the cart
+func (s *cartService) UpdateItem(ctx context.Context, userID, itemID uuid.UUID, quantity int) (*domain.Cart, error) {
+	if quantity < 1 || quantity > MaxQuantity {
+		return nil, ErrInvalidQuantity
+	}
+
+	item, err := s.cartRepo.FindItem(ctx, userID, itemID)
+	if err != nil {
+		if err == repository.ErrCartItemNotFound {
+			return nil, repository.ErrCartItemNotFound
+		}
+		return nil, fmt.Errorf("failed to find cart item: %w", err)
+	}
+
+	storeID, err := s.findStore(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := findProduct(ctx, s.productRepo, &storeID, item.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.cartRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	return s.saveItem(ctx, userID, product, item.Selections, quantity, lines)
+}
+
+// RemoveItem removes an item from the cart
+func (s *cartService) RemoveItem(ctx context.Context, userID, itemID uuid.UUID) (*domain.Cart, error) {
+	if err := s.cartRepo.Delete(ctx, userID, itemID); err != nil {
+		if err == repository.ErrCartItemNotFound {
+			return nil, repository.ErrCartItemNotFound
+		}
+		return nil, fmt.Errorf("failed to remove cart item: %w", err)
+	}
+
+	return s.GetCart(ctx, userID)
+}
+
+// Clear empties the user's cart and removes the applied coupon
+func (s *cartService) Clear(ctx context.Context, userID uuid.UUID) error {
+	if err := s.cartRepo.Clear(ctx, userID); err != nil {
+		return fmt.Errorf("failed to clear cart: %w", err)
+	}
+	return nil
+}
+
+// evaluateCoupon returns the discount promotion grants the user on the priced lines
+func (s *cartService) evaluateCoupon(ctx context.Context, userID uuid.UUID, promotion *domain.Promotion, lines []*domain.CartLine) (domain.Money, error) {
+	redemptions, err := s.promotionRepo.CountUserRedemptions(ctx, promotion.ID, userID)
+	if err != nil {
+		return domain.Money{}, fmt.Errorf("failed to count coupon redemptions: %w", err)
+	}
+
+	return evaluateCoupon(promotion, lines, redemptions, time.Now())
+}
+
+// findStore returns the store selected for the user's cart or ErrStoreNotSelected
+func (s *cartService) findStore(ctx context.Context, userID uuid.UUID) (uuid.UUID, error) {
+	storeID, err := s.cartRepo.FindStore(ctx, userID)
+	if err != nil {
+		if err == repository.ErrCartStoreNotSet {
+			return uuid.Nil, ErrStoreNotSelected
+		}
+		return uuid.Nil, fmt.Errorf("failed to get cart store: %w", err)
+	}
+	return storeID, nil
+}
+
+// saveItem stores the line for product with the given selections and quantity. product
+// carries the stock of the cart's store, which is shared by every line of the product, so
+// the quantities of the user's other lines of the same product count towards the stock check.
+func (s *cartService) saveItem(ctx context.Context, userID uuid.UUID, product *domain.Product, selections []domain.OptionSelection, quantity int, lines []*domain.CartLine) (*domain.Cart, error) {
+	requested := quantity
+	for _, line := range lines {
+		if line.ProductID == product.ID && !sameSelections(line.Selections, selections) {
+			requested += line.Quantity
+		}
+	}
+
+	if requested > product.Stock {
+		return nil, &InsufficientStockError{
+			Shortages: []StockShortage{{
+				ProductID:   product.ID,
+				ProductName: product.Name,
+				Requested:   requested,
+				Available:   product.Stock,
+			}},
+		}
+	}
+
+	now := time.Now()
+	item := &domain.CartItem{
+		ID:         uuid.New(),
+		UserID:     userID,
+		ProductID:  product.ID,
+		Selections: selections,
+		Quantity:   quantity,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := s.cartRepo.Upsert(ctx, item); err != nil {
+		if err == repository.ErrProductNotFound {
+			return nil, repository.ErrProductNotFound
+		}
+		return nil, fmt.Errorf("failed to save cart item: %w", err)
+	}
+
+	return s.GetCart(ctx, userID)
+}
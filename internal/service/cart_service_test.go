@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+type mockCartRepository struct {
+	items       map[uuid.UUID]map[uuid.UUID]*domain.CartItem
+	coupons     map[uuid.UUID]uuid.UUID
+	stores      map[uuid.UUID]uuid.UUID
+	productRepo *mockProductRepository
+	// checkoutStore is the store the last checkout locked the cart at
+	checkoutStore uuid.UUID
+}
+
+func newMockCartRepository(productRepo *mockProductRepository) *mockCartRepository {
+	return &mockCartRepository{
+		items:       make(map[uuid.UUID]map[uuid.UUID]*domain.CartItem),
+		coupons:     make(map[uuid.UUID]uuid.UUID),
+		stores:      make(map[uuid.UUID]uuid.UUID),
+		productRepo: productRepo,
+	}
+}
+
+// quantityOf sums the quantities of every line of a product in the user's cart
+func (m *mockCartRepository) quantityOf(userID, productID uuid.UUID) int {
+	total := 0
+	for _, item := range m.items[userID] {
+		if item.ProductID == productID {
+			total += item.Quantity
+		}
+	}
+	return total
+}
+
+func (m *mockCartRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.CartLine, error) {
+	lines := []*domain.CartLine{}
+	for _, item := range m.items[userID] {
+		product, err := m.productRepo.FindByID(ctx, item.ProductID)
+		if err != nil {
+			continue
+		}
+		lines = append(lines, &domain.CartLine{
+			CartItem:    *item,
+			ProductName: product.Name,
+			CategoryID:  product.CategoryID,
+			BasePrice:   product.Price,
+			Stock:       product.Stock,
+		})
+	}
+	return lines, nil
+}
+
+func (m *mockCartRepository) FindItem(ctx context.Context, userID, itemID uuid.UUID) (*domain.CartItem, error) {
+	item, exists := m.items[userID][itemID]
+	if !exists {
+		return nil, repository.ErrCartItemNotFound
+	}
+	return item, nil
+}
+
+func (m *mockCartRepository) Upsert(ctx context.Context, item *domain.CartItem) error {
+	if _, err := m.productRepo.FindByID(ctx, item.ProductID); err != nil {
+		return repository.ErrProductNotFound
+	}
+	if m.items[item.UserID] == nil {
+		m.items[item.UserID] = make(map[uuid.UUID]*domain.CartItem)
+	}
+	for _, existing := range m.items[item.UserID] {
+		if existing.ProductID == item.ProductID && sameSelections(existing.Selections, item.Selections) {
+			existing.Quantity = item.Quantity
+			existing.UpdatedAt = item.UpdatedAt
+			item.ID = existing.ID
+			item.CreatedAt = existing.CreatedAt
+			return nil
+		}
+	}
+	m.items[item.UserID][item.ID] = item
+	return nil
+}
+
+func (m *mockCartRepository) Delete(ctx context.Context, userID, itemID uuid.UUID) error {
+	if _, exists := m.items[userID][itemID]; !exists {
+		return repository.ErrCartItemNotFound
+	}
+	delete(m.items[userID], itemID)
+	return nil
+}
+
+func (m *mockCartRepository) Clear(ctx context.Context, userID uuid.UUID) error {
+	delete(m.items, userID)
+	delete(m.coupons, userID)
+	return nil
+}
+
+func (m *mockCartRepository) LockForCheckoutTx(ctx context.Context, tx *sql.Tx, userID, storeID uuid.UUID) ([]*domain.CartLine, error) {
+	m.checkoutStore = storeID
+	return m.ListByUser(ctx, userID)
+}
+
+func (m *mockCartRepository) ClearTx(ctx context.Context, tx *sql.Tx, userID uuid.UUID) error {
+	return m.Clear(ctx, userID)
+}
+
+func (m *mockCartRepository) DeleteByProductTx(ctx context.Context, tx *sql.Tx, productID uuid.UUID) error {
+	for _, items := range m.items {
+		for id, item := range items {
+			if item.ProductID == productID {
+				delete(items, id)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *mockCartRepository) FindCoupon(ctx context.Context, userID uuid.UUID) (uuid.UUID, error) {
+	promotionID, exists := m.coupons[userID]
+	if !exists {
+		return uuid.Nil, repository.ErrCartCouponNotFound
+	}
+	return promotionID, nil
+}
+
+func (m *mockCartRepository) FindCouponTx(ctx context.Context, tx *sql.Tx, userID uuid.UUID) (uuid.UUID, error) {
+	return m.FindCoupon(ctx, userID)
+}
+
+func (m *mockCartRepository) SetCoupon(ctx context.Context, userID, promotionID uuid.UUID) error {
+	m.coupons[userID] = promotionID
+	return nil
+}
+
+func (m *mockCartRepository) RemoveCoupon(ctx context.Context, userID uuid.UUID) error {
+	if _, exists := m.coupons[userID]; !exists {
+		return repository.ErrCartCouponNotFound
+	}
+	delete(m.coupons, userID)
+	return nil
+}
+
+// FindStore returns the store a user selected, or the test store when none was selected
+func (m *mockCartRepository) FindStore(ctx context.Context, userID uuid.UUID) (uuid.UUID, error) {
+	if storeID, exists := m.stores[userID]; exists {
+		return storeID, nil
+	}
+	return testStoreID, nil
+}
+
+func (m *mockCartRepository) FindStoreTx(ctx context.Context, tx *sql.Tx, userID uuid.UUID) (uuid.UUID, error) {
+	return m.FindStore(ctx, userID)
+}
+
+func (m *mockCartRepository) SetStore(ctx context.Context, userID, storeID uuid.UUID) error {
+	m.stores[userID] = storeID
+	return nil
+}
+
+// Feature: ordering-platform, Property 72: Cart subtotal equals the sum of its lines
+func TestProperty_CartSubtotalEqualsSumOfLines(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("cart subtotal is the sum of price times quantity for every line", prop.ForAll(
+		func(prices []int64, quantities []int) bool {
+			productRepo := newMockProductRepository()
+			cartRepo := newMockCartRepository(productRepo)
+			service := NewCartService(cartRepo, productRepo, newMockStoreRepository(), nil, newTestPricingEngine(productRepo))
+			ctx := context.Background()
+			userID := uuid.New()
+
+			var expected int64
+			for i, price := range prices {
+				quantity := quantities[i%len(quantities)]
+				product := &domain.Product{ID: uuid.New(), Name: "Pizza", Price: domain.Cents(price), Stock: 1000, CreatedAt: time.Now()}
+				_ = productRepo.Create(ctx, product)
+
+				if _, err := service.AddItem(ctx, userID, product.ID, nil, quantity); err != nil {
+					t.Logf("FAIL: AddItem failed: %v", err)
+					return false
+				}
+				expected += price * int64(quantity)
+			}
+
+			cart, err := service.GetCart(ctx, userID)
+			if err != nil {
+				t.Logf("FAIL: GetCart failed: %v", err)
+				return false
+			}
+
+			if len(cart.Items) != len(prices) {
+				t.Logf("FAIL: Expected %d lines, got %d", len(prices), len(cart.Items))
+				return false
+			}
+
+			if cart.Subtotal != domain.Cents(expected) {
+				t.Logf("FAIL: Subtotal mismatch. Expected %s, got %s", domain.Cents(expected), cart.Subtotal)
+				return false
+			}
+
+			return true
+		},
+		gen.SliceOfN(5, gen.Int64Range(50, 5000)),
+		gen.SliceOfN(5, gen.IntRange(1, 10)),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// Feature: ordering-platform, Property 73: Cart quantities never exceed product stock
+func TestProperty_CartQuantitiesNeverExceedStock(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("adding more than the available stock is rejected", prop.ForAll(
+		func(stock int, first int, second int) bool {
+			productRepo := newMockProductRepository()
+			cartRepo := newMockCartRepository(productRepo)
+			service := NewCartService(cartRepo, productRepo, newMockStoreRepository(), nil, newTestPricingEngine(productRepo))
+			ctx := context.Background()
+			userID := uuid.New()
+
+			product := &domain.Product{ID: uuid.New(), Name: "Calzone", Price: domain.Cents(800), Stock: stock}
+			_ = productRepo.Create(ctx, product)
+
+			_, err := service.AddItem(ctx, userID, product.ID, nil, first)
+			if first > stock {
+				return errors.Is(err, ErrInsufficientStock)
+			}
+			if err != nil {
+				t.Logf("FAIL: First AddItem failed: %v", err)
+				return false
+			}
+
+			// Adding merges with the existing line, so the combined quantity is checked
+			_, err = service.AddItem(ctx, userID, product.ID, nil, second)
+			if first+second > stock {
+				var stockErr *InsufficientStockError
+				if !errors.As(err, &stockErr) || stockErr.Shortages[0].Available != stock {
+					t.Logf("FAIL: Expected InsufficientStockError, got %v", err)
+					return false
+				}
+				return cartRepo.quantityOf(userID, product.ID) == first
+			}
+
+			return err == nil && cartRepo.quantityOf(userID, product.ID) == first+second
+		},
+		gen.IntRange(0, 20),
+		gen.IntRange(1, 15),
+		gen.IntRange(1, 15),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestCartService_ItemErrors(t *testing.T) {
+	productRepo := newMockProductRepository()
+	service := NewCartService(newMockCartRepository(productRepo), productRepo, newMockStoreRepository(), nil, newTestPricingEngine(productRepo))
+	ctx := context.Background()
+	userID := uuid.New()
+
+	if _, err := service.AddItem(ctx, userID, uuid.New(), nil, 1); err != repository.ErrProductNotFound {
+		t.Errorf("Expected ErrProductNotFound for unknown product, got %v", err)
+	}
+
+	for _, quantity := range []int{0, MaxQuantity + 1} {
+		if _, err := service.AddItem(ctx, userID, uuid.New(), nil, quantity); err != ErrInvalidQuantity {
+			t.Errorf("Expected ErrInvalidQuantity for quantity %d, got %v", quantity, err)
+		}
+	}
+	if _, err := service.UpdateItem(ctx, userID, uuid.New(), MaxQuantity+1); err != ErrInvalidQuantity {
+		t.Errorf("Expected ErrInvalidQuantity on update, got %v", err)
+	}
+
+	// Adding to an existing line cannot take it past the limit either
+	product := &domain.Product{ID: uuid.New(), Name: "Calzone", Price: domain.Cents(1100), Stock: 500}
+	_ = productRepo.Create(ctx, product)
+	if _, err := service.AddItem(ctx, userID, product.ID, nil, MaxQuantity); err != nil {
+		t.Fatalf("AddItem failed: %v", err)
+	}
+	if _, err := service.AddItem(ctx, userID, product.ID, nil, 1); err != ErrInvalidQuantity {
+		t.Errorf("Expected ErrInvalidQuantity when merging past the limit, got %v", err)
+	}
+
+	if _, err := service.UpdateItem(ctx, userID, uuid.New(), 2); err != repository.ErrCartItemNotFound {
+		t.Errorf("Expected ErrCartItemNotFound on update, got %v", err)
+	}
+
+	if _, err := service.RemoveItem(ctx, userID, uuid.New()); err != repository.ErrCartItemNotFound {
+		t.Errorf("Expected ErrCartItemNotFound on remove, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// CategoryService defines the interface for category business logic
+type CategoryService interface {
+	ListCategories(ctx context.Context) ([]*domain.Category, error)
+	GetCategory(ctx context.Context, id uuid.UUID) (*domain.Category, error)
+	CreateCategory(ctx context.Context, name, description string) (*domain.Category, error)
+	UpdateCategory(ctx context.Context, id uuid.UUID, name, description string) (*domain.Category, error)
+	DeleteCategory(ctx context.Context, id uuid.UUID) error
+}
+
+type categoryService struct {
+	categoryRepo repository.CategoryRepository
+}
+
+// NewCategoryService creates a new instance of CategoryService
+func NewCategoryService(categoryRepo repository.CategoryRepository) CategoryService {
+	return &categoryService{
+		categoryRepo: categoryRepo,
+	}
+}
+
+// ListCategories returns all categories ordered by name
+func (s *categoryService) ListCategories(ctx context.Context) ([]*domain.Category, error) {
+	categories, err := s.categoryRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+	return categories, nil
+}
+
+// GetCategory retrieves a single category by ID
+func (s *categoryService) GetCategory(ctx context.Context, id uuid.UUID) (*domain.Category, error) {
+	category, err := s.categoryRepo.FindByID(ctx, id)
+	if err != nil {
+		if err == repository.ErrCategoryNotFound {
+			return nil, repository.ErrCategoryNotFound
+		}
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+	return category, nil
+}
+
+// CreateCategory adds a new category with a unique name
+func (s *categoryService) CreateCategory(ctx context.Context, name, description string) (*domain.Category, error) {
+	category := &domain.Category{
+		ID:          uuid.New(),
+		Name:        name,
+		Description: description,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := s.categoryRepo.Create(ctx, category); err != nil {
+		if err == repository.ErrCategoryAlreadyExists {
+			return nil, repository.ErrCategoryAlreadyExists
+		}
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+
+	return category, nil
+}
+
+// UpdateCategory renames or re-describes an existing category
+func (s *categoryService) UpdateCategory(ctx context.Context, id uuid.UUID, name, description string) (*domain.Category, error) {
+	category, err := s.GetCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	category.Name = name
+	category.Description = description
+
+	if err := s.categoryRepo.Update(ctx, category); err != nil {
+		if err == repository.ErrCategoryNotFound || err == repository.ErrCategoryAlreadyExists {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update category: %w", err)
+	}
+
+	return category, nil
+}
+
+// DeleteCategory removes a category that no longer has any products
+func (s *categoryService) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	if err := s.categoryRepo.Delete(ctx, id); err != nil {
+		if err == repository.ErrCategoryNotFound || err == repository.ErrCategoryHasProducts {
+			return err
+		}
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+	return nil
+}
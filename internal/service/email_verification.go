@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/mailer"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidVerificationToken = errors.New("invalid or expired email verification token")
+	ErrEmailAlreadyVerified     = errors.New("email address is already verified")
+	ErrVerificationThrottled    = errors.New("verification email was sent recently")
+	ErrEmailNotVerified         = errors.New("email address is not verified")
+)
+
+// VerificationThrottledError reports that a verification email was requested too soon after
+// the previous one. It matches ErrVerificationThrottled with errors.Is.
+type VerificationThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *VerificationThrottledError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", ErrVerificationThrottled, e.RetryAfter.Round(time.Second))
+}
+
+func (e *VerificationThrottledError) Is(target error) bool {
+	return target == ErrVerificationThrottled
+}
+
+// EmailVerificationConfig controls verification emails and what unverified users may do
+type EmailVerificationConfig struct {
+	// TokenTTL is how long a verification link stays usable
+	TokenTTL time.Duration
+	// VerifyURL is the address of the verification endpoint; the token is added to it as
+	// the token query parameter
+	VerifyURL string
+	// ResendCooldown is the minimum time between two verification emails to the same user
+	ResendCooldown time.Duration
+	// RequiredForCheckout blocks unverified users from placing orders
+	RequiredForCheckout bool
+}
+
+// EmailVerificationService defines the interface for verifying users' email addresses
+type EmailVerificationService interface {
+	SendVerification(ctx context.Context, user *domain.User) error
+	ResendVerification(ctx context.Context, userID uuid.UUID) error
+	VerifyEmail(ctx context.Context, token string) error
+	CheckCanCheckout(ctx context.Context, userID uuid.UUID) error
+}
+
+type emailVerificationService struct {
+	userRepo  repository.UserRepository
+	tokenRepo repository.EmailVerificationTokenRepository
+	mailer    mailer.Mailer
+	config    EmailVerificationConfig
+}
+
+// NewEmailVerificationService creates a new instance of EmailVerificationService
+func NewEmailVerificationService(
+	userRepo repository.UserRepository,
+	tokenRepo repository.EmailVerificationTokenRepository,
+	mailer mailer.Mailer,
+	config EmailVerificationConfig,
+) EmailVerificationService {
+	return &emailVerificationService{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		mailer:    mailer,
+		config:    config,
+	}
+}
+
+// SendVerification mails a single-use verification link to a newly registered user
+func (s *emailVerificationService) SendVerification(ctx context.Context, user *domain.User) error {
+	if user.IsEmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+
+	tokenString, err := randomToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+	link, err := linkWithToken(s.config.VerifyURL, tokenString)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	token := &domain.EmailVerificationToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		TokenHash: hashToken(tokenString),
+		ExpiresAt: now.Add(s.config.TokenTTL),
+		CreatedAt: now,
+	}
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return fmt.Errorf("failed to store verification token: %w", err)
+	}
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nOpen the link below to verify your email address. It expires in %s.\n\n%s\n",
+			user.FirstName, s.config.TokenTTL, link,
+		),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	return nil
+}
+
+// ResendVerification mails a new verification link to an unverified user. A link is sent at
+// most once per resend cooldown; earlier links keep working until they expire.
+func (s *emailVerificationService) ResendVerification(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			return repository.ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.IsEmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+
+	latest, err := s.tokenRepo.FindLatestForUser(ctx, userID)
+	if err != nil && err != repository.ErrEmailVerificationTokenNotFound {
+		return fmt.Errorf("failed to check previous verification email: %w", err)
+	}
+	if latest != nil {
+		if wait := s.config.ResendCooldown - time.Since(latest.CreatedAt); wait > 0 {
+			return &VerificationThrottledError{RetryAfter: wait}
+		}
+	}
+
+	return s.SendVerification(ctx, user)
+}
+
+// VerifyEmail redeems a verification token and marks the user's email address as verified
+func (s *emailVerificationService) VerifyEmail(ctx context.Context, token string) error {
+	now := time.Now()
+	verificationToken, err := s.tokenRepo.Consume(ctx, hashToken(token), now)
+	if err != nil {
+		if err == repository.ErrEmailVerificationTokenNotFound {
+			return ErrInvalidVerificationToken
+		}
+		return fmt.Errorf("failed to redeem verification token: %w", err)
+	}
+
+	if err := s.userRepo.MarkEmailVerified(ctx, verificationToken.UserID, now); err != nil {
+		if err == repository.ErrUserNotFound {
+			return ErrInvalidVerificationToken
+		}
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	if err := s.tokenRepo.InvalidateForUser(ctx, verificationToken.UserID, now); err != nil {
+		return fmt.Errorf("failed to invalidate verification tokens: %w", err)
+	}
+
+	return nil
+}
+
+// CheckCanCheckout returns ErrEmailNotVerified when checkout requires a verified email
+// address and the user has not verified theirs
+func (s *emailVerificationService) CheckCanCheckout(ctx context.Context, userID uuid.UUID) error {
+	if !s.config.RequiredForCheckout {
+		return nil
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			return repository.ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.IsEmailVerified() {
+		return ErrEmailNotVerified
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+type mockEmailVerificationTokenRepository struct {
+	tokens map[string]*domain.EmailVerificationToken
+}
+
+func newMockEmailVerificationTokenRepository() *mockEmailVerificationTokenRepository {
+	return &mockEmailVerificationTokenRepository{
+		tokens: make(map[string]*domain.EmailVerificationToken),
+	}
+}
+
+func (m *mockEmailVerificationTokenRepository) Create(ctx context.Context, token *domain.EmailVerificationToken) error {
+	m.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (m *mockEmailVerificationTokenRepository) Consume(ctx context.Context, tokenHash string, now time.Time) (*domain.EmailVerificationToken, error) {
+	token, exists := m.tokens[tokenHash]
+	if !exists || token.UsedAt != nil || !token.ExpiresAt.After(now) {
+		return nil, repository.ErrEmailVerificationTokenNotFound
+	}
+	token.UsedAt = &now
+	return token, nil
+}
+
+func (m *mockEmailVerificationTokenRepository) FindLatestForUser(ctx context.Context, userID uuid.UUID) (*domain.EmailVerificationToken, error) {
+	var latest *domain.EmailVerificationToken
+	for _, token := range m.tokens {
+		if token.UserID == userID && (latest == nil || token.CreatedAt.After(latest.CreatedAt)) {
+			latest = token
+		}
+	}
+	if latest == nil {
+		return nil, repository.ErrEmailVerificationTokenNotFound
+	}
+	return latest, nil
+}
+
+func (m *mockEmailVerificationTokenRepository) InvalidateForUser(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	for _, token := range m.tokens {
+		if token.UserID == userID && token.UsedAt == nil {
+			token.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+type emailVerificationFixture struct {
+	users        UserService
+	verification EmailVerificationService
+	tokens       *mockEmailVerificationTokenRepository
+	mail         *recordingMailer
+}
+
+func newEmailVerificationFixture(config EmailVerificationConfig) *emailVerificationFixture {
+	userRepo := newMockUserRepository()
+	f := &emailVerificationFixture{
+		tokens: newMockEmailVerificationTokenRepository(),
+		mail:   &recordingMailer{},
+	}
+	f.users = NewUserService(&mockTransactor{}, userRepo, newMockRoleRepository(), newMockRefreshTokenRepository(), newTestTokenManager(), DefaultTokenPolicy(), newTestLoginThrottle())
+	config.VerifyURL = "https://pizza.example/api/users/verify"
+	f.verification = NewEmailVerificationService(userRepo, f.tokens, f.mail, config)
+	return f
+}
+
+// register creates an unverified user and sends the registration verification email
+func (f *emailVerificationFixture) register(t *testing.T, email string) *domain.User {
+	t.Helper()
+	user, err := f.users.Register(context.Background(), email, "password123", "Test", "User")
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := f.verification.SendVerification(context.Background(), user); err != nil {
+		t.Fatalf("SendVerification failed: %v", err)
+	}
+	return user
+}
+
+// Feature: ordering-platform, Property 87: Verification links verify only their own account, once
+// Validates: Requirements 1.1
+func TestProperty_VerificationLinksVerifyTheirOwnAccount(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("redeeming a verification link verifies exactly its account", prop.ForAll(
+		func(accounts int, verified int) bool {
+			f := newEmailVerificationFixture(EmailVerificationConfig{TokenTTL: time.Hour, RequiredForCheckout: true})
+			ctx := context.Background()
+
+			users := make([]*domain.User, accounts)
+			for i := range users {
+				users[i] = f.register(t, uuid.NewString()+"@example.com")
+			}
+			target := verified % accounts
+			token := tokenFromMail(t, f.mail.sent[target])
+
+			if err := f.verification.VerifyEmail(ctx, token); err != nil {
+				t.Logf("FAIL: VerifyEmail failed: %v", err)
+				return false
+			}
+			if err := f.verification.VerifyEmail(ctx, token); err != ErrInvalidVerificationToken {
+				t.Logf("FAIL: Reused verification token should be rejected, got: %v", err)
+				return false
+			}
+
+			for i, user := range users {
+				stored, err := f.users.GetUserByID(ctx, user.ID)
+				if err != nil {
+					t.Logf("FAIL: GetUserByID failed: %v", err)
+					return false
+				}
+				if stored.IsEmailVerified() != (i == target) {
+					t.Logf("FAIL: Account %d verified = %v", i, stored.IsEmailVerified())
+					return false
+				}
+
+				err = f.verification.CheckCanCheckout(ctx, user.ID)
+				if i == target && err != nil {
+					t.Logf("FAIL: Verified user should be able to checkout: %v", err)
+					return false
+				}
+				if i != target && err != ErrEmailNotVerified {
+					t.Logf("FAIL: Unverified user should not be able to checkout, got: %v", err)
+					return false
+				}
+			}
+
+			return true
+		},
+		gen.IntRange(1, 4),
+		gen.IntRange(0, 10),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestResendVerification_IsThrottled(t *testing.T) {
+	f := newEmailVerificationFixture(EmailVerificationConfig{TokenTTL: time.Hour, ResendCooldown: time.Minute})
+	ctx := context.Background()
+	user := f.register(t, "resend@example.com")
+
+	var throttled *VerificationThrottledError
+	err := f.verification.ResendVerification(ctx, user.ID)
+	if !errors.As(err, &throttled) || !errors.Is(err, ErrVerificationThrottled) {
+		t.Fatalf("expected VerificationThrottledError, got %v", err)
+	}
+	if throttled.RetryAfter <= 0 || throttled.RetryAfter > time.Minute {
+		t.Errorf("RetryAfter = %s, want within the cooldown", throttled.RetryAfter)
+	}
+
+	// Once the cooldown has passed a new link is sent and both links work
+	for _, token := range f.tokens.tokens {
+		token.CreatedAt = token.CreatedAt.Add(-2 * time.Minute)
+	}
+	if err := f.verification.ResendVerification(ctx, user.ID); err != nil {
+		t.Fatalf("ResendVerification failed: %v", err)
+	}
+	if len(f.mail.sent) != 2 {
+		t.Fatalf("expected 2 verification emails, got %d", len(f.mail.sent))
+	}
+	if err := f.verification.VerifyEmail(ctx, tokenFromMail(t, f.mail.sent[0])); err != nil {
+		t.Fatalf("VerifyEmail with the first link failed: %v", err)
+	}
+
+	if err := f.verification.ResendVerification(ctx, user.ID); err != ErrEmailAlreadyVerified {
+		t.Errorf("expected ErrEmailAlreadyVerified, got %v", err)
+	}
+}
+
+func TestCheckCanCheckout_HonorsPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		required bool
+		want     error
+	}{
+		{"verification required", true, ErrEmailNotVerified},
+		{"verification not required", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newEmailVerificationFixture(EmailVerificationConfig{TokenTTL: time.Hour, RequiredForCheckout: tt.required})
+			user := f.register(t, "checkout@example.com")
+
+			if err := f.verification.CheckCanCheckout(context.Background(), user.ID); err != tt.want {
+				t.Errorf("CheckCanCheckout error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyEmail_RejectsExpiredToken(t *testing.T) {
+	f := newEmailVerificationFixture(EmailVerificationConfig{TokenTTL: time.Hour})
+	f.register(t, "expired@example.com")
+
+	token := tokenFromMail(t, f.mail.sent[0])
+	f.tokens.tokens[hashToken(token)].ExpiresAt = time.Now().Add(-time.Minute)
+
+	if err := f.verification.VerifyEmail(context.Background(), token); err != ErrInvalidVerificationToken {
+		t.Errorf("expected ErrInvalidVerificationToken, got %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidKitchenStatus     = errors.New("invalid kitchen status")
+	ErrIllegalKitchenTransition = errors.New("illegal kitchen status change")
+	ErrOrderNotInKitchen        = errors.New("order is not in the kitchen queue")
+)
+
+// KitchenService defines the interface for the kitchen's view of orders
+type KitchenService interface {
+	Queue(ctx context.Context, access StoreAccess) ([]*domain.KitchenQueue, error)
+	SetItemStatus(ctx context.Context, orderID, itemID, actorID uuid.UUID, access StoreAccess, status string) (*domain.Order, error)
+	Subscribe(access StoreAccess) (<-chan *domain.OrderEvent, func())
+}
+
+type kitchenService struct {
+	transactor repository.Transactor
+	orderRepo  repository.OrderRepository
+	events     *OrderEventBroker
+}
+
+// NewKitchenService creates a new instance of KitchenService
+func NewKitchenService(transactor repository.Transactor, orderRepo repository.OrderRepository, events *OrderEventBroker) KitchenService {
+	return &kitchenService{
+		transactor: transactor,
+		orderRepo:  orderRepo,
+		events:     events,
+	}
+}
+
+// Queue returns the confirmed and preparing orders of the stores where access may operate
+// the kitchen, grouped by store. Orders are oldest first and stores are ordered by their
+// oldest order.
+func (s *kitchenService) Queue(ctx context.Context, access StoreAccess) ([]*domain.KitchenQueue, error) {
+	orders, err := s.orderRepo.ListKitchenQueue(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list kitchen queue: %w", err)
+	}
+
+	queues := []*domain.KitchenQueue{}
+	byStore := make(map[uuid.UUID]*domain.KitchenQueue)
+	for _, order := range orders {
+		if !hasStorePermission(access, order.StoreID, domain.PermissionKitchenOperate) {
+			continue
+		}
+		queue, ok := byStore[order.StoreID]
+		if !ok {
+			queue = &domain.KitchenQueue{StoreID: order.StoreID, Orders: []*domain.Order{}}
+			byStore[order.StoreID] = queue
+			queues = append(queues, queue)
+		}
+		queue.Orders = append(queue.Orders, order)
+	}
+
+	return queues, nil
+}
+
+// SetItemStatus moves a product line of a queued order to preparing or ready on behalf of
+// actorID, who must operate the kitchen at the order's store; orders of other stores are
+// reported as not found. Starting the order's first line moves the order to preparing and
+// finishing its last line moves it to ready, both recorded in the status history.
+func (s *kitchenService) SetItemStatus(ctx context.Context, orderID, itemID, actorID uuid.UUID, access StoreAccess, status string) (*domain.Order, error) {
+	if !domain.IsValidKitchenItemStatus(status) {
+		return nil, ErrInvalidKitchenStatus
+	}
+
+	var order *domain.Order
+
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		order, err = s.orderRepo.FindByIDForUpdateTx(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+
+		if !hasStorePermission(access, order.StoreID, domain.PermissionKitchenOperate) {
+			return repository.ErrOrderNotFound
+		}
+		if !domain.IsInKitchenQueue(order.Status) {
+			return ErrOrderNotInKitchen
+		}
+
+		item := findProductItem(order, itemID)
+		if item == nil {
+			return repository.ErrOrderItemNotFound
+		}
+		if !domain.CanAdvanceKitchenItem(item.KitchenStatus, status) {
+			return fmt.Errorf("%w: %s -> %s", ErrIllegalKitchenTransition, item.KitchenStatus, status)
+		}
+
+		item.KitchenStatus = status
+		if err := s.orderRepo.UpdateItemKitchenStatusTx(ctx, tx, item); err != nil {
+			return err
+		}
+
+		var next []string
+		progress := order.KitchenProgress()
+		if order.Status == domain.OrderStatusConfirmed && progress != domain.OrderStatusConfirmed {
+			next = append(next, domain.OrderStatusPreparing)
+		}
+		if progress == domain.OrderStatusReady {
+			next = append(next, domain.OrderStatusReady)
+		}
+
+		for _, to := range next {
+			from := order.Status
+			order.Status = to
+			order.UpdatedAt = time.Now()
+			if err := s.orderRepo.UpdateStatusTx(ctx, tx, order); err != nil {
+				return err
+			}
+			if err := s.orderRepo.AddStatusChangeTx(ctx, tx, newOrderStatusChange(order, from, actorID, "")); err != nil {
+				return fmt.Errorf("failed to record order status: %w", err)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.events.Publish(&domain.OrderEvent{Type: domain.OrderEventUpdated, Order: order})
+	return order, nil
+}
+
+// Subscribe streams the new and changed orders of the stores where access may operate the
+// kitchen. Access is not looked up again, so callers end the subscription once the
+// credentials it came from expire. The returned function ends the subscription; see
+// OrderEventBroker.Subscribe.
+func (s *kitchenService) Subscribe(access StoreAccess) (<-chan *domain.OrderEvent, func()) {
+	return s.events.Subscribe(func(event *domain.OrderEvent) bool {
+		return hasStorePermission(access, event.Order.StoreID, domain.PermissionKitchenOperate)
+	})
+}
+
+// findProductItem returns the product line of order with the given ID, or nil
+func findProductItem(order *domain.Order, itemID uuid.UUID) *domain.OrderItem {
+	for _, item := range order.Items {
+		if item.ID == itemID && item.Type == domain.OrderItemProduct {
+			return item
+		}
+	}
+	return nil
+}
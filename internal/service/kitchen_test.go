@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pizza-must/internal/auth"
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// kitchenAccess operates the kitchen at the given stores only
+func kitchenAccess(storeIDs ...uuid.UUID) auth.Access {
+	access := auth.Access{StorePermissions: map[uuid.UUID][]string{}}
+	for _, storeID := range storeIDs {
+		access.StorePermissions[storeID] = []string{domain.PermissionKitchenOperate}
+	}
+	return access
+}
+
+// newKitchenTestOrder adds an order with the given number of queued product lines and a
+// delivery fee line to orderRepo
+func newKitchenTestOrder(orderRepo *mockOrderRepository, storeID uuid.UUID, status string, lines int, createdAt time.Time) *domain.Order {
+	order := &domain.Order{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		StoreID:   storeID,
+		Status:    status,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+	for i := 0; i < lines; i++ {
+		order.Items = append(order.Items, &domain.OrderItem{
+			ID:            uuid.New(),
+			OrderID:       order.ID,
+			Type:          domain.OrderItemProduct,
+			ProductID:     uuid.New(),
+			Quantity:      1,
+			KitchenStatus: domain.KitchenItemQueued,
+		})
+	}
+	order.Items = append(order.Items, &domain.OrderItem{ID: uuid.New(), OrderID: order.ID, Type: domain.OrderItemDeliveryFee, Quantity: 1})
+	orderRepo.orders[order.ID] = order
+	return order
+}
+
+func TestKitchenService_QueueGroupsOrdersByStore(t *testing.T) {
+	orderRepo := newMockOrderRepository()
+	kitchen := NewKitchenService(&mockTransactor{}, orderRepo, NewOrderEventBroker())
+	downtown, harbour, uptown := uuid.New(), uuid.New(), uuid.New()
+	base := time.Date(2024, 3, 8, 19, 0, 0, 0, time.UTC)
+
+	harbourFirst := newKitchenTestOrder(orderRepo, harbour, domain.OrderStatusPreparing, 1, base)
+	downtownFirst := newKitchenTestOrder(orderRepo, downtown, domain.OrderStatusConfirmed, 1, base.Add(time.Minute))
+	downtownSecond := newKitchenTestOrder(orderRepo, downtown, domain.OrderStatusPreparing, 1, base.Add(2*time.Minute))
+	newKitchenTestOrder(orderRepo, downtown, domain.OrderStatusPending, 1, base)
+	newKitchenTestOrder(orderRepo, downtown, domain.OrderStatusReady, 1, base)
+	newKitchenTestOrder(orderRepo, uptown, domain.OrderStatusConfirmed, 1, base)
+
+	queues, err := kitchen.Queue(context.Background(), kitchenAccess(downtown, harbour))
+	if err != nil {
+		t.Fatalf("Queue: %v", err)
+	}
+	if len(queues) != 2 {
+		t.Fatalf("expected the queues of two stores, got %d", len(queues))
+	}
+	if queues[0].StoreID != harbour || len(queues[0].Orders) != 1 || queues[0].Orders[0].ID != harbourFirst.ID {
+		t.Errorf("expected the harbour queue first with its one order, got %+v", queues[0])
+	}
+	if queues[1].StoreID != downtown || len(queues[1].Orders) != 2 ||
+		queues[1].Orders[0].ID != downtownFirst.ID || queues[1].Orders[1].ID != downtownSecond.ID {
+		t.Errorf("expected the downtown queue oldest first, got %+v", queues[1])
+	}
+}
+
+func TestKitchenService_SetItemStatus(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := newMockOrderRepository()
+	events := NewOrderEventBroker()
+	kitchen := NewKitchenService(&mockTransactor{}, orderRepo, events)
+	storeID := uuid.New()
+	cook := kitchenAccess(storeID)
+
+	order := newKitchenTestOrder(orderRepo, storeID, domain.OrderStatusConfirmed, 2, time.Now())
+	first, second, fee := order.Items[0], order.Items[1], order.Items[2]
+
+	stream, cancel := kitchen.Subscribe(cook)
+	defer cancel()
+
+	updated, err := kitchen.SetItemStatus(ctx, order.ID, first.ID, uuid.New(), cook, domain.KitchenItemPreparing)
+	if err != nil {
+		t.Fatalf("SetItemStatus: %v", err)
+	}
+	if updated.Status != domain.OrderStatusPreparing || len(orderRepo.history[order.ID]) != 1 {
+		t.Errorf("expected starting the first line to move the order to preparing, got %s", updated.Status)
+	}
+	if event := <-stream; event.Type != domain.OrderEventUpdated || event.Order.ID != order.ID {
+		t.Errorf("expected an update event for the order, got %+v", event)
+	}
+
+	failures := []struct {
+		name   string
+		itemID uuid.UUID
+		access auth.Access
+		status string
+		want   error
+	}{
+		{"unknown status", first.ID, cook, "burnt", ErrInvalidKitchenStatus},
+		{"back to queued", first.ID, cook, domain.KitchenItemQueued, ErrIllegalKitchenTransition},
+		{"same status", first.ID, cook, domain.KitchenItemPreparing, ErrIllegalKitchenTransition},
+		{"charge line", fee.ID, cook, domain.KitchenItemReady, repository.ErrOrderItemNotFound},
+		{"unknown line", uuid.New(), cook, domain.KitchenItemReady, repository.ErrOrderItemNotFound},
+		{"another store's cook", first.ID, kitchenAccess(uuid.New()), domain.KitchenItemReady, repository.ErrOrderNotFound},
+	}
+	for _, tt := range failures {
+		if _, err := kitchen.SetItemStatus(ctx, order.ID, tt.itemID, uuid.New(), tt.access, tt.status); !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+	}
+
+	if updated, _ = kitchen.SetItemStatus(ctx, order.ID, first.ID, uuid.New(), cook, domain.KitchenItemReady); updated.Status != domain.OrderStatusPreparing {
+		t.Errorf("expected the order to keep preparing while a line is queued, got %s", updated.Status)
+	}
+	if updated, _ = kitchen.SetItemStatus(ctx, order.ID, second.ID, uuid.New(), cook, domain.KitchenItemReady); updated.Status != domain.OrderStatusReady {
+		t.Errorf("expected finishing the last line to make the order ready, got %s", updated.Status)
+	}
+	if history := orderRepo.history[order.ID]; len(history) != 2 || history[1].FromStatus != domain.OrderStatusPreparing || history[1].ToStatus != domain.OrderStatusReady {
+		t.Errorf("expected preparing and ready in the history, got %d entries", len(history))
+	}
+
+	if _, err := kitchen.SetItemStatus(ctx, order.ID, second.ID, uuid.New(), cook, domain.KitchenItemReady); err != ErrOrderNotInKitchen {
+		t.Errorf("expected ErrOrderNotInKitchen once the order is ready, got %v", err)
+	}
+}
+
+func TestOrderEvents_PublishedToTheOrdersStores(t *testing.T) {
+	productRepo := newMockProductRepository()
+	cartRepo := newMockCartRepository(productRepo)
+	events := NewOrderEventBroker()
+	orderService := NewOrderService(&mockTransactor{}, newMockOrderRepository(), cartRepo, productRepo, newMockStoreRepository(), newMockAddressRepository(), nil, newTestPricingEngine(productRepo), events)
+	kitchen := NewKitchenService(&mockTransactor{}, newMockOrderRepository(), events)
+
+	own, cancelOwn := kitchen.Subscribe(kitchenAccess(testStoreID))
+	defer cancelOwn()
+	other, cancelOther := kitchen.Subscribe(kitchenAccess(uuid.New()))
+	defer cancelOther()
+
+	product := &domain.Product{ID: uuid.New(), Name: "Diavola", Price: domain.Cents(1100), Stock: 5}
+	_ = productRepo.Create(context.Background(), product)
+	order := placeTestOrder(t, cartRepo, orderService, product, 2)
+	if _, err := orderService.TransitionStatus(context.Background(), order.ID, uuid.New(), staffAccess, domain.OrderStatusConfirmed, ""); err != nil {
+		t.Fatalf("TransitionStatus: %v", err)
+	}
+
+	created := <-own
+	if created.Type != domain.OrderEventCreated || created.Order.ID != order.ID || created.Order.Items[0].KitchenStatus != domain.KitchenItemQueued {
+		t.Errorf("expected a created event with queued lines, got %+v", created)
+	}
+	if confirmed := <-own; confirmed.Type != domain.OrderEventUpdated || confirmed.Order.Status != domain.OrderStatusConfirmed {
+		t.Errorf("expected an update event for the confirmation, got %+v", confirmed)
+	}
+	select {
+	case event := <-other:
+		t.Errorf("expected no events for another store's kitchen, got %+v", event)
+	default:
+	}
+}
+
+func TestOrderEventBroker_DropsSlowSubscribers(t *testing.T) {
+	broker := NewOrderEventBroker()
+	slow, cancel := broker.Subscribe(func(*domain.OrderEvent) bool { return true })
+
+	event := &domain.OrderEvent{Type: domain.OrderEventUpdated, Order: &domain.Order{ID: uuid.New()}}
+	for i := 0; i <= orderEventBuffer; i++ {
+		broker.Publish(event)
+	}
+
+	received := 0
+	for range slow {
+		received++
+	}
+	if received != orderEventBuffer {
+		t.Errorf("expected the buffered %d events before the channel closed, got %d", orderEventBuffer, received)
+	}
+
+	// Ending a dropped subscription is harmless
+	cancel()
+	broker.Publish(event)
+}
+
+// Feature: ordering-platform, Property 93: Kitchen progress of the lines sets the order status
+func TestProperty_KitchenProgressSetsOrderStatus(t *testing.T) {
+	statuses := []string{domain.KitchenItemQueued, domain.KitchenItemPreparing, domain.KitchenItemReady}
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("the order is ready once every line is, preparing once one is started, and its history stays legal", prop.ForAll(
+		func(lines int, moves []int) bool {
+			orderRepo := newMockOrderRepository()
+			kitchen := NewKitchenService(&mockTransactor{}, orderRepo, NewOrderEventBroker())
+			storeID := uuid.New()
+			order := newKitchenTestOrder(orderRepo, storeID, domain.OrderStatusConfirmed, lines, time.Now())
+
+			for _, move := range moves {
+				item := order.Items[move%lines]
+				status := statuses[move/lines%len(statuses)]
+				legal := domain.CanAdvanceKitchenItem(item.KitchenStatus, status)
+
+				_, err := kitchen.SetItemStatus(context.Background(), order.ID, item.ID, uuid.New(), kitchenAccess(storeID), status)
+				if order.Status == domain.OrderStatusReady {
+					// Moves after the order left the queue are rejected
+					if err != nil && err != ErrOrderNotInKitchen && !errors.Is(err, ErrIllegalKitchenTransition) {
+						t.Logf("FAIL: Unexpected error %v", err)
+						return false
+					}
+				} else if legal != (err == nil) {
+					t.Logf("FAIL: Move to %s from %s returned %v", status, item.KitchenStatus, err)
+					return false
+				}
+
+				started, ready := 0, 0
+				for _, line := range order.Items[:lines] {
+					if line.KitchenStatus != domain.KitchenItemQueued {
+						started++
+					}
+					if line.KitchenStatus == domain.KitchenItemReady {
+						ready++
+					}
+				}
+				want := domain.OrderStatusConfirmed
+				if ready == lines {
+					want = domain.OrderStatusReady
+				} else if started > 0 {
+					want = domain.OrderStatusPreparing
+				}
+				if order.Status != want {
+					t.Logf("FAIL: Order is %s with %d of %d lines started and %d ready", order.Status, started, lines, ready)
+					return false
+				}
+			}
+
+			for _, change := range orderRepo.history[order.ID] {
+				if !domain.CanTransitionOrder(change.FromStatus, change.ToStatus) {
+					t.Logf("FAIL: Illegal transition %s -> %s recorded", change.FromStatus, change.ToStatus)
+					return false
+				}
+			}
+			return true
+		},
+		gen.IntRange(1, 4),
+		gen.SliceOfN(12, gen.IntRange(0, 100)),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
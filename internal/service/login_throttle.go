@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+)
+
+var (
+	ErrLoginThrottled             = errors.New("too many failed login attempts")
+	ErrInvalidLoginThrottlePolicy = errors.New("invalid login throttle policy")
+)
+
+// LoginThrottledError reports that logins for an email address or client IP are refused
+// after too many failures. It matches ErrLoginThrottled with errors.Is.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", ErrLoginThrottled, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginThrottledError) Is(target error) bool {
+	return target == ErrLoginThrottled
+}
+
+// LoginLimit sets how many failed logins an email address or a client IP is allowed
+type LoginLimit struct {
+	// FreeAttempts is how many failures are allowed before logins are delayed
+	FreeAttempts int
+	// LockoutThreshold is the number of failures that locks logins for the lockout duration
+	LockoutThreshold int
+}
+
+// LoginThrottlePolicy controls how failed logins slow down and lock out further attempts.
+// Failures are counted separately per email address and per client IP.
+type LoginThrottlePolicy struct {
+	// FailureWindow is how long a failure is remembered; the count restarts once no login
+	// has failed for this long
+	FailureWindow time.Duration
+	// BaseDelay is how long logins are refused after the first failure beyond the free
+	// attempts; every further failure doubles it, up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// LockoutDuration is how long logins are refused once the lockout threshold is reached
+	LockoutDuration time.Duration
+	// Email limits failures per email address
+	Email LoginLimit
+	// IP limits failures per client IP. It should allow more than Email because many users
+	// can share an address.
+	IP LoginLimit
+}
+
+// DefaultLoginThrottlePolicy returns the policy used when nothing is configured
+func DefaultLoginThrottlePolicy() LoginThrottlePolicy {
+	return LoginThrottlePolicy{
+		FailureWindow:   15 * time.Minute,
+		BaseDelay:       time.Second,
+		MaxDelay:        30 * time.Second,
+		LockoutDuration: 15 * time.Minute,
+		Email:           LoginLimit{FreeAttempts: 3, LockoutThreshold: 10},
+		IP:              LoginLimit{FreeAttempts: 20, LockoutThreshold: 100},
+	}
+}
+
+// Validate checks that durations are positive and lockouts follow the free attempts
+func (p LoginThrottlePolicy) Validate() error {
+	switch {
+	case p.FailureWindow <= 0 || p.BaseDelay <= 0 || p.LockoutDuration <= 0:
+		return fmt.Errorf("%w: durations must be positive", ErrInvalidLoginThrottlePolicy)
+	case p.MaxDelay < p.BaseDelay:
+		return fmt.Errorf("%w: maximum delay must not be shorter than the base delay", ErrInvalidLoginThrottlePolicy)
+	case p.Email.FreeAttempts < 0 || p.IP.FreeAttempts < 0:
+		return fmt.Errorf("%w: free attempts must not be negative", ErrInvalidLoginThrottlePolicy)
+	case p.Email.LockoutThreshold <= p.Email.FreeAttempts || p.IP.LockoutThreshold <= p.IP.FreeAttempts:
+		return fmt.Errorf("%w: lockout thresholds must exceed the free attempts", ErrInvalidLoginThrottlePolicy)
+	}
+	return nil
+}
+
+// lockFor returns how long logins are refused after the given number of failures
+func (p LoginThrottlePolicy) lockFor(failures int, limit LoginLimit) time.Duration {
+	if failures >= limit.LockoutThreshold {
+		return p.LockoutDuration
+	}
+	if failures <= limit.FreeAttempts {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := limit.FreeAttempts + 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// LoginThrottle tracks failed logins and refuses logins after too many of them
+type LoginThrottle interface {
+	Check(ctx context.Context, email, ipAddress string) error
+	RecordFailure(ctx context.Context, email, ipAddress string) error
+	RecordSuccess(ctx context.Context, email string) error
+	Unlock(ctx context.Context, email string) error
+}
+
+type loginThrottle struct {
+	repo   repository.LoginThrottleRepository
+	policy LoginThrottlePolicy
+}
+
+// NewLoginThrottle creates a new instance of LoginThrottle
+func NewLoginThrottle(repo repository.LoginThrottleRepository, policy LoginThrottlePolicy) LoginThrottle {
+	return &loginThrottle{
+		repo:   repo,
+		policy: policy,
+	}
+}
+
+// throttleKey names what a throttle counts failures for
+type throttleKey struct {
+	kind    string
+	subject string
+}
+
+// loginThrottleKeys returns the throttles a login attempt counts against. Email addresses
+// are compared case-insensitively so changing the case does not reset the count.
+func loginThrottleKeys(email, ipAddress string) []throttleKey {
+	keys := []throttleKey{{domain.LoginThrottleEmail, normalizeEmail(email)}}
+	if ipAddress != "" {
+		keys = append(keys, throttleKey{domain.LoginThrottleIP, ipAddress})
+	}
+	return keys
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Check returns a LoginThrottledError while logins for the email address or the client IP
+// are refused
+func (t *loginThrottle) Check(ctx context.Context, email, ipAddress string) error {
+	now := time.Now()
+	var retryAfter time.Duration
+	for _, key := range loginThrottleKeys(email, ipAddress) {
+		throttle, err := t.repo.Find(ctx, key.kind, key.subject)
+		if err != nil {
+			if err == repository.ErrLoginThrottleNotFound {
+				continue
+			}
+			return fmt.Errorf("failed to check login throttle: %w", err)
+		}
+		if throttle.IsLocked(now) {
+			if wait := throttle.LockedUntil.Sub(now); wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LoginThrottledError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login for the email address and the client IP and refuses
+// further logins for as long as the policy requires
+func (t *loginThrottle) RecordFailure(ctx context.Context, email, ipAddress string) error {
+	now := time.Now()
+	for _, key := range loginThrottleKeys(email, ipAddress) {
+		throttle, err := t.repo.RecordFailure(ctx, key.kind, key.subject, now, now.Add(-t.policy.FailureWindow))
+		if err != nil {
+			return err
+		}
+
+		limit := t.policy.Email
+		if key.kind == domain.LoginThrottleIP {
+			limit = t.policy.IP
+		}
+		if lock := t.policy.lockFor(throttle.Failures, limit); lock > 0 {
+			if err := t.repo.Lock(ctx, key.kind, key.subject, now.Add(lock)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RecordSuccess forgets the failed logins of an email address. Failures from the client IP
+// are kept, so signing in to one account does not reset attempts against others.
+func (t *loginThrottle) RecordSuccess(ctx context.Context, email string) error {
+	return t.repo.Clear(ctx, domain.LoginThrottleEmail, normalizeEmail(email))
+}
+
+// Unlock lifts the lock on an email address and forgets its failed logins
+func (t *loginThrottle) Unlock(ctx context.Context, email string) error {
+	return t.repo.Clear(ctx, domain.LoginThrottleEmail, normalizeEmail(email))
+}
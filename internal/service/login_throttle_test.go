@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+type mockLoginThrottleRepository struct {
+	throttles map[string]*domain.LoginThrottle
+}
+
+func newMockLoginThrottleRepository() *mockLoginThrottleRepository {
+	return &mockLoginThrottleRepository{
+		throttles: make(map[string]*domain.LoginThrottle),
+	}
+}
+
+func (m *mockLoginThrottleRepository) Find(ctx context.Context, kind, subject string) (*domain.LoginThrottle, error) {
+	throttle, exists := m.throttles[kind+":"+subject]
+	if !exists {
+		return nil, repository.ErrLoginThrottleNotFound
+	}
+	return throttle, nil
+}
+
+func (m *mockLoginThrottleRepository) RecordFailure(ctx context.Context, kind, subject string, now, windowStart time.Time) (*domain.LoginThrottle, error) {
+	throttle, exists := m.throttles[kind+":"+subject]
+	if !exists {
+		throttle = &domain.LoginThrottle{Kind: kind, Subject: subject}
+		m.throttles[kind+":"+subject] = throttle
+	}
+	if throttle.LastFailureAt.Before(windowStart) {
+		throttle.Failures = 0
+	}
+	throttle.Failures++
+	throttle.LastFailureAt = now
+	return throttle, nil
+}
+
+func (m *mockLoginThrottleRepository) Lock(ctx context.Context, kind, subject string, until time.Time) error {
+	throttle, exists := m.throttles[kind+":"+subject]
+	if !exists {
+		return repository.ErrLoginThrottleNotFound
+	}
+	throttle.LockedUntil = &until
+	return nil
+}
+
+func (m *mockLoginThrottleRepository) Clear(ctx context.Context, kind, subject string) error {
+	delete(m.throttles, kind+":"+subject)
+	return nil
+}
+
+// newTestLoginThrottle returns a throttle with the default policy and no recorded failures
+func newTestLoginThrottle() LoginThrottle {
+	return NewLoginThrottle(newMockLoginThrottleRepository(), DefaultLoginThrottlePolicy())
+}
+
+// unlockAll lets every throttled login through again without forgetting the failures
+func (m *mockLoginThrottleRepository) unlockAll() {
+	for _, throttle := range m.throttles {
+		throttle.LockedUntil = nil
+	}
+}
+
+// Feature: ordering-platform, Property 88: Failed logins lock out an email address whether or not it has an account
+// Validates: Requirements 2.2
+func TestProperty_FailedLoginsLockOutEmail(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	policy := LoginThrottlePolicy{
+		FailureWindow:   time.Hour,
+		BaseDelay:       time.Second,
+		MaxDelay:        time.Minute,
+		LockoutDuration: 15 * time.Minute,
+		Email:           LoginLimit{FreeAttempts: 2, LockoutThreshold: 5},
+		IP:              LoginLimit{FreeAttempts: 10, LockoutThreshold: 50},
+	}
+
+	properties.Property("the threshold locks the address and only that address", prop.ForAll(
+		func(registered bool, ip string) bool {
+			throttles := newMockLoginThrottleRepository()
+			service := NewUserService(&mockTransactor{}, newMockUserRepository(), newMockRoleRepository(), newMockRefreshTokenRepository(), newTestTokenManager(), DefaultTokenPolicy(), NewLoginThrottle(throttles, policy))
+			ctx := context.Background()
+			client := ClientInfo{IPAddress: ip}
+
+			if registered {
+				if _, err := service.Register(ctx, "target@example.com", "password123", "Test", "User"); err != nil {
+					t.Logf("FAIL: Register failed: %v", err)
+					return false
+				}
+			}
+			if _, err := service.Register(ctx, "other@example.com", "password123", "Other", "User"); err != nil {
+				t.Logf("FAIL: Register failed: %v", err)
+				return false
+			}
+
+			for i := 1; i <= policy.Email.LockoutThreshold; i++ {
+				_, _, _, err := service.Login(ctx, "target@example.com", "wrongpassword", client)
+				if err != ErrInvalidCredentials {
+					t.Logf("FAIL: Attempt %d should fail with invalid credentials, got: %v", i, err)
+					return false
+				}
+				// Skip the backoff delays to reach the lockout
+				if i < policy.Email.LockoutThreshold {
+					throttles.unlockAll()
+				}
+			}
+
+			// Even the right password is refused, with a different case too
+			var throttled *LoginThrottledError
+			_, _, _, err := service.Login(ctx, "Target@Example.com", "password123", client)
+			if !errors.As(err, &throttled) || throttled.RetryAfter <= 14*time.Minute {
+				t.Logf("FAIL: Locked address should be throttled for the lockout, got: %v", err)
+				return false
+			}
+
+			// Another account from the same client is not locked
+			if _, _, _, err := service.Login(ctx, "other@example.com", "password123", client); err != nil {
+				t.Logf("FAIL: Other account should still log in: %v", err)
+				return false
+			}
+
+			return true
+		},
+		gen.Bool(),
+		gen.OneConstOf("", "10.0.0.1", "2001:db8::1"),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestLoginThrottlePolicy_BacksOffExponentially(t *testing.T) {
+	policy := LoginThrottlePolicy{
+		FailureWindow:   time.Hour,
+		BaseDelay:       time.Second,
+		MaxDelay:        10 * time.Second,
+		LockoutDuration: 15 * time.Minute,
+		Email:           LoginLimit{FreeAttempts: 3, LockoutThreshold: 10},
+	}
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, 0},
+		{3, 0},
+		{4, time.Second},
+		{5, 2 * time.Second},
+		{6, 4 * time.Second},
+		{7, 8 * time.Second},
+		{8, 10 * time.Second},
+		{9, 10 * time.Second},
+		{10, 15 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := policy.lockFor(tt.failures, policy.Email); got != tt.want {
+			t.Errorf("lockFor(%d) = %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestLoginThrottlePolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*LoginThrottlePolicy)
+		wantErr bool
+	}{
+		{"default", func(p *LoginThrottlePolicy) {}, false},
+		{"no lockout duration", func(p *LoginThrottlePolicy) { p.LockoutDuration = 0 }, true},
+		{"maximum below base delay", func(p *LoginThrottlePolicy) { p.MaxDelay = p.BaseDelay / 2 }, true},
+		{"threshold within free attempts", func(p *LoginThrottlePolicy) { p.IP.LockoutThreshold = p.IP.FreeAttempts }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := DefaultLoginThrottlePolicy()
+			tt.modify(&policy)
+			err := policy.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidLoginThrottlePolicy) {
+				t.Errorf("expected ErrInvalidLoginThrottlePolicy, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLogin_IPLockoutCoversEveryAccount(t *testing.T) {
+	policy := DefaultLoginThrottlePolicy()
+	policy.IP = LoginLimit{FreeAttempts: 1, LockoutThreshold: 3}
+	throttles := newMockLoginThrottleRepository()
+	service := NewUserService(&mockTransactor{}, newMockUserRepository(), newMockRoleRepository(), newMockRefreshTokenRepository(), newTestTokenManager(), DefaultTokenPolicy(), NewLoginThrottle(throttles, policy))
+	ctx := context.Background()
+	attacker := ClientInfo{IPAddress: "203.0.113.7"}
+
+	if _, err := service.Register(ctx, "victim@example.com", "password123", "Test", "User"); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		throttles.unlockAll()
+		if _, _, _, err := service.Login(ctx, email, "guess", attacker); err != ErrInvalidCredentials {
+			t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+		}
+	}
+
+	if _, _, _, err := service.Login(ctx, "victim@example.com", "password123", attacker); !errors.Is(err, ErrLoginThrottled) {
+		t.Errorf("locked client IP should be throttled, got %v", err)
+	}
+	if _, _, _, err := service.Login(ctx, "victim@example.com", "password123", ClientInfo{IPAddress: "198.51.100.1"}); err != nil {
+		t.Errorf("another client should still log in: %v", err)
+	}
+}
+
+func TestUnlockAccount_LiftsLockout(t *testing.T) {
+	policy := DefaultLoginThrottlePolicy()
+	policy.Email = LoginLimit{FreeAttempts: 0, LockoutThreshold: 1}
+	service := NewUserService(&mockTransactor{}, newMockUserRepository(), newMockRoleRepository(), newMockRefreshTokenRepository(), newTestTokenManager(), DefaultTokenPolicy(), NewLoginThrottle(newMockLoginThrottleRepository(), policy))
+	ctx := context.Background()
+
+	user, err := service.Register(ctx, "Locked@example.com", "password123", "Test", "User")
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if _, _, _, err := service.Login(ctx, "Locked@example.com", "wrongpassword", ClientInfo{}); err != ErrInvalidCredentials {
+		t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+	}
+	if _, _, _, err := service.Login(ctx, "Locked@example.com", "password123", ClientInfo{}); !errors.Is(err, ErrLoginThrottled) {
+		t.Fatalf("expected the account to be locked, got %v", err)
+	}
+
+	if err := service.UnlockAccount(ctx, user.ID); err != nil {
+		t.Fatalf("UnlockAccount failed: %v", err)
+	}
+	if _, _, _, err := service.Login(ctx, "Locked@example.com", "password123", ClientInfo{}); err != nil {
+		t.Errorf("unlocked account should log in: %v", err)
+	}
+}
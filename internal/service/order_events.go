@@ -0,0 +1,82 @@
+package service
+
+import (
+	"sync"
+
+	"pizza-must/internal/domain"
+)
+
+// orderEventBuffer is how many events a subscriber may fall behind before it is dropped
+const orderEventBuffer = 64
+
+// OrderEventPublisher receives every new and changed order
+type OrderEventPublisher interface {
+	Publish(event *domain.OrderEvent)
+}
+
+// OrderEventBroker fans order events out to subscribers such as the kitchen stream.
+// Subscribers only see events published by the same API instance.
+type OrderEventBroker struct {
+	mu          sync.Mutex
+	subscribers map[*orderSubscription]struct{}
+}
+
+type orderSubscription struct {
+	events chan *domain.OrderEvent
+	accept func(*domain.OrderEvent) bool
+}
+
+// NewOrderEventBroker creates a broker without subscribers
+func NewOrderEventBroker() *OrderEventBroker {
+	return &OrderEventBroker{
+		subscribers: make(map[*orderSubscription]struct{}),
+	}
+}
+
+// Subscribe returns a channel of the events accept returns true for and a function ending
+// the subscription, which callers must call once done. Publishing never waits for a slow
+// subscriber: one that falls orderEventBuffer events behind has its channel closed and
+// should resubscribe after reloading the state it tracks.
+func (b *OrderEventBroker) Subscribe(accept func(*domain.OrderEvent) bool) (<-chan *domain.OrderEvent, func()) {
+	subscription := &orderSubscription{
+		events: make(chan *domain.OrderEvent, orderEventBuffer),
+		accept: accept,
+	}
+
+	b.mu.Lock()
+	b.subscribers[subscription] = struct{}{}
+	b.mu.Unlock()
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(subscription)
+	}
+	return subscription.events, cancel
+}
+
+// Publish delivers event to every subscriber accepting it
+func (b *OrderEventBroker) Publish(event *domain.OrderEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for subscription := range b.subscribers {
+		if !subscription.accept(event) {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			b.remove(subscription)
+		}
+	}
+}
+
+// remove ends a subscription if it is still registered; b.mu must be held
+func (b *OrderEventBroker) remove(subscription *orderSubscription) {
+	if _, ok := b.subscribers[subscription]; !ok {
+		return
+	}
+	delete(b.subscribers, subscription)
+	close(subscription.events)
+}
@@ -0,0 +1,657 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrEmptyCart              = errors.New("cart is empty")
+	ErrInvalidOrderStatus     = errors.New("invalid order status")
+	ErrIllegalOrderTransition = errors.New("illegal order status transition")
+	ErrInvalidDateRange       = errors.New("invalid date range")
+	ErrInsufficientPermission = errors.New("insufficient permissions")
+	ErrInvalidFulfillment     = errors.New("invalid fulfillment")
+	ErrAddressNotServed       = errors.New("the store does not deliver to this address")
+	ErrBelowDeliveryMinimum   = errors.New("order is below the delivery minimum")
+)
+
+// OrderListOptions holds filtering and pagination options for a customer's order history
+type OrderListOptions struct {
+	Status      string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Page        int
+	PageSize    int
+}
+
+// OrderPage represents a single page of orders with pagination metadata
+type OrderPage struct {
+	Orders     []*domain.Order
+	Total      int
+	Page       int
+	PageSize   int
+	TotalPages int
+}
+
+// CheckoutOptions holds the customer's choices for a checkout
+type CheckoutOptions struct {
+	// ScheduledFor is the start of the time slot to book the order into; nil orders for
+	// as soon as possible
+	ScheduledFor *time.Time
+	// Fulfillment is pickup or delivery; empty means pickup
+	Fulfillment string
+	// AddressID is the address book entry a delivery goes to
+	AddressID *uuid.UUID
+}
+
+// OrderTransitionError describes a status change the order lifecycle does not allow.
+// It matches ErrIllegalOrderTransition with errors.Is.
+type OrderTransitionError struct {
+	From    string   `json:"from"`
+	To      string   `json:"to"`
+	Allowed []string `json:"allowed"`
+}
+
+func (e *OrderTransitionError) Error() string {
+	return fmt.Sprintf("%s: %s -> %s", ErrIllegalOrderTransition, e.From, e.To)
+}
+
+func (e *OrderTransitionError) Is(target error) bool {
+	return target == ErrIllegalOrderTransition
+}
+
+// OrderService defines the interface for order business logic
+type OrderService interface {
+	Checkout(ctx context.Context, userID uuid.UUID, opts CheckoutOptions) (*domain.Order, error)
+	ListOrders(ctx context.Context, userID uuid.UUID, opts OrderListOptions) (*OrderPage, error)
+	GetOrder(ctx context.Context, orderID, requesterID uuid.UUID, access StoreAccess) (*domain.Order, error)
+	TransitionStatus(ctx context.Context, orderID, actorID uuid.UUID, access StoreAccess, status, reason string) (*domain.Order, error)
+}
+
+type orderService struct {
+	transactor    repository.Transactor
+	orderRepo     repository.OrderRepository
+	cartRepo      repository.CartRepository
+	productRepo   repository.ProductRepository
+	storeRepo     repository.StoreRepository
+	addressRepo   repository.AddressRepository
+	promotionRepo repository.PromotionRepository
+	pricing       PricingEngine
+	events        OrderEventPublisher
+}
+
+// NewOrderService creates a new instance of OrderService
+func NewOrderService(
+	transactor repository.Transactor,
+	orderRepo repository.OrderRepository,
+	cartRepo repository.CartRepository,
+	productRepo repository.ProductRepository,
+	storeRepo repository.StoreRepository,
+	addressRepo repository.AddressRepository,
+	promotionRepo repository.PromotionRepository,
+	pricing PricingEngine,
+	events OrderEventPublisher,
+) OrderService {
+	return &orderService{
+		transactor:    transactor,
+		orderRepo:     orderRepo,
+		cartRepo:      cartRepo,
+		productRepo:   productRepo,
+		storeRepo:     storeRepo,
+		addressRepo:   addressRepo,
+		promotionRepo: promotionRepo,
+		pricing:       pricing,
+		events:        events,
+	}
+}
+
+// Checkout converts the user's cart into a pending order in a single transaction. Pickups
+// are placed at the cart's store. The store must be active and, unless the order is scheduled, open now. Cart rows and the store's stock rows are locked,
+// lines are priced by the pricing engine at the store's prices, the store's stock
+// is verified and decremented, name, option and price snapshots are written to the order
+// items and the cart is emptied. If any product cannot be supplied, nothing is written and
+// an InsufficientStockError listing every short product is returned.
+//
+// An applied coupon is re-checked with its promotion row locked, so caps cannot be
+// exceeded by concurrent checkouts, and its redemption is recorded with the order. A
+// coupon that no longer applies fails the checkout with a CouponError.
+//
+// A scheduled order must start one of the store's bookable time slots, see
+// StoreService.ListSlots. The slot's bookings are counted with the store row locked, so
+// concurrent checkouts cannot overbook it.
+//
+// A delivery goes to one of the user's addresses and is placed at the store serving it, see
+// servingStore; when that is not the cart's store the lines are priced and the stock taken
+// at the serving store instead. The serving zone's minimum order value must be reached by
+// the product subtotal and its fee is added as a separate order line.
+//
+// Placed orders are published as OrderEventCreated.
+func (s *orderService) Checkout(ctx context.Context, userID uuid.UUID, opts CheckoutOptions) (*domain.Order, error) {
+	address, err := s.deliveryAddress(ctx, userID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var order *domain.Order
+
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		// Items can only be added once a store is selected, so a cart without one is empty
+		storeID, err := s.cartRepo.FindStoreTx(ctx, tx, userID)
+		if err != nil {
+			if err == repository.ErrCartStoreNotSet {
+				return ErrEmptyCart
+			}
+			return fmt.Errorf("failed to get cart store: %w", err)
+		}
+
+		store, err := findStore(ctx, s.storeRepo, storeID)
+		if err != nil {
+			return err
+		}
+
+		var zone *domain.DeliveryZone
+		if address != nil {
+			store, zone, err = s.servingStore(ctx, store, address)
+			if err != nil {
+				return err
+			}
+			storeID = store.ID
+		}
+		if !store.Active {
+			return ErrStoreInactive
+		}
+
+		scheduledFor, err := s.reserveSlot(ctx, tx, store, opts.ScheduledFor)
+		if err != nil {
+			return err
+		}
+
+		lines, err := s.cartRepo.LockForCheckoutTx(ctx, tx, userID, storeID)
+		if err != nil {
+			return fmt.Errorf("failed to lock cart: %w", err)
+		}
+
+		if len(lines) == 0 {
+			return ErrEmptyCart
+		}
+
+		// Lines are priced by the same engine that serves quotes
+		if err := s.pricing.PriceCartLines(ctx, lines); err != nil {
+			return err
+		}
+
+		if shortages := cartShortages(lines); len(shortages) > 0 {
+			return &InsufficientStockError{Shortages: shortages}
+		}
+
+		order = newOrderFromCart(userID, storeID, lines)
+		order.ScheduledFor = scheduledFor
+
+		promotion, err := s.redeemCoupon(ctx, tx, order, lines)
+		if err != nil {
+			return err
+		}
+
+		if address != nil {
+			if err := addDelivery(order, address, zone); err != nil {
+				return err
+			}
+		}
+
+		for _, line := range lines {
+			if err := s.productRepo.AdjustStockTx(ctx, tx, storeID, line.ProductID, -line.Quantity); err != nil {
+				return fmt.Errorf("failed to decrement stock: %w", err)
+			}
+		}
+
+		if err := s.orderRepo.CreateTx(ctx, tx, order); err != nil {
+			return fmt.Errorf("failed to create order: %w", err)
+		}
+
+		if err := s.orderRepo.AddStatusChangeTx(ctx, tx, newOrderStatusChange(order, "", userID, "")); err != nil {
+			return fmt.Errorf("failed to record order status: %w", err)
+		}
+
+		if promotion != nil {
+			redemption := &domain.PromotionRedemption{
+				ID:          uuid.New(),
+				PromotionID: promotion.ID,
+				UserID:      userID,
+				OrderID:     order.ID,
+				Discount:    order.Discount,
+				CreatedAt:   order.CreatedAt,
+			}
+			if err := s.promotionRepo.AddRedemptionTx(ctx, tx, redemption); err != nil {
+				return fmt.Errorf("failed to redeem coupon: %w", err)
+			}
+		}
+
+		if err := s.cartRepo.ClearTx(ctx, tx, userID); err != nil {
+			return fmt.Errorf("failed to clear cart: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.events.Publish(&domain.OrderEvent{Type: domain.OrderEventCreated, Order: order})
+	return order, nil
+}
+
+// ListOrders retrieves a page of the user's orders, newest first
+func (s *orderService) ListOrders(ctx context.Context, userID uuid.UUID, opts OrderListOptions) (*OrderPage, error) {
+	if opts.Status != "" && !domain.IsValidOrderStatus(opts.Status) {
+		return nil, ErrInvalidOrderStatus
+	}
+
+	if opts.CreatedFrom != nil && opts.CreatedTo != nil && !opts.CreatedFrom.Before(*opts.CreatedTo) {
+		return nil, ErrInvalidDateRange
+	}
+
+	page, pageSize := normalizePagination(opts.Page, opts.PageSize)
+
+	filter := repository.OrderFilter{
+		UserID:      userID,
+		Status:      opts.Status,
+		CreatedFrom: opts.CreatedFrom,
+		CreatedTo:   opts.CreatedTo,
+	}
+
+	orders, total, err := s.orderRepo.List(ctx, filter, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrderPage{
+		Orders:     orders,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages(total, pageSize),
+	}, nil
+}
+
+// GetOrder retrieves an order with its items and status history. Orders belonging to
+// another user are reported as not found unless the requester may read orders at the
+// order's store, so order IDs cannot be probed.
+func (s *orderService) GetOrder(ctx context.Context, orderID, requesterID uuid.UUID, access StoreAccess) (*domain.Order, error) {
+	order, err := s.orderRepo.FindByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.UserID != requesterID && !hasStorePermission(access, order.StoreID, domain.PermissionOrdersRead) {
+		return nil, repository.ErrOrderNotFound
+	}
+
+	history, err := s.orderRepo.ListStatusHistory(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	order.History = history
+
+	return order, nil
+}
+
+// TransitionStatus moves an order to a new status on behalf of actorID, who must be allowed
+// to transition orders at the order's store; orders of other stores are reported as not
+// found. Cancellations and refunds also need the cancel and refunds permissions at the
+// store. The order row is locked for the duration of the change, the transition is checked
+// against the order lifecycle and recorded in the status history. Cancelling an order
+// returns its items to the store's stock. Once the order leaves the kitchen, lines still
+// queued or being prepared are marked ready so the kitchen queue agrees with the order.
+// The changed order is published as OrderEventUpdated.
+func (s *orderService) TransitionStatus(ctx context.Context, orderID, actorID uuid.UUID, access StoreAccess, status, reason string) (*domain.Order, error) {
+	if !domain.IsValidOrderStatus(status) {
+		return nil, ErrInvalidOrderStatus
+	}
+
+	var order *domain.Order
+
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		order, err = s.orderRepo.FindByIDForUpdateTx(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+
+		if !hasStorePermission(access, order.StoreID, domain.PermissionOrdersTransition) {
+			return repository.ErrOrderNotFound
+		}
+		// Refunds move money and cancellations restock, so they need their own permission on
+		// top of transitioning orders
+		if status == domain.OrderStatusRefunded && !hasStorePermission(access, order.StoreID, domain.PermissionRefundsIssue) {
+			return ErrInsufficientPermission
+		}
+		if status == domain.OrderStatusCancelled && !hasStorePermission(access, order.StoreID, domain.PermissionOrdersCancel) {
+			return ErrInsufficientPermission
+		}
+
+		from := order.Status
+		if !domain.CanTransitionOrder(from, status) {
+			return &OrderTransitionError{
+				From:    from,
+				To:      status,
+				Allowed: domain.AllowedOrderTransitions(from),
+			}
+		}
+
+		if domain.RestoresStock(status) {
+			for _, item := range order.Items {
+				if item.Type != domain.OrderItemProduct {
+					continue
+				}
+				if err := s.productRepo.AdjustStockTx(ctx, tx, order.StoreID, item.ProductID, item.Quantity); err != nil {
+					return fmt.Errorf("failed to restore stock: %w", err)
+				}
+			}
+		}
+
+		if domain.EndsKitchenWork(status) {
+			for _, item := range order.Items {
+				if item.Type != domain.OrderItemProduct || item.KitchenStatus == domain.KitchenItemReady {
+					continue
+				}
+				item.KitchenStatus = domain.KitchenItemReady
+				if err := s.orderRepo.UpdateItemKitchenStatusTx(ctx, tx, item); err != nil {
+					return err
+				}
+			}
+		}
+
+		order.Status = status
+		order.UpdatedAt = time.Now()
+		if err := s.orderRepo.UpdateStatusTx(ctx, tx, order); err != nil {
+			return err
+		}
+
+		if err := s.orderRepo.AddStatusChangeTx(ctx, tx, newOrderStatusChange(order, from, actorID, reason)); err != nil {
+			return fmt.Errorf("failed to record order status: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.events.Publish(&domain.OrderEvent{Type: domain.OrderEventUpdated, Order: order})
+	return order, nil
+}
+
+// deliveryAddress validates the fulfillment options and returns the user's address a
+// delivery goes to, or nil for pickup
+func (s *orderService) deliveryAddress(ctx context.Context, userID uuid.UUID, opts CheckoutOptions) (*domain.Address, error) {
+	switch opts.Fulfillment {
+	case "", domain.FulfillmentPickup:
+		if opts.AddressID != nil {
+			return nil, fmt.Errorf("%w: pickup orders take no address", ErrInvalidFulfillment)
+		}
+		return nil, nil
+	case domain.FulfillmentDelivery:
+		if opts.AddressID == nil {
+			return nil, fmt.Errorf("%w: delivery needs an address", ErrInvalidFulfillment)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown fulfillment type %q", ErrInvalidFulfillment, opts.Fulfillment)
+	}
+
+	address, err := s.addressRepo.FindByID(ctx, userID, *opts.AddressID)
+	if err != nil {
+		if err == repository.ErrAddressNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find address: %w", err)
+	}
+	return address, nil
+}
+
+// servingStore returns the store delivering to address and the zone it delivers from. The
+// cart's store serves the order when one of its active zones reaches the address; otherwise
+// the active store reaching it with the lowest fee does, the nearest on a tie.
+// ErrAddressNotServed is returned when no store delivers there.
+func (s *orderService) servingStore(ctx context.Context, cartStore *domain.Store, address *domain.Address) (*domain.Store, *domain.DeliveryZone, error) {
+	if cartStore.Active {
+		zone, err := s.servingZone(ctx, cartStore, address)
+		if err != nil {
+			return nil, nil, err
+		}
+		if zone != nil {
+			return cartStore, zone, nil
+		}
+	}
+
+	stores, err := s.storeRepo.List(ctx, true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list stores: %w", err)
+	}
+
+	var serving *domain.Store
+	var servingZone *domain.DeliveryZone
+	for _, store := range stores {
+		if store.ID == cartStore.ID {
+			continue
+		}
+		zone, err := s.servingZone(ctx, store, address)
+		if err != nil {
+			return nil, nil, err
+		}
+		if zone == nil {
+			continue
+		}
+		if servingZone == nil || zone.DeliveryFee.Amount < servingZone.DeliveryFee.Amount ||
+			zone.DeliveryFee.Amount == servingZone.DeliveryFee.Amount &&
+				domain.DistanceMeters(store.Point(), address.Point()) < domain.DistanceMeters(serving.Point(), address.Point()) {
+			serving, servingZone = store, zone
+		}
+	}
+	if serving == nil {
+		return nil, nil, ErrAddressNotServed
+	}
+	return serving, servingZone, nil
+}
+
+// servingZone returns the store's active zone reaching address with the lowest fee, or nil
+func (s *orderService) servingZone(ctx context.Context, store *domain.Store, address *domain.Address) (*domain.DeliveryZone, error) {
+	zones, err := s.storeRepo.ListDeliveryZones(ctx, store.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delivery zones: %w", err)
+	}
+	return domain.ServingZone(zones, store.Point(), address.Point()), nil
+}
+
+// addDelivery turns order into a delivery to address from zone, checking the zone's
+// minimum order value and adding its fee as a separate line
+func addDelivery(order *domain.Order, address *domain.Address, zone *domain.DeliveryZone) error {
+	if order.Subtotal.Amount < zone.MinOrderValue.Amount {
+		return fmt.Errorf("%w: delivery needs an order of at least %s", ErrBelowDeliveryMinimum, zone.MinOrderValue)
+	}
+
+	order.Fulfillment = domain.FulfillmentDelivery
+	order.DeliveryAddress = address
+	if !zone.DeliveryFee.IsZero() {
+		order.Items = append(order.Items, &domain.OrderItem{
+			ID:          uuid.New(),
+			OrderID:     order.ID,
+			Type:        domain.OrderItemDeliveryFee,
+			ProductName: "Delivery fee",
+			Options:     []domain.SelectedOption{},
+			Price:       zone.DeliveryFee,
+			Quantity:    1,
+			Subtotal:    zone.DeliveryFee,
+		})
+		order.Total = order.Total.Add(zone.DeliveryFee)
+	}
+	return nil
+}
+
+// reserveSlot checks the store takes an order for scheduledFor and returns the start of
+// the booked slot in UTC. Orders for as soon as possible need the store to be open now.
+func (s *orderService) reserveSlot(ctx context.Context, tx *sql.Tx, store *domain.Store, scheduledFor *time.Time) (*time.Time, error) {
+	now := time.Now()
+	if scheduledFor == nil {
+		if !store.IsOpenAt(now) {
+			return nil, ErrStoreClosed
+		}
+		return nil, nil
+	}
+
+	var slot *domain.TimeSlot
+	if withinScheduleDays(store, *scheduledFor, now) {
+		for _, candidate := range bookableSlots(store, *scheduledFor, now) {
+			if candidate.Start.Equal(*scheduledFor) {
+				slot = candidate
+				break
+			}
+		}
+	}
+	if slot == nil {
+		return nil, fmt.Errorf("%w: no bookable time slot starts at %s", ErrInvalidSchedule, scheduledFor.Format(time.RFC3339))
+	}
+
+	if err := s.storeRepo.LockTx(ctx, tx, store.ID); err != nil {
+		if err == repository.ErrStoreNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to lock store: %w", err)
+	}
+
+	booked, err := s.orderRepo.CountScheduledTx(ctx, tx, store.ID, slot.Start, slot.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count scheduled orders: %w", err)
+	}
+	if booked >= slot.Capacity {
+		return nil, ErrSlotFull
+	}
+
+	start := slot.Start.UTC()
+	return &start, nil
+}
+
+// redeemCoupon locks the promotion of the coupon applied to the user's cart, checks it still
+// applies to lines and deducts its discount from order. It returns nil when no coupon is applied.
+func (s *orderService) redeemCoupon(ctx context.Context, tx *sql.Tx, order *domain.Order, lines []*domain.CartLine) (*domain.Promotion, error) {
+	promotionID, err := s.cartRepo.FindCouponTx(ctx, tx, order.UserID)
+	if err != nil {
+		if err == repository.ErrCartCouponNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get cart coupon: %w", err)
+	}
+
+	promotion, err := s.promotionRepo.FindByIDForUpdateTx(ctx, tx, promotionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock promotion: %w", err)
+	}
+
+	redemptions, err := s.promotionRepo.CountUserRedemptionsTx(ctx, tx, promotion.ID, order.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count coupon redemptions: %w", err)
+	}
+
+	discount, err := evaluateCoupon(promotion, lines, redemptions, order.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	order.Discount = discount
+	order.CouponCode = promotion.Code
+	order.Total = order.Subtotal.Sub(discount)
+	return promotion, nil
+}
+
+// newOrderStatusChange builds a history entry for the order's current status
+func newOrderStatusChange(order *domain.Order, from string, actorID uuid.UUID, reason string) *domain.OrderStatusChange {
+	return &domain.OrderStatusChange{
+		ID:         uuid.New(),
+		OrderID:    order.ID,
+		FromStatus: from,
+		ToStatus:   order.Status,
+		ActorID:    &actorID,
+		Reason:     reason,
+		CreatedAt:  order.UpdatedAt,
+	}
+}
+
+// hasStorePermission reports whether access grants the permission at the store
+func hasStorePermission(access StoreAccess, storeID uuid.UUID, permission string) bool {
+	return access != nil && access.HasStorePermission(storeID, permission)
+}
+
+// newOrderFromCart builds a pending, undiscounted pickup order at storeID with item snapshots of the
+// given cart lines
+func newOrderFromCart(userID, storeID uuid.UUID, lines []*domain.CartLine) *domain.Order {
+	now := time.Now()
+	order := &domain.Order{
+		ID:          uuid.New(),
+		UserID:      userID,
+		StoreID:     storeID,
+		Status:      domain.OrderStatusPending,
+		Fulfillment: domain.FulfillmentPickup,
+		Subtotal:    domain.Cents(0),
+		Discount:    domain.Cents(0),
+		Items:       make([]*domain.OrderItem, 0, len(lines)),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	for _, line := range lines {
+		order.Items = append(order.Items, &domain.OrderItem{
+			ID:            uuid.New(),
+			OrderID:       order.ID,
+			Type:          domain.OrderItemProduct,
+			ProductID:     line.ProductID,
+			ProductName:   line.ProductName,
+			Options:       line.Options,
+			Price:         line.UnitPrice,
+			Quantity:      line.Quantity,
+			Subtotal:      line.Subtotal,
+			KitchenStatus: domain.KitchenItemQueued,
+		})
+		order.Subtotal = order.Subtotal.Add(line.Subtotal)
+	}
+	order.Total = order.Subtotal
+
+	return order
+}
+
+// cartShortages returns every product whose lines together request more than its stock.
+// Lines of the same product with different options share the product's stock.
+func cartShortages(lines []*domain.CartLine) []StockShortage {
+	requested := make(map[uuid.UUID]int)
+	var products []*domain.CartLine
+	for _, line := range lines {
+		if _, seen := requested[line.ProductID]; !seen {
+			products = append(products, line)
+		}
+		requested[line.ProductID] += line.Quantity
+	}
+
+	// Collect every shortage before failing so the client can fix the whole cart at once
+	var shortages []StockShortage
+	for _, line := range products {
+		if requested[line.ProductID] > line.Stock {
+			shortages = append(shortages, StockShortage{
+				ProductID:   line.ProductID,
+				ProductName: line.ProductName,
+				Requested:   requested[line.ProductID],
+				Available:   line.Stock,
+			})
+		}
+	}
+
+	return shortages
+}
@@ -0,0 +1,802 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"pizza-must/internal/auth"
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// staffAccess holds every order permission at every store
+var staffAccess = auth.Access{Permissions: []string{
+	domain.PermissionOrdersRead,
+	domain.PermissionOrdersTransition,
+	domain.PermissionOrdersCancel,
+	domain.PermissionRefundsIssue,
+}}
+
+// mockTransactor runs the unit of work directly without a real transaction
+type mockTransactor struct{}
+
+func (m *mockTransactor) WithinTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	return fn(nil)
+}
+
+type mockOrderRepository struct {
+	orders  map[uuid.UUID]*domain.Order
+	history map[uuid.UUID][]*domain.OrderStatusChange
+}
+
+func newMockOrderRepository() *mockOrderRepository {
+	return &mockOrderRepository{
+		orders:  make(map[uuid.UUID]*domain.Order),
+		history: make(map[uuid.UUID][]*domain.OrderStatusChange),
+	}
+}
+
+func (m *mockOrderRepository) CreateTx(ctx context.Context, tx *sql.Tx, order *domain.Order) error {
+	m.orders[order.ID] = order
+	return nil
+}
+
+func (m *mockOrderRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Order, error) {
+	order, exists := m.orders[id]
+	if !exists {
+		return nil, repository.ErrOrderNotFound
+	}
+	copied := *order
+	return &copied, nil
+}
+
+func (m *mockOrderRepository) List(ctx context.Context, filter repository.OrderFilter, page, pageSize int) ([]*domain.Order, int, error) {
+	matching := []*domain.Order{}
+	for _, order := range m.orders {
+		if filter.UserID != uuid.Nil && order.UserID != filter.UserID {
+			continue
+		}
+		if filter.Status != "" && order.Status != filter.Status {
+			continue
+		}
+		if filter.CreatedFrom != nil && order.CreatedAt.Before(*filter.CreatedFrom) {
+			continue
+		}
+		if filter.CreatedTo != nil && !order.CreatedAt.Before(*filter.CreatedTo) {
+			continue
+		}
+		matching = append(matching, order)
+	}
+
+	sort.Slice(matching, func(i, j int) bool {
+		return matching[i].CreatedAt.After(matching[j].CreatedAt)
+	})
+
+	total := len(matching)
+	start := (page - 1) * pageSize
+	if start >= total {
+		return []*domain.Order{}, total, nil
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	return matching[start:end], total, nil
+}
+
+func (m *mockOrderRepository) ListStatusHistory(ctx context.Context, orderID uuid.UUID) ([]*domain.OrderStatusChange, error) {
+	return append([]*domain.OrderStatusChange{}, m.history[orderID]...), nil
+}
+
+func (m *mockOrderRepository) FindByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*domain.Order, error) {
+	order, exists := m.orders[id]
+	if !exists {
+		return nil, repository.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func (m *mockOrderRepository) UpdateStatusTx(ctx context.Context, tx *sql.Tx, order *domain.Order) error {
+	if _, exists := m.orders[order.ID]; !exists {
+		return repository.ErrOrderNotFound
+	}
+	m.orders[order.ID] = order
+	return nil
+}
+
+func (m *mockOrderRepository) AddStatusChangeTx(ctx context.Context, tx *sql.Tx, change *domain.OrderStatusChange) error {
+	m.history[change.OrderID] = append(m.history[change.OrderID], change)
+	return nil
+}
+
+func (m *mockOrderRepository) ListScheduledTimes(ctx context.Context, storeID uuid.UUID, from, to time.Time) ([]time.Time, error) {
+	times := []time.Time{}
+	for _, order := range m.orders {
+		if order.StoreID == storeID && holdsSlot(order, from, to) {
+			times = append(times, *order.ScheduledFor)
+		}
+	}
+	return times, nil
+}
+
+func (m *mockOrderRepository) CountScheduledTx(ctx context.Context, tx *sql.Tx, storeID uuid.UUID, from, to time.Time) (int, error) {
+	times, err := m.ListScheduledTimes(ctx, storeID, from, to)
+	return len(times), err
+}
+
+func (m *mockOrderRepository) ListKitchenQueue(ctx context.Context) ([]*domain.Order, error) {
+	queue := []*domain.Order{}
+	for _, order := range m.orders {
+		if domain.IsInKitchenQueue(order.Status) {
+			queue = append(queue, order)
+		}
+	}
+	sort.Slice(queue, func(i, j int) bool { return queue[i].CreatedAt.Before(queue[j].CreatedAt) })
+	return queue, nil
+}
+
+func (m *mockOrderRepository) UpdateItemKitchenStatusTx(ctx context.Context, tx *sql.Tx, item *domain.OrderItem) error {
+	order, exists := m.orders[item.OrderID]
+	if !exists {
+		return repository.ErrOrderItemNotFound
+	}
+	for _, existing := range order.Items {
+		if existing.ID == item.ID && existing.Type == domain.OrderItemProduct {
+			existing.KitchenStatus = item.KitchenStatus
+			return nil
+		}
+	}
+	return repository.ErrOrderItemNotFound
+}
+
+// holdsSlot reports whether order is scheduled within [from, to) and still holds its slot
+func holdsSlot(order *domain.Order, from, to time.Time) bool {
+	if order.ScheduledFor == nil || order.ScheduledFor.Before(from) || !order.ScheduledFor.Before(to) {
+		return false
+	}
+	return order.Status != domain.OrderStatusCancelled && order.Status != domain.OrderStatusRefunded
+}
+
+// placeTestOrder checks out a single-line cart and returns the resulting order
+func placeTestOrder(t *testing.T, cartRepo *mockCartRepository, orderService OrderService, product *domain.Product, quantity int) *domain.Order {
+	t.Helper()
+	ctx := context.Background()
+	userID := uuid.New()
+
+	if _, err := NewCartService(cartRepo, cartRepo.productRepo, newMockStoreRepository(), nil, newTestPricingEngine(cartRepo.productRepo)).AddItem(ctx, userID, product.ID, nil, quantity); err != nil {
+		t.Fatalf("AddItem failed: %v", err)
+	}
+
+	order, err := orderService.Checkout(ctx, userID, CheckoutOptions{})
+	if err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	return order
+}
+
+// Feature: ordering-platform, Property 74: Checkout totals and stock are consistent
+func TestProperty_CheckoutTotalsAndStockAreConsistent(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("checkout snapshots lines, decrements stock and empties the cart", prop.ForAll(
+		func(prices []int64, quantities []int) bool {
+			productRepo := newMockProductRepository()
+			cartRepo := newMockCartRepository(productRepo)
+			orderRepo := newMockOrderRepository()
+			cartService := NewCartService(cartRepo, productRepo, newMockStoreRepository(), nil, newTestPricingEngine(productRepo))
+			orderService := NewOrderService(&mockTransactor{}, orderRepo, cartRepo, productRepo, newMockStoreRepository(), newMockAddressRepository(), nil, newTestPricingEngine(productRepo), NewOrderEventBroker())
+			ctx := context.Background()
+			userID := uuid.New()
+
+			const initialStock = 50
+			var expectedTotal int64
+			products := map[uuid.UUID]int{}
+			for i, price := range prices {
+				quantity := quantities[i]
+				product := &domain.Product{ID: uuid.New(), Name: "Pizza", Price: domain.Cents(price), Stock: initialStock}
+				_ = productRepo.Create(ctx, product)
+				if _, err := cartService.AddItem(ctx, userID, product.ID, nil, quantity); err != nil {
+					t.Logf("FAIL: AddItem failed: %v", err)
+					return false
+				}
+				products[product.ID] = quantity
+				expectedTotal += price * int64(quantity)
+			}
+
+			order, err := orderService.Checkout(ctx, userID, CheckoutOptions{})
+			if err != nil {
+				t.Logf("FAIL: Checkout failed: %v", err)
+				return false
+			}
+
+			if order.Status != domain.OrderStatusPending {
+				t.Logf("FAIL: Expected pending status, got %s", order.Status)
+				return false
+			}
+
+			if order.Total != domain.Cents(expectedTotal) {
+				t.Logf("FAIL: Total mismatch. Expected %s, got %s", domain.Cents(expectedTotal), order.Total)
+				return false
+			}
+
+			itemSum := domain.Cents(0)
+			for _, item := range order.Items {
+				itemSum = itemSum.Add(item.Subtotal)
+				if item.OrderID != order.ID {
+					t.Logf("FAIL: Item not linked to order")
+					return false
+				}
+			}
+			if itemSum != order.Total {
+				t.Logf("FAIL: Item subtotals %s do not add up to total %s", itemSum, order.Total)
+				return false
+			}
+
+			for productID, quantity := range products {
+				product, _ := productRepo.FindByID(ctx, productID)
+				if product.Stock != initialStock-quantity {
+					t.Logf("FAIL: Stock not decremented. Expected %d, got %d", initialStock-quantity, product.Stock)
+					return false
+				}
+			}
+
+			if len(cartRepo.items[userID]) != 0 {
+				t.Logf("FAIL: Cart not emptied after checkout")
+				return false
+			}
+
+			return orderRepo.orders[order.ID] != nil
+		},
+		gen.SliceOfN(4, gen.Int64Range(100, 4000)),
+		gen.SliceOfN(4, gen.IntRange(1, 10)),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestCheckout_ReportsEveryOutOfStockLine(t *testing.T) {
+	productRepo := newMockProductRepository()
+	cartRepo := newMockCartRepository(productRepo)
+	orderRepo := newMockOrderRepository()
+	cartService := NewCartService(cartRepo, productRepo, newMockStoreRepository(), nil, newTestPricingEngine(productRepo))
+	orderService := NewOrderService(&mockTransactor{}, orderRepo, cartRepo, productRepo, newMockStoreRepository(), newMockAddressRepository(), nil, newTestPricingEngine(productRepo), NewOrderEventBroker())
+	ctx := context.Background()
+	userID := uuid.New()
+
+	products := []*domain.Product{
+		{ID: uuid.New(), Name: "Margherita", Price: domain.Cents(900), Stock: 5},
+		{ID: uuid.New(), Name: "Diavola", Price: domain.Cents(1100), Stock: 5},
+		{ID: uuid.New(), Name: "Funghi", Price: domain.Cents(1000), Stock: 5},
+	}
+	for _, p := range products {
+		_ = productRepo.Create(ctx, p)
+		if _, err := cartService.AddItem(ctx, userID, p.ID, nil, 3); err != nil {
+			t.Fatalf("AddItem failed: %v", err)
+		}
+	}
+
+	// Stock drops after the items were added to the cart
+	products[0].Stock = 1
+	products[2].Stock = 0
+
+	_, err := orderService.Checkout(ctx, userID, CheckoutOptions{})
+
+	var stockErr *InsufficientStockError
+	if !errors.As(err, &stockErr) {
+		t.Fatalf("Expected InsufficientStockError, got %v", err)
+	}
+	if len(stockErr.Shortages) != 2 {
+		t.Fatalf("Expected 2 shortages, got %d", len(stockErr.Shortages))
+	}
+	if len(orderRepo.orders) != 0 {
+		t.Errorf("No order should be created when stock is insufficient")
+	}
+	if products[1].Stock != 5 {
+		t.Errorf("Stock of in-stock products must not change, got %d", products[1].Stock)
+	}
+	if len(cartRepo.items[userID]) != 3 {
+		t.Errorf("Cart must be left intact, got %d items", len(cartRepo.items[userID]))
+	}
+}
+
+func TestCheckout_EmptyCart(t *testing.T) {
+	productRepo := newMockProductRepository()
+	cartRepo := newMockCartRepository(productRepo)
+	orderService := NewOrderService(&mockTransactor{}, newMockOrderRepository(), cartRepo, productRepo, newMockStoreRepository(), newMockAddressRepository(), nil, newTestPricingEngine(productRepo), NewOrderEventBroker())
+
+	if _, err := orderService.Checkout(context.Background(), uuid.New(), CheckoutOptions{}); err != ErrEmptyCart {
+		t.Fatalf("Expected ErrEmptyCart, got %v", err)
+	}
+}
+
+// Feature: ordering-platform, Property 75: Order status only follows lifecycle transitions
+func TestProperty_OrderStatusFollowsLifecycle(t *testing.T) {
+	statuses := []string{
+		domain.OrderStatusPending,
+		domain.OrderStatusConfirmed,
+		domain.OrderStatusPreparing,
+		domain.OrderStatusReady,
+		domain.OrderStatusShipped,
+		domain.OrderStatusDelivered,
+		domain.OrderStatusCancelled,
+		domain.OrderStatusRefunded,
+	}
+
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("every accepted change is a legal transition and is recorded in history", prop.ForAll(
+		func(moves []int) bool {
+			productRepo := newMockProductRepository()
+			cartRepo := newMockCartRepository(productRepo)
+			orderRepo := newMockOrderRepository()
+			orderService := NewOrderService(&mockTransactor{}, orderRepo, cartRepo, productRepo, newMockStoreRepository(), newMockAddressRepository(), nil, newTestPricingEngine(productRepo), NewOrderEventBroker())
+			ctx := context.Background()
+			adminID := uuid.New()
+
+			product := &domain.Product{ID: uuid.New(), Name: "Capricciosa", Price: domain.Cents(1200), Stock: 10}
+			_ = productRepo.Create(ctx, product)
+			order := placeTestOrder(t, cartRepo, orderService, product, 2)
+
+			accepted := 1 // checkout records the initial pending entry
+			for _, move := range moves {
+				from := orderRepo.orders[order.ID].Status
+				to := statuses[move]
+
+				_, err := orderService.TransitionStatus(ctx, order.ID, adminID, staffAccess, to, "test")
+				legal := domain.CanTransitionOrder(from, to)
+
+				if legal && err != nil {
+					t.Logf("FAIL: Legal transition %s -> %s rejected: %v", from, to, err)
+					return false
+				}
+				if !legal {
+					if !errors.Is(err, ErrIllegalOrderTransition) {
+						t.Logf("FAIL: Illegal transition %s -> %s not rejected, got %v", from, to, err)
+						return false
+					}
+					if orderRepo.orders[order.ID].Status != from {
+						t.Logf("FAIL: Status changed by rejected transition")
+						return false
+					}
+					continue
+				}
+
+				accepted++
+				last := orderRepo.history[order.ID][len(orderRepo.history[order.ID])-1]
+				if last.FromStatus != from || last.ToStatus != to || *last.ActorID != adminID {
+					t.Logf("FAIL: History entry %+v does not match %s -> %s", last, from, to)
+					return false
+				}
+			}
+
+			return len(orderRepo.history[order.ID]) == accepted
+		},
+		gen.SliceOfN(6, gen.IntRange(0, len(statuses)-1)),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestTransitionStatus_CancelRestoresStock(t *testing.T) {
+	for _, from := range []string{domain.OrderStatusPending, domain.OrderStatusConfirmed} {
+		t.Run(from, func(t *testing.T) {
+			productRepo := newMockProductRepository()
+			cartRepo := newMockCartRepository(productRepo)
+			orderRepo := newMockOrderRepository()
+			orderService := NewOrderService(&mockTransactor{}, orderRepo, cartRepo, productRepo, newMockStoreRepository(), newMockAddressRepository(), nil, newTestPricingEngine(productRepo), NewOrderEventBroker())
+			ctx := context.Background()
+
+			product := &domain.Product{ID: uuid.New(), Name: "Quattro Formaggi", Price: domain.Cents(1300), Stock: 8}
+			_ = productRepo.Create(ctx, product)
+			order := placeTestOrder(t, cartRepo, orderService, product, 3)
+
+			if from == domain.OrderStatusConfirmed {
+				if _, err := orderService.TransitionStatus(ctx, order.ID, uuid.New(), staffAccess, from, ""); err != nil {
+					t.Fatalf("Confirm failed: %v", err)
+				}
+			}
+
+			cancelled, err := orderService.TransitionStatus(ctx, order.ID, uuid.New(), staffAccess, domain.OrderStatusCancelled, "customer request")
+			if err != nil {
+				t.Fatalf("Cancel failed: %v", err)
+			}
+			if cancelled.Status != domain.OrderStatusCancelled {
+				t.Errorf("Expected cancelled status, got %s", cancelled.Status)
+			}
+			if product.Stock != 8 {
+				t.Errorf("Expected stock restored to 8, got %d", product.Stock)
+			}
+		})
+	}
+}
+
+func TestTransitionStatus_Errors(t *testing.T) {
+	productRepo := newMockProductRepository()
+	cartRepo := newMockCartRepository(productRepo)
+	orderRepo := newMockOrderRepository()
+	orderService := NewOrderService(&mockTransactor{}, orderRepo, cartRepo, productRepo, newMockStoreRepository(), newMockAddressRepository(), nil, newTestPricingEngine(productRepo), NewOrderEventBroker())
+	ctx := context.Background()
+
+	if _, err := orderService.TransitionStatus(ctx, uuid.New(), uuid.New(), staffAccess, "lost", ""); err != ErrInvalidOrderStatus {
+		t.Errorf("Expected ErrInvalidOrderStatus, got %v", err)
+	}
+
+	if _, err := orderService.TransitionStatus(ctx, uuid.New(), uuid.New(), staffAccess, domain.OrderStatusConfirmed, ""); err != repository.ErrOrderNotFound {
+		t.Errorf("Expected ErrOrderNotFound, got %v", err)
+	}
+
+	product := &domain.Product{ID: uuid.New(), Name: "Marinara", Price: domain.Cents(700), Stock: 5}
+	_ = productRepo.Create(ctx, product)
+	order := placeTestOrder(t, cartRepo, orderService, product, 1)
+
+	_, err := orderService.TransitionStatus(ctx, order.ID, uuid.New(), staffAccess, domain.OrderStatusDelivered, "")
+	var transitionErr *OrderTransitionError
+	if !errors.As(err, &transitionErr) {
+		t.Fatalf("Expected OrderTransitionError, got %v", err)
+	}
+	if transitionErr.From != domain.OrderStatusPending || len(transitionErr.Allowed) != 2 {
+		t.Errorf("Unexpected transition error details: %+v", transitionErr)
+	}
+	if product.Stock != 4 {
+		t.Errorf("Rejected transition must not touch stock, got %d", product.Stock)
+	}
+}
+
+// Feature: ordering-platform, Property 76: Order history only lists the user's matching orders
+func TestProperty_OrderHistoryOnlyListsOwnMatchingOrders(t *testing.T) {
+	statuses := []string{domain.OrderStatusPending, domain.OrderStatusConfirmed, domain.OrderStatusCancelled}
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("listed orders belong to the user, match the filters and are newest first", prop.ForAll(
+		func(owners []bool, statusIdx []int, statusFilter int, pageSize int) bool {
+			orderRepo := newMockOrderRepository()
+			orderService := NewOrderService(&mockTransactor{}, orderRepo, nil, nil, newMockStoreRepository(), newMockAddressRepository(), nil, nil, NewOrderEventBroker())
+			userID := uuid.New()
+
+			expected := 0
+			wantStatus := statuses[statusFilter]
+			from := base.AddDate(0, 0, 2)
+			to := base.AddDate(0, 0, 8)
+			for i, own := range owners {
+				order := &domain.Order{
+					ID:        uuid.New(),
+					UserID:    uuid.New(),
+					Status:    statuses[statusIdx[i]],
+					CreatedAt: base.AddDate(0, 0, i),
+				}
+				if own {
+					order.UserID = userID
+				}
+				orderRepo.orders[order.ID] = order
+
+				if own && order.Status == wantStatus && !order.CreatedAt.Before(from) && order.CreatedAt.Before(to) {
+					expected++
+				}
+			}
+
+			opts := OrderListOptions{Status: wantStatus, CreatedFrom: &from, CreatedTo: &to, PageSize: pageSize}
+			seen := 0
+			for page := 1; ; page++ {
+				opts.Page = page
+				result, err := orderService.ListOrders(context.Background(), userID, opts)
+				if err != nil {
+					t.Logf("FAIL: ListOrders failed: %v", err)
+					return false
+				}
+				if result.Total != expected {
+					t.Logf("FAIL: Expected total %d, got %d", expected, result.Total)
+					return false
+				}
+
+				for i, order := range result.Orders {
+					if order.UserID != userID || order.Status != wantStatus {
+						t.Logf("FAIL: Order %s does not match filters", order.ID)
+						return false
+					}
+					if i > 0 && order.CreatedAt.After(result.Orders[i-1].CreatedAt) {
+						t.Logf("FAIL: Orders not sorted newest first")
+						return false
+					}
+				}
+
+				seen += len(result.Orders)
+				if page >= result.TotalPages {
+					break
+				}
+			}
+
+			return seen == expected
+		},
+		gen.SliceOfN(10, gen.Bool()),
+		gen.SliceOfN(10, gen.IntRange(0, len(statuses)-1)),
+		gen.IntRange(0, len(statuses)-1),
+		gen.IntRange(1, 4),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestListOrders_RejectsInvalidFilters(t *testing.T) {
+	orderService := NewOrderService(&mockTransactor{}, newMockOrderRepository(), nil, nil, newMockStoreRepository(), newMockAddressRepository(), nil, nil, NewOrderEventBroker())
+	ctx := context.Background()
+
+	if _, err := orderService.ListOrders(ctx, uuid.New(), OrderListOptions{Status: "lost"}); err != ErrInvalidOrderStatus {
+		t.Errorf("Expected ErrInvalidOrderStatus, got %v", err)
+	}
+
+	from := time.Now()
+	to := from.Add(-time.Hour)
+	if _, err := orderService.ListOrders(ctx, uuid.New(), OrderListOptions{CreatedFrom: &from, CreatedTo: &to}); err != ErrInvalidDateRange {
+		t.Errorf("Expected ErrInvalidDateRange, got %v", err)
+	}
+}
+
+func TestGetOrder_EnforcesOwnership(t *testing.T) {
+	productRepo := newMockProductRepository()
+	cartRepo := newMockCartRepository(productRepo)
+	orderRepo := newMockOrderRepository()
+	orderService := NewOrderService(&mockTransactor{}, orderRepo, cartRepo, productRepo, newMockStoreRepository(), newMockAddressRepository(), nil, newTestPricingEngine(productRepo), NewOrderEventBroker())
+	ctx := context.Background()
+
+	product := &domain.Product{ID: uuid.New(), Name: "Prosciutto", Price: domain.Cents(1200), Stock: 5}
+	_ = productRepo.Create(ctx, product)
+	order := placeTestOrder(t, cartRepo, orderService, product, 2)
+
+	owned, err := orderService.GetOrder(ctx, order.ID, order.UserID, nil)
+	if err != nil {
+		t.Fatalf("Owner could not get order: %v", err)
+	}
+	if len(owned.Items) != 1 || len(owned.History) != 1 || owned.History[0].ToStatus != domain.OrderStatusPending {
+		t.Errorf("Expected items and initial history, got %d items and %d history entries", len(owned.Items), len(owned.History))
+	}
+
+	if _, err := orderService.GetOrder(ctx, order.ID, uuid.New(), nil); err != repository.ErrOrderNotFound {
+		t.Errorf("Expected ErrOrderNotFound for another user, got %v", err)
+	}
+
+	if _, err := orderService.GetOrder(ctx, order.ID, uuid.New(), staffAccess); err != nil {
+		t.Errorf("Admin could not get order: %v", err)
+	}
+
+	otherStore := auth.Access{StorePermissions: map[uuid.UUID][]string{uuid.New(): {domain.PermissionOrdersRead}}}
+	if _, err := orderService.GetOrder(ctx, order.ID, uuid.New(), otherStore); err != repository.ErrOrderNotFound {
+		t.Errorf("Expected ErrOrderNotFound for staff of another store, got %v", err)
+	}
+
+	sameStore := auth.Access{StorePermissions: map[uuid.UUID][]string{order.StoreID: {domain.PermissionOrdersRead}}}
+	if _, err := orderService.GetOrder(ctx, order.ID, uuid.New(), sameStore); err != nil {
+		t.Errorf("Staff of the order's store could not get order: %v", err)
+	}
+}
+
+func TestTransitionStatus_StoreScopedPermissions(t *testing.T) {
+	productRepo := newMockProductRepository()
+	cartRepo := newMockCartRepository(productRepo)
+	orderRepo := newMockOrderRepository()
+	orderService := NewOrderService(&mockTransactor{}, orderRepo, cartRepo, productRepo, newMockStoreRepository(), newMockAddressRepository(), nil, newTestPricingEngine(productRepo), NewOrderEventBroker())
+	ctx := context.Background()
+
+	product := &domain.Product{ID: uuid.New(), Name: "Napoli", Price: domain.Cents(1000), Stock: 5}
+	_ = productRepo.Create(ctx, product)
+	order := placeTestOrder(t, cartRepo, orderService, product, 1)
+	if order.StoreID != testStoreID {
+		t.Fatalf("Expected the order to be placed at the cart's store, got %s", order.StoreID)
+	}
+
+	otherStore := auth.Access{StorePermissions: map[uuid.UUID][]string{uuid.New(): {domain.PermissionOrdersTransition}}}
+	if _, err := orderService.TransitionStatus(ctx, order.ID, uuid.New(), otherStore, domain.OrderStatusConfirmed, ""); err != repository.ErrOrderNotFound {
+		t.Errorf("Expected ErrOrderNotFound for staff of another store, got %v", err)
+	}
+
+	cook := auth.Access{StorePermissions: map[uuid.UUID][]string{order.StoreID: {domain.PermissionOrdersTransition}}}
+	if _, err := orderService.TransitionStatus(ctx, order.ID, uuid.New(), cook, domain.OrderStatusCancelled, ""); err != ErrInsufficientPermission {
+		t.Errorf("Expected ErrInsufficientPermission for a cancellation, got %v", err)
+	}
+	for _, status := range []string{domain.OrderStatusConfirmed, domain.OrderStatusShipped, domain.OrderStatusDelivered} {
+		if _, err := orderService.TransitionStatus(ctx, order.ID, uuid.New(), cook, status, ""); err != nil {
+			t.Fatalf("Staff of the order's store could not move it to %s: %v", status, err)
+		}
+	}
+
+	if _, err := orderService.TransitionStatus(ctx, order.ID, uuid.New(), cook, domain.OrderStatusRefunded, ""); err != ErrInsufficientPermission {
+		t.Errorf("Expected ErrInsufficientPermission for a refund, got %v", err)
+	}
+}
+
+func TestTransitionStatus_ClosesKitchenLines(t *testing.T) {
+	productRepo := newMockProductRepository()
+	cartRepo := newMockCartRepository(productRepo)
+	orderRepo := newMockOrderRepository()
+	orderService := NewOrderService(&mockTransactor{}, orderRepo, cartRepo, productRepo, newMockStoreRepository(), newMockAddressRepository(), nil, newTestPricingEngine(productRepo), NewOrderEventBroker())
+	ctx := context.Background()
+
+	// A manager finishing an order the kitchen was still preparing
+	preparing := newKitchenTestOrder(orderRepo, testStoreID, domain.OrderStatusPreparing, 2, time.Now())
+	preparing.Items[0].KitchenStatus = domain.KitchenItemPreparing
+	if _, err := orderService.TransitionStatus(ctx, preparing.ID, uuid.New(), staffAccess, domain.OrderStatusReady, ""); err != nil {
+		t.Fatalf("TransitionStatus: %v", err)
+	}
+	for _, item := range orderRepo.orders[preparing.ID].Items {
+		want := domain.KitchenItemReady
+		if item.Type != domain.OrderItemProduct {
+			want = ""
+		}
+		if item.KitchenStatus != want {
+			t.Errorf("expected %s line to be %q, got %q", item.Type, want, item.KitchenStatus)
+		}
+	}
+
+	// Cancelling takes the order's lines off the kitchen queue
+	product := &domain.Product{ID: uuid.New(), Name: "Napoli", Price: domain.Cents(1000), Stock: 5}
+	_ = productRepo.Create(ctx, product)
+	order := placeTestOrder(t, cartRepo, orderService, product, 1)
+	if _, err := orderService.TransitionStatus(ctx, order.ID, uuid.New(), staffAccess, domain.OrderStatusCancelled, ""); err != nil {
+		t.Fatalf("TransitionStatus: %v", err)
+	}
+	if status := orderRepo.orders[order.ID].Items[0].KitchenStatus; status != domain.KitchenItemReady {
+		t.Errorf("expected the cancelled order's line to be closed, got %q", status)
+	}
+}
+
+func TestCheckout_Delivery(t *testing.T) {
+	ctx := context.Background()
+	productRepo := newMockProductRepository()
+	cartRepo := newMockCartRepository(productRepo)
+	storeRepo := newMockStoreRepository()
+	addressRepo := newMockAddressRepository()
+	cartService := NewCartService(cartRepo, productRepo, newMockStoreRepository(), nil, newTestPricingEngine(productRepo))
+	orderService := NewOrderService(&mockTransactor{}, newMockOrderRepository(), cartRepo, productRepo, storeRepo, addressRepo, nil, newTestPricingEngine(productRepo), NewOrderEventBroker())
+	addressService := NewAddressService(addressRepo)
+
+	product := &domain.Product{ID: uuid.New(), Name: "Margherita", Price: domain.Cents(900), Stock: 10}
+	_ = productRepo.Create(ctx, product)
+	fillCart := func(userID uuid.UUID, quantity int) {
+		if _, err := cartService.AddItem(ctx, userID, product.ID, nil, quantity); err != nil {
+			t.Fatalf("AddItem failed: %v", err)
+		}
+	}
+
+	_ = storeRepo.CreateDeliveryZone(ctx, &domain.DeliveryZone{
+		ID: uuid.New(), StoreID: testStoreID, Name: "Centre", RadiusMeters: 1000,
+		MinOrderValue: domain.Cents(1500), DeliveryFee: domain.Cents(250), Active: true,
+	})
+	_ = storeRepo.CreateDeliveryZone(ctx, &domain.DeliveryZone{
+		ID: uuid.New(), StoreID: testStoreID, Name: "City", RadiusMeters: 5000,
+		MinOrderValue: domain.Cents(1500), DeliveryFee: domain.Cents(500), Active: true,
+	})
+
+	userID := uuid.New()
+	near, _ := addressService.CreateAddress(ctx, userID, testAddressInput())
+	farInput := testAddressInput()
+	farInput.Latitude = 42.5
+	far, _ := addressService.CreateAddress(ctx, userID, farInput)
+	strangersAddress, _ := addressService.CreateAddress(ctx, uuid.New(), testAddressInput())
+
+	fillCart(userID, 1)
+	failures := []struct {
+		name string
+		opts CheckoutOptions
+		want error
+	}{
+		{"delivery without address", CheckoutOptions{Fulfillment: domain.FulfillmentDelivery}, ErrInvalidFulfillment},
+		{"pickup with address", CheckoutOptions{Fulfillment: domain.FulfillmentPickup, AddressID: &near.ID}, ErrInvalidFulfillment},
+		{"unknown fulfillment", CheckoutOptions{Fulfillment: "drone", AddressID: &near.ID}, ErrInvalidFulfillment},
+		{"another user's address", CheckoutOptions{Fulfillment: domain.FulfillmentDelivery, AddressID: &strangersAddress.ID}, repository.ErrAddressNotFound},
+		{"outside every zone", CheckoutOptions{Fulfillment: domain.FulfillmentDelivery, AddressID: &far.ID}, ErrAddressNotServed},
+		{"below the minimum", CheckoutOptions{Fulfillment: domain.FulfillmentDelivery, AddressID: &near.ID}, ErrBelowDeliveryMinimum},
+	}
+	for _, tt := range failures {
+		if _, err := orderService.Checkout(ctx, userID, tt.opts); !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+	}
+
+	fillCart(userID, 1)
+	order, err := orderService.Checkout(ctx, userID, CheckoutOptions{Fulfillment: domain.FulfillmentDelivery, AddressID: &near.ID})
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if order.Fulfillment != domain.FulfillmentDelivery || order.DeliveryAddress == nil || order.DeliveryAddress.ID != near.ID {
+		t.Errorf("expected a delivery to the near address, got %+v", order)
+	}
+	if len(order.Items) != 2 || order.Items[1].Type != domain.OrderItemDeliveryFee || order.Items[1].Subtotal != domain.Cents(250) {
+		t.Fatalf("expected the centre zone's fee as a separate line, got %+v", order.Items)
+	}
+	if order.Subtotal != domain.Cents(1800) || order.Total != domain.Cents(2050) {
+		t.Errorf("expected subtotal 18.00 and total 20.50, got %s and %s", order.Subtotal, order.Total)
+	}
+
+	// Cancelling restores the stock of product lines only
+	if _, err := orderService.TransitionStatus(ctx, order.ID, uuid.New(), staffAccess, domain.OrderStatusCancelled, ""); err != nil {
+		t.Fatalf("TransitionStatus: %v", err)
+	}
+	if product.Stock != 10 {
+		t.Errorf("expected the stock back at 10, got %d", product.Stock)
+	}
+
+	fillCart(userID, 1)
+	pickup, err := orderService.Checkout(ctx, userID, CheckoutOptions{})
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if pickup.Fulfillment != domain.FulfillmentPickup || pickup.DeliveryAddress != nil || len(pickup.Items) != 1 {
+		t.Errorf("expected a pickup order without charges, got %+v", pickup)
+	}
+}
+
+func TestCheckout_DeliveryFromServingStore(t *testing.T) {
+	ctx := context.Background()
+	productRepo := newMockProductRepository()
+	cartRepo := newMockCartRepository(productRepo)
+	storeRepo := newMockStoreRepository()
+	addressRepo := newMockAddressRepository()
+	cartService := NewCartService(cartRepo, productRepo, newMockStoreRepository(), nil, newTestPricingEngine(productRepo))
+	orderService := NewOrderService(&mockTransactor{}, newMockOrderRepository(), cartRepo, productRepo, storeRepo, addressRepo, nil, newTestPricingEngine(productRepo), NewOrderEventBroker())
+	addressService := NewAddressService(addressRepo)
+
+	product := &domain.Product{ID: uuid.New(), Name: "Margherita", Price: domain.Cents(900), Stock: 10}
+	_ = productRepo.Create(ctx, product)
+
+	// The cart's store only serves the centre, two stores further north reach the far address
+	_ = storeRepo.CreateDeliveryZone(ctx, &domain.DeliveryZone{
+		ID: uuid.New(), StoreID: testStoreID, Name: "Centre", RadiusMeters: 1000,
+		DeliveryFee: domain.Cents(250), Active: true,
+	})
+	northStores := make([]*domain.Store, 2)
+	for i, fee := range []int64{400, 300} {
+		store := &domain.Store{
+			ID: uuid.New(), Name: "North", Latitude: 42.5 + float64(i)*0.01, Longitude: 12.4964,
+			Timezone: "UTC", Active: true, SlotMinutes: domain.DefaultSlotMinutes, SlotCapacity: domain.DefaultSlotCapacity,
+			OpeningHours: alwaysOpenHours(), HoursExceptions: []*domain.HoursException{},
+		}
+		_ = storeRepo.Create(ctx, store)
+		_ = storeRepo.CreateDeliveryZone(ctx, &domain.DeliveryZone{
+			ID: uuid.New(), StoreID: store.ID, Name: "North", RadiusMeters: 5000,
+			DeliveryFee: domain.Cents(fee), Active: true,
+		})
+		northStores[i] = store
+	}
+
+	userID := uuid.New()
+	near, _ := addressService.CreateAddress(ctx, userID, testAddressInput())
+	farInput := testAddressInput()
+	farInput.Latitude = 42.5
+	far, _ := addressService.CreateAddress(ctx, userID, farInput)
+
+	if _, err := cartService.AddItem(ctx, userID, product.ID, nil, 1); err != nil {
+		t.Fatalf("AddItem failed: %v", err)
+	}
+	order, err := orderService.Checkout(ctx, userID, CheckoutOptions{Fulfillment: domain.FulfillmentDelivery, AddressID: &far.ID})
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if order.StoreID != northStores[1].ID || cartRepo.checkoutStore != northStores[1].ID {
+		t.Errorf("expected the cheapest serving store to price and place the order, got order at %s priced at %s", order.StoreID, cartRepo.checkoutStore)
+	}
+	if len(order.Items) != 2 || order.Items[1].Subtotal != domain.Cents(300) {
+		t.Errorf("expected the serving zone's fee, got %+v", order.Items)
+	}
+
+	// The cart's store keeps the order when it serves the address
+	if _, err := cartService.AddItem(ctx, userID, product.ID, nil, 1); err != nil {
+		t.Fatalf("AddItem failed: %v", err)
+	}
+	order, err = orderService.Checkout(ctx, userID, CheckoutOptions{Fulfillment: domain.FulfillmentDelivery, AddressID: &near.ID})
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if order.StoreID != testStoreID || cartRepo.checkoutStore != testStoreID {
+		t.Errorf("expected the order at the cart's store, got %s", order.StoreID)
+	}
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/mailer"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidResetToken      = errors.New("invalid or expired password reset token")
+	ErrPasswordResetThrottled = errors.New("too many password reset requests")
+)
+
+// PasswordResetThrottledError reports that a client IP made too many reset requests. It
+// matches ErrPasswordResetThrottled with errors.Is.
+type PasswordResetThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *PasswordResetThrottledError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", ErrPasswordResetThrottled, e.RetryAfter.Round(time.Second))
+}
+
+func (e *PasswordResetThrottledError) Is(target error) bool {
+	return target == ErrPasswordResetThrottled
+}
+
+// PasswordResetConfig controls the reset links mailed to users
+type PasswordResetConfig struct {
+	// TokenTTL is how long a reset link stays usable
+	TokenTTL time.Duration
+	// ResetURL is the page users open to choose a new password; the token is added to it
+	// as the token query parameter
+	ResetURL string
+	// RequestCooldown is the minimum time between two reset emails to the same account
+	RequestCooldown time.Duration
+	// IPRequestLimit is how many reset requests a client IP may make within IPRequestWindow;
+	// zero disables the limit
+	IPRequestLimit int
+	// IPRequestWindow is how long a client IP must stop requesting resets for its count
+	// to restart
+	IPRequestWindow time.Duration
+}
+
+// PasswordResetService defines the interface for recovering an account with a lost password
+type PasswordResetService interface {
+	CountRequest(ctx context.Context, ipAddress string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+}
+
+type passwordResetService struct {
+	userRepo         repository.UserRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	resetTokenRepo   repository.PasswordResetTokenRepository
+	throttleRepo     repository.LoginThrottleRepository
+	mailer           mailer.Mailer
+	config           PasswordResetConfig
+}
+
+// NewPasswordResetService creates a new instance of PasswordResetService
+func NewPasswordResetService(
+	userRepo repository.UserRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	resetTokenRepo repository.PasswordResetTokenRepository,
+	throttleRepo repository.LoginThrottleRepository,
+	mailer mailer.Mailer,
+	config PasswordResetConfig,
+) PasswordResetService {
+	return &passwordResetService{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		resetTokenRepo:   resetTokenRepo,
+		throttleRepo:     throttleRepo,
+		mailer:           mailer,
+		config:           config,
+	}
+}
+
+// CountRequest counts a reset request from a client IP whatever the email asked for, and
+// returns a PasswordResetThrottledError once the IP has made more than the limit. The count
+// restarts after the IP has made no request for the window.
+func (s *passwordResetService) CountRequest(ctx context.Context, ipAddress string) error {
+	if s.config.IPRequestLimit <= 0 || ipAddress == "" {
+		return nil
+	}
+
+	now := time.Now()
+	throttle, err := s.throttleRepo.RecordFailure(ctx, domain.LoginThrottleResetIP, ipAddress, now, now.Add(-s.config.IPRequestWindow))
+	if err != nil {
+		return fmt.Errorf("failed to count reset request: %w", err)
+	}
+	if throttle.Failures > s.config.IPRequestLimit {
+		return &PasswordResetThrottledError{RetryAfter: s.config.IPRequestWindow}
+	}
+
+	return nil
+}
+
+// RequestPasswordReset mails a single-use reset link to the account registered with email,
+// at most once per request cooldown; earlier links keep working until they expire. Unknown
+// emails and requests within the cooldown are not errors, so callers cannot tell which
+// emails have accounts. Callers should not wait for it before responding, since looking up
+// the account and sending the mail take longer for known emails.
+func (s *passwordResetService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	latest, err := s.resetTokenRepo.FindLatestForUser(ctx, user.ID)
+	if err != nil && err != repository.ErrPasswordResetTokenNotFound {
+		return fmt.Errorf("failed to check previous reset email: %w", err)
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < s.config.RequestCooldown {
+		return nil
+	}
+
+	tokenString, err := randomToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	link, err := linkWithToken(s.config.ResetURL, tokenString)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	resetToken := &domain.PasswordResetToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		TokenHash: hashToken(tokenString),
+		ExpiresAt: now.Add(s.config.TokenTTL),
+		CreatedAt: now,
+	}
+	if err := s.resetTokenRepo.Create(ctx, resetToken); err != nil {
+		return fmt.Errorf("failed to store reset token: %w", err)
+	}
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nOpen the link below to choose a new password. It expires in %s and can only be used once.\n\n%s\n\nIf you did not ask to reset your password, you can ignore this email.\n",
+			user.FirstName, s.config.TokenTTL, link,
+		),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("failed to send reset email: %w", err)
+	}
+
+	return nil
+}
+
+// ResetPassword redeems a reset token and sets the user's new password. Every other reset
+// token of the user stops working and every session is signed out.
+func (s *passwordResetService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	now := time.Now()
+	resetToken, err := s.resetTokenRepo.Consume(ctx, hashToken(token), now)
+	if err != nil {
+		if err == repository.ErrPasswordResetTokenNotFound {
+			return ErrInvalidResetToken
+		}
+		return fmt.Errorf("failed to redeem reset token: %w", err)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), BcryptCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, resetToken.UserID, string(hashedPassword)); err != nil {
+		if err == repository.ErrUserNotFound {
+			return ErrInvalidResetToken
+		}
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if err := s.resetTokenRepo.InvalidateForUser(ctx, resetToken.UserID, now); err != nil {
+		return fmt.Errorf("failed to invalidate reset tokens: %w", err)
+	}
+	if err := s.refreshTokenRepo.RevokeAllForUser(ctx, resetToken.UserID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/mailer"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+type mockPasswordResetTokenRepository struct {
+	tokens map[string]*domain.PasswordResetToken
+}
+
+func newMockPasswordResetTokenRepository() *mockPasswordResetTokenRepository {
+	return &mockPasswordResetTokenRepository{
+		tokens: make(map[string]*domain.PasswordResetToken),
+	}
+}
+
+func (m *mockPasswordResetTokenRepository) Create(ctx context.Context, token *domain.PasswordResetToken) error {
+	m.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (m *mockPasswordResetTokenRepository) Consume(ctx context.Context, tokenHash string, now time.Time) (*domain.PasswordResetToken, error) {
+	token, exists := m.tokens[tokenHash]
+	if !exists || token.UsedAt != nil || !token.ExpiresAt.After(now) {
+		return nil, repository.ErrPasswordResetTokenNotFound
+	}
+	token.UsedAt = &now
+	return token, nil
+}
+
+func (m *mockPasswordResetTokenRepository) FindLatestForUser(ctx context.Context, userID uuid.UUID) (*domain.PasswordResetToken, error) {
+	var latest *domain.PasswordResetToken
+	for _, token := range m.tokens {
+		if token.UserID == userID && (latest == nil || token.CreatedAt.After(latest.CreatedAt)) {
+			latest = token
+		}
+	}
+	if latest == nil {
+		return nil, repository.ErrPasswordResetTokenNotFound
+	}
+	return latest, nil
+}
+
+func (m *mockPasswordResetTokenRepository) InvalidateForUser(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	for _, token := range m.tokens {
+		if token.UserID == userID && token.UsedAt == nil {
+			token.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+// recordingMailer keeps every message it is asked to send
+type recordingMailer struct {
+	sent []mailer.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+var mailLinkPattern = regexp.MustCompile(`https://\S+`)
+
+// tokenFromMail extracts the token from the link in a reset or verification email
+func tokenFromMail(t *testing.T, msg mailer.Message) string {
+	t.Helper()
+	link, err := url.Parse(mailLinkPattern.FindString(msg.Body))
+	if err != nil {
+		t.Fatalf("email has no valid link: %v", err)
+	}
+	return link.Query().Get("token")
+}
+
+type passwordResetFixture struct {
+	users         UserService
+	resets        PasswordResetService
+	resetTokens   *mockPasswordResetTokenRepository
+	refreshTokens *mockRefreshTokenRepository
+	mail          *recordingMailer
+}
+
+// newPasswordResetFixture returns a fixture without reset cooldown or client IP limit
+func newPasswordResetFixture() *passwordResetFixture {
+	return newLimitedPasswordResetFixture(0, 0)
+}
+
+// newLimitedPasswordResetFixture returns a fixture allowing one reset email per account per
+// cooldown and ipLimit requests per client IP within 15 minutes
+func newLimitedPasswordResetFixture(cooldown time.Duration, ipLimit int) *passwordResetFixture {
+	userRepo := newMockUserRepository()
+	f := &passwordResetFixture{
+		resetTokens:   newMockPasswordResetTokenRepository(),
+		refreshTokens: newMockRefreshTokenRepository(),
+		mail:          &recordingMailer{},
+	}
+	f.users = NewUserService(&mockTransactor{}, userRepo, newMockRoleRepository(), f.refreshTokens, newTestTokenManager(), DefaultTokenPolicy(), newTestLoginThrottle())
+	f.resets = NewPasswordResetService(userRepo, f.refreshTokens, f.resetTokens, newMockLoginThrottleRepository(), f.mail, PasswordResetConfig{
+		TokenTTL:        30 * time.Minute,
+		ResetURL:        "https://pizza.example/reset?lang=en",
+		RequestCooldown: cooldown,
+		IPRequestLimit:  ipLimit,
+		IPRequestWindow: 15 * time.Minute,
+	})
+	return f
+}
+
+// Feature: ordering-platform, Property 86: Password reset tokens work once and sign out every session
+// Validates: Requirements 2.1, 3.1
+func TestProperty_PasswordResetTokensWorkOnce(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("a reset token sets the password once and revokes all sessions", prop.ForAll(
+		func(email string, oldPassword string, newPassword string, devices int) bool {
+			// Setup
+			f := newPasswordResetFixture()
+			ctx := context.Background()
+
+			if _, err := f.users.Register(ctx, email, oldPassword, "Test", "User"); err != nil {
+				return true // Skip if registration fails
+			}
+			refreshTokens := make([]string, devices)
+			for i := range refreshTokens {
+				_, refreshToken, _, err := f.users.Login(ctx, email, oldPassword, ClientInfo{})
+				if err != nil {
+					t.Logf("FAIL: Login failed: %v", err)
+					return false
+				}
+				refreshTokens[i] = refreshToken
+			}
+
+			// Request two reset links; only the digests are stored
+			for i := 0; i < 2; i++ {
+				if err := f.resets.RequestPasswordReset(ctx, email); err != nil {
+					t.Logf("FAIL: RequestPasswordReset failed: %v", err)
+					return false
+				}
+			}
+			if len(f.mail.sent) != 2 || f.mail.sent[0].To != email {
+				t.Logf("FAIL: Expected two reset emails to %s, got %v", email, f.mail.sent)
+				return false
+			}
+			first, second := tokenFromMail(t, f.mail.sent[0]), tokenFromMail(t, f.mail.sent[1])
+			if _, exists := f.resetTokens.tokens[first]; exists {
+				t.Logf("FAIL: Reset token stored in plaintext")
+				return false
+			}
+
+			if err := f.resets.ResetPassword(ctx, first, newPassword); err != nil {
+				t.Logf("FAIL: ResetPassword failed: %v", err)
+				return false
+			}
+
+			// The token cannot be reused and the other outstanding token no longer works
+			if err := f.resets.ResetPassword(ctx, first, oldPassword); err != ErrInvalidResetToken {
+				t.Logf("FAIL: Reused reset token should be rejected, got: %v", err)
+				return false
+			}
+			if err := f.resets.ResetPassword(ctx, second, oldPassword); err != ErrInvalidResetToken {
+				t.Logf("FAIL: Outstanding reset token should be invalidated, got: %v", err)
+				return false
+			}
+
+			// Every session is signed out and only the new password works
+			for _, refreshToken := range refreshTokens {
+				if _, _, err := f.users.RefreshToken(ctx, refreshToken, ClientInfo{}); err == nil {
+					t.Logf("FAIL: Session survived the password reset")
+					return false
+				}
+			}
+			if _, _, _, err := f.users.Login(ctx, email, oldPassword, ClientInfo{}); err != ErrInvalidCredentials {
+				t.Logf("FAIL: Old password should be rejected, got: %v", err)
+				return false
+			}
+			if _, _, _, err := f.users.Login(ctx, email, newPassword, ClientInfo{}); err != nil {
+				t.Logf("FAIL: New password should be accepted: %v", err)
+				return false
+			}
+
+			return true
+		},
+		gen.RegexMatch(`[a-z]{3,10}@[a-z]{3,8}\.(com|org|net)`),
+		gen.RegexMatch(`[A-Za-z0-9!@#$%]{8,20}`),
+		gen.RegexMatch(`[A-Za-z0-9!@#$%]{21,30}`),
+		gen.IntRange(1, 3),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestRequestPasswordReset_UnknownEmailSendsNothing(t *testing.T) {
+	f := newPasswordResetFixture()
+
+	if err := f.resets.RequestPasswordReset(context.Background(), "nobody@example.com"); err != nil {
+		t.Fatalf("expected no error for an unknown email, got %v", err)
+	}
+	if len(f.mail.sent) != 0 || len(f.resetTokens.tokens) != 0 {
+		t.Errorf("expected no email and no token, got %d emails and %d tokens", len(f.mail.sent), len(f.resetTokens.tokens))
+	}
+}
+
+func TestRequestPasswordReset_CooldownPerAccount(t *testing.T) {
+	f := newLimitedPasswordResetFixture(time.Minute, 0)
+	ctx := context.Background()
+
+	if _, err := f.users.Register(ctx, "flooded@example.com", "password123", "Test", "User"); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := f.resets.RequestPasswordReset(ctx, "flooded@example.com"); err != nil {
+			t.Fatalf("expected requests within the cooldown to be ignored, got %v", err)
+		}
+	}
+	if len(f.mail.sent) != 1 {
+		t.Fatalf("expected one email within the cooldown, got %d", len(f.mail.sent))
+	}
+
+	// Once the cooldown has passed another link is sent
+	for _, token := range f.resetTokens.tokens {
+		token.CreatedAt = token.CreatedAt.Add(-time.Minute)
+	}
+	if err := f.resets.RequestPasswordReset(ctx, "flooded@example.com"); err != nil {
+		t.Fatalf("RequestPasswordReset failed: %v", err)
+	}
+	if len(f.mail.sent) != 2 {
+		t.Errorf("expected a second email after the cooldown, got %d", len(f.mail.sent))
+	}
+}
+
+func TestCountRequest_LimitsRequestsPerClientIP(t *testing.T) {
+	f := newLimitedPasswordResetFixture(0, 2)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := f.resets.CountRequest(ctx, "203.0.113.7"); err != nil {
+			t.Fatalf("expected request %d to be allowed, got %v", i+1, err)
+		}
+	}
+	err := f.resets.CountRequest(ctx, "203.0.113.7")
+	var throttled *PasswordResetThrottledError
+	if !errors.As(err, &throttled) || !errors.Is(err, ErrPasswordResetThrottled) || throttled.RetryAfter != 15*time.Minute {
+		t.Fatalf("expected the third request to be throttled, got %v", err)
+	}
+
+	// Other client IPs are counted separately
+	if err := f.resets.CountRequest(ctx, "198.51.100.1"); err != nil {
+		t.Errorf("expected another IP to be allowed, got %v", err)
+	}
+}
+
+func TestResetPassword_RejectsExpiredToken(t *testing.T) {
+	f := newPasswordResetFixture()
+	ctx := context.Background()
+
+	if _, err := f.users.Register(ctx, "reset@example.com", "password123", "Test", "User"); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := f.resets.RequestPasswordReset(ctx, "reset@example.com"); err != nil {
+		t.Fatalf("RequestPasswordReset failed: %v", err)
+	}
+	token := tokenFromMail(t, f.mail.sent[0])
+	f.resetTokens.tokens[hashToken(token)].ExpiresAt = time.Now().Add(-time.Minute)
+
+	if err := f.resets.ResetPassword(ctx, token, "newpassword123"); err != ErrInvalidResetToken {
+		t.Errorf("expected ErrInvalidResetToken, got %v", err)
+	}
+	if err := f.resets.ResetPassword(ctx, "not-a-token", "newpassword123"); err != ErrInvalidResetToken {
+		t.Errorf("expected ErrInvalidResetToken for an unknown token, got %v", err)
+	}
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// An option covering only one half of the pizza is charged
+// HalfPlacementPriceNumerator/HalfPlacementPriceDenominator of its price delta
+const (
+	HalfPlacementPriceNumerator   = 1
+	HalfPlacementPriceDenominator = 2
+)
+
+// MaxQuantity is the most units of one product a quote or cart line may hold. The quantity
+// fields of the request payloads check the same limit.
+const MaxQuantity = 99
+
+var (
+	ErrInvalidOptionSelection = errors.New("invalid option selection")
+)
+
+// PricingEngine prices products with their chosen options. Quotes, cart lines and checkout
+// all go through the engine so a quoted price is always the price charged.
+type PricingEngine interface {
+	Quote(ctx context.Context, storeID *uuid.UUID, productID uuid.UUID, selections []domain.OptionSelection, quantity int) (*domain.PriceQuote, error)
+	PriceCartLines(ctx context.Context, lines []*domain.CartLine) error
+}
+
+type pricingEngine struct {
+	productRepo repository.ProductRepository
+	optionRepo  repository.ProductOptionRepository
+}
+
+// NewPricingEngine creates a new instance of PricingEngine
+func NewPricingEngine(productRepo repository.ProductRepository, optionRepo repository.ProductOptionRepository) PricingEngine {
+	return &pricingEngine{
+		productRepo: productRepo,
+		optionRepo:  optionRepo,
+	}
+}
+
+// Quote prices quantity units of a product with the given selections at current prices.
+// With a store, the store's price is used and the product must be available there.
+func (e *pricingEngine) Quote(ctx context.Context, storeID *uuid.UUID, productID uuid.UUID, selections []domain.OptionSelection, quantity int) (*domain.PriceQuote, error) {
+	if quantity < 1 || quantity > MaxQuantity {
+		return nil, ErrInvalidQuantity
+	}
+
+	product, err := findProduct(ctx, e.productRepo, storeID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := e.optionRepo.ListByProduct(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product options: %w", err)
+	}
+
+	return priceQuote(product.ID, product.Name, product.Price, groups, selections, quantity)
+}
+
+// PriceCartLines prices every line against the product's current option groups and fills
+// in the resolved options, unit price and subtotal
+func (e *pricingEngine) PriceCartLines(ctx context.Context, lines []*domain.CartLine) error {
+	groupsByProduct := make(map[uuid.UUID][]*domain.OptionGroup)
+
+	for _, line := range lines {
+		groups, loaded := groupsByProduct[line.ProductID]
+		if !loaded {
+			var err error
+			groups, err = e.optionRepo.ListByProduct(ctx, line.ProductID)
+			if err != nil {
+				return fmt.Errorf("failed to get product options: %w", err)
+			}
+			groupsByProduct[line.ProductID] = groups
+		}
+
+		quote, err := priceQuote(line.ProductID, line.ProductName, line.BasePrice, groups, line.Selections, line.Quantity)
+		if err != nil {
+			return fmt.Errorf("%s: %w", line.ProductName, err)
+		}
+
+		line.Options = quote.Options
+		line.UnitPrice = quote.UnitPrice
+		line.Subtotal = quote.Total
+	}
+
+	return nil
+}
+
+// findProduct retrieves a product, at a store's price and stock when storeID is set, passing
+// ErrProductNotFound and ErrProductUnavailable through unwrapped
+func findProduct(ctx context.Context, productRepo repository.ProductRepository, storeID *uuid.UUID, productID uuid.UUID) (*domain.Product, error) {
+	var product *domain.Product
+	var err error
+	if storeID != nil {
+		product, err = productRepo.FindForStore(ctx, *storeID, productID)
+	} else {
+		product, err = productRepo.FindByID(ctx, productID)
+	}
+
+	if err != nil {
+		if err == repository.ErrProductNotFound || err == repository.ErrProductUnavailable {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	return product, nil
+}
+
+// priceQuote resolves selections against groups and computes the unit price as the base
+// price plus the charge of every selected option. Options may lower the price, but a
+// selection bringing the unit price below zero is rejected. ErrInvalidQuantity is returned
+// when the total does not fit in an amount.
+func priceQuote(productID uuid.UUID, name string, basePrice domain.Money, groups []*domain.OptionGroup, selections []domain.OptionSelection, quantity int) (*domain.PriceQuote, error) {
+	selected, err := resolveSelections(groups, selections)
+	if err != nil {
+		return nil, err
+	}
+
+	unitPrice := basePrice
+	for _, option := range selected {
+		unitPrice = unitPrice.Add(option.PriceDelta)
+	}
+	if unitPrice.IsNegative() {
+		return nil, fmt.Errorf("%w: the selected options bring the price of %q below zero", ErrInvalidOptionSelection, name)
+	}
+	total, err := unitPrice.Mul(int64(quantity))
+	if err != nil {
+		return nil, ErrInvalidQuantity
+	}
+
+	return &domain.PriceQuote{
+		ProductID:   productID,
+		ProductName: name,
+		BasePrice:   basePrice,
+		Options:     selected,
+		UnitPrice:   unitPrice,
+		Quantity:    quantity,
+		Total:       total,
+	}, nil
+}
+
+// resolveSelections checks selections against a product's option groups and returns the
+// selected options in display order with the amount charged for each.
+//   - every option must belong to one of the groups and may be chosen only once
+//   - half placements are only allowed in groups that allow halves and cost the half
+//     placement share of the option's delta, rounded to the nearest cent
+//   - each half of the pizza must receive between MinSelect and MaxSelect options from
+//     every group; whole options count towards both halves
+func resolveSelections(groups []*domain.OptionGroup, selections []domain.OptionSelection) ([]domain.SelectedOption, error) {
+	placements := make(map[uuid.UUID]string, len(selections))
+	for _, selection := range selections {
+		if _, exists := placements[selection.OptionID]; exists {
+			return nil, fmt.Errorf("%w: option %s selected more than once", ErrInvalidOptionSelection, selection.OptionID)
+		}
+
+		placement := normalizePlacement(selection.Placement)
+		if placement != domain.PlacementWhole && placement != domain.PlacementLeft && placement != domain.PlacementRight {
+			return nil, fmt.Errorf("%w: unknown placement %q", ErrInvalidOptionSelection, selection.Placement)
+		}
+		placements[selection.OptionID] = placement
+	}
+
+	selected := []domain.SelectedOption{}
+	for _, group := range groups {
+		left, right := 0, 0
+		for _, option := range group.Options {
+			placement, chosen := placements[option.ID]
+			if !chosen {
+				continue
+			}
+			delete(placements, option.ID)
+
+			charge := option.PriceDelta
+			switch placement {
+			case domain.PlacementWhole:
+				left++
+				right++
+			case domain.PlacementLeft, domain.PlacementRight:
+				if !group.AllowHalves {
+					return nil, fmt.Errorf("%w: %q cannot be placed on half of the pizza", ErrInvalidOptionSelection, group.Name)
+				}
+				if placement == domain.PlacementLeft {
+					left++
+				} else {
+					right++
+				}
+				var err error
+				charge, err = option.PriceDelta.MulRatio(HalfPlacementPriceNumerator, HalfPlacementPriceDenominator)
+				if err != nil {
+					return nil, fmt.Errorf("%w: the price of %q is out of range", ErrInvalidOptionSelection, option.Name)
+				}
+			}
+
+			selected = append(selected, domain.SelectedOption{
+				GroupID:    group.ID,
+				GroupName:  group.Name,
+				OptionID:   option.ID,
+				OptionName: option.Name,
+				Placement:  placement,
+				PriceDelta: charge,
+			})
+		}
+
+		if left < group.MinSelect || right < group.MinSelect {
+			return nil, fmt.Errorf("%w: choose at least %d from %q", ErrInvalidOptionSelection, group.MinSelect, group.Name)
+		}
+		if left > group.MaxSelect || right > group.MaxSelect {
+			return nil, fmt.Errorf("%w: choose at most %d from %q", ErrInvalidOptionSelection, group.MaxSelect, group.Name)
+		}
+	}
+
+	for id := range placements {
+		return nil, fmt.Errorf("%w: option %s does not belong to this product", ErrInvalidOptionSelection, id)
+	}
+
+	return selected, nil
+}
+
+// normalizeSelections returns a sorted copy of selections with explicit placements so
+// equal choices compare equal
+func normalizeSelections(selections []domain.OptionSelection) []domain.OptionSelection {
+	normalized := make([]domain.OptionSelection, 0, len(selections))
+	for _, selection := range selections {
+		normalized = append(normalized, domain.OptionSelection{
+			OptionID:  selection.OptionID,
+			Placement: normalizePlacement(selection.Placement),
+		})
+	}
+
+	sort.Slice(normalized, func(i, j int) bool {
+		if c := bytes.Compare(normalized[i].OptionID[:], normalized[j].OptionID[:]); c != 0 {
+			return c < 0
+		}
+		return normalized[i].Placement < normalized[j].Placement
+	})
+	return normalized
+}
+
+// sameSelections reports whether two normalized selections are identical
+func sameSelections(a, b []domain.OptionSelection) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizePlacement maps an empty placement to the whole pizza
+func normalizePlacement(placement string) string {
+	if placement == "" {
+		return domain.PlacementWhole
+	}
+	return placement
+}
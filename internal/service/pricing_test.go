@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// newTestPricingEngine builds a PricingEngine for products without option groups
+func newTestPricingEngine(productRepo *mockProductRepository) PricingEngine {
+	return NewPricingEngine(productRepo, newMockProductOptionRepository())
+}
+
+// wholeSelections selects every option for the whole pizza
+func wholeSelections(ids ...uuid.UUID) []domain.OptionSelection {
+	selections := make([]domain.OptionSelection, 0, len(ids))
+	for _, id := range ids {
+		selections = append(selections, domain.OptionSelection{OptionID: id})
+	}
+	return selections
+}
+
+// Feature: ordering-platform, Property 77: Option selections follow group rules and price deltas
+func TestProperty_OptionSelectionsFollowGroupRules(t *testing.T) {
+	placements := []string{"", domain.PlacementLeft, domain.PlacementRight}
+
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("valid selections are priced as base plus charges and invalid ones are rejected", prop.ForAll(
+		func(cents int64, size int, crust int, toppings []int) bool {
+			groups, err := newOptionGroups(uuid.New(), pizzaOptionGroups())
+			if err != nil {
+				t.Logf("FAIL: newOptionGroups failed: %v", err)
+				return false
+			}
+			basePrice := domain.Cents(cents)
+
+			// size and crust of -1 leave the required group empty; topping -1 skips the topping
+			var selections []domain.OptionSelection
+			expected := basePrice
+			if size >= 0 {
+				selections = append(selections, domain.OptionSelection{OptionID: groups[0].Options[size].ID})
+				expected = expected.Add(groups[0].Options[size].PriceDelta)
+			}
+			if crust >= 0 {
+				selections = append(selections, domain.OptionSelection{OptionID: groups[1].Options[crust].ID})
+				expected = expected.Add(groups[1].Options[crust].PriceDelta)
+			}
+			left, right := 0, 0
+			for i, placement := range toppings {
+				if placement < 0 {
+					continue
+				}
+				option := groups[2].Options[i]
+				selections = append(selections, domain.OptionSelection{OptionID: option.ID, Placement: placements[placement]})
+				switch placements[placement] {
+				case "":
+					left++
+					right++
+					expected = expected.Add(option.PriceDelta)
+				case domain.PlacementLeft:
+					left++
+					half, _ := option.PriceDelta.MulRatio(1, 2)
+					expected = expected.Add(half)
+				case domain.PlacementRight:
+					right++
+					half, _ := option.PriceDelta.MulRatio(1, 2)
+					expected = expected.Add(half)
+				}
+			}
+
+			quote, err := priceQuote(uuid.New(), "Custom", basePrice, groups, normalizeSelections(selections), 2)
+
+			if size < 0 || crust < 0 || left > groups[2].MaxSelect || right > groups[2].MaxSelect {
+				return errors.Is(err, ErrInvalidOptionSelection)
+			}
+			if err != nil {
+				t.Logf("FAIL: Valid selection rejected: %v", err)
+				return false
+			}
+			if len(quote.Options) != len(selections) {
+				t.Logf("FAIL: Expected %d selected options, got %d", len(selections), len(quote.Options))
+				return false
+			}
+
+			if quote.UnitPrice != expected {
+				t.Logf("FAIL: Unit price mismatch. Expected %s, got %s", expected, quote.UnitPrice)
+				return false
+			}
+
+			return quote.Total == quote.UnitPrice.Add(quote.UnitPrice)
+		},
+		gen.Int64Range(500, 2000),
+		gen.IntRange(-1, 2),
+		gen.IntRange(-1, 1),
+		gen.SliceOfN(4, gen.IntRange(-1, 2)),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// Feature: ordering-platform, Property 78: Checkout charges exactly the quoted price
+func TestProperty_CheckoutChargesQuotedPrice(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("the order item price equals the quote for the same selections", prop.ForAll(
+		func(size int, toppings []int, quantity int) bool {
+			productRepo := newMockProductRepository()
+			cartRepo := newMockCartRepository(productRepo)
+			optionRepo := newMockProductOptionRepository()
+			pricing := NewPricingEngine(productRepo, optionRepo)
+			cartService := NewCartService(cartRepo, productRepo, newMockStoreRepository(), nil, pricing)
+			orderService := NewOrderService(&mockTransactor{}, newMockOrderRepository(), cartRepo, productRepo, newMockStoreRepository(), newMockAddressRepository(), nil, pricing, NewOrderEventBroker())
+			ctx := context.Background()
+			userID := uuid.New()
+
+			product := &domain.Product{ID: uuid.New(), Name: "Build your own", Price: domain.Cents(750), Stock: 100}
+			_ = productRepo.Create(ctx, product)
+			groups, _ := newOptionGroups(product.ID, pizzaOptionGroups())
+			optionRepo.groups[product.ID] = groups
+
+			placements := []string{domain.PlacementWhole, domain.PlacementLeft, domain.PlacementRight}
+			selections := wholeSelections(groups[0].Options[size].ID, groups[1].Options[0].ID)
+			for i, placement := range toppings {
+				if placement >= 0 {
+					selections = append(selections, domain.OptionSelection{OptionID: groups[2].Options[i].ID, Placement: placements[placement]})
+				}
+			}
+
+			quote, quoteErr := pricing.Quote(ctx, nil, product.ID, selections, quantity)
+			_, addErr := cartService.AddItem(ctx, userID, product.ID, selections, quantity)
+			if (quoteErr == nil) != (addErr == nil) {
+				t.Logf("FAIL: Quote error %v and add error %v disagree", quoteErr, addErr)
+				return false
+			}
+			if quoteErr != nil {
+				return errors.Is(quoteErr, ErrInvalidOptionSelection)
+			}
+
+			order, err := orderService.Checkout(ctx, userID, CheckoutOptions{})
+			if err != nil {
+				t.Logf("FAIL: Checkout failed: %v", err)
+				return false
+			}
+
+			item := order.Items[0]
+			if item.Price != quote.UnitPrice || item.Subtotal != quote.Total || order.Total != quote.Total {
+				t.Logf("FAIL: Charged %s/%s but quoted %s/%s", item.Price, item.Subtotal, quote.UnitPrice, quote.Total)
+				return false
+			}
+
+			return len(item.Options) == len(quote.Options)
+		},
+		gen.IntRange(0, 2),
+		gen.SliceOfN(4, gen.IntRange(-1, 2)),
+		gen.IntRange(1, 5),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestResolveSelections_RejectsInvalidSelections(t *testing.T) {
+	groups, _ := newOptionGroups(uuid.New(), pizzaOptionGroups())
+	size := groups[0].Options[0].ID
+	crust := groups[1].Options[0].ID
+	olives := groups[2].Options[0].ID
+
+	tests := []struct {
+		name       string
+		selections []domain.OptionSelection
+	}{
+		{"foreign option", wholeSelections(size, crust, uuid.New())},
+		{"duplicate option", wholeSelections(size, crust, crust)},
+		{"same option on both halves", append(wholeSelections(size, crust),
+			domain.OptionSelection{OptionID: olives, Placement: domain.PlacementLeft},
+			domain.OptionSelection{OptionID: olives, Placement: domain.PlacementRight})},
+		{"half placement in whole-only group", []domain.OptionSelection{
+			{OptionID: size, Placement: domain.PlacementLeft},
+			{OptionID: crust},
+		}},
+		{"unknown placement", append(wholeSelections(size, crust),
+			domain.OptionSelection{OptionID: olives, Placement: "middle"})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := resolveSelections(groups, tt.selections); !errors.Is(err, ErrInvalidOptionSelection) {
+				t.Errorf("Expected ErrInvalidOptionSelection, got %v", err)
+			}
+		})
+	}
+}
+
+func TestQuote_HalfToppingsCostHalf(t *testing.T) {
+	productRepo := newMockProductRepository()
+	optionRepo := newMockProductOptionRepository()
+	pricing := NewPricingEngine(productRepo, optionRepo)
+	ctx := context.Background()
+
+	product := &domain.Product{ID: uuid.New(), Name: "Half and half", Price: domain.Cents(900), Stock: 10}
+	_ = productRepo.Create(ctx, product)
+	groups, _ := newOptionGroups(product.ID, pizzaOptionGroups())
+	optionRepo.groups[product.ID] = groups
+
+	// Small thin, pepperoni (1.20) on the left, olives (0.80) and mushrooms (0.90) on the right
+	selections := append(wholeSelections(groups[0].Options[0].ID, groups[1].Options[0].ID),
+		domain.OptionSelection{OptionID: groups[2].Options[2].ID, Placement: domain.PlacementLeft},
+		domain.OptionSelection{OptionID: groups[2].Options[0].ID, Placement: domain.PlacementRight},
+		domain.OptionSelection{OptionID: groups[2].Options[1].ID, Placement: domain.PlacementRight},
+	)
+
+	quote, err := pricing.Quote(ctx, nil, product.ID, selections, 3)
+	if err != nil {
+		t.Fatalf("Quote failed: %v", err)
+	}
+	if quote.UnitPrice != domain.Cents(1045) {
+		t.Errorf("Expected unit price 10.45, got %s", quote.UnitPrice)
+	}
+	if quote.Total != domain.Cents(3135) {
+		t.Errorf("Expected total 31.35, got %s", quote.Total)
+	}
+
+	if _, err := pricing.Quote(ctx, nil, uuid.New(), nil, 1); err != repository.ErrProductNotFound {
+		t.Errorf("Expected ErrProductNotFound, got %v", err)
+	}
+	for _, quantity := range []int{0, MaxQuantity + 1, math.MaxInt32} {
+		if _, err := pricing.Quote(ctx, nil, product.ID, selections, quantity); err != ErrInvalidQuantity {
+			t.Errorf("Expected ErrInvalidQuantity for quantity %d, got %v", quantity, err)
+		}
+	}
+
+	// A total that does not fit in an amount is refused rather than wrapped around
+	if _, err := priceQuote(product.ID, product.Name, domain.Cents(math.MaxInt64/10), nil, nil, MaxQuantity); err != ErrInvalidQuantity {
+		t.Errorf("Expected ErrInvalidQuantity for an overflowing total, got %v", err)
+	}
+}
+
+func TestQuote_RejectsSelectionsPricedBelowZero(t *testing.T) {
+	productRepo := newMockProductRepository()
+	optionRepo := newMockProductOptionRepository()
+	pricing := NewPricingEngine(productRepo, optionRepo)
+	ctx := context.Background()
+
+	product := &domain.Product{ID: uuid.New(), Name: "Focaccia", Price: domain.Cents(300), Stock: 10}
+	_ = productRepo.Create(ctx, product)
+	groups, err := newOptionGroups(product.ID, []OptionGroupInput{
+		{Name: "Extras", MinSelect: 0, MaxSelect: 2, Options: []OptionInput{
+			{Name: "No rosemary", PriceDelta: domain.Cents(-50)},
+			{Name: "Kitchen credit", PriceDelta: domain.Cents(-400)},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("newOptionGroups failed: %v", err)
+	}
+	optionRepo.groups[product.ID] = groups
+
+	// Discounting options are allowed while the price stays at or above zero
+	quote, err := pricing.Quote(ctx, nil, product.ID, wholeSelections(groups[0].Options[0].ID), 2)
+	if err != nil {
+		t.Fatalf("Quote failed: %v", err)
+	}
+	if quote.UnitPrice != domain.Cents(250) || quote.Total != domain.Cents(500) {
+		t.Errorf("Expected unit price 2.50 and total 5.00, got %s and %s", quote.UnitPrice, quote.Total)
+	}
+
+	_, err = pricing.Quote(ctx, nil, product.ID, wholeSelections(groups[0].Options[0].ID, groups[0].Options[1].ID), 1)
+	if !errors.Is(err, ErrInvalidOptionSelection) {
+		t.Errorf("Expected ErrInvalidOptionSelection for a negative unit price, got %v", err)
+	}
+}
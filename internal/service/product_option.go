@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"pizza-must/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidOptionGroup = errors.New("invalid option group")
+)
+
+// OptionGroupInput holds a product option group and its options as submitted by an admin
+type OptionGroupInput struct {
+	Name        string
+	MinSelect   int
+	MaxSelect   int
+	AllowHalves bool
+	Options     []OptionInput
+}
+
+// OptionInput holds a single option and the amount it adds to the product price
+type OptionInput struct {
+	Name       string
+	PriceDelta domain.Money
+}
+
+// newOptionGroups validates option group inputs and builds the groups for a product.
+// Groups and options keep the order they were submitted in.
+func newOptionGroups(productID uuid.UUID, inputs []OptionGroupInput) ([]*domain.OptionGroup, error) {
+	groups := make([]*domain.OptionGroup, 0, len(inputs))
+	groupNames := make(map[string]bool, len(inputs))
+
+	for i, input := range inputs {
+		if groupNames[input.Name] {
+			return nil, fmt.Errorf("%w: duplicate group %q", ErrInvalidOptionGroup, input.Name)
+		}
+		groupNames[input.Name] = true
+
+		switch {
+		case input.MaxSelect < 1:
+			return nil, fmt.Errorf("%w: group %q must allow at least one option", ErrInvalidOptionGroup, input.Name)
+		case input.MinSelect < 0 || input.MinSelect > input.MaxSelect:
+			return nil, fmt.Errorf("%w: group %q min_select must be between 0 and max_select", ErrInvalidOptionGroup, input.Name)
+		case input.MaxSelect > len(input.Options):
+			return nil, fmt.Errorf("%w: group %q max_select exceeds its number of options", ErrInvalidOptionGroup, input.Name)
+		}
+
+		group := &domain.OptionGroup{
+			ID:          uuid.New(),
+			ProductID:   productID,
+			Name:        input.Name,
+			MinSelect:   input.MinSelect,
+			MaxSelect:   input.MaxSelect,
+			AllowHalves: input.AllowHalves,
+			Position:    i,
+			Options:     make([]*domain.Option, 0, len(input.Options)),
+		}
+
+		optionNames := make(map[string]bool, len(input.Options))
+		for j, option := range input.Options {
+			if optionNames[option.Name] {
+				return nil, fmt.Errorf("%w: group %q has duplicate option %q", ErrInvalidOptionGroup, input.Name, option.Name)
+			}
+			optionNames[option.Name] = true
+
+			group.Options = append(group.Options, &domain.Option{
+				ID:         uuid.New(),
+				GroupID:    group.ID,
+				Name:       option.Name,
+				PriceDelta: option.PriceDelta,
+				Position:   j,
+			})
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type mockProductOptionRepository struct {
+	groups map[uuid.UUID][]*domain.OptionGroup
+}
+
+func newMockProductOptionRepository() *mockProductOptionRepository {
+	return &mockProductOptionRepository{
+		groups: make(map[uuid.UUID][]*domain.OptionGroup),
+	}
+}
+
+func (m *mockProductOptionRepository) ListByProduct(ctx context.Context, productID uuid.UUID) ([]*domain.OptionGroup, error) {
+	return m.groups[productID], nil
+}
+
+func (m *mockProductOptionRepository) ReplaceForProductTx(ctx context.Context, tx *sql.Tx, productID uuid.UUID, groups []*domain.OptionGroup) error {
+	m.groups[productID] = groups
+	return nil
+}
+
+// newTestProductService builds a ProductService backed by in-memory option and cart repositories
+func newTestProductService(productRepo *mockProductRepository, categoryRepo *mockCategoryRepository) ProductService {
+	return NewProductService(
+		&mockTransactor{},
+		productRepo,
+		newMockStoreRepository(),
+		categoryRepo,
+		newMockProductOptionRepository(),
+		newMockCartRepository(productRepo),
+	)
+}
+
+// pizzaOptionGroups returns size (pick one), crust (pick one) and toppings (up to three per half)
+func pizzaOptionGroups() []OptionGroupInput {
+	return []OptionGroupInput{
+		{Name: "Size", MinSelect: 1, MaxSelect: 1, Options: []OptionInput{
+			{Name: "Small", PriceDelta: domain.Cents(0)},
+			{Name: "Medium", PriceDelta: domain.Cents(250)},
+			{Name: "Large", PriceDelta: domain.Cents(475)},
+		}},
+		{Name: "Crust", MinSelect: 1, MaxSelect: 1, Options: []OptionInput{
+			{Name: "Thin", PriceDelta: domain.Cents(0)},
+			{Name: "Stuffed", PriceDelta: domain.Cents(199)},
+		}},
+		{Name: "Toppings", MinSelect: 0, MaxSelect: 3, AllowHalves: true, Options: []OptionInput{
+			{Name: "Olives", PriceDelta: domain.Cents(80)},
+			{Name: "Mushrooms", PriceDelta: domain.Cents(90)},
+			{Name: "Pepperoni", PriceDelta: domain.Cents(120)},
+			{Name: "Anchovies", PriceDelta: domain.Cents(110)},
+		}},
+	}
+}
+
+func TestSetProductOptions_ValidatesGroupsAndClearsCartLines(t *testing.T) {
+	productRepo := newMockProductRepository()
+	cartRepo := newMockCartRepository(productRepo)
+	optionRepo := newMockProductOptionRepository()
+	productService := NewProductService(&mockTransactor{}, productRepo, newMockStoreRepository(), newMockCategoryRepository(), optionRepo, cartRepo)
+	cartService := NewCartService(cartRepo, productRepo, newMockStoreRepository(), nil, NewPricingEngine(productRepo, optionRepo))
+	ctx := context.Background()
+	userID := uuid.New()
+
+	product := &domain.Product{ID: uuid.New(), Name: "Margherita", Price: domain.Cents(800), Stock: 20}
+	_ = productRepo.Create(ctx, product)
+
+	invalid := []OptionGroupInput{{Name: "Size", MinSelect: 2, MaxSelect: 1, Options: []OptionInput{{Name: "Small"}}}}
+	if _, err := productService.SetProductOptions(ctx, product.ID, invalid); !errors.Is(err, ErrInvalidOptionGroup) {
+		t.Errorf("Expected ErrInvalidOptionGroup, got %v", err)
+	}
+
+	if _, err := productService.SetProductOptions(ctx, uuid.New(), pizzaOptionGroups()); err != repository.ErrProductNotFound {
+		t.Errorf("Expected ErrProductNotFound, got %v", err)
+	}
+
+	groups, err := productService.SetProductOptions(ctx, product.ID, pizzaOptionGroups())
+	if err != nil {
+		t.Fatalf("SetProductOptions failed: %v", err)
+	}
+
+	// The same product with different options becomes two cart lines
+	small := wholeSelections(groups[0].Options[0].ID, groups[1].Options[0].ID)
+	large := wholeSelections(groups[0].Options[2].ID, groups[1].Options[1].ID, groups[2].Options[2].ID)
+	if _, err := cartService.AddItem(ctx, userID, product.ID, small, 1); err != nil {
+		t.Fatalf("AddItem failed: %v", err)
+	}
+	cart, err := cartService.AddItem(ctx, userID, product.ID, large, 2)
+	if err != nil {
+		t.Fatalf("AddItem failed: %v", err)
+	}
+	if len(cart.Items) != 2 {
+		t.Fatalf("Expected 2 cart lines, got %d", len(cart.Items))
+	}
+	// 8 + 1 x small thin + 2 x (8 + 4.75 + 1.99 + 1.2)
+	if cart.Subtotal != domain.Cents(3988) {
+		t.Errorf("Expected subtotal 39.88, got %s", cart.Subtotal)
+	}
+
+	if _, err := cartService.AddItem(ctx, userID, product.ID, nil, 1); !errors.Is(err, ErrInvalidOptionSelection) {
+		t.Errorf("Expected ErrInvalidOptionSelection when required groups are empty, got %v", err)
+	}
+
+	if _, err := productService.SetProductOptions(ctx, product.ID, pizzaOptionGroups()); err != nil {
+		t.Fatalf("SetProductOptions failed: %v", err)
+	}
+	if quantity := cartRepo.quantityOf(userID, product.ID); quantity != 0 {
+		t.Errorf("Cart lines must be removed when options are replaced, got quantity %d", quantity)
+	}
+}
+
+func TestCheckout_SnapshotsChosenOptions(t *testing.T) {
+	productRepo := newMockProductRepository()
+	cartRepo := newMockCartRepository(productRepo)
+	optionRepo := newMockProductOptionRepository()
+	orderService := NewOrderService(&mockTransactor{}, newMockOrderRepository(), cartRepo, productRepo, newMockStoreRepository(), newMockAddressRepository(), nil, NewPricingEngine(productRepo, optionRepo), NewOrderEventBroker())
+	cartService := NewCartService(cartRepo, productRepo, newMockStoreRepository(), nil, NewPricingEngine(productRepo, optionRepo))
+	ctx := context.Background()
+	userID := uuid.New()
+
+	product := &domain.Product{ID: uuid.New(), Name: "Diavola", Price: domain.Cents(1000), Stock: 3}
+	_ = productRepo.Create(ctx, product)
+	groups, _ := newOptionGroups(product.ID, pizzaOptionGroups())
+	optionRepo.groups[product.ID] = groups
+
+	medium := wholeSelections(groups[0].Options[1].ID, groups[1].Options[0].ID)
+	large := wholeSelections(groups[0].Options[2].ID, groups[1].Options[0].ID)
+	if _, err := cartService.AddItem(ctx, userID, product.ID, medium, 1); err != nil {
+		t.Fatalf("AddItem failed: %v", err)
+	}
+	if _, err := cartService.AddItem(ctx, userID, product.ID, large, 2); err != nil {
+		t.Fatalf("AddItem failed: %v", err)
+	}
+
+	// Both lines share the product's stock
+	if _, err := cartService.AddItem(ctx, userID, product.ID, medium, 1); !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("Expected ErrInsufficientStock across lines, got %v", err)
+	}
+
+	order, err := orderService.Checkout(ctx, userID, CheckoutOptions{})
+	if err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+
+	for _, item := range order.Items {
+		if len(item.Options) != 2 || item.Options[0].GroupName != "Size" {
+			t.Errorf("Expected size and crust snapshot, got %+v", item.Options)
+		}
+		charged := product.Price
+		for _, option := range item.Options {
+			charged = charged.Add(option.PriceDelta)
+		}
+		if item.Price != charged {
+			t.Errorf("Item price %s does not include option deltas", item.Price)
+		}
+	}
+	// 12.50 + 2 x 14.75
+	if order.Total != domain.Cents(4200) {
+		t.Errorf("Expected total 42.00, got %s", order.Total)
+	}
+	if product.Stock != 0 {
+		t.Errorf("Expected stock 0 after checkout, got %d", product.Stock)
+	}
+}
@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Pagination defaults for catalog listings
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ProductListOptions holds filtering, sorting and pagination options for product listings.
+// With a StoreID only the store's menu is listed, at the store's prices and stock.
+type ProductListOptions struct {
+	CategoryID *uuid.UUID
+	StoreID    *uuid.UUID
+	Page       int
+	PageSize   int
+	SortBy     string
+	SortOrder  repository.SortOrder
+}
+
+// ProductPage represents a single page of products with pagination metadata
+type ProductPage struct {
+	Products   []*domain.Product
+	Total      int
+	Page       int
+	PageSize   int
+	TotalPages int
+}
+
+// ProductInput holds the full set of writable product attributes
+type ProductInput struct {
+	Name        string
+	Description string
+	Price       domain.Money
+	CategoryID  uuid.UUID
+	ImageURL    string
+}
+
+// ProductPatch holds a partial product update; nil fields are left unchanged
+type ProductPatch struct {
+	Name        *string
+	Description *string
+	Price       *domain.Money
+	CategoryID  *uuid.UUID
+	ImageURL    *string
+}
+
+// ProductService defines the interface for product catalog business logic
+type ProductService interface {
+	ListProducts(ctx context.Context, opts ProductListOptions) (*ProductPage, error)
+	SearchProducts(ctx context.Context, query string, storeID *uuid.UUID, page, pageSize int) (*ProductPage, error)
+	GetProduct(ctx context.Context, id uuid.UUID, storeID *uuid.UUID) (*domain.Product, error)
+	CreateProduct(ctx context.Context, input ProductInput) (*domain.Product, error)
+	UpdateProduct(ctx context.Context, id uuid.UUID, input ProductInput) (*domain.Product, error)
+	PatchProduct(ctx context.Context, id uuid.UUID, patch ProductPatch) (*domain.Product, error)
+	DeleteProduct(ctx context.Context, id uuid.UUID) error
+	SetProductOptions(ctx context.Context, id uuid.UUID, groups []OptionGroupInput) ([]*domain.OptionGroup, error)
+}
+
+type productService struct {
+	transactor   repository.Transactor
+	productRepo  repository.ProductRepository
+	storeRepo    repository.StoreRepository
+	categoryRepo repository.CategoryRepository
+	optionRepo   repository.ProductOptionRepository
+	cartRepo     repository.CartRepository
+}
+
+// NewProductService creates a new instance of ProductService
+func NewProductService(
+	transactor repository.Transactor,
+	productRepo repository.ProductRepository,
+	storeRepo repository.StoreRepository,
+	categoryRepo repository.CategoryRepository,
+	optionRepo repository.ProductOptionRepository,
+	cartRepo repository.CartRepository,
+) ProductService {
+	return &productService{
+		transactor:   transactor,
+		productRepo:  productRepo,
+		storeRepo:    storeRepo,
+		categoryRepo: categoryRepo,
+		optionRepo:   optionRepo,
+		cartRepo:     cartRepo,
+	}
+}
+
+// ListProducts returns a page of products, optionally filtered by category and store
+func (s *productService) ListProducts(ctx context.Context, opts ProductListOptions) (*ProductPage, error) {
+	page, pageSize := normalizePagination(opts.Page, opts.PageSize)
+
+	// Filtering by an unknown category or store is reported as not found rather than an empty page
+	if opts.CategoryID != nil {
+		if err := s.ensureCategoryExists(ctx, *opts.CategoryID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.ensureStoreExists(ctx, opts.StoreID); err != nil {
+		return nil, err
+	}
+
+	products, total, err := s.productRepo.List(ctx, opts.CategoryID, opts.StoreID, page, pageSize, opts.SortBy, opts.SortOrder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list products: %w", err)
+	}
+
+	return newProductPage(products, total, page, pageSize), nil
+}
+
+// SearchProducts returns a page of products matching the query by name or description,
+// optionally only from a store's menu
+func (s *productService) SearchProducts(ctx context.Context, query string, storeID *uuid.UUID, page, pageSize int) (*ProductPage, error) {
+	page, pageSize = normalizePagination(page, pageSize)
+
+	if err := s.ensureStoreExists(ctx, storeID); err != nil {
+		return nil, err
+	}
+
+	products, total, err := s.productRepo.Search(ctx, query, storeID, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search products: %w", err)
+	}
+
+	return newProductPage(products, total, page, pageSize), nil
+}
+
+// GetProduct retrieves a single product by ID together with its option groups. With a
+// store, the product must be available there and carries the store's price and stock.
+func (s *productService) GetProduct(ctx context.Context, id uuid.UUID, storeID *uuid.UUID) (*domain.Product, error) {
+	if err := s.ensureStoreExists(ctx, storeID); err != nil {
+		return nil, err
+	}
+
+	product, err := findProduct(ctx, s.productRepo, storeID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := s.optionRepo.ListByProduct(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product options: %w", err)
+	}
+	product.OptionGroups = groups
+
+	return product, nil
+}
+
+// CreateProduct adds a new product to the catalog
+func (s *productService) CreateProduct(ctx context.Context, input ProductInput) (*domain.Product, error) {
+	if err := s.ensureCategoryExists(ctx, input.CategoryID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	product := &domain.Product{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	applyProductInput(product, input)
+
+	if err := s.productRepo.Create(ctx, product); err != nil {
+		if err == repository.ErrCategoryNotFound {
+			return nil, repository.ErrCategoryNotFound
+		}
+		return nil, fmt.Errorf("failed to create product: %w", err)
+	}
+
+	return product, nil
+}
+
+// UpdateProduct replaces all writable attributes of an existing product
+func (s *productService) UpdateProduct(ctx context.Context, id uuid.UUID, input ProductInput) (*domain.Product, error) {
+	product, err := s.GetProduct(ctx, id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureCategoryExists(ctx, input.CategoryID); err != nil {
+		return nil, err
+	}
+
+	applyProductInput(product, input)
+	return s.saveProduct(ctx, product)
+}
+
+// PatchProduct updates only the attributes present in the patch
+func (s *productService) PatchProduct(ctx context.Context, id uuid.UUID, patch ProductPatch) (*domain.Product, error) {
+	product, err := s.GetProduct(ctx, id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if patch.CategoryID != nil {
+		if err := s.ensureCategoryExists(ctx, *patch.CategoryID); err != nil {
+			return nil, err
+		}
+		product.CategoryID = *patch.CategoryID
+	}
+	if patch.Name != nil {
+		product.Name = *patch.Name
+	}
+	if patch.Description != nil {
+		product.Description = *patch.Description
+	}
+	if patch.Price != nil {
+		product.Price = *patch.Price
+	}
+	if patch.ImageURL != nil {
+		product.ImageURL = *patch.ImageURL
+	}
+
+	return s.saveProduct(ctx, product)
+}
+
+// DeleteProduct removes a product from the catalog
+func (s *productService) DeleteProduct(ctx context.Context, id uuid.UUID) error {
+	if err := s.productRepo.Delete(ctx, id); err != nil {
+		if err == repository.ErrProductNotFound || err == repository.ErrProductHasOrders {
+			return err
+		}
+		return fmt.Errorf("failed to delete product: %w", err)
+	}
+	return nil
+}
+
+// SetProductOptions replaces all option groups of a product. Cart lines of the product are
+// removed in the same transaction because their selections refer to the replaced options.
+func (s *productService) SetProductOptions(ctx context.Context, id uuid.UUID, inputs []OptionGroupInput) ([]*domain.OptionGroup, error) {
+	groups, err := newOptionGroups(id, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, group := range groups {
+		group.CreatedAt = now
+	}
+
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		if _, err := s.productRepo.FindByID(ctx, id); err != nil {
+			return err
+		}
+
+		if err := s.optionRepo.ReplaceForProductTx(ctx, tx, id, groups); err != nil {
+			return err
+		}
+
+		return s.cartRepo.DeleteByProductTx(ctx, tx, id)
+	})
+
+	if err != nil {
+		if err == repository.ErrProductNotFound || err == repository.ErrDuplicateOptionName {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to set product options: %w", err)
+	}
+
+	return groups, nil
+}
+
+// saveProduct persists product changes and refreshes the update timestamp
+func (s *productService) saveProduct(ctx context.Context, product *domain.Product) (*domain.Product, error) {
+	product.UpdatedAt = time.Now()
+
+	if err := s.productRepo.Update(ctx, product); err != nil {
+		if err == repository.ErrProductNotFound || err == repository.ErrCategoryNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update product: %w", err)
+	}
+
+	return product, nil
+}
+
+// ensureCategoryExists returns ErrCategoryNotFound when the category does not exist
+func (s *productService) ensureCategoryExists(ctx context.Context, categoryID uuid.UUID) error {
+	if _, err := s.categoryRepo.FindByID(ctx, categoryID); err != nil {
+		if err == repository.ErrCategoryNotFound {
+			return repository.ErrCategoryNotFound
+		}
+		return fmt.Errorf("failed to find category: %w", err)
+	}
+	return nil
+}
+
+// ensureStoreExists returns ErrStoreNotFound when storeID is set and the store does not exist
+func (s *productService) ensureStoreExists(ctx context.Context, storeID *uuid.UUID) error {
+	if storeID == nil {
+		return nil
+	}
+	_, err := findStore(ctx, s.storeRepo, *storeID)
+	return err
+}
+
+// applyProductInput copies all writable attributes from input onto product
+func applyProductInput(product *domain.Product, input ProductInput) {
+	product.Name = input.Name
+	product.Description = input.Description
+	product.Price = input.Price
+	product.CategoryID = input.CategoryID
+	product.ImageURL = input.ImageURL
+}
+
+// newProductPage builds a ProductPage and computes the total number of pages
+func newProductPage(products []*domain.Product, total, page, pageSize int) *ProductPage {
+	return &ProductPage{
+		Products:   products,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages(total, pageSize),
+	}
+}
+
+// normalizePagination applies defaults and bounds to page and page size values
+func normalizePagination(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+	return page, pageSize
+}
+
+// totalPages returns the number of pages needed to hold total items
+func totalPages(total, pageSize int) int {
+	if total == 0 || pageSize < 1 {
+		return 0
+	}
+	return (total + pageSize - 1) / pageSize
+}
@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+type mockProductRepository struct {
+	products []*domain.Product
+}
+
+func newMockProductRepository() *mockProductRepository {
+	return &mockProductRepository{}
+}
+
+func (m *mockProductRepository) Create(ctx context.Context, product *domain.Product) error {
+	m.products = append(m.products, product)
+	return nil
+}
+
+func (m *mockProductRepository) Update(ctx context.Context, product *domain.Product) error {
+	for i, p := range m.products {
+		if p.ID == product.ID {
+			m.products[i] = product
+			return nil
+		}
+	}
+	return repository.ErrProductNotFound
+}
+
+func (m *mockProductRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	for i, p := range m.products {
+		if p.ID == id {
+			m.products = append(m.products[:i], m.products[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrProductNotFound
+}
+
+func (m *mockProductRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
+	for _, p := range m.products {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return nil, repository.ErrProductNotFound
+}
+
+// FindForStore treats every product as listed at every store with its Stock
+func (m *mockProductRepository) FindForStore(ctx context.Context, storeID, id uuid.UUID) (*domain.Product, error) {
+	return m.FindByID(ctx, id)
+}
+
+func (m *mockProductRepository) List(ctx context.Context, categoryID, storeID *uuid.UUID, page, pageSize int, sortBy string, sortOrder repository.SortOrder) ([]*domain.Product, int, error) {
+	filtered := []*domain.Product{}
+	for _, p := range m.products {
+		if categoryID == nil || p.CategoryID == *categoryID {
+			filtered = append(filtered, p)
+		}
+	}
+	return paginate(filtered, page, pageSize), len(filtered), nil
+}
+
+func (m *mockProductRepository) Search(ctx context.Context, query string, storeID *uuid.UUID, page, pageSize int) ([]*domain.Product, int, error) {
+	filtered := []*domain.Product{}
+	for _, p := range m.products {
+		if strings.Contains(strings.ToLower(p.Name), strings.ToLower(query)) {
+			filtered = append(filtered, p)
+		}
+	}
+	return paginate(filtered, page, pageSize), len(filtered), nil
+}
+
+func paginate(products []*domain.Product, page, pageSize int) []*domain.Product {
+	start := (page - 1) * pageSize
+	if start >= len(products) {
+		return []*domain.Product{}
+	}
+	end := start + pageSize
+	if end > len(products) {
+		end = len(products)
+	}
+	return products[start:end]
+}
+
+func (m *mockProductRepository) AdjustStockTx(ctx context.Context, tx *sql.Tx, storeID, id uuid.UUID, delta int) error {
+	for _, p := range m.products {
+		if p.ID == id {
+			p.Stock += delta
+			return nil
+		}
+	}
+	return repository.ErrProductNotFound
+}
+
+type mockCategoryRepository struct {
+	categories map[uuid.UUID]*domain.Category
+	// productRepo emulates the products.category_id foreign key when set
+	productRepo *mockProductRepository
+}
+
+func newMockCategoryRepository() *mockCategoryRepository {
+	return &mockCategoryRepository{
+		categories: make(map[uuid.UUID]*domain.Category),
+	}
+}
+
+func (m *mockCategoryRepository) Create(ctx context.Context, category *domain.Category) error {
+	for _, c := range m.categories {
+		if c.Name == category.Name {
+			return repository.ErrCategoryAlreadyExists
+		}
+	}
+	m.categories[category.ID] = category
+	return nil
+}
+
+func (m *mockCategoryRepository) List(ctx context.Context) ([]*domain.Category, error) {
+	categories := []*domain.Category{}
+	for _, c := range m.categories {
+		categories = append(categories, c)
+	}
+	return categories, nil
+}
+
+func (m *mockCategoryRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Category, error) {
+	category, exists := m.categories[id]
+	if !exists {
+		return nil, repository.ErrCategoryNotFound
+	}
+	return category, nil
+}
+
+func (m *mockCategoryRepository) Update(ctx context.Context, category *domain.Category) error {
+	if _, exists := m.categories[category.ID]; !exists {
+		return repository.ErrCategoryNotFound
+	}
+	for _, c := range m.categories {
+		if c.ID != category.ID && c.Name == category.Name {
+			return repository.ErrCategoryAlreadyExists
+		}
+	}
+	m.categories[category.ID] = category
+	return nil
+}
+
+func (m *mockCategoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, exists := m.categories[id]; !exists {
+		return repository.ErrCategoryNotFound
+	}
+	if m.productRepo != nil {
+		for _, p := range m.productRepo.products {
+			if p.CategoryID == id {
+				return repository.ErrCategoryHasProducts
+			}
+		}
+	}
+	delete(m.categories, id)
+	return nil
+}
+
+// Feature: ordering-platform, Property 69: Product pages report consistent pagination metadata
+func TestProperty_ProductPagesReportConsistentMetadata(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("page metadata matches the number of products and page size", prop.ForAll(
+		func(productCount int, page int, pageSize int) bool {
+			productRepo := newMockProductRepository()
+			categoryRepo := newMockCategoryRepository()
+			service := newTestProductService(productRepo, categoryRepo)
+			ctx := context.Background()
+
+			category := &domain.Category{ID: uuid.New(), Name: "Pizzas", CreatedAt: time.Now()}
+			_ = categoryRepo.Create(ctx, category)
+
+			for i := 0; i < productCount; i++ {
+				_ = productRepo.Create(ctx, &domain.Product{
+					ID:         uuid.New(),
+					Name:       "Margherita",
+					Price:      domain.Cents(999),
+					CategoryID: category.ID,
+				})
+			}
+
+			result, err := service.ListProducts(ctx, ProductListOptions{
+				CategoryID: &category.ID,
+				Page:       page,
+				PageSize:   pageSize,
+			})
+			if err != nil {
+				t.Logf("FAIL: ListProducts failed: %v", err)
+				return false
+			}
+
+			if result.Total != productCount {
+				t.Logf("FAIL: Total mismatch. Expected %d, got %d", productCount, result.Total)
+				return false
+			}
+
+			if result.PageSize < 1 || result.PageSize > MaxPageSize {
+				t.Logf("FAIL: Page size %d outside bounds", result.PageSize)
+				return false
+			}
+
+			if result.TotalPages*result.PageSize < result.Total {
+				t.Logf("FAIL: %d pages of %d cannot hold %d products", result.TotalPages, result.PageSize, result.Total)
+				return false
+			}
+
+			if len(result.Products) > result.PageSize {
+				t.Logf("FAIL: Page holds %d products, more than page size %d", len(result.Products), result.PageSize)
+				return false
+			}
+
+			return true
+		},
+		gen.IntRange(0, 250),
+		gen.IntRange(-1, 20),
+		gen.IntRange(-1, 150),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestListProducts_UnknownCategoryReturnsNotFound(t *testing.T) {
+	service := newTestProductService(newMockProductRepository(), newMockCategoryRepository())
+
+	categoryID := uuid.New()
+	_, err := service.ListProducts(context.Background(), ProductListOptions{CategoryID: &categoryID})
+	if err != repository.ErrCategoryNotFound {
+		t.Fatalf("Expected ErrCategoryNotFound, got %v", err)
+	}
+}
+
+func TestGetProduct_MissingProductReturnsNotFound(t *testing.T) {
+	service := newTestProductService(newMockProductRepository(), newMockCategoryRepository())
+
+	_, err := service.GetProduct(context.Background(), uuid.New(), nil)
+	if err != repository.ErrProductNotFound {
+		t.Fatalf("Expected ErrProductNotFound, got %v", err)
+	}
+}
+
+// Feature: ordering-platform, Property 71: Product patches only change supplied fields
+func TestProperty_ProductPatchesOnlyChangeSuppliedFields(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("patching a product leaves omitted attributes untouched", prop.ForAll(
+		func(name string, cents int64, description string, patchName bool, patchPrice bool) bool {
+			productRepo := newMockProductRepository()
+			categoryRepo := newMockCategoryRepository()
+			service := newTestProductService(productRepo, categoryRepo)
+			ctx := context.Background()
+
+			category := &domain.Category{ID: uuid.New(), Name: "Pizzas", CreatedAt: time.Now()}
+			_ = categoryRepo.Create(ctx, category)
+
+			original, err := service.CreateProduct(ctx, ProductInput{
+				Name:        "Original",
+				Description: "Original description",
+				Price:       domain.Cents(500),
+				CategoryID:  category.ID,
+				ImageURL:    "https://example.com/original.png",
+			})
+			if err != nil {
+				t.Logf("FAIL: CreateProduct failed: %v", err)
+				return false
+			}
+
+			patch := ProductPatch{Description: &description}
+			if patchName {
+				patch.Name = &name
+			}
+			price := domain.Cents(cents)
+			if patchPrice {
+				patch.Price = &price
+			}
+
+			updated, err := service.PatchProduct(ctx, original.ID, patch)
+			if err != nil {
+				t.Logf("FAIL: PatchProduct failed: %v", err)
+				return false
+			}
+
+			expectedName := "Original"
+			if patchName {
+				expectedName = name
+			}
+			expectedPrice := domain.Cents(500)
+			if patchPrice {
+				expectedPrice = price
+			}
+
+			if updated.Name != expectedName || updated.Price != expectedPrice || updated.Description != description {
+				t.Logf("FAIL: Unexpected product after patch: %+v", updated)
+				return false
+			}
+
+			if updated.ImageURL != "https://example.com/original.png" || updated.CategoryID != category.ID {
+				t.Logf("FAIL: Omitted attributes were modified: %+v", updated)
+				return false
+			}
+
+			return true
+		},
+		gen.RegexMatch(`[A-Za-z ]{3,30}`),
+		gen.Int64Range(0, 10000),
+		gen.AlphaString(),
+		gen.Bool(),
+		gen.Bool(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestCreateProduct_UnknownCategoryIsRejected(t *testing.T) {
+	service := newTestProductService(newMockProductRepository(), newMockCategoryRepository())
+
+	_, err := service.CreateProduct(context.Background(), ProductInput{Name: "Calzone", CategoryID: uuid.New()})
+	if err != repository.ErrCategoryNotFound {
+		t.Fatalf("Expected ErrCategoryNotFound, got %v", err)
+	}
+}
+
+func TestDeleteCategory_WithProductsIsRejected(t *testing.T) {
+	productRepo := newMockProductRepository()
+	categoryRepo := newMockCategoryRepository()
+	categoryRepo.productRepo = productRepo
+	productService := newTestProductService(productRepo, categoryRepo)
+	categoryService := NewCategoryService(categoryRepo)
+	ctx := context.Background()
+
+	category, err := categoryService.CreateCategory(ctx, "Sides", "")
+	if err != nil {
+		t.Fatalf("CreateCategory failed: %v", err)
+	}
+	if _, err := productService.CreateProduct(ctx, ProductInput{Name: "Garlic bread", CategoryID: category.ID}); err != nil {
+		t.Fatalf("CreateProduct failed: %v", err)
+	}
+
+	if err := categoryService.DeleteCategory(ctx, category.ID); err != repository.ErrCategoryHasProducts {
+		t.Fatalf("Expected ErrCategoryHasProducts, got %v", err)
+	}
+
+	if _, err := categoryService.CreateCategory(ctx, "Sides", ""); err != repository.ErrCategoryAlreadyExists {
+		t.Fatalf("Expected ErrCategoryAlreadyExists, got %v", err)
+	}
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidPromotion    = errors.New("invalid promotion")
+	ErrCouponNotApplicable = errors.New("coupon cannot be applied")
+)
+
+// CouponError explains why a coupon cannot be applied to a cart.
+// It matches ErrCouponNotApplicable with errors.Is.
+type CouponError struct {
+	Code   string `json:"code"`
+	Reason string `json:"reason"`
+}
+
+func (e *CouponError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrCouponNotApplicable, e.Reason)
+}
+
+func (e *CouponError) Is(target error) bool {
+	return target == ErrCouponNotApplicable
+}
+
+// PromotionInput holds the full set of writable promotion attributes
+type PromotionInput struct {
+	Code                  string
+	Description           string
+	Type                  string
+	PercentOff            int
+	AmountOff             domain.Money
+	MinOrderValue         domain.Money
+	ProductIDs            []uuid.UUID
+	CategoryIDs           []uuid.UUID
+	StartsAt              *time.Time
+	EndsAt                *time.Time
+	MaxRedemptions        int
+	MaxRedemptionsPerUser int
+	Active                bool
+}
+
+// PromotionService defines the interface for promotion management
+type PromotionService interface {
+	ListPromotions(ctx context.Context) ([]*domain.Promotion, error)
+	GetPromotion(ctx context.Context, id uuid.UUID) (*domain.Promotion, error)
+	CreatePromotion(ctx context.Context, input PromotionInput) (*domain.Promotion, error)
+	UpdatePromotion(ctx context.Context, id uuid.UUID, input PromotionInput) (*domain.Promotion, error)
+	DeletePromotion(ctx context.Context, id uuid.UUID) error
+}
+
+type promotionService struct {
+	promotionRepo repository.PromotionRepository
+}
+
+// NewPromotionService creates a new instance of PromotionService
+func NewPromotionService(promotionRepo repository.PromotionRepository) PromotionService {
+	return &promotionService{
+		promotionRepo: promotionRepo,
+	}
+}
+
+// ListPromotions returns every promotion, newest first
+func (s *promotionService) ListPromotions(ctx context.Context) ([]*domain.Promotion, error) {
+	promotions, err := s.promotionRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list promotions: %w", err)
+	}
+	return promotions, nil
+}
+
+// GetPromotion retrieves a single promotion by ID
+func (s *promotionService) GetPromotion(ctx context.Context, id uuid.UUID) (*domain.Promotion, error) {
+	promotion, err := s.promotionRepo.FindByID(ctx, id)
+	if err != nil {
+		if err == repository.ErrPromotionNotFound {
+			return nil, repository.ErrPromotionNotFound
+		}
+		return nil, fmt.Errorf("failed to get promotion: %w", err)
+	}
+	return promotion, nil
+}
+
+// CreatePromotion validates input and adds a promotion with a unique code
+func (s *promotionService) CreatePromotion(ctx context.Context, input PromotionInput) (*domain.Promotion, error) {
+	now := time.Now()
+	promotion := &domain.Promotion{
+		ID:        uuid.New(),
+		CreatedAt: now,
+	}
+	if err := applyPromotionInput(promotion, input, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.promotionRepo.Create(ctx, promotion); err != nil {
+		if err == repository.ErrPromotionCodeExists {
+			return nil, repository.ErrPromotionCodeExists
+		}
+		return nil, fmt.Errorf("failed to create promotion: %w", err)
+	}
+
+	return promotion, nil
+}
+
+// UpdatePromotion validates input and replaces every writable attribute of a promotion.
+// Redemptions made so far keep counting towards the new caps.
+func (s *promotionService) UpdatePromotion(ctx context.Context, id uuid.UUID, input PromotionInput) (*domain.Promotion, error) {
+	promotion, err := s.GetPromotion(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyPromotionInput(promotion, input, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.promotionRepo.Update(ctx, promotion); err != nil {
+		if err == repository.ErrPromotionNotFound || err == repository.ErrPromotionCodeExists {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update promotion: %w", err)
+	}
+
+	return promotion, nil
+}
+
+// DeletePromotion removes a promotion; carts it was applied to lose the coupon
+func (s *promotionService) DeletePromotion(ctx context.Context, id uuid.UUID) error {
+	if err := s.promotionRepo.Delete(ctx, id); err != nil {
+		if err == repository.ErrPromotionNotFound {
+			return repository.ErrPromotionNotFound
+		}
+		return fmt.Errorf("failed to delete promotion: %w", err)
+	}
+	return nil
+}
+
+// applyPromotionInput validates input and copies it onto promotion.
+// Coupon codes are stored upper-cased so they are matched case-insensitively.
+func applyPromotionInput(promotion *domain.Promotion, input PromotionInput, now time.Time) error {
+	code := normalizeCouponCode(input.Code)
+	if code == "" {
+		return fmt.Errorf("%w: code is required", ErrInvalidPromotion)
+	}
+	if !domain.IsValidPromotionType(input.Type) {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidPromotion, input.Type)
+	}
+
+	percentOff, amountOff := 0, domain.Cents(0)
+	switch input.Type {
+	case domain.PromotionPercentage:
+		if input.PercentOff < 1 || input.PercentOff > 100 {
+			return fmt.Errorf("%w: percent_off must be between 1 and 100", ErrInvalidPromotion)
+		}
+		percentOff = input.PercentOff
+	case domain.PromotionFixed:
+		if input.AmountOff.IsNegative() || input.AmountOff.IsZero() {
+			return fmt.Errorf("%w: amount_off must be greater than zero", ErrInvalidPromotion)
+		}
+		amountOff = input.AmountOff
+	}
+
+	switch {
+	case input.MinOrderValue.IsNegative():
+		return fmt.Errorf("%w: min_order_value must not be negative", ErrInvalidPromotion)
+	case input.MaxRedemptions < 0 || input.MaxRedemptionsPerUser < 0:
+		return fmt.Errorf("%w: redemption limits must not be negative", ErrInvalidPromotion)
+	case input.StartsAt != nil && input.EndsAt != nil && !input.StartsAt.Before(*input.EndsAt):
+		return fmt.Errorf("%w: starts_at must be before ends_at", ErrInvalidPromotion)
+	}
+
+	promotion.Code = code
+	promotion.Description = input.Description
+	promotion.Type = input.Type
+	promotion.PercentOff = percentOff
+	promotion.AmountOff = amountOff
+	promotion.MinOrderValue = input.MinOrderValue
+	promotion.ProductIDs = input.ProductIDs
+	promotion.CategoryIDs = input.CategoryIDs
+	promotion.StartsAt = input.StartsAt
+	promotion.EndsAt = input.EndsAt
+	promotion.MaxRedemptions = input.MaxRedemptions
+	promotion.MaxRedemptionsPerUser = input.MaxRedemptionsPerUser
+	promotion.Active = input.Active
+	promotion.UpdatedAt = now
+	return nil
+}
+
+// evaluateCoupon checks that a user who has already redeemed promotion userRedemptions times
+// may redeem it on lines at now, and returns the discount it grants. Lines must already be
+// priced. The discount never exceeds the subtotal of the eligible lines.
+func evaluateCoupon(promotion *domain.Promotion, lines []*domain.CartLine, userRedemptions int, now time.Time) (domain.Money, error) {
+	notApplicable := func(format string, args ...interface{}) (domain.Money, error) {
+		return domain.Cents(0), &CouponError{Code: promotion.Code, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	switch {
+	case !promotion.Active:
+		return notApplicable("coupon is not active")
+	case promotion.StartsAt != nil && now.Before(*promotion.StartsAt):
+		return notApplicable("coupon is not valid until %s", promotion.StartsAt.Format(time.RFC3339))
+	case promotion.EndsAt != nil && !now.Before(*promotion.EndsAt):
+		return notApplicable("coupon has expired")
+	case promotion.MaxRedemptions > 0 && promotion.RedemptionCount >= promotion.MaxRedemptions:
+		return notApplicable("coupon has been fully redeemed")
+	case promotion.MaxRedemptionsPerUser > 0 && userRedemptions >= promotion.MaxRedemptionsPerUser:
+		return notApplicable("coupon has already been used the maximum number of times")
+	}
+
+	subtotal := domain.Cents(0)
+	var eligible []*domain.CartLine
+	for _, line := range lines {
+		subtotal = subtotal.Add(line.Subtotal)
+		if promotionCovers(promotion, line) {
+			eligible = append(eligible, line)
+		}
+	}
+
+	if subtotal.Amount < promotion.MinOrderValue.Amount {
+		return notApplicable("a minimum order of %s is required", promotion.MinOrderValue)
+	}
+	if len(eligible) == 0 {
+		return notApplicable("no item in the cart is eligible")
+	}
+
+	eligibleSubtotal := domain.Cents(0)
+	eligibleUnits := 0
+	for _, line := range eligible {
+		eligibleSubtotal = eligibleSubtotal.Add(line.Subtotal)
+		eligibleUnits += line.Quantity
+	}
+	if promotion.Type == domain.PromotionBOGO && eligibleUnits < 2 {
+		return notApplicable("at least two eligible items are required")
+	}
+
+	switch promotion.Type {
+	case domain.PromotionPercentage:
+		discount, err := eligibleSubtotal.MulRatio(int64(promotion.PercentOff), 100)
+		if err != nil {
+			return domain.Cents(0), fmt.Errorf("failed to compute discount: %w", err)
+		}
+		return discount, nil
+	case domain.PromotionFixed:
+		if promotion.AmountOff.Amount > eligibleSubtotal.Amount {
+			return eligibleSubtotal, nil
+		}
+		return promotion.AmountOff, nil
+	case domain.PromotionFreeItem:
+		cheapest := eligible[0].UnitPrice
+		for _, line := range eligible[1:] {
+			if line.UnitPrice.Amount < cheapest.Amount {
+				cheapest = line.UnitPrice
+			}
+		}
+		return cheapest, nil
+	case domain.PromotionBOGO:
+		return buyOneGetOneDiscount(eligible)
+	default:
+		return notApplicable("coupon has an unknown type")
+	}
+}
+
+// buyOneGetOneDiscount pairs eligible units from the most to the least expensive and makes
+// the second unit of every pair free
+func buyOneGetOneDiscount(lines []*domain.CartLine) (domain.Money, error) {
+	sorted := append([]*domain.CartLine{}, lines...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].UnitPrice.Amount > sorted[j].UnitPrice.Amount
+	})
+
+	discount := domain.Cents(0)
+	position := 0
+	for _, line := range sorted {
+		// Units at odd positions in the ordering are the free half of a pair
+		free := (position+line.Quantity)/2 - position/2
+		freeUnits, err := line.UnitPrice.Mul(int64(free))
+		if err != nil {
+			return domain.Cents(0), fmt.Errorf("failed to compute discount: %w", err)
+		}
+		discount = discount.Add(freeUnits)
+		position += line.Quantity
+	}
+	return discount, nil
+}
+
+// promotionCovers reports whether a cart line falls within the promotion's product and
+// category scope; a promotion without a scope covers every line
+func promotionCovers(promotion *domain.Promotion, line *domain.CartLine) bool {
+	if len(promotion.ProductIDs) == 0 && len(promotion.CategoryIDs) == 0 {
+		return true
+	}
+	for _, id := range promotion.ProductIDs {
+		if id == line.ProductID {
+			return true
+		}
+	}
+	for _, id := range promotion.CategoryIDs {
+		if id == line.CategoryID {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeCouponCode trims and upper-cases a coupon code
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
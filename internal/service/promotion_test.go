@@ -0,0 +1,409 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+type mockPromotionRepository struct {
+	promotions  map[uuid.UUID]*domain.Promotion
+	redemptions []*domain.PromotionRedemption
+}
+
+func newMockPromotionRepository() *mockPromotionRepository {
+	return &mockPromotionRepository{promotions: make(map[uuid.UUID]*domain.Promotion)}
+}
+
+func (m *mockPromotionRepository) Create(ctx context.Context, promotion *domain.Promotion) error {
+	if _, err := m.FindByCode(ctx, promotion.Code); err == nil {
+		return repository.ErrPromotionCodeExists
+	}
+	m.promotions[promotion.ID] = promotion
+	return nil
+}
+
+func (m *mockPromotionRepository) Update(ctx context.Context, promotion *domain.Promotion) error {
+	if _, exists := m.promotions[promotion.ID]; !exists {
+		return repository.ErrPromotionNotFound
+	}
+	m.promotions[promotion.ID] = promotion
+	return nil
+}
+
+func (m *mockPromotionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, exists := m.promotions[id]; !exists {
+		return repository.ErrPromotionNotFound
+	}
+	delete(m.promotions, id)
+	return nil
+}
+
+func (m *mockPromotionRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Promotion, error) {
+	promotion, exists := m.promotions[id]
+	if !exists {
+		return nil, repository.ErrPromotionNotFound
+	}
+	return promotion, nil
+}
+
+func (m *mockPromotionRepository) FindByCode(ctx context.Context, code string) (*domain.Promotion, error) {
+	for _, promotion := range m.promotions {
+		if promotion.Code == code {
+			return promotion, nil
+		}
+	}
+	return nil, repository.ErrPromotionNotFound
+}
+
+func (m *mockPromotionRepository) List(ctx context.Context) ([]*domain.Promotion, error) {
+	promotions := []*domain.Promotion{}
+	for _, promotion := range m.promotions {
+		promotions = append(promotions, promotion)
+	}
+	return promotions, nil
+}
+
+func (m *mockPromotionRepository) CountUserRedemptions(ctx context.Context, promotionID, userID uuid.UUID) (int, error) {
+	count := 0
+	for _, redemption := range m.redemptions {
+		if redemption.PromotionID == promotionID && redemption.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockPromotionRepository) FindByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*domain.Promotion, error) {
+	return m.FindByID(ctx, id)
+}
+
+func (m *mockPromotionRepository) CountUserRedemptionsTx(ctx context.Context, tx *sql.Tx, promotionID, userID uuid.UUID) (int, error) {
+	return m.CountUserRedemptions(ctx, promotionID, userID)
+}
+
+func (m *mockPromotionRepository) AddRedemptionTx(ctx context.Context, tx *sql.Tx, redemption *domain.PromotionRedemption) error {
+	promotion, exists := m.promotions[redemption.PromotionID]
+	if !exists {
+		return repository.ErrPromotionNotFound
+	}
+	m.redemptions = append(m.redemptions, redemption)
+	promotion.RedemptionCount++
+	return nil
+}
+
+// pricedLine builds a priced cart line of quantity units at unitPrice cents
+func pricedLine(productID, categoryID uuid.UUID, unitPrice int64, quantity int) *domain.CartLine {
+	line := &domain.CartLine{
+		CartItem:   domain.CartItem{ProductID: productID, Quantity: quantity},
+		CategoryID: categoryID,
+		UnitPrice:  domain.Cents(unitPrice),
+		Subtotal:   domain.Cents(unitPrice * int64(quantity)),
+	}
+	return line
+}
+
+// Feature: ordering-platform, Property 81: Coupon discounts stay within the eligible subtotal
+func TestProperty_CouponDiscountsStayWithinEligibleSubtotal(t *testing.T) {
+	types := []string{domain.PromotionPercentage, domain.PromotionFixed, domain.PromotionFreeItem, domain.PromotionBOGO}
+	scopedCategory := uuid.New()
+
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("discounts are never negative and never exceed the eligible lines", prop.ForAll(
+		func(typeIdx int, value int64, prices []int64, quantities []int, scoped []bool) bool {
+			promotion := &domain.Promotion{
+				Code:        "TEST",
+				Type:        types[typeIdx],
+				PercentOff:  int(value%100) + 1,
+				AmountOff:   domain.Cents(value),
+				CategoryIDs: []uuid.UUID{scopedCategory},
+				Active:      true,
+			}
+
+			var lines []*domain.CartLine
+			eligible := domain.Cents(0)
+			eligibleUnits := 0
+			for i, price := range prices {
+				categoryID := uuid.New()
+				if scoped[i] {
+					categoryID = scopedCategory
+				}
+				line := pricedLine(uuid.New(), categoryID, price, quantities[i])
+				lines = append(lines, line)
+				if scoped[i] {
+					eligible = eligible.Add(line.Subtotal)
+					eligibleUnits += quantities[i]
+				}
+			}
+
+			discount, err := evaluateCoupon(promotion, lines, 0, time.Now())
+			if eligible.IsZero() || promotion.Type == domain.PromotionBOGO && eligibleUnits < 2 {
+				return errors.Is(err, ErrCouponNotApplicable)
+			}
+			if err != nil {
+				t.Logf("FAIL: Coupon rejected: %v", err)
+				return false
+			}
+
+			if discount.IsNegative() || discount.Amount > eligible.Amount {
+				t.Logf("FAIL: %s discount %s outside [0, %s]", promotion.Type, discount, eligible)
+				return false
+			}
+			return true
+		},
+		gen.IntRange(0, len(types)-1),
+		gen.Int64Range(1, 5000),
+		gen.SliceOfN(4, gen.Int64Range(1, 3000)),
+		gen.SliceOfN(4, gen.IntRange(1, 5)),
+		gen.SliceOfN(4, gen.Bool()),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestEvaluateCoupon_Discounts(t *testing.T) {
+	lines := []*domain.CartLine{
+		pricedLine(uuid.New(), uuid.New(), 1000, 1),
+		pricedLine(uuid.New(), uuid.New(), 800, 2),
+		pricedLine(uuid.New(), uuid.New(), 500, 1),
+	}
+
+	tests := []struct {
+		name      string
+		promotion *domain.Promotion
+		want      domain.Money
+	}{
+		{"percentage", &domain.Promotion{Type: domain.PromotionPercentage, PercentOff: 15}, domain.Cents(465)},
+		{"fixed", &domain.Promotion{Type: domain.PromotionFixed, AmountOff: domain.Cents(500)}, domain.Cents(500)},
+		{"fixed capped at eligible items", &domain.Promotion{Type: domain.PromotionFixed, AmountOff: domain.Cents(1200), ProductIDs: []uuid.UUID{lines[0].ProductID}}, domain.Cents(1000)},
+		{"free item", &domain.Promotion{Type: domain.PromotionFreeItem}, domain.Cents(500)},
+		// Units sorted by price are 10.00, 8.00, 8.00, 5.00; the cheaper unit of each pair is free
+		{"buy one get one", &domain.Promotion{Type: domain.PromotionBOGO}, domain.Cents(1300)},
+		{"scoped to a category", &domain.Promotion{Type: domain.PromotionBOGO, CategoryIDs: []uuid.UUID{lines[1].CategoryID}}, domain.Cents(800)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.promotion.Active = true
+			discount, err := evaluateCoupon(tt.promotion, lines, 0, time.Now())
+			if err != nil {
+				t.Fatalf("Coupon rejected: %v", err)
+			}
+			if discount != tt.want {
+				t.Errorf("Expected discount %s, got %s", tt.want, discount)
+			}
+		})
+	}
+}
+
+func TestEvaluateCoupon_RejectsInapplicableCoupons(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	lines := []*domain.CartLine{pricedLine(uuid.New(), uuid.New(), 1200, 1)}
+
+	tests := []struct {
+		name        string
+		promotion   domain.Promotion
+		redemptions int
+	}{
+		{"inactive", domain.Promotion{}, 0},
+		{"not started", domain.Promotion{Active: true, StartsAt: &future}, 0},
+		{"expired", domain.Promotion{Active: true, EndsAt: &past}, 0},
+		{"fully redeemed", domain.Promotion{Active: true, MaxRedemptions: 3, RedemptionCount: 3}, 0},
+		{"used up by user", domain.Promotion{Active: true, MaxRedemptionsPerUser: 1}, 1},
+		{"below minimum order", domain.Promotion{Active: true, MinOrderValue: domain.Cents(1500)}, 0},
+		{"no eligible items", domain.Promotion{Active: true, ProductIDs: []uuid.UUID{uuid.New()}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			promotion := tt.promotion
+			promotion.Type = domain.PromotionPercentage
+			promotion.PercentOff = 10
+
+			_, err := evaluateCoupon(&promotion, lines, tt.redemptions, now)
+			var couponErr *CouponError
+			if !errors.As(err, &couponErr) || couponErr.Reason == "" {
+				t.Errorf("Expected CouponError with a reason, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckout_RedeemsAppliedCoupon(t *testing.T) {
+	productRepo := newMockProductRepository()
+	cartRepo := newMockCartRepository(productRepo)
+	orderRepo := newMockOrderRepository()
+	promotionRepo := newMockPromotionRepository()
+	pricing := newTestPricingEngine(productRepo)
+	cartService := NewCartService(cartRepo, productRepo, newMockStoreRepository(), promotionRepo, pricing)
+	orderService := NewOrderService(&mockTransactor{}, orderRepo, cartRepo, productRepo, newMockStoreRepository(), newMockAddressRepository(), promotionRepo, pricing, NewOrderEventBroker())
+	promotionService := NewPromotionService(promotionRepo)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	promotion, err := promotionService.CreatePromotion(ctx, PromotionInput{
+		Code:                  " pizza20 ",
+		Type:                  domain.PromotionPercentage,
+		PercentOff:            20,
+		MinOrderValue:         domain.Cents(2000),
+		MaxRedemptionsPerUser: 1,
+		Active:                true,
+	})
+	if err != nil {
+		t.Fatalf("CreatePromotion failed: %v", err)
+	}
+	if promotion.Code != "PIZZA20" {
+		t.Errorf("Expected normalized code PIZZA20, got %q", promotion.Code)
+	}
+
+	product := &domain.Product{ID: uuid.New(), Name: "Diavola", Price: domain.Cents(1100), Stock: 10}
+	_ = productRepo.Create(ctx, product)
+
+	if _, err := cartService.ApplyCoupon(ctx, userID, "PIZZA20"); err != ErrEmptyCart {
+		t.Errorf("Expected ErrEmptyCart, got %v", err)
+	}
+	if _, err := cartService.AddItem(ctx, userID, product.ID, nil, 1); err != nil {
+		t.Fatalf("AddItem failed: %v", err)
+	}
+	if _, err := cartService.ApplyCoupon(ctx, userID, "pizza20"); !errors.Is(err, ErrCouponNotApplicable) {
+		t.Errorf("Expected minimum order to be enforced, got %v", err)
+	}
+	if _, err := cartService.ApplyCoupon(ctx, userID, "NOPE"); err != repository.ErrPromotionNotFound {
+		t.Errorf("Expected ErrPromotionNotFound, got %v", err)
+	}
+
+	if _, err := cartService.AddItem(ctx, userID, product.ID, nil, 1); err != nil {
+		t.Fatalf("AddItem failed: %v", err)
+	}
+	cart, err := cartService.ApplyCoupon(ctx, userID, "pizza20")
+	if err != nil {
+		t.Fatalf("ApplyCoupon failed: %v", err)
+	}
+	if cart.Discount != domain.Cents(440) || cart.Total != domain.Cents(1760) || !cart.Coupon.Applicable {
+		t.Errorf("Unexpected cart breakdown: subtotal %s, discount %s, total %s", cart.Subtotal, cart.Discount, cart.Total)
+	}
+
+	order, err := orderService.Checkout(ctx, userID, CheckoutOptions{})
+	if err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	if order.Subtotal != domain.Cents(2200) || order.Discount != domain.Cents(440) || order.Total != domain.Cents(1760) || order.CouponCode != "PIZZA20" {
+		t.Errorf("Unexpected order breakdown: %s - %s = %s (%s)", order.Subtotal, order.Discount, order.Total, order.CouponCode)
+	}
+	if promotion.RedemptionCount != 1 || len(promotionRepo.redemptions) != 1 || promotionRepo.redemptions[0].OrderID != order.ID {
+		t.Errorf("Expected a single redemption for the order, got %d", len(promotionRepo.redemptions))
+	}
+	if _, exists := cartRepo.coupons[userID]; exists {
+		t.Errorf("Coupon must be removed from the cart after checkout")
+	}
+
+	// The per-user cap is reached, so the coupon cannot be applied again
+	if _, err := cartService.AddItem(ctx, userID, product.ID, nil, 2); err != nil {
+		t.Fatalf("AddItem failed: %v", err)
+	}
+	if _, err := cartService.ApplyCoupon(ctx, userID, "PIZZA20"); !errors.Is(err, ErrCouponNotApplicable) {
+		t.Errorf("Expected per-user cap to be enforced, got %v", err)
+	}
+}
+
+func TestCheckout_RejectsCouponThatNoLongerApplies(t *testing.T) {
+	productRepo := newMockProductRepository()
+	cartRepo := newMockCartRepository(productRepo)
+	orderRepo := newMockOrderRepository()
+	promotionRepo := newMockPromotionRepository()
+	pricing := newTestPricingEngine(productRepo)
+	cartService := NewCartService(cartRepo, productRepo, newMockStoreRepository(), promotionRepo, pricing)
+	orderService := NewOrderService(&mockTransactor{}, orderRepo, cartRepo, productRepo, newMockStoreRepository(), newMockAddressRepository(), promotionRepo, pricing, NewOrderEventBroker())
+	ctx := context.Background()
+	userID := uuid.New()
+
+	promotion, err := NewPromotionService(promotionRepo).CreatePromotion(ctx, PromotionInput{
+		Code:           "LAST",
+		Type:           domain.PromotionFixed,
+		AmountOff:      domain.Cents(300),
+		MaxRedemptions: 1,
+		Active:         true,
+	})
+	if err != nil {
+		t.Fatalf("CreatePromotion failed: %v", err)
+	}
+
+	product := &domain.Product{ID: uuid.New(), Name: "Margherita", Price: domain.Cents(900), Stock: 10}
+	_ = productRepo.Create(ctx, product)
+	if _, err := cartService.AddItem(ctx, userID, product.ID, nil, 1); err != nil {
+		t.Fatalf("AddItem failed: %v", err)
+	}
+	if _, err := cartService.ApplyCoupon(ctx, userID, "LAST"); err != nil {
+		t.Fatalf("ApplyCoupon failed: %v", err)
+	}
+
+	// Another customer redeems the last use before this checkout
+	promotion.RedemptionCount = 1
+
+	cart, err := cartService.GetCart(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetCart failed: %v", err)
+	}
+	if cart.Coupon == nil || cart.Coupon.Applicable || !cart.Discount.IsZero() || cart.Total != cart.Subtotal {
+		t.Errorf("Expected the coupon to be shown as not applicable without a discount, got %+v", cart.Coupon)
+	}
+
+	if _, err := orderService.Checkout(ctx, userID, CheckoutOptions{}); !errors.Is(err, ErrCouponNotApplicable) {
+		t.Fatalf("Expected ErrCouponNotApplicable, got %v", err)
+	}
+	if len(orderRepo.orders) != 0 || product.Stock != 10 {
+		t.Errorf("A rejected coupon must leave orders and stock untouched")
+	}
+
+	if _, err := cartService.RemoveCoupon(ctx, userID); err != nil {
+		t.Fatalf("RemoveCoupon failed: %v", err)
+	}
+	order, err := orderService.Checkout(ctx, userID, CheckoutOptions{})
+	if err != nil {
+		t.Fatalf("Checkout without coupon failed: %v", err)
+	}
+	if order.Total != domain.Cents(900) || !order.Discount.IsZero() {
+		t.Errorf("Expected undiscounted order, got %s - %s", order.Subtotal, order.Discount)
+	}
+}
+
+func TestCreatePromotion_ValidatesInput(t *testing.T) {
+	service := NewPromotionService(newMockPromotionRepository())
+	ctx := context.Background()
+	start := time.Now()
+
+	invalid := []PromotionInput{
+		{Code: "  ", Type: domain.PromotionBOGO},
+		{Code: "X", Type: "half_off"},
+		{Code: "X", Type: domain.PromotionPercentage, PercentOff: 0},
+		{Code: "X", Type: domain.PromotionPercentage, PercentOff: 101},
+		{Code: "X", Type: domain.PromotionFixed},
+		{Code: "X", Type: domain.PromotionBOGO, MaxRedemptions: -1},
+		{Code: "X", Type: domain.PromotionBOGO, StartsAt: &start, EndsAt: &start},
+	}
+	for _, input := range invalid {
+		if _, err := service.CreatePromotion(ctx, input); !errors.Is(err, ErrInvalidPromotion) {
+			t.Errorf("Expected ErrInvalidPromotion for %+v, got %v", input, err)
+		}
+	}
+
+	if _, err := service.CreatePromotion(ctx, PromotionInput{Code: "bogo", Type: domain.PromotionBOGO}); err != nil {
+		t.Fatalf("CreatePromotion failed: %v", err)
+	}
+	if _, err := service.CreatePromotion(ctx, PromotionInput{Code: "BOGO", Type: domain.PromotionBOGO}); err != repository.ErrPromotionCodeExists {
+		t.Errorf("Expected ErrPromotionCodeExists, got %v", err)
+	}
+}
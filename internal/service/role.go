@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var ErrLastAdmin = errors.New("the last admin cannot lose the admin role")
+
+// RoleService defines the interface for role management business logic
+type RoleService interface {
+	ListRoles(ctx context.Context) ([]*domain.Role, error)
+	ListUserRoles(ctx context.Context, userID uuid.UUID) ([]*domain.RoleAssignment, error)
+	AssignRole(ctx context.Context, userID uuid.UUID, roleName string, storeID *uuid.UUID) ([]*domain.RoleAssignment, error)
+	RevokeRole(ctx context.Context, userID uuid.UUID, roleName string, storeID *uuid.UUID) ([]*domain.RoleAssignment, error)
+}
+
+type roleService struct {
+	transactor repository.Transactor
+	roleRepo   repository.RoleRepository
+	userRepo   repository.UserRepository
+}
+
+// NewRoleService creates a new instance of RoleService
+func NewRoleService(transactor repository.Transactor, roleRepo repository.RoleRepository, userRepo repository.UserRepository) RoleService {
+	return &roleService{
+		transactor: transactor,
+		roleRepo:   roleRepo,
+		userRepo:   userRepo,
+	}
+}
+
+// ListRoles retrieves every role with its permissions
+func (s *roleService) ListRoles(ctx context.Context) ([]*domain.Role, error) {
+	roles, err := s.roleRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	return roles, nil
+}
+
+// ListUserRoles retrieves the roles granted to a user and the stores they are granted for
+func (s *roleService) ListUserRoles(ctx context.Context, userID uuid.UUID) ([]*domain.RoleAssignment, error) {
+	if _, err := s.findUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	roles, err := s.roleRepo.ListAssignmentsForUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user roles: %w", err)
+	}
+	return roles, nil
+}
+
+// AssignRole grants a role to a user at a store, or at every store when storeID is nil, and
+// returns the roles they now hold. Granting a role the user already holds there is not an
+// error. The user's access token carries the new permissions once it is refreshed.
+func (s *roleService) AssignRole(ctx context.Context, userID uuid.UUID, roleName string, storeID *uuid.UUID) ([]*domain.RoleAssignment, error) {
+	role, err := s.findRole(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.findUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.roleRepo.Assign(ctx, userID, role.ID, storeID, time.Now()); err != nil {
+		if err == repository.ErrUserNotFound || err == repository.ErrRoleNotFound || err == repository.ErrStoreNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to assign role: %w", err)
+	}
+
+	return s.ListUserRoles(ctx, userID)
+}
+
+// RevokeRole takes away a role granted to a user at a store, or at every store when storeID
+// is nil, and returns the roles they still hold. The admin role cannot be taken from its last
+// holder at every store, so role management always stays possible.
+func (s *roleService) RevokeRole(ctx context.Context, userID uuid.UUID, roleName string, storeID *uuid.UUID) ([]*domain.RoleAssignment, error) {
+	role, err := s.findRole(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.findUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		if role.Name == domain.RoleAdmin && storeID == nil {
+			if err := ensureNotLastAdminTx(ctx, tx, s.roleRepo, role.ID, userID); err != nil {
+				return err
+			}
+		}
+		return s.roleRepo.RevokeTx(ctx, tx, userID, role.ID, storeID)
+	})
+	if err != nil {
+		if err == ErrLastAdmin || err == repository.ErrRoleAssignmentNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to revoke role: %w", err)
+	}
+
+	return s.ListUserRoles(ctx, userID)
+}
+
+// findRole retrieves a role, passing ErrRoleNotFound through unwrapped
+func (s *roleService) findRole(ctx context.Context, name string) (*domain.Role, error) {
+	role, err := s.roleRepo.FindByName(ctx, name)
+	if err != nil {
+		if err == repository.ErrRoleNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find role: %w", err)
+	}
+	return role, nil
+}
+
+// findUser retrieves a user, passing ErrUserNotFound through unwrapped
+func (s *roleService) findUser(ctx context.Context, userID uuid.UUID) (*domain.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	return user, nil
+}
+
+// ensureNotLastAdminTx returns ErrLastAdmin if userID is the only user holding the admin
+// role for every store. The admins' grants stay locked until tx ends, so two admins cannot
+// both step down at once.
+func ensureNotLastAdminTx(ctx context.Context, tx *sql.Tx, roleRepo repository.RoleRepository, adminRoleID, userID uuid.UUID) error {
+	holders, err := roleRepo.ListHoldersForUpdateTx(ctx, tx, adminRoleID)
+	if err != nil {
+		return fmt.Errorf("failed to list admins: %w", err)
+	}
+	if len(holders) == 1 && holders[0] == userID {
+		return ErrLastAdmin
+	}
+	return nil
+}
@@ -0,0 +1,367 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"testing"
+	"time"
+
+	"pizza-must/internal/auth"
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// mockRoleRepository holds the built-in roles with the permissions the migrations give them.
+// Assignments are keyed by user, role and store; uuid.Nil stands for every store.
+type mockRoleRepository struct {
+	roles       map[string]*domain.Role
+	assignments map[uuid.UUID]map[uuid.UUID]map[uuid.UUID]bool
+}
+
+func newMockRoleRepository() *mockRoleRepository {
+	m := &mockRoleRepository{
+		roles:       make(map[string]*domain.Role),
+		assignments: make(map[uuid.UUID]map[uuid.UUID]map[uuid.UUID]bool),
+	}
+	builtIn := map[string][]string{
+		domain.RoleAdmin: {
+			domain.PermissionOrdersRead, domain.PermissionOrdersTransition, domain.PermissionRefundsIssue,
+			domain.PermissionProductsWrite, domain.PermissionPromotionsWrite, domain.PermissionUsersManage,
+			domain.PermissionRolesManage, domain.PermissionStoresManage,
+		},
+		domain.RoleStoreManager: {
+			domain.PermissionOrdersRead, domain.PermissionOrdersTransition, domain.PermissionRefundsIssue,
+			domain.PermissionProductsWrite, domain.PermissionPromotionsWrite, domain.PermissionStoresManage,
+		},
+		domain.RoleKitchen: {domain.PermissionOrdersRead, domain.PermissionOrdersTransition},
+		domain.RoleDriver:  {domain.PermissionOrdersRead, domain.PermissionOrdersTransition},
+		domain.RoleSupport: {domain.PermissionOrdersRead, domain.PermissionRefundsIssue, domain.PermissionUsersManage},
+	}
+	for name, permissions := range builtIn {
+		sort.Strings(permissions)
+		m.roles[name] = &domain.Role{ID: uuid.New(), Name: name, Permissions: permissions, CreatedAt: time.Now()}
+	}
+	return m
+}
+
+// storeKey maps an optional store to its assignment key
+func storeKey(storeID *uuid.UUID) uuid.UUID {
+	if storeID == nil {
+		return uuid.Nil
+	}
+	return *storeID
+}
+
+// storeRef maps an assignment key back to an optional store
+func storeRef(key uuid.UUID) *uuid.UUID {
+	if key == uuid.Nil {
+		return nil
+	}
+	return &key
+}
+
+func (m *mockRoleRepository) List(ctx context.Context) ([]*domain.Role, error) {
+	roles := make([]*domain.Role, 0, len(m.roles))
+	for _, role := range m.roles {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+func (m *mockRoleRepository) FindByName(ctx context.Context, name string) (*domain.Role, error) {
+	role, exists := m.roles[name]
+	if !exists {
+		return nil, repository.ErrRoleNotFound
+	}
+	return role, nil
+}
+
+func (m *mockRoleRepository) ListNamesForUser(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	names := []string{}
+	for _, role := range m.roles {
+		if len(m.assignments[userID][role.ID]) > 0 {
+			names = append(names, role.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (m *mockRoleRepository) ListAssignmentsForUser(ctx context.Context, userID uuid.UUID) ([]*domain.RoleAssignment, error) {
+	assignments := []*domain.RoleAssignment{}
+	for _, role := range m.roles {
+		for key := range m.assignments[userID][role.ID] {
+			assignments = append(assignments, &domain.RoleAssignment{Role: role.Name, StoreID: storeRef(key)})
+		}
+	}
+	sort.Slice(assignments, func(i, j int) bool { return assignments[i].Role < assignments[j].Role })
+	return assignments, nil
+}
+
+func (m *mockRoleRepository) ListPermissionsForUser(ctx context.Context, userID uuid.UUID) ([]*domain.PermissionGrant, error) {
+	seen := make(map[string]bool)
+	grants := []*domain.PermissionGrant{}
+	for _, role := range m.roles {
+		for key := range m.assignments[userID][role.ID] {
+			for _, permission := range role.Permissions {
+				if seen[permission+"@"+key.String()] {
+					continue
+				}
+				seen[permission+"@"+key.String()] = true
+				grants = append(grants, &domain.PermissionGrant{Permission: permission, StoreID: storeRef(key)})
+			}
+		}
+	}
+	sort.Slice(grants, func(i, j int) bool { return grants[i].Permission < grants[j].Permission })
+	return grants, nil
+}
+
+func (m *mockRoleRepository) ListHoldersForUpdateTx(ctx context.Context, tx *sql.Tx, roleID uuid.UUID) ([]uuid.UUID, error) {
+	holders := []uuid.UUID{}
+	for userID, roles := range m.assignments {
+		if roles[roleID][uuid.Nil] {
+			holders = append(holders, userID)
+		}
+	}
+	return holders, nil
+}
+
+func (m *mockRoleRepository) Assign(ctx context.Context, userID, roleID uuid.UUID, storeID *uuid.UUID, at time.Time) error {
+	if m.assignments[userID] == nil {
+		m.assignments[userID] = make(map[uuid.UUID]map[uuid.UUID]bool)
+	}
+	if m.assignments[userID][roleID] == nil {
+		m.assignments[userID][roleID] = make(map[uuid.UUID]bool)
+	}
+	m.assignments[userID][roleID][storeKey(storeID)] = true
+	return nil
+}
+
+func (m *mockRoleRepository) RevokeTx(ctx context.Context, tx *sql.Tx, userID, roleID uuid.UUID, storeID *uuid.UUID) error {
+	if !m.assignments[userID][roleID][storeKey(storeID)] {
+		return repository.ErrRoleAssignmentNotFound
+	}
+	delete(m.assignments[userID][roleID], storeKey(storeID))
+	return nil
+}
+
+// grant gives a user a built-in role at every store
+func (m *mockRoleRepository) grant(userID uuid.UUID, roleName string) {
+	_ = m.Assign(context.Background(), userID, m.roles[roleName].ID, nil, time.Now())
+}
+
+// Feature: ordering-platform, Property 89: Access tokens carry exactly the permissions of the user's roles
+// Validates: Requirements 17.3
+func TestProperty_AccessTokensCarryRolePermissions(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	roleNames := []string{domain.RoleAdmin, domain.RoleStoreManager, domain.RoleKitchen, domain.RoleDriver, domain.RoleSupport}
+
+	properties.Property("token permissions are the union of the granted roles' permissions", prop.ForAll(
+		func(granted []bool) bool {
+			userRepo := newMockUserRepository()
+			roleRepo := newMockRoleRepository()
+			tokens := newTestTokenManager()
+			service := NewUserService(&mockTransactor{}, userRepo, roleRepo, newMockRefreshTokenRepository(), tokens, DefaultTokenPolicy(), newTestLoginThrottle())
+			ctx := context.Background()
+
+			user, err := service.Register(ctx, "staff@example.com", "password123", "Test", "User")
+			if err != nil {
+				t.Logf("FAIL: Register failed: %v", err)
+				return false
+			}
+
+			want := make(map[string]bool)
+			for i, grant := range granted {
+				if !grant {
+					continue
+				}
+				roleRepo.grant(user.ID, roleNames[i])
+				for _, permission := range roleRepo.roles[roleNames[i]].Permissions {
+					want[permission] = true
+				}
+			}
+
+			accessToken, _, _, err := service.Login(ctx, "staff@example.com", "password123", ClientInfo{})
+			if err != nil {
+				t.Logf("FAIL: Login failed: %v", err)
+				return false
+			}
+			claims, err := tokens.Verify(accessToken)
+			if err != nil {
+				t.Logf("FAIL: Verify failed: %v", err)
+				return false
+			}
+
+			if len(claims.Permissions) != len(want) {
+				t.Logf("FAIL: Expected %d permissions, got %v", len(want), claims.Permissions)
+				return false
+			}
+			for _, permission := range claims.Permissions {
+				if !want[permission] {
+					t.Logf("FAIL: Unexpected permission %s", permission)
+					return false
+				}
+			}
+			for i, grant := range granted {
+				if claims.HasRole(roleNames[i]) != grant {
+					t.Logf("FAIL: Role %s granted=%v but token says %v", roleNames[i], grant, claims.Roles)
+					return false
+				}
+			}
+
+			return true
+		},
+		gen.SliceOfN(len(roleNames), gen.Bool()),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestRoleService_AssignAndRevoke(t *testing.T) {
+	userRepo := newMockUserRepository()
+	roleRepo := newMockRoleRepository()
+	roles := NewRoleService(&mockTransactor{}, roleRepo, userRepo)
+	ctx := context.Background()
+	user := &domain.User{ID: uuid.New(), Email: "cook@example.com"}
+	_ = userRepo.Create(ctx, user)
+
+	got, err := roles.AssignRole(ctx, user.ID, domain.RoleKitchen, nil)
+	if err != nil {
+		t.Fatalf("AssignRole failed: %v", err)
+	}
+	if len(got) != 1 || got[0].Role != domain.RoleKitchen || got[0].StoreID != nil {
+		t.Errorf("expected [kitchen], got %v", got)
+	}
+
+	// Assigning again is harmless
+	if got, err = roles.AssignRole(ctx, user.ID, domain.RoleKitchen, nil); err != nil || len(got) != 1 {
+		t.Errorf("expected reassigning to keep one role, got %v, %v", got, err)
+	}
+
+	if got, err = roles.RevokeRole(ctx, user.ID, domain.RoleKitchen, nil); err != nil || len(got) != 0 {
+		t.Errorf("expected no roles after revoking, got %v, %v", got, err)
+	}
+	if _, err = roles.RevokeRole(ctx, user.ID, domain.RoleKitchen, nil); err != repository.ErrRoleAssignmentNotFound {
+		t.Errorf("expected ErrRoleAssignmentNotFound, got %v", err)
+	}
+
+	if _, err = roles.AssignRole(ctx, user.ID, "owner", nil); err != repository.ErrRoleNotFound {
+		t.Errorf("expected ErrRoleNotFound, got %v", err)
+	}
+	if _, err = roles.AssignRole(ctx, uuid.New(), domain.RoleKitchen, nil); err != repository.ErrUserNotFound {
+		t.Errorf("expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestRoleService_KeepsLastAdmin(t *testing.T) {
+	userRepo := newMockUserRepository()
+	roleRepo := newMockRoleRepository()
+	roles := NewRoleService(&mockTransactor{}, roleRepo, userRepo)
+	ctx := context.Background()
+	first := &domain.User{ID: uuid.New(), Email: "first@example.com"}
+	second := &domain.User{ID: uuid.New(), Email: "second@example.com"}
+	_ = userRepo.Create(ctx, first)
+	_ = userRepo.Create(ctx, second)
+	roleRepo.grant(first.ID, domain.RoleAdmin)
+
+	if _, err := roles.RevokeRole(ctx, first.ID, domain.RoleAdmin, nil); err != ErrLastAdmin {
+		t.Fatalf("expected ErrLastAdmin, got %v", err)
+	}
+	if _, err := roles.RevokeRole(ctx, second.ID, domain.RoleAdmin, nil); err != repository.ErrRoleAssignmentNotFound {
+		t.Errorf("expected ErrRoleAssignmentNotFound for a non-admin, got %v", err)
+	}
+
+	if _, err := roles.AssignRole(ctx, second.ID, domain.RoleAdmin, nil); err != nil {
+		t.Fatalf("AssignRole failed: %v", err)
+	}
+	if _, err := roles.RevokeRole(ctx, first.ID, domain.RoleAdmin, nil); err != nil {
+		t.Errorf("expected revoking to succeed with another admin, got %v", err)
+	}
+}
+
+func TestRefreshToken_PicksUpRoleChanges(t *testing.T) {
+	roleRepo := newMockRoleRepository()
+	tokens := newTestTokenManager()
+	service := NewUserService(&mockTransactor{}, newMockUserRepository(), roleRepo, newMockRefreshTokenRepository(), tokens, DefaultTokenPolicy(), newTestLoginThrottle())
+	ctx := context.Background()
+
+	user, err := service.Register(ctx, "driver@example.com", "password123", "Test", "User")
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	_, refreshToken, _, err := service.Login(ctx, "driver@example.com", "password123", ClientInfo{})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	roleRepo.grant(user.ID, domain.RoleDriver)
+	accessToken, _, err := service.RefreshToken(ctx, refreshToken, ClientInfo{})
+	if err != nil {
+		t.Fatalf("RefreshToken failed: %v", err)
+	}
+
+	var claims *auth.Claims
+	if claims, err = tokens.Verify(accessToken); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !claims.HasRole(domain.RoleDriver) || !claims.HasPermission(domain.PermissionOrdersTransition) {
+		t.Errorf("refreshed token should carry the driver role, got %+v", claims.Access)
+	}
+}
+
+func TestRoleService_StoreScopedRolesOnlyGrantAtTheirStore(t *testing.T) {
+	userRepo := newMockUserRepository()
+	roleRepo := newMockRoleRepository()
+	tokens := newTestTokenManager()
+	users := NewUserService(&mockTransactor{}, userRepo, roleRepo, newMockRefreshTokenRepository(), tokens, DefaultTokenPolicy(), newTestLoginThrottle())
+	roles := NewRoleService(&mockTransactor{}, roleRepo, userRepo)
+	ctx := context.Background()
+	storeID := uuid.New()
+
+	user, err := users.Register(ctx, "cook@example.com", "password123", "Test", "User")
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	got, err := roles.AssignRole(ctx, user.ID, domain.RoleKitchen, &storeID)
+	if err != nil {
+		t.Fatalf("AssignRole failed: %v", err)
+	}
+	if len(got) != 1 || got[0].StoreID == nil || *got[0].StoreID != storeID {
+		t.Fatalf("expected kitchen at one store, got %+v", got)
+	}
+
+	accessToken, _, _, err := users.Login(ctx, "cook@example.com", "password123", ClientInfo{})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	claims, err := tokens.Verify(accessToken)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	if claims.HasPermission(domain.PermissionOrdersTransition) {
+		t.Errorf("a store-scoped role must not grant permissions everywhere, got %+v", claims.Access)
+	}
+	if !claims.HasStorePermission(storeID, domain.PermissionOrdersTransition) {
+		t.Errorf("expected orders:transition at the assigned store, got %+v", claims.Access)
+	}
+	if claims.HasStorePermission(uuid.New(), domain.PermissionOrdersTransition) {
+		t.Errorf("expected no permissions at another store, got %+v", claims.Access)
+	}
+
+	// The global assignment is separate from the store-scoped one
+	if _, err := roles.RevokeRole(ctx, user.ID, domain.RoleKitchen, nil); err != repository.ErrRoleAssignmentNotFound {
+		t.Errorf("expected ErrRoleAssignmentNotFound for the global assignment, got %v", err)
+	}
+	if got, err = roles.RevokeRole(ctx, user.ID, domain.RoleKitchen, &storeID); err != nil || len(got) != 0 {
+		t.Errorf("expected no roles after revoking, got %v, %v", got, err)
+	}
+}
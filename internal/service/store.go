@@ -0,0 +1,562 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidStore     = errors.New("invalid store")
+	ErrStoreInactive    = errors.New("store is not taking orders")
+	ErrStoreNotSelected = errors.New("select a store before adding items to the cart")
+	ErrStoreClosed      = errors.New("store is closed")
+	ErrInvalidSchedule  = errors.New("invalid schedule")
+	ErrSlotFull         = errors.New("time slot is fully booked")
+	ErrInvalidZone      = errors.New("invalid delivery zone")
+)
+
+// scheduleDays is how many local store days, today included, orders can be scheduled into
+const scheduleDays = 7
+
+// StoreAccess reports the permissions a staff member holds at a store.
+// auth.Access and auth.Principal implement it.
+type StoreAccess interface {
+	HasStorePermission(storeID uuid.UUID, permission string) bool
+}
+
+// StoreInput holds the full set of writable store attributes
+type StoreInput struct {
+	Name         string
+	AddressLine1 string
+	AddressLine2 string
+	City         string
+	PostalCode   string
+	Country      string
+	Latitude     float64
+	Longitude    float64
+	Timezone     string
+	Phone        string
+	Active       bool
+	SlotMinutes  int
+	SlotCapacity int
+	LeadMinutes  int
+}
+
+// StoreProductInput holds a store's listing of a catalog product
+type StoreProductInput struct {
+	Available bool
+	Stock     int
+	Price     *domain.Money
+}
+
+// DeliveryZoneInput holds the full set of writable delivery zone attributes. A zone has
+// either a positive RadiusMeters or a Polygon of at least three points.
+type DeliveryZoneInput struct {
+	Name          string
+	RadiusMeters  int
+	Polygon       []domain.GeoPoint
+	MinOrderValue domain.Money
+	DeliveryFee   domain.Money
+	Active        bool
+}
+
+// StoreService defines the interface for store management and store menus
+type StoreService interface {
+	ListStores(ctx context.Context, includeInactive bool) ([]*domain.Store, error)
+	GetStore(ctx context.Context, id uuid.UUID) (*domain.Store, error)
+	CreateStore(ctx context.Context, input StoreInput) (*domain.Store, error)
+	UpdateStore(ctx context.Context, id uuid.UUID, input StoreInput) (*domain.Store, error)
+	SetOpeningHours(ctx context.Context, id uuid.UUID, hours []*domain.OpeningHours) (*domain.Store, error)
+	SetHoursExceptions(ctx context.Context, id uuid.UUID, exceptions []*domain.HoursException) (*domain.Store, error)
+	ListSlots(ctx context.Context, id uuid.UUID, date string) ([]*domain.TimeSlot, error)
+	ListStoreProducts(ctx context.Context, id uuid.UUID) ([]*domain.StoreProduct, error)
+	SetStoreProduct(ctx context.Context, id, productID uuid.UUID, input StoreProductInput) (*domain.StoreProduct, error)
+	ListDeliveryZones(ctx context.Context, id uuid.UUID) ([]*domain.DeliveryZone, error)
+	CreateDeliveryZone(ctx context.Context, id uuid.UUID, input DeliveryZoneInput) (*domain.DeliveryZone, error)
+	UpdateDeliveryZone(ctx context.Context, id, zoneID uuid.UUID, input DeliveryZoneInput) (*domain.DeliveryZone, error)
+	DeleteDeliveryZone(ctx context.Context, id, zoneID uuid.UUID) error
+}
+
+type storeService struct {
+	transactor  repository.Transactor
+	storeRepo   repository.StoreRepository
+	productRepo repository.ProductRepository
+	orderRepo   repository.OrderRepository
+}
+
+// NewStoreService creates a new instance of StoreService
+func NewStoreService(
+	transactor repository.Transactor,
+	storeRepo repository.StoreRepository,
+	productRepo repository.ProductRepository,
+	orderRepo repository.OrderRepository,
+) StoreService {
+	return &storeService{
+		transactor:  transactor,
+		storeRepo:   storeRepo,
+		productRepo: productRepo,
+		orderRepo:   orderRepo,
+	}
+}
+
+// ListStores returns stores ordered by name, leaving out inactive ones unless asked for
+func (s *storeService) ListStores(ctx context.Context, includeInactive bool) ([]*domain.Store, error) {
+	stores, err := s.storeRepo.List(ctx, !includeInactive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stores: %w", err)
+	}
+	return stores, nil
+}
+
+// GetStore retrieves a store with its opening hours and their exceptions
+func (s *storeService) GetStore(ctx context.Context, id uuid.UUID) (*domain.Store, error) {
+	return findStore(ctx, s.storeRepo, id)
+}
+
+// CreateStore validates input and adds a store without opening hours or products
+func (s *storeService) CreateStore(ctx context.Context, input StoreInput) (*domain.Store, error) {
+	now := time.Now()
+	store := &domain.Store{
+		ID:              uuid.New(),
+		OpeningHours:    []*domain.OpeningHours{},
+		HoursExceptions: []*domain.HoursException{},
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := applyStoreInput(store, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.storeRepo.Create(ctx, store); err != nil {
+		return nil, fmt.Errorf("failed to create store: %w", err)
+	}
+
+	return store, nil
+}
+
+// UpdateStore validates input and replaces every writable attribute of a store
+func (s *storeService) UpdateStore(ctx context.Context, id uuid.UUID, input StoreInput) (*domain.Store, error) {
+	store, err := s.GetStore(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyStoreInput(store, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.storeRepo.Update(ctx, store); err != nil {
+		if err == repository.ErrStoreNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update store: %w", err)
+	}
+
+	return store, nil
+}
+
+// SetOpeningHours replaces every opening period of a store. Periods of the same weekday must
+// not overlap; a period closing at or before its opening time runs past midnight.
+func (s *storeService) SetOpeningHours(ctx context.Context, id uuid.UUID, hours []*domain.OpeningHours) (*domain.Store, error) {
+	if err := validateOpeningHours(hours); err != nil {
+		return nil, err
+	}
+
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		return s.storeRepo.ReplaceOpeningHoursTx(ctx, tx, id, hours)
+	})
+	if err != nil {
+		if err == repository.ErrStoreNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to set opening hours: %w", err)
+	}
+
+	return s.GetStore(ctx, id)
+}
+
+// SetHoursExceptions replaces every opening hours exception of a store. An exception
+// either closes the store for its date or replaces that date's weekly hours with its own.
+func (s *storeService) SetHoursExceptions(ctx context.Context, id uuid.UUID, exceptions []*domain.HoursException) (*domain.Store, error) {
+	if err := validateHoursExceptions(exceptions); err != nil {
+		return nil, err
+	}
+
+	err := s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		return s.storeRepo.ReplaceHoursExceptionsTx(ctx, tx, id, exceptions)
+	})
+	if err != nil {
+		if err == repository.ErrStoreNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to set opening hours exceptions: %w", err)
+	}
+
+	return s.GetStore(ctx, id)
+}
+
+// ListSlots returns the time slots orders can still be scheduled into on a local store
+// date, "YYYY-MM-DD", defaulting to today. Slots starting within the store's lead time are
+// left out and each slot's remaining capacity accounts for the orders already booked.
+func (s *storeService) ListSlots(ctx context.Context, id uuid.UUID, date string) ([]*domain.TimeSlot, error) {
+	store, err := s.GetStore(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !store.Active {
+		return nil, ErrStoreInactive
+	}
+
+	now := time.Now()
+	day := now
+	if date != "" {
+		day, err = time.ParseInLocation(domain.DateLayout, date, store.Location())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not a date (YYYY-MM-DD)", ErrInvalidSchedule, date)
+		}
+	}
+	if !withinScheduleDays(store, day, now) {
+		return nil, fmt.Errorf("%w: orders can only be scheduled for the next %d days", ErrInvalidSchedule, scheduleDays)
+	}
+
+	slots := bookableSlots(store, day, now)
+	if len(slots) == 0 {
+		return slots, nil
+	}
+
+	booked, err := s.orderRepo.ListScheduledTimes(ctx, id, slots[0].Start, slots[len(slots)-1].End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled orders: %w", err)
+	}
+
+	for _, at := range booked {
+		for _, slot := range slots {
+			if !at.Before(slot.Start) && at.Before(slot.End) && slot.Remaining > 0 {
+				slot.Remaining--
+				break
+			}
+		}
+	}
+
+	return slots, nil
+}
+
+// ListStoreProducts returns a store's listings of catalog products
+func (s *storeService) ListStoreProducts(ctx context.Context, id uuid.UUID) ([]*domain.StoreProduct, error) {
+	if _, err := s.GetStore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	products, err := s.storeRepo.ListProducts(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list store products: %w", err)
+	}
+	return products, nil
+}
+
+// SetStoreProduct lists a catalog product at a store, or updates its availability, stock and
+// price there. A nil price sells the product at its catalog price.
+func (s *storeService) SetStoreProduct(ctx context.Context, id, productID uuid.UUID, input StoreProductInput) (*domain.StoreProduct, error) {
+	if input.Stock < 0 {
+		return nil, fmt.Errorf("%w: stock must not be negative", ErrInvalidStore)
+	}
+	if input.Price != nil && input.Price.IsNegative() {
+		return nil, fmt.Errorf("%w: price must not be negative", ErrInvalidStore)
+	}
+
+	if _, err := s.GetStore(ctx, id); err != nil {
+		return nil, err
+	}
+	if _, err := s.productRepo.FindByID(ctx, productID); err != nil {
+		if err == repository.ErrProductNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+
+	product := &domain.StoreProduct{
+		StoreID:   id,
+		ProductID: productID,
+		Available: input.Available,
+		Stock:     input.Stock,
+		Price:     input.Price,
+		UpdatedAt: time.Now(),
+	}
+
+	if err := s.storeRepo.SaveProduct(ctx, product); err != nil {
+		if err == repository.ErrStoreNotFound || err == repository.ErrProductNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to save store product: %w", err)
+	}
+
+	return product, nil
+}
+
+// ListDeliveryZones returns a store's delivery zones, including inactive ones
+func (s *storeService) ListDeliveryZones(ctx context.Context, id uuid.UUID) ([]*domain.DeliveryZone, error) {
+	if _, err := s.GetStore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	zones, err := s.storeRepo.ListDeliveryZones(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delivery zones: %w", err)
+	}
+	return zones, nil
+}
+
+// CreateDeliveryZone validates input and adds a delivery zone to a store
+func (s *storeService) CreateDeliveryZone(ctx context.Context, id uuid.UUID, input DeliveryZoneInput) (*domain.DeliveryZone, error) {
+	now := time.Now()
+	zone := &domain.DeliveryZone{
+		ID:        uuid.New(),
+		StoreID:   id,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := applyDeliveryZoneInput(zone, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.storeRepo.CreateDeliveryZone(ctx, zone); err != nil {
+		if err == repository.ErrStoreNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create delivery zone: %w", err)
+	}
+
+	return zone, nil
+}
+
+// UpdateDeliveryZone validates input and replaces every writable attribute of one of a
+// store's delivery zones
+func (s *storeService) UpdateDeliveryZone(ctx context.Context, id, zoneID uuid.UUID, input DeliveryZoneInput) (*domain.DeliveryZone, error) {
+	zone := &domain.DeliveryZone{ID: zoneID, StoreID: id}
+	if err := applyDeliveryZoneInput(zone, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.storeRepo.UpdateDeliveryZone(ctx, zone); err != nil {
+		if err == repository.ErrDeliveryZoneNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update delivery zone: %w", err)
+	}
+
+	return zone, nil
+}
+
+// DeleteDeliveryZone removes one of a store's delivery zones
+func (s *storeService) DeleteDeliveryZone(ctx context.Context, id, zoneID uuid.UUID) error {
+	if err := s.storeRepo.DeleteDeliveryZone(ctx, id, zoneID); err != nil {
+		if err == repository.ErrDeliveryZoneNotFound {
+			return err
+		}
+		return fmt.Errorf("failed to delete delivery zone: %w", err)
+	}
+	return nil
+}
+
+// findStore retrieves a store, passing ErrStoreNotFound through unwrapped
+func findStore(ctx context.Context, storeRepo repository.StoreRepository, id uuid.UUID) (*domain.Store, error) {
+	store, err := storeRepo.FindByID(ctx, id)
+	if err != nil {
+		if err == repository.ErrStoreNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find store: %w", err)
+	}
+	return store, nil
+}
+
+// applyStoreInput validates input and copies it onto store
+func applyStoreInput(store *domain.Store, input StoreInput) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidStore)
+	}
+	if input.Latitude < -90 || input.Latitude > 90 || input.Longitude < -180 || input.Longitude > 180 {
+		return fmt.Errorf("%w: coordinates are out of range", ErrInvalidStore)
+	}
+	if input.Timezone == "" {
+		return fmt.Errorf("%w: timezone is required", ErrInvalidStore)
+	}
+	if _, err := time.LoadLocation(input.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidStore, input.Timezone)
+	}
+	if input.SlotMinutes < 5 || input.SlotMinutes > 240 {
+		return fmt.Errorf("%w: slot length must be between 5 and 240 minutes", ErrInvalidStore)
+	}
+	if input.SlotCapacity < 1 {
+		return fmt.Errorf("%w: slot capacity must be positive", ErrInvalidStore)
+	}
+	if input.LeadMinutes < 0 {
+		return fmt.Errorf("%w: lead time must not be negative", ErrInvalidStore)
+	}
+
+	store.Name = strings.TrimSpace(input.Name)
+	store.AddressLine1 = input.AddressLine1
+	store.AddressLine2 = input.AddressLine2
+	store.City = input.City
+	store.PostalCode = input.PostalCode
+	store.Country = strings.ToUpper(input.Country)
+	store.Latitude = input.Latitude
+	store.Longitude = input.Longitude
+	store.Timezone = input.Timezone
+	store.Phone = input.Phone
+	store.Active = input.Active
+	store.SlotMinutes = input.SlotMinutes
+	store.SlotCapacity = input.SlotCapacity
+	store.LeadMinutes = input.LeadMinutes
+	return nil
+}
+
+// applyDeliveryZoneInput validates input and copies it onto zone
+func applyDeliveryZoneInput(zone *domain.DeliveryZone, input DeliveryZoneInput) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidZone)
+	}
+	if (input.RadiusMeters > 0) == (len(input.Polygon) > 0) {
+		return fmt.Errorf("%w: a zone needs either a radius or a polygon", ErrInvalidZone)
+	}
+	if input.RadiusMeters < 0 {
+		return fmt.Errorf("%w: radius must be positive", ErrInvalidZone)
+	}
+	if len(input.Polygon) > 0 && len(input.Polygon) < 3 {
+		return fmt.Errorf("%w: a polygon needs at least three points", ErrInvalidZone)
+	}
+	for _, point := range input.Polygon {
+		if point.Latitude < -90 || point.Latitude > 90 || point.Longitude < -180 || point.Longitude > 180 {
+			return fmt.Errorf("%w: polygon coordinates are out of range", ErrInvalidZone)
+		}
+	}
+	if input.MinOrderValue.IsNegative() || input.DeliveryFee.IsNegative() {
+		return fmt.Errorf("%w: minimum order value and delivery fee must not be negative", ErrInvalidZone)
+	}
+
+	zone.Name = strings.TrimSpace(input.Name)
+	zone.RadiusMeters = input.RadiusMeters
+	zone.Polygon = input.Polygon
+	zone.MinOrderValue = input.MinOrderValue
+	zone.DeliveryFee = input.DeliveryFee
+	zone.Active = input.Active
+	return nil
+}
+
+// validateOpeningHours checks weekdays and times and that no two periods of a weekday overlap
+func validateOpeningHours(hours []*domain.OpeningHours) error {
+	type period struct{ opens, closes int }
+	byWeekday := make(map[int][]period)
+
+	for _, h := range hours {
+		if h.Weekday < 0 || h.Weekday > 6 {
+			return fmt.Errorf("%w: weekday must be between 0 and 6", ErrInvalidStore)
+		}
+		opens, err := parseClock(h.Opens)
+		if err != nil {
+			return err
+		}
+		closes, err := parseClock(h.Closes)
+		if err != nil {
+			return err
+		}
+		if opens == closes {
+			return fmt.Errorf("%w: opening hours must not open and close at the same time", ErrInvalidStore)
+		}
+		// Periods running past midnight are compared as if the day went on
+		if closes < opens {
+			closes += 24 * 60
+		}
+		byWeekday[h.Weekday] = append(byWeekday[h.Weekday], period{opens, closes})
+	}
+
+	for _, periods := range byWeekday {
+		sort.Slice(periods, func(i, j int) bool { return periods[i].opens < periods[j].opens })
+		for i := 1; i < len(periods); i++ {
+			if periods[i].opens < periods[i-1].closes {
+				return fmt.Errorf("%w: opening hours of the same weekday overlap", ErrInvalidStore)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateHoursExceptions checks dates and times and that no date has two exceptions
+func validateHoursExceptions(exceptions []*domain.HoursException) error {
+	dates := make(map[string]bool, len(exceptions))
+
+	for _, e := range exceptions {
+		if _, err := time.Parse(domain.DateLayout, e.Date); err != nil {
+			return fmt.Errorf("%w: %q is not a date (YYYY-MM-DD)", ErrInvalidStore, e.Date)
+		}
+		if dates[e.Date] {
+			return fmt.Errorf("%w: %s has more than one exception", ErrInvalidStore, e.Date)
+		}
+		dates[e.Date] = true
+
+		if e.Closed() {
+			continue
+		}
+		if e.Opens == "" || e.Closes == "" {
+			return fmt.Errorf("%w: an exception needs both opening and closing times, or neither to close", ErrInvalidStore)
+		}
+		opens, err := parseClock(e.Opens)
+		if err != nil {
+			return err
+		}
+		closes, err := parseClock(e.Closes)
+		if err != nil {
+			return err
+		}
+		if opens == closes {
+			return fmt.Errorf("%w: opening hours must not open and close at the same time", ErrInvalidStore)
+		}
+	}
+
+	return nil
+}
+
+// withinScheduleDays reports whether day falls on one of the local store dates orders can
+// be scheduled into, from today on
+func withinScheduleDays(store *domain.Store, day, now time.Time) bool {
+	loc := store.Location()
+	year, month, date := now.In(loc).Date()
+	today := time.Date(year, month, date, 0, 0, 0, 0, loc)
+	year, month, date = day.In(loc).Date()
+	requested := time.Date(year, month, date, 0, 0, 0, 0, loc)
+
+	return !requested.Before(today) && requested.Before(today.AddDate(0, 0, scheduleDays))
+}
+
+// bookableSlots returns the slots on day's local store date that start no sooner than the
+// store's lead time from now
+func bookableSlots(store *domain.Store, day, now time.Time) []*domain.TimeSlot {
+	earliest := now.Add(time.Duration(store.LeadMinutes) * time.Minute)
+	slots := []*domain.TimeSlot{}
+	for _, slot := range store.SlotsOn(day) {
+		if !slot.Start.Before(earliest) {
+			slots = append(slots, slot)
+		}
+	}
+	return slots
+}
+
+// parseClock parses an "HH:MM" time of day into minutes since midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse(domain.ClockLayout, value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q is not a time of day (HH:MM)", ErrInvalidStore, value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,449 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// testStoreID is the active store carts are filled at unless a test selects another.
+// It is open around the clock.
+var testStoreID = uuid.New()
+
+type mockStoreRepository struct {
+	stores   map[uuid.UUID]*domain.Store
+	products map[uuid.UUID]map[uuid.UUID]*domain.StoreProduct
+	zones    map[uuid.UUID]*domain.DeliveryZone
+}
+
+func newMockStoreRepository() *mockStoreRepository {
+	m := &mockStoreRepository{
+		stores:   make(map[uuid.UUID]*domain.Store),
+		products: make(map[uuid.UUID]map[uuid.UUID]*domain.StoreProduct),
+		zones:    make(map[uuid.UUID]*domain.DeliveryZone),
+	}
+	m.stores[testStoreID] = &domain.Store{
+		ID:              testStoreID,
+		Name:            "Test store",
+		Latitude:        41.9028,
+		Longitude:       12.4964,
+		Timezone:        "UTC",
+		Active:          true,
+		SlotMinutes:     domain.DefaultSlotMinutes,
+		SlotCapacity:    domain.DefaultSlotCapacity,
+		LeadMinutes:     domain.DefaultLeadMinutes,
+		OpeningHours:    alwaysOpenHours(),
+		HoursExceptions: []*domain.HoursException{},
+	}
+	return m
+}
+
+// alwaysOpenHours opens a store every day and night
+func alwaysOpenHours() []*domain.OpeningHours {
+	hours := []*domain.OpeningHours{}
+	for weekday := 0; weekday < 7; weekday++ {
+		hours = append(hours,
+			&domain.OpeningHours{Weekday: weekday, Opens: "00:00", Closes: "12:00"},
+			&domain.OpeningHours{Weekday: weekday, Opens: "12:00", Closes: "00:00"},
+		)
+	}
+	return hours
+}
+
+func (m *mockStoreRepository) Create(ctx context.Context, store *domain.Store) error {
+	m.stores[store.ID] = store
+	return nil
+}
+
+func (m *mockStoreRepository) Update(ctx context.Context, store *domain.Store) error {
+	if _, exists := m.stores[store.ID]; !exists {
+		return repository.ErrStoreNotFound
+	}
+	m.stores[store.ID] = store
+	return nil
+}
+
+func (m *mockStoreRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Store, error) {
+	store, exists := m.stores[id]
+	if !exists {
+		return nil, repository.ErrStoreNotFound
+	}
+	copied := *store
+	return &copied, nil
+}
+
+func (m *mockStoreRepository) List(ctx context.Context, activeOnly bool) ([]*domain.Store, error) {
+	stores := []*domain.Store{}
+	for _, store := range m.stores {
+		if !activeOnly || store.Active {
+			stores = append(stores, store)
+		}
+	}
+	return stores, nil
+}
+
+func (m *mockStoreRepository) ReplaceOpeningHoursTx(ctx context.Context, tx *sql.Tx, storeID uuid.UUID, hours []*domain.OpeningHours) error {
+	store, exists := m.stores[storeID]
+	if !exists {
+		return repository.ErrStoreNotFound
+	}
+	store.OpeningHours = hours
+	return nil
+}
+
+func (m *mockStoreRepository) ListProducts(ctx context.Context, storeID uuid.UUID) ([]*domain.StoreProduct, error) {
+	products := []*domain.StoreProduct{}
+	for _, product := range m.products[storeID] {
+		products = append(products, product)
+	}
+	return products, nil
+}
+
+func (m *mockStoreRepository) SaveProduct(ctx context.Context, product *domain.StoreProduct) error {
+	if m.products[product.StoreID] == nil {
+		m.products[product.StoreID] = make(map[uuid.UUID]*domain.StoreProduct)
+	}
+	m.products[product.StoreID][product.ProductID] = product
+	return nil
+}
+
+func (m *mockStoreRepository) ReplaceHoursExceptionsTx(ctx context.Context, tx *sql.Tx, storeID uuid.UUID, exceptions []*domain.HoursException) error {
+	store, exists := m.stores[storeID]
+	if !exists {
+		return repository.ErrStoreNotFound
+	}
+	store.HoursExceptions = exceptions
+	return nil
+}
+
+func (m *mockStoreRepository) LockTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	if _, exists := m.stores[id]; !exists {
+		return repository.ErrStoreNotFound
+	}
+	return nil
+}
+
+func (m *mockStoreRepository) ListDeliveryZones(ctx context.Context, storeID uuid.UUID) ([]*domain.DeliveryZone, error) {
+	zones := []*domain.DeliveryZone{}
+	for _, zone := range m.zones {
+		if zone.StoreID == storeID {
+			zones = append(zones, zone)
+		}
+	}
+	return zones, nil
+}
+
+func (m *mockStoreRepository) CreateDeliveryZone(ctx context.Context, zone *domain.DeliveryZone) error {
+	if _, exists := m.stores[zone.StoreID]; !exists {
+		return repository.ErrStoreNotFound
+	}
+	m.zones[zone.ID] = zone
+	return nil
+}
+
+func (m *mockStoreRepository) UpdateDeliveryZone(ctx context.Context, zone *domain.DeliveryZone) error {
+	existing, exists := m.zones[zone.ID]
+	if !exists || existing.StoreID != zone.StoreID {
+		return repository.ErrDeliveryZoneNotFound
+	}
+	m.zones[zone.ID] = zone
+	return nil
+}
+
+func (m *mockStoreRepository) DeleteDeliveryZone(ctx context.Context, storeID, id uuid.UUID) error {
+	existing, exists := m.zones[id]
+	if !exists || existing.StoreID != storeID {
+		return repository.ErrDeliveryZoneNotFound
+	}
+	delete(m.zones, id)
+	return nil
+}
+
+// Feature: ordering-platform, Property 90: Opening periods of the same weekday never overlap
+func TestProperty_OverlappingOpeningHoursRejected(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("a store accepts two periods of a weekday only when they do not overlap", prop.ForAll(
+		func(weekday, firstOpens, firstLength, secondOpens, secondLength int) bool {
+			storeRepo := newMockStoreRepository()
+			storeRepo.stores[testStoreID].OpeningHours = []*domain.OpeningHours{}
+			service := NewStoreService(&mockTransactor{}, storeRepo, newMockProductRepository(), newMockOrderRepository())
+
+			clock := func(minutes int) string {
+				minutes %= 24 * 60
+				return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+			}
+			hours := []*domain.OpeningHours{
+				{Weekday: weekday, Opens: clock(firstOpens), Closes: clock(firstOpens + firstLength)},
+				{Weekday: weekday, Opens: clock(secondOpens), Closes: clock(secondOpens + secondLength)},
+			}
+
+			// Periods running past midnight are compared on the same extended day
+			overlaps := firstOpens < secondOpens+secondLength && secondOpens < firstOpens+firstLength
+
+			_, err := service.SetOpeningHours(context.Background(), testStoreID, hours)
+			if overlaps {
+				return errors.Is(err, ErrInvalidStore) && len(storeRepo.stores[testStoreID].OpeningHours) == 0
+			}
+			return err == nil && len(storeRepo.stores[testStoreID].OpeningHours) == 2
+		},
+		gen.IntRange(0, 6),
+		gen.IntRange(0, 24*60-1),
+		gen.IntRange(1, 12*60),
+		gen.IntRange(0, 24*60-1),
+		gen.IntRange(1, 12*60),
+	))
+
+	properties.TestingRun(t)
+}
+
+func TestStoreService_SetOpeningHoursValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		hours   []*domain.OpeningHours
+		wantErr bool
+	}{
+		{"closed every day", []*domain.OpeningHours{}, false},
+		{"lunch and dinner", []*domain.OpeningHours{{Weekday: 1, Opens: "11:30", Closes: "14:30"}, {Weekday: 1, Opens: "18:00", Closes: "23:00"}}, false},
+		{"past midnight", []*domain.OpeningHours{{Weekday: 5, Opens: "18:00", Closes: "02:00"}}, false},
+		{"weekday out of range", []*domain.OpeningHours{{Weekday: 7, Opens: "11:00", Closes: "22:00"}}, true},
+		{"not a time", []*domain.OpeningHours{{Weekday: 1, Opens: "11am", Closes: "22:00"}}, true},
+		{"opens when it closes", []*domain.OpeningHours{{Weekday: 1, Opens: "11:00", Closes: "11:00"}}, true},
+		{"touching periods", []*domain.OpeningHours{{Weekday: 2, Opens: "11:00", Closes: "15:00"}, {Weekday: 2, Opens: "15:00", Closes: "22:00"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewStoreService(&mockTransactor{}, newMockStoreRepository(), newMockProductRepository(), newMockOrderRepository())
+
+			_, err := service.SetOpeningHours(context.Background(), testStoreID, tt.hours)
+			if tt.wantErr != errors.Is(err, ErrInvalidStore) {
+				t.Errorf("expected invalid store error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestStoreService_CreateStoreRejectsUnknownTimezone(t *testing.T) {
+	service := NewStoreService(&mockTransactor{}, newMockStoreRepository(), newMockProductRepository(), newMockOrderRepository())
+
+	_, err := service.CreateStore(context.Background(), StoreInput{Name: "Harbour", Timezone: "Mars/Olympus"})
+	if !errors.Is(err, ErrInvalidStore) {
+		t.Fatalf("expected ErrInvalidStore, got %v", err)
+	}
+
+	store, err := service.CreateStore(context.Background(), StoreInput{
+		Name:         "Harbour",
+		Country:      "de",
+		Timezone:     "Europe/Berlin",
+		Active:       true,
+		SlotMinutes:  domain.DefaultSlotMinutes,
+		SlotCapacity: domain.DefaultSlotCapacity,
+	})
+	if err != nil {
+		t.Fatalf("CreateStore: %v", err)
+	}
+	if store.Country != "DE" || len(store.OpeningHours) != 0 {
+		t.Errorf("expected an upper-case country and no opening hours, got %+v", store)
+	}
+}
+
+func TestStoreService_SetStoreProduct(t *testing.T) {
+	ctx := context.Background()
+	storeRepo := newMockStoreRepository()
+	productRepo := newMockProductRepository()
+	service := NewStoreService(&mockTransactor{}, storeRepo, productRepo, newMockOrderRepository())
+
+	product := &domain.Product{ID: uuid.New(), Name: "Margherita", Price: domain.Cents(1000), CreatedAt: time.Now()}
+	_ = productRepo.Create(ctx, product)
+
+	price := domain.Cents(1200)
+	listed, err := service.SetStoreProduct(ctx, testStoreID, product.ID, StoreProductInput{Available: true, Stock: 5, Price: &price})
+	if err != nil {
+		t.Fatalf("SetStoreProduct: %v", err)
+	}
+	if listed.Stock != 5 || *listed.Price != price {
+		t.Errorf("expected stock 5 at 12.00, got %+v", listed)
+	}
+
+	if _, err := service.SetStoreProduct(ctx, testStoreID, product.ID, StoreProductInput{Stock: -1}); !errors.Is(err, ErrInvalidStore) {
+		t.Errorf("expected ErrInvalidStore for negative stock, got %v", err)
+	}
+	if _, err := service.SetStoreProduct(ctx, uuid.New(), product.ID, StoreProductInput{}); err != repository.ErrStoreNotFound {
+		t.Errorf("expected ErrStoreNotFound, got %v", err)
+	}
+	if _, err := service.SetStoreProduct(ctx, testStoreID, uuid.New(), StoreProductInput{}); err != repository.ErrProductNotFound {
+		t.Errorf("expected ErrProductNotFound, got %v", err)
+	}
+}
+
+func TestCartService_SelectStore(t *testing.T) {
+	ctx := context.Background()
+	productRepo := newMockProductRepository()
+	cartRepo := newMockCartRepository(productRepo)
+	storeRepo := newMockStoreRepository()
+	service := NewCartService(cartRepo, productRepo, storeRepo, nil, newTestPricingEngine(productRepo))
+	userID := uuid.New()
+
+	closed := &domain.Store{ID: uuid.New(), Name: "Closed", Timezone: "UTC", Active: false}
+	_ = storeRepo.Create(ctx, closed)
+
+	if _, err := service.SelectStore(ctx, userID, closed.ID); err != ErrStoreInactive {
+		t.Errorf("expected ErrStoreInactive, got %v", err)
+	}
+	if _, err := service.SelectStore(ctx, userID, uuid.New()); err != repository.ErrStoreNotFound {
+		t.Errorf("expected ErrStoreNotFound, got %v", err)
+	}
+
+	cart, err := service.SelectStore(ctx, userID, testStoreID)
+	if err != nil {
+		t.Fatalf("SelectStore: %v", err)
+	}
+	if cart.StoreID == nil || *cart.StoreID != testStoreID {
+		t.Errorf("expected the cart to be filled at the test store, got %v", cart.StoreID)
+	}
+}
+
+func TestStoreService_SetHoursExceptionsValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		exceptions []*domain.HoursException
+		wantErr    bool
+	}{
+		{"no exceptions", []*domain.HoursException{}, false},
+		{"closed for a holiday", []*domain.HoursException{{Date: "2024-12-25", Note: "Christmas"}}, false},
+		{"special hours past midnight", []*domain.HoursException{{Date: "2024-12-31", Opens: "18:00", Closes: "02:00"}}, false},
+		{"not a date", []*domain.HoursException{{Date: "25/12/2024"}}, true},
+		{"same date twice", []*domain.HoursException{{Date: "2024-12-25"}, {Date: "2024-12-25", Opens: "10:00", Closes: "14:00"}}, true},
+		{"opening time only", []*domain.HoursException{{Date: "2024-12-24", Opens: "10:00"}}, true},
+		{"opens when it closes", []*domain.HoursException{{Date: "2024-12-24", Opens: "10:00", Closes: "10:00"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewStoreService(&mockTransactor{}, newMockStoreRepository(), newMockProductRepository(), newMockOrderRepository())
+
+			_, err := service.SetHoursExceptions(context.Background(), testStoreID, tt.exceptions)
+			if tt.wantErr != errors.Is(err, ErrInvalidStore) {
+				t.Errorf("expected invalid store error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestStoreService_ListSlots(t *testing.T) {
+	ctx := context.Background()
+	storeRepo := newMockStoreRepository()
+	orderRepo := newMockOrderRepository()
+	service := NewStoreService(&mockTransactor{}, storeRepo, newMockProductRepository(), orderRepo)
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	date := tomorrow.Format(domain.DateLayout)
+	first := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 0, 0, 0, 0, time.UTC)
+	orderRepo.orders[uuid.New()] = &domain.Order{StoreID: testStoreID, Status: domain.OrderStatusPending, ScheduledFor: &first}
+	orderRepo.orders[uuid.New()] = &domain.Order{StoreID: testStoreID, Status: domain.OrderStatusCancelled, ScheduledFor: &first}
+
+	slots, err := service.ListSlots(ctx, testStoreID, date)
+	if err != nil {
+		t.Fatalf("ListSlots: %v", err)
+	}
+	if len(slots) != 24*60/domain.DefaultSlotMinutes {
+		t.Fatalf("expected a slot every %d minutes of the day, got %d", domain.DefaultSlotMinutes, len(slots))
+	}
+	if !slots[0].Start.Equal(first) || slots[0].Remaining != domain.DefaultSlotCapacity-1 || slots[1].Remaining != domain.DefaultSlotCapacity {
+		t.Errorf("expected only the live booking to take capacity, got %+v and %+v", slots[0], slots[1])
+	}
+
+	today, err := service.ListSlots(ctx, testStoreID, "")
+	if err != nil {
+		t.Fatalf("ListSlots: %v", err)
+	}
+	earliest := time.Now().Add(domain.DefaultLeadMinutes * time.Minute)
+	for _, slot := range today {
+		if slot.Start.Before(earliest) {
+			t.Fatalf("slot %s starts within the lead time", slot.Start)
+		}
+	}
+
+	for _, invalid := range []string{"tomorrow", time.Now().UTC().AddDate(0, 0, -1).Format(domain.DateLayout), time.Now().UTC().AddDate(0, 0, scheduleDays).Format(domain.DateLayout)} {
+		if _, err := service.ListSlots(ctx, testStoreID, invalid); !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("expected ErrInvalidSchedule for %q, got %v", invalid, err)
+		}
+	}
+
+	storeRepo.stores[testStoreID].Active = false
+	if _, err := service.ListSlots(ctx, testStoreID, date); err != ErrStoreInactive {
+		t.Errorf("expected ErrStoreInactive, got %v", err)
+	}
+}
+
+func TestCheckout_OpeningHoursAndScheduling(t *testing.T) {
+	ctx := context.Background()
+	productRepo := newMockProductRepository()
+	cartRepo := newMockCartRepository(productRepo)
+	storeRepo := newMockStoreRepository()
+	orderRepo := newMockOrderRepository()
+	cartService := NewCartService(cartRepo, productRepo, newMockStoreRepository(), nil, newTestPricingEngine(productRepo))
+	orderService := NewOrderService(&mockTransactor{}, orderRepo, cartRepo, productRepo, storeRepo, newMockAddressRepository(), nil, newTestPricingEngine(productRepo), NewOrderEventBroker())
+
+	product := &domain.Product{ID: uuid.New(), Name: "Margherita", Price: domain.Cents(900), Stock: 10}
+	_ = productRepo.Create(ctx, product)
+	fillCart := func() uuid.UUID {
+		userID := uuid.New()
+		if _, err := cartService.AddItem(ctx, userID, product.ID, nil, 1); err != nil {
+			t.Fatalf("AddItem failed: %v", err)
+		}
+		return userID
+	}
+
+	store := storeRepo.stores[testStoreID]
+	store.SlotCapacity = 1
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	slot := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 18, 0, 0, 0, time.UTC)
+
+	// Closed today and tomorrow except for the booked slot's hour
+	store.OpeningHours = []*domain.OpeningHours{}
+	store.HoursExceptions = []*domain.HoursException{{Date: tomorrow.Format(domain.DateLayout), Opens: "18:00", Closes: "19:00"}}
+
+	userID := fillCart()
+	if _, err := orderService.Checkout(ctx, userID, CheckoutOptions{}); err != ErrStoreClosed {
+		t.Fatalf("expected ErrStoreClosed, got %v", err)
+	}
+
+	for _, at := range []time.Time{slot.Add(5 * time.Minute), slot.Add(-time.Hour), slot.AddDate(0, 0, scheduleDays)} {
+		if _, err := orderService.Checkout(ctx, userID, CheckoutOptions{ScheduledFor: &at}); !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("expected ErrInvalidSchedule for %s, got %v", at, err)
+		}
+	}
+
+	// The same instant in another timezone books the same slot
+	inRome := slot.In(time.FixedZone("CET", 3600))
+	order, err := orderService.Checkout(ctx, userID, CheckoutOptions{ScheduledFor: &inRome})
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if order.ScheduledFor == nil || !order.ScheduledFor.Equal(slot) || order.ScheduledFor.Location() != time.UTC {
+		t.Errorf("expected the order scheduled for %s in UTC, got %v", slot, order.ScheduledFor)
+	}
+
+	if _, err := orderService.Checkout(ctx, fillCart(), CheckoutOptions{ScheduledFor: &slot}); err != ErrSlotFull {
+		t.Errorf("expected ErrSlotFull, got %v", err)
+	}
+
+	// A cancelled order frees its slot
+	order.Status = domain.OrderStatusCancelled
+	if _, err := orderService.Checkout(ctx, fillCart(), CheckoutOptions{ScheduledFor: &slot}); err != nil {
+		t.Errorf("expected the freed slot to be bookable, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"pizza-must/internal/domain"
+)
+
+var ErrInvalidTokenPolicy = errors.New("invalid token policy")
+
+// TokenPolicy controls how long access tokens, refresh tokens and sessions stay valid
+type TokenPolicy struct {
+	// AccessTokenTTL is the lifetime of an access token
+	AccessTokenTTL time.Duration
+	// RefreshTokenTTL is the lifetime of a refresh token; every refresh issues a new one
+	RefreshTokenTTL time.Duration
+	// SessionMaxAge caps how long a session lasts after sign-in, however often it is
+	// refreshed. Zero means no limit.
+	SessionMaxAge time.Duration
+	// IdleTimeout ends a session that has not been refreshed for this long.
+	// Zero means no limit beyond RefreshTokenTTL.
+	IdleTimeout time.Duration
+}
+
+// DefaultTokenPolicy returns the policy used when nothing is configured
+func DefaultTokenPolicy() TokenPolicy {
+	return TokenPolicy{
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: 7 * 24 * time.Hour,
+	}
+}
+
+// Validate checks that token lifetimes are positive and limits are not negative
+func (p TokenPolicy) Validate() error {
+	switch {
+	case p.AccessTokenTTL <= 0:
+		return fmt.Errorf("%w: access token lifetime must be positive", ErrInvalidTokenPolicy)
+	case p.RefreshTokenTTL <= 0:
+		return fmt.Errorf("%w: refresh token lifetime must be positive", ErrInvalidTokenPolicy)
+	case p.SessionMaxAge < 0 || p.IdleTimeout < 0:
+		return fmt.Errorf("%w: session limits must not be negative", ErrInvalidTokenPolicy)
+	}
+	return nil
+}
+
+// refreshTokenExpiry returns when a refresh token issued at now for a session that started
+// at sessionStartedAt expires; it never outlives the session's maximum age
+func (p TokenPolicy) refreshTokenExpiry(now, sessionStartedAt time.Time) time.Time {
+	expiresAt := now.Add(p.RefreshTokenTTL)
+	if p.SessionMaxAge > 0 {
+		if sessionEnd := sessionStartedAt.Add(p.SessionMaxAge); sessionEnd.Before(expiresAt) {
+			return sessionEnd
+		}
+	}
+	return expiresAt
+}
+
+// sessionExpiry returns when the session a refresh token belongs to ends: at the token's
+// expiry, at the session's maximum age, or once it has been idle for the idle timeout,
+// whichever comes first
+func (p TokenPolicy) sessionExpiry(token *domain.RefreshToken) time.Time {
+	expiresAt := token.ExpiresAt
+	if p.SessionMaxAge > 0 {
+		if sessionEnd := token.SessionStartedAt.Add(p.SessionMaxAge); sessionEnd.Before(expiresAt) {
+			expiresAt = sessionEnd
+		}
+	}
+	if p.IdleTimeout > 0 {
+		if idleEnd := token.LastUsedAt.Add(p.IdleTimeout); idleEnd.Before(expiresAt) {
+			expiresAt = idleEnd
+		}
+	}
+	return expiresAt
+}
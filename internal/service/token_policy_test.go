@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// signInWithPolicy registers a user and signs them in with a service using policy
+func signInWithPolicy(t *testing.T, policy TokenPolicy) (UserService, *mockRefreshTokenRepository, string, string) {
+	t.Helper()
+	refreshTokenRepo := newMockRefreshTokenRepository()
+	service := NewUserService(&mockTransactor{}, newMockUserRepository(), newMockRoleRepository(), refreshTokenRepo, newTestTokenManager(), policy, newTestLoginThrottle())
+	ctx := context.Background()
+
+	if _, err := service.Register(ctx, "policy@example.com", "password123", "Test", "User"); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	accessToken, refreshToken, _, err := service.Login(ctx, "policy@example.com", "password123", ClientInfo{})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	return service, refreshTokenRepo, accessToken, refreshToken
+}
+
+func TestTokenPolicy_AccessTokenTTL(t *testing.T) {
+	for _, ttl := range []time.Duration{5 * time.Minute, time.Hour} {
+		policy := DefaultTokenPolicy()
+		policy.AccessTokenTTL = ttl
+		service, _, accessToken, _ := signInWithPolicy(t, policy)
+
+		claims, err := service.ValidateToken(accessToken)
+		if err != nil {
+			t.Fatalf("ValidateToken failed: %v", err)
+		}
+		if got := claims.ExpiresAt.Sub(claims.IssuedAt.Time); got != ttl {
+			t.Errorf("access token lifetime = %s, want %s", got, ttl)
+		}
+	}
+}
+
+func TestTokenPolicy_RefreshTokenTTL(t *testing.T) {
+	for _, ttl := range []time.Duration{time.Hour, 3 * 24 * time.Hour} {
+		policy := DefaultTokenPolicy()
+		policy.RefreshTokenTTL = ttl
+		_, refreshTokenRepo, _, refreshToken := signInWithPolicy(t, policy)
+
+		stored := refreshTokenRepo.tokens[hashToken(refreshToken)]
+		if got := stored.ExpiresAt.Sub(stored.CreatedAt); got != ttl {
+			t.Errorf("refresh token lifetime = %s, want %s", got, ttl)
+		}
+	}
+}
+
+func TestTokenPolicy_RefreshAcceptance(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  TokenPolicy
+		age     time.Duration // how long ago the session started
+		idle    time.Duration // how long ago the token was last used
+		wantErr error
+	}{
+		{"fresh session", TokenPolicy{AccessTokenTTL: time.Minute, RefreshTokenTTL: 24 * time.Hour, SessionMaxAge: 2 * time.Hour, IdleTimeout: time.Hour}, time.Minute, time.Minute, nil},
+		{"past session max age", TokenPolicy{AccessTokenTTL: time.Minute, RefreshTokenTTL: 24 * time.Hour, SessionMaxAge: 2 * time.Hour}, 3 * time.Hour, time.Minute, ErrTokenExpired},
+		{"no session max age", TokenPolicy{AccessTokenTTL: time.Minute, RefreshTokenTTL: 24 * time.Hour}, 3 * time.Hour, time.Minute, nil},
+		{"past idle timeout", TokenPolicy{AccessTokenTTL: time.Minute, RefreshTokenTTL: 24 * time.Hour, IdleTimeout: time.Hour}, 2 * time.Hour, 2 * time.Hour, ErrTokenExpired},
+		{"no idle timeout", TokenPolicy{AccessTokenTTL: time.Minute, RefreshTokenTTL: 24 * time.Hour}, 2 * time.Hour, 2 * time.Hour, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, refreshTokenRepo, _, refreshToken := signInWithPolicy(t, tt.policy)
+
+			stored := refreshTokenRepo.tokens[hashToken(refreshToken)]
+			stored.SessionStartedAt = time.Now().Add(-tt.age)
+			stored.LastUsedAt = time.Now().Add(-tt.idle)
+
+			_, next, err := service.RefreshToken(context.Background(), refreshToken, ClientInfo{})
+			if err != tt.wantErr {
+				t.Fatalf("RefreshToken error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			// The rotated token belongs to the same session and never outlives its maximum age
+			replacement := refreshTokenRepo.tokens[hashToken(next)]
+			if !replacement.SessionStartedAt.Equal(stored.SessionStartedAt) {
+				t.Errorf("rotated token started a new session")
+			}
+			if tt.policy.SessionMaxAge > 0 && replacement.ExpiresAt.After(stored.SessionStartedAt.Add(tt.policy.SessionMaxAge)) {
+				t.Errorf("rotated token expires at %s, after the session max age", replacement.ExpiresAt)
+			}
+		})
+	}
+}
+
+func TestTokenPolicy_ListSessionsHidesIdleSessions(t *testing.T) {
+	policy := DefaultTokenPolicy()
+	policy.IdleTimeout = time.Hour
+	service, refreshTokenRepo, _, refreshToken := signInWithPolicy(t, policy)
+	stored := refreshTokenRepo.tokens[hashToken(refreshToken)]
+
+	sessions, err := service.ListSessions(context.Background(), stored.UserID)
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("expected one session, got %d (%v)", len(sessions), err)
+	}
+	if want := stored.LastUsedAt.Add(time.Hour); !sessions[0].ExpiresAt.Equal(want) {
+		t.Errorf("session expires at %s, want %s", sessions[0].ExpiresAt, want)
+	}
+
+	stored.LastUsedAt = time.Now().Add(-2 * time.Hour)
+	sessions, err = service.ListSessions(context.Background(), stored.UserID)
+	if err != nil || len(sessions) != 0 {
+		t.Errorf("expected the idle session to be hidden, got %d (%v)", len(sessions), err)
+	}
+}
+
+func TestTokenPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy TokenPolicy
+		valid  bool
+	}{
+		{"default", DefaultTokenPolicy(), true},
+		{"with session limits", TokenPolicy{AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour, SessionMaxAge: time.Hour, IdleTimeout: time.Minute}, true},
+		{"zero access lifetime", TokenPolicy{RefreshTokenTTL: time.Hour}, false},
+		{"zero refresh lifetime", TokenPolicy{AccessTokenTTL: time.Minute}, false},
+		{"negative idle timeout", TokenPolicy{AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour, IdleTimeout: -time.Minute}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("expected a valid policy, got %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidTokenPolicy) {
+				t.Errorf("expected ErrInvalidTokenPolicy, got %v", err)
+			}
+		})
+	}
+}
@@ -2,14 +2,20 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/url"
 	"time"
 
+	"pizza-must/internal/auth"
 	"pizza-must/internal/domain"
 	"pizza-must/internal/repository"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -18,50 +24,97 @@ const (
 	// BcryptCost is the cost factor for bcrypt hashing (10 as per requirements)
 	BcryptCost = 10
 
-	// Token expiration times
-	AccessTokenExpiration  = 15 * time.Minute
-	RefreshTokenExpiration = 7 * 24 * time.Hour
+	// opaqueTokenBytes is the number of random bytes in refresh and password reset tokens
+	opaqueTokenBytes = 32
+
+	// unknownAccountHash is a bcrypt hash with BcryptCost that logins for unknown email
+	// addresses are checked against, so they take as long as a wrong password
+	unknownAccountHash = "$2a$10$oic9JLqWNc35Ez/vH6QPRe5yFvY1fdkGdCOd0wfKaJIBARpJfFlxG"
 )
 
 var (
 	ErrInvalidCredentials = errors.New("invalid email or password")
-	ErrInvalidToken       = errors.New("invalid token")
-	ErrTokenExpired       = errors.New("token has expired")
+	ErrInvalidToken       = auth.ErrInvalidToken
+	ErrTokenExpired       = auth.ErrTokenExpired
+	ErrTokenReused        = errors.New("refresh token reuse detected")
+	ErrIncorrectPassword  = errors.New("current password is incorrect")
 )
 
+// TokenReuseError reports that a refresh token that had already been rotated was presented
+// again, which means it has leaked. Every token of its family has been revoked.
+// It matches ErrTokenReused with errors.Is.
+type TokenReuseError struct {
+	UserID   uuid.UUID
+	FamilyID uuid.UUID
+}
+
+func (e *TokenReuseError) Error() string {
+	return fmt.Sprintf("%s for family %s", ErrTokenReused, e.FamilyID)
+}
+
+func (e *TokenReuseError) Is(target error) bool {
+	return target == ErrTokenReused
+}
+
+// ClientInfo describes the client a session is signed in from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// ProfileUpdate holds the profile attributes a user can change; nil fields are left as they are
+type ProfileUpdate struct {
+	FirstName *string
+	LastName  *string
+	Phone     *string
+}
+
 // UserService defines the interface for user business logic
 type UserService interface {
 	Register(ctx context.Context, email, password, firstName, lastName string) (*domain.User, error)
-	Login(ctx context.Context, email, password string) (accessToken, refreshToken string, user *domain.User, err error)
+	Login(ctx context.Context, email, password string, client ClientInfo) (accessToken, refreshToken string, user *domain.User, err error)
 	Logout(ctx context.Context, refreshToken string) error
-	RefreshToken(ctx context.Context, refreshToken string) (newAccessToken string, err error)
-	ValidateToken(tokenString string) (*Claims, error)
+	RefreshToken(ctx context.Context, refreshToken string, client ClientInfo) (newAccessToken, newRefreshToken string, err error)
+	ValidateToken(tokenString string) (*auth.Claims, error)
 	GetUserByID(ctx context.Context, userID uuid.UUID) (*domain.User, error)
-}
-
-// Claims represents the JWT claims
-type Claims struct {
-	UserID uuid.UUID `json:"user_id"`
-	Role   string    `json:"role"`
-	jwt.RegisteredClaims
+	ListSessions(ctx context.Context, userID uuid.UUID) ([]*domain.Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error
+	RevokeAllSessions(ctx context.Context, userID uuid.UUID) error
+	UpdateProfile(ctx context.Context, userID uuid.UUID, update ProfileUpdate) (*domain.User, error)
+	ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error
+	ChangeEmail(ctx context.Context, userID uuid.UUID, currentPassword, newEmail string) (*domain.User, error)
+	DeleteAccount(ctx context.Context, userID uuid.UUID, password string) error
+	UnlockAccount(ctx context.Context, userID uuid.UUID) error
 }
 
 type userService struct {
+	transactor       repository.Transactor
 	userRepo         repository.UserRepository
+	roleRepo         repository.RoleRepository
 	refreshTokenRepo repository.RefreshTokenRepository
-	jwtSecret        string
+	tokens           auth.TokenManager
+	policy           TokenPolicy
+	loginThrottle    LoginThrottle
 }
 
 // NewUserService creates a new instance of UserService
 func NewUserService(
+	transactor repository.Transactor,
 	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
 	refreshTokenRepo repository.RefreshTokenRepository,
-	jwtSecret string,
+	tokens auth.TokenManager,
+	policy TokenPolicy,
+	loginThrottle LoginThrottle,
 ) UserService {
 	return &userService{
+		transactor:       transactor,
 		userRepo:         userRepo,
+		roleRepo:         roleRepo,
 		refreshTokenRepo: refreshTokenRepo,
-		jwtSecret:        jwtSecret,
+		tokens:           tokens,
+		policy:           policy,
+		loginThrottle:    loginThrottle,
 	}
 }
 
@@ -89,7 +142,7 @@ func (s *userService) Register(ctx context.Context, email, password, firstName,
 		PasswordHash: hashedPassword,
 		FirstName:    firstName,
 		LastName:     lastName,
-		Role:         "user", // Default role
+		Roles:        []string{},
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
@@ -102,30 +155,42 @@ func (s *userService) Register(ctx context.Context, email, password, firstName,
 	return user, nil
 }
 
-// Login authenticates a user and returns JWT tokens
-func (s *userService) Login(ctx context.Context, email, password string) (accessToken, refreshToken string, user *domain.User, err error) {
+// Login authenticates a user and returns JWT tokens. Failed logins are counted per email
+// address and client IP; once too many have failed, Login returns a LoginThrottledError.
+func (s *userService) Login(ctx context.Context, email, password string, client ClientInfo) (accessToken, refreshToken string, user *domain.User, err error) {
+	// Refuse logins for an email address or client IP with too many recent failures
+	if err := s.loginThrottle.Check(ctx, email, client.IPAddress); err != nil {
+		return "", "", nil, err
+	}
+
 	// Find user by email
 	user, err = s.userRepo.FindByEmail(ctx, email)
 	if err != nil {
 		if err == repository.ErrUserNotFound {
-			return "", "", nil, ErrInvalidCredentials
+			// Check the password anyway so unknown addresses cannot be told apart by timing
+			_ = s.verifyPassword(unknownAccountHash, password)
+			return "", "", nil, s.loginFailed(ctx, email, client)
 		}
 		return "", "", nil, fmt.Errorf("failed to find user: %w", err)
 	}
 
 	// Verify password
 	if err := s.verifyPassword(user.PasswordHash, password); err != nil {
-		return "", "", nil, ErrInvalidCredentials
+		return "", "", nil, s.loginFailed(ctx, email, client)
+	}
+
+	if err := s.loginThrottle.RecordSuccess(ctx, email); err != nil {
+		return "", "", nil, fmt.Errorf("failed to reset failed logins: %w", err)
 	}
 
 	// Generate access token
-	accessToken, err = s.generateAccessToken(user)
+	accessToken, err = s.generateAccessToken(ctx, user)
 	if err != nil {
 		return "", "", nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
 	// Generate refresh token
-	refreshToken, err = s.generateRefreshToken(ctx, user)
+	refreshToken, err = s.generateRefreshToken(ctx, user, client)
 	if err != nil {
 		return "", "", nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
@@ -135,7 +200,7 @@ func (s *userService) Login(ctx context.Context, email, password string) (access
 
 // Logout invalidates the refresh token
 func (s *userService) Logout(ctx context.Context, refreshToken string) error {
-	if err := s.refreshTokenRepo.Revoke(ctx, refreshToken); err != nil {
+	if err := s.refreshTokenRepo.Revoke(ctx, hashToken(refreshToken)); err != nil {
 		if err == repository.ErrRefreshTokenNotFound {
 			// Token doesn't exist, consider it already logged out
 			return nil
@@ -145,64 +210,285 @@ func (s *userService) Logout(ctx context.Context, refreshToken string) error {
 	return nil
 }
 
-// RefreshToken generates a new access token using a valid refresh token
-func (s *userService) RefreshToken(ctx context.Context, refreshTokenString string) (newAccessToken string, err error) {
-	// Find and validate refresh token
-	refreshToken, err := s.refreshTokenRepo.FindByToken(ctx, refreshTokenString)
+// RefreshToken rotates a valid refresh token: the presented token is revoked and a new access
+// token and a new refresh token in the same family are returned. Presenting a token that has
+// already been rotated revokes the whole family and returns a TokenReuseError.
+func (s *userService) RefreshToken(ctx context.Context, refreshTokenString string, client ClientInfo) (newAccessToken, newRefreshToken string, err error) {
+	// Find the refresh token, including revoked ones so reuse can be detected
+	refreshToken, err := s.refreshTokenRepo.FindByTokenHashIncludingRevoked(ctx, hashToken(refreshTokenString))
 	if err != nil {
-		if err == repository.ErrRefreshTokenNotFound || err == repository.ErrRefreshTokenRevoked {
-			return "", ErrInvalidToken
+		if err == repository.ErrRefreshTokenNotFound {
+			return "", "", ErrInvalidToken
+		}
+		return "", "", fmt.Errorf("failed to find refresh token: %w", err)
+	}
+
+	if refreshToken.Revoked {
+		// A token revoked by logout was never handed out again; a rotated one was
+		if refreshToken.ReplacedBy == nil {
+			return "", "", ErrInvalidToken
 		}
-		return "", fmt.Errorf("failed to find refresh token: %w", err)
+		return "", "", s.revokeReusedFamily(ctx, refreshToken)
 	}
 
-	// Check if token is expired
-	if time.Now().After(refreshToken.ExpiresAt) {
-		return "", ErrTokenExpired
+	// Check if the token or its session has expired or been idle too long
+	if time.Now().After(s.policy.sessionExpiry(refreshToken)) {
+		return "", "", ErrTokenExpired
 	}
 
 	// Get user
 	user, err := s.userRepo.FindByID(ctx, refreshToken.UserID)
 	if err != nil {
-		return "", fmt.Errorf("failed to find user: %w", err)
+		return "", "", fmt.Errorf("failed to find user: %w", err)
+	}
+
+	// Replace the presented token with a new one in the same family
+	newRefreshToken, replacement, err := s.buildRefreshToken(user.ID, refreshToken, client)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	if err := s.refreshTokenRepo.Rotate(ctx, refreshToken, replacement); err != nil {
+		if err == repository.ErrRefreshTokenRevoked {
+			// Another request rotated the token first
+			return "", "", s.revokeReusedFamily(ctx, refreshToken)
+		}
+		return "", "", fmt.Errorf("failed to rotate refresh token: %w", err)
 	}
 
 	// Generate new access token
-	newAccessToken, err = s.generateAccessToken(user)
+	newAccessToken, err = s.generateAccessToken(ctx, user)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate access token: %w", err)
+		return "", "", fmt.Errorf("failed to generate access token: %w", err)
 	}
 
-	return newAccessToken, nil
+	return newAccessToken, newRefreshToken, nil
+}
+
+// revokeReusedFamily revokes every token in the family of a reused refresh token
+func (s *userService) revokeReusedFamily(ctx context.Context, refreshToken *domain.RefreshToken) error {
+	if err := s.refreshTokenRepo.RevokeFamily(ctx, refreshToken.FamilyID); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	return &TokenReuseError{UserID: refreshToken.UserID, FamilyID: refreshToken.FamilyID}
 }
 
-// ValidateToken validates a JWT token and returns the claims
-func (s *userService) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Verify signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+// ValidateToken validates a JWT token and returns the claims.
+// It applies the same checks as the authentication middleware.
+func (s *userService) ValidateToken(tokenString string) (*auth.Claims, error) {
+	return s.tokens.Verify(tokenString)
+}
+
+// GetUserByID retrieves a user by ID together with their roles
+func (s *userService) GetUserByID(ctx context.Context, userID uuid.UUID) (*domain.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.Roles, err = s.roleRepo.ListNamesForUser(ctx, user.ID); err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	return user, nil
+}
+
+// ListSessions returns the devices a user is signed in on, most recently used first.
+// Sessions past the idle timeout are left out and expiry times account for it.
+func (s *userService) ListSessions(ctx context.Context, userID uuid.UUID) ([]*domain.Session, error) {
+	sessions, err := s.refreshTokenRepo.ListActiveSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	now := time.Now()
+	active := make([]*domain.Session, 0, len(sessions))
+	for _, session := range sessions {
+		session.ExpiresAt = s.policy.sessionExpiry(&domain.RefreshToken{
+			ExpiresAt:        session.ExpiresAt,
+			LastUsedAt:       session.LastUsedAt,
+			SessionStartedAt: session.CreatedAt,
+		})
+		if now.After(session.ExpiresAt) {
+			continue
 		}
-		return []byte(s.jwtSecret), nil
+		active = append(active, session)
+	}
+	return active, nil
+}
+
+// RevokeSession signs a user out of one session; its refresh token stops working
+func (s *userService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	if err := s.refreshTokenRepo.RevokeSession(ctx, userID, sessionID); err != nil {
+		if err == repository.ErrSessionNotFound {
+			return repository.ErrSessionNotFound
+		}
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// RevokeAllSessions signs a user out of every session.
+// Access tokens already issued stay valid until they expire.
+func (s *userService) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		if err == repository.ErrUserNotFound {
+			return repository.ErrUserNotFound
+		}
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if err := s.refreshTokenRepo.RevokeAllForUser(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// UpdateProfile changes the names and phone number of a user
+func (s *userService) UpdateProfile(ctx context.Context, userID uuid.UUID, update ProfileUpdate) (*domain.User, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.FirstName != nil {
+		user.FirstName = *update.FirstName
+	}
+	if update.LastName != nil {
+		user.LastName = *update.LastName
+	}
+	if update.Phone != nil {
+		user.Phone = *update.Phone
+	}
+	user.UpdatedAt = time.Now()
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		if err == repository.ErrUserNotFound {
+			return nil, repository.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to update profile: %w", err)
+	}
+
+	return user, nil
+}
+
+// ChangePassword replaces a user's password after checking the current one.
+// Every session is signed out, so other devices must sign in with the new password.
+func (s *userService) ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.verifyPassword(user.PasswordHash, currentPassword); err != nil {
+		return ErrIncorrectPassword
+	}
+
+	hashedPassword, err := s.hashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	if err := s.userRepo.UpdatePassword(ctx, userID, hashedPassword); err != nil {
+		if err == repository.ErrUserNotFound {
+			return repository.ErrUserNotFound
+		}
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if err := s.refreshTokenRepo.RevokeAllForUser(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// ChangeEmail moves a user to a new email address after checking their password.
+// The new address is unverified until the user verifies it again.
+func (s *userService) ChangeEmail(ctx context.Context, userID uuid.UUID, currentPassword, newEmail string) (*domain.User, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifyPassword(user.PasswordHash, currentPassword); err != nil {
+		return nil, ErrIncorrectPassword
+	}
+	if user.Email == newEmail {
+		return user, nil
+	}
+
+	user.Email = newEmail
+	user.EmailVerifiedAt = nil
+	user.UpdatedAt = time.Now()
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		if err == repository.ErrUserNotFound || err == repository.ErrUserAlreadyExists {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to change email: %w", err)
+	}
+
+	return user, nil
+}
+
+// DeleteAccount deletes a user's account after checking their password. The account is
+// anonymized rather than removed so its orders are kept; every session is signed out.
+// The last admin cannot delete their account, as that would take the admin role with it.
+func (s *userService) DeleteAccount(ctx context.Context, userID uuid.UUID, password string) error {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.verifyPassword(user.PasswordHash, password); err != nil {
+		return ErrIncorrectPassword
+	}
+
+	admin, err := s.roleRepo.FindByName(ctx, domain.RoleAdmin)
+	if err != nil {
+		return fmt.Errorf("failed to find admin role: %w", err)
+	}
+
+	err = s.transactor.WithinTx(ctx, func(tx *sql.Tx) error {
+		if err := ensureNotLastAdminTx(ctx, tx, s.roleRepo, admin.ID, userID); err != nil {
+			return err
+		}
+		return s.userRepo.AnonymizeTx(ctx, tx, userID, time.Now())
 	})
+	if err != nil {
+		if err == ErrLastAdmin || err == repository.ErrUserNotFound {
+			return err
+		}
+		return fmt.Errorf("failed to delete account: %w", err)
+	}
+	return nil
+}
 
+// UnlockAccount lifts a login lockout on a user's email address
+func (s *userService) UnlockAccount(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.findUser(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse token: %w", err)
+		return err
 	}
 
-	claims, ok := token.Claims.(*Claims)
-	if !ok || !token.Valid {
-		return nil, ErrInvalidToken
+	if err := s.loginThrottle.Unlock(ctx, user.Email); err != nil {
+		return fmt.Errorf("failed to unlock account: %w", err)
 	}
+	return nil
+}
 
-	return claims, nil
+// loginFailed records a failed login and returns ErrInvalidCredentials
+func (s *userService) loginFailed(ctx context.Context, email string, client ClientInfo) error {
+	if err := s.loginThrottle.RecordFailure(ctx, email, client.IPAddress); err != nil {
+		return fmt.Errorf("failed to record failed login: %w", err)
+	}
+	return ErrInvalidCredentials
 }
 
-// GetUserByID retrieves a user by ID
-func (s *userService) GetUserByID(ctx context.Context, userID uuid.UUID) (*domain.User, error) {
+// findUser retrieves a user with their roles, passing ErrUserNotFound through unwrapped
+func (s *userService) findUser(ctx context.Context, userID uuid.UUID) (*domain.User, error) {
 	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		if err == repository.ErrUserNotFound {
+			return nil, repository.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if user.Roles, err = s.roleRepo.ListNamesForUser(ctx, user.ID); err != nil {
+		return nil, fmt.Errorf("failed to find user roles: %w", err)
 	}
 	return user, nil
 }
@@ -221,44 +507,110 @@ func (s *userService) verifyPassword(hashedPassword, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 }
 
-// generateAccessToken generates a JWT access token with user ID and role claims
-func (s *userService) generateAccessToken(user *domain.User) (string, error) {
-	expirationTime := time.Now().Add(AccessTokenExpiration)
-	claims := &Claims{
-		UserID: user.ID,
-		Role:   user.Role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+// generateAccessToken generates a JWT access token carrying the user's roles and the
+// permissions they hold at every store and at single stores as they are now. It also fills
+// in user.Roles.
+func (s *userService) generateAccessToken(ctx context.Context, user *domain.User) (string, error) {
+	roles, err := s.roleRepo.ListNamesForUser(ctx, user.ID)
+	if err != nil {
+		return "", err
+	}
+	grants, err := s.roleRepo.ListPermissionsForUser(ctx, user.ID)
+	if err != nil {
+		return "", err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(s.jwtSecret))
+	user.Roles = roles
+	return s.tokens.Issue(user.ID, newAccess(roles, grants), s.policy.AccessTokenTTL)
+}
+
+// newAccess splits permission grants into those held at every store and those held at a
+// single store
+func newAccess(roles []string, grants []*domain.PermissionGrant) auth.Access {
+	access := auth.Access{Roles: roles, Permissions: []string{}}
+	for _, grant := range grants {
+		if grant.StoreID == nil {
+			access.Permissions = append(access.Permissions, grant.Permission)
+			continue
+		}
+		if access.StorePermissions == nil {
+			access.StorePermissions = make(map[uuid.UUID][]string)
+		}
+		access.StorePermissions[*grant.StoreID] = append(access.StorePermissions[*grant.StoreID], grant.Permission)
+	}
+	return access
+}
+
+// generateRefreshToken generates a refresh token starting a new family and stores its digest in the database
+func (s *userService) generateRefreshToken(ctx context.Context, user *domain.User, client ClientInfo) (string, error) {
+	tokenString, refreshToken, err := s.buildRefreshToken(user.ID, nil, client)
 	if err != nil {
 		return "", err
 	}
 
+	if err := s.refreshTokenRepo.Create(ctx, refreshToken); err != nil {
+		return "", err
+	}
+
 	return tokenString, nil
 }
 
-// generateRefreshToken generates a refresh token and stores it in the database
-func (s *userService) generateRefreshToken(ctx context.Context, user *domain.User) (string, error) {
-	// Generate a random token string
-	tokenString := uuid.New().String()
+// buildRefreshToken generates a random opaque token string and the unsaved refresh token
+// holding its digest for a user signed in from client. The token succeeds previous in its
+// family; a nil previous starts a new session and family named after the token itself.
+func (s *userService) buildRefreshToken(userID uuid.UUID, previous *domain.RefreshToken, client ClientInfo) (string, *domain.RefreshToken, error) {
+	tokenString, err := randomToken()
+	if err != nil {
+		return "", nil, err
+	}
 
+	now := time.Now()
 	refreshToken := &domain.RefreshToken{
-		ID:        uuid.New(),
-		UserID:    user.ID,
-		Token:     tokenString,
-		ExpiresAt: time.Now().Add(RefreshTokenExpiration),
-		CreatedAt: time.Now(),
-		Revoked:   false,
+		ID:               uuid.New(),
+		UserID:           userID,
+		TokenHash:        hashToken(tokenString),
+		CreatedAt:        now,
+		Revoked:          false,
+		UserAgent:        client.UserAgent,
+		IPAddress:        client.IPAddress,
+		LastUsedAt:       now,
+		SessionStartedAt: now,
+	}
+	refreshToken.FamilyID = refreshToken.ID
+	if previous != nil {
+		refreshToken.FamilyID = previous.FamilyID
+		refreshToken.SessionStartedAt = previous.SessionStartedAt
 	}
+	refreshToken.ExpiresAt = s.policy.refreshTokenExpiry(now, refreshToken.SessionStartedAt)
 
-	if err := s.refreshTokenRepo.Create(ctx, refreshToken); err != nil {
+	return tokenString, refreshToken, nil
+}
+
+// randomToken generates an opaque URL-safe token string
+func randomToken() (string, error) {
+	randomBytes := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(randomBytes); err != nil {
 		return "", err
 	}
+	return base64.RawURLEncoding.EncodeToString(randomBytes), nil
+}
 
-	return tokenString, nil
+// linkWithToken adds token to rawURL as the token query parameter, building the links
+// mailed to users
+func linkWithToken(rawURL, token string) (string, error) {
+	link, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid link URL %q: %w", rawURL, err)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String(), nil
+}
+
+// hashToken returns the hex SHA-256 digest under which a refresh or password reset token is
+// stored. Both carry enough entropy that an unsalted fast hash cannot be reversed.
+func hashToken(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(digest[:])
 }
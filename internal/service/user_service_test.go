@@ -2,9 +2,13 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"pizza-must/internal/auth"
 	"pizza-must/internal/domain"
 	"pizza-must/internal/repository"
 
@@ -36,7 +40,7 @@ func (m *mockUserRepository) Create(ctx context.Context, user *domain.User) erro
 
 func (m *mockUserRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
 	user, exists := m.users[email]
-	if !exists {
+	if !exists || user.DeletedAt != nil {
 		return nil, repository.ErrUserNotFound
 	}
 	return user, nil
@@ -44,13 +48,66 @@ func (m *mockUserRepository) FindByEmail(ctx context.Context, email string) (*do
 
 func (m *mockUserRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
 	for _, user := range m.users {
-		if user.ID == id {
+		if user.ID == id && user.DeletedAt == nil {
 			return user, nil
 		}
 	}
 	return nil, repository.ErrUserNotFound
 }
 
+func (m *mockUserRepository) Update(ctx context.Context, user *domain.User) error {
+	for email, existing := range m.users {
+		if existing.ID != user.ID {
+			continue
+		}
+		if other, taken := m.users[user.Email]; taken && other.ID != user.ID {
+			return repository.ErrUserAlreadyExists
+		}
+		delete(m.users, email)
+		m.users[user.Email] = user
+		return nil
+	}
+	return repository.ErrUserNotFound
+}
+
+func (m *mockUserRepository) AnonymizeTx(ctx context.Context, tx *sql.Tx, id uuid.UUID, deletedAt time.Time) error {
+	for email, user := range m.users {
+		if user.ID != id || user.DeletedAt != nil {
+			continue
+		}
+		delete(m.users, email)
+		anonymized := *user
+		anonymized.Email = "deleted-" + id.String() + "@deleted.invalid"
+		anonymized.PasswordHash, anonymized.FirstName, anonymized.LastName, anonymized.Phone = "", "", "", ""
+		anonymized.DeletedAt = &deletedAt
+		m.users[anonymized.Email] = &anonymized
+		return nil
+	}
+	return repository.ErrUserNotFound
+}
+
+func (m *mockUserRepository) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	for _, user := range m.users {
+		if user.ID == id {
+			user.PasswordHash = passwordHash
+			return nil
+		}
+	}
+	return repository.ErrUserNotFound
+}
+
+func (m *mockUserRepository) MarkEmailVerified(ctx context.Context, id uuid.UUID, verifiedAt time.Time) error {
+	for _, user := range m.users {
+		if user.ID == id {
+			if user.EmailVerifiedAt == nil {
+				user.EmailVerifiedAt = &verifiedAt
+			}
+			return nil
+		}
+	}
+	return repository.ErrUserNotFound
+}
+
 type mockRefreshTokenRepository struct {
 	tokens map[string]*domain.RefreshToken
 }
@@ -62,12 +119,12 @@ func newMockRefreshTokenRepository() *mockRefreshTokenRepository {
 }
 
 func (m *mockRefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
-	m.tokens[token.Token] = token
+	m.tokens[token.TokenHash] = token
 	return nil
 }
 
-func (m *mockRefreshTokenRepository) FindByToken(ctx context.Context, token string) (*domain.RefreshToken, error) {
-	refreshToken, exists := m.tokens[token]
+func (m *mockRefreshTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	refreshToken, exists := m.tokens[tokenHash]
 	if !exists {
 		return nil, repository.ErrRefreshTokenNotFound
 	}
@@ -77,8 +134,8 @@ func (m *mockRefreshTokenRepository) FindByToken(ctx context.Context, token stri
 	return refreshToken, nil
 }
 
-func (m *mockRefreshTokenRepository) Revoke(ctx context.Context, token string) error {
-	refreshToken, exists := m.tokens[token]
+func (m *mockRefreshTokenRepository) Revoke(ctx context.Context, tokenHash string) error {
+	refreshToken, exists := m.tokens[tokenHash]
 	if !exists {
 		return repository.ErrRefreshTokenNotFound
 	}
@@ -86,6 +143,83 @@ func (m *mockRefreshTokenRepository) Revoke(ctx context.Context, token string) e
 	return nil
 }
 
+func (m *mockRefreshTokenRepository) FindByTokenHashIncludingRevoked(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	refreshToken, exists := m.tokens[tokenHash]
+	if !exists {
+		return nil, repository.ErrRefreshTokenNotFound
+	}
+	return refreshToken, nil
+}
+
+func (m *mockRefreshTokenRepository) Rotate(ctx context.Context, current *domain.RefreshToken, replacement *domain.RefreshToken) error {
+	if current.Revoked {
+		return repository.ErrRefreshTokenRevoked
+	}
+	current.Revoked = true
+	current.ReplacedBy = &replacement.ID
+	m.tokens[replacement.TokenHash] = replacement
+	return nil
+}
+
+func (m *mockRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	for _, refreshToken := range m.tokens {
+		if refreshToken.FamilyID == familyID {
+			refreshToken.Revoked = true
+		}
+	}
+	return nil
+}
+
+func (m *mockRefreshTokenRepository) ListActiveSessions(ctx context.Context, userID uuid.UUID) ([]*domain.Session, error) {
+	sessions := []*domain.Session{}
+	for _, refreshToken := range m.tokens {
+		if refreshToken.UserID != userID || refreshToken.Revoked || time.Now().After(refreshToken.ExpiresAt) {
+			continue
+		}
+		sessions = append(sessions, &domain.Session{
+			ID:         refreshToken.FamilyID,
+			UserAgent:  refreshToken.UserAgent,
+			IPAddress:  refreshToken.IPAddress,
+			CreatedAt:  refreshToken.SessionStartedAt,
+			LastUsedAt: refreshToken.LastUsedAt,
+			ExpiresAt:  refreshToken.ExpiresAt,
+		})
+	}
+	return sessions, nil
+}
+
+func (m *mockRefreshTokenRepository) RevokeSession(ctx context.Context, userID, familyID uuid.UUID) error {
+	revoked := false
+	for _, refreshToken := range m.tokens {
+		if refreshToken.UserID == userID && refreshToken.FamilyID == familyID && !refreshToken.Revoked {
+			refreshToken.Revoked = true
+			revoked = true
+		}
+	}
+	if !revoked {
+		return repository.ErrSessionNotFound
+	}
+	return nil
+}
+
+func (m *mockRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	for _, refreshToken := range m.tokens {
+		if refreshToken.UserID == userID {
+			refreshToken.Revoked = true
+		}
+	}
+	return nil
+}
+
+// newTestTokenManager creates a token manager with a fast EdDSA key for signing test tokens
+func newTestTokenManager() auth.TokenManager {
+	keys, err := auth.NewKeyManager(auth.KeyManagerConfig{Algorithm: auth.AlgorithmEdDSA, OverlapWindow: time.Hour}, auth.NewMemoryKeyStore())
+	if err != nil {
+		panic(err)
+	}
+	return auth.NewTokenManager(keys, auth.TokenConfig{Issuer: "test", Audience: "test-api"})
+}
+
 // Feature: ordering-platform, Property 1: Registration creates hashed passwords
 // Validates: Requirements 1.1, 1.3
 func TestProperty_RegistrationCreatesHashedPasswords(t *testing.T) {
@@ -96,7 +230,7 @@ func TestProperty_RegistrationCreatesHashedPasswords(t *testing.T) {
 			// Setup
 			userRepo := newMockUserRepository()
 			refreshTokenRepo := newMockRefreshTokenRepository()
-			service := NewUserService(userRepo, refreshTokenRepo, "test-secret")
+			service := NewUserService(&mockTransactor{}, userRepo, newMockRoleRepository(), refreshTokenRepo, newTestTokenManager(), DefaultTokenPolicy(), newTestLoginThrottle())
 			ctx := context.Background()
 
 			// Execute registration
@@ -160,8 +294,9 @@ func TestProperty_JWTTokensContainRequiredClaims(t *testing.T) {
 		func(email string, password string, firstName string, lastName string, role string) bool {
 			// Setup
 			userRepo := newMockUserRepository()
+			roleRepo := newMockRoleRepository()
 			refreshTokenRepo := newMockRefreshTokenRepository()
-			service := NewUserService(userRepo, refreshTokenRepo, "test-secret-key")
+			service := NewUserService(&mockTransactor{}, userRepo, roleRepo, refreshTokenRepo, newTestTokenManager(), DefaultTokenPolicy(), newTestLoginThrottle())
 			ctx := context.Background()
 
 			// Register user
@@ -170,12 +305,13 @@ func TestProperty_JWTTokensContainRequiredClaims(t *testing.T) {
 				return true // Skip if registration fails
 			}
 
-			// Override role for testing
-			user.Role = role
-			userRepo.users[email] = user
+			// Grant a role for testing; customers hold none
+			if role != "" {
+				roleRepo.grant(user.ID, role)
+			}
 
 			// Login to get tokens
-			accessToken, _, _, err := service.Login(ctx, email, password)
+			accessToken, _, _, err := service.Login(ctx, email, password, ClientInfo{})
 			if err != nil {
 				t.Logf("FAIL: Login failed: %v", err)
 				return false
@@ -194,9 +330,9 @@ func TestProperty_JWTTokensContainRequiredClaims(t *testing.T) {
 				return false
 			}
 
-			// Verify role claim is present and matches
-			if claims.Role != role {
-				t.Logf("FAIL: Role claim mismatch. Expected %s, got %s", role, claims.Role)
+			// Verify role claims are present and match
+			if role == "" && len(claims.Roles) != 0 || role != "" && (len(claims.Roles) != 1 || !claims.HasRole(role)) {
+				t.Logf("FAIL: Role claim mismatch. Expected %q, got %v", role, claims.Roles)
 				return false
 			}
 
@@ -218,7 +354,7 @@ func TestProperty_JWTTokensContainRequiredClaims(t *testing.T) {
 		gen.RegexMatch(`[A-Za-z0-9!@#$%]{8,20}`),
 		gen.RegexMatch(`[A-Z][a-z]{2,15}`),
 		gen.RegexMatch(`[A-Z][a-z]{2,15}`),
-		gen.OneConstOf("user", "admin"),
+		gen.OneConstOf("", domain.RoleAdmin, domain.RoleKitchen),
 	))
 
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
@@ -234,7 +370,7 @@ func TestProperty_TokenRefreshRoundTrip(t *testing.T) {
 			// Setup
 			userRepo := newMockUserRepository()
 			refreshTokenRepo := newMockRefreshTokenRepository()
-			service := NewUserService(userRepo, refreshTokenRepo, "test-secret-key")
+			service := NewUserService(&mockTransactor{}, userRepo, newMockRoleRepository(), refreshTokenRepo, newTestTokenManager(), DefaultTokenPolicy(), newTestLoginThrottle())
 			ctx := context.Background()
 
 			// Register and login
@@ -243,14 +379,14 @@ func TestProperty_TokenRefreshRoundTrip(t *testing.T) {
 				return true // Skip if registration fails
 			}
 
-			_, refreshToken, user, err := service.Login(ctx, email, password)
+			_, refreshToken, user, err := service.Login(ctx, email, password, ClientInfo{})
 			if err != nil {
 				t.Logf("FAIL: Login failed: %v", err)
 				return false
 			}
 
 			// Use refresh token to get new access token
-			newAccessToken, err := service.RefreshToken(ctx, refreshToken)
+			newAccessToken, _, err := service.RefreshToken(ctx, refreshToken, ClientInfo{})
 			if err != nil {
 				t.Logf("FAIL: Token refresh failed: %v", err)
 				return false
@@ -269,7 +405,7 @@ func TestProperty_TokenRefreshRoundTrip(t *testing.T) {
 				return false
 			}
 
-			if claims.Role != user.Role {
+			if len(claims.Roles) != len(user.Roles) {
 				t.Logf("FAIL: Role mismatch in refreshed token")
 				return false
 			}
@@ -301,7 +437,7 @@ func TestProperty_LogoutInvalidatesRefreshToken(t *testing.T) {
 			// Setup
 			userRepo := newMockUserRepository()
 			refreshTokenRepo := newMockRefreshTokenRepository()
-			service := NewUserService(userRepo, refreshTokenRepo, "test-secret-key")
+			service := NewUserService(&mockTransactor{}, userRepo, newMockRoleRepository(), refreshTokenRepo, newTestTokenManager(), DefaultTokenPolicy(), newTestLoginThrottle())
 			ctx := context.Background()
 
 			// Register and login
@@ -310,14 +446,14 @@ func TestProperty_LogoutInvalidatesRefreshToken(t *testing.T) {
 				return true // Skip if registration fails
 			}
 
-			_, refreshToken, _, err := service.Login(ctx, email, password)
+			_, refreshToken, _, err := service.Login(ctx, email, password, ClientInfo{})
 			if err != nil {
 				t.Logf("FAIL: Login failed: %v", err)
 				return false
 			}
 
-			// Verify refresh token works before logout
-			_, err = service.RefreshToken(ctx, refreshToken)
+			// Verify refresh token works before logout; it is rotated into a new one
+			_, refreshToken, err = service.RefreshToken(ctx, refreshToken, ClientInfo{})
 			if err != nil {
 				t.Logf("FAIL: Refresh token should work before logout: %v", err)
 				return false
@@ -331,7 +467,7 @@ func TestProperty_LogoutInvalidatesRefreshToken(t *testing.T) {
 			}
 
 			// Verify refresh token is now invalid
-			_, err = service.RefreshToken(ctx, refreshToken)
+			_, _, err = service.RefreshToken(ctx, refreshToken, ClientInfo{})
 			if err == nil {
 				t.Logf("FAIL: Refresh token should be invalid after logout")
 				return false
@@ -344,7 +480,7 @@ func TestProperty_LogoutInvalidatesRefreshToken(t *testing.T) {
 			}
 
 			// Verify token is marked as revoked in repository
-			storedToken, err := refreshTokenRepo.FindByToken(ctx, refreshToken)
+			storedToken, err := refreshTokenRepo.FindByTokenHash(ctx, hashToken(refreshToken))
 			if err != repository.ErrRefreshTokenRevoked {
 				t.Logf("FAIL: Token should be revoked in repository, got error: %v", err)
 				return false
@@ -366,3 +502,367 @@ func TestProperty_LogoutInvalidatesRefreshToken(t *testing.T) {
 
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
 }
+
+// Feature: ordering-platform, Property 82: Refresh token rotation detects reuse
+// Validates: Requirements 2.5
+func TestProperty_RefreshTokenRotationDetectsReuse(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("each refresh rotates the token and reusing a rotated token revokes its family", prop.ForAll(
+		func(email string, password string, rotations int, reused int) bool {
+			// Setup
+			userRepo := newMockUserRepository()
+			refreshTokenRepo := newMockRefreshTokenRepository()
+			service := NewUserService(&mockTransactor{}, userRepo, newMockRoleRepository(), refreshTokenRepo, newTestTokenManager(), DefaultTokenPolicy(), newTestLoginThrottle())
+			ctx := context.Background()
+
+			if _, err := service.Register(ctx, email, password, "Test", "User"); err != nil {
+				return true // Skip if registration fails
+			}
+
+			_, refreshToken, _, err := service.Login(ctx, email, password, ClientInfo{})
+			if err != nil {
+				t.Logf("FAIL: Login failed: %v", err)
+				return false
+			}
+			familyID := refreshTokenRepo.tokens[hashToken(refreshToken)].FamilyID
+
+			// Rotate the token several times, keeping every token handed out
+			issued := []string{refreshToken}
+			for i := 0; i < rotations; i++ {
+				_, next, err := service.RefreshToken(ctx, issued[len(issued)-1], ClientInfo{})
+				if err != nil {
+					t.Logf("FAIL: Refresh %d failed: %v", i, err)
+					return false
+				}
+				if next == issued[len(issued)-1] {
+					t.Logf("FAIL: Refresh %d returned the presented token", i)
+					return false
+				}
+				if refreshTokenRepo.tokens[hashToken(next)].FamilyID != familyID {
+					t.Logf("FAIL: Rotated token left the family")
+					return false
+				}
+				issued = append(issued, next)
+			}
+
+			// Present a token that has already been rotated
+			_, _, err = service.RefreshToken(ctx, issued[reused%rotations], ClientInfo{})
+			var reuseErr *TokenReuseError
+			if !errors.As(err, &reuseErr) || reuseErr.FamilyID != familyID {
+				t.Logf("FAIL: Expected TokenReuseError for family %s, got: %v", familyID, err)
+				return false
+			}
+
+			// The latest token is revoked together with the rest of its family
+			if _, _, err := service.RefreshToken(ctx, issued[len(issued)-1], ClientInfo{}); err == nil {
+				t.Logf("FAIL: Latest token should be revoked after reuse")
+				return false
+			}
+			for _, token := range issued {
+				if !refreshTokenRepo.tokens[hashToken(token)].Revoked {
+					t.Logf("FAIL: Token in reused family is still valid")
+					return false
+				}
+			}
+
+			return true
+		},
+		gen.RegexMatch(`[a-z]{3,10}@[a-z]{3,8}\.(com|org|net)`),
+		gen.RegexMatch(`[A-Za-z0-9!@#$%]{8,20}`),
+		gen.IntRange(1, 5),
+		gen.IntRange(0, 10),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// Feature: ordering-platform, Property 83: Refresh tokens are stored only as digests
+// Validates: Requirements 2.5
+func TestProperty_RefreshTokensStoredAsDigests(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("issued refresh tokens are stored as their SHA-256 digest", prop.ForAll(
+		func(email string, password string) bool {
+			// Setup
+			userRepo := newMockUserRepository()
+			refreshTokenRepo := newMockRefreshTokenRepository()
+			service := NewUserService(&mockTransactor{}, userRepo, newMockRoleRepository(), refreshTokenRepo, newTestTokenManager(), DefaultTokenPolicy(), newTestLoginThrottle())
+			ctx := context.Background()
+
+			if _, err := service.Register(ctx, email, password, "Test", "User"); err != nil {
+				return true // Skip if registration fails
+			}
+
+			_, loginToken, _, err := service.Login(ctx, email, password, ClientInfo{})
+			if err != nil {
+				t.Logf("FAIL: Login failed: %v", err)
+				return false
+			}
+			_, rotatedToken, err := service.RefreshToken(ctx, loginToken, ClientInfo{})
+			if err != nil {
+				t.Logf("FAIL: Refresh failed: %v", err)
+				return false
+			}
+
+			for _, token := range []string{loginToken, rotatedToken} {
+				if len(token) < 43 {
+					t.Logf("FAIL: Refresh token %q carries less than 256 bits", token)
+					return false
+				}
+				if _, exists := refreshTokenRepo.tokens[token]; exists {
+					t.Logf("FAIL: Refresh token stored in plaintext")
+					return false
+				}
+				stored, exists := refreshTokenRepo.tokens[hashToken(token)]
+				if !exists || stored.TokenHash != hashToken(token) {
+					t.Logf("FAIL: Refresh token digest not stored")
+					return false
+				}
+			}
+
+			return true
+		},
+		gen.RegexMatch(`[a-z]{3,10}@[a-z]{3,8}\.(com|org|net)`),
+		gen.RegexMatch(`[A-Za-z0-9!@#$%]{8,20}`),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// Feature: ordering-platform, Property 84: Revoking a session signs out only that device
+// Validates: Requirements 3.1
+func TestProperty_RevokingSessionSignsOutOnlyThatDevice(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("each login is a session and revoking one leaves the others usable", prop.ForAll(
+		func(email string, password string, devices int, revoked int) bool {
+			// Setup
+			userRepo := newMockUserRepository()
+			refreshTokenRepo := newMockRefreshTokenRepository()
+			service := NewUserService(&mockTransactor{}, userRepo, newMockRoleRepository(), refreshTokenRepo, newTestTokenManager(), DefaultTokenPolicy(), newTestLoginThrottle())
+			ctx := context.Background()
+
+			user, err := service.Register(ctx, email, password, "Test", "User")
+			if err != nil {
+				return true // Skip if registration fails
+			}
+
+			// Sign in from several devices and refresh each session once
+			tokens := make([]string, devices)
+			for i := range tokens {
+				client := ClientInfo{UserAgent: fmt.Sprintf("device-%d", i), IPAddress: "10.0.0.1"}
+				_, loginToken, _, err := service.Login(ctx, email, password, client)
+				if err != nil {
+					t.Logf("FAIL: Login failed: %v", err)
+					return false
+				}
+				if _, tokens[i], err = service.RefreshToken(ctx, loginToken, client); err != nil {
+					t.Logf("FAIL: Refresh failed: %v", err)
+					return false
+				}
+			}
+
+			sessions, err := service.ListSessions(ctx, user.ID)
+			if err != nil || len(sessions) != devices {
+				t.Logf("FAIL: Expected %d sessions, got %d (%v)", devices, len(sessions), err)
+				return false
+			}
+
+			// Revoke the session of one device
+			target := refreshTokenRepo.tokens[hashToken(tokens[revoked%devices])].FamilyID
+			if err := service.RevokeSession(ctx, user.ID, target); err != nil {
+				t.Logf("FAIL: RevokeSession failed: %v", err)
+				return false
+			}
+			if err := service.RevokeSession(ctx, uuid.New(), target); err != repository.ErrSessionNotFound {
+				t.Logf("FAIL: Another user revoked the session, got: %v", err)
+				return false
+			}
+
+			for i, token := range tokens {
+				_, next, err := service.RefreshToken(ctx, token, ClientInfo{})
+				if i == revoked%devices {
+					if err != ErrInvalidToken {
+						t.Logf("FAIL: Revoked session should be invalid, got: %v", err)
+						return false
+					}
+					continue
+				}
+				if err != nil {
+					t.Logf("FAIL: Other session should still work: %v", err)
+					return false
+				}
+				tokens[i] = next
+			}
+
+			// Signing out everywhere leaves no session
+			if err := service.RevokeAllSessions(ctx, user.ID); err != nil {
+				t.Logf("FAIL: RevokeAllSessions failed: %v", err)
+				return false
+			}
+			sessions, err = service.ListSessions(ctx, user.ID)
+			if err != nil || len(sessions) != 0 {
+				t.Logf("FAIL: Expected no sessions after signing out everywhere, got %d (%v)", len(sessions), err)
+				return false
+			}
+
+			return true
+		},
+		gen.RegexMatch(`[a-z]{3,10}@[a-z]{3,8}\.(com|org|net)`),
+		gen.RegexMatch(`[A-Za-z0-9!@#$%]{8,20}`),
+		gen.IntRange(1, 4),
+		gen.IntRange(0, 10),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// registerTestUser registers and signs in a user, returning the user and its refresh token
+func registerTestUser(t *testing.T, users UserService, email string) (*domain.User, string) {
+	t.Helper()
+	ctx := context.Background()
+	user, err := users.Register(ctx, email, "password123", "Test", "User")
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	_, refreshToken, _, err := users.Login(ctx, email, "password123", ClientInfo{})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	return user, refreshToken
+}
+
+func TestUpdateProfile_ChangesOnlyGivenFields(t *testing.T) {
+	users := NewUserService(&mockTransactor{}, newMockUserRepository(), newMockRoleRepository(), newMockRefreshTokenRepository(), newTestTokenManager(), DefaultTokenPolicy(), newTestLoginThrottle())
+	ctx := context.Background()
+	user, _ := registerTestUser(t, users, "profile@example.com")
+
+	phone := "+4915112345678"
+	if _, err := users.UpdateProfile(ctx, user.ID, ProfileUpdate{Phone: &phone}); err != nil {
+		t.Fatalf("UpdateProfile failed: %v", err)
+	}
+	lastName := "Renamed"
+	updated, err := users.UpdateProfile(ctx, user.ID, ProfileUpdate{LastName: &lastName})
+	if err != nil {
+		t.Fatalf("UpdateProfile failed: %v", err)
+	}
+
+	if updated.FirstName != "Test" || updated.LastName != lastName || updated.Phone != phone {
+		t.Errorf("unexpected profile %q %q %q", updated.FirstName, updated.LastName, updated.Phone)
+	}
+	if _, err := users.UpdateProfile(ctx, uuid.New(), ProfileUpdate{LastName: &lastName}); err != repository.ErrUserNotFound {
+		t.Errorf("expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestChangePassword_RequiresCurrentPasswordAndSignsOut(t *testing.T) {
+	users := NewUserService(&mockTransactor{}, newMockUserRepository(), newMockRoleRepository(), newMockRefreshTokenRepository(), newTestTokenManager(), DefaultTokenPolicy(), newTestLoginThrottle())
+	ctx := context.Background()
+	user, refreshToken := registerTestUser(t, users, "change@example.com")
+
+	if err := users.ChangePassword(ctx, user.ID, "wrongpassword", "newpassword123"); err != ErrIncorrectPassword {
+		t.Fatalf("expected ErrIncorrectPassword, got %v", err)
+	}
+	if err := users.ChangePassword(ctx, user.ID, "password123", "newpassword123"); err != nil {
+		t.Fatalf("ChangePassword failed: %v", err)
+	}
+
+	if _, _, err := users.RefreshToken(ctx, refreshToken, ClientInfo{}); err != ErrInvalidToken {
+		t.Errorf("existing session should be signed out, got %v", err)
+	}
+	if _, _, _, err := users.Login(ctx, "change@example.com", "password123", ClientInfo{}); err != ErrInvalidCredentials {
+		t.Errorf("old password should no longer work, got %v", err)
+	}
+	if _, _, _, err := users.Login(ctx, "change@example.com", "newpassword123", ClientInfo{}); err != nil {
+		t.Errorf("new password should work: %v", err)
+	}
+}
+
+func TestChangeEmail_RequiresReverification(t *testing.T) {
+	users := NewUserService(&mockTransactor{}, newMockUserRepository(), newMockRoleRepository(), newMockRefreshTokenRepository(), newTestTokenManager(), DefaultTokenPolicy(), newTestLoginThrottle())
+	ctx := context.Background()
+	user, _ := registerTestUser(t, users, "old@example.com")
+	registerTestUser(t, users, "taken@example.com")
+
+	tests := []struct {
+		name     string
+		password string
+		email    string
+		want     error
+	}{
+		{"wrong password", "wrongpassword", "new@example.com", ErrIncorrectPassword},
+		{"address in use", "password123", "taken@example.com", repository.ErrUserAlreadyExists},
+		{"new address", "password123", "new@example.com", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := users.ChangeEmail(ctx, user.ID, tt.password, tt.email); err != tt.want {
+				t.Errorf("ChangeEmail error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	stored, err := users.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID failed: %v", err)
+	}
+	if stored.Email != "new@example.com" || stored.IsEmailVerified() {
+		t.Errorf("expected unverified new@example.com, got %s (verified %v)", stored.Email, stored.IsEmailVerified())
+	}
+	if _, _, _, err := users.Login(ctx, "new@example.com", "password123", ClientInfo{}); err != nil {
+		t.Errorf("login with the new address failed: %v", err)
+	}
+}
+
+func TestDeleteAccount_AnonymizesUser(t *testing.T) {
+	userRepo := newMockUserRepository()
+	users := NewUserService(&mockTransactor{}, userRepo, newMockRoleRepository(), newMockRefreshTokenRepository(), newTestTokenManager(), DefaultTokenPolicy(), newTestLoginThrottle())
+	ctx := context.Background()
+	user, refreshToken := registerTestUser(t, users, "leaving@example.com")
+
+	if err := users.DeleteAccount(ctx, user.ID, "wrongpassword"); err != ErrIncorrectPassword {
+		t.Fatalf("expected ErrIncorrectPassword, got %v", err)
+	}
+	if err := users.DeleteAccount(ctx, user.ID, "password123"); err != nil {
+		t.Fatalf("DeleteAccount failed: %v", err)
+	}
+
+	if _, err := users.GetUserByID(ctx, user.ID); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("deleted user should not be found, got %v", err)
+	}
+	if _, _, _, err := users.Login(ctx, "leaving@example.com", "password123", ClientInfo{}); err != ErrInvalidCredentials {
+		t.Errorf("deleted user should not be able to login, got %v", err)
+	}
+	if _, _, err := users.RefreshToken(ctx, refreshToken, ClientInfo{}); err == nil {
+		t.Error("deleted user's session should no longer refresh")
+	}
+
+	// The email address can be used for a new account
+	if _, err := users.Register(ctx, "leaving@example.com", "password123", "New", "User"); err != nil {
+		t.Errorf("registering the freed address failed: %v", err)
+	}
+}
+
+func TestDeleteAccount_KeepsLastAdmin(t *testing.T) {
+	roleRepo := newMockRoleRepository()
+	users := NewUserService(&mockTransactor{}, newMockUserRepository(), roleRepo, newMockRefreshTokenRepository(), newTestTokenManager(), DefaultTokenPolicy(), newTestLoginThrottle())
+	ctx := context.Background()
+	first, _ := registerTestUser(t, users, "first-admin@example.com")
+	second, _ := registerTestUser(t, users, "second-admin@example.com")
+	roleRepo.grant(first.ID, domain.RoleAdmin)
+
+	if err := users.DeleteAccount(ctx, first.ID, "password123"); err != ErrLastAdmin {
+		t.Fatalf("expected ErrLastAdmin, got %v", err)
+	}
+	if _, err := users.GetUserByID(ctx, first.ID); err != nil {
+		t.Errorf("the last admin's account should be kept, got %v", err)
+	}
+
+	// Once another admin exists the account can go
+	roleRepo.grant(second.ID, domain.RoleAdmin)
+	if err := users.DeleteAccount(ctx, first.ID, "password123"); err != nil {
+		t.Errorf("expected deleting to succeed with another admin, got %v", err)
+	}
+}
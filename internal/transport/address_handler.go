@@ -0,0 +1,190 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+
+	"pizza-must/internal/middleware"
+	"pizza-must/internal/repository"
+	"pizza-must/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// AddressRequest represents the create and full-update address payload
+type AddressRequest struct {
+	Label        string  `json:"label" validate:"max=50"`
+	AddressLine1 string  `json:"address_line1" validate:"required,max=255"`
+	AddressLine2 string  `json:"address_line2" validate:"max=255"`
+	City         string  `json:"city" validate:"required,max=100"`
+	PostalCode   string  `json:"postal_code" validate:"required,max=20"`
+	Country      string  `json:"country" validate:"required,len=2,alpha"`
+	Latitude     float64 `json:"latitude" validate:"gte=-90,lte=90"`
+	Longitude    float64 `json:"longitude" validate:"gte=-180,lte=180"`
+	Phone        string  `json:"phone" validate:"max=32"`
+	Instructions string  `json:"instructions" validate:"max=255"`
+}
+
+// AddressHandler handles HTTP requests for the current user's address book
+type AddressHandler struct {
+	addressService service.AddressService
+	logger         *zap.Logger
+}
+
+// NewAddressHandler creates a new AddressHandler
+func NewAddressHandler(addressService service.AddressService, logger *zap.Logger) *AddressHandler {
+	return &AddressHandler{
+		addressService: addressService,
+		logger:         logger,
+	}
+}
+
+// RegisterRoutes registers the address book routes; every route requires authentication
+func (h *AddressHandler) RegisterRoutes(r chi.Router, authMiddleware func(http.Handler) http.Handler) {
+	r.Route("/api/users/addresses", func(r chi.Router) {
+		r.Use(authMiddleware)
+
+		r.Get("/", h.ListAddresses)
+		r.Post("/", h.CreateAddress)
+		r.Get("/{id}", h.GetAddress)
+		r.Put("/{id}", h.UpdateAddress)
+		r.Delete("/{id}", h.DeleteAddress)
+	})
+}
+
+// ListAddresses handles listing the current user's addresses
+func (h *AddressHandler) ListAddresses(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	addresses, err := h.addressService.ListAddresses(r.Context(), userID)
+	if err != nil {
+		h.respondWithAddressError(w, err, "failed to list addresses")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, addresses)
+}
+
+// GetAddress handles retrieving one of the current user's addresses
+func (h *AddressHandler) GetAddress(w http.ResponseWriter, r *http.Request) {
+	userID, addressID, ok := h.addressParams(w, r)
+	if !ok {
+		return
+	}
+
+	address, err := h.addressService.GetAddress(r.Context(), userID, addressID)
+	if err != nil {
+		h.respondWithAddressError(w, err, "failed to get address")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, address)
+}
+
+// CreateAddress handles adding an address to the current user's address book
+func (h *AddressHandler) CreateAddress(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	var req AddressRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	address, err := h.addressService.CreateAddress(r.Context(), userID, req.toInput())
+	if err != nil {
+		h.respondWithAddressError(w, err, "failed to create address")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusCreated, address)
+}
+
+// UpdateAddress handles replacing one of the current user's addresses
+func (h *AddressHandler) UpdateAddress(w http.ResponseWriter, r *http.Request) {
+	userID, addressID, ok := h.addressParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req AddressRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	address, err := h.addressService.UpdateAddress(r.Context(), userID, addressID, req.toInput())
+	if err != nil {
+		h.respondWithAddressError(w, err, "failed to update address")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, address)
+}
+
+// DeleteAddress handles removing one of the current user's addresses
+func (h *AddressHandler) DeleteAddress(w http.ResponseWriter, r *http.Request) {
+	userID, addressID, ok := h.addressParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.addressService.DeleteAddress(r.Context(), userID, addressID); err != nil {
+		h.respondWithAddressError(w, err, "failed to delete address")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// addressParams returns the current user's ID and the address ID path parameter. It
+// writes the error response and returns false when either is missing or invalid.
+func (h *AddressHandler) addressParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	addressID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid address ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, addressID, true
+}
+
+// respondWithAddressError maps address service errors to HTTP responses
+func (h *AddressHandler) respondWithAddressError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case err == repository.ErrAddressNotFound:
+		middleware.RespondWithError(w, http.StatusNotFound, "address not found")
+	case errors.Is(err, service.ErrInvalidAddress):
+		middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.Error("Address operation failed", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, fallback)
+	}
+}
+
+// toInput converts the request payload into a service AddressInput
+func (req AddressRequest) toInput() service.AddressInput {
+	return service.AddressInput{
+		Label:        req.Label,
+		AddressLine1: req.AddressLine1,
+		AddressLine2: req.AddressLine2,
+		City:         req.City,
+		PostalCode:   req.PostalCode,
+		Country:      req.Country,
+		Latitude:     req.Latitude,
+		Longitude:    req.Longitude,
+		Phone:        req.Phone,
+		Instructions: req.Instructions,
+	}
+}
@@ -0,0 +1,133 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pizza-must/internal/auth"
+	"pizza-must/internal/domain"
+	"pizza-must/internal/middleware"
+	"pizza-must/internal/repository"
+	"pizza-must/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type mockAddressRepository struct {
+	addresses map[uuid.UUID]*domain.Address
+}
+
+func newMockAddressRepository() *mockAddressRepository {
+	return &mockAddressRepository{addresses: make(map[uuid.UUID]*domain.Address)}
+}
+
+func (m *mockAddressRepository) Create(ctx context.Context, address *domain.Address) error {
+	m.addresses[address.ID] = address
+	return nil
+}
+
+func (m *mockAddressRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Address, error) {
+	addresses := []*domain.Address{}
+	for _, address := range m.addresses {
+		if address.UserID == userID {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses, nil
+}
+
+func (m *mockAddressRepository) FindByID(ctx context.Context, userID, id uuid.UUID) (*domain.Address, error) {
+	address, exists := m.addresses[id]
+	if !exists || address.UserID != userID {
+		return nil, repository.ErrAddressNotFound
+	}
+	copied := *address
+	return &copied, nil
+}
+
+func (m *mockAddressRepository) Update(ctx context.Context, address *domain.Address) error {
+	existing, exists := m.addresses[address.ID]
+	if !exists || existing.UserID != address.UserID {
+		return repository.ErrAddressNotFound
+	}
+	m.addresses[address.ID] = address
+	return nil
+}
+
+func (m *mockAddressRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	existing, exists := m.addresses[id]
+	if !exists || existing.UserID != userID {
+		return repository.ErrAddressNotFound
+	}
+	delete(m.addresses, id)
+	return nil
+}
+
+func TestAddressHandler_StatusCodes(t *testing.T) {
+	addressRepo := newMockAddressRepository()
+	tokens := newTestTokenManager(t)
+	router := chi.NewRouter()
+	NewAddressHandler(service.NewAddressService(addressRepo), zap.NewNop()).
+		RegisterRoutes(router, middleware.AuthMiddleware(tokens, zap.NewNop()))
+
+	userID := uuid.New()
+	home := &domain.Address{ID: uuid.New(), UserID: userID, AddressLine1: "Via Roma 1", City: "Roma", PostalCode: "00184", Country: "IT"}
+	neighbours := &domain.Address{ID: uuid.New(), UserID: uuid.New(), AddressLine1: "Via Roma 3", City: "Roma", PostalCode: "00184", Country: "IT"}
+	_ = addressRepo.Create(context.Background(), home)
+	_ = addressRepo.Create(context.Background(), neighbours)
+
+	token, err := tokens.Issue(userID, auth.Access{Permissions: []string{}}, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	address := `{"label":"Office","address_line1":"Via Nazionale 10","city":"Roma","postal_code":"00184","country":"it","latitude":41.9,"longitude":12.49}`
+	homePath := "/api/users/addresses/" + home.ID.String()
+	neighboursPath := "/api/users/addresses/" + neighbours.ID.String()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		body   string
+		want   int
+	}{
+		{"list without token", http.MethodGet, "/api/users/addresses", "", "", http.StatusUnauthorized},
+		{"list own addresses", http.MethodGet, "/api/users/addresses", token, "", http.StatusOK},
+		{"create address", http.MethodPost, "/api/users/addresses", token, address, http.StatusCreated},
+		{"create without city", http.MethodPost, "/api/users/addresses", token, strings.Replace(address, `"city":"Roma",`, "", 1), http.StatusBadRequest},
+		{"create with long country", http.MethodPost, "/api/users/addresses", token, strings.Replace(address, `"it"`, `"ita"`, 1), http.StatusBadRequest},
+		{"get own address", http.MethodGet, homePath, token, "", http.StatusOK},
+		{"get another user's address", http.MethodGet, neighboursPath, token, "", http.StatusNotFound},
+		{"get invalid address ID", http.MethodGet, "/api/users/addresses/not-a-uuid", token, "", http.StatusBadRequest},
+		{"update own address", http.MethodPut, homePath, token, address, http.StatusOK},
+		{"update another user's address", http.MethodPut, neighboursPath, token, address, http.StatusNotFound},
+		{"delete another user's address", http.MethodDelete, neighboursPath, token, "", http.StatusNotFound},
+		{"delete own address", http.MethodDelete, homePath, token, "", http.StatusNoContent},
+		{"get deleted address", http.MethodGet, homePath, token, "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
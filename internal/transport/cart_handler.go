@@ -0,0 +1,263 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+
+	"pizza-must/internal/middleware"
+	"pizza-must/internal/repository"
+	"pizza-must/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// AddCartItemRequest represents the payload for adding a product with its chosen options to the cart
+type AddCartItemRequest struct {
+	ProductID  string                   `json:"product_id" validate:"required,uuid"`
+	Selections []OptionSelectionRequest `json:"selections" validate:"omitempty,dive"`
+	Quantity   int                      `json:"quantity" validate:"required,gte=1,lte=99"`
+}
+
+// UpdateCartItemRequest represents the payload for changing a cart line quantity
+type UpdateCartItemRequest struct {
+	Quantity int `json:"quantity" validate:"required,gte=1,lte=99"`
+}
+
+// SelectCartStoreRequest represents the payload for choosing the store the cart is filled at
+type SelectCartStoreRequest struct {
+	StoreID string `json:"store_id" validate:"required,uuid"`
+}
+
+// ApplyCouponRequest represents the payload for applying a coupon code to the cart
+type ApplyCouponRequest struct {
+	Code string `json:"code" validate:"required,max=50"`
+}
+
+// CartHandler handles HTTP requests for the shopping cart
+type CartHandler struct {
+	cartService service.CartService
+	logger      *zap.Logger
+}
+
+// NewCartHandler creates a new CartHandler
+func NewCartHandler(cartService service.CartService, logger *zap.Logger) *CartHandler {
+	return &CartHandler{
+		cartService: cartService,
+		logger:      logger,
+	}
+}
+
+// RegisterRoutes registers all cart routes; every cart route requires authentication
+func (h *CartHandler) RegisterRoutes(r chi.Router, authMiddleware func(http.Handler) http.Handler) {
+	r.Route("/api/cart", func(r chi.Router) {
+		r.Use(authMiddleware)
+
+		r.Get("/", h.GetCart)
+		r.Delete("/", h.ClearCart)
+		r.Put("/store", h.SelectStore)
+		r.Post("/items", h.AddItem)
+		r.Patch("/items/{itemID}", h.UpdateItem)
+		r.Delete("/items/{itemID}", h.RemoveItem)
+		r.Post("/coupon", h.ApplyCoupon)
+		r.Delete("/coupon", h.RemoveCoupon)
+	})
+}
+
+// GetCart handles retrieving the current user's cart
+func (h *CartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	cart, err := h.cartService.GetCart(r.Context(), userID)
+	if err != nil {
+		h.respondWithCartError(w, err, "failed to get cart")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, cart)
+}
+
+// SelectStore handles choosing the store the cart is filled at
+func (h *CartHandler) SelectStore(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	var req SelectCartStoreRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	cart, err := h.cartService.SelectStore(r.Context(), userID, uuid.MustParse(req.StoreID))
+	if err != nil {
+		h.respondWithCartError(w, err, "failed to select store")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, cart)
+}
+
+// AddItem handles adding a product to the cart
+func (h *CartHandler) AddItem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	var req AddCartItemRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	cart, err := h.cartService.AddItem(r.Context(), userID, uuid.MustParse(req.ProductID), toOptionSelections(req.Selections), req.Quantity)
+	if err != nil {
+		h.respondWithCartError(w, err, "failed to add item to cart")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, cart)
+}
+
+// UpdateItem handles changing the quantity of a cart item
+func (h *CartHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	itemID, err := uuid.Parse(chi.URLParam(r, "itemID"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid cart item ID")
+		return
+	}
+
+	var req UpdateCartItemRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	cart, err := h.cartService.UpdateItem(r.Context(), userID, itemID, req.Quantity)
+	if err != nil {
+		h.respondWithCartError(w, err, "failed to update cart item")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, cart)
+}
+
+// RemoveItem handles removing an item from the cart
+func (h *CartHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	itemID, err := uuid.Parse(chi.URLParam(r, "itemID"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid cart item ID")
+		return
+	}
+
+	cart, err := h.cartService.RemoveItem(r.Context(), userID, itemID)
+	if err != nil {
+		h.respondWithCartError(w, err, "failed to remove cart item")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, cart)
+}
+
+// ClearCart handles removing every item from the cart
+func (h *CartHandler) ClearCart(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	if err := h.cartService.Clear(r.Context(), userID); err != nil {
+		h.respondWithCartError(w, err, "failed to clear cart")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ApplyCoupon handles applying a coupon code to the cart
+func (h *CartHandler) ApplyCoupon(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	var req ApplyCouponRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	cart, err := h.cartService.ApplyCoupon(r.Context(), userID, req.Code)
+	if err != nil {
+		h.respondWithCartError(w, err, "failed to apply coupon")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, cart)
+}
+
+// RemoveCoupon handles removing the applied coupon from the cart
+func (h *CartHandler) RemoveCoupon(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	cart, err := h.cartService.RemoveCoupon(r.Context(), userID)
+	if err != nil {
+		h.respondWithCartError(w, err, "failed to remove coupon")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, cart)
+}
+
+// respondWithCartError maps cart service errors to HTTP responses
+func (h *CartHandler) respondWithCartError(w http.ResponseWriter, err error, fallback string) {
+	var stockErr *service.InsufficientStockError
+	var couponErr *service.CouponError
+	switch {
+	case errors.As(err, &stockErr):
+		middleware.RespondWithErrorDetails(w, http.StatusConflict, "insufficient stock", map[string]interface{}{
+			"shortages": stockErr.Shortages,
+		})
+	case errors.As(err, &couponErr):
+		middleware.RespondWithErrorDetails(w, http.StatusUnprocessableEntity, "coupon cannot be applied", map[string]interface{}{
+			"code":   couponErr.Code,
+			"reason": couponErr.Reason,
+		})
+	case err == service.ErrEmptyCart:
+		middleware.RespondWithError(w, http.StatusUnprocessableEntity, "cart is empty")
+	case err == repository.ErrPromotionNotFound:
+		middleware.RespondWithError(w, http.StatusNotFound, "coupon not found")
+	case err == repository.ErrCartCouponNotFound:
+		middleware.RespondWithError(w, http.StatusNotFound, err.Error())
+	case err == service.ErrInvalidQuantity:
+		middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrInvalidOptionSelection):
+		middleware.RespondWithError(w, http.StatusUnprocessableEntity, err.Error())
+	case err == repository.ErrProductNotFound:
+		middleware.RespondWithError(w, http.StatusNotFound, "product not found")
+	case err == repository.ErrStoreNotFound:
+		middleware.RespondWithError(w, http.StatusNotFound, "store not found")
+	case err == service.ErrStoreNotSelected, err == service.ErrStoreInactive, err == repository.ErrProductUnavailable:
+		middleware.RespondWithError(w, http.StatusConflict, err.Error())
+	case err == repository.ErrCartItemNotFound:
+		middleware.RespondWithError(w, http.StatusNotFound, "cart item not found")
+	default:
+		h.logger.Error("Cart operation failed", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,117 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"pizza-must/internal/middleware"
+	"pizza-must/internal/repository"
+	"pizza-must/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// EmailVerificationHandler handles HTTP requests for verifying users' email addresses
+type EmailVerificationHandler struct {
+	emailVerificationService service.EmailVerificationService
+	logger                   *zap.Logger
+}
+
+// NewEmailVerificationHandler creates a new EmailVerificationHandler
+func NewEmailVerificationHandler(emailVerificationService service.EmailVerificationService, logger *zap.Logger) *EmailVerificationHandler {
+	return &EmailVerificationHandler{
+		emailVerificationService: emailVerificationService,
+		logger:                   logger,
+	}
+}
+
+// RegisterRoutes registers the email verification routes
+func (h *EmailVerificationHandler) RegisterRoutes(r chi.Router, authMiddleware func(http.Handler) http.Handler) {
+	r.Route("/api/users/verify", func(r chi.Router) {
+		// Public route opened from the verification email
+		r.Get("/", h.VerifyEmail)
+
+		// Protected routes
+		r.Group(func(r chi.Router) {
+			r.Use(authMiddleware)
+			r.Post("/resend", h.ResendVerification)
+		})
+	})
+}
+
+// VerifyEmail handles redeeming the token from a verification email
+func (h *EmailVerificationHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		middleware.RespondWithError(w, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	if err := h.emailVerificationService.VerifyEmail(r.Context(), token); err != nil {
+		if err == service.ErrInvalidVerificationToken {
+			middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.Error("Email verification failed", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, "failed to verify email")
+		return
+	}
+
+	h.logger.Info("Email verified")
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "email address verified"})
+}
+
+// ResendVerification handles sending the current user another verification email
+func (h *EmailVerificationHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	if err := h.emailVerificationService.ResendVerification(r.Context(), userID); err != nil {
+		var throttled *service.VerificationThrottledError
+		switch {
+		case errors.As(err, &throttled):
+			w.Header().Set("Retry-After", strconv.Itoa(int(throttled.RetryAfter.Seconds())+1))
+			middleware.RespondWithError(w, http.StatusTooManyRequests, "verification email was sent recently, try again later")
+		case err == service.ErrEmailAlreadyVerified:
+			middleware.RespondWithError(w, http.StatusConflict, err.Error())
+		case err == repository.ErrUserNotFound:
+			middleware.RespondWithError(w, http.StatusNotFound, "user not found")
+		default:
+			h.logger.Error("Failed to resend verification email", zap.Error(err))
+			middleware.RespondWithError(w, http.StatusInternalServerError, "failed to send verification email")
+		}
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusAccepted, map[string]string{"message": "verification email sent"})
+}
+
+// RequireCheckoutAllowed returns middleware that rejects users who may not place orders
+// until they verify their email address
+func (h *EmailVerificationHandler) RequireCheckoutAllowed(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := currentUserID(w, r, h.logger)
+		if !ok {
+			return
+		}
+
+		if err := h.emailVerificationService.CheckCanCheckout(r.Context(), userID); err != nil {
+			switch err {
+			case service.ErrEmailNotVerified:
+				middleware.RespondWithError(w, http.StatusForbidden, "email address must be verified before placing an order")
+			case repository.ErrUserNotFound:
+				middleware.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+			default:
+				h.logger.Error("Failed to check email verification", zap.Error(err))
+				middleware.RespondWithError(w, http.StatusInternalServerError, "failed to checkout")
+			}
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,69 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pizza-must/internal/auth"
+	"pizza-must/internal/middleware"
+	"pizza-must/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// withTestPrincipal authenticates every request as the given user
+func withTestPrincipal(userID uuid.UUID) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := middleware.WithPrincipal(r.Context(), auth.Principal{UserID: userID})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func TestRequireCheckoutAllowed_BlocksUnverifiedUsers(t *testing.T) {
+	tests := []struct {
+		name        string
+		checkoutErr error
+		want        int
+	}{
+		{"verified", nil, http.StatusTeapot},
+		{"unverified", service.ErrEmailNotVerified, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewEmailVerificationHandler(&stubEmailVerificationService{checkoutErr: tt.checkoutErr}, zap.NewNop())
+			router := chi.NewRouter()
+			router.With(withTestPrincipal(uuid.New()), handler.RequireCheckoutAllowed).Post("/checkout", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			})
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/checkout", nil))
+			if rec.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestResendVerification_ReportsRetryAfter(t *testing.T) {
+	verification := &stubEmailVerificationService{resendErr: &service.VerificationThrottledError{RetryAfter: 30 * time.Second}}
+	router := chi.NewRouter()
+	NewEmailVerificationHandler(verification, zap.NewNop()).RegisterRoutes(router, withTestPrincipal(uuid.New()))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/users/verify/resend", nil))
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Retry-After"); got != "31" {
+		t.Errorf("Retry-After = %q, want 31", got)
+	}
+}
@@ -0,0 +1,40 @@
+package transport
+
+import (
+	"net/http"
+
+	"pizza-must/internal/auth"
+	"pizza-must/internal/middleware"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// jwksMaxAge is how long, in seconds, clients may cache the key set.
+// It must stay well below the signing key overlap window.
+const jwksMaxAge = "300"
+
+// JWKSHandler publishes the public keys that verify our access tokens
+type JWKSHandler struct {
+	keys   auth.KeyManager
+	logger *zap.Logger
+}
+
+// NewJWKSHandler creates a new JWKSHandler
+func NewJWKSHandler(keys auth.KeyManager, logger *zap.Logger) *JWKSHandler {
+	return &JWKSHandler{
+		keys:   keys,
+		logger: logger,
+	}
+}
+
+// RegisterRoutes registers the public key set route
+func (h *JWKSHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/.well-known/jwks.json", h.GetJWKS)
+}
+
+// GetJWKS handles serving the current key set
+func (h *JWKSHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age="+jwksMaxAge)
+	middleware.RespondWithJSON(w, http.StatusOK, h.keys.JWKS())
+}
@@ -0,0 +1,192 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/middleware"
+	"pizza-must/internal/repository"
+	"pizza-must/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// kitchenKeepAlive is how often an idle kitchen stream sends a comment, so proxies do not
+// close it
+const kitchenKeepAlive = 15 * time.Second
+
+// KitchenItemRequest represents the payload for moving an order line through the kitchen
+type KitchenItemRequest struct {
+	Status string `json:"status" validate:"required,oneof=preparing ready"`
+}
+
+// KitchenHandler handles HTTP requests for kitchen displays
+type KitchenHandler struct {
+	kitchenService service.KitchenService
+	logger         *zap.Logger
+}
+
+// NewKitchenHandler creates a new KitchenHandler
+func NewKitchenHandler(kitchenService service.KitchenService, logger *zap.Logger) *KitchenHandler {
+	return &KitchenHandler{
+		kitchenService: kitchenService,
+		logger:         logger,
+	}
+}
+
+// RegisterRoutes registers the kitchen routes, restricted to staff who may operate the
+// kitchen at one store or more; the service leaves out orders of other stores
+func (h *KitchenHandler) RegisterRoutes(r chi.Router, authMiddleware func(http.Handler) http.Handler) {
+	r.Route("/api/kitchen", func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.Use(middleware.RequireStorePermission(domain.PermissionKitchenOperate, h.logger))
+
+		r.Get("/queue", h.GetQueue)
+		r.Get("/stream", h.Stream)
+		r.Put("/orders/{id}/items/{itemID}", h.SetItemStatus)
+	})
+}
+
+// GetQueue handles listing the confirmed and preparing orders of the caller's stores
+func (h *KitchenHandler) GetQueue(w http.ResponseWriter, r *http.Request) {
+	principal, ok := currentPrincipal(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	queues, err := h.kitchenService.Queue(r.Context(), principal)
+	if err != nil {
+		h.logger.Error("Failed to list kitchen queue", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, "failed to list kitchen queue")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, queues)
+}
+
+// Stream handles pushing new and changed orders of the caller's stores as Server-Sent
+// Events. Each event is named after the event type and carries the order as JSON. The
+// stream ends when the client falls too far behind, and when the access token that opened
+// it expires so revoked staff stop receiving orders; clients reconnect with a fresh token
+// and reload the queue.
+func (h *KitchenHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	principal, ok := currentPrincipal(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	events, cancel := h.kitchenService.Subscribe(principal)
+	defer cancel()
+
+	// The stream outlives the server's write timeout. Writers without deadlines have
+	// none to clear.
+	controller := http.NewResponseController(w)
+	_ = controller.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Disables response buffering in nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := controller.Flush(); err != nil {
+		h.logger.Error("Kitchen stream cannot be flushed", zap.Error(err))
+		return
+	}
+
+	keepAlive := time.NewTicker(kitchenKeepAlive)
+	defer keepAlive.Stop()
+
+	// The permissions the stream is filtered by are only as current as the token
+	var expired <-chan time.Time
+	if !principal.ExpiresAt.IsZero() {
+		expiry := time.NewTimer(time.Until(principal.ExpiresAt))
+		defer expiry.Stop()
+		expired = expiry.C
+	}
+
+	for {
+		var err error
+		select {
+		case <-r.Context().Done():
+			return
+		case <-expired:
+			return
+		case <-keepAlive.C:
+			_, err = fmt.Fprint(w, ": keep-alive\n\n")
+		case event, open := <-events:
+			if !open {
+				return
+			}
+			var data []byte
+			data, err = json.Marshal(event.Order)
+			if err != nil {
+				h.logger.Error("Failed to encode order event", zap.Error(err))
+				return
+			}
+			_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+
+		if err == nil {
+			err = controller.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// SetItemStatus handles marking an order line as preparing or ready
+func (h *KitchenHandler) SetItemStatus(w http.ResponseWriter, r *http.Request) {
+	principal, ok := currentPrincipal(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	orderID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid order ID")
+		return
+	}
+	itemID, err := uuid.Parse(chi.URLParam(r, "itemID"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid order item ID")
+		return
+	}
+
+	var req KitchenItemRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	order, err := h.kitchenService.SetItemStatus(r.Context(), orderID, itemID, principal.UserID, principal, req.Status)
+	if err != nil {
+		switch {
+		case err == repository.ErrOrderNotFound:
+			middleware.RespondWithError(w, http.StatusNotFound, "order not found")
+		case err == repository.ErrOrderItemNotFound:
+			middleware.RespondWithError(w, http.StatusNotFound, "order item not found")
+		case err == service.ErrInvalidKitchenStatus:
+			middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case err == service.ErrOrderNotInKitchen, errors.Is(err, service.ErrIllegalKitchenTransition):
+			middleware.RespondWithError(w, http.StatusConflict, err.Error())
+		default:
+			h.logger.Error("Kitchen status change failed", zap.Error(err))
+			middleware.RespondWithError(w, http.StatusInternalServerError, "failed to update order item")
+		}
+		return
+	}
+
+	h.logger.Info("Order item kitchen status changed",
+		zap.String("order_id", order.ID.String()),
+		zap.String("item_id", itemID.String()),
+		zap.String("kitchen_status", req.Status),
+		zap.String("actor_id", principal.UserID.String()),
+	)
+	middleware.RespondWithJSON(w, http.StatusOK, order)
+}
@@ -0,0 +1,200 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pizza-must/internal/auth"
+	"pizza-must/internal/domain"
+	"pizza-must/internal/middleware"
+	"pizza-must/internal/repository"
+	"pizza-must/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// mockKitchenOrderRepository holds orders in memory for the kitchen routes; other order
+// methods are not used by them
+type mockKitchenOrderRepository struct {
+	repository.OrderRepository
+	orders map[uuid.UUID]*domain.Order
+}
+
+func (m *mockKitchenOrderRepository) ListKitchenQueue(ctx context.Context) ([]*domain.Order, error) {
+	queue := []*domain.Order{}
+	for _, order := range m.orders {
+		if domain.IsInKitchenQueue(order.Status) {
+			queue = append(queue, order)
+		}
+	}
+	return queue, nil
+}
+
+func (m *mockKitchenOrderRepository) FindByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*domain.Order, error) {
+	order, exists := m.orders[id]
+	if !exists {
+		return nil, repository.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func (m *mockKitchenOrderRepository) UpdateItemKitchenStatusTx(ctx context.Context, tx *sql.Tx, item *domain.OrderItem) error {
+	return nil
+}
+
+func (m *mockKitchenOrderRepository) UpdateStatusTx(ctx context.Context, tx *sql.Tx, order *domain.Order) error {
+	return nil
+}
+
+func (m *mockKitchenOrderRepository) AddStatusChangeTx(ctx context.Context, tx *sql.Tx, change *domain.OrderStatusChange) error {
+	return nil
+}
+
+// newKitchenTestRouter serves the kitchen routes over orders, one of them confirmed at
+// storeID with a single pizza
+func newKitchenTestRouter(t *testing.T, storeID uuid.UUID) (http.Handler, *domain.Order, func(...string) string, auth.TokenManager) {
+	t.Helper()
+	order := &domain.Order{ID: uuid.New(), StoreID: storeID, Status: domain.OrderStatusConfirmed, CreatedAt: time.Now()}
+	order.Items = []*domain.OrderItem{{ID: uuid.New(), OrderID: order.ID, Type: domain.OrderItemProduct, KitchenStatus: domain.KitchenItemQueued}}
+	orderRepo := &mockKitchenOrderRepository{orders: map[uuid.UUID]*domain.Order{order.ID: order}}
+
+	tokens := newTestTokenManager(t)
+	router := chi.NewRouter()
+	kitchenService := service.NewKitchenService(&mockTransactor{}, orderRepo, service.NewOrderEventBroker())
+	NewKitchenHandler(kitchenService, zap.NewNop()).RegisterRoutes(router, middleware.AuthMiddleware(tokens, zap.NewNop()))
+
+	storeToken := func(permissions ...string) string {
+		return newTestStoreAccessToken(t, tokens, storeID, permissions...)
+	}
+	return router, order, storeToken, tokens
+}
+
+func TestKitchenHandler_StatusCodes(t *testing.T) {
+	storeID := uuid.New()
+	router, order, storeToken, _ := newKitchenTestRouter(t, storeID)
+
+	cookToken := storeToken(domain.PermissionKitchenOperate)
+	driverToken := storeToken(domain.PermissionOrdersRead, domain.PermissionOrdersTransition)
+	itemPath := "/api/kitchen/orders/" + order.ID.String() + "/items/" + order.Items[0].ID.String()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		body   string
+		want   int
+	}{
+		{"queue without token", http.MethodGet, "/api/kitchen/queue", "", "", http.StatusUnauthorized},
+		{"queue without kitchen permission", http.MethodGet, "/api/kitchen/queue", driverToken, "", http.StatusForbidden},
+		{"stream without kitchen permission", http.MethodGet, "/api/kitchen/stream", driverToken, "", http.StatusForbidden},
+		{"queue", http.MethodGet, "/api/kitchen/queue", cookToken, "", http.StatusOK},
+		{"unknown kitchen status", http.MethodPut, itemPath, cookToken, `{"status":"burnt"}`, http.StatusBadRequest},
+		{"invalid item ID", http.MethodPut, "/api/kitchen/orders/" + order.ID.String() + "/items/not-a-uuid", cookToken, `{"status":"ready"}`, http.StatusBadRequest},
+		{"unknown order", http.MethodPut, "/api/kitchen/orders/" + uuid.New().String() + "/items/" + order.Items[0].ID.String(), cookToken, `{"status":"ready"}`, http.StatusNotFound},
+		{"unknown item", http.MethodPut, "/api/kitchen/orders/" + order.ID.String() + "/items/" + uuid.New().String(), cookToken, `{"status":"ready"}`, http.StatusNotFound},
+		{"start preparing", http.MethodPut, itemPath, cookToken, `{"status":"preparing"}`, http.StatusOK},
+		{"start preparing twice", http.MethodPut, itemPath, cookToken, `{"status":"preparing"}`, http.StatusConflict},
+		{"mark ready", http.MethodPut, itemPath, cookToken, `{"status":"ready"}`, http.StatusOK},
+		{"ready order left the queue", http.MethodPut, itemPath, cookToken, `{"status":"ready"}`, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestKitchenHandler_StreamPushesUpdatedOrders(t *testing.T) {
+	storeID := uuid.New()
+	router, order, storeToken, _ := newKitchenTestRouter(t, storeID)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	cookToken := storeToken(domain.PermissionKitchenOperate)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/kitchen/stream", nil)
+	req.Header.Set("Authorization", "Bearer "+cookToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open the stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	update, _ := http.NewRequest(http.MethodPut, server.URL+"/api/kitchen/orders/"+order.ID.String()+"/items/"+order.Items[0].ID.String(), strings.NewReader(`{"status":"preparing"}`))
+	update.Header.Set("Authorization", "Bearer "+cookToken)
+	update.Header.Set("Content-Type", "application/json")
+	updated, err := http.DefaultClient.Do(update)
+	if err != nil || updated.StatusCode != http.StatusOK {
+		t.Fatalf("Failed to update the item: %v", err)
+	}
+	updated.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	var lines []string
+	for scanner.Scan() && scanner.Text() != "" {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 2 || lines[0] != "event: "+domain.OrderEventUpdated {
+		t.Fatalf("expected an update event, got %q", lines)
+	}
+	if !strings.HasPrefix(lines[1], "data: ") || !strings.Contains(lines[1], order.ID.String()) || !strings.Contains(lines[1], `"status":"preparing"`) {
+		t.Errorf("expected the preparing order as data, got %q", lines[1])
+	}
+}
+
+func TestKitchenHandler_StreamEndsWhenTheTokenExpires(t *testing.T) {
+	storeID := uuid.New()
+	router, _, _, tokens := newKitchenTestRouter(t, storeID)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	shortLived, err := tokens.Issue(uuid.New(), auth.Access{
+		StorePermissions: map[uuid.UUID][]string{storeID: {domain.PermissionKitchenOperate}},
+	}, 2*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/kitchen/stream", nil)
+	req.Header.Set("Authorization", "Bearer "+shortLived)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open the stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected an event stream, got %d", resp.StatusCode)
+	}
+
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		t.Fatalf("expected the stream to end when the token expires, got %v", err)
+	}
+}
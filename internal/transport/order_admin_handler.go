@@ -0,0 +1,82 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/middleware"
+	"pizza-must/internal/repository"
+	"pizza-must/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// OrderTransitionRequest represents the payload for moving an order to a new status
+type OrderTransitionRequest struct {
+	Status string `json:"status" validate:"required"`
+	Reason string `json:"reason" validate:"max=1000"`
+}
+
+// RegisterAdminRoutes registers order management routes restricted to staff who may
+// transition orders at one store or more; the service checks the order's store
+func (h *OrderHandler) RegisterAdminRoutes(r chi.Router, authMiddleware func(http.Handler) http.Handler) {
+	r.Route("/api/admin/orders", func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.Use(middleware.RequireStorePermission(domain.PermissionOrdersTransition, h.logger))
+
+		r.Post("/{id}/transition", h.TransitionStatus)
+	})
+}
+
+// TransitionStatus handles moving an order through its lifecycle
+func (h *OrderHandler) TransitionStatus(w http.ResponseWriter, r *http.Request) {
+	principal, ok := currentPrincipal(w, r, h.logger)
+	if !ok {
+		return
+	}
+	actorID := principal.UserID
+
+	orderID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid order ID")
+		return
+	}
+
+	var req OrderTransitionRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	order, err := h.orderService.TransitionStatus(r.Context(), orderID, actorID, principal, req.Status, req.Reason)
+	if err != nil {
+		var transitionErr *service.OrderTransitionError
+		switch {
+		case errors.As(err, &transitionErr):
+			middleware.RespondWithErrorDetails(w, http.StatusConflict, "illegal order status transition", map[string]interface{}{
+				"from":    transitionErr.From,
+				"to":      transitionErr.To,
+				"allowed": transitionErr.Allowed,
+			})
+		case err == service.ErrInvalidOrderStatus:
+			middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case err == service.ErrInsufficientPermission:
+			middleware.RespondWithError(w, http.StatusForbidden, err.Error())
+		case err == repository.ErrOrderNotFound:
+			middleware.RespondWithError(w, http.StatusNotFound, "order not found")
+		default:
+			h.logger.Error("Order transition failed", zap.Error(err))
+			middleware.RespondWithError(w, http.StatusInternalServerError, "failed to transition order")
+		}
+		return
+	}
+
+	h.logger.Info("Order status changed",
+		zap.String("order_id", order.ID.String()),
+		zap.String("status", order.Status),
+		zap.String("actor_id", actorID.String()),
+	)
+	middleware.RespondWithJSON(w, http.StatusOK, order)
+}
@@ -0,0 +1,219 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"pizza-must/internal/middleware"
+	"pizza-must/internal/repository"
+	"pizza-must/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// dateLayout is the accepted format for date-only query parameters
+const dateLayout = "2006-01-02"
+
+// CheckoutRequest represents the optional checkout payload.
+// Omitting scheduled_for orders for as soon as possible; omitting fulfillment_type picks
+// the order up at the store. Deliveries need the address_id of an address book entry.
+type CheckoutRequest struct {
+	ScheduledFor *time.Time `json:"scheduled_for"`
+	Fulfillment  string     `json:"fulfillment_type" validate:"omitempty,oneof=pickup delivery"`
+	AddressID    string     `json:"address_id" validate:"omitempty,uuid"`
+}
+
+// OrderHandler handles HTTP requests for customer orders
+type OrderHandler struct {
+	orderService service.OrderService
+	logger       *zap.Logger
+}
+
+// NewOrderHandler creates a new OrderHandler
+func NewOrderHandler(orderService service.OrderService, logger *zap.Logger) *OrderHandler {
+	return &OrderHandler{
+		orderService: orderService,
+		logger:       logger,
+	}
+}
+
+// RegisterRoutes registers all order routes; every order route requires authentication.
+// checkoutMiddlewares run before checkout only, after authentication.
+func (h *OrderHandler) RegisterRoutes(r chi.Router, authMiddleware func(http.Handler) http.Handler, checkoutMiddlewares ...func(http.Handler) http.Handler) {
+	r.Route("/api/orders", func(r chi.Router) {
+		r.Use(authMiddleware)
+
+		r.Get("/", h.ListOrders)
+		r.Get("/{id}", h.GetOrder)
+		r.With(checkoutMiddlewares...).Post("/checkout", h.Checkout)
+	})
+}
+
+// Checkout handles converting the current user's cart into an order.
+// The request body is optional; scheduled_for must be the start of an available time slot
+// and a delivery's address_id one of the user's addresses. Deliveries are placed at the store
+// serving the address, which may differ from the cart's store.
+func (h *OrderHandler) Checkout(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	var req CheckoutRequest
+	if r.ContentLength != 0 && !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	opts := service.CheckoutOptions{ScheduledFor: req.ScheduledFor, Fulfillment: req.Fulfillment}
+	if req.AddressID != "" {
+		addressID := uuid.MustParse(req.AddressID)
+		opts.AddressID = &addressID
+	}
+
+	order, err := h.orderService.Checkout(r.Context(), userID, opts)
+	if err != nil {
+		var stockErr *service.InsufficientStockError
+		var couponErr *service.CouponError
+		switch {
+		case errors.As(err, &stockErr):
+			middleware.RespondWithErrorDetails(w, http.StatusConflict, "insufficient stock", map[string]interface{}{
+				"shortages": stockErr.Shortages,
+			})
+		case errors.As(err, &couponErr):
+			middleware.RespondWithErrorDetails(w, http.StatusUnprocessableEntity, "coupon cannot be applied", map[string]interface{}{
+				"code":   couponErr.Code,
+				"reason": couponErr.Reason,
+			})
+		case err == service.ErrEmptyCart:
+			middleware.RespondWithError(w, http.StatusUnprocessableEntity, "cart is empty")
+		case err == service.ErrStoreInactive, err == service.ErrStoreClosed, err == service.ErrSlotFull:
+			middleware.RespondWithError(w, http.StatusConflict, err.Error())
+		case err == repository.ErrAddressNotFound:
+			middleware.RespondWithError(w, http.StatusNotFound, "address not found")
+		case errors.Is(err, service.ErrInvalidSchedule), errors.Is(err, service.ErrInvalidFulfillment),
+			err == service.ErrAddressNotServed, errors.Is(err, service.ErrBelowDeliveryMinimum),
+			errors.Is(err, service.ErrInvalidOptionSelection):
+			middleware.RespondWithError(w, http.StatusUnprocessableEntity, err.Error())
+		default:
+			h.logger.Error("Checkout failed", zap.Error(err))
+			middleware.RespondWithError(w, http.StatusInternalServerError, "failed to checkout")
+		}
+		return
+	}
+
+	h.logger.Info("Order placed",
+		zap.String("order_id", order.ID.String()),
+		zap.String("user_id", userID.String()),
+		zap.Stringer("total", order.Total),
+	)
+	middleware.RespondWithJSON(w, http.StatusCreated, order)
+}
+
+// ListOrders handles listing the current user's orders.
+// Supports status, from and to filters; from/to accept RFC 3339 timestamps or dates,
+// and a date-only "to" includes the whole day.
+func (h *OrderHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	page, pageSize, err := parsePagination(r)
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	opts := service.OrderListOptions{
+		Status:   query.Get("status"),
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	if raw := query.Get("from"); raw != "" {
+		from, err := parseTimeParam(raw, false)
+		if err != nil {
+			middleware.RespondWithError(w, http.StatusBadRequest, "invalid from date")
+			return
+		}
+		opts.CreatedFrom = &from
+	}
+
+	if raw := query.Get("to"); raw != "" {
+		to, err := parseTimeParam(raw, true)
+		if err != nil {
+			middleware.RespondWithError(w, http.StatusBadRequest, "invalid to date")
+			return
+		}
+		opts.CreatedTo = &to
+	}
+
+	result, err := h.orderService.ListOrders(r.Context(), userID, opts)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidOrderStatus, service.ErrInvalidDateRange:
+			middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			h.logger.Error("Failed to list orders", zap.Error(err))
+			middleware.RespondWithError(w, http.StatusInternalServerError, "failed to list orders")
+		}
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, PaginatedResponse{
+		Items:      result.Orders,
+		Total:      result.Total,
+		Page:       result.Page,
+		PageSize:   result.PageSize,
+		TotalPages: result.TotalPages,
+	})
+}
+
+// GetOrder handles retrieving a single order with its items and status history
+func (h *OrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	principal, ok := currentPrincipal(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	orderID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid order ID")
+		return
+	}
+
+	order, err := h.orderService.GetOrder(r.Context(), orderID, principal.UserID, principal)
+	if err != nil {
+		if err == repository.ErrOrderNotFound {
+			middleware.RespondWithError(w, http.StatusNotFound, "order not found")
+			return
+		}
+		h.logger.Error("Failed to get order", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, "failed to get order")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, order)
+}
+
+// parseTimeParam parses an RFC 3339 timestamp or a date. When endOfDay is set, a date
+// is moved to the start of the following day so it can be used as an exclusive bound.
+func parseTimeParam(raw string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(dateLayout, raw)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
@@ -0,0 +1,43 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+var (
+	errInvalidPage     = errors.New("page must be a positive integer")
+	errInvalidPageSize = errors.New("page_size must be a positive integer")
+)
+
+// PaginatedResponse represents a page of results with pagination metadata
+type PaginatedResponse struct {
+	Items      interface{} `json:"items"`
+	Total      int         `json:"total"`
+	Page       int         `json:"page"`
+	PageSize   int         `json:"page_size"`
+	TotalPages int         `json:"total_pages"`
+}
+
+// parsePagination reads the page and page_size query parameters.
+// Missing values are returned as zero so the service layer can apply its defaults.
+func parsePagination(r *http.Request) (page, pageSize int, err error) {
+	query := r.URL.Query()
+
+	if raw := query.Get("page"); raw != "" {
+		page, err = strconv.Atoi(raw)
+		if err != nil || page < 1 {
+			return 0, 0, errInvalidPage
+		}
+	}
+
+	if raw := query.Get("page_size"); raw != "" {
+		pageSize, err = strconv.Atoi(raw)
+		if err != nil || pageSize < 1 {
+			return 0, 0, errInvalidPageSize
+		}
+	}
+
+	return page, pageSize, nil
+}
@@ -0,0 +1,154 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pizza-must/internal/middleware"
+	"pizza-must/internal/repository"
+	"pizza-must/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// forgotPasswordMessage is returned whether or not an account exists for the email
+const forgotPasswordMessage = "if an account exists for this email, a password reset link has been sent"
+
+// passwordResetTimeout bounds sending a reset link after the response has been written
+const passwordResetTimeout = 30 * time.Second
+
+// ForgotPasswordRequest represents the password reset request payload
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest represents the payload choosing a new password with a reset token
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// ChangePasswordRequest represents the payload changing the current user's password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8"`
+}
+
+// PasswordHandler handles HTTP requests for changing a password and for recovering an
+// account with a lost password
+type PasswordHandler struct {
+	userService          service.UserService
+	passwordResetService service.PasswordResetService
+	logger               *zap.Logger
+}
+
+// NewPasswordHandler creates a new PasswordHandler
+func NewPasswordHandler(userService service.UserService, passwordResetService service.PasswordResetService, logger *zap.Logger) *PasswordHandler {
+	return &PasswordHandler{
+		userService:          userService,
+		passwordResetService: passwordResetService,
+		logger:               logger,
+	}
+}
+
+// RegisterRoutes registers the password routes
+func (h *PasswordHandler) RegisterRoutes(r chi.Router, authMiddleware func(http.Handler) http.Handler) {
+	r.Route("/api/users/password", func(r chi.Router) {
+		// Public routes
+		r.Post("/forgot", h.ForgotPassword)
+		r.Post("/reset", h.ResetPassword)
+
+		// Protected routes
+		r.With(authMiddleware).Post("/", h.ChangePassword)
+	})
+}
+
+// ChangePassword handles changing the current user's password. Every session is signed
+// out, including the one making the request.
+func (h *PasswordHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	var req ChangePasswordRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	if err := h.userService.ChangePassword(r.Context(), userID, req.CurrentPassword, req.NewPassword); err != nil {
+		switch err {
+		case service.ErrIncorrectPassword:
+			middleware.RespondWithError(w, http.StatusForbidden, err.Error())
+		case repository.ErrUserNotFound:
+			middleware.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		default:
+			h.logger.Error("Password change failed", zap.Error(err))
+			middleware.RespondWithError(w, http.StatusInternalServerError, "failed to change password")
+		}
+		return
+	}
+
+	h.logger.Info("Password changed", zap.String("user_id", userID.String()))
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "password has been changed"})
+}
+
+// ForgotPassword handles requesting a password reset link.
+// The response is the same whether or not the email belongs to an account, and the link is
+// sent in the background with failures only logged, so neither the response nor its timing
+// can be used to discover accounts. Client IPs making too many requests get 429.
+func (h *PasswordHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req ForgotPasswordRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	ipAddress := clientInfo(r).IPAddress
+	if err := h.passwordResetService.CountRequest(r.Context(), ipAddress); err != nil {
+		var throttled *service.PasswordResetThrottledError
+		if errors.As(err, &throttled) {
+			h.logger.Warn("Password reset throttled", zap.String("ip_address", ipAddress))
+			w.Header().Set("Retry-After", strconv.Itoa(int(throttled.RetryAfter.Seconds())+1))
+			middleware.RespondWithError(w, http.StatusTooManyRequests, "too many password reset requests, try again later")
+			return
+		}
+		h.logger.Error("Password reset request failed", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, "failed to request password reset")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), passwordResetTimeout)
+	go func() {
+		defer cancel()
+		if err := h.passwordResetService.RequestPasswordReset(ctx, req.Email); err != nil {
+			h.logger.Error("Password reset request failed", zap.Error(err))
+		}
+	}()
+
+	middleware.RespondWithJSON(w, http.StatusAccepted, map[string]string{"message": forgotPasswordMessage})
+}
+
+// ResetPassword handles choosing a new password with a reset token
+func (h *PasswordHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	if err := h.passwordResetService.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		if err == service.ErrInvalidResetToken {
+			middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.Error("Password reset failed", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, "failed to reset password")
+		return
+	}
+
+	h.logger.Info("Password reset")
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "password has been reset"})
+}
@@ -0,0 +1,135 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pizza-must/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// stubPasswordResetService throttles one client IP, fails reset requests for one email,
+// reports every requested email on requested and rejects every token
+type stubPasswordResetService struct {
+	throttledIP  string
+	failingEmail string
+	requested    chan string
+}
+
+func (s *stubPasswordResetService) CountRequest(ctx context.Context, ipAddress string) error {
+	if ipAddress == s.throttledIP {
+		return &service.PasswordResetThrottledError{RetryAfter: time.Minute}
+	}
+	return nil
+}
+
+func (s *stubPasswordResetService) RequestPasswordReset(ctx context.Context, email string) error {
+	s.requested <- email
+	if email == s.failingEmail {
+		return errors.New("mail server unavailable")
+	}
+	return nil
+}
+
+func (s *stubPasswordResetService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	return service.ErrInvalidResetToken
+}
+
+func newPasswordTestRouter(t *testing.T) (http.Handler, *stubPasswordResetService) {
+	t.Helper()
+	router := chi.NewRouter()
+	logger := zap.NewNop()
+	noAuth := func(next http.Handler) http.Handler { return next }
+
+	// The password routes live under the user routes and must not be shadowed by them
+	userService := service.NewUserService(&mockTransactor{}, newMockUserRepository(), newMockRoleRepository(), newMockRefreshTokenRepository(), newTestTokenManager(t), service.DefaultTokenPolicy(), newTestLoginThrottle())
+	NewUserHandler(userService, &stubEmailVerificationService{}, logger).RegisterRoutes(router, noAuth)
+	resets := &stubPasswordResetService{
+		throttledIP:  "203.0.113.7",
+		failingEmail: "broken@example.com",
+		requested:    make(chan string, 10),
+	}
+	NewPasswordHandler(userService, resets, logger).RegisterRoutes(router, noAuth)
+	return router, resets
+}
+
+func TestForgotPassword_DoesNotRevealAccounts(t *testing.T) {
+	router, resets := newPasswordTestRouter(t)
+
+	var responses []string
+	for _, email := range []string{"known@example.com", "broken@example.com"} {
+		body := bytes.NewBufferString(`{"email":"` + email + `"}`)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/users/password/forgot", body))
+
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("expected 202 for %s, got %d: %s", email, rec.Code, rec.Body.String())
+		}
+		responses = append(responses, rec.Body.String())
+	}
+
+	if responses[0] != responses[1] {
+		t.Errorf("responses differ: %q and %q", responses[0], responses[1])
+	}
+
+	// The links are sent after responding
+	for i := 0; i < 2; i++ {
+		select {
+		case <-resets.requested:
+		case <-time.After(time.Second):
+			t.Fatal("expected the reset link to be requested in the background")
+		}
+	}
+}
+
+func TestForgotPassword_ThrottlesClientIP(t *testing.T) {
+	router, resets := newPasswordTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/users/password/forgot", bytes.NewBufferString(`{"email":"known@example.com"}`))
+	req.RemoteAddr = "203.0.113.7:4711"
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("expected a Retry-After header")
+	}
+	select {
+	case email := <-resets.requested:
+		t.Errorf("expected no reset link for a throttled IP, got one for %s", email)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestResetPassword_RejectsInvalidToken(t *testing.T) {
+	router, _ := newPasswordTestRouter(t)
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"invalid token", `{"token":"expired","password":"newpassword123"}`, http.StatusBadRequest},
+		{"short password", `{"token":"expired","password":"short"}`, http.StatusBadRequest},
+		{"missing token", `{"password":"newpassword123"}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/users/password/reset", bytes.NewBufferString(tt.body)))
+			if rec.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
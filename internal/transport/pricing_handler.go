@@ -0,0 +1,100 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/middleware"
+	"pizza-must/internal/repository"
+	"pizza-must/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// OptionSelectionRequest represents a chosen option and where it goes on the pizza
+type OptionSelectionRequest struct {
+	OptionID  string `json:"option_id" validate:"required,uuid"`
+	Placement string `json:"placement" validate:"omitempty,oneof=whole left right"`
+}
+
+// QuoteRequest represents the payload for previewing the price of a configured product,
+// at a store's price when a store is given
+type QuoteRequest struct {
+	ProductID  string                   `json:"product_id" validate:"required,uuid"`
+	StoreID    string                   `json:"store_id" validate:"omitempty,uuid"`
+	Selections []OptionSelectionRequest `json:"selections" validate:"omitempty,dive"`
+	Quantity   int                      `json:"quantity" validate:"omitempty,gte=1,lte=99"`
+}
+
+// PricingHandler handles HTTP requests for price quotes
+type PricingHandler struct {
+	pricing service.PricingEngine
+	logger  *zap.Logger
+}
+
+// NewPricingHandler creates a new PricingHandler
+func NewPricingHandler(pricing service.PricingEngine, logger *zap.Logger) *PricingHandler {
+	return &PricingHandler{
+		pricing: pricing,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes registers the public pricing routes
+func (h *PricingHandler) RegisterRoutes(r chi.Router) {
+	r.Post("/api/pricing/quote", h.Quote)
+}
+
+// Quote handles pricing a product with the chosen options without adding it to the cart
+func (h *PricingHandler) Quote(w http.ResponseWriter, r *http.Request) {
+	var req QuoteRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	var storeID *uuid.UUID
+	if req.StoreID != "" {
+		id := uuid.MustParse(req.StoreID)
+		storeID = &id
+	}
+
+	quote, err := h.pricing.Quote(r.Context(), storeID, uuid.MustParse(req.ProductID), toOptionSelections(req.Selections), quantity)
+	if err != nil {
+		switch {
+		case err == repository.ErrProductNotFound:
+			middleware.RespondWithError(w, http.StatusNotFound, "product not found")
+		case err == repository.ErrProductUnavailable:
+			middleware.RespondWithError(w, http.StatusNotFound, err.Error())
+		case err == service.ErrInvalidQuantity:
+			middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrInvalidOptionSelection):
+			middleware.RespondWithError(w, http.StatusUnprocessableEntity, err.Error())
+		default:
+			h.logger.Error("Failed to quote price", zap.Error(err))
+			middleware.RespondWithError(w, http.StatusInternalServerError, "failed to quote price")
+		}
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, quote)
+}
+
+// toOptionSelections converts validated selection payloads into domain selections
+func toOptionSelections(reqs []OptionSelectionRequest) []domain.OptionSelection {
+	selections := make([]domain.OptionSelection, 0, len(reqs))
+	for _, req := range reqs {
+		selections = append(selections, domain.OptionSelection{
+			OptionID:  uuid.MustParse(req.OptionID),
+			Placement: req.Placement,
+		})
+	}
+	return selections
+}
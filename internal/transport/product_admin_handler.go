@@ -0,0 +1,325 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/middleware"
+	"pizza-must/internal/repository"
+	"pizza-must/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ProductRequest represents the create and full-update product payload
+type ProductRequest struct {
+	Name        string        `json:"name" validate:"required,max=255"`
+	Description string        `json:"description"`
+	Price       *domain.Money `json:"price" validate:"required,gte=0"`
+	CategoryID  string        `json:"category_id" validate:"required,uuid"`
+	ImageURL    string        `json:"image_url" validate:"omitempty,url,max=500"`
+}
+
+// PatchProductRequest represents the partial product update payload
+type PatchProductRequest struct {
+	Name        *string       `json:"name" validate:"omitempty,min=1,max=255"`
+	Description *string       `json:"description"`
+	Price       *domain.Money `json:"price" validate:"omitempty,gte=0"`
+	CategoryID  *string       `json:"category_id" validate:"omitempty,uuid"`
+	ImageURL    *string       `json:"image_url" validate:"omitempty,max=500"`
+}
+
+// ProductOptionsRequest represents the full set of option groups offered for a product
+type ProductOptionsRequest struct {
+	Groups []OptionGroupRequest `json:"groups" validate:"dive"`
+}
+
+// OptionGroupRequest represents a single option group, such as size, crust or toppings
+type OptionGroupRequest struct {
+	Name        string          `json:"name" validate:"required,max=100"`
+	MinSelect   int             `json:"min_select" validate:"gte=0"`
+	MaxSelect   int             `json:"max_select" validate:"required,gte=1"`
+	AllowHalves bool            `json:"allow_halves"`
+	Options     []OptionRequest `json:"options" validate:"required,min=1,dive"`
+}
+
+// OptionRequest represents a single option and the amount it adds to the product price
+type OptionRequest struct {
+	Name       string       `json:"name" validate:"required,max=100"`
+	PriceDelta domain.Money `json:"price_delta"`
+}
+
+// CategoryRequest represents the create and update category payload
+type CategoryRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description"`
+}
+
+// RegisterAdminRoutes registers catalog management routes restricted to users who may
+// edit the menu
+func (h *ProductHandler) RegisterAdminRoutes(r chi.Router, authMiddleware func(http.Handler) http.Handler) {
+	r.Route("/api/admin/products", func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.Use(middleware.RequirePermission(domain.PermissionProductsWrite, h.logger))
+
+		r.Post("/", h.CreateProduct)
+		r.Put("/{id}", h.UpdateProduct)
+		r.Patch("/{id}", h.PatchProduct)
+		r.Delete("/{id}", h.DeleteProduct)
+		r.Put("/{id}/options", h.SetProductOptions)
+	})
+
+	r.Route("/api/admin/categories", func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.Use(middleware.RequirePermission(domain.PermissionProductsWrite, h.logger))
+
+		r.Post("/", h.CreateCategory)
+		r.Put("/{id}", h.UpdateCategory)
+		r.Delete("/{id}", h.DeleteCategory)
+	})
+}
+
+// CreateProduct handles adding a product to the catalog
+func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
+	var req ProductRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	product, err := h.productService.CreateProduct(r.Context(), req.toInput())
+	if err != nil {
+		h.respondWithProductError(w, err, "failed to create product")
+		return
+	}
+
+	h.logger.Info("Product created", zap.String("product_id", product.ID.String()))
+	middleware.RespondWithJSON(w, http.StatusCreated, product)
+}
+
+// UpdateProduct handles replacing all attributes of a product
+func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
+	productID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	var req ProductRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	product, err := h.productService.UpdateProduct(r.Context(), productID, req.toInput())
+	if err != nil {
+		h.respondWithProductError(w, err, "failed to update product")
+		return
+	}
+
+	h.logger.Info("Product updated", zap.String("product_id", product.ID.String()))
+	middleware.RespondWithJSON(w, http.StatusOK, product)
+}
+
+// PatchProduct handles partially updating a product
+func (h *ProductHandler) PatchProduct(w http.ResponseWriter, r *http.Request) {
+	productID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	var req PatchProductRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	patch := service.ProductPatch{
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+		ImageURL:    req.ImageURL,
+	}
+	if req.CategoryID != nil {
+		// Already validated as a UUID by the request tags
+		categoryID := uuid.MustParse(*req.CategoryID)
+		patch.CategoryID = &categoryID
+	}
+
+	product, err := h.productService.PatchProduct(r.Context(), productID, patch)
+	if err != nil {
+		h.respondWithProductError(w, err, "failed to update product")
+		return
+	}
+
+	h.logger.Info("Product patched", zap.String("product_id", product.ID.String()))
+	middleware.RespondWithJSON(w, http.StatusOK, product)
+}
+
+// DeleteProduct handles removing a product from the catalog
+func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
+	productID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	if err := h.productService.DeleteProduct(r.Context(), productID); err != nil {
+		h.respondWithProductError(w, err, "failed to delete product")
+		return
+	}
+
+	h.logger.Info("Product deleted", zap.String("product_id", productID.String()))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetProductOptions handles replacing the option groups of a product
+func (h *ProductHandler) SetProductOptions(w http.ResponseWriter, r *http.Request) {
+	productID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	var req ProductOptionsRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	groups, err := h.productService.SetProductOptions(r.Context(), productID, req.toInput())
+	if err != nil {
+		h.respondWithProductError(w, err, "failed to set product options")
+		return
+	}
+
+	h.logger.Info("Product options replaced",
+		zap.String("product_id", productID.String()),
+		zap.Int("groups", len(groups)),
+	)
+	middleware.RespondWithJSON(w, http.StatusOK, groups)
+}
+
+// CreateCategory handles adding a category
+func (h *ProductHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var req CategoryRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	category, err := h.categoryService.CreateCategory(r.Context(), req.Name, req.Description)
+	if err != nil {
+		h.respondWithCategoryError(w, err, "failed to create category")
+		return
+	}
+
+	h.logger.Info("Category created", zap.String("category_id", category.ID.String()))
+	middleware.RespondWithJSON(w, http.StatusCreated, category)
+}
+
+// UpdateCategory handles renaming or re-describing a category
+func (h *ProductHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	categoryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid category ID")
+		return
+	}
+
+	var req CategoryRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	category, err := h.categoryService.UpdateCategory(r.Context(), categoryID, req.Name, req.Description)
+	if err != nil {
+		h.respondWithCategoryError(w, err, "failed to update category")
+		return
+	}
+
+	h.logger.Info("Category updated", zap.String("category_id", category.ID.String()))
+	middleware.RespondWithJSON(w, http.StatusOK, category)
+}
+
+// DeleteCategory handles removing a category that has no products
+func (h *ProductHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	categoryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid category ID")
+		return
+	}
+
+	if err := h.categoryService.DeleteCategory(r.Context(), categoryID); err != nil {
+		h.respondWithCategoryError(w, err, "failed to delete category")
+		return
+	}
+
+	h.logger.Info("Category deleted", zap.String("category_id", categoryID.String()))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respondWithProductError maps product service errors to HTTP responses
+func (h *ProductHandler) respondWithProductError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case err == repository.ErrProductNotFound:
+		middleware.RespondWithError(w, http.StatusNotFound, "product not found")
+	case err == repository.ErrCategoryNotFound:
+		middleware.RespondWithError(w, http.StatusUnprocessableEntity, "category does not exist")
+	case err == repository.ErrProductHasOrders:
+		middleware.RespondWithError(w, http.StatusConflict, "product is referenced by existing orders")
+	case err == repository.ErrDuplicateOptionName:
+		middleware.RespondWithError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrInvalidOptionGroup):
+		middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.Error("Product operation failed", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, fallback)
+	}
+}
+
+// respondWithCategoryError maps category service errors to HTTP responses
+func (h *ProductHandler) respondWithCategoryError(w http.ResponseWriter, err error, fallback string) {
+	switch err {
+	case repository.ErrCategoryNotFound:
+		middleware.RespondWithError(w, http.StatusNotFound, "category not found")
+	case repository.ErrCategoryAlreadyExists:
+		middleware.RespondWithError(w, http.StatusConflict, "category with this name already exists")
+	case repository.ErrCategoryHasProducts:
+		middleware.RespondWithError(w, http.StatusConflict, "category still has products; move or delete them first")
+	default:
+		h.logger.Error("Category operation failed", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, fallback)
+	}
+}
+
+// toInput converts the request payload into a service ProductInput
+func (req ProductRequest) toInput() service.ProductInput {
+	return service.ProductInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       *req.Price,
+		CategoryID:  uuid.MustParse(req.CategoryID),
+		ImageURL:    req.ImageURL,
+	}
+}
+
+// toInput converts the request payload into service option group inputs
+func (req ProductOptionsRequest) toInput() []service.OptionGroupInput {
+	groups := make([]service.OptionGroupInput, 0, len(req.Groups))
+	for _, group := range req.Groups {
+		input := service.OptionGroupInput{
+			Name:        group.Name,
+			MinSelect:   group.MinSelect,
+			MaxSelect:   group.MaxSelect,
+			AllowHalves: group.AllowHalves,
+			Options:     make([]service.OptionInput, 0, len(group.Options)),
+		}
+		for _, option := range group.Options {
+			input.Options = append(input.Options, service.OptionInput{
+				Name:       option.Name,
+				PriceDelta: option.PriceDelta,
+			})
+		}
+		groups = append(groups, input)
+	}
+	return groups
+}
@@ -0,0 +1,213 @@
+package transport
+
+import (
+	"net/http"
+	"strings"
+
+	"pizza-must/internal/middleware"
+	"pizza-must/internal/repository"
+	"pizza-must/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// validProductSortFields lists the sort fields accepted by the product listing endpoint
+var validProductSortFields = map[string]bool{
+	"name":       true,
+	"price":      true,
+	"created_at": true,
+	"stock":      true,
+}
+
+// ProductHandler handles HTTP requests for the product catalog
+type ProductHandler struct {
+	productService  service.ProductService
+	categoryService service.CategoryService
+	logger          *zap.Logger
+}
+
+// NewProductHandler creates a new ProductHandler
+func NewProductHandler(productService service.ProductService, categoryService service.CategoryService, logger *zap.Logger) *ProductHandler {
+	return &ProductHandler{
+		productService:  productService,
+		categoryService: categoryService,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registers all public catalog routes
+func (h *ProductHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/api/products", func(r chi.Router) {
+		r.Get("/", h.ListProducts)
+		r.Get("/search", h.SearchProducts)
+		r.Get("/{id}", h.GetProduct)
+	})
+
+	r.Get("/api/categories", h.ListCategories)
+}
+
+// ListProducts handles listing products with category and store filters, sorting and
+// pagination. With a store_id only the store's menu is listed at the store's prices.
+func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
+	page, pageSize, err := parsePagination(r)
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	storeID, ok := parseStoreID(w, r)
+	if !ok {
+		return
+	}
+
+	// Default to newest products first
+	query := r.URL.Query()
+	opts := service.ProductListOptions{
+		StoreID:   storeID,
+		Page:      page,
+		PageSize:  pageSize,
+		SortBy:    "created_at",
+		SortOrder: repository.SortOrderDesc,
+	}
+
+	if raw := query.Get("category_id"); raw != "" {
+		categoryID, err := uuid.Parse(raw)
+		if err != nil {
+			middleware.RespondWithError(w, http.StatusBadRequest, "invalid category ID")
+			return
+		}
+		opts.CategoryID = &categoryID
+	}
+
+	if sortBy := query.Get("sort"); sortBy != "" {
+		if !validProductSortFields[sortBy] {
+			middleware.RespondWithError(w, http.StatusBadRequest, "invalid sort field")
+			return
+		}
+		// Stock is kept per store
+		if sortBy == "stock" && storeID == nil {
+			middleware.RespondWithError(w, http.StatusBadRequest, "sorting by stock requires a store_id")
+			return
+		}
+		opts.SortBy = sortBy
+	}
+
+	if order := query.Get("order"); order != "" {
+		switch strings.ToLower(order) {
+		case "asc":
+			opts.SortOrder = repository.SortOrderAsc
+		case "desc":
+			opts.SortOrder = repository.SortOrderDesc
+		default:
+			middleware.RespondWithError(w, http.StatusBadRequest, "invalid sort order")
+			return
+		}
+	}
+
+	result, err := h.productService.ListProducts(r.Context(), opts)
+	if err != nil {
+		switch err {
+		case repository.ErrCategoryNotFound:
+			middleware.RespondWithError(w, http.StatusNotFound, "category not found")
+			return
+		case repository.ErrStoreNotFound:
+			middleware.RespondWithError(w, http.StatusNotFound, "store not found")
+			return
+		}
+
+		h.logger.Error("Failed to list products", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, "failed to list products")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, newProductPageResponse(result))
+}
+
+// SearchProducts handles searching products by name or description, optionally within a
+// store's menu
+func (h *ProductHandler) SearchProducts(w http.ResponseWriter, r *http.Request) {
+	page, pageSize, err := parsePagination(r)
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	storeID, ok := parseStoreID(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.productService.SearchProducts(r.Context(), r.URL.Query().Get("q"), storeID, page, pageSize)
+	if err != nil {
+		if err == repository.ErrStoreNotFound {
+			middleware.RespondWithError(w, http.StatusNotFound, "store not found")
+			return
+		}
+
+		h.logger.Error("Failed to search products", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, "failed to search products")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, newProductPageResponse(result))
+}
+
+// GetProduct handles retrieving a single product, at a store's price when store_id is given
+func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
+	productID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	storeID, ok := parseStoreID(w, r)
+	if !ok {
+		return
+	}
+
+	product, err := h.productService.GetProduct(r.Context(), productID, storeID)
+	if err != nil {
+		switch err {
+		case repository.ErrProductNotFound:
+			middleware.RespondWithError(w, http.StatusNotFound, "product not found")
+			return
+		case repository.ErrProductUnavailable:
+			middleware.RespondWithError(w, http.StatusNotFound, err.Error())
+			return
+		case repository.ErrStoreNotFound:
+			middleware.RespondWithError(w, http.StatusNotFound, "store not found")
+			return
+		}
+
+		h.logger.Error("Failed to get product", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, "failed to get product")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, product)
+}
+
+// ListCategories handles listing all categories
+func (h *ProductHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.categoryService.ListCategories(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list categories", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, "failed to list categories")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, categories)
+}
+
+// newProductPageResponse converts a service ProductPage into the paginated response envelope
+func newProductPageResponse(result *service.ProductPage) PaginatedResponse {
+	return PaginatedResponse{
+		Items:      result.Products,
+		Total:      result.Total,
+		Page:       result.Page,
+		PageSize:   result.PageSize,
+		TotalPages: result.TotalPages,
+	}
+}
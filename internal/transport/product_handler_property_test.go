@@ -0,0 +1,392 @@
+package transport
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"pizza-must/internal/auth"
+	"pizza-must/internal/domain"
+	"pizza-must/internal/middleware"
+	"pizza-must/internal/repository"
+	"pizza-must/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"go.uber.org/zap"
+)
+
+type mockProductRepository struct {
+	products []*domain.Product
+}
+
+func newMockProductRepository() *mockProductRepository {
+	return &mockProductRepository{}
+}
+
+func (m *mockProductRepository) Create(ctx context.Context, product *domain.Product) error {
+	m.products = append(m.products, product)
+	return nil
+}
+
+func (m *mockProductRepository) Update(ctx context.Context, product *domain.Product) error {
+	for i, p := range m.products {
+		if p.ID == product.ID {
+			m.products[i] = product
+			return nil
+		}
+	}
+	return repository.ErrProductNotFound
+}
+
+func (m *mockProductRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	for i, p := range m.products {
+		if p.ID == id {
+			m.products = append(m.products[:i], m.products[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrProductNotFound
+}
+
+func (m *mockProductRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
+	for _, p := range m.products {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return nil, repository.ErrProductNotFound
+}
+
+// FindForStore treats every product as listed at every store with its Stock
+func (m *mockProductRepository) FindForStore(ctx context.Context, storeID, id uuid.UUID) (*domain.Product, error) {
+	return m.FindByID(ctx, id)
+}
+
+func (m *mockProductRepository) List(ctx context.Context, categoryID, storeID *uuid.UUID, page, pageSize int, sortBy string, sortOrder repository.SortOrder) ([]*domain.Product, int, error) {
+	filtered := []*domain.Product{}
+	for _, p := range m.products {
+		if categoryID == nil || p.CategoryID == *categoryID {
+			filtered = append(filtered, p)
+		}
+	}
+	start := (page - 1) * pageSize
+	if start >= len(filtered) {
+		return []*domain.Product{}, len(filtered), nil
+	}
+	end := start + pageSize
+	if end > len(filtered) {
+		end = len(filtered)
+	}
+	return filtered[start:end], len(filtered), nil
+}
+
+func (m *mockProductRepository) Search(ctx context.Context, query string, storeID *uuid.UUID, page, pageSize int) ([]*domain.Product, int, error) {
+	return m.List(ctx, nil, storeID, page, pageSize, "created_at", repository.SortOrderDesc)
+}
+
+func (m *mockProductRepository) AdjustStockTx(ctx context.Context, tx *sql.Tx, storeID, id uuid.UUID, delta int) error {
+	for _, p := range m.products {
+		if p.ID == id {
+			p.Stock += delta
+			return nil
+		}
+	}
+	return repository.ErrProductNotFound
+}
+
+type mockCategoryRepository struct {
+	categories map[uuid.UUID]*domain.Category
+	// productRepo emulates the products.category_id foreign key when set
+	productRepo *mockProductRepository
+}
+
+func newMockCategoryRepository() *mockCategoryRepository {
+	return &mockCategoryRepository{
+		categories: make(map[uuid.UUID]*domain.Category),
+	}
+}
+
+func (m *mockCategoryRepository) Create(ctx context.Context, category *domain.Category) error {
+	for _, c := range m.categories {
+		if c.Name == category.Name {
+			return repository.ErrCategoryAlreadyExists
+		}
+	}
+	m.categories[category.ID] = category
+	return nil
+}
+
+func (m *mockCategoryRepository) List(ctx context.Context) ([]*domain.Category, error) {
+	categories := []*domain.Category{}
+	for _, c := range m.categories {
+		categories = append(categories, c)
+	}
+	return categories, nil
+}
+
+func (m *mockCategoryRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Category, error) {
+	category, exists := m.categories[id]
+	if !exists {
+		return nil, repository.ErrCategoryNotFound
+	}
+	return category, nil
+}
+
+func (m *mockCategoryRepository) Update(ctx context.Context, category *domain.Category) error {
+	if _, exists := m.categories[category.ID]; !exists {
+		return repository.ErrCategoryNotFound
+	}
+	for _, c := range m.categories {
+		if c.ID != category.ID && c.Name == category.Name {
+			return repository.ErrCategoryAlreadyExists
+		}
+	}
+	m.categories[category.ID] = category
+	return nil
+}
+
+func (m *mockCategoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, exists := m.categories[id]; !exists {
+		return repository.ErrCategoryNotFound
+	}
+	if m.productRepo != nil {
+		for _, p := range m.productRepo.products {
+			if p.CategoryID == id {
+				return repository.ErrCategoryHasProducts
+			}
+		}
+	}
+	delete(m.categories, id)
+	return nil
+}
+
+// mockTransactor runs the unit of work directly without a real transaction
+type mockTransactor struct{}
+
+func (m *mockTransactor) WithinTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	return fn(nil)
+}
+
+type mockProductOptionRepository struct {
+	groups map[uuid.UUID][]*domain.OptionGroup
+}
+
+func (m *mockProductOptionRepository) ListByProduct(ctx context.Context, productID uuid.UUID) ([]*domain.OptionGroup, error) {
+	return m.groups[productID], nil
+}
+
+func (m *mockProductOptionRepository) ReplaceForProductTx(ctx context.Context, tx *sql.Tx, productID uuid.UUID, groups []*domain.OptionGroup) error {
+	m.groups[productID] = groups
+	return nil
+}
+
+// newTestProductService builds a ProductService over the mocks. No cart repository is
+// wired, so tests must not replace the options of an existing product.
+func newTestProductService(productRepo *mockProductRepository, categoryRepo *mockCategoryRepository) service.ProductService {
+	optionRepo := &mockProductOptionRepository{groups: make(map[uuid.UUID][]*domain.OptionGroup)}
+	return service.NewProductService(&mockTransactor{}, productRepo, newMockStoreRepository(), categoryRepo, optionRepo, nil)
+}
+
+func newTestProductRouter(productRepo *mockProductRepository, categoryRepo *mockCategoryRepository) http.Handler {
+	logger, _ := zap.NewDevelopment()
+	handler := NewProductHandler(
+		newTestProductService(productRepo, categoryRepo),
+		service.NewCategoryService(categoryRepo),
+		logger,
+	)
+	router := chi.NewRouter()
+	handler.RegisterRoutes(router)
+	return router
+}
+
+// Feature: ordering-platform, Property 70: Product listing returns a paginated envelope
+func TestProperty_ProductListingReturnsPaginatedEnvelope(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("listing products returns items, total, page and total_pages", prop.ForAll(
+		func(productCount int, pageSize int) bool {
+			productRepo := newMockProductRepository()
+			categoryRepo := newMockCategoryRepository()
+			router := newTestProductRouter(productRepo, categoryRepo)
+
+			for i := 0; i < productCount; i++ {
+				_ = productRepo.Create(context.Background(), &domain.Product{
+					ID:        uuid.New(),
+					Name:      "Pepperoni",
+					Price:     domain.Cents(1150),
+					CreatedAt: time.Now(),
+				})
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/products?page=1&page_size="+strconv.Itoa(pageSize)+"&sort=price&order=asc", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Logf("FAIL: Expected 200 status code, got %d", w.Code)
+				return false
+			}
+
+			var response struct {
+				Items      []domain.Product `json:"items"`
+				Total      int              `json:"total"`
+				Page       int              `json:"page"`
+				TotalPages int              `json:"total_pages"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Logf("FAIL: Could not decode response: %v", err)
+				return false
+			}
+
+			if response.Total != productCount {
+				t.Logf("FAIL: Total mismatch. Expected %d, got %d", productCount, response.Total)
+				return false
+			}
+
+			if response.Page != 1 {
+				t.Logf("FAIL: Page mismatch. Expected 1, got %d", response.Page)
+				return false
+			}
+
+			expectedPages := (productCount + pageSize - 1) / pageSize
+			if response.TotalPages != expectedPages {
+				t.Logf("FAIL: TotalPages mismatch. Expected %d, got %d", expectedPages, response.TotalPages)
+				return false
+			}
+
+			if len(response.Items) > pageSize {
+				t.Logf("FAIL: Page holds %d items, more than page size %d", len(response.Items), pageSize)
+				return false
+			}
+
+			return true
+		},
+		gen.IntRange(0, 60),
+		gen.IntRange(1, 25),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestProductHandler_NotFoundMapping(t *testing.T) {
+	router := newTestProductRouter(newMockProductRepository(), newMockCategoryRepository())
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"unknown product", "/api/products/" + uuid.New().String(), http.StatusNotFound},
+		{"unknown category filter", "/api/products?category_id=" + uuid.New().String(), http.StatusNotFound},
+		{"malformed product ID", "/api/products/not-a-uuid", http.StatusBadRequest},
+		{"invalid page", "/api/products?page=0", http.StatusBadRequest},
+		{"invalid sort field", "/api/products?sort=password", http.StatusBadRequest},
+		{"invalid sort order", "/api/products?order=sideways", http.StatusBadRequest},
+		{"search", "/api/products/search?q=pizza", http.StatusOK},
+		{"categories", "/api/categories", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d (%s)", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+// newTestTokenManager creates a token manager with a fast EdDSA key for signing test tokens
+func newTestTokenManager(t *testing.T) auth.TokenManager {
+	t.Helper()
+	keys, err := auth.NewKeyManager(auth.KeyManagerConfig{Algorithm: auth.AlgorithmEdDSA, OverlapWindow: time.Hour}, auth.NewMemoryKeyStore())
+	if err != nil {
+		t.Fatalf("Failed to create key manager: %v", err)
+	}
+	return auth.NewTokenManager(keys, auth.TokenConfig{Issuer: "test", Audience: "test-api"})
+}
+
+func newTestAccessToken(t *testing.T, tokens auth.TokenManager, permissions ...string) string {
+	t.Helper()
+	signed, err := tokens.Issue(uuid.New(), auth.Access{Permissions: permissions}, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func TestProductHandler_AdminRoutes(t *testing.T) {
+	productRepo := newMockProductRepository()
+	categoryRepo := newMockCategoryRepository()
+	categoryRepo.productRepo = productRepo
+
+	logger, _ := zap.NewDevelopment()
+	handler := NewProductHandler(
+		newTestProductService(productRepo, categoryRepo),
+		service.NewCategoryService(categoryRepo),
+		logger,
+	)
+	router := chi.NewRouter()
+	tokens := newTestTokenManager(t)
+	handler.RegisterAdminRoutes(router, middleware.AuthMiddleware(tokens, logger))
+
+	adminToken := newTestAccessToken(t, tokens, domain.PermissionProductsWrite)
+	userToken := newTestAccessToken(t, tokens)
+
+	category := &domain.Category{ID: uuid.New(), Name: "Pizzas", CreatedAt: time.Now()}
+	_ = categoryRepo.Create(context.Background(), category)
+	margherita := &domain.Product{ID: uuid.New(), Name: "Margherita", CategoryID: category.ID}
+	_ = productRepo.Create(context.Background(), margherita)
+	sizes := `{"groups":[{"name":"Size","min_select":1,"max_select":2,"options":[{"name":"Small"}]}]}`
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		body   string
+		want   int
+	}{
+		{"missing token", http.MethodPost, "/api/admin/categories", "", `{"name":"Drinks"}`, http.StatusUnauthorized},
+		{"non-admin", http.MethodPost, "/api/admin/categories", userToken, `{"name":"Drinks"}`, http.StatusForbidden},
+		{"create category", http.MethodPost, "/api/admin/categories", adminToken, `{"name":"Drinks"}`, http.StatusCreated},
+		{"duplicate category", http.MethodPost, "/api/admin/categories", adminToken, `{"name":"Pizzas"}`, http.StatusConflict},
+		{"delete category with products", http.MethodDelete, "/api/admin/categories/" + category.ID.String(), adminToken, "", http.StatusConflict},
+		{"create product missing price", http.MethodPost, "/api/admin/products", adminToken, `{"name":"Diavola","category_id":"` + category.ID.String() + `"}`, http.StatusBadRequest},
+		{"create product unknown category", http.MethodPost, "/api/admin/products", adminToken, `{"name":"Diavola","price":12.5,"category_id":"` + uuid.New().String() + `"}`, http.StatusUnprocessableEntity},
+		{"create product", http.MethodPost, "/api/admin/products", adminToken, `{"name":"Diavola","price":12.5,"category_id":"` + category.ID.String() + `"}`, http.StatusCreated},
+		{"patch unknown product", http.MethodPatch, "/api/admin/products/" + uuid.New().String(), adminToken, `{"name":"Renamed"}`, http.StatusNotFound},
+		{"options missing group name", http.MethodPut, "/api/admin/products/" + margherita.ID.String() + "/options", adminToken, `{"groups":[{"max_select":1,"options":[{"name":"Small"}]}]}`, http.StatusBadRequest},
+		{"options exceeding group size", http.MethodPut, "/api/admin/products/" + margherita.ID.String() + "/options", adminToken, sizes, http.StatusBadRequest},
+		{"options for unknown product", http.MethodPut, "/api/admin/products/" + uuid.New().String() + "/options", adminToken, strings.Replace(sizes, `"max_select":2`, `"max_select":1`, 1), http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d (%s)", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
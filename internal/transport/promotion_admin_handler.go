@@ -0,0 +1,195 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/middleware"
+	"pizza-must/internal/repository"
+	"pizza-must/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// PromotionRequest represents the create and full-update promotion payload.
+// Omitting active creates an active promotion.
+type PromotionRequest struct {
+	Code                  string       `json:"code" validate:"required,max=50"`
+	Description           string       `json:"description"`
+	Type                  string       `json:"type" validate:"required,oneof=percentage fixed free_item bogo"`
+	PercentOff            int          `json:"percent_off" validate:"gte=0,lte=100"`
+	AmountOff             domain.Money `json:"amount_off" validate:"gte=0"`
+	MinOrderValue         domain.Money `json:"min_order_value" validate:"gte=0"`
+	ProductIDs            []string     `json:"product_ids" validate:"omitempty,dive,uuid"`
+	CategoryIDs           []string     `json:"category_ids" validate:"omitempty,dive,uuid"`
+	StartsAt              *time.Time   `json:"starts_at"`
+	EndsAt                *time.Time   `json:"ends_at"`
+	MaxRedemptions        int          `json:"max_redemptions" validate:"gte=0"`
+	MaxRedemptionsPerUser int          `json:"max_redemptions_per_user" validate:"gte=0"`
+	Active                *bool        `json:"active"`
+}
+
+// PromotionHandler handles HTTP requests for promotion management
+type PromotionHandler struct {
+	promotionService service.PromotionService
+	logger           *zap.Logger
+}
+
+// NewPromotionHandler creates a new PromotionHandler
+func NewPromotionHandler(promotionService service.PromotionService, logger *zap.Logger) *PromotionHandler {
+	return &PromotionHandler{
+		promotionService: promotionService,
+		logger:           logger,
+	}
+}
+
+// RegisterAdminRoutes registers promotion management routes restricted to users who may
+// manage promotions
+func (h *PromotionHandler) RegisterAdminRoutes(r chi.Router, authMiddleware func(http.Handler) http.Handler) {
+	r.Route("/api/admin/promotions", func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.Use(middleware.RequirePermission(domain.PermissionPromotionsWrite, h.logger))
+
+		r.Get("/", h.ListPromotions)
+		r.Post("/", h.CreatePromotion)
+		r.Get("/{id}", h.GetPromotion)
+		r.Put("/{id}", h.UpdatePromotion)
+		r.Delete("/{id}", h.DeletePromotion)
+	})
+}
+
+// ListPromotions handles listing every promotion
+func (h *PromotionHandler) ListPromotions(w http.ResponseWriter, r *http.Request) {
+	promotions, err := h.promotionService.ListPromotions(r.Context())
+	if err != nil {
+		h.respondWithPromotionError(w, err, "failed to list promotions")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, promotions)
+}
+
+// GetPromotion handles retrieving a single promotion
+func (h *PromotionHandler) GetPromotion(w http.ResponseWriter, r *http.Request) {
+	promotionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid promotion ID")
+		return
+	}
+
+	promotion, err := h.promotionService.GetPromotion(r.Context(), promotionID)
+	if err != nil {
+		h.respondWithPromotionError(w, err, "failed to get promotion")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, promotion)
+}
+
+// CreatePromotion handles adding a promotion
+func (h *PromotionHandler) CreatePromotion(w http.ResponseWriter, r *http.Request) {
+	var req PromotionRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	promotion, err := h.promotionService.CreatePromotion(r.Context(), req.toInput())
+	if err != nil {
+		h.respondWithPromotionError(w, err, "failed to create promotion")
+		return
+	}
+
+	h.logger.Info("Promotion created",
+		zap.String("promotion_id", promotion.ID.String()),
+		zap.String("code", promotion.Code),
+	)
+	middleware.RespondWithJSON(w, http.StatusCreated, promotion)
+}
+
+// UpdatePromotion handles replacing all attributes of a promotion
+func (h *PromotionHandler) UpdatePromotion(w http.ResponseWriter, r *http.Request) {
+	promotionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid promotion ID")
+		return
+	}
+
+	var req PromotionRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	promotion, err := h.promotionService.UpdatePromotion(r.Context(), promotionID, req.toInput())
+	if err != nil {
+		h.respondWithPromotionError(w, err, "failed to update promotion")
+		return
+	}
+
+	h.logger.Info("Promotion updated", zap.String("promotion_id", promotion.ID.String()))
+	middleware.RespondWithJSON(w, http.StatusOK, promotion)
+}
+
+// DeletePromotion handles removing a promotion
+func (h *PromotionHandler) DeletePromotion(w http.ResponseWriter, r *http.Request) {
+	promotionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid promotion ID")
+		return
+	}
+
+	if err := h.promotionService.DeletePromotion(r.Context(), promotionID); err != nil {
+		h.respondWithPromotionError(w, err, "failed to delete promotion")
+		return
+	}
+
+	h.logger.Info("Promotion deleted", zap.String("promotion_id", promotionID.String()))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respondWithPromotionError maps promotion service errors to HTTP responses
+func (h *PromotionHandler) respondWithPromotionError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case err == repository.ErrPromotionNotFound:
+		middleware.RespondWithError(w, http.StatusNotFound, "promotion not found")
+	case err == repository.ErrPromotionCodeExists:
+		middleware.RespondWithError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrInvalidPromotion):
+		middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.Error("Promotion operation failed", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, fallback)
+	}
+}
+
+// toInput converts the request payload into a service PromotionInput
+func (req PromotionRequest) toInput() service.PromotionInput {
+	input := service.PromotionInput{
+		Code:                  req.Code,
+		Description:           req.Description,
+		Type:                  req.Type,
+		PercentOff:            req.PercentOff,
+		AmountOff:             req.AmountOff,
+		MinOrderValue:         req.MinOrderValue,
+		ProductIDs:            make([]uuid.UUID, 0, len(req.ProductIDs)),
+		CategoryIDs:           make([]uuid.UUID, 0, len(req.CategoryIDs)),
+		StartsAt:              req.StartsAt,
+		EndsAt:                req.EndsAt,
+		MaxRedemptions:        req.MaxRedemptions,
+		MaxRedemptionsPerUser: req.MaxRedemptionsPerUser,
+		Active:                req.Active == nil || *req.Active,
+	}
+
+	// Already validated as UUIDs by the request tags
+	for _, id := range req.ProductIDs {
+		input.ProductIDs = append(input.ProductIDs, uuid.MustParse(id))
+	}
+	for _, id := range req.CategoryIDs {
+		input.CategoryIDs = append(input.CategoryIDs, uuid.MustParse(id))
+	}
+
+	return input
+}
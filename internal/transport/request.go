@@ -0,0 +1,70 @@
+package transport
+
+import (
+	"net/http"
+
+	"pizza-must/internal/auth"
+	"pizza-must/internal/middleware"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// decodeAndValidate decodes the JSON request body into v and validates it.
+// It writes a 400 response and returns false when decoding or validation fails.
+func decodeAndValidate(w http.ResponseWriter, r *http.Request, v interface{}, logger *zap.Logger) bool {
+	if err := middleware.DecodeAndValidate(r, v); err != nil {
+		logger.Debug("Request validation failed", zap.String("path", r.URL.Path), zap.Error(err))
+
+		if validationErrors := middleware.FormatValidationErrors(err); len(validationErrors) > 0 {
+			middleware.RespondWithValidationErrors(w, validationErrors)
+			return false
+		}
+
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid request body")
+		return false
+	}
+	return true
+}
+
+// currentUserID returns the authenticated user's ID from the request context.
+// It writes a 401 response and returns false when no principal is present.
+func currentUserID(w http.ResponseWriter, r *http.Request, logger *zap.Logger) (uuid.UUID, bool) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		logger.Error("Principal not found in context")
+		middleware.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+// currentPrincipal returns the authenticated caller from the request context.
+// It writes a 401 response and returns false when no principal is present.
+func currentPrincipal(w http.ResponseWriter, r *http.Request, logger *zap.Logger) (auth.Principal, bool) {
+	principal, ok := middleware.GetPrincipal(r.Context())
+	if !ok {
+		logger.Error("Principal not found in context")
+		middleware.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return auth.Principal{}, false
+	}
+
+	return principal, true
+}
+
+// parseStoreID reads the optional store_id query parameter.
+// It writes a 400 response and returns false when the parameter is not a valid ID.
+func parseStoreID(w http.ResponseWriter, r *http.Request) (*uuid.UUID, bool) {
+	raw := r.URL.Query().Get("store_id")
+	if raw == "" {
+		return nil, true
+	}
+
+	storeID, err := uuid.Parse(raw)
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid store ID")
+		return nil, false
+	}
+	return &storeID, true
+}
@@ -0,0 +1,144 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/middleware"
+	"pizza-must/internal/repository"
+	"pizza-must/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// UserRolesResponse represents the roles a user holds and the stores they are granted for
+type UserRolesResponse struct {
+	UserID string                   `json:"user_id"`
+	Roles  []*domain.RoleAssignment `json:"roles"`
+}
+
+// RoleHandler handles HTTP requests for role management
+type RoleHandler struct {
+	roleService service.RoleService
+	logger      *zap.Logger
+}
+
+// NewRoleHandler creates a new RoleHandler
+func NewRoleHandler(roleService service.RoleService, logger *zap.Logger) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+		logger:      logger,
+	}
+}
+
+// RegisterRoutes registers role management routes restricted to users who may manage roles.
+// Assigning and revoking apply at the store given by ?store_id=, or at every store without it.
+func (h *RoleHandler) RegisterRoutes(r chi.Router, authMiddleware func(http.Handler) http.Handler) {
+	r.Route("/api/admin/roles", func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.Use(middleware.RequirePermission(domain.PermissionRolesManage, h.logger))
+
+		r.Get("/", h.ListRoles)
+		r.Get("/users/{userID}", h.ListUserRoles)
+		r.Put("/{role}/users/{userID}", h.AssignRole)
+		r.Delete("/{role}/users/{userID}", h.RevokeRole)
+	})
+}
+
+// ListRoles handles listing every role with its permissions
+func (h *RoleHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.roleService.ListRoles(r.Context())
+	if err != nil {
+		h.respondWithRoleError(w, err, "failed to list roles")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, roles)
+}
+
+// ListUserRoles handles listing the roles a user holds
+func (h *RoleHandler) ListUserRoles(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	roles, err := h.roleService.ListUserRoles(r.Context(), userID)
+	if err != nil {
+		h.respondWithRoleError(w, err, "failed to list user roles")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, UserRolesResponse{UserID: userID.String(), Roles: roles})
+}
+
+// AssignRole handles granting a role to a user
+func (h *RoleHandler) AssignRole(w http.ResponseWriter, r *http.Request) {
+	h.changeRole(w, r, "granted", h.roleService.AssignRole)
+}
+
+// RevokeRole handles taking a role away from a user
+func (h *RoleHandler) RevokeRole(w http.ResponseWriter, r *http.Request) {
+	h.changeRole(w, r, "revoked", h.roleService.RevokeRole)
+}
+
+// changeRole applies a role assignment change and responds with the user's roles
+func (h *RoleHandler) changeRole(w http.ResponseWriter, r *http.Request, action string, change func(ctx context.Context, userID uuid.UUID, roleName string, storeID *uuid.UUID) ([]*domain.RoleAssignment, error)) {
+	actorID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+	roleName := chi.URLParam(r, "role")
+
+	storeID, ok := parseStoreID(w, r)
+	if !ok {
+		return
+	}
+
+	roles, err := change(r.Context(), userID, roleName, storeID)
+	if err != nil {
+		h.respondWithRoleError(w, err, "failed to change roles")
+		return
+	}
+
+	scope := "all"
+	if storeID != nil {
+		scope = storeID.String()
+	}
+	h.logger.Info("Role "+action,
+		zap.String("role", roleName),
+		zap.String("store_id", scope),
+		zap.String("user_id", userID.String()),
+		zap.String("actor_id", actorID.String()),
+	)
+	middleware.RespondWithJSON(w, http.StatusOK, UserRolesResponse{UserID: userID.String(), Roles: roles})
+}
+
+// respondWithRoleError maps role service errors to HTTP responses
+func (h *RoleHandler) respondWithRoleError(w http.ResponseWriter, err error, message string) {
+	switch err {
+	case repository.ErrRoleNotFound:
+		middleware.RespondWithError(w, http.StatusNotFound, "role not found")
+	case repository.ErrUserNotFound:
+		middleware.RespondWithError(w, http.StatusNotFound, "user not found")
+	case repository.ErrStoreNotFound:
+		middleware.RespondWithError(w, http.StatusNotFound, "store not found")
+	case repository.ErrRoleAssignmentNotFound:
+		middleware.RespondWithError(w, http.StatusNotFound, err.Error())
+	case service.ErrLastAdmin:
+		middleware.RespondWithError(w, http.StatusConflict, err.Error())
+	default:
+		h.logger.Error("Role operation failed", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,214 @@
+package transport
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/middleware"
+	"pizza-must/internal/repository"
+	"pizza-must/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// mockRoleRepository keys assignments by user, role and store; uuid.Nil stands for every store
+type mockRoleRepository struct {
+	roles       map[string]*domain.Role
+	assignments map[uuid.UUID]map[uuid.UUID]map[uuid.UUID]bool
+}
+
+func newMockRoleRepository() *mockRoleRepository {
+	m := &mockRoleRepository{
+		roles:       make(map[string]*domain.Role),
+		assignments: make(map[uuid.UUID]map[uuid.UUID]map[uuid.UUID]bool),
+	}
+	for _, name := range []string{domain.RoleAdmin, domain.RoleKitchen} {
+		m.roles[name] = &domain.Role{ID: uuid.New(), Name: name, Permissions: []string{}, CreatedAt: time.Now()}
+	}
+	m.roles[domain.RoleAdmin].Permissions = []string{domain.PermissionRolesManage}
+	m.roles[domain.RoleKitchen].Permissions = []string{domain.PermissionOrdersRead, domain.PermissionOrdersTransition}
+	return m
+}
+
+func (m *mockRoleRepository) List(ctx context.Context) ([]*domain.Role, error) {
+	roles := make([]*domain.Role, 0, len(m.roles))
+	for _, role := range m.roles {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+func (m *mockRoleRepository) FindByName(ctx context.Context, name string) (*domain.Role, error) {
+	role, exists := m.roles[name]
+	if !exists {
+		return nil, repository.ErrRoleNotFound
+	}
+	return role, nil
+}
+
+func (m *mockRoleRepository) ListNamesForUser(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	names := []string{}
+	for _, role := range m.roles {
+		if len(m.assignments[userID][role.ID]) > 0 {
+			names = append(names, role.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (m *mockRoleRepository) ListAssignmentsForUser(ctx context.Context, userID uuid.UUID) ([]*domain.RoleAssignment, error) {
+	assignments := []*domain.RoleAssignment{}
+	for _, role := range m.roles {
+		for key := range m.assignments[userID][role.ID] {
+			assignment := &domain.RoleAssignment{Role: role.Name}
+			if key != uuid.Nil {
+				storeID := key
+				assignment.StoreID = &storeID
+			}
+			assignments = append(assignments, assignment)
+		}
+	}
+	sort.Slice(assignments, func(i, j int) bool { return assignments[i].Role < assignments[j].Role })
+	return assignments, nil
+}
+
+func (m *mockRoleRepository) ListPermissionsForUser(ctx context.Context, userID uuid.UUID) ([]*domain.PermissionGrant, error) {
+	grants := []*domain.PermissionGrant{}
+	assignments, _ := m.ListAssignmentsForUser(ctx, userID)
+	for _, assignment := range assignments {
+		for _, permission := range m.roles[assignment.Role].Permissions {
+			grants = append(grants, &domain.PermissionGrant{Permission: permission, StoreID: assignment.StoreID})
+		}
+	}
+	return grants, nil
+}
+
+func (m *mockRoleRepository) ListHoldersForUpdateTx(ctx context.Context, tx *sql.Tx, roleID uuid.UUID) ([]uuid.UUID, error) {
+	holders := []uuid.UUID{}
+	for userID, roles := range m.assignments {
+		if roles[roleID][uuid.Nil] {
+			holders = append(holders, userID)
+		}
+	}
+	return holders, nil
+}
+
+func (m *mockRoleRepository) Assign(ctx context.Context, userID, roleID uuid.UUID, storeID *uuid.UUID, at time.Time) error {
+	if m.assignments[userID] == nil {
+		m.assignments[userID] = make(map[uuid.UUID]map[uuid.UUID]bool)
+	}
+	if m.assignments[userID][roleID] == nil {
+		m.assignments[userID][roleID] = make(map[uuid.UUID]bool)
+	}
+	m.assignments[userID][roleID][mockStoreKey(storeID)] = true
+	return nil
+}
+
+func (m *mockRoleRepository) RevokeTx(ctx context.Context, tx *sql.Tx, userID, roleID uuid.UUID, storeID *uuid.UUID) error {
+	if !m.assignments[userID][roleID][mockStoreKey(storeID)] {
+		return repository.ErrRoleAssignmentNotFound
+	}
+	delete(m.assignments[userID][roleID], mockStoreKey(storeID))
+	return nil
+}
+
+// mockStoreKey maps an optional store to its assignment key
+func mockStoreKey(storeID *uuid.UUID) uuid.UUID {
+	if storeID == nil {
+		return uuid.Nil
+	}
+	return *storeID
+}
+
+func TestRoleHandler_StatusCodes(t *testing.T) {
+	userRepo := newMockUserRepository()
+	roleRepo := newMockRoleRepository()
+	admin := &domain.User{ID: uuid.New(), Email: "admin@example.com"}
+	cook := &domain.User{ID: uuid.New(), Email: "cook@example.com"}
+	_ = userRepo.Create(context.Background(), admin)
+	_ = userRepo.Create(context.Background(), cook)
+	_ = roleRepo.Assign(context.Background(), admin.ID, roleRepo.roles[domain.RoleAdmin].ID, nil, time.Now())
+
+	tokens := newTestTokenManager(t)
+	router := chi.NewRouter()
+	NewRoleHandler(service.NewRoleService(&mockTransactor{}, roleRepo, userRepo), zap.NewNop()).
+		RegisterRoutes(router, middleware.AuthMiddleware(tokens, zap.NewNop()))
+
+	managerToken := newTestAccessToken(t, tokens, domain.PermissionRolesManage)
+	staffToken := newTestAccessToken(t, tokens, domain.PermissionOrdersTransition)
+	storeID := uuid.New()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"list without permission", http.MethodGet, "/api/admin/roles", staffToken, http.StatusForbidden},
+		{"assign without permission", http.MethodPut, "/api/admin/roles/kitchen/users/" + cook.ID.String(), staffToken, http.StatusForbidden},
+		{"list roles", http.MethodGet, "/api/admin/roles", managerToken, http.StatusOK},
+		{"assign role", http.MethodPut, "/api/admin/roles/kitchen/users/" + cook.ID.String(), managerToken, http.StatusOK},
+		{"assign again", http.MethodPut, "/api/admin/roles/kitchen/users/" + cook.ID.String(), managerToken, http.StatusOK},
+		{"list user roles", http.MethodGet, "/api/admin/roles/users/" + cook.ID.String(), managerToken, http.StatusOK},
+		{"unknown role", http.MethodPut, "/api/admin/roles/owner/users/" + cook.ID.String(), managerToken, http.StatusNotFound},
+		{"unknown user", http.MethodPut, "/api/admin/roles/kitchen/users/" + uuid.New().String(), managerToken, http.StatusNotFound},
+		{"invalid user ID", http.MethodPut, "/api/admin/roles/kitchen/users/not-a-uuid", managerToken, http.StatusBadRequest},
+		{"assign role at a store", http.MethodPut, "/api/admin/roles/kitchen/users/" + cook.ID.String() + "?store_id=" + storeID.String(), managerToken, http.StatusOK},
+		{"invalid store ID", http.MethodPut, "/api/admin/roles/kitchen/users/" + cook.ID.String() + "?store_id=downtown", managerToken, http.StatusBadRequest},
+		{"revoke role at a store", http.MethodDelete, "/api/admin/roles/kitchen/users/" + cook.ID.String() + "?store_id=" + storeID.String(), managerToken, http.StatusOK},
+		{"revoke role", http.MethodDelete, "/api/admin/roles/kitchen/users/" + cook.ID.String(), managerToken, http.StatusOK},
+		{"revoke role not held", http.MethodDelete, "/api/admin/roles/kitchen/users/" + cook.ID.String(), managerToken, http.StatusNotFound},
+		{"revoke last admin", http.MethodDelete, "/api/admin/roles/admin/users/" + admin.ID.String(), managerToken, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAssignRole_ReturnsUserRoles(t *testing.T) {
+	userRepo := newMockUserRepository()
+	roleRepo := newMockRoleRepository()
+	cook := &domain.User{ID: uuid.New(), Email: "cook@example.com"}
+	_ = userRepo.Create(context.Background(), cook)
+
+	tokens := newTestTokenManager(t)
+	router := chi.NewRouter()
+	NewRoleHandler(service.NewRoleService(&mockTransactor{}, roleRepo, userRepo), zap.NewNop()).
+		RegisterRoutes(router, middleware.AuthMiddleware(tokens, zap.NewNop()))
+
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/roles/kitchen/users/"+cook.ID.String(), nil)
+	req.Header.Set("Authorization", "Bearer "+newTestAccessToken(t, tokens, domain.PermissionRolesManage))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response UserRolesResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Could not decode response: %v", err)
+	}
+	if response.UserID != cook.ID.String() || len(response.Roles) != 1 || response.Roles[0].Role != domain.RoleKitchen {
+		t.Errorf("expected the cook to hold the kitchen role, got %+v", response)
+	}
+}
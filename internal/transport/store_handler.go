@@ -0,0 +1,525 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/middleware"
+	"pizza-must/internal/repository"
+	"pizza-must/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// StoreRequest represents the create and full-update store payload.
+// Omitting active creates an active store; omitted slot settings take their defaults.
+type StoreRequest struct {
+	Name         string  `json:"name" validate:"required,max=100"`
+	AddressLine1 string  `json:"address_line1" validate:"required,max=255"`
+	AddressLine2 string  `json:"address_line2" validate:"max=255"`
+	City         string  `json:"city" validate:"required,max=100"`
+	PostalCode   string  `json:"postal_code" validate:"required,max=20"`
+	Country      string  `json:"country" validate:"required,len=2,alpha"`
+	Latitude     float64 `json:"latitude" validate:"gte=-90,lte=90"`
+	Longitude    float64 `json:"longitude" validate:"gte=-180,lte=180"`
+	Timezone     string  `json:"timezone" validate:"required,max=64"`
+	Phone        string  `json:"phone" validate:"max=32"`
+	Active       *bool   `json:"active"`
+	SlotMinutes  *int    `json:"slot_minutes" validate:"omitempty,gte=5,lte=240"`
+	SlotCapacity *int    `json:"slot_capacity" validate:"omitempty,gte=1"`
+	LeadMinutes  *int    `json:"lead_minutes" validate:"omitempty,gte=0"`
+}
+
+// OpeningHoursRequest represents the payload replacing every opening period of a store
+type OpeningHoursRequest struct {
+	Hours []OpeningHoursEntry `json:"hours" validate:"dive"`
+}
+
+// OpeningHoursEntry represents one opening period in local store time
+type OpeningHoursEntry struct {
+	Weekday int    `json:"weekday" validate:"gte=0,lte=6"`
+	Opens   string `json:"opens" validate:"required"`
+	Closes  string `json:"closes" validate:"required"`
+}
+
+// HoursExceptionsRequest represents the payload replacing every opening hours exception of a store
+type HoursExceptionsRequest struct {
+	Exceptions []HoursExceptionEntry `json:"exceptions" validate:"dive"`
+}
+
+// HoursExceptionEntry represents the hours of one local store date.
+// Omitting both times closes the store for the day.
+type HoursExceptionEntry struct {
+	Date   string `json:"date" validate:"required"`
+	Opens  string `json:"opens"`
+	Closes string `json:"closes"`
+	Note   string `json:"note" validate:"max=255"`
+}
+
+// DeliveryZoneRequest represents the create and full-update delivery zone payload. A zone
+// sets either radius_meters, around the store, or a polygon of at least three points.
+// Omitting active creates an active zone.
+type DeliveryZoneRequest struct {
+	Name          string            `json:"name" validate:"required,max=100"`
+	RadiusMeters  int               `json:"radius_meters" validate:"gte=0"`
+	Polygon       []domain.GeoPoint `json:"polygon"`
+	MinOrderValue domain.Money      `json:"min_order_value"`
+	DeliveryFee   domain.Money      `json:"delivery_fee"`
+	Active        *bool             `json:"active"`
+}
+
+// StoreProductRequest represents the payload listing a product at a store.
+// Omitting price sells the product at its catalog price.
+type StoreProductRequest struct {
+	Available bool          `json:"available"`
+	Stock     int           `json:"stock" validate:"gte=0"`
+	Price     *domain.Money `json:"price"`
+}
+
+// StoreHandler handles HTTP requests for stores and their menus
+type StoreHandler struct {
+	storeService service.StoreService
+	logger       *zap.Logger
+}
+
+// NewStoreHandler creates a new StoreHandler
+func NewStoreHandler(storeService service.StoreService, logger *zap.Logger) *StoreHandler {
+	return &StoreHandler{
+		storeService: storeService,
+		logger:       logger,
+	}
+}
+
+// RegisterRoutes registers the public store routes
+func (h *StoreHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/api/stores", func(r chi.Router) {
+		r.Get("/", h.ListStores)
+		r.Get("/{id}", h.GetStore)
+		r.Get("/{id}/slots", h.ListSlots)
+	})
+}
+
+// RegisterAdminRoutes registers store management routes. Creating a store requires
+// stores:manage at every store; the other routes check the permission at the store they touch.
+func (h *StoreHandler) RegisterAdminRoutes(r chi.Router, authMiddleware func(http.Handler) http.Handler) {
+	r.Route("/api/admin/stores", func(r chi.Router) {
+		r.Use(authMiddleware)
+
+		r.With(middleware.RequireStorePermission(domain.PermissionStoresManage, h.logger)).Get("/", h.ListAllStores)
+		r.With(middleware.RequirePermission(domain.PermissionStoresManage, h.logger)).Post("/", h.CreateStore)
+		r.Put("/{id}", h.UpdateStore)
+		r.Put("/{id}/hours", h.SetOpeningHours)
+		r.Put("/{id}/exceptions", h.SetHoursExceptions)
+		r.Get("/{id}/products", h.ListStoreProducts)
+		r.Put("/{id}/products/{productID}", h.SetStoreProduct)
+		r.Get("/{id}/zones", h.ListDeliveryZones)
+		r.Post("/{id}/zones", h.CreateDeliveryZone)
+		r.Put("/{id}/zones/{zoneID}", h.UpdateDeliveryZone)
+		r.Delete("/{id}/zones/{zoneID}", h.DeleteDeliveryZone)
+	})
+}
+
+// ListStores handles listing the stores taking orders
+func (h *StoreHandler) ListStores(w http.ResponseWriter, r *http.Request) {
+	h.listStores(w, r, false)
+}
+
+// ListAllStores handles listing every store, including inactive ones
+func (h *StoreHandler) ListAllStores(w http.ResponseWriter, r *http.Request) {
+	h.listStores(w, r, true)
+}
+
+func (h *StoreHandler) listStores(w http.ResponseWriter, r *http.Request, includeInactive bool) {
+	stores, err := h.storeService.ListStores(r.Context(), includeInactive)
+	if err != nil {
+		h.respondWithStoreError(w, err, "failed to list stores")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, stores)
+}
+
+// GetStore handles retrieving a single store with its opening hours
+func (h *StoreHandler) GetStore(w http.ResponseWriter, r *http.Request) {
+	storeID, ok := storeIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	store, err := h.storeService.GetStore(r.Context(), storeID)
+	if err != nil {
+		h.respondWithStoreError(w, err, "failed to get store")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, store)
+}
+
+// ListSlots handles listing the time slots orders can be scheduled into at a store.
+// The date query parameter is a local store date and defaults to today.
+func (h *StoreHandler) ListSlots(w http.ResponseWriter, r *http.Request) {
+	storeID, ok := storeIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	slots, err := h.storeService.ListSlots(r.Context(), storeID, r.URL.Query().Get("date"))
+	if err != nil {
+		h.respondWithStoreError(w, err, "failed to list time slots")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, slots)
+}
+
+// CreateStore handles adding a store
+func (h *StoreHandler) CreateStore(w http.ResponseWriter, r *http.Request) {
+	var req StoreRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	store, err := h.storeService.CreateStore(r.Context(), req.toInput())
+	if err != nil {
+		h.respondWithStoreError(w, err, "failed to create store")
+		return
+	}
+
+	h.logger.Info("Store created", zap.String("store_id", store.ID.String()))
+	middleware.RespondWithJSON(w, http.StatusCreated, store)
+}
+
+// UpdateStore handles replacing the details of a store
+func (h *StoreHandler) UpdateStore(w http.ResponseWriter, r *http.Request) {
+	storeID, ok := h.authorizeStore(w, r, domain.PermissionStoresManage)
+	if !ok {
+		return
+	}
+
+	var req StoreRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	store, err := h.storeService.UpdateStore(r.Context(), storeID, req.toInput())
+	if err != nil {
+		h.respondWithStoreError(w, err, "failed to update store")
+		return
+	}
+
+	h.logger.Info("Store updated", zap.String("store_id", store.ID.String()))
+	middleware.RespondWithJSON(w, http.StatusOK, store)
+}
+
+// SetOpeningHours handles replacing every opening period of a store
+func (h *StoreHandler) SetOpeningHours(w http.ResponseWriter, r *http.Request) {
+	storeID, ok := h.authorizeStore(w, r, domain.PermissionStoresManage)
+	if !ok {
+		return
+	}
+
+	var req OpeningHoursRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	hours := make([]*domain.OpeningHours, 0, len(req.Hours))
+	for _, entry := range req.Hours {
+		hours = append(hours, &domain.OpeningHours{Weekday: entry.Weekday, Opens: entry.Opens, Closes: entry.Closes})
+	}
+
+	store, err := h.storeService.SetOpeningHours(r.Context(), storeID, hours)
+	if err != nil {
+		h.respondWithStoreError(w, err, "failed to set opening hours")
+		return
+	}
+
+	h.logger.Info("Store opening hours replaced",
+		zap.String("store_id", storeID.String()),
+		zap.Int("periods", len(store.OpeningHours)),
+	)
+	middleware.RespondWithJSON(w, http.StatusOK, store)
+}
+
+// SetHoursExceptions handles replacing every opening hours exception of a store
+func (h *StoreHandler) SetHoursExceptions(w http.ResponseWriter, r *http.Request) {
+	storeID, ok := h.authorizeStore(w, r, domain.PermissionStoresManage)
+	if !ok {
+		return
+	}
+
+	var req HoursExceptionsRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	exceptions := make([]*domain.HoursException, 0, len(req.Exceptions))
+	for _, entry := range req.Exceptions {
+		exceptions = append(exceptions, &domain.HoursException{Date: entry.Date, Opens: entry.Opens, Closes: entry.Closes, Note: entry.Note})
+	}
+
+	store, err := h.storeService.SetHoursExceptions(r.Context(), storeID, exceptions)
+	if err != nil {
+		h.respondWithStoreError(w, err, "failed to set opening hours exceptions")
+		return
+	}
+
+	h.logger.Info("Store opening hours exceptions replaced",
+		zap.String("store_id", storeID.String()),
+		zap.Int("exceptions", len(store.HoursExceptions)),
+	)
+	middleware.RespondWithJSON(w, http.StatusOK, store)
+}
+
+// ListStoreProducts handles listing a store's listings of catalog products
+func (h *StoreHandler) ListStoreProducts(w http.ResponseWriter, r *http.Request) {
+	storeID, ok := h.authorizeStore(w, r, domain.PermissionProductsWrite)
+	if !ok {
+		return
+	}
+
+	products, err := h.storeService.ListStoreProducts(r.Context(), storeID)
+	if err != nil {
+		h.respondWithStoreError(w, err, "failed to list store products")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, products)
+}
+
+// SetStoreProduct handles listing a product at a store or changing its stock and price there
+func (h *StoreHandler) SetStoreProduct(w http.ResponseWriter, r *http.Request) {
+	storeID, ok := h.authorizeStore(w, r, domain.PermissionProductsWrite)
+	if !ok {
+		return
+	}
+
+	productID, err := uuid.Parse(chi.URLParam(r, "productID"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	var req StoreProductRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	product, err := h.storeService.SetStoreProduct(r.Context(), storeID, productID, service.StoreProductInput{
+		Available: req.Available,
+		Stock:     req.Stock,
+		Price:     req.Price,
+	})
+	if err != nil {
+		h.respondWithStoreError(w, err, "failed to set store product")
+		return
+	}
+
+	h.logger.Info("Store product updated",
+		zap.String("store_id", storeID.String()),
+		zap.String("product_id", productID.String()),
+		zap.Bool("available", product.Available),
+		zap.Int("stock", product.Stock),
+	)
+	middleware.RespondWithJSON(w, http.StatusOK, product)
+}
+
+// ListDeliveryZones handles listing a store's delivery zones
+func (h *StoreHandler) ListDeliveryZones(w http.ResponseWriter, r *http.Request) {
+	storeID, ok := h.authorizeStore(w, r, domain.PermissionStoresManage)
+	if !ok {
+		return
+	}
+
+	zones, err := h.storeService.ListDeliveryZones(r.Context(), storeID)
+	if err != nil {
+		h.respondWithStoreError(w, err, "failed to list delivery zones")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, zones)
+}
+
+// CreateDeliveryZone handles adding a delivery zone to a store
+func (h *StoreHandler) CreateDeliveryZone(w http.ResponseWriter, r *http.Request) {
+	storeID, ok := h.authorizeStore(w, r, domain.PermissionStoresManage)
+	if !ok {
+		return
+	}
+
+	var req DeliveryZoneRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	zone, err := h.storeService.CreateDeliveryZone(r.Context(), storeID, req.toInput())
+	if err != nil {
+		h.respondWithStoreError(w, err, "failed to create delivery zone")
+		return
+	}
+
+	h.logger.Info("Delivery zone created",
+		zap.String("store_id", storeID.String()),
+		zap.String("zone_id", zone.ID.String()),
+	)
+	middleware.RespondWithJSON(w, http.StatusCreated, zone)
+}
+
+// UpdateDeliveryZone handles replacing one of a store's delivery zones
+func (h *StoreHandler) UpdateDeliveryZone(w http.ResponseWriter, r *http.Request) {
+	storeID, zoneID, ok := h.authorizeZone(w, r)
+	if !ok {
+		return
+	}
+
+	var req DeliveryZoneRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	zone, err := h.storeService.UpdateDeliveryZone(r.Context(), storeID, zoneID, req.toInput())
+	if err != nil {
+		h.respondWithStoreError(w, err, "failed to update delivery zone")
+		return
+	}
+
+	h.logger.Info("Delivery zone updated",
+		zap.String("store_id", storeID.String()),
+		zap.String("zone_id", zone.ID.String()),
+	)
+	middleware.RespondWithJSON(w, http.StatusOK, zone)
+}
+
+// DeleteDeliveryZone handles removing one of a store's delivery zones
+func (h *StoreHandler) DeleteDeliveryZone(w http.ResponseWriter, r *http.Request) {
+	storeID, zoneID, ok := h.authorizeZone(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.storeService.DeleteDeliveryZone(r.Context(), storeID, zoneID); err != nil {
+		h.respondWithStoreError(w, err, "failed to delete delivery zone")
+		return
+	}
+
+	h.logger.Info("Delivery zone deleted",
+		zap.String("store_id", storeID.String()),
+		zap.String("zone_id", zoneID.String()),
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorizeZone checks the user manages the store and parses the zone ID path parameter.
+// It writes the error response and returns false when either check fails.
+func (h *StoreHandler) authorizeZone(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	storeID, ok := h.authorizeStore(w, r, domain.PermissionStoresManage)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	zoneID, err := uuid.Parse(chi.URLParam(r, "zoneID"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid delivery zone ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return storeID, zoneID, true
+}
+
+// authorizeStore parses the store ID path parameter and checks the user holds permission there.
+// It writes the error response and returns false when either check fails.
+func (h *StoreHandler) authorizeStore(w http.ResponseWriter, r *http.Request, permission string) (uuid.UUID, bool) {
+	principal, ok := currentPrincipal(w, r, h.logger)
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	storeID, ok := storeIDParam(w, r)
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	if !principal.HasStorePermission(storeID, permission) {
+		h.logger.Warn("User lacks permission at store",
+			zap.String("user_id", principal.UserID.String()),
+			zap.String("store_id", storeID.String()),
+			zap.String("permission", permission),
+		)
+		middleware.RespondWithError(w, http.StatusForbidden, "insufficient permissions")
+		return uuid.Nil, false
+	}
+
+	return storeID, true
+}
+
+// storeIDParam parses the store ID path parameter, writing a 400 response when it is invalid
+func storeIDParam(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	storeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid store ID")
+		return uuid.Nil, false
+	}
+	return storeID, true
+}
+
+// respondWithStoreError maps store service errors to HTTP responses
+func (h *StoreHandler) respondWithStoreError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case err == repository.ErrStoreNotFound:
+		middleware.RespondWithError(w, http.StatusNotFound, "store not found")
+	case err == repository.ErrProductNotFound:
+		middleware.RespondWithError(w, http.StatusNotFound, "product not found")
+	case err == repository.ErrDeliveryZoneNotFound:
+		middleware.RespondWithError(w, http.StatusNotFound, "delivery zone not found")
+	case errors.Is(err, service.ErrInvalidStore), errors.Is(err, service.ErrInvalidSchedule), errors.Is(err, service.ErrInvalidZone):
+		middleware.RespondWithError(w, http.StatusBadRequest, err.Error())
+	case err == service.ErrStoreInactive:
+		middleware.RespondWithError(w, http.StatusConflict, err.Error())
+	default:
+		h.logger.Error("Store operation failed", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, fallback)
+	}
+}
+
+// toInput converts the request payload into a service StoreInput
+func (req StoreRequest) toInput() service.StoreInput {
+	return service.StoreInput{
+		Name:         req.Name,
+		AddressLine1: req.AddressLine1,
+		AddressLine2: req.AddressLine2,
+		City:         req.City,
+		PostalCode:   req.PostalCode,
+		Country:      req.Country,
+		Latitude:     req.Latitude,
+		Longitude:    req.Longitude,
+		Timezone:     req.Timezone,
+		Phone:        req.Phone,
+		Active:       req.Active == nil || *req.Active,
+		SlotMinutes:  intOrDefault(req.SlotMinutes, domain.DefaultSlotMinutes),
+		SlotCapacity: intOrDefault(req.SlotCapacity, domain.DefaultSlotCapacity),
+		LeadMinutes:  intOrDefault(req.LeadMinutes, domain.DefaultLeadMinutes),
+	}
+}
+
+// toInput converts the request payload into a service DeliveryZoneInput
+func (req DeliveryZoneRequest) toInput() service.DeliveryZoneInput {
+	return service.DeliveryZoneInput{
+		Name:          req.Name,
+		RadiusMeters:  req.RadiusMeters,
+		Polygon:       req.Polygon,
+		MinOrderValue: req.MinOrderValue,
+		DeliveryFee:   req.DeliveryFee,
+		Active:        req.Active == nil || *req.Active,
+	}
+}
+
+// intOrDefault returns *value, or fallback when the field was omitted
+func intOrDefault(value *int, fallback int) int {
+	if value == nil {
+		return fallback
+	}
+	return *value
+}
@@ -0,0 +1,258 @@
+package transport
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pizza-must/internal/auth"
+	"pizza-must/internal/domain"
+	"pizza-must/internal/middleware"
+	"pizza-must/internal/repository"
+	"pizza-must/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type mockStoreRepository struct {
+	stores   map[uuid.UUID]*domain.Store
+	products map[uuid.UUID]map[uuid.UUID]*domain.StoreProduct
+	zones    map[uuid.UUID]*domain.DeliveryZone
+}
+
+func newMockStoreRepository() *mockStoreRepository {
+	return &mockStoreRepository{
+		stores:   make(map[uuid.UUID]*domain.Store),
+		products: make(map[uuid.UUID]map[uuid.UUID]*domain.StoreProduct),
+		zones:    make(map[uuid.UUID]*domain.DeliveryZone),
+	}
+}
+
+func (m *mockStoreRepository) Create(ctx context.Context, store *domain.Store) error {
+	m.stores[store.ID] = store
+	return nil
+}
+
+func (m *mockStoreRepository) Update(ctx context.Context, store *domain.Store) error {
+	if _, exists := m.stores[store.ID]; !exists {
+		return repository.ErrStoreNotFound
+	}
+	m.stores[store.ID] = store
+	return nil
+}
+
+func (m *mockStoreRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Store, error) {
+	store, exists := m.stores[id]
+	if !exists {
+		return nil, repository.ErrStoreNotFound
+	}
+	copied := *store
+	return &copied, nil
+}
+
+func (m *mockStoreRepository) List(ctx context.Context, activeOnly bool) ([]*domain.Store, error) {
+	stores := []*domain.Store{}
+	for _, store := range m.stores {
+		if !activeOnly || store.Active {
+			stores = append(stores, store)
+		}
+	}
+	return stores, nil
+}
+
+func (m *mockStoreRepository) ReplaceOpeningHoursTx(ctx context.Context, tx *sql.Tx, storeID uuid.UUID, hours []*domain.OpeningHours) error {
+	store, exists := m.stores[storeID]
+	if !exists {
+		return repository.ErrStoreNotFound
+	}
+	store.OpeningHours = hours
+	return nil
+}
+
+func (m *mockStoreRepository) ListProducts(ctx context.Context, storeID uuid.UUID) ([]*domain.StoreProduct, error) {
+	products := []*domain.StoreProduct{}
+	for _, product := range m.products[storeID] {
+		products = append(products, product)
+	}
+	return products, nil
+}
+
+func (m *mockStoreRepository) SaveProduct(ctx context.Context, product *domain.StoreProduct) error {
+	if m.products[product.StoreID] == nil {
+		m.products[product.StoreID] = make(map[uuid.UUID]*domain.StoreProduct)
+	}
+	m.products[product.StoreID][product.ProductID] = product
+	return nil
+}
+
+func (m *mockStoreRepository) ReplaceHoursExceptionsTx(ctx context.Context, tx *sql.Tx, storeID uuid.UUID, exceptions []*domain.HoursException) error {
+	store, exists := m.stores[storeID]
+	if !exists {
+		return repository.ErrStoreNotFound
+	}
+	store.HoursExceptions = exceptions
+	return nil
+}
+
+func (m *mockStoreRepository) LockTx(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	if _, exists := m.stores[id]; !exists {
+		return repository.ErrStoreNotFound
+	}
+	return nil
+}
+
+func (m *mockStoreRepository) ListDeliveryZones(ctx context.Context, storeID uuid.UUID) ([]*domain.DeliveryZone, error) {
+	zones := []*domain.DeliveryZone{}
+	for _, zone := range m.zones {
+		if zone.StoreID == storeID {
+			zones = append(zones, zone)
+		}
+	}
+	return zones, nil
+}
+
+func (m *mockStoreRepository) CreateDeliveryZone(ctx context.Context, zone *domain.DeliveryZone) error {
+	if _, exists := m.stores[zone.StoreID]; !exists {
+		return repository.ErrStoreNotFound
+	}
+	m.zones[zone.ID] = zone
+	return nil
+}
+
+func (m *mockStoreRepository) UpdateDeliveryZone(ctx context.Context, zone *domain.DeliveryZone) error {
+	existing, exists := m.zones[zone.ID]
+	if !exists || existing.StoreID != zone.StoreID {
+		return repository.ErrDeliveryZoneNotFound
+	}
+	m.zones[zone.ID] = zone
+	return nil
+}
+
+func (m *mockStoreRepository) DeleteDeliveryZone(ctx context.Context, storeID, id uuid.UUID) error {
+	existing, exists := m.zones[id]
+	if !exists || existing.StoreID != storeID {
+		return repository.ErrDeliveryZoneNotFound
+	}
+	delete(m.zones, id)
+	return nil
+}
+
+// mockScheduledOrderRepository reports no orders booked into time slots; the embedded nil
+// repository panics if any other order method is called
+type mockScheduledOrderRepository struct {
+	repository.OrderRepository
+}
+
+func (m *mockScheduledOrderRepository) ListScheduledTimes(ctx context.Context, storeID uuid.UUID, from, to time.Time) ([]time.Time, error) {
+	return []time.Time{}, nil
+}
+
+// newTestStoreAccessToken signs a token granting permissions at a single store
+func newTestStoreAccessToken(t *testing.T, tokens auth.TokenManager, storeID uuid.UUID, permissions ...string) string {
+	t.Helper()
+	signed, err := tokens.Issue(uuid.New(), auth.Access{
+		Permissions:      []string{},
+		StorePermissions: map[uuid.UUID][]string{storeID: permissions},
+	}, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func TestStoreHandler_StatusCodes(t *testing.T) {
+	storeRepo := newMockStoreRepository()
+	productRepo := newMockProductRepository()
+	downtown := &domain.Store{ID: uuid.New(), Name: "Downtown", Timezone: "UTC", Active: true, OpeningHours: []*domain.OpeningHours{}}
+	harbour := &domain.Store{ID: uuid.New(), Name: "Harbour", Timezone: "UTC", Active: false, OpeningHours: []*domain.OpeningHours{}}
+	_ = storeRepo.Create(context.Background(), downtown)
+	_ = storeRepo.Create(context.Background(), harbour)
+	margherita := &domain.Product{ID: uuid.New(), Name: "Margherita"}
+	_ = productRepo.Create(context.Background(), margherita)
+
+	tokens := newTestTokenManager(t)
+	router := chi.NewRouter()
+	handler := NewStoreHandler(service.NewStoreService(&mockTransactor{}, storeRepo, productRepo, &mockScheduledOrderRepository{}), zap.NewNop())
+	handler.RegisterRoutes(router)
+	handler.RegisterAdminRoutes(router, middleware.AuthMiddleware(tokens, zap.NewNop()))
+
+	adminToken := newTestAccessToken(t, tokens, domain.PermissionStoresManage, domain.PermissionProductsWrite)
+	managerToken := newTestStoreAccessToken(t, tokens, downtown.ID, domain.PermissionStoresManage, domain.PermissionProductsWrite)
+	cookToken := newTestStoreAccessToken(t, tokens, downtown.ID, domain.PermissionOrdersTransition)
+
+	newStore := `{"name":"Uptown","address_line1":"1 Main St","city":"Springfield","postal_code":"12345","country":"us","latitude":40.7,"longitude":-74,"timezone":"America/New_York"}`
+	downtownPath := "/api/admin/stores/" + downtown.ID.String()
+	harbourPath := "/api/admin/stores/" + harbour.ID.String()
+	productPath := downtownPath + "/products/" + margherita.ID.String()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		body   string
+		want   int
+	}{
+		{"list public stores", http.MethodGet, "/api/stores", "", "", http.StatusOK},
+		{"get store", http.MethodGet, "/api/stores/" + downtown.ID.String(), "", "", http.StatusOK},
+		{"get unknown store", http.MethodGet, "/api/stores/" + uuid.New().String(), "", "", http.StatusNotFound},
+		{"get invalid store ID", http.MethodGet, "/api/stores/not-a-uuid", "", "", http.StatusBadRequest},
+		{"admin list without token", http.MethodGet, "/api/admin/stores", "", "", http.StatusUnauthorized},
+		{"admin list without permission", http.MethodGet, "/api/admin/stores", cookToken, "", http.StatusForbidden},
+		{"admin list as store manager", http.MethodGet, "/api/admin/stores", managerToken, "", http.StatusOK},
+		{"create as store manager", http.MethodPost, "/api/admin/stores", managerToken, newStore, http.StatusForbidden},
+		{"create store", http.MethodPost, "/api/admin/stores", adminToken, newStore, http.StatusCreated},
+		{"create with unknown timezone", http.MethodPost, "/api/admin/stores", adminToken, strings.Replace(newStore, "America/New_York", "Mars/Olympus", 1), http.StatusBadRequest},
+		{"update own store", http.MethodPut, downtownPath, managerToken, newStore, http.StatusOK},
+		{"update another store", http.MethodPut, harbourPath, managerToken, newStore, http.StatusForbidden},
+		{"update unknown store", http.MethodPut, "/api/admin/stores/" + uuid.New().String(), adminToken, newStore, http.StatusNotFound},
+		{"set opening hours", http.MethodPut, downtownPath + "/hours", managerToken, `{"hours":[{"weekday":1,"opens":"11:00","closes":"23:00"}]}`, http.StatusOK},
+		{"set overlapping hours", http.MethodPut, downtownPath + "/hours", managerToken, `{"hours":[{"weekday":1,"opens":"11:00","closes":"15:00"},{"weekday":1,"opens":"14:00","closes":"22:00"}]}`, http.StatusBadRequest},
+		{"set hours as cook", http.MethodPut, downtownPath + "/hours", cookToken, `{"hours":[]}`, http.StatusForbidden},
+		{"list store products", http.MethodGet, downtownPath + "/products", managerToken, "", http.StatusOK},
+		{"stock a product", http.MethodPut, productPath, managerToken, `{"available":true,"stock":12,"price":"9.50"}`, http.StatusOK},
+		{"stock a product at another store", http.MethodPut, harbourPath + "/products/" + margherita.ID.String(), managerToken, `{"available":true,"stock":12}`, http.StatusForbidden},
+		{"stock an unknown product", http.MethodPut, downtownPath + "/products/" + uuid.New().String(), managerToken, `{"available":true,"stock":1}`, http.StatusNotFound},
+		{"negative stock", http.MethodPut, productPath, managerToken, `{"available":true,"stock":-1}`, http.StatusBadRequest},
+		{"create with short slots", http.MethodPost, "/api/admin/stores", adminToken, strings.Replace(newStore, `"timezone"`, `"slot_minutes":2,"timezone"`, 1), http.StatusBadRequest},
+		{"set holiday closure", http.MethodPut, downtownPath + "/exceptions", managerToken, `{"exceptions":[{"date":"2024-12-25","note":"Christmas"}]}`, http.StatusOK},
+		{"set exception without closing time", http.MethodPut, downtownPath + "/exceptions", managerToken, `{"exceptions":[{"date":"2024-12-24","opens":"10:00"}]}`, http.StatusBadRequest},
+		{"set exceptions as cook", http.MethodPut, downtownPath + "/exceptions", cookToken, `{"exceptions":[]}`, http.StatusForbidden},
+		{"list slots", http.MethodGet, "/api/stores/" + downtown.ID.String() + "/slots", "", "", http.StatusOK},
+		{"list slots on an invalid date", http.MethodGet, "/api/stores/" + downtown.ID.String() + "/slots?date=soon", "", "", http.StatusBadRequest},
+		{"list slots of an inactive store", http.MethodGet, "/api/stores/" + harbour.ID.String() + "/slots", "", "", http.StatusConflict},
+		{"list slots of an unknown store", http.MethodGet, "/api/stores/" + uuid.New().String() + "/slots", "", "", http.StatusNotFound},
+		{"create radius zone", http.MethodPost, downtownPath + "/zones", managerToken, `{"name":"Centre","radius_meters":3000,"min_order_value":"15.00","delivery_fee":"2.50"}`, http.StatusCreated},
+		{"create polygon zone", http.MethodPost, downtownPath + "/zones", managerToken, `{"name":"Harbour side","polygon":[{"latitude":41.9,"longitude":12.4},{"latitude":41.95,"longitude":12.5},{"latitude":41.85,"longitude":12.55}]}`, http.StatusCreated},
+		{"create zone with radius and polygon", http.MethodPost, downtownPath + "/zones", managerToken, `{"name":"Both","radius_meters":3000,"polygon":[{"latitude":41.9,"longitude":12.4},{"latitude":41.95,"longitude":12.5},{"latitude":41.85,"longitude":12.55}]}`, http.StatusBadRequest},
+		{"create zone without area", http.MethodPost, downtownPath + "/zones", managerToken, `{"name":"Nowhere"}`, http.StatusBadRequest},
+		{"create zone at another store", http.MethodPost, harbourPath + "/zones", managerToken, `{"name":"Centre","radius_meters":3000}`, http.StatusForbidden},
+		{"list zones", http.MethodGet, downtownPath + "/zones", managerToken, "", http.StatusOK},
+		{"update unknown zone", http.MethodPut, downtownPath + "/zones/" + uuid.New().String(), managerToken, `{"name":"Centre","radius_meters":3000}`, http.StatusNotFound},
+		{"delete unknown zone", http.MethodDelete, downtownPath + "/zones/" + uuid.New().String(), managerToken, "", http.StatusNotFound},
+		{"list zones as cook", http.MethodGet, downtownPath + "/zones", cookToken, "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
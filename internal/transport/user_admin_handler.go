@@ -0,0 +1,85 @@
+package transport
+
+import (
+	"net/http"
+
+	"pizza-must/internal/domain"
+	"pizza-must/internal/middleware"
+	"pizza-must/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// RegisterAdminRoutes registers user management routes restricted to users who may
+// manage accounts
+func (h *UserHandler) RegisterAdminRoutes(r chi.Router, authMiddleware func(http.Handler) http.Handler) {
+	r.Route("/api/admin/users", func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.Use(middleware.RequirePermission(domain.PermissionUsersManage, h.logger))
+
+		r.Delete("/{id}/sessions", h.RevokeUserSessions)
+		r.Post("/{id}/unlock", h.UnlockUser)
+	})
+}
+
+// RevokeUserSessions handles signing a user out of every session
+func (h *UserHandler) RevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	if err := h.userService.RevokeAllSessions(r.Context(), userID); err != nil {
+		if err == repository.ErrUserNotFound {
+			middleware.RespondWithError(w, http.StatusNotFound, "user not found")
+			return
+		}
+		h.logger.Error("Failed to revoke user sessions", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, "failed to revoke sessions")
+		return
+	}
+
+	h.logger.Info("User sessions revoked by admin",
+		zap.String("user_id", userID.String()),
+		zap.String("admin_id", actorID.String()),
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnlockUser handles lifting a login lockout on a user's account
+func (h *UserHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	if err := h.userService.UnlockAccount(r.Context(), userID); err != nil {
+		if err == repository.ErrUserNotFound {
+			middleware.RespondWithError(w, http.StatusNotFound, "user not found")
+			return
+		}
+		h.logger.Error("Failed to unlock user", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, "failed to unlock user")
+		return
+	}
+
+	h.logger.Info("User unlocked by admin",
+		zap.String("user_id", userID.String()),
+		zap.String("admin_id", actorID.String()),
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
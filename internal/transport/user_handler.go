@@ -2,9 +2,14 @@ package transport
 
 import (
 	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
+	"strconv"
 
+	"pizza-must/internal/domain"
 	"pizza-must/internal/middleware"
+	"pizza-must/internal/repository"
 	"pizza-must/internal/service"
 
 	"github.com/go-chi/chi/v5"
@@ -31,6 +36,24 @@ type RefreshRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
+// UpdateProfileRequest represents the profile update payload; omitted fields are left as they are
+type UpdateProfileRequest struct {
+	FirstName *string `json:"first_name" validate:"omitempty,min=1,max=100"`
+	LastName  *string `json:"last_name" validate:"omitempty,min=1,max=100"`
+	Phone     *string `json:"phone" validate:"omitempty,e164"`
+}
+
+// ChangeEmailRequest represents the payload moving the current user to a new email address
+type ChangeEmailRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	Email           string `json:"email" validate:"required,email"`
+}
+
+// DeleteAccountRequest represents the payload confirming the deletion of the current user's account
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
 // LoginResponse represents the login response
 type LoginResponse struct {
 	AccessToken  string      `json:"access_token"`
@@ -38,31 +61,50 @@ type LoginResponse struct {
 	User         UserProfile `json:"user"`
 }
 
-// RefreshResponse represents the token refresh response
+// RefreshResponse represents the token refresh response.
+// The presented refresh token is no longer valid; clients must store the returned one.
 type RefreshResponse struct {
-	AccessToken string `json:"access_token"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
 }
 
 // UserProfile represents user profile data
 type UserProfile struct {
-	ID        string `json:"id"`
-	Email     string `json:"email"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	Role      string `json:"role"`
+	ID            string   `json:"id"`
+	Email         string   `json:"email"`
+	FirstName     string   `json:"first_name"`
+	LastName      string   `json:"last_name"`
+	Phone         string   `json:"phone"`
+	Roles         []string `json:"roles"`
+	EmailVerified bool     `json:"email_verified"`
+}
+
+// newUserProfile converts a user into the profile returned to clients
+func newUserProfile(user *domain.User) UserProfile {
+	return UserProfile{
+		ID:            user.ID.String(),
+		Email:         user.Email,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		Phone:         user.Phone,
+		Roles:         user.Roles,
+		EmailVerified: user.IsEmailVerified(),
+	}
 }
 
 // UserHandler handles HTTP requests for user operations
 type UserHandler struct {
-	userService service.UserService
-	logger      *zap.Logger
+	userService              service.UserService
+	emailVerificationService service.EmailVerificationService
+	logger                   *zap.Logger
 }
 
 // NewUserHandler creates a new UserHandler
-func NewUserHandler(userService service.UserService, logger *zap.Logger) *UserHandler {
+func NewUserHandler(userService service.UserService, emailVerificationService service.EmailVerificationService, logger *zap.Logger) *UserHandler {
 	return &UserHandler{
-		userService: userService,
-		logger:      logger,
+		userService:              userService,
+		emailVerificationService: emailVerificationService,
+		logger:                   logger,
 	}
 }
 
@@ -78,7 +120,13 @@ func (h *UserHandler) RegisterRoutes(r chi.Router, authMiddleware func(http.Hand
 		r.Group(func(r chi.Router) {
 			r.Use(authMiddleware)
 			r.Post("/logout", h.Logout)
+			r.Post("/logout-all", h.LogoutAll)
 			r.Get("/profile", h.GetProfile)
+			r.Patch("/profile", h.UpdateProfile)
+			r.Post("/email", h.ChangeEmail)
+			r.Delete("/me", h.DeleteAccount)
+			r.Get("/sessions", h.ListSessions)
+			r.Delete("/sessions/{id}", h.RevokeSession)
 		})
 	})
 }
@@ -117,15 +165,17 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Return user profile
-	profile := UserProfile{
-		ID:        user.ID.String(),
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Role:      user.Role,
+	// A failed verification email does not fail the registration; the user can ask for another
+	if err := h.emailVerificationService.SendVerification(r.Context(), user); err != nil {
+		h.logger.Error("Failed to send verification email",
+			zap.String("user_id", user.ID.String()),
+			zap.Error(err),
+		)
 	}
 
+	// Return user profile
+	profile := newUserProfile(user)
+
 	h.logger.Info("User registered successfully", zap.String("user_id", user.ID.String()))
 	middleware.RespondWithJSON(w, http.StatusCreated, profile)
 }
@@ -149,7 +199,7 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Call service
-	accessToken, refreshToken, user, err := h.userService.Login(r.Context(), req.Email, req.Password)
+	accessToken, refreshToken, user, err := h.userService.Login(r.Context(), req.Email, req.Password, clientInfo(r))
 	if err != nil {
 		h.logger.Debug("Login failed", zap.Error(err))
 
@@ -159,6 +209,14 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			h.logger.Warn("Login throttled", zap.String("ip_address", clientInfo(r).IPAddress))
+			w.Header().Set("Retry-After", strconv.Itoa(int(throttled.RetryAfter.Seconds())+1))
+			middleware.RespondWithError(w, http.StatusTooManyRequests, "too many failed login attempts, try again later")
+			return
+		}
+
 		middleware.RespondWithError(w, http.StatusInternalServerError, "failed to login")
 		return
 	}
@@ -167,13 +225,7 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	response := LoginResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
-		User: UserProfile{
-			ID:        user.ID.String(),
-			Email:     user.Email,
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			Role:      user.Role,
-		},
+		User:         newUserProfile(user),
 	}
 
 	h.logger.Info("User logged in successfully", zap.String("user_id", user.ID.String()))
@@ -221,8 +273,20 @@ func (h *UserHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Call service
-	newAccessToken, err := h.userService.RefreshToken(r.Context(), req.RefreshToken)
+	newAccessToken, newRefreshToken, err := h.userService.RefreshToken(r.Context(), req.RefreshToken, clientInfo(r))
 	if err != nil {
+		// Reuse of a rotated token means it has leaked; its whole family has been revoked
+		var reuseErr *service.TokenReuseError
+		if errors.As(err, &reuseErr) {
+			h.logger.Warn("Security event: refresh token reuse detected, token family revoked",
+				zap.String("user_id", reuseErr.UserID.String()),
+				zap.String("family_id", reuseErr.FamilyID.String()),
+				zap.String("remote_addr", r.RemoteAddr),
+			)
+			middleware.RespondWithError(w, http.StatusUnauthorized, "invalid refresh token")
+			return
+		}
+
 		h.logger.Debug("Token refresh failed", zap.Error(err))
 
 		// Check for specific errors
@@ -239,9 +303,10 @@ func (h *UserHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Return new access token
+	// Return new token pair
 	response := RefreshResponse{
-		AccessToken: newAccessToken,
+		AccessToken:  newAccessToken,
+		RefreshToken: newRefreshToken,
 	}
 
 	h.logger.Info("Token refreshed successfully")
@@ -251,37 +316,208 @@ func (h *UserHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 // GetProfile handles getting user profile
 func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	// Extract user ID from context (set by auth middleware)
-	userIDStr, ok := middleware.GetUserID(r.Context())
+	userID, ok := currentUserID(w, r, h.logger)
 	if !ok {
-		h.logger.Error("User ID not found in context")
-		middleware.RespondWithError(w, http.StatusUnauthorized, "unauthorized")
-		return
-	}
-
-	// Parse user ID
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		h.logger.Error("Invalid user ID format", zap.Error(err))
-		middleware.RespondWithError(w, http.StatusBadRequest, "invalid user ID")
 		return
 	}
 
 	// Get user from service
 	user, err := h.userService.GetUserByID(r.Context(), userID)
 	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			middleware.RespondWithError(w, http.StatusNotFound, "user not found")
+			return
+		}
 		h.logger.Error("Failed to get user profile", zap.Error(err))
 		middleware.RespondWithError(w, http.StatusInternalServerError, "failed to get user profile")
 		return
 	}
 
 	// Return user profile
-	profile := UserProfile{
-		ID:        user.ID.String(),
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Role:      user.Role,
-	}
+	profile := newUserProfile(user)
 
 	middleware.RespondWithJSON(w, http.StatusOK, profile)
 }
+
+// UpdateProfile handles changing the current user's names and phone number
+func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	var req UpdateProfileRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(r.Context(), userID, service.ProfileUpdate{
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Phone:     req.Phone,
+	})
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			middleware.RespondWithError(w, http.StatusNotFound, "user not found")
+			return
+		}
+		h.logger.Error("Failed to update user profile", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, "failed to update user profile")
+		return
+	}
+
+	h.logger.Info("User profile updated", zap.String("user_id", userID.String()))
+	middleware.RespondWithJSON(w, http.StatusOK, newUserProfile(user))
+}
+
+// ChangeEmail handles moving the current user to a new email address and sends a
+// verification email to it
+func (h *UserHandler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	var req ChangeEmailRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	user, err := h.userService.ChangeEmail(r.Context(), userID, req.CurrentPassword, req.Email)
+	if err != nil {
+		switch err {
+		case service.ErrIncorrectPassword:
+			middleware.RespondWithError(w, http.StatusForbidden, err.Error())
+		case repository.ErrUserAlreadyExists:
+			middleware.RespondWithError(w, http.StatusConflict, "user with this email already exists")
+		case repository.ErrUserNotFound:
+			middleware.RespondWithError(w, http.StatusNotFound, "user not found")
+		default:
+			h.logger.Error("Failed to change email", zap.Error(err))
+			middleware.RespondWithError(w, http.StatusInternalServerError, "failed to change email")
+		}
+		return
+	}
+
+	// As on registration, a failed verification email is only logged; the user can ask for another
+	if !user.IsEmailVerified() {
+		if err := h.emailVerificationService.SendVerification(r.Context(), user); err != nil {
+			h.logger.Error("Failed to send verification email",
+				zap.String("user_id", user.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	h.logger.Info("User email changed", zap.String("user_id", userID.String()))
+	middleware.RespondWithJSON(w, http.StatusOK, newUserProfile(user))
+}
+
+// DeleteAccount handles deleting the current user's account. The user's orders are kept
+// but no longer carry any personal data.
+func (h *UserHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	var req DeleteAccountRequest
+	if !decodeAndValidate(w, r, &req, h.logger) {
+		return
+	}
+
+	if err := h.userService.DeleteAccount(r.Context(), userID, req.Password); err != nil {
+		switch err {
+		case service.ErrIncorrectPassword:
+			middleware.RespondWithError(w, http.StatusForbidden, err.Error())
+		case repository.ErrUserNotFound:
+			middleware.RespondWithError(w, http.StatusNotFound, "user not found")
+		case service.ErrLastAdmin:
+			middleware.RespondWithError(w, http.StatusConflict, err.Error())
+		default:
+			h.logger.Error("Failed to delete account", zap.Error(err))
+			middleware.RespondWithError(w, http.StatusInternalServerError, "failed to delete account")
+		}
+		return
+	}
+
+	h.logger.Info("User account deleted", zap.String("user_id", userID.String()))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListSessions handles listing the devices the current user is signed in on
+func (h *UserHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.userService.ListSessions(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list sessions", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, "failed to list sessions")
+		return
+	}
+
+	middleware.RespondWithJSON(w, http.StatusOK, sessions)
+}
+
+// RevokeSession handles signing the current user out of one session
+func (h *UserHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.RespondWithError(w, http.StatusBadRequest, "invalid session ID")
+		return
+	}
+
+	if err := h.userService.RevokeSession(r.Context(), userID, sessionID); err != nil {
+		if err == repository.ErrSessionNotFound {
+			middleware.RespondWithError(w, http.StatusNotFound, "session not found")
+			return
+		}
+		h.logger.Error("Failed to revoke session", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, "failed to revoke session")
+		return
+	}
+
+	h.logger.Info("Session revoked",
+		zap.String("user_id", userID.String()),
+		zap.String("session_id", sessionID.String()),
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LogoutAll handles signing the current user out of every session
+func (h *UserHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r, h.logger)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.RevokeAllSessions(r.Context(), userID); err != nil {
+		h.logger.Error("Logout from all sessions failed", zap.Error(err))
+		middleware.RespondWithError(w, http.StatusInternalServerError, "failed to logout")
+		return
+	}
+
+	h.logger.Info("User logged out of all sessions", zap.String("user_id", userID.String()))
+	middleware.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "logged out of all sessions"})
+}
+
+// clientInfo describes the client making the request. RemoteAddr is the socket address, or
+// the client IP reported by a trusted proxy when the router runs the RealIP middleware.
+func clientInfo(r *http.Request) service.ClientInfo {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	return service.ClientInfo{
+		UserAgent: r.UserAgent(),
+		IPAddress: ip,
+	}
+}
@@ -3,10 +3,12 @@ package transport
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"pizza-must/internal/domain"
 	"pizza-must/internal/repository"
@@ -40,7 +42,7 @@ func (m *mockUserRepository) Create(ctx context.Context, user *domain.User) erro
 
 func (m *mockUserRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
 	user, exists := m.users[email]
-	if !exists {
+	if !exists || user.DeletedAt != nil {
 		return nil, repository.ErrUserNotFound
 	}
 	return user, nil
@@ -48,13 +50,66 @@ func (m *mockUserRepository) FindByEmail(ctx context.Context, email string) (*do
 
 func (m *mockUserRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
 	for _, user := range m.users {
-		if user.ID == id {
+		if user.ID == id && user.DeletedAt == nil {
 			return user, nil
 		}
 	}
 	return nil, repository.ErrUserNotFound
 }
 
+func (m *mockUserRepository) Update(ctx context.Context, user *domain.User) error {
+	for email, existing := range m.users {
+		if existing.ID != user.ID {
+			continue
+		}
+		if other, taken := m.users[user.Email]; taken && other.ID != user.ID {
+			return repository.ErrUserAlreadyExists
+		}
+		delete(m.users, email)
+		m.users[user.Email] = user
+		return nil
+	}
+	return repository.ErrUserNotFound
+}
+
+func (m *mockUserRepository) AnonymizeTx(ctx context.Context, tx *sql.Tx, id uuid.UUID, deletedAt time.Time) error {
+	for email, user := range m.users {
+		if user.ID != id || user.DeletedAt != nil {
+			continue
+		}
+		delete(m.users, email)
+		anonymized := *user
+		anonymized.Email = "deleted-" + id.String() + "@deleted.invalid"
+		anonymized.PasswordHash, anonymized.FirstName, anonymized.LastName, anonymized.Phone = "", "", "", ""
+		anonymized.DeletedAt = &deletedAt
+		m.users[anonymized.Email] = &anonymized
+		return nil
+	}
+	return repository.ErrUserNotFound
+}
+
+func (m *mockUserRepository) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	for _, user := range m.users {
+		if user.ID == id {
+			user.PasswordHash = passwordHash
+			return nil
+		}
+	}
+	return repository.ErrUserNotFound
+}
+
+func (m *mockUserRepository) MarkEmailVerified(ctx context.Context, id uuid.UUID, verifiedAt time.Time) error {
+	for _, user := range m.users {
+		if user.ID == id {
+			if user.EmailVerifiedAt == nil {
+				user.EmailVerifiedAt = &verifiedAt
+			}
+			return nil
+		}
+	}
+	return repository.ErrUserNotFound
+}
+
 type mockRefreshTokenRepository struct {
 	tokens map[string]*domain.RefreshToken
 }
@@ -66,12 +121,12 @@ func newMockRefreshTokenRepository() *mockRefreshTokenRepository {
 }
 
 func (m *mockRefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
-	m.tokens[token.Token] = token
+	m.tokens[token.TokenHash] = token
 	return nil
 }
 
-func (m *mockRefreshTokenRepository) FindByToken(ctx context.Context, token string) (*domain.RefreshToken, error) {
-	refreshToken, exists := m.tokens[token]
+func (m *mockRefreshTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	refreshToken, exists := m.tokens[tokenHash]
 	if !exists {
 		return nil, repository.ErrRefreshTokenNotFound
 	}
@@ -81,8 +136,8 @@ func (m *mockRefreshTokenRepository) FindByToken(ctx context.Context, token stri
 	return refreshToken, nil
 }
 
-func (m *mockRefreshTokenRepository) Revoke(ctx context.Context, token string) error {
-	refreshToken, exists := m.tokens[token]
+func (m *mockRefreshTokenRepository) Revoke(ctx context.Context, tokenHash string) error {
+	refreshToken, exists := m.tokens[tokenHash]
 	if !exists {
 		return repository.ErrRefreshTokenNotFound
 	}
@@ -90,6 +145,150 @@ func (m *mockRefreshTokenRepository) Revoke(ctx context.Context, token string) e
 	return nil
 }
 
+func (m *mockRefreshTokenRepository) FindByTokenHashIncludingRevoked(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	refreshToken, exists := m.tokens[tokenHash]
+	if !exists {
+		return nil, repository.ErrRefreshTokenNotFound
+	}
+	return refreshToken, nil
+}
+
+func (m *mockRefreshTokenRepository) Rotate(ctx context.Context, current *domain.RefreshToken, replacement *domain.RefreshToken) error {
+	if current.Revoked {
+		return repository.ErrRefreshTokenRevoked
+	}
+	current.Revoked = true
+	current.ReplacedBy = &replacement.ID
+	m.tokens[replacement.TokenHash] = replacement
+	return nil
+}
+
+func (m *mockRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	for _, refreshToken := range m.tokens {
+		if refreshToken.FamilyID == familyID {
+			refreshToken.Revoked = true
+		}
+	}
+	return nil
+}
+
+func (m *mockRefreshTokenRepository) ListActiveSessions(ctx context.Context, userID uuid.UUID) ([]*domain.Session, error) {
+	sessions := []*domain.Session{}
+	for _, refreshToken := range m.tokens {
+		if refreshToken.UserID != userID || refreshToken.Revoked || time.Now().After(refreshToken.ExpiresAt) {
+			continue
+		}
+		sessions = append(sessions, &domain.Session{
+			ID:         refreshToken.FamilyID,
+			UserAgent:  refreshToken.UserAgent,
+			IPAddress:  refreshToken.IPAddress,
+			CreatedAt:  refreshToken.SessionStartedAt,
+			LastUsedAt: refreshToken.LastUsedAt,
+			ExpiresAt:  refreshToken.ExpiresAt,
+		})
+	}
+	return sessions, nil
+}
+
+func (m *mockRefreshTokenRepository) RevokeSession(ctx context.Context, userID, familyID uuid.UUID) error {
+	revoked := false
+	for _, refreshToken := range m.tokens {
+		if refreshToken.UserID == userID && refreshToken.FamilyID == familyID && !refreshToken.Revoked {
+			refreshToken.Revoked = true
+			revoked = true
+		}
+	}
+	if !revoked {
+		return repository.ErrSessionNotFound
+	}
+	return nil
+}
+
+func (m *mockRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	for _, refreshToken := range m.tokens {
+		if refreshToken.UserID == userID {
+			refreshToken.Revoked = true
+		}
+	}
+	return nil
+}
+
+type mockLoginThrottleRepository struct {
+	throttles map[string]*domain.LoginThrottle
+}
+
+func newMockLoginThrottleRepository() *mockLoginThrottleRepository {
+	return &mockLoginThrottleRepository{
+		throttles: make(map[string]*domain.LoginThrottle),
+	}
+}
+
+func (m *mockLoginThrottleRepository) Find(ctx context.Context, kind, subject string) (*domain.LoginThrottle, error) {
+	throttle, exists := m.throttles[kind+":"+subject]
+	if !exists {
+		return nil, repository.ErrLoginThrottleNotFound
+	}
+	return throttle, nil
+}
+
+func (m *mockLoginThrottleRepository) RecordFailure(ctx context.Context, kind, subject string, now, windowStart time.Time) (*domain.LoginThrottle, error) {
+	throttle, exists := m.throttles[kind+":"+subject]
+	if !exists {
+		throttle = &domain.LoginThrottle{Kind: kind, Subject: subject}
+		m.throttles[kind+":"+subject] = throttle
+	}
+	if throttle.LastFailureAt.Before(windowStart) {
+		throttle.Failures = 0
+	}
+	throttle.Failures++
+	throttle.LastFailureAt = now
+	return throttle, nil
+}
+
+func (m *mockLoginThrottleRepository) Lock(ctx context.Context, kind, subject string, until time.Time) error {
+	throttle, exists := m.throttles[kind+":"+subject]
+	if !exists {
+		return repository.ErrLoginThrottleNotFound
+	}
+	throttle.LockedUntil = &until
+	return nil
+}
+
+func (m *mockLoginThrottleRepository) Clear(ctx context.Context, kind, subject string) error {
+	delete(m.throttles, kind+":"+subject)
+	return nil
+}
+
+// newTestLoginThrottle returns a throttle with the default policy and no recorded failures
+func newTestLoginThrottle() service.LoginThrottle {
+	return service.NewLoginThrottle(newMockLoginThrottleRepository(), service.DefaultLoginThrottlePolicy())
+}
+
+// stubEmailVerificationService records the users it is asked to verify and returns the
+// configured errors
+type stubEmailVerificationService struct {
+	sentTo      []uuid.UUID
+	resendErr   error
+	checkoutErr error
+}
+
+func (s *stubEmailVerificationService) SendVerification(ctx context.Context, user *domain.User) error {
+	s.sentTo = append(s.sentTo, user.ID)
+	return nil
+}
+
+func (s *stubEmailVerificationService) ResendVerification(ctx context.Context, userID uuid.UUID) error {
+	return s.resendErr
+}
+
+func (s *stubEmailVerificationService) VerifyEmail(ctx context.Context, token string) error {
+	return service.ErrInvalidVerificationToken
+}
+
+func (s *stubEmailVerificationService) CheckCanCheckout(ctx context.Context, userID uuid.UUID) error {
+	return s.checkoutErr
+}
+
 // Feature: ordering-platform, Property 3: Invalid registration data is rejected
 // Validates: Requirements 1.5
 func TestProperty_InvalidRegistrationDataIsRejected(t *testing.T) {
@@ -100,9 +299,9 @@ func TestProperty_InvalidRegistrationDataIsRejected(t *testing.T) {
 			// Setup
 			userRepo := newMockUserRepository()
 			refreshTokenRepo := newMockRefreshTokenRepository()
-			userService := service.NewUserService(userRepo, refreshTokenRepo, "test-secret")
+			userService := service.NewUserService(&mockTransactor{}, userRepo, newMockRoleRepository(), refreshTokenRepo, newTestTokenManager(t), service.DefaultTokenPolicy(), newTestLoginThrottle())
 			logger, _ := zap.NewDevelopment()
-			handler := NewUserHandler(userService, logger)
+			handler := NewUserHandler(userService, &stubEmailVerificationService{}, logger)
 
 			var reqBody RegisterRequest
 
@@ -187,9 +386,9 @@ func TestProperty_SuccessfulRegistrationReturnsProfileData(t *testing.T) {
 			// Setup
 			userRepo := newMockUserRepository()
 			refreshTokenRepo := newMockRefreshTokenRepository()
-			userService := service.NewUserService(userRepo, refreshTokenRepo, "test-secret")
+			userService := service.NewUserService(&mockTransactor{}, userRepo, newMockRoleRepository(), refreshTokenRepo, newTestTokenManager(t), service.DefaultTokenPolicy(), newTestLoginThrottle())
 			logger, _ := zap.NewDevelopment()
-			handler := NewUserHandler(userService, logger)
+			handler := NewUserHandler(userService, &stubEmailVerificationService{}, logger)
 
 			// Create request
 			reqBody := RegisterRequest{
@@ -245,8 +444,8 @@ func TestProperty_SuccessfulRegistrationReturnsProfileData(t *testing.T) {
 				return false
 			}
 
-			if profile.Role == "" {
-				t.Logf("FAIL: Profile missing Role")
+			if profile.Roles == nil {
+				t.Logf("FAIL: Profile missing Roles")
 				return false
 			}
 
@@ -277,9 +476,9 @@ func TestProperty_ValidLoginReturnsBothTokens(t *testing.T) {
 			// Setup
 			userRepo := newMockUserRepository()
 			refreshTokenRepo := newMockRefreshTokenRepository()
-			userService := service.NewUserService(userRepo, refreshTokenRepo, "test-secret")
+			userService := service.NewUserService(&mockTransactor{}, userRepo, newMockRoleRepository(), refreshTokenRepo, newTestTokenManager(t), service.DefaultTokenPolicy(), newTestLoginThrottle())
 			logger, _ := zap.NewDevelopment()
-			handler := NewUserHandler(userService, logger)
+			handler := NewUserHandler(userService, &stubEmailVerificationService{}, logger)
 
 			// First, register the user
 			_, err := userService.Register(context.Background(), email, password, firstName, lastName)
@@ -350,7 +549,7 @@ func TestProperty_ValidLoginReturnsBothTokens(t *testing.T) {
 			}
 
 			// Verify refresh token can be used
-			newAccessToken, err := userService.RefreshToken(context.Background(), loginResp.RefreshToken)
+			newAccessToken, newRefreshToken, err := userService.RefreshToken(context.Background(), loginResp.RefreshToken, service.ClientInfo{})
 			if err != nil {
 				t.Logf("FAIL: Refresh token is not valid: %v", err)
 				return false
@@ -361,6 +560,11 @@ func TestProperty_ValidLoginReturnsBothTokens(t *testing.T) {
 				return false
 			}
 
+			if newRefreshToken == "" || newRefreshToken == loginResp.RefreshToken {
+				t.Logf("FAIL: Refresh token was not rotated")
+				return false
+			}
+
 			return true
 		},
 		gen.RegexMatch(`[a-z]{3,10}@[a-z]{3,8}\.(com|org|net)`),
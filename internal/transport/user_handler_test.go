@@ -0,0 +1,139 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pizza-must/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// newAccountTestRouter registers a user and serves the user and password routes as that user
+func newAccountTestRouter(t *testing.T) (http.Handler, *stubEmailVerificationService) {
+	t.Helper()
+	userService := service.NewUserService(&mockTransactor{}, newMockUserRepository(), newMockRoleRepository(), newMockRefreshTokenRepository(), newTestTokenManager(t), service.DefaultTokenPolicy(), newTestLoginThrottle())
+	user, err := userService.Register(context.Background(), "account@example.com", "password123", "Test", "User")
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if _, err := userService.Register(context.Background(), "taken@example.com", "password123", "Other", "User"); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	verification := &stubEmailVerificationService{}
+	router := chi.NewRouter()
+	auth := withTestPrincipal(user.ID)
+	NewUserHandler(userService, verification, zap.NewNop()).RegisterRoutes(router, auth)
+	NewPasswordHandler(userService, &stubPasswordResetService{}, zap.NewNop()).RegisterRoutes(router, auth)
+	return router, verification
+}
+
+func TestAccountEndpoints_StatusCodes(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"update names", http.MethodPatch, "/api/users/profile", `{"first_name":"Ada"}`, http.StatusOK},
+		{"update phone", http.MethodPatch, "/api/users/profile", `{"phone":"+4915112345678"}`, http.StatusOK},
+		{"empty first name", http.MethodPatch, "/api/users/profile", `{"first_name":""}`, http.StatusBadRequest},
+		{"invalid phone", http.MethodPatch, "/api/users/profile", `{"phone":"call me"}`, http.StatusBadRequest},
+		{"change password", http.MethodPost, "/api/users/password", `{"current_password":"password123","new_password":"newpassword123"}`, http.StatusOK},
+		{"wrong current password", http.MethodPost, "/api/users/password", `{"current_password":"wrongpassword","new_password":"newpassword123"}`, http.StatusForbidden},
+		{"short new password", http.MethodPost, "/api/users/password", `{"current_password":"password123","new_password":"short"}`, http.StatusBadRequest},
+		{"email in use", http.MethodPost, "/api/users/email", `{"current_password":"password123","email":"taken@example.com"}`, http.StatusConflict},
+		{"email wrong password", http.MethodPost, "/api/users/email", `{"current_password":"wrongpassword","email":"new@example.com"}`, http.StatusForbidden},
+		{"delete wrong password", http.MethodDelete, "/api/users/me", `{"password":"wrongpassword"}`, http.StatusForbidden},
+		{"delete account", http.MethodDelete, "/api/users/me", `{"password":"password123"}`, http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _ := newAccountTestRouter(t)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body)))
+			if rec.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestChangeEmail_SendsVerificationToNewAddress(t *testing.T) {
+	router, verification := newAccountTestRouter(t)
+
+	body := bytes.NewBufferString(`{"current_password":"password123","email":"new@example.com"}`)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/users/email", body))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var profile UserProfile
+	if err := json.NewDecoder(rec.Body).Decode(&profile); err != nil {
+		t.Fatalf("failed to decode profile: %v", err)
+	}
+	if profile.Email != "new@example.com" || profile.EmailVerified {
+		t.Errorf("expected unverified new@example.com, got %+v", profile)
+	}
+	if len(verification.sentTo) != 1 || verification.sentTo[0].String() != profile.ID {
+		t.Errorf("expected one verification email to the user, got %v", verification.sentTo)
+	}
+}
+
+func TestDeleteAccount_RemovesProfile(t *testing.T) {
+	router, _ := newAccountTestRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/users/me", bytes.NewBufferString(`{"password":"password123"}`)))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users/profile", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for the deleted profile, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestLogin_ThrottlesRepeatedFailures(t *testing.T) {
+	policy := service.DefaultLoginThrottlePolicy()
+	policy.Email = service.LoginLimit{FreeAttempts: 1, LockoutThreshold: 2}
+	throttle := service.NewLoginThrottle(newMockLoginThrottleRepository(), policy)
+	userService := service.NewUserService(&mockTransactor{}, newMockUserRepository(), newMockRoleRepository(), newMockRefreshTokenRepository(), newTestTokenManager(t), service.DefaultTokenPolicy(), throttle)
+	router := chi.NewRouter()
+	NewUserHandler(userService, &stubEmailVerificationService{}, zap.NewNop()).RegisterRoutes(router, withTestPrincipal(uuid.New()))
+
+	login := func(password string) *httptest.ResponseRecorder {
+		body := bytes.NewBufferString(`{"email":"nobody@example.com","password":"` + password + `"}`)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/users/login", body))
+		return rec
+	}
+
+	if rec := login("wrongpassword"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec := login("wrongpassword")
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = login("anotherpassword")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("expected a Retry-After header")
+	}
+}
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Allow cancelled and refunded orders
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS check_order_status;
+ALTER TABLE orders ADD CONSTRAINT check_order_status
+    CHECK (status IN ('pending', 'confirmed', 'shipped', 'delivered', 'cancelled', 'refunded'));
+
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL,
+    from_status VARCHAR(50),
+    to_status VARCHAR(50) NOT NULL,
+    actor_id UUID,
+    reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_order_status_history_order
+        FOREIGN KEY (order_id)
+        REFERENCES orders(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_order_status_history_actor
+        FOREIGN KEY (actor_id)
+        REFERENCES users(id)
+        ON DELETE SET NULL
+);
+
+-- Create index on order_id for fetching an order's history
+CREATE INDEX idx_order_status_history_order_id ON order_status_history(order_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_order_status_history_order_id;
+DROP TABLE IF EXISTS order_status_history;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS check_order_status;
+ALTER TABLE orders ADD CONSTRAINT check_order_status
+    CHECK (status IN ('pending', 'confirmed', 'shipped', 'delivered'));
+-- +goose StatementEnd
@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS product_option_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    min_select INTEGER NOT NULL DEFAULT 0 CHECK (min_select >= 0),
+    max_select INTEGER NOT NULL DEFAULT 1 CHECK (max_select >= 1),
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_product_option_groups_product
+        FOREIGN KEY (product_id)
+        REFERENCES products(id)
+        ON DELETE CASCADE,
+    CONSTRAINT unique_product_option_group_name
+        UNIQUE (product_id, name),
+    CONSTRAINT check_option_group_selection
+        CHECK (min_select <= max_select)
+);
+
+CREATE TABLE IF NOT EXISTS product_options (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    group_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    price_delta DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    position INTEGER NOT NULL DEFAULT 0,
+    CONSTRAINT fk_product_options_group
+        FOREIGN KEY (group_id)
+        REFERENCES product_option_groups(id)
+        ON DELETE CASCADE,
+    CONSTRAINT unique_product_option_name
+        UNIQUE (group_id, name)
+);
+
+-- Create index on product_id for loading a product's option groups
+CREATE INDEX idx_product_option_groups_product_id ON product_option_groups(product_id);
+
+-- Create index on group_id for loading a group's options
+CREATE INDEX idx_product_options_group_id ON product_options(group_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_product_options_group_id;
+DROP INDEX IF EXISTS idx_product_option_groups_product_id;
+DROP TABLE IF EXISTS product_options;
+DROP TABLE IF EXISTS product_option_groups;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Cart lines store the chosen option IDs as a sorted JSON array so the same product
+-- with different options becomes a separate line
+ALTER TABLE cart_items ADD COLUMN option_ids JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE cart_items DROP CONSTRAINT IF EXISTS unique_user_product;
+ALTER TABLE cart_items ADD CONSTRAINT unique_user_product_options
+    UNIQUE (user_id, product_id, option_ids);
+
+-- Order items snapshot the chosen options with their names and price deltas
+ALTER TABLE order_items ADD COLUMN options JSONB NOT NULL DEFAULT '[]';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE order_items DROP COLUMN IF EXISTS options;
+
+-- Collapse lines that only differed by options before restoring the old constraint
+DELETE FROM cart_items a
+    USING cart_items b
+    WHERE a.user_id = b.user_id
+      AND a.product_id = b.product_id
+      AND a.created_at > b.created_at;
+ALTER TABLE cart_items DROP CONSTRAINT IF EXISTS unique_user_product_options;
+ALTER TABLE cart_items DROP COLUMN IF EXISTS option_ids;
+ALTER TABLE cart_items ADD CONSTRAINT unique_user_product
+    UNIQUE (user_id, product_id);
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Groups such as toppings can be placed on either half of a pizza
+ALTER TABLE product_option_groups ADD COLUMN allow_halves BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Cart lines store each chosen option with its placement instead of bare option IDs
+ALTER TABLE cart_items DROP CONSTRAINT IF EXISTS unique_user_product_options;
+ALTER TABLE cart_items RENAME COLUMN option_ids TO selections;
+UPDATE cart_items SET selections = COALESCE(
+    (SELECT jsonb_agg(jsonb_build_object('option_id', option_id, 'placement', 'whole'))
+     FROM jsonb_array_elements_text(cart_items.selections) AS option_id),
+    '[]'::jsonb
+);
+ALTER TABLE cart_items ADD CONSTRAINT unique_user_product_selections
+    UNIQUE (user_id, product_id, selections);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Half placements cannot be represented by bare option IDs, so those lines are dropped
+DELETE FROM cart_items WHERE EXISTS (
+    SELECT 1 FROM jsonb_array_elements(cart_items.selections) AS s
+    WHERE s->>'placement' <> 'whole'
+);
+ALTER TABLE cart_items DROP CONSTRAINT IF EXISTS unique_user_product_selections;
+UPDATE cart_items SET selections = COALESCE(
+    (SELECT jsonb_agg(s->'option_id') FROM jsonb_array_elements(cart_items.selections) AS s),
+    '[]'::jsonb
+);
+ALTER TABLE cart_items RENAME COLUMN selections TO option_ids;
+ALTER TABLE cart_items ADD CONSTRAINT unique_user_product_options
+    UNIQUE (user_id, product_id, option_ids);
+
+ALTER TABLE product_option_groups DROP COLUMN IF EXISTS allow_halves;
+-- +goose StatementEnd
@@ -0,0 +1,90 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS promotions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(50) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    type VARCHAR(20) NOT NULL,
+    percent_off INTEGER NOT NULL DEFAULT 0 CHECK (percent_off >= 0 AND percent_off <= 100),
+    amount_off DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (amount_off >= 0),
+    min_order_value DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (min_order_value >= 0),
+    product_ids JSONB NOT NULL DEFAULT '[]'::jsonb,
+    category_ids JSONB NOT NULL DEFAULT '[]'::jsonb,
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    max_redemptions INTEGER NOT NULL DEFAULT 0 CHECK (max_redemptions >= 0),
+    max_redemptions_per_user INTEGER NOT NULL DEFAULT 0 CHECK (max_redemptions_per_user >= 0),
+    redemption_count INTEGER NOT NULL DEFAULT 0 CHECK (redemption_count >= 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT check_promotion_type
+        CHECK (type IN ('percentage', 'fixed', 'free_item', 'bogo')),
+    CONSTRAINT check_promotion_window
+        CHECK (starts_at IS NULL OR ends_at IS NULL OR starts_at < ends_at)
+);
+
+CREATE TRIGGER update_promotions_updated_at
+    BEFORE UPDATE ON promotions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Every redeemed coupon is recorded against the order it discounted
+CREATE TABLE IF NOT EXISTS promotion_redemptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    promotion_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    order_id UUID NOT NULL UNIQUE,
+    discount DECIMAL(10, 2) NOT NULL CHECK (discount >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_promotion_redemptions_promotion
+        FOREIGN KEY (promotion_id)
+        REFERENCES promotions(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_promotion_redemptions_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_promotion_redemptions_order
+        FOREIGN KEY (order_id)
+        REFERENCES orders(id)
+        ON DELETE CASCADE
+);
+
+-- Create index for counting a user's redemptions of a promotion
+CREATE INDEX idx_promotion_redemptions_promotion_user ON promotion_redemptions(promotion_id, user_id);
+
+-- A cart carries at most one coupon until checkout
+CREATE TABLE IF NOT EXISTS cart_coupons (
+    user_id UUID PRIMARY KEY,
+    promotion_id UUID NOT NULL,
+    applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_cart_coupons_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_cart_coupons_promotion
+        FOREIGN KEY (promotion_id)
+        REFERENCES promotions(id)
+        ON DELETE CASCADE
+);
+
+-- Orders keep the pre-discount subtotal and the coupon that was redeemed
+ALTER TABLE orders ADD COLUMN subtotal DECIMAL(10, 2);
+UPDATE orders SET subtotal = total;
+ALTER TABLE orders ALTER COLUMN subtotal SET NOT NULL;
+ALTER TABLE orders ADD COLUMN discount DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (discount >= 0);
+ALTER TABLE orders ADD COLUMN coupon_code VARCHAR(50);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders DROP COLUMN IF EXISTS coupon_code;
+ALTER TABLE orders DROP COLUMN IF EXISTS discount;
+ALTER TABLE orders DROP COLUMN IF EXISTS subtotal;
+DROP TABLE IF EXISTS cart_coupons;
+DROP INDEX IF EXISTS idx_promotion_redemptions_promotion_user;
+DROP TABLE IF EXISTS promotion_redemptions;
+DROP TRIGGER IF EXISTS update_promotions_updated_at ON promotions;
+DROP TABLE IF EXISTS promotions;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Every refresh token belongs to the family started by the login that issued its first
+-- ancestor; replaced_by points at the token it was rotated into
+ALTER TABLE refresh_tokens
+    ADD COLUMN family_id UUID,
+    ADD COLUMN replaced_by UUID;
+
+-- Existing tokens each start their own family
+UPDATE refresh_tokens SET family_id = id;
+
+ALTER TABLE refresh_tokens ALTER COLUMN family_id SET NOT NULL;
+
+-- Create index on family_id for revoking a whole family
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_refresh_tokens_family_id;
+ALTER TABLE refresh_tokens
+    DROP COLUMN IF EXISTS replaced_by,
+    DROP COLUMN IF EXISTS family_id;
+-- +goose StatementEnd
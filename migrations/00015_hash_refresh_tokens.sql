@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Refresh tokens are stored as the hex SHA-256 digest of the token handed to the client
+ALTER TABLE refresh_tokens ADD COLUMN token_hash CHAR(64);
+
+-- Existing tokens keep working: their digest is computed from the stored plaintext
+UPDATE refresh_tokens SET token_hash = encode(sha256(convert_to(token, 'UTF8')), 'hex');
+
+ALTER TABLE refresh_tokens ALTER COLUMN token_hash SET NOT NULL;
+ALTER TABLE refresh_tokens ADD CONSTRAINT refresh_tokens_token_hash_key UNIQUE (token_hash);
+
+DROP INDEX IF EXISTS idx_refresh_tokens_token;
+ALTER TABLE refresh_tokens DROP COLUMN token;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Plaintext tokens cannot be recovered, so every existing session is revoked
+ALTER TABLE refresh_tokens ADD COLUMN token VARCHAR(500);
+UPDATE refresh_tokens SET token = token_hash, revoked = TRUE;
+ALTER TABLE refresh_tokens ALTER COLUMN token SET NOT NULL;
+ALTER TABLE refresh_tokens ADD CONSTRAINT refresh_tokens_token_key UNIQUE (token);
+CREATE INDEX idx_refresh_tokens_token ON refresh_tokens(token);
+
+ALTER TABLE refresh_tokens DROP COLUMN token_hash;
+-- +goose StatementEnd
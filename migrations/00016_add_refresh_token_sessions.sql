@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Client details captured when a refresh token is issued at login or refresh
+ALTER TABLE refresh_tokens
+    ADD COLUMN user_agent TEXT NOT NULL DEFAULT '',
+    ADD COLUMN ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    ADD COLUMN last_used_at TIMESTAMP;
+
+UPDATE refresh_tokens SET last_used_at = created_at;
+
+ALTER TABLE refresh_tokens ALTER COLUMN last_used_at SET NOT NULL;
+ALTER TABLE refresh_tokens ALTER COLUMN last_used_at SET DEFAULT CURRENT_TIMESTAMP;
+
+-- Create index for listing a user's active sessions
+CREATE INDEX idx_refresh_tokens_user_active ON refresh_tokens(user_id, expires_at) WHERE revoked = FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_refresh_tokens_user_active;
+ALTER TABLE refresh_tokens
+    DROP COLUMN IF EXISTS last_used_at,
+    DROP COLUMN IF EXISTS ip_address,
+    DROP COLUMN IF EXISTS user_agent;
+-- +goose StatementEnd
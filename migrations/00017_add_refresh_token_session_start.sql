@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Every token of a family records when its session began, so the absolute session age
+-- can be enforced across rotations
+ALTER TABLE refresh_tokens ADD COLUMN session_started_at TIMESTAMP;
+
+UPDATE refresh_tokens t
+SET session_started_at = COALESCE(
+    (SELECT f.created_at FROM refresh_tokens f WHERE f.id = t.family_id),
+    t.created_at
+);
+
+ALTER TABLE refresh_tokens ALTER COLUMN session_started_at SET NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS session_started_at;
+-- +goose StatementEnd
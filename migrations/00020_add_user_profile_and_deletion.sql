@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN phone VARCHAR(32) NOT NULL DEFAULT '';
+
+-- Deleted accounts are anonymized rather than removed so their orders are kept
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS phone;
+-- +goose StatementEnd
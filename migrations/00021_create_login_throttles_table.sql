@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Failed login attempts per email address and per client IP. A row is keyed by what it
+-- counts, so attempts against unknown email addresses are throttled like any other.
+CREATE TABLE IF NOT EXISTS login_throttles (
+    kind VARCHAR(10) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP,
+    CONSTRAINT login_throttles_pkey PRIMARY KEY (kind, subject),
+    CONSTRAINT login_throttles_kind_check CHECK (kind IN ('email', 'ip'))
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS login_throttles;
+-- +goose StatementEnd
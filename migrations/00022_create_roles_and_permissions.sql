@@ -0,0 +1,102 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS permissions (
+    name VARCHAR(64) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS roles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(50) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT roles_name_key UNIQUE (name)
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id UUID NOT NULL,
+    permission VARCHAR(64) NOT NULL,
+    CONSTRAINT role_permissions_pkey PRIMARY KEY (role_id, permission),
+    CONSTRAINT fk_role_permissions_role
+        FOREIGN KEY (role_id)
+        REFERENCES roles(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_role_permissions_permission
+        FOREIGN KEY (permission)
+        REFERENCES permissions(name)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id UUID NOT NULL,
+    role_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT user_roles_pkey PRIMARY KEY (user_id, role_id),
+    CONSTRAINT fk_user_roles_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_user_roles_role
+        FOREIGN KEY (role_id)
+        REFERENCES roles(id)
+        ON DELETE CASCADE
+);
+
+-- Create index on role_id for counting the holders of a role
+CREATE INDEX idx_user_roles_role_id ON user_roles(role_id);
+
+INSERT INTO permissions (name, description) VALUES
+    ('orders:read', 'View every user''s orders'),
+    ('orders:transition', 'Move orders through their lifecycle'),
+    ('refunds:issue', 'Refund delivered orders'),
+    ('products:write', 'Manage products, product options and categories'),
+    ('promotions:write', 'Manage promotions'),
+    ('users:manage', 'Sign users out and lift login lockouts'),
+    ('roles:manage', 'Grant and revoke roles');
+
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Full access'),
+    ('store_manager', 'Runs a store: orders, refunds, menu and promotions'),
+    ('kitchen', 'Prepares orders'),
+    ('driver', 'Delivers orders'),
+    ('support', 'Helps customers with their orders and accounts');
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.name
+FROM roles r
+JOIN permissions p ON
+    r.name = 'admin'
+    OR (r.name = 'store_manager' AND p.name IN ('orders:read', 'orders:transition', 'refunds:issue', 'products:write', 'promotions:write'))
+    OR (r.name IN ('kitchen', 'driver') AND p.name IN ('orders:read', 'orders:transition'))
+    OR (r.name = 'support' AND p.name IN ('orders:read', 'refunds:issue', 'users:manage'));
+
+-- Existing admins keep full access; every other account is a customer without roles
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id
+FROM users u
+JOIN roles r ON r.name = 'admin'
+WHERE u.role = 'admin';
+
+DROP INDEX IF EXISTS idx_users_role;
+ALTER TABLE users DROP COLUMN role;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN role VARCHAR(50) NOT NULL DEFAULT 'user';
+CREATE INDEX idx_users_role ON users(role);
+
+UPDATE users SET role = 'admin'
+WHERE id IN (
+    SELECT ur.user_id
+    FROM user_roles ur
+    JOIN roles r ON r.id = ur.role_id
+    WHERE r.name = 'admin'
+);
+
+DROP INDEX IF EXISTS idx_user_roles_role_id;
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS permissions;
+-- +goose StatementEnd
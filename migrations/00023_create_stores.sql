@@ -0,0 +1,151 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS stores (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    address_line1 VARCHAR(255) NOT NULL,
+    address_line2 VARCHAR(255) NOT NULL DEFAULT '',
+    city VARCHAR(100) NOT NULL,
+    postal_code VARCHAR(20) NOT NULL,
+    country CHAR(2) NOT NULL,
+    latitude DOUBLE PRECISION NOT NULL CHECK (latitude BETWEEN -90 AND 90),
+    longitude DOUBLE PRECISION NOT NULL CHECK (longitude BETWEEN -180 AND 180),
+    timezone VARCHAR(64) NOT NULL,
+    phone VARCHAR(32) NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_stores_updated_at
+    BEFORE UPDATE ON stores
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Opening hours are local to the store's timezone; a period closing before it opens
+-- runs past midnight
+CREATE TABLE IF NOT EXISTS store_opening_hours (
+    store_id UUID NOT NULL,
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    opens_at TIME NOT NULL,
+    closes_at TIME NOT NULL CHECK (closes_at <> opens_at),
+    CONSTRAINT store_opening_hours_pkey PRIMARY KEY (store_id, weekday, opens_at),
+    CONSTRAINT fk_store_opening_hours_store
+        FOREIGN KEY (store_id)
+        REFERENCES stores(id)
+        ON DELETE CASCADE
+);
+
+-- A product is on a store's menu when it has an available row here. Price overrides the
+-- catalog price at that store when set.
+CREATE TABLE IF NOT EXISTS store_products (
+    store_id UUID NOT NULL,
+    product_id UUID NOT NULL,
+    available BOOLEAN NOT NULL DEFAULT TRUE,
+    stock INTEGER NOT NULL DEFAULT 0 CHECK (stock >= 0),
+    price DECIMAL(10, 2) CHECK (price >= 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT store_products_pkey PRIMARY KEY (store_id, product_id),
+    CONSTRAINT fk_store_products_store
+        FOREIGN KEY (store_id)
+        REFERENCES stores(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_store_products_product
+        FOREIGN KEY (product_id)
+        REFERENCES products(id)
+        ON DELETE CASCADE
+);
+
+-- Create index on product_id for removing a product from every store
+CREATE INDEX idx_store_products_product_id ON store_products(product_id);
+
+-- A cart is filled at one store; its lines are priced and stocked there
+CREATE TABLE IF NOT EXISTS carts (
+    user_id UUID PRIMARY KEY,
+    store_id UUID NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_carts_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_carts_store
+        FOREIGN KEY (store_id)
+        REFERENCES stores(id)
+        ON DELETE CASCADE
+);
+
+ALTER TABLE orders ADD COLUMN store_id UUID;
+
+-- Existing data belongs to the single shop that ran before stores existed
+INSERT INTO stores (name, address_line1, city, postal_code, country, latitude, longitude, timezone)
+SELECT 'Main store', '', '', '', 'XX', 0, 0, 'UTC'
+WHERE EXISTS (SELECT 1 FROM products) OR EXISTS (SELECT 1 FROM orders);
+
+INSERT INTO store_products (store_id, product_id, available, stock)
+SELECT s.id, p.id, TRUE, p.stock
+FROM products p
+CROSS JOIN stores s;
+
+INSERT INTO carts (user_id, store_id)
+SELECT DISTINCT ci.user_id, s.id
+FROM cart_items ci
+CROSS JOIN stores s;
+
+UPDATE orders SET store_id = (SELECT id FROM stores LIMIT 1);
+
+ALTER TABLE orders ALTER COLUMN store_id SET NOT NULL;
+ALTER TABLE orders ADD CONSTRAINT fk_orders_store
+    FOREIGN KEY (store_id)
+    REFERENCES stores(id)
+    ON DELETE RESTRICT;
+
+-- Create index on store_id for a store's order queue
+CREATE INDEX idx_orders_store_id ON orders(store_id);
+
+ALTER TABLE products DROP COLUMN stock;
+
+-- Roles can be granted for a single store; a NULL store grants them everywhere
+ALTER TABLE user_roles ADD COLUMN store_id UUID;
+ALTER TABLE user_roles ADD CONSTRAINT fk_user_roles_store
+    FOREIGN KEY (store_id)
+    REFERENCES stores(id)
+    ON DELETE CASCADE;
+ALTER TABLE user_roles DROP CONSTRAINT user_roles_pkey;
+ALTER TABLE user_roles ADD CONSTRAINT user_roles_assignment_key
+    UNIQUE NULLS NOT DISTINCT (user_id, role_id, store_id);
+
+INSERT INTO permissions (name, description) VALUES
+    ('stores:manage', 'Manage store details and opening hours');
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT id, 'stores:manage' FROM roles WHERE name IN ('admin', 'store_manager');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name = 'stores:manage';
+
+DELETE FROM user_roles WHERE store_id IS NOT NULL;
+ALTER TABLE user_roles DROP CONSTRAINT IF EXISTS user_roles_assignment_key;
+ALTER TABLE user_roles ADD CONSTRAINT user_roles_pkey PRIMARY KEY (user_id, role_id);
+ALTER TABLE user_roles DROP CONSTRAINT IF EXISTS fk_user_roles_store;
+ALTER TABLE user_roles DROP COLUMN IF EXISTS store_id;
+
+-- Stock of every store is pooled back into the catalog
+ALTER TABLE products ADD COLUMN stock INTEGER NOT NULL DEFAULT 0 CHECK (stock >= 0);
+UPDATE products p SET stock = COALESCE(
+    (SELECT SUM(sp.stock) FROM store_products sp WHERE sp.product_id = p.id),
+    0
+);
+
+DROP INDEX IF EXISTS idx_orders_store_id;
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS fk_orders_store;
+ALTER TABLE orders DROP COLUMN IF EXISTS store_id;
+
+DROP TABLE IF EXISTS carts;
+DROP INDEX IF EXISTS idx_store_products_product_id;
+DROP TABLE IF EXISTS store_products;
+DROP TABLE IF EXISTS store_opening_hours;
+DROP TRIGGER IF EXISTS update_stores_updated_at ON stores;
+DROP TABLE IF EXISTS stores;
+-- +goose StatementEnd
@@ -0,0 +1,59 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Orders for later are booked into fixed-length slots, each taking a limited number of
+-- orders. Lead time is the shortest notice a slot can be booked with.
+ALTER TABLE stores
+    ADD COLUMN slot_minutes INTEGER NOT NULL DEFAULT 15 CHECK (slot_minutes BETWEEN 5 AND 240),
+    ADD COLUMN slot_capacity INTEGER NOT NULL DEFAULT 10 CHECK (slot_capacity > 0),
+    ADD COLUMN lead_minutes INTEGER NOT NULL DEFAULT 30 CHECK (lead_minutes >= 0);
+
+-- An exception replaces the weekly opening hours of one local date, for holidays and
+-- special events. A row without times closes the store for the whole day.
+CREATE TABLE IF NOT EXISTS store_hours_exceptions (
+    store_id UUID NOT NULL,
+    date DATE NOT NULL,
+    opens_at TIME,
+    closes_at TIME,
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    CONSTRAINT store_hours_exceptions_pkey PRIMARY KEY (store_id, date),
+    CONSTRAINT chk_store_hours_exceptions_times CHECK (
+        (opens_at IS NULL AND closes_at IS NULL)
+        OR (opens_at IS NOT NULL AND closes_at IS NOT NULL AND opens_at <> closes_at)
+    ),
+    CONSTRAINT fk_store_hours_exceptions_store
+        FOREIGN KEY (store_id)
+        REFERENCES stores(id)
+        ON DELETE CASCADE
+);
+
+-- Checkout is refused while a store is closed, so stores that had no opening hours yet,
+-- such as the store holding the data from before stores existed, stay open around the clock
+-- until their hours are set. A period cannot open and close at the same time, so each day
+-- is split at noon.
+INSERT INTO store_opening_hours (store_id, weekday, opens_at, closes_at)
+SELECT s.id, d.weekday, h.opens_at, h.closes_at
+FROM stores s
+CROSS JOIN generate_series(0, 6) AS d(weekday)
+CROSS JOIN (VALUES (TIME '00:00', TIME '12:00'), (TIME '12:00', TIME '00:00')) AS h(opens_at, closes_at)
+WHERE NOT EXISTS (SELECT 1 FROM store_opening_hours oh WHERE oh.store_id = s.id);
+
+-- NULL means as soon as possible
+ALTER TABLE orders ADD COLUMN scheduled_for TIMESTAMP;
+
+-- Create index on store_id and scheduled_for for counting the orders booked into a slot
+CREATE INDEX idx_orders_store_scheduled_for ON orders(store_id, scheduled_for)
+    WHERE scheduled_for IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_orders_store_scheduled_for;
+ALTER TABLE orders DROP COLUMN IF EXISTS scheduled_for;
+
+DROP TABLE IF EXISTS store_hours_exceptions;
+
+ALTER TABLE stores
+    DROP COLUMN IF EXISTS lead_minutes,
+    DROP COLUMN IF EXISTS slot_capacity,
+    DROP COLUMN IF EXISTS slot_minutes;
+-- +goose StatementEnd
@@ -0,0 +1,89 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS addresses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    label VARCHAR(50) NOT NULL DEFAULT '',
+    address_line1 VARCHAR(255) NOT NULL,
+    address_line2 VARCHAR(255) NOT NULL DEFAULT '',
+    city VARCHAR(100) NOT NULL,
+    postal_code VARCHAR(20) NOT NULL,
+    country CHAR(2) NOT NULL,
+    latitude DOUBLE PRECISION NOT NULL CHECK (latitude BETWEEN -90 AND 90),
+    longitude DOUBLE PRECISION NOT NULL CHECK (longitude BETWEEN -180 AND 180),
+    phone VARCHAR(32) NOT NULL DEFAULT '',
+    instructions VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_addresses_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+-- Create index on user_id for fetching a user's address book
+CREATE INDEX idx_addresses_user_id ON addresses(user_id);
+
+CREATE TRIGGER update_addresses_updated_at
+    BEFORE UPDATE ON addresses
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- A zone is either a radius around the store or a polygon of latitude/longitude points
+CREATE TABLE IF NOT EXISTS delivery_zones (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    store_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    radius_meters INTEGER CHECK (radius_meters > 0),
+    polygon JSONB,
+    min_order_value DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (min_order_value >= 0),
+    delivery_fee DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (delivery_fee >= 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_delivery_zones_area CHECK ((radius_meters IS NULL) <> (polygon IS NULL)),
+    CONSTRAINT fk_delivery_zones_store
+        FOREIGN KEY (store_id)
+        REFERENCES stores(id)
+        ON DELETE CASCADE
+);
+
+-- Create index on store_id for fetching a store's zones
+CREATE INDEX idx_delivery_zones_store_id ON delivery_zones(store_id);
+
+CREATE TRIGGER update_delivery_zones_updated_at
+    BEFORE UPDATE ON delivery_zones
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Delivery orders keep a snapshot of the address they were delivered to
+ALTER TABLE orders ADD COLUMN fulfillment_type VARCHAR(20) NOT NULL DEFAULT 'pickup'
+    CONSTRAINT check_order_fulfillment_type CHECK (fulfillment_type IN ('pickup', 'delivery'));
+ALTER TABLE orders ADD COLUMN delivery_address JSONB;
+
+-- Charges such as the delivery fee are order lines without a product
+ALTER TABLE order_items ADD COLUMN type VARCHAR(20) NOT NULL DEFAULT 'product'
+    CONSTRAINT check_order_item_type CHECK (type IN ('product', 'delivery_fee'));
+ALTER TABLE order_items ALTER COLUMN product_id DROP NOT NULL;
+ALTER TABLE order_items ADD CONSTRAINT check_order_item_product
+    CHECK ((type = 'product') = (product_id IS NOT NULL));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM order_items WHERE type <> 'product';
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS check_order_item_product;
+ALTER TABLE order_items ALTER COLUMN product_id SET NOT NULL;
+ALTER TABLE order_items DROP COLUMN IF EXISTS type;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS delivery_address;
+ALTER TABLE orders DROP COLUMN IF EXISTS fulfillment_type;
+
+DROP TRIGGER IF EXISTS update_delivery_zones_updated_at ON delivery_zones;
+DROP INDEX IF EXISTS idx_delivery_zones_store_id;
+DROP TABLE IF EXISTS delivery_zones;
+
+DROP TRIGGER IF EXISTS update_addresses_updated_at ON addresses;
+DROP INDEX IF EXISTS idx_addresses_user_id;
+DROP TABLE IF EXISTS addresses;
+-- +goose StatementEnd
@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The kitchen moves confirmed orders through preparing to ready before they are handed
+-- over or shipped
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS check_order_status;
+ALTER TABLE orders ADD CONSTRAINT check_order_status
+    CHECK (status IN ('pending', 'confirmed', 'preparing', 'ready', 'shipped', 'delivered', 'cancelled', 'refunded'));
+
+-- Create index on store_id and created_at for the kitchen queue, which only holds
+-- confirmed and preparing orders
+CREATE INDEX idx_orders_kitchen_queue ON orders(store_id, created_at)
+    WHERE status IN ('confirmed', 'preparing');
+
+-- Each product line is tracked by the kitchen; charge lines are not
+ALTER TABLE order_items ADD COLUMN kitchen_status VARCHAR(20)
+    CONSTRAINT check_order_item_kitchen_status CHECK (kitchen_status IN ('queued', 'preparing', 'ready'));
+
+-- Lines of orders the kitchen has already finished with are ready
+UPDATE order_items oi
+SET kitchen_status = CASE WHEN o.status IN ('pending', 'confirmed') THEN 'queued' ELSE 'ready' END
+FROM orders o
+WHERE o.id = oi.order_id AND oi.type = 'product';
+
+ALTER TABLE order_items ADD CONSTRAINT check_order_item_kitchen_tracked
+    CHECK ((type = 'product') = (kitchen_status IS NOT NULL));
+
+INSERT INTO permissions (name, description) VALUES
+    ('kitchen:operate', 'View the kitchen queue and mark order items as preparing or ready');
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT id, 'kitchen:operate' FROM roles WHERE name IN ('admin', 'store_manager', 'kitchen');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name = 'kitchen:operate';
+
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS check_order_item_kitchen_tracked;
+ALTER TABLE order_items DROP COLUMN IF EXISTS kitchen_status;
+
+DROP INDEX IF EXISTS idx_orders_kitchen_queue;
+
+-- Orders in the kitchen go back to confirmed
+UPDATE orders SET status = 'confirmed' WHERE status IN ('preparing', 'ready');
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS check_order_status;
+ALTER TABLE orders ADD CONSTRAINT check_order_status
+    CHECK (status IN ('pending', 'confirmed', 'shipped', 'delivered', 'cancelled', 'refunded'));
+-- +goose StatementEnd
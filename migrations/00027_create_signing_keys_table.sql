@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Access token signing keys, shared by every replica so tokens survive restarts and
+-- verify anywhere. Each key names the key it replaced; the newest key signs and a key is
+-- retired when its successor is created. Replacing a key twice is a unique violation, so
+-- replicas rotating at the same moment agree on a single successor.
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id VARCHAR(64) PRIMARY KEY,
+    algorithm VARCHAR(10) NOT NULL,
+    private_key BYTEA NOT NULL,
+    replaces VARCHAR(64),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_signing_keys_replaces ON signing_keys (COALESCE(replaces, ''));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS signing_keys;
+-- +goose StatementEnd
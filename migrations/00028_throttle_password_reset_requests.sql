@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Password reset requests are counted per client IP alongside failed logins
+ALTER TABLE login_throttles DROP CONSTRAINT IF EXISTS login_throttles_kind_check;
+ALTER TABLE login_throttles ADD CONSTRAINT login_throttles_kind_check CHECK (kind IN ('email', 'ip', 'reset_ip'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM login_throttles WHERE kind = 'reset_ip';
+ALTER TABLE login_throttles DROP CONSTRAINT IF EXISTS login_throttles_kind_check;
+ALTER TABLE login_throttles ADD CONSTRAINT login_throttles_kind_check CHECK (kind IN ('email', 'ip'));
+-- +goose StatementEnd
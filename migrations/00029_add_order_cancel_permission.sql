@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Cancelling returns an order's items to stock, so staff who only move orders along
+-- (kitchen and driver) may no longer cancel them
+INSERT INTO permissions (name, description) VALUES
+    ('orders:cancel', 'Cancel orders and return their items to stock');
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT id, 'orders:cancel' FROM roles WHERE name IN ('admin', 'store_manager');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name = 'orders:cancel';
+-- +goose StatementEnd
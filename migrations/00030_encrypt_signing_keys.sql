@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Signing keys are now stored encrypted. Keys stored before hold plaintext key material, so
+-- they are dropped and a new encrypted key is generated at startup; access tokens they
+-- signed stop verifying and clients refresh them.
+DELETE FROM signing_keys;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Encrypted keys cannot be read without the encryption key
+DELETE FROM signing_keys;
+-- +goose StatementEnd